@@ -0,0 +1,222 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"littleclaw/pkg/config"
+	"littleclaw/pkg/logging"
+)
+
+// cliOptions are the global flags, given before the command. Each also has an
+// environment variable so containers can be configured without arguments.
+type cliOptions struct {
+	profile   string
+	config    string
+	workspace string
+	logLevel  string
+	model     string
+	provider  string
+}
+
+var (
+	opts        cliOptions
+	globalFlags *flag.FlagSet
+)
+
+// command is one littleclaw subcommand.
+type command struct {
+	name    string
+	args    string // argument synopsis for help
+	summary string
+	run     func(args []string)
+}
+
+// commands are the subcommands; running without one starts the agent. Set in
+// init because help lists them.
+var commands []command
+
+func init() {
+	commands = []command{
+		{"configure", "", "Interactive setup wizard", func([]string) { runConfigure() }},
+		{"ask", "<question>", "Answer one question and exit, without channels", runAsk},
+		{"doctor", "", "Check the config, provider, Telegram, and workspace", func([]string) { runDoctor() }},
+		{"status", "", "Show the running agent's uptime, queues, runs, and cron jobs", func([]string) { runStatus() }},
+		{"stop", "", "Stop the running agent", func([]string) { runStop() }},
+		{"service", "install|uninstall|start|stop", "Run the agent as a systemd/launchd service", runService},
+		{"reset", "", "Delete the workspace (memory, history, files)", func([]string) { runReset() }},
+		{"profiles", "", "List profiles", func([]string) { runProfiles() }},
+		{"memory", "show|edit|search|versions|rollback", "Inspect and curate core memory", runMemory},
+		{"cron", "list|add|remove|pause|resume|run", "Manage scheduled jobs", runCron},
+		{"agenda", "", "Print today's calendar events", func([]string) { runAgenda() }},
+		{"weather", "<location> [days]", "Print the weather forecast", runWeather},
+		{"skills", "install <git-url> [--force] | list", "Manage shared skill packs", runSkills},
+		{"audit", "[days] | exec [n]", "Summarize tool usage, or list recent shell commands", runAudit},
+		{"help", "[command]", "Show this help", runHelp},
+	}
+}
+
+// parseGlobalFlags parses the flags before the command, applies the profile,
+// paths, and log level, and returns the command and its arguments.
+func parseGlobalFlags(args []string) []string {
+	fs := flag.NewFlagSet("littleclaw", flag.ExitOnError)
+	fs.StringVar(&opts.profile, "profile", os.Getenv(config.ProfileEnv), "profile to use, from ~/.littleclaw/profiles/<name> ($"+config.ProfileEnv+")")
+	fs.StringVar(&opts.config, "config", os.Getenv("LITTLECLAW_CONFIG"), "config file to use instead of the profile's config.json ($LITTLECLAW_CONFIG)")
+	fs.StringVar(&opts.workspace, "workspace", os.Getenv("LITTLECLAW_WORKSPACE"), "workspace directory to use instead of the profile's ($LITTLECLAW_WORKSPACE)")
+	fs.StringVar(&opts.logLevel, "log-level", os.Getenv("LITTLECLAW_LOG_LEVEL"), "debug, info, warn, or error; overrides log_level in config.json ($LITTLECLAW_LOG_LEVEL)")
+	fs.StringVar(&opts.model, "model", "", "use this model instead of provider_model for this run")
+	fs.StringVar(&opts.provider, "provider", "", "use this provider (openrouter, openai, ollama) instead of provider_type for this run")
+	fs.Usage = func() { runHelp(nil) }
+	globalFlags = fs
+	fs.Parse(args)
+
+	if err := config.SetProfile(opts.profile); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	if err := config.SetConfigPath(opts.config); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	if err := config.SetWorkspaceDir(opts.workspace); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	logging.Setup(os.Stderr, "text")
+	if opts.logLevel != "" {
+		level, err := logging.ParseLevel(opts.logLevel)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		logging.SetLevel(level)
+	}
+	return fs.Args()
+}
+
+// dispatch runs the named command and reports whether there was one.
+func dispatch(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	for _, c := range commands {
+		if c.name == args[0] {
+			c.run(args[1:])
+			return true
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Unknown command %q.\n\n", args[0])
+	runHelp(nil)
+	os.Exit(2)
+	return true
+}
+
+// runHelp prints usage: `littleclaw help [command]`.
+func runHelp(args []string) {
+	out := os.Stderr
+	if len(args) > 0 {
+		for _, c := range commands {
+			if c.name == args[0] {
+				fmt.Fprintf(out, "Usage: littleclaw [flags] %s %s\n\n%s\n", c.name, c.args, c.summary)
+				return
+			}
+		}
+	}
+	fmt.Fprintln(out, "Usage: littleclaw [flags] [command] [args]")
+	fmt.Fprintln(out, "\nWithout a command, starts the agent.\n\nCommands:")
+	for _, c := range commands {
+		fmt.Fprintf(out, "  %-10s %-36s %s\n", c.name, c.args, c.summary)
+	}
+	fmt.Fprintln(out, "\nFlags:")
+	globalFlags.SetOutput(out)
+	globalFlags.PrintDefaults()
+}
+
+// applyOverrides applies --model and --provider to a loaded config.
+func applyOverrides(cfg *config.AppConfig) {
+	if opts.provider != "" {
+		cfg.ProviderType = opts.provider
+	}
+	if opts.model != "" {
+		cfg.ProviderModel = opts.model
+	}
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// logFile is the open log_file, if any, and logFileOpts what it was opened with.
+var (
+	logFile     *logging.RotatingFile
+	logFileOpts logging.RotateOptions
+)
+
+// applyLogConfig applies log_file, log_rotation, log_format and, unless
+// --log-level was given, log_level from the config.
+func applyLogConfig(cfg *config.AppConfig) error {
+	level := slog.LevelInfo
+	if cfg.LogLevel != "" {
+		var err error
+		if level, err = logging.ParseLevel(cfg.LogLevel); err != nil {
+			return err
+		}
+	}
+
+	var out io.Writer = os.Stderr
+	file := logFile
+	if cfg.LogFile != "" {
+		path := cfg.LogFile
+		if !filepath.IsAbs(path) {
+			dir, err := config.Dir()
+			if err != nil {
+				return err
+			}
+			path = filepath.Join(dir, path)
+		}
+		r := cfg.LogRotation
+		rotate := logging.RotateOptions{
+			MaxSize:    int64(r.MaxSizeMB) << 20,
+			Daily:      r.Daily,
+			MaxBackups: r.MaxBackups,
+			MaxAge:     time.Duration(r.MaxAgeDays) * 24 * time.Hour,
+		}
+		if file == nil || file.Path() != path || rotate != logFileOpts {
+			f, err := logging.OpenRotatingFile(path, rotate)
+			if err != nil {
+				return fmt.Errorf("log_file: %w", err)
+			}
+			file = f
+		}
+		out = file
+		logFileOpts = rotate
+	} else {
+		file = nil
+	}
+
+	if err := logging.Setup(out, cfg.LogFormat); err != nil {
+		if file != logFile {
+			file.Close()
+		}
+		return err
+	}
+	if logFile != nil && logFile != file {
+		logFile.Close()
+	}
+	logFile = file
+	if opts.logLevel == "" {
+		logging.SetLevel(level)
+	}
+	return nil
+}
+
+// fatal logs msg as an error and exits.
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
@@ -2,22 +2,50 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
+	"log/slog"
+	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
 	"os/exec" // Added for runStop function
 	"strings" // Added for runStop function
 	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"sync/atomic"
 	"syscall"
+	"text/tabwriter"
 	"time"
+	"unicode/utf8"
 	"strconv" // Added for runStop function
 
 	"littleclaw/pkg/agent"
 	"littleclaw/pkg/bus"
+	"littleclaw/pkg/calendar"
 	"littleclaw/pkg/channels/telegram"
 	"littleclaw/pkg/config"
+	"littleclaw/pkg/control"
+	"littleclaw/pkg/doctor"
+	"littleclaw/pkg/filelinks"
+	"littleclaw/pkg/format"
+	"littleclaw/pkg/health"
+	"littleclaw/pkg/httpauth"
+	"littleclaw/pkg/httpclient"
+	"littleclaw/pkg/logging"
+	"littleclaw/pkg/memory"
 	"littleclaw/pkg/providers"
+	"littleclaw/pkg/redact"
+	"littleclaw/pkg/service"
+	"littleclaw/pkg/telemetry"
+	"littleclaw/pkg/tools"
+	"littleclaw/pkg/weather"
 
 	"github.com/joho/godotenv"
 	"github.com/manifoldco/promptui"
@@ -84,53 +112,68 @@ func runConfigure() {
 	cfg.TelegramToken = promptWithDefault("Enter Telegram Bot Token", cfg.TelegramToken)
 	cfg.TelegramAllowedUser = promptWithDefault("Enter Restricted Telegram User ID (Optional)", cfg.TelegramAllowedUser)
 
-	providerOptions := []string{"openrouter", "ollama", "openai"}
+	providerOptions := []string{"openrouter", "ollama", "openai", "xai"}
 	cfg.ProviderType = selectOption("Choose LLM Provider", providerOptions, cfg.ProviderType)
 
 	if cfg.ProviderType == "ollama" {
-		cfg.ProviderModel = promptWithDefault("Enter Ollama Model (e.g. llama3.2)", cfg.ProviderModel)
+		cfg.ProviderModel = pickOllamaModel(&cfg.Ollama, cfg.ProviderModel)
 	} else {
 		cfg.ProviderAPIKey = promptWithDefault(fmt.Sprintf("Enter %s API Key", cfg.ProviderType), cfg.ProviderAPIKey)
-		cfg.ProviderModel = promptWithDefault("Enter Model Name (e.g. gpt-4o-mini)", cfg.ProviderModel)
+		example := "gpt-4o-mini"
+		if cfg.ProviderType == "xai" {
+			example = "grok-4"
+		}
+		cfg.ProviderModel = pickModel(cfg, example)
+	}
+	if cfg.ProviderType == "xai" {
+		cfg.XAI.Search = selectOption("Grok Live Search (answers cite web and X sources)", []string{"off", "auto", "on"}, cfg.XAI.Search)
 	}
 
 	transcriberOptions := []string{"groq", "openai", "whisper-cli", "none"}
-	cfg.TranscriptionProvider = selectOption("Choose Transcription Provider", transcriberOptions, cfg.TranscriptionProvider)
-
-	if cfg.TranscriptionProvider != "none" {
-		if cfg.TranscriptionProvider == "openai" {
-			cfg.TranscriptionBaseURL = promptWithDefault("Enter OpenAI/Local Whisper Base URL (e.g. http://localhost:8080/v1)", cfg.TranscriptionBaseURL)
-			if cfg.TranscriptionBaseURL == "" {
-				cfg.TranscriptionBaseURL = "http://localhost:8080/v1"
+	tc := &cfg.Transcription
+	tc.Provider = selectOption("Choose Transcription Provider", transcriberOptions, tc.Provider)
+
+	if tc.Provider != "none" {
+		if tc.Provider == "openai" {
+			tc.BaseURL = promptWithDefault("Enter OpenAI/Local Whisper Base URL (e.g. http://localhost:8080/v1)", tc.BaseURL)
+			if tc.BaseURL == "" {
+				tc.BaseURL = "http://localhost:8080/v1"
 			}
 		}
 
-		if cfg.TranscriptionProvider == "openai" || cfg.TranscriptionProvider == "whisper-cli" {
-			cfg.TranscriptionModel = promptWithDefault("Enter Whisper Model (e.g. whisper-1, base, small)", cfg.TranscriptionModel)
-			if cfg.TranscriptionModel == "" {
-				cfg.TranscriptionModel = "small"
+		if tc.Provider == "openai" || tc.Provider == "whisper-cli" {
+			tc.Model = promptWithDefault("Enter Whisper Model (e.g. whisper-1, base, small)", tc.Model)
+			if tc.Model == "" {
+				tc.Model = "small"
 			}
 		}
 
-		if cfg.TranscriptionProvider != "whisper-cli" {
-			cfg.TranscriptionAPIKey = promptWithDefault(fmt.Sprintf("Enter %s API Key", cfg.TranscriptionProvider), cfg.TranscriptionAPIKey)
+		if tc.Provider != "whisper-cli" {
+			tc.APIKey = promptWithDefault(fmt.Sprintf("Enter %s API Key", tc.Provider), tc.APIKey)
 		}
+		tc.Language = promptWithDefault("Enter Spoken Language Code (e.g. en; leave blank to auto-detect)", tc.Language)
 	}
 
 	fmt.Println("")
 	fmt.Println("--- Web Search (Optional) ---")
 	cfg.TavilyAPIKey = promptWithDefault("Enter Tavily Search API Key (leave blank to skip)", cfg.TavilyAPIKey)
 
+	fmt.Println("")
+	fmt.Println("--- Persona (Optional) ---")
+	personaStore, persona, personaChanged := promptPersona()
+
 	fmt.Println("\n🔍 Testing Provider Connection...")
 
 	// Create temporary provider to verify settings before saving
 	var provider providers.Provider
 	if cfg.ProviderType == "ollama" {
-		provider = providers.NewOpenAIProvider("ollama", "http://localhost:11434/v1", "dummy")
+		provider = providers.NewOllamaProvider(cfg.Ollama.BaseURL, cfg.Ollama.KeepAlive)
 	} else if cfg.ProviderType == "openrouter" {
 		provider = providers.NewOpenAIProvider("openrouter", "https://openrouter.ai/api/v1", cfg.ProviderAPIKey)
 	} else if cfg.ProviderType == "openai" {
 		provider = providers.NewOpenAIProvider("openai", "https://api.openai.com/v1", cfg.ProviderAPIKey)
+	} else if cfg.ProviderType == "xai" {
+		provider = providers.NewOpenAIProvider("xai", providers.XAIBaseURL, cfg.ProviderAPIKey)
 	}
 
 	if provider != nil {
@@ -164,16 +207,169 @@ func runConfigure() {
 		log.Fatalf("❌ Failed to save config: %v", err)
 	}
 
-	fmt.Println("✅ Configuration saved successfully to ~/.littleclaw/config.json!")
-	fmt.Println("You can now run 'go run cmd/littleclaw/main.go' to start the agent.")
+	if path, err := config.Path(); err == nil {
+		fmt.Printf("✅ Configuration saved successfully to %s!\n", path)
+	}
+	if personaChanged {
+		if err := personaStore.WritePersona(persona); err != nil {
+			fmt.Printf("⚠️ Failed to save persona: %v\n", err)
+		} else {
+			fmt.Printf("✅ Persona saved to %s\n", personaStore.PersonaFile())
+		}
+	}
+	if p := config.Profile(); p != "" {
+		fmt.Printf("You can now run 'littleclaw --profile %s' to start the agent.\n", p)
+	} else {
+		fmt.Println("You can now run 'go run cmd/littleclaw/main.go' to start the agent.")
+	}
+}
+
+// pullOllamaModel is the model picker entry for downloading a model.
+const pullOllamaModel = "Download another model..."
+
+// pickOllamaModel asks for the Ollama server and offers its installed models,
+// downloading one if asked. It falls back to a free-text prompt when the
+// server can't be reached.
+func pickOllamaModel(c *config.OllamaConfig, current string) string {
+	if c.BaseURL == "" {
+		c.BaseURL = providers.DefaultOllamaURL
+	}
+	c.BaseURL = promptWithDefault("Enter Ollama URL", c.BaseURL)
+	ollama := providers.NewOllamaProvider(c.BaseURL, c.KeepAlive)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	models, err := ollama.ListModels(ctx)
+	cancel()
+	if err != nil {
+		fmt.Printf("⚠️ Could not list installed models (is 'ollama serve' running?): %v\n", err)
+		return promptWithDefault("Enter Ollama Model (e.g. llama3.2)", current)
+	}
+	options := make([]string, 0, len(models)+1)
+	for _, m := range models {
+		options = append(options, m.ID)
+	}
+	if choice := selectOption("Choose Ollama Model", append(options, pullOllamaModel), current); choice != pullOllamaModel {
+		return choice
+	}
+
+	model := promptWithDefault("Enter the model to download (e.g. llama3.2)", "")
+	if model == "" {
+		return current
+	}
+	lastStatus := ""
+	err = ollama.Pull(context.Background(), model, func(p providers.PullProgress) {
+		if p.Total > 0 {
+			fmt.Printf("\r⬇️  %s: %d%%   ", p.Status, p.Completed*100/p.Total)
+		} else if p.Status != lastStatus {
+			fmt.Printf("\n%s", p.Status)
+		}
+		lastStatus = p.Status
+	})
+	fmt.Println()
+	if err != nil {
+		fmt.Printf("❌ Failed to download %s: %v\n", model, err)
+		return current
+	}
+	fmt.Printf("✅ Downloaded %s\n", model)
+	return model
+}
+
+// otherModel is the model picker entry for typing a model name.
+const otherModel = "Enter another model..."
+
+// pickModel offers the models the configured provider lists, searchable
+// with '/'. It falls back to a free-text prompt when they can't be listed.
+func pickModel(cfg *config.AppConfig, example string) string {
+	enterModel := func() string {
+		return promptWithDefault(fmt.Sprintf("Enter Model Name (e.g. %s)", example), cfg.ProviderModel)
+	}
+	provider, err := newChatProvider(cfg.ProviderType, cfg.ProviderAPIKey, cfg)
+	if err != nil {
+		return enterModel()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	models, err := provider.ListModels(ctx)
+	cancel()
+	if err != nil || len(models) == 0 {
+		if err != nil {
+			fmt.Printf("⚠️ Could not list %s models: %v\n", cfg.ProviderType, err)
+		}
+		return enterModel()
+	}
+
+	items := make([]string, 0, len(models)+1)
+	cursorPos := 0
+	for i, m := range models {
+		if m.ID == cfg.ProviderModel {
+			cursorPos = i
+		}
+		item := m.ID
+		if m.Description != "" && m.Description != m.ID {
+			item += " (" + m.Description + ")"
+		}
+		items = append(items, item)
+	}
+	items = append(items, otherModel)
+	prompt := promptui.Select{
+		Label:     fmt.Sprintf("Choose Model (%d available, / to search)", len(models)),
+		Items:     items,
+		CursorPos: cursorPos,
+		Size:      12,
+		Searcher: func(input string, i int) bool {
+			return strings.Contains(strings.ToLower(items[i]), strings.ToLower(input))
+		},
+	}
+	i, _, err := prompt.Run()
+	switch {
+	case err != nil:
+		return cfg.ProviderModel
+	case i == len(models):
+		return enterModel()
+	}
+	return models[i].ID
+}
+
+// promptPersona asks for the agent's name, tone, reply language, and standing
+// instructions, starting from the workspace's current SYSTEM.md. Values left at
+// the defaults are not stored.
+func promptPersona() (*memory.Store, memory.Persona, bool) {
+	workspaceDir, err := config.WorkspaceDir()
+	if err != nil {
+		return nil, memory.Persona{}, false
+	}
+	store, err := memory.NewStore(workspaceDir)
+	if err != nil {
+		fmt.Printf("⚠️ Skipping persona setup: %v\n", err)
+		return nil, memory.Persona{}, false
+	}
+
+	current := store.ReadPersona()
+	shown := current.WithDefaults()
+	p := current
+	p.Name = strings.TrimSpace(promptWithDefault("Agent Name", shown.Name))
+	p.Tone = strings.TrimSpace(promptWithDefault("Tone", shown.Tone))
+	p.Language = strings.TrimSpace(promptWithDefault("Reply Language (leave blank to match the user)", current.Language))
+	instructions := strings.TrimSpace(promptWithDefault("Standing Instructions (leave blank to keep current, 'none' to clear)", ""))
+	if strings.EqualFold(instructions, "none") {
+		p.Instructions = ""
+	} else if instructions != "" {
+		p.Instructions = instructions
+	}
+
+	if p.Name == memory.DefaultPersonaName {
+		p.Name = ""
+	}
+	if p.Tone == memory.DefaultPersonaTone {
+		p.Tone = ""
+	}
+	return store, p, p != current
 }
 
 func runReset() {
-	home, err := os.UserHomeDir()
+	workspaceDir, err := config.WorkspaceDir()
 	if err != nil {
-		log.Fatalf("Cannot get home dir: %v", err)
+		log.Fatalf("Cannot locate workspace: %v", err)
 	}
-	workspaceDir := filepath.Join(home, ".littleclaw", "workspace")
 
 	fmt.Printf("🗑️ Are you sure you want to reset Littleclaw's entire workspace? This will delete all memory, history, entities, and downloaded files in %s. (y/N): ", workspaceDir)
 	var confirm string
@@ -191,11 +387,11 @@ func runReset() {
 }
 
 func runStop() {
-	home, err := os.UserHomeDir()
+	dir, err := config.Dir()
 	if err != nil {
-		log.Fatalf("Cannot get home dir: %v", err)
+		log.Fatalf("Cannot locate littleclaw directory: %v", err)
 	}
-	pidFile := filepath.Join(home, ".littleclaw", "littleclaw.pid")
+	pidFile := filepath.Join(dir, "littleclaw.pid")
 	stoppedProcesses := 0
 
 	// --- Attempt 1: Stop using PID file ---
@@ -222,6 +418,14 @@ func runStop() {
 	os.Remove(pidFile)
 
 	// --- Attempt 2: Search for 'go run' processes (from go-build cache) ---
+	// A named profile only stops its own process: the search cannot tell
+	// profiles apart and would stop the others too.
+	if p := config.Profile(); p != "" {
+		if stoppedProcesses == 0 {
+			fmt.Printf("No running Littleclaw process found for profile %q.\n", p)
+		}
+		return
+	}
 	if stoppedProcesses == 0 {
 		fmt.Println("No Littleclaw process stopped via PID file. Searching for 'go run' instances...")
 	} else {
@@ -277,205 +481,1932 @@ func runStop() {
 	}
 }
 
-func main() {
-	if len(os.Args) > 1 {
-		if os.Args[1] == "configure" {
-			runConfigure()
-			return
-		} else if os.Args[1] == "reset" {
-			runReset()
-			return
-		} else if os.Args[1] == "stop" { // Added stop command
-			runStop()
-			return
+// newCalendarClient builds the configured calendar backend, or nil when none is set.
+func newCalendarClient(c config.CalendarConfig) (calendar.Client, error) {
+	switch c.Provider {
+	case "":
+		return nil, nil
+	case "caldav":
+		if c.URL == "" {
+			return nil, fmt.Errorf("calendar.url is required for caldav")
+		}
+		return calendar.NewCalDAVClient(c.URL, c.Username, c.Password, time.Local), nil
+	case "google":
+		if c.ClientID == "" || c.ClientSecret == "" || c.RefreshToken == "" {
+			return nil, fmt.Errorf("calendar.client_id, client_secret and refresh_token are required for google")
 		}
+		return calendar.NewGoogleClient(c.CalendarID, c.ClientID, c.ClientSecret, c.RefreshToken, time.Local), nil
 	}
+	return nil, fmt.Errorf("unknown calendar provider %q (want caldav or google)", c.Provider)
+}
 
-	printLogo()
-	fmt.Println("⚙️  Starting Littleclaw Core Systems...")
+// newVisionProvider returns the provider for analyze_image. An empty
+// vision.provider reuses the chat provider with the vision model.
+func newVisionProvider(cfg *config.AppConfig, chat providers.Provider) (providers.Provider, error) {
+	v := cfg.Vision
+	return newCompatibleProvider(cfg, chat, "vision", v.Provider, v.APIKey, v.BaseURL)
+}
 
-	// 0. Try loading from Config File first
-	cfg, err := config.Load()
-	if err != nil {
-		// Fallback to testing ENV variables so we don't break backward compatibility instantly
-		if err := godotenv.Load(); err != nil {
-			log.Println("⚠️ Could not load config.json or .env file.")
-			log.Println("Please run: 'go run cmd/littleclaw/main.go configure'")
-			log.Fatal(err)
+// newCompatibleProvider builds an OpenAI-compatible provider for a config
+// section (vision, agent roles). With neither name nor baseURL set it returns
+// chat; the API key defaults to provider_apikey when the provider matches.
+func newCompatibleProvider(cfg *config.AppConfig, chat providers.Provider, section, name, apiKey, baseURL string) (providers.Provider, error) {
+	if name == "" && baseURL == "" {
+		return chat, nil
+	}
+
+	if name == "" {
+		name = cfg.ProviderType
+	}
+	if apiKey == "" && name == cfg.ProviderType {
+		apiKey = cfg.ProviderAPIKey
+	}
+	if baseURL == "" {
+		switch name {
+		case "ollama":
+			return providers.NewOllamaProvider(cfg.Ollama.BaseURL, cfg.Ollama.KeepAlive), nil
+		case "openrouter":
+			baseURL = "https://openrouter.ai/api/v1"
+		case "openai":
+			baseURL = "https://api.openai.com/v1"
+		case "xai":
+			baseURL = providers.XAIBaseURL
+		default:
+			return nil, fmt.Errorf("unknown %s provider %q (want openai, openrouter, xai, or ollama, or set %s.baseurl)", section, name, section)
 		}
-		log.Println("⚠️ Using Legacy .env configuration. Consider running 'littleclaw configure'.")
 	}
+	return providers.NewOpenAIProvider(name, baseURL, apiKey), nil
+}
 
-	// 1. Setup Data Paths
-	home, err := os.UserHomeDir()
+// memoryIndex builds the Postgres memory index from the memory_index
+// section, embedding with its provider or else the chat provider.
+func memoryIndex(cfg *config.AppConfig, chat providers.Provider) (*memory.PgIndex, error) {
+	m := cfg.MemoryIndex
+	p, err := newCompatibleProvider(cfg, chat, "memory_index", m.Provider, m.APIKey, m.BaseURL)
 	if err != nil {
-		log.Fatalf("Cannot get home dir: %v", err)
+		return nil, err
 	}
-	workspace := filepath.Join(home, ".littleclaw", "workspace")
-
-	// Create PID file
-	pidFile := filepath.Join(home, ".littleclaw", "littleclaw.pid")
-	if err := os.WriteFile(pidFile, []byte(fmt.Sprintf("%d", os.Getpid())), 0644); err != nil {
-		log.Fatalf("Failed to write PID file: %v", err)
+	embedder, ok := p.(providers.Embedder)
+	if !ok {
+		return nil, fmt.Errorf("provider %s cannot compute embeddings", p.Name())
 	}
-	defer os.Remove(pidFile) // Ensure PID file is removed on exit
-
-
-	// 2. Load Configuration
-	var tgToken, tgAllowedUser, providerType, modelName, providerAPIKey string
+	model := m.EmbeddingModel
+	if model == "" {
+		model = "text-embedding-3-small"
+		if _, ok := p.(*providers.OllamaProvider); ok {
+			model = "nomic-embed-text"
+		}
+	}
+	return memory.NewPgIndex(m.DSN, m.Table, func(ctx context.Context, texts []string) ([][]float32, error) {
+		return embedder.Embed(ctx, model, texts)
+	})
+}
 
-	if cfg != nil {
-		// Read from config.json
-		tgToken = cfg.TelegramToken
-		tgAllowedUser = cfg.TelegramAllowedUser
-		providerType = cfg.ProviderType
-		modelName = cfg.ProviderModel
-		providerAPIKey = cfg.ProviderAPIKey
-	} else {
-		// Legacy .env fallback
-		tgToken = os.Getenv("TELEGRAM_BOT_TOKEN")
-		tgAllowedUser = os.Getenv("TELEGRAM_ALLOWED_USER_ID")
-		providerType = os.Getenv("LLM_PROVIDER")
-		if providerType == "" {
-			providerType = "openrouter" // Default
+// agentRoles builds the delegate tool's specialists from agent.roles.
+func agentRoles(cfg *config.AppConfig, chat providers.Provider) []agent.AgentRole {
+	var roles []agent.AgentRole
+	for name, r := range cfg.Agent.Roles {
+		p, err := newCompatibleProvider(cfg, chat, "agent.roles."+name, r.Provider, r.APIKey, r.BaseURL)
+		if err != nil {
+			slog.Warn("skipping agent role", "role", name, "err", err)
+			continue
 		}
+		roles = append(roles, agent.AgentRole{
+			Name:          name,
+			Description:   r.Description,
+			Provider:      p,
+			Model:         r.Model,
+			Tools:         r.Tools,
+			Instructions:  r.Instructions,
+			MaxIterations: r.MaxIterations,
+		})
+	}
+	return roles
+}
 
-		if providerType == "ollama" {
-			modelName = os.Getenv("OLLAMA_MODEL")
-			if modelName == "" {
-				modelName = "llama3.2"
-			}
-		} else {
-			providerAPIKey = os.Getenv("OPENROUTER_API_KEY")
-			modelName = "gpt-4o-mini"
+// userRoles builds the permission tier of each sender from the users
+// section. telegram_allowed_user is an admin unless listed otherwise.
+func userRoles(cfg *config.AppConfig) (agent.UserRoles, error) {
+	var p agent.UserRoles
+	if len(cfg.Users.Roles) == 0 {
+		return p, nil
+	}
+	p.Users = make(map[string]tools.UserRole, len(cfg.Users.Roles)+1)
+	if cfg.TelegramAllowedUser != "" {
+		p.Users[cfg.TelegramAllowedUser] = tools.RoleAdmin
+	}
+	for id, name := range cfg.Users.Roles {
+		role, err := tools.ParseUserRole(name)
+		if err != nil {
+			return p, fmt.Errorf("users.roles.%s: %w", id, err)
 		}
+		p.Users[id] = role
 	}
+	if cfg.Users.DefaultRole != "" {
+		role, err := tools.ParseUserRole(cfg.Users.DefaultRole)
+		if err != nil {
+			return p, fmt.Errorf("users.default_role: %w", err)
+		}
+		p.Default = role
+	}
+	return p, nil
+}
 
-	if tgToken == "" {
-		log.Println("⚠️ Missing Telegram Token! Please run 'go run cmd/littleclaw/main.go configure'")
-		log.Fatal("Exiting due to missing configuration.")
+// rateLimits builds the per-user limits from the rate_limits section. Unless
+// exempt users are listed, telegram_allowed_user and admins are exempt.
+func rateLimits(cfg *config.AppConfig) agent.RateLimitPolicy {
+	r := cfg.RateLimits
+	p := agent.RateLimitPolicy{
+		MessagesPerMinute: r.MessagesPerMinute,
+		ConcurrentRuns:    r.ConcurrentRuns,
+		DailyTokens:       r.DailyTokens,
+		Exempt:            r.Exempt,
+	}
+	if len(p.Exempt) == 0 {
+		p.Exempt = cfg.Admins()
 	}
+	return p
+}
 
-	var provider providers.Provider
+// modelRouter builds the cheap-model router from the router section. Without
+// a provider or base URL it follows the chat provider, across reloads too.
+func modelRouter(cfg *config.AppConfig) (agent.ModelRouter, error) {
+	r := cfg.Router
+	mr := agent.ModelRouter{Model: r.Model, MaxChars: r.MaxChars, MaxContextTokens: r.MaxContextTokens}
+	switch r.Classifier {
+	case "", "heuristic":
+	case "model":
+		mr.UseModel = true
+	default:
+		return mr, fmt.Errorf("unknown router classifier %q (want heuristic or model)", r.Classifier)
+	}
+	if r.Model == "" || (r.Provider == "" && r.BaseURL == "") {
+		return mr, nil
+	}
+	p, err := newCompatibleProvider(cfg, nil, "router", r.Provider, r.APIKey, r.BaseURL)
+	mr.Provider = p
+	return mr, err
+}
 
-	if providerType == "ollama" {
-		log.Printf("🤖 Initializing Ollama provider with model: %s", modelName)
-		provider = providers.NewOpenAIProvider(
-			"ollama",
-			"http://localhost:11434/v1", // Standard Ollama local port
-			"ollama",                    // Dummy key
-		)
-	} else {
-		if providerAPIKey == "" {
-			log.Println("⚠️ Missing API keys! Please run 'go run cmd/littleclaw/main.go configure'")
-			log.Fatal("Exiting due to missing configuration.")
-		}
+// runAgenda prints today's calendar events. Meant to be scheduled with add_cron
+// (e.g. "littleclaw agenda" at "0 8 * * *") for a morning briefing.
+func runAgenda() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	cal, err := newCalendarClient(cfg.Calendar)
+	if err != nil {
+		log.Fatalf("❌ Invalid calendar configuration: %v", err)
+	}
+	if cal == nil {
+		log.Fatal("❌ No calendar configured. Add a \"calendar\" section to config.json.")
+	}
+
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	events, err := cal.ListEvents(ctx, from, from.AddDate(0, 0, 1))
+	if err != nil {
+		log.Fatalf("❌ Failed to fetch events: %v", err)
+	}
+	fmt.Printf("📅 Agenda for %s\n%s\n", from.Format("Monday, Jan 2"), calendar.FormatEvents(events, time.Local))
+}
 
-		log.Printf("🤖 Initializing %s provider", providerType)
+// agentParams converts a config section into agent loop parameters.
+func agentParams(c config.AgentParamsConfig) agent.AgentParams {
+	return agent.AgentParams{
+		MaxIterations:    c.MaxIterations,
+		Temperature:      c.Temperature,
+		MaxTokens:        c.MaxTokens,
+		HistoryBytes:     c.HistoryBytes,
+		EntityTopK:       c.EntityTopK,
+		ContextWindow:    c.ContextWindow,
+		MaxContinuations: c.MaxContinuations,
+		Verify:           c.Verify,
+		TokenBudget:      c.TokenBudget,
+		CostBudget:       c.CostBudget,
+		PromptPrice:      c.PromptPrice,
+		CompletionPrice:  c.CompletionPrice,
+		Progress:         c.Progress,
+		Coalesce:         c.Coalesce,
+		CoalesceSeconds:  c.CoalesceSeconds,
+	}
+}
 
-		baseURL := "https://openrouter.ai/api/v1"
-		if providerType == "openai" {
-			baseURL = "https://api.openai.com/v1"
+// botProfile is the Telegram profile for an assistant called name, with the
+// texts and commands set in c taking precedence.
+func botProfile(c config.BotProfileConfig, name string) telegram.Profile {
+	p := telegram.DefaultProfile(name)
+	if c.ShortDescription != "" {
+		p.ShortDescription = c.ShortDescription
+	}
+	if c.Description != "" {
+		p.Description = c.Description
+	}
+	if len(c.Commands) > 0 {
+		p.Commands = nil
+		for _, cmd := range c.Commands {
+			p.Commands = append(p.Commands, telegram.Command{Command: strings.TrimPrefix(cmd.Command, "/"), Description: cmd.Description})
 		}
-
-		provider = providers.NewOpenAIProvider(
-			providerType,
-			baseURL,
-			providerAPIKey,
-		)
 	}
+	return p
+}
 
-	if tgToken == "" {
-		log.Println("⚠️ Missing TELEGRAM_BOT_TOKEN. Export it to continue.")
-		log.Fatal("Exiting due to missing configuration.")
+// newWeatherProvider builds the configured get_weather backend (Open-Meteo when unset).
+func newWeatherProvider(c config.WeatherConfig) (weather.Provider, error) {
+	switch c.Provider {
+	case "", "open-meteo":
+		return weather.NewOpenMeteo(c.Units), nil
+	case "openweathermap":
+		if c.APIKey == "" {
+			return nil, fmt.Errorf("weather.apikey is required for openweathermap")
+		}
+		return weather.NewOpenWeatherMap(c.APIKey, c.Units), nil
 	}
+	return nil, fmt.Errorf("unknown weather provider %q (want open-meteo or openweathermap)", c.Provider)
+}
 
-	allowedUsers := []string{}
-	if tgAllowedUser != "" {
-		allowedUsers = append(allowedUsers, tgAllowedUser)
+// newTranscriber returns the voice message transcriber for c, or nil when
+// transcription is off.
+func newTranscriber(c config.TranscriptionConfig) (providers.TranscriptionProvider, error) {
+	switch c.Provider {
+	case "", "none":
+		return nil, nil
+	case "groq":
+		if c.APIKey == "" {
+			return nil, fmt.Errorf("transcription.apikey is required for groq")
+		}
+		p := providers.NewGroqTranscriptionProvider(c.APIKey)
+		if c.Model != "" {
+			p.Model = c.Model
+		}
+		p.Language = c.Language
+		return p, nil
+	case "openai":
+		p := providers.NewOpenAITranscriptionProvider(c.BaseURL, c.APIKey, c.Model)
+		p.Language = c.Language
+		return p, nil
+	case "whisper-cli":
+		p := providers.NewWhisperCLITranscriptionProvider(c.Model)
+		p.Language = c.Language
+		return p, nil
 	}
+	return nil, fmt.Errorf("unknown transcription provider %q (want groq, openai, whisper-cli, or none)", c.Provider)
+}
 
-	// 3. Initialize Core Infrastructure
-	msgBus := bus.NewMessageBus()
+// runWeather prints the forecast for a location, e.g. from a morning-briefing cron job.
+func runWeather(args []string) {
+	if len(args) == 0 {
+		fmt.Println("Usage: littleclaw weather <location> [days]")
+		return
+	}
+	var wc config.WeatherConfig
+	if cfg, err := config.Load(); err == nil {
+		wc = cfg.Weather
+	}
+	provider, err := newWeatherProvider(wc)
+	if err != nil {
+		log.Fatalf("❌ Invalid weather configuration: %v", err)
+	}
 
-	// Collect optional feature keys
-	tavilyAPIKey := ""
-	if cfg != nil {
-		tavilyAPIKey = cfg.TavilyAPIKey
+	days := 1
+	location := strings.Join(args, " ")
+	if n, err := strconv.Atoi(args[len(args)-1]); err == nil && len(args) > 1 {
+		days = n
+		location = strings.Join(args[:len(args)-1], " ")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	f, err := provider.Forecast(ctx, location, days)
+	if err != nil {
+		log.Fatalf("❌ Failed to fetch weather: %v", err)
 	}
+	fmt.Println(weather.Format(f))
+}
 
-	// Initialize the NanoCore Agent Loop
-	nanoCore, err := agent.NewNanoCore(provider, providerType, modelName, workspace, msgBus, tavilyAPIKey)
+// runSkills handles `littleclaw skills install <git-url> [--force]` and `littleclaw skills list`.
+func runSkills(args []string) {
+	workspaceDir, err := config.WorkspaceDir()
 	if err != nil {
-		log.Fatalf("Failed to initialize Agent Core: %v", err)
+		log.Fatalf("Cannot locate workspace: %v", err)
 	}
 
-	// Initialize the Telegram Channel
-	tgChannel := telegram.NewChannel(tgToken, allowedUsers, msgBus)
+	usage := "Usage: littleclaw skills install <git-url> [--force] | littleclaw skills list"
+	if len(args) == 0 {
+		fmt.Println(usage)
+		return
+	}
 
-	// Initialize Transcription Provider if configured
-	if cfg != nil {
-		if cfg.TranscriptionProvider == "groq" {
-			log.Printf("🎙️ Initializing Groq transcription provider")
-			groqTranscriber := providers.NewGroqTranscriptionProvider(cfg.TranscriptionAPIKey)
-			tgChannel.SetTranscriptionProvider(groqTranscriber)
-		} else if cfg.TranscriptionProvider == "openai" {
-			log.Printf("🎙️ Initializing OpenAI/Local transcription provider")
-			oaTranscriber := providers.NewOpenAITranscriptionProvider(cfg.TranscriptionBaseURL, cfg.TranscriptionAPIKey, cfg.TranscriptionModel)
-			tgChannel.SetTranscriptionProvider(oaTranscriber)
-		} else if cfg.TranscriptionProvider == "whisper-cli" {
-			log.Printf("🎙️ Initializing Whisper CLI transcription provider")
-			cliTranscriber := providers.NewWhisperCLITranscriptionProvider(cfg.TranscriptionModel)
-			tgChannel.SetTranscriptionProvider(cliTranscriber)
+	switch args[0] {
+	case "install":
+		var url string
+		force := false
+		for _, a := range args[1:] {
+			if a == "--force" {
+				force = true
+			} else {
+				url = a
+			}
+		}
+		if url == "" {
+			log.Fatal(usage)
 		}
-	}
 
-	// Initialize the Background Heartbeat (Memory Janitor & Cron)
-	// 5-minute interval — the dirty-flag check in the heartbeat means it only
-	// actually runs LLM consolidation when new history has been appended.
-	hb := agent.NewHeartbeat(nanoCore, 5*time.Minute)
+		// Vet scripts against the same exec policy the agent uses
+		policy := tools.DefaultExecPolicy()
+		if cfg, err := config.Load(); err == nil {
+			p := cfg.ExecPolicy
+			if policy, err = tools.NewExecPolicy(p.Allow, p.Deny, p.AllowlistOnly); err != nil {
+				log.Fatalf("Invalid exec_policy configuration: %v", err)
+			}
+		}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		res, err := tools.InstallSkillPack(ctx, workspaceDir, url, policy, force)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		fmt.Println("✅ " + res.Summary())
+		fmt.Println("A running agent picks these up on its next reload_skills call or restart.")
 
-	// 4. Start Background Heartbeat & Cron Service
-	go hb.Start(ctx)
-	nanoCore.StartCronService(ctx)
-	log.Println("✅ Background Heartbeat & Cron daemon started.")
+	case "list":
+		packs, err := tools.LoadSkillPacks(workspaceDir)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		if len(packs) == 0 {
+			fmt.Println("No skill packs installed.")
+			return
+		}
+		for _, p := range packs {
+			fmt.Printf("%s  %s@%s  (%s)\n", p.Name, p.URL, p.Commit, strings.Join(p.Skills, ", "))
+		}
 
-	// 5. Start Telegram Listener
-	if err := tgChannel.Start(ctx); err != nil {
-		log.Fatalf("Failed to start Telegram channel: %v", err)
+	default:
+		fmt.Println(usage)
 	}
-	log.Println("✅ Telegram channel started successfully. Listening for messages...")
+}
 
-	// 6. Start Message Processing Loop
-	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case inMsg := <-msgBus.Inbound:
-				// Route inbound message to the NanoCore
-				log.Printf("📩 Received message from %s (Chat: %s): %s", inMsg.SenderID, inMsg.ChatID, inMsg.Content)
-				go nanoCore.RunAgentLoop(ctx, inMsg)
-
-			case outMsg := <-msgBus.Outbound:
-				// Route outbound message back to Telegram
-				if outMsg.Channel == "telegram" {
-					if err := tgChannel.SendMessage(ctx, outMsg.ChatID, outMsg.ReplyToMessageID, outMsg.Content, outMsg.Files); err != nil {
-						log.Printf("❌ Failed to send Telegram message: %v", err)
-					}
+// runMemory lets a human curate the agent's memory: `littleclaw memory
+// show|edit|search <query>|versions|rollback [version]`.
+func runMemory(args []string) {
+	usage := "Usage: littleclaw memory show | edit | search <query> | versions | rollback [version]"
+	if len(args) == 0 {
+		fmt.Println(usage)
+		return
+	}
+	workspaceDir, err := config.WorkspaceDir()
+	if err != nil {
+		log.Fatalf("Cannot locate workspace: %v", err)
+	}
+	store, err := memory.NewStore(workspaceDir)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	switch args[0] {
+	case "show":
+		content := store.ReadLongTerm()
+		if strings.TrimSpace(content) == "" {
+			fmt.Println("Core memory (MEMORY.md) is empty.")
+			return
+		}
+		fmt.Print(content)
+		if !strings.HasSuffix(content, "\n") {
+			fmt.Println()
+		}
+
+	case "edit":
+		editMemory(store)
+
+	case "search":
+		query := strings.TrimSpace(strings.Join(args[1:], " "))
+		if query == "" {
+			log.Fatal(usage)
+		}
+		entities := store.SearchEntities(query)
+		history := store.SearchHistory(query, "", "")
+		if len(entities) == 0 && len(history) == 0 {
+			fmt.Printf("Nothing found for %q.\n", query)
+			return
+		}
+		if len(entities) > 0 {
+			fmt.Println("🧠 Entities:")
+			for _, e := range entities {
+				if e.Line != "" {
+					fmt.Printf("  %s: %s\n", e.Name, e.Line)
+				} else {
+					fmt.Printf("  %s\n", e.Name)
 				}
 			}
 		}
-	}()
+		if len(history) > 0 {
+			fmt.Println("💬 History:")
+			for _, h := range history {
+				fmt.Printf("  [%s] %s\n", h.Date, strings.ReplaceAll(h.Content, "\n", "\n    "))
+			}
+		}
 
-	// Wait for termination signal
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	<-sigChan
+	case "versions":
+		versions := store.ListMemoryVersions()
+		if len(versions) == 0 {
+			fmt.Println("No MEMORY.md versions kept yet.")
+			return
+		}
+		for _, v := range versions {
+			content, _ := store.ReadMemoryVersion(v)
+			fmt.Printf("%s  %6d bytes\n", v, len(content))
+		}
 
-	log.Println("Shutting down Littleclaw...")
-	cancel()
+	case "rollback":
+		versions := store.ListMemoryVersions()
+		if len(versions) == 0 {
+			log.Fatal("❌ No MEMORY.md versions to roll back to.")
+		}
+		version := versions[0]
+		if len(args) > 1 {
+			version = args[1]
+		}
+		if err := store.RestoreMemoryVersion(version); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		fmt.Printf("✅ Restored MEMORY.md from %s. The replaced content was kept as a new version.\n", version)
+
+	default:
+		fmt.Println(usage)
+	}
+}
+
+// editMemory opens MEMORY.md in $EDITOR (vi by default) and saves the result
+// through the store, which keeps the previous content as a version.
+func editMemory(store *memory.Store) {
+	before := store.ReadLongTerm()
+	tmp, err := os.CreateTemp("", "MEMORY-*.md")
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	tmp.Close()
+	if err := os.WriteFile(tmp.Name(), []byte(before), 0600); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	editor := envOr("VISUAL", envOr("EDITOR", "vi"))
+	// $EDITOR may carry arguments, e.g. "code --wait"
+	parts := strings.Fields(editor)
+	cmd := exec.Command(parts[0], append(parts[1:], tmp.Name())...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		log.Fatalf("❌ %s failed: %v (your edit is in %s)", editor, err, tmp.Name())
+	}
+
+	data, err := os.ReadFile(tmp.Name())
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	after := string(data)
+	if after == before {
+		os.Remove(tmp.Name())
+		fmt.Println("No changes.")
+		return
+	}
+	if !utf8.Valid(data) || strings.ContainsRune(after, 0) {
+		log.Fatalf("❌ The edited memory is not valid text; nothing was saved (your edit is in %s)", tmp.Name())
+	}
+	if strings.TrimSpace(after) == "" {
+		fmt.Print("⚠️ The edit empties core memory. Save anyway? (y/N): ")
+		var confirm string
+		fmt.Scanln(&confirm)
+		if confirm != "y" && confirm != "Y" {
+			fmt.Printf("Nothing saved (your edit is in %s).\n", tmp.Name())
+			return
+		}
+	}
+	if err := store.WriteLongTerm(after); err != nil {
+		log.Fatalf("❌ Failed to save MEMORY.md: %v (your edit is in %s)", err, tmp.Name())
+	}
+	os.Remove(tmp.Name())
+	fmt.Printf("✅ Saved MEMORY.md (%d bytes).\n", len(after))
+	if before != "" {
+		fmt.Println("'littleclaw memory rollback' undoes it.")
+	}
+	if budget := agent.CoreBudgetTokens * agent.CharsPerToken; len(after) > budget {
+		fmt.Printf("⚠️ That is over the %d-byte budget; only the start is shown to the agent.\n", budget)
+	}
+}
+
+// runAudit prints per-tool usage from TOOL_AUDIT.jsonl: `littleclaw audit [days]`,
+// or the last shell commands from EXEC_AUDIT.jsonl: `littleclaw audit exec [n]`.
+func runAudit(args []string) {
+	if len(args) > 0 && args[0] == "exec" {
+		runExecAudit(args[1:])
+		return
+	}
+	workspaceDir, err := config.WorkspaceDir()
+	if err != nil {
+		log.Fatalf("Cannot locate workspace: %v", err)
+	}
+
+	days := 7
+	if len(args) > 0 {
+		if days, err = strconv.Atoi(args[0]); err != nil || days <= 0 {
+			log.Fatal("Usage: littleclaw audit [days]")
+		}
+	}
+
+	stats, err := tools.ReadToolStats(workspaceDir, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		log.Fatalf("❌ Failed to read audit log: %v", err)
+	}
+	fmt.Printf("🔎 Tool usage, last %d day(s)\n%s\n", days, tools.FormatToolStats(stats))
+}
+
+// runExecAudit prints the last n (default 20) shell commands the agent ran.
+func runExecAudit(args []string) {
+	baseDir, err := config.Dir()
+	if err != nil {
+		log.Fatalf("Cannot locate profile directory: %v", err)
+	}
+	n := 20
+	if len(args) > 0 {
+		if n, err = strconv.Atoi(args[0]); err != nil || n <= 0 {
+			log.Fatal("Usage: littleclaw audit exec [n]")
+		}
+	}
+	recs, err := tools.ReadExecAudit(filepath.Join(baseDir, tools.ExecAuditFile), n)
+	if err != nil {
+		log.Fatalf("❌ Failed to read exec audit log: %v", err)
+	}
+	fmt.Printf("🔎 Last %d shell command(s)\n%s\n", len(recs), tools.FormatExecRecords(recs))
+}
+
+// newChatProvider creates the chat provider for a provider type: Ollama's
+// native API, or OpenRouter/OpenAI/xAI with an API key. cfg, if not nil,
+// supplies the ollama and xai options.
+func newChatProvider(providerType, apiKey string, cfg *config.AppConfig) (providers.Provider, error) {
+	if cfg == nil {
+		cfg = &config.AppConfig{}
+	}
+	if providerType == "ollama" {
+		return providers.NewOllamaProvider(cfg.Ollama.BaseURL, cfg.Ollama.KeepAlive), nil
+	}
+	if apiKey == "" {
+		return nil, fmt.Errorf("missing API key for %s", providerType)
+	}
+	if providerType == "xai" {
+		return providers.NewXAIProvider(apiKey, xaiOptions(cfg.XAI))
+	}
+	baseURL := "https://openrouter.ai/api/v1"
+	if providerType == "openai" {
+		baseURL = "https://api.openai.com/v1"
+	}
+	return providers.NewOpenAIProvider(providerType, baseURL, apiKey), nil
+}
+
+// xaiOptions maps the xai config section to the provider's options.
+func xaiOptions(c config.XAIConfig) providers.XAIOptions {
+	return providers.XAIOptions{
+		ToolChoice:       c.ToolChoice,
+		Search:           c.Search,
+		SearchSources:    c.SearchSources,
+		MaxSearchResults: c.MaxSearchResults,
+	}
+}
+
+// liveConfigFields are the config.json fields reloadConfig applies without a
+// restart; "agent" only partly (see reloadConfig).
+var liveConfigFields = map[string]bool{
+	"provider_type":          true,
+	"provider_model":         true,
+	"provider_apikey":        true,
+	"telegram_allowed_user":  true,
+	"telegram_allowed_chats": true,
+	"exec_policy":            true,
+	"sandbox":                true,
+	"log_level":              true,
+	"log_format":             true,
+	"log_file":               true,
+	"log_rotation":           true,
+	"agent":                  true,
+	"users":                  true,
+	"rate_limits":            true,
+	"http":                   true,
+	"router":                 true,
+}
+
+// reloadConfig applies a changed config to the running agent: the chat
+// provider and model, the model router, the Telegram allowlist, the exec policy, logging, and
+// the agent loop parameters. Other changes are reported as needing a restart.
+// It returns the config now in effect, keeping old values for changes that
+// could not be applied.
+func reloadConfig(old, cfg *config.AppConfig, nanoCore *agent.NanoCore, tg *telegram.Channel) *config.AppConfig {
+	changed := config.Changed(old, cfg)
+	if len(changed) == 0 {
+		slog.Info("config unchanged")
+		return old
+	}
+	next := *cfg
+	// Old keys may stay in use until a restart, so both sets are redacted
+	redact.SetSecrets(append(old.Secrets(), cfg.Secrets()...)...)
+
+	if old.ProviderType != cfg.ProviderType || old.ProviderModel != cfg.ProviderModel || old.ProviderAPIKey != cfg.ProviderAPIKey || !reflect.DeepEqual(old.XAI, cfg.XAI) || old.Ollama != cfg.Ollama {
+		if provider, err := newChatProvider(cfg.ProviderType, cfg.ProviderAPIKey, cfg); err != nil {
+			slog.Warn("keeping the current provider", "provider", old.ProviderType, "err", err)
+			next.ProviderType, next.ProviderModel, next.ProviderAPIKey = old.ProviderType, old.ProviderModel, old.ProviderAPIKey
+			next.XAI, next.Ollama = old.XAI, old.Ollama
+		} else {
+			nanoCore.SetModel(provider, cfg.ProviderType, cfg.ProviderModel)
+			slog.Info("switched provider", "provider", cfg.ProviderType, "model", cfg.ProviderModel)
+		}
+	}
+
+	if old.TelegramAllowedUser != cfg.TelegramAllowedUser || !reflect.DeepEqual(old.Users, cfg.Users) {
+		if roles, err := userRoles(cfg); err != nil {
+			slog.Warn("keeping the current users", "err", err)
+			next.TelegramAllowedUser, next.Users = old.TelegramAllowedUser, old.Users
+		} else {
+			nanoCore.SetUserRoles(roles)
+			users := cfg.AllowedUsers()
+			tg.SetAllowedUsers(users)
+			tg.SetChatAdmins(cfg.Admins())
+			slog.Info("telegram allowlist updated", "users", len(users), "roles", len(roles.Users))
+		}
+	}
+
+	if !reflect.DeepEqual(old.TelegramAllowedChats, cfg.TelegramAllowedChats) {
+		tg.SetAllowedChats(cfg.TelegramAllowedChats)
+		slog.Info("telegram chat allowlist updated", "chats", len(cfg.TelegramAllowedChats))
+	}
+
+	if !reflect.DeepEqual(old.RateLimits, cfg.RateLimits) || !reflect.DeepEqual(old.Users, cfg.Users) || old.TelegramAllowedUser != cfg.TelegramAllowedUser {
+		nanoCore.SetRateLimits(rateLimits(cfg))
+		slog.Info("rate limits updated")
+	}
+
+	if !reflect.DeepEqual(old.HTTP, cfg.HTTP) {
+		if err := httpclient.Configure(httpOptions(cfg.HTTP)); err != nil {
+			slog.Warn("keeping the current http settings", "err", err)
+			next.HTTP = old.HTTP
+		} else {
+			slog.Info("http transport updated", "proxy", cfg.HTTP.Proxy != "")
+		}
+	}
+
+	if !reflect.DeepEqual(old.Sandbox, cfg.Sandbox) {
+		s := cfg.Sandbox
+		if sandbox, err := tools.NewSandbox(s.Mode, s.NoNetwork, s.Writable); err != nil {
+			slog.Warn("keeping the current sandbox", "err", err)
+			next.Sandbox = old.Sandbox
+		} else {
+			nanoCore.SetSandbox(sandbox)
+			slog.Info("sandbox updated", "mode", s.Mode, "no_network", s.NoNetwork)
+		}
+	}
+
+	if !reflect.DeepEqual(old.ExecPolicy, cfg.ExecPolicy) {
+		p := cfg.ExecPolicy
+		if policy, err := tools.NewExecPolicy(p.Allow, p.Deny, p.AllowlistOnly); err != nil {
+			slog.Warn("keeping the current exec policy", "err", err)
+			next.ExecPolicy = old.ExecPolicy
+		} else {
+			nanoCore.SetExecPolicy(policy)
+			slog.Info("exec policy updated")
+		}
+	}
+
+	if old.LogLevel != cfg.LogLevel || old.LogFormat != cfg.LogFormat || old.LogFile != cfg.LogFile || old.LogRotation != cfg.LogRotation {
+		if err := applyLogConfig(cfg); err != nil {
+			slog.Warn("keeping the current logging settings", "err", err)
+			next.LogLevel, next.LogFormat, next.LogFile, next.LogRotation = old.LogLevel, old.LogFormat, old.LogFile, old.LogRotation
+		} else {
+			slog.Info("logging settings updated", "level", cfg.LogLevel, "format", cfg.LogFormat, "file", cfg.LogFile)
+		}
+	}
+
+	if old.Router != cfg.Router || next.ProviderType != old.ProviderType || next.ProviderAPIKey != old.ProviderAPIKey {
+		if r, err := modelRouter(&next); err != nil {
+			slog.Warn("keeping the current router", "err", err)
+			next.Router = old.Router
+		} else {
+			nanoCore.SetModelRouter(r)
+			slog.Info("model router updated", "model", r.Model)
+		}
+	}
+
+	var restart []string
+	if !reflect.DeepEqual(old.Agent.AgentParamsConfig, cfg.Agent.AgentParamsConfig) || !reflect.DeepEqual(old.Agent.Chats, cfg.Agent.Chats) {
+		nanoCore.SetAgentParams(agentParams(cfg.Agent.AgentParamsConfig))
+		chats := make(map[string]agent.AgentParams, len(cfg.Agent.Chats))
+		for chatID, p := range cfg.Agent.Chats {
+			chats[chatID] = agentParams(p)
+		}
+		nanoCore.ReplaceChatParams(chats)
+		slog.Info("agent parameters updated")
+	}
+	if !reflect.DeepEqual(old.Agent.Roles, cfg.Agent.Roles) || old.Agent.Timezone != cfg.Agent.Timezone || old.Agent.ContextTemplate != cfg.Agent.ContextTemplate {
+		restart = append(restart, "agent (roles, timezone, context_template)")
+	}
+
+	for _, name := range changed {
+		if !liveConfigFields[name] {
+			restart = append(restart, name)
+		}
+	}
+	if len(restart) > 0 {
+		slog.Warn("restart to apply config changes", "fields", strings.Join(restart, ","))
+	}
+	return &next
+}
+
+// runDoctor checks the config, provider, Telegram token, transcription setup,
+// optional binaries, and workspace: `littleclaw doctor`. It exits non-zero if
+// any check fails.
+func runDoctor() {
+	cfg, cfgErr := config.Load()
+	if cfg != nil {
+		applyOverrides(cfg)
+	}
+	path, err := config.Path()
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	workspaceDir, err := config.WorkspaceDir()
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	fmt.Printf("🩺 Checking %s\n\n", path)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	results := doctor.New(cfg, cfgErr, path, workspaceDir).Run(ctx)
+	fmt.Print(doctor.Format(results))
+	if doctor.Failed(results) {
+		fmt.Println("\nSome checks failed; see the hints above.")
+		os.Exit(1)
+	}
+	fmt.Println("\nAll essential checks passed.")
+}
+
+// runStatus asks the running agent for its state over the control socket:
+// `littleclaw status`. It exits non-zero when the agent is not running.
+func runStatus() {
+	dir, err := config.Dir()
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	status, err := control.NewClient(filepath.Join(dir, control.SocketName)).Status(ctx)
+	if errors.Is(err, control.ErrNotRunning) {
+		fmt.Println("🔴 Littleclaw is not running.")
+		os.Exit(1)
+	}
+	if err != nil {
+		log.Fatalf("❌ Failed to get status: %v", err)
+	}
+	fmt.Print(status.Format(time.Now()))
+}
+
+// runAsk answers one question without starting any channel: `littleclaw ask
+// "question"`, or the question on stdin. The agent runs with its tools and
+// memory; the final reply goes to stdout and status and tool messages to stderr.
+func runAsk(args []string) {
+	question := strings.TrimSpace(strings.Join(args, " "))
+	stdinFree := question != ""
+	if question == "" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			log.Fatalf("❌ Failed to read the question from stdin: %v", err)
+		}
+		question = strings.TrimSpace(string(data))
+	}
+	if question == "" {
+		fmt.Fprintln(os.Stderr, `Usage: littleclaw ask "question"  (or pipe the question on stdin)`)
+		os.Exit(2)
+	}
+	// Keep stderr to warnings unless a log level was asked for
+	if opts.logLevel == "" {
+		logging.SetLevel(slog.LevelWarn)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("❌ Could not load config: %v. Run 'littleclaw configure' first.", err)
+	}
+	applyOverrides(cfg)
+	workspace, err := config.WorkspaceDir()
+	if err != nil {
+		log.Fatalf("❌ Cannot locate workspace: %v", err)
+	}
+	provider, err := newChatProvider(cfg.ProviderType, cfg.ProviderAPIKey, cfg)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	msgBus := bus.NewMessageBus()
+	nanoCore, err := agent.NewNanoCore(provider, cfg.ProviderType, cfg.ProviderModel, workspace, msgBus, cfg.TavilyAPIKey)
+	if err != nil {
+		log.Fatalf("❌ Failed to initialize Agent Core: %v", err)
+	}
+	configureCore(cfg, nanoCore, provider)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		nanoCore.RunAgentLoop(ctx, bus.InboundMessage{Channel: "cli", ChatID: "cli", SenderID: "cli", SenderName: os.Getenv("USER"), Content: question})
+	}()
+
+	// Every message but the last is a status or tool message; hold each one
+	// until the next arrives.
+	var held *bus.OutboundMessage
+	show := func(w io.Writer, m *bus.OutboundMessage) {
+		if m.Content != "" {
+			fmt.Fprintln(w, m.Content)
+		}
+		for _, f := range m.Files {
+			fmt.Fprintln(w, "📎 "+filepath.Join(workspace, f))
+		}
+	}
+	handle := func(m bus.OutboundMessage) {
+		if m.ApprovalID != "" {
+			fmt.Fprintln(os.Stderr, m.Content)
+			approved := false
+			if fi, err := os.Stdin.Stat(); err == nil && stdinFree && fi.Mode()&os.ModeCharDevice != 0 {
+				_, err := (&promptui.Prompt{Label: "Approve", IsConfirm: true}).Run()
+				approved = err == nil
+			} else {
+				fmt.Fprintln(os.Stderr, "⚠️ No terminal to approve on; denied.")
+			}
+			nanoCore.ResolveApproval(bus.ApprovalDecision{ID: m.ApprovalID, Approved: approved, SenderID: "cli"})
+			return
+		}
+		if held != nil {
+			show(os.Stderr, held)
+		}
+		held = &m
+	}
+	for running := true; running; {
+		select {
+		case m := <-msgBus.Outbound:
+			handle(m)
+		case <-done:
+			running = false
+		}
+	}
+	for len(msgBus.Outbound) > 0 {
+		handle(<-msgBus.Outbound)
+	}
+
+	if held == nil {
+		log.Fatal("❌ No answer. Run 'littleclaw doctor' to check the provider.")
+	}
+	show(os.Stdout, held)
+}
+
+// runCron manages scheduled jobs from the terminal: `littleclaw cron list |
+// add [flags] <schedule> <command> | remove|pause|resume|run <id>`. Changes go
+// through the running agent's control socket so its scheduler stays in sync;
+// when it is not running, CRON.json is edited directly.
+func runCron(args []string) {
+	usage := "Usage: littleclaw cron list | add [--label L] [--type shell|agent|message] [--chat ID] [--silent] [--once] <schedule> <command> | remove|pause|resume|run <id>"
+	if len(args) == 0 {
+		fmt.Println(usage)
+		return
+	}
+	dir, err := config.Dir()
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	client := control.NewClient(filepath.Join(dir, control.SocketName))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// offline returns the scheduler backed by CRON.json, for when the agent is not running.
+	offline := func() *agent.CronService {
+		workspace, err := config.WorkspaceDir()
+		if err != nil {
+			log.Fatalf("Cannot locate workspace: %v", err)
+		}
+		if err := os.MkdirAll(workspace, 0755); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		cs := agent.NewCronService(workspace, nil, nil)
+		if err := cs.Load(); err != nil && !os.IsNotExist(err) {
+			log.Fatalf("❌ Failed to read CRON.json: %v", err)
+		}
+		return cs
+	}
+
+	switch args[0] {
+	case "list":
+		var jobs []*agent.CronJob
+		err := client.Do(ctx, http.MethodGet, "/cron", nil, &jobs)
+		if errors.Is(err, control.ErrNotRunning) {
+			jobs, err = sortedCronJobs(offline()), nil
+		}
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		printCronJobs(jobs)
+
+	case "add":
+		fs := flag.NewFlagSet("cron add", flag.ExitOnError)
+		job := &agent.CronJob{Channel: "telegram"}
+		fs.StringVar(&job.Label, "label", "", "name for the job (default: the command)")
+		fs.StringVar(&job.Type, "type", agent.CronJobShell, "shell, agent (an instruction for the agent), or message (a reminder)")
+		fs.StringVar(&job.ChatID, "chat", "", "Telegram chat to report to (default: telegram_allowed_user)")
+		fs.BoolVar(&job.Silent, "silent", false, "log the result instead of messaging it")
+		fs.BoolVar(&job.Once, "once", false, "remove the job after it runs")
+		fs.IntVar(&job.Timeout, "timeout", 0, "seconds a run may take (default: cron.timeout_seconds)")
+		fs.Parse(args[1:])
+		if fs.NArg() < 2 {
+			log.Fatal(usage)
+		}
+		job.Schedule, job.Command = fs.Arg(0), strings.Join(fs.Args()[1:], " ")
+		if job.Label == "" {
+			job.Label = truncateRunes(job.Command, 40)
+		}
+		cfg, cfgErr := config.Load()
+		if job.ChatID == "" && cfgErr == nil {
+			job.ChatID = cfg.TelegramAllowedUser
+		}
+		if job.ChatID == "" && !job.Silent {
+			log.Fatal("❌ No chat to report to: pass --chat or --silent, or set telegram_allowed_user.")
+		}
+
+		var added agent.CronJob
+		err := client.Do(ctx, http.MethodPost, "/cron", job, &added)
+		if errors.Is(err, control.ErrNotRunning) {
+			policy := tools.DefaultExecPolicy()
+			if cfgErr == nil {
+				p := cfg.ExecPolicy
+				if policy, err = tools.NewExecPolicy(p.Allow, p.Deny, p.AllowlistOnly); err != nil {
+					log.Fatalf("Invalid exec_policy configuration: %v", err)
+				}
+			}
+			if err = agent.PrepareCronJob(job, policy.Check); err == nil {
+				err = offline().AddJob(job)
+			}
+			added = *job
+		}
+		if err != nil {
+			log.Fatalf("❌ Failed to add cron job: %v", err)
+		}
+		fmt.Printf("✅ Added %s (ID: %s, schedule: %s)\n", added.Label, added.ID, added.Schedule)
+
+	case "remove", "pause", "resume", "run":
+		if len(args) < 2 {
+			log.Fatal(usage)
+		}
+		var job agent.CronJob
+		err := client.Do(ctx, http.MethodPost, "/cron/"+url.PathEscape(args[1])+"/"+args[0], nil, &job)
+		if errors.Is(err, control.ErrNotRunning) {
+			if args[0] == "run" {
+				log.Fatal("❌ Littleclaw is not running; start it to run a job now.")
+			}
+			var j *agent.CronJob
+			if j, err = cronAction(offline(), args[1], args[0]); err == nil {
+				job = *j
+			}
+		}
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		done := map[string]string{"remove": "Removed", "pause": "Paused", "resume": "Resumed", "run": "Started"}[args[0]]
+		fmt.Printf("✅ %s %s (ID: %s)\n", done, job.Label, job.ID)
+
+	default:
+		fmt.Println(usage)
+	}
+}
+
+// handleCronControl serves `littleclaw cron` on the control socket.
+func handleCronControl(ctrl *control.Server, nanoCore *agent.NanoCore) {
+	cs := nanoCore.CronService()
+	ctrl.HandleJSON("GET /cron", func(*http.Request) (any, error) {
+		return sortedCronJobs(cs), nil
+	})
+	ctrl.HandleJSON("POST /cron", func(r *http.Request) (any, error) {
+		var job agent.CronJob
+		if err := json.NewDecoder(r.Body).Decode(&job); err != nil {
+			return nil, err
+		}
+		if err := agent.PrepareCronJob(&job, nanoCore.CheckCommand); err != nil {
+			return nil, err
+		}
+		return &job, cs.AddJob(&job)
+	})
+	ctrl.HandleJSON("POST /cron/{id}/{action}", func(r *http.Request) (any, error) {
+		return cronAction(cs, r.PathValue("id"), r.PathValue("action"))
+	})
+}
+
+// cronAction removes, pauses, resumes, or runs (in the background) the job
+// with the given ID or label, and returns it.
+func cronAction(cs *agent.CronService, idOrLabel, action string) (*agent.CronJob, error) {
+	job := cs.FindJob(idOrLabel)
+	if job == nil {
+		return nil, fmt.Errorf("cron job %q not found", idOrLabel)
+	}
+	var err error
+	switch action {
+	case "remove":
+		err = cs.RemoveJob(job.ID)
+	case "pause":
+		err = cs.PauseJob(job.ID)
+	case "resume":
+		err = cs.ResumeJob(job.ID)
+	case "run":
+		go cs.RunJobNow(job.ID)
+	default:
+		err = fmt.Errorf("unknown cron action %q", action)
+	}
+	return job, err
+}
+
+// sortedCronJobs returns the jobs ordered by ID.
+func sortedCronJobs(cs *agent.CronService) []*agent.CronJob {
+	jobs := cs.ListJobs()
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].ID < jobs[j].ID })
+	return jobs
+}
+
+// printCronJobs prints jobs as a table.
+func printCronJobs(jobs []*agent.CronJob) {
+	if len(jobs) == 0 {
+		fmt.Println("No cron jobs scheduled.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSTATUS\tSCHEDULE\tNEXT RUN\tLABEL")
+	for _, j := range jobs {
+		status := j.State.LastStatus
+		if status == "" {
+			status = "never run"
+		}
+		if !j.IsEnabled() {
+			status = "paused"
+		}
+		next := "-"
+		if j.State.NextRunAtMs > 0 {
+			next = time.UnixMilli(j.State.NextRunAtMs).Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", j.ID, status, j.Schedule, next, j.Label)
+	}
+	w.Flush()
+}
+
+// truncateRunes shortens s to at most n runes.
+func truncateRunes(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
+
+// runService installs and controls the agent as a systemd user unit (Linux)
+// or launchd agent (macOS): `littleclaw service install|uninstall|start|stop`.
+// The service runs this binary with the same global flags.
+func runService(args []string) {
+	usage := "Usage: littleclaw service install|uninstall|start|stop"
+	if len(args) == 0 {
+		fmt.Println(usage)
+		return
+	}
+
+	bin, err := os.Executable()
+	if err == nil {
+		bin, err = filepath.EvalSymlinks(bin)
+	}
+	if err != nil {
+		log.Fatalf("❌ Cannot locate the littleclaw binary: %v", err)
+	}
+	if strings.Contains(bin, "go-build") {
+		log.Fatal("❌ This is a temporary 'go run' binary. Build littleclaw first (make build) and run the service command from the built binary.")
+	}
+	dir, err := config.Dir()
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	name := "littleclaw"
+	var flags []string
+	if p := config.Profile(); p != "" {
+		name += "-" + p
+		flags = append(flags, "--profile", p)
+	}
+	if opts.config != "" {
+		path, _ := config.Path()
+		flags = append(flags, "--config", path)
+	}
+	if opts.workspace != "" {
+		ws, _ := config.WorkspaceDir()
+		flags = append(flags, "--workspace", ws)
+	}
+	for flag, v := range map[string]string{"--log-level": opts.logLevel, "--model": opts.model, "--provider": opts.provider} {
+		if v != "" {
+			flags = append(flags, flag, v)
+		}
+	}
+
+	mgr, err := service.New(service.Spec{Name: name, Binary: bin, Args: flags, WorkDir: dir, LogFile: filepath.Join(dir, "littleclaw.log")})
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	switch args[0] {
+	case "install":
+		path, err := mgr.Install()
+		if err != nil {
+			log.Fatalf("❌ Failed to install the service: %v", err)
+		}
+		fmt.Printf("✅ Installed %s\nStart it now with 'littleclaw service start'; it also starts at login.\n", path)
+		if runtime.GOOS == "linux" {
+			fmt.Println("To keep it running while you are logged out: loginctl enable-linger $USER")
+		}
+	case "uninstall":
+		if err := mgr.Uninstall(); err != nil {
+			log.Fatalf("❌ Failed to uninstall the service: %v", err)
+		}
+		fmt.Println("✅ Service removed.")
+	case "start":
+		if err := mgr.Start(); err != nil {
+			log.Fatalf("❌ Failed to start the service: %v", err)
+		}
+		fmt.Println("✅ Service started. Check on it with 'littleclaw status'.")
+	case "stop":
+		if err := mgr.Stop(); err != nil {
+			log.Fatalf("❌ Failed to stop the service: %v", err)
+		}
+		fmt.Println("✅ Service stopped.")
+	default:
+		fmt.Println(usage)
+	}
+}
+
+// startTelemetry starts exporting OpenTelemetry spans when an OTLP endpoint is
+// configured; $OTEL_EXPORTER_OTLP_ENDPOINT and $OTEL_SERVICE_NAME override the
+// config. The returned function flushes the spans still queued.
+func startTelemetry(cfg *config.AppConfig) func() {
+	var tc config.TelemetryConfig
+	if cfg != nil {
+		tc = cfg.Telemetry
+	}
+	if v := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); v != "" {
+		tc.OTLPEndpoint = v
+	}
+	if v := os.Getenv("OTEL_SERVICE_NAME"); v != "" {
+		tc.ServiceName = v
+	}
+	shutdown, err := telemetry.Setup(telemetry.Options{Endpoint: tc.OTLPEndpoint, Headers: tc.Headers, ServiceName: tc.ServiceName})
+	if err != nil {
+		slog.Warn("OpenTelemetry tracing disabled", "err", err)
+		return func() {}
+	}
+	if tc.OTLPEndpoint != "" {
+		slog.Info("exporting OpenTelemetry traces", "endpoint", tc.OTLPEndpoint)
+	}
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			slog.Warn("failed to flush OpenTelemetry spans", "err", err)
+		}
+	}
+}
+
+// httpOptions converts the http config section to transport options.
+func httpOptions(c config.HTTPConfig) httpclient.Options {
+	return httpclient.Options{
+		Proxy:               c.Proxy,
+		DialTimeout:         time.Duration(c.DialTimeoutSeconds) * time.Second,
+		TLSHandshakeTimeout: time.Duration(c.TLSTimeoutSeconds) * time.Second,
+		IdleConnTimeout:     time.Duration(c.IdleTimeoutSeconds) * time.Second,
+		MaxIdleConnsPerHost: c.MaxIdleConnsPerHost,
+	}
+}
+
+// newHTTPAuth builds the API key check for the HTTP endpoints from http_auth.
+func newHTTPAuth(c config.HTTPAuthConfig) (*httpauth.Authenticator, error) {
+	if len(c.APIKeys) == 0 {
+		return nil, errors.New("no api_keys configured")
+	}
+	keys := make([]httpauth.Key, len(c.APIKeys))
+	for i, k := range c.APIKeys {
+		keys[i] = httpauth.Key{Name: k.Name, Secret: k.Key, RequestsPerMinute: k.RequestsPerMinute}
+	}
+	return httpauth.New(keys)
+}
+
+// newHealthChecker sets up the /healthz checks: Telegram and the provider
+// (probed at most every CheckSeconds with the doctor's checks, using the
+// config current returns) and the message bus, which fails when a queue is
+// full because nothing is draining it.
+func newHealthChecker(hc config.HealthConfig, current func() *config.AppConfig, workspace string, msgBus *bus.MessageBus) *health.Checker {
+	ttl := time.Duration(hc.CheckSeconds) * time.Second
+	if ttl <= 0 {
+		ttl = time.Minute
+	}
+	firstFailure := func(results []doctor.Result) error {
+		for _, r := range results {
+			if r.Status == doctor.Fail {
+				return errors.New(r.Detail)
+			}
+		}
+		return nil
+	}
+
+	checker := health.New()
+	checker.Add(health.Check{Name: "telegram", Critical: true, TTL: ttl, Probe: func(ctx context.Context) error {
+		return firstFailure([]doctor.Result{doctor.New(current(), nil, "", workspace).CheckTelegram(ctx)})
+	}})
+	checker.Add(health.Check{Name: "provider", TTL: ttl, Probe: func(ctx context.Context) error {
+		return firstFailure(doctor.New(current(), nil, "", workspace).CheckProvider(ctx))
+	}})
+	checker.Add(health.Check{Name: "bus", Critical: true, Probe: func(context.Context) error {
+		if len(msgBus.Inbound) == cap(msgBus.Inbound) {
+			return fmt.Errorf("inbound queue full (%d)", cap(msgBus.Inbound))
+		}
+		if len(msgBus.Outbound) == cap(msgBus.Outbound) {
+			return fmt.Errorf("outbound queue full (%d)", cap(msgBus.Outbound))
+		}
+		return nil
+	}})
+	checker.SetQueues(func() map[string]int {
+		return map[string]int{"inbound": len(msgBus.Inbound), "outbound": len(msgBus.Outbound)}
+	})
+	return checker
+}
+
+// configureCore applies the optional agent features in cfg (nil with the
+// legacy .env setup) to nanoCore.
+func configureCore(cfg *config.AppConfig, nanoCore *agent.NanoCore, provider providers.Provider) {
+	// Keep the configured keys out of logs, history, and tool results
+	if cfg != nil {
+		redact.SetSecrets(cfg.Secrets()...)
+	}
+	// Pool outbound connections and route them through the configured proxy
+	if cfg != nil && cfg.HTTP != (config.HTTPConfig{}) {
+		if err := httpclient.Configure(httpOptions(cfg.HTTP)); err != nil {
+			fatal("invalid http configuration", "err", err)
+		}
+		if cfg.HTTP.Proxy != "" {
+			slog.Info("outbound http goes through a proxy")
+		}
+	}
+	// Apply the configured exec allow/deny policy
+	if cfg != nil {
+		p := cfg.ExecPolicy
+		policy, err := tools.NewExecPolicy(p.Allow, p.Deny, p.AllowlistOnly)
+		if err != nil {
+			fatal("invalid exec_policy configuration", "err", err)
+		}
+		nanoCore.SetExecPolicy(policy)
+		if p.AllowlistOnly {
+			slog.Info("exec policy: allowlist-only mode", "allow_patterns", len(p.Allow))
+		}
+	}
+	// Give each configured user their permission tier
+	if cfg != nil {
+		roles, err := userRoles(cfg)
+		if err != nil {
+			fatal("invalid users configuration", "err", err)
+		}
+		nanoCore.SetUserRoles(roles)
+		if len(roles.Users) > 0 {
+			slog.Info("user roles enabled", "users", len(roles.Users))
+		}
+	}
+	// Keep one busy user from draining the budget or the workers
+	if cfg != nil {
+		if r := cfg.RateLimits; r.MessagesPerMinute > 0 || r.ConcurrentRuns > 0 || r.DailyTokens > 0 {
+			p := rateLimits(cfg)
+			nanoCore.SetRateLimits(p)
+			slog.Info("rate limits enabled", "messages_per_minute", p.MessagesPerMinute, "concurrent_runs", p.ConcurrentRuns, "daily_tokens", p.DailyTokens, "exempt", len(p.Exempt))
+		}
+	}
+	// Every shell command is logged outside the workspace, where tools cannot edit it
+	if baseDir, err := config.Dir(); err == nil {
+		nanoCore.SetExecAuditLog(filepath.Join(baseDir, tools.ExecAuditFile))
+	}
+	// Run shell commands under bubblewrap or firejail if configured
+	if cfg != nil {
+		s := cfg.Sandbox
+		sandbox, err := tools.NewSandbox(s.Mode, s.NoNetwork, s.Writable)
+		if err != nil {
+			fatal("invalid sandbox configuration", "err", err)
+		}
+		nanoCore.SetSandbox(sandbox)
+		if sandbox != nil {
+			slog.Info("exec sandbox enabled", "mode", sandbox.Mode, "no_network", sandbox.NoNetwork)
+		}
+	}
+
+	// Let the admin send one message to several chats
+	if cfg != nil && len(cfg.Broadcast.Chats) > 0 {
+		admins := cfg.Broadcast.Admins
+		if len(admins) == 0 && cfg.TelegramAllowedUser != "" {
+			admins = []string{cfg.TelegramAllowedUser}
+		}
+		nanoCore.EnableBroadcast(agent.BroadcastPolicy{Admins: admins, Chats: cfg.Broadcast.Chats})
+		slog.Info("broadcast enabled", "chats", len(cfg.Broadcast.Chats), "admins", len(admins))
+	}
+
+	// Write a trace file for every agent run
+	if cfg != nil && cfg.Traces.Enabled {
+		nanoCore.EnableTraces(agent.TracePolicy{
+			MaxFiles: cfg.Traces.MaxFiles,
+			MaxAge:   time.Duration(cfg.Traces.MaxAgeDays) * 24 * time.Hour,
+		})
+		slog.Info("run traces enabled", "dir", agent.TraceDir)
+	}
+
+	// Apply tool call timeouts
+	if cfg != nil {
+		perTool := make(map[string]time.Duration, len(cfg.Timeouts.PerTool))
+		for name, secs := range cfg.Timeouts.PerTool {
+			perTool[name] = time.Duration(secs) * time.Second
+		}
+		nanoCore.SetToolTimeouts(time.Duration(cfg.Timeouts.DefaultSeconds)*time.Second, perTool)
+	}
+
+	// Bound how long a cron job run may take
+	if cfg != nil && cfg.Cron.TimeoutSeconds > 0 {
+		nanoCore.SetCronJobTimeout(time.Duration(cfg.Cron.TimeoutSeconds) * time.Second)
+	}
+
+	// Bound the per-chat in-memory session
+	if cfg != nil {
+		nanoCore.SetSessionLimits(time.Duration(cfg.Session.TTLMinutes)*time.Minute, cfg.Session.MaxMessages)
+	}
+
+	// Tune the agent loop, globally and per chat
+	if cfg != nil {
+		nanoCore.SetAgentParams(agentParams(cfg.Agent.AgentParamsConfig))
+		for chatID, p := range cfg.Agent.Chats {
+			nanoCore.SetChatParams(chatID, agentParams(p))
+		}
+	}
+
+	// Date, time, and chat details in the system prompt
+	if cfg != nil && cfg.Agent.Timezone != "" {
+		if loc, err := time.LoadLocation(cfg.Agent.Timezone); err != nil {
+			slog.Warn("ignoring agent.timezone", "err", err)
+		} else {
+			nanoCore.SetTimezone(loc)
+		}
+	}
+	if cfg != nil && cfg.Agent.ContextTemplate != "" {
+		if err := nanoCore.SetContextTemplate(cfg.Agent.ContextTemplate); err != nil {
+			slog.Warn("ignoring agent.context_template", "err", err)
+		}
+	}
+
+	// Named specialists for the delegate tool
+	if cfg != nil && len(cfg.Agent.Roles) > 0 {
+		nanoCore.SetAgentRoles(agentRoles(cfg, provider))
+		slog.Info("delegation enabled", "roles", len(cfg.Agent.Roles))
+	}
+
+	// Only send the most relevant tool definitions when a cap is configured
+	if cfg != nil && cfg.Tools.MaxTools > 0 {
+		nanoCore.SetToolSelection(cfg.Tools.MaxTools)
+	}
+
+	// Enable human-in-the-loop approval for risky commands
+	if cfg != nil && cfg.Approval.Enabled {
+		timeout := time.Duration(cfg.Approval.TimeoutSeconds) * time.Second
+		if err := nanoCore.EnableApprovals(cfg.Approval.Patterns, timeout); err != nil {
+			fatal("invalid approval configuration", "err", err)
+		}
+		slog.Info("approval mode enabled for risky commands")
+	}
+
+	// Register configured database connections for sql_query
+	if cfg != nil && len(cfg.Databases) > 0 {
+		conns := make(map[string]tools.SQLConnection, len(cfg.Databases))
+		for name, db := range cfg.Databases {
+			conns[name] = tools.SQLConnection{Driver: db.Driver, DSN: db.DSN, AllowWrites: db.AllowWrites, MaxRows: db.MaxRows}
+		}
+		if err := nanoCore.SetSQLConnections(conns); err != nil {
+			fatal("invalid databases configuration", "err", err)
+		}
+		slog.Info("sql_query enabled", "databases", len(conns))
+	}
+
+	// Connect the calendar backend for list_events / create_event / find_free_slot
+	if cfg != nil {
+		cal, err := newCalendarClient(cfg.Calendar)
+		if err != nil {
+			fatal("invalid calendar configuration", "err", err)
+		}
+		if cal != nil {
+			nanoCore.SetCalendar(cal)
+			slog.Info("calendar tools enabled", "provider", cfg.Calendar.Provider)
+		}
+	}
+
+	// Select the get_weather backend
+	if cfg != nil {
+		wp, err := newWeatherProvider(cfg.Weather)
+		if err != nil {
+			fatal("invalid weather configuration", "err", err)
+		}
+		nanoCore.SetWeather(wp)
+	}
+
+	// Route analyze_image to a vision-capable model
+	if cfg != nil && cfg.Vision.Model != "" {
+		visionProvider, err := newVisionProvider(cfg, provider)
+		if err != nil {
+			fatal("invalid vision configuration", "err", err)
+		}
+		nanoCore.SetVisionModel(visionProvider, cfg.Vision.Model)
+		slog.Info("analyze_image enabled", "model", cfg.Vision.Model, "provider", visionProvider.Name())
+	}
+
+	// Search memory by meaning through Postgres with pgvector
+	if cfg != nil && cfg.MemoryIndex.DSN != "" {
+		ix, err := memoryIndex(cfg, provider)
+		if err != nil {
+			fatal("invalid memory_index configuration", "err", err)
+		}
+		nanoCore.EnableMemoryIndex(ix)
+		slog.Info("semantic_search enabled", "table", ix.Table)
+	}
+
+	// Send simple messages to a cheaper model
+	if cfg != nil && cfg.Router.Model != "" {
+		r, err := modelRouter(cfg)
+		if err != nil {
+			fatal("invalid router configuration", "err", err)
+		}
+		nanoCore.SetModelRouter(r)
+		slog.Info("model router enabled", "model", r.Model, "classifier", cfg.Router.Classifier)
+	}
+
+	// Screen and clipboard access for workstation installs
+	if cfg != nil && cfg.Desktop.Enabled {
+		nanoCore.EnableDesktopTools()
+		slog.Info("desktop tools enabled (screenshot, clipboard)")
+	}
+}
+
+// runProfiles lists the named profiles: `littleclaw profiles`.
+func runProfiles() {
+	names, err := config.Profiles()
+	if err != nil {
+		log.Fatalf("❌ Failed to list profiles: %v", err)
+	}
+	fmt.Println("default  (~/.littleclaw)")
+	for _, n := range names {
+		fmt.Printf("%s  (~/.littleclaw/profiles/%s)\n", n, n)
+	}
+	fmt.Println("Create one with: littleclaw --profile <name> configure")
+}
+
+func main() {
+	if dispatch(parseGlobalFlags(os.Args[1:])) {
+		return
+	}
+
+	printLogo()
+	fmt.Println("⚙️  Starting Littleclaw Core Systems...")
+	startedAt := time.Now()
+
+	// 0. Try loading from Config File first
+	cfg, err := config.Load()
+	if err != nil {
+		// Fallback to testing ENV variables so we don't break backward compatibility instantly
+		if err := godotenv.Load(); err != nil {
+			fatal("could not load config.json or .env; run 'littleclaw configure'", "err", err)
+		}
+		slog.Warn("using legacy .env configuration; consider running 'littleclaw configure'")
+	} else {
+		applyOverrides(cfg)
+		if err := applyLogConfig(cfg); err != nil {
+			fatal("invalid logging configuration", "err", err)
+		}
+		if logFile != nil {
+			fmt.Printf("📝 Logging to %s\n", logFile.Path())
+		}
+	}
+
+	// 1. Setup Data Paths (per profile, see config.SetProfile)
+	baseDir, err := config.Dir()
+	if err != nil {
+		fatal("cannot locate littleclaw directory", "err", err)
+	}
+	workspace, err := config.WorkspaceDir()
+	if err != nil {
+		fatal("cannot locate workspace", "err", err)
+	}
+	if p := config.Profile(); p != "" {
+		slog.Info("using profile", "profile", p, "dir", baseDir)
+	}
+
+	// Create PID file
+	pidFile := filepath.Join(baseDir, "littleclaw.pid")
+	if err := os.WriteFile(pidFile, []byte(fmt.Sprintf("%d", os.Getpid())), 0644); err != nil {
+		fatal("failed to write PID file", "err", err)
+	}
+	defer os.Remove(pidFile) // Ensure PID file is removed on exit
+
+
+	// 2. Load Configuration
+	var tgToken, tgAllowedUser, providerType, modelName, providerAPIKey string
+
+	if cfg != nil {
+		// Read from config.json
+		tgToken = cfg.TelegramToken
+		tgAllowedUser = cfg.TelegramAllowedUser
+		providerType = cfg.ProviderType
+		modelName = cfg.ProviderModel
+		providerAPIKey = cfg.ProviderAPIKey
+	} else {
+		// Legacy .env fallback
+		tgToken = os.Getenv("TELEGRAM_BOT_TOKEN")
+		tgAllowedUser = os.Getenv("TELEGRAM_ALLOWED_USER_ID")
+		providerType = os.Getenv("LLM_PROVIDER")
+		if providerType == "" {
+			providerType = "openrouter" // Default
+		}
+
+		if providerType == "ollama" {
+			modelName = os.Getenv("OLLAMA_MODEL")
+			if modelName == "" {
+				modelName = "llama3.2"
+			}
+		} else {
+			providerAPIKey = os.Getenv("OPENROUTER_API_KEY")
+			modelName = "gpt-4o-mini"
+		}
+		if opts.provider != "" {
+			providerType = opts.provider
+		}
+		if opts.model != "" {
+			modelName = opts.model
+		}
+	}
+
+	if tgToken == "" {
+		fatal("missing Telegram token; run 'littleclaw configure'")
+	}
+
+	slog.Info("initializing provider", "provider", providerType, "model", modelName)
+	provider, err := newChatProvider(providerType, providerAPIKey, cfg)
+	if err != nil {
+		fatal("cannot create provider; run 'littleclaw configure'", "err", err)
+	}
+
+	if tgToken == "" {
+		fatal("missing TELEGRAM_BOT_TOKEN; export it to continue")
+	}
+
+	allowedUsers := []string{}
+	if cfg != nil {
+		allowedUsers = append(allowedUsers, cfg.AllowedUsers()...)
+	} else if tgAllowedUser != "" {
+		allowedUsers = append(allowedUsers, tgAllowedUser)
+	}
+
+	// 3. Initialize Core Infrastructure
+	msgBus := bus.NewMessageBus()
+
+	// Collect optional feature keys
+	tavilyAPIKey := ""
+	if cfg != nil {
+		tavilyAPIKey = cfg.TavilyAPIKey
+	}
+
+	// Initialize the NanoCore Agent Loop
+	nanoCore, err := agent.NewNanoCore(provider, providerType, modelName, workspace, msgBus, tavilyAPIKey)
+	if err != nil {
+		fatal("failed to initialize agent core", "err", err)
+	}
+
+	configureCore(cfg, nanoCore, provider)
+
+	// Initialize the Telegram Channel
+	tgChannel := telegram.NewChannel(tgToken, allowedUsers, msgBus)
+	// Group chats must be listed or approved with /allowchat
+	if cfg != nil {
+		tgChannel.SetAllowedChats(cfg.TelegramAllowedChats)
+		tgChannel.SetChatAdmins(cfg.Admins())
+	} else {
+		tgChannel.SetAllowedChats(strings.Split(os.Getenv("TELEGRAM_ALLOWED_CHATS"), ","))
+		tgChannel.SetChatAdmins(allowedUsers)
+	}
+	if err := tgChannel.LoadChatOptIns(filepath.Join(baseDir, telegram.ChatsFile)); err != nil {
+		fatal("failed to read approved chats", "err", err)
+	}
+	// Received videos and GIFs are saved to the workspace inbox
+	tgChannel.SetWorkspace(workspace)
+	// The command menu and descriptions are set from the persona unless the
+	// config leaves them to BotFather
+	var profileCfg config.BotProfileConfig
+	if cfg != nil {
+		profileCfg = cfg.BotProfile
+	}
+	profile := botProfile(profileCfg, nanoCore.MemoryStore().ReadPersona().WithDefaults().Name)
+	if err := profile.Validate(); err != nil && !profileCfg.Disabled {
+		fatal("invalid bot profile configuration", "err", err)
+	}
+
+	// Initialize Transcription Provider if configured
+	transcription := config.TranscriptionFromEnv()
+	if cfg != nil {
+		transcription = cfg.TranscriptionSettings()
+	}
+	transcriber, err := newTranscriber(transcription)
+	if err != nil {
+		fatal("invalid transcription configuration", "err", err)
+	}
+	if transcriber != nil {
+		slog.Info("initializing transcription provider", "provider", transcription.Provider)
+		tgChannel.SetTranscriptionProvider(transcriber)
+	}
+
+	// Initialize the Background Heartbeat (Memory Janitor & Cron)
+	// 5-minute default interval — the dirty-flag check in the heartbeat means it only
+	// actually runs LLM consolidation when new history has been appended.
+	hbInterval := agent.DefaultHeartbeatInterval
+	hbEnabled := true
+	if cfg != nil {
+		if cfg.Heartbeat.IntervalMinutes > 0 {
+			hbInterval = time.Duration(cfg.Heartbeat.IntervalMinutes) * time.Minute
+		}
+		if cfg.Heartbeat.Enabled != nil {
+			hbEnabled = *cfg.Heartbeat.Enabled
+		}
+		if cfg.Heartbeat.QuietStart != "" || cfg.Heartbeat.QuietEnd != "" {
+			if quiet, err := agent.ParseQuietHours(cfg.Heartbeat.QuietStart, cfg.Heartbeat.QuietEnd); err != nil {
+				slog.Warn("ignoring heartbeat quiet hours", "err", err)
+			} else {
+				nanoCore.SetQuietHours(quiet)
+				slog.Info("quiet hours set; no background LLM calls", "quiet_hours", quiet)
+			}
+		}
+	}
+	hb := agent.NewHeartbeat(nanoCore, hbInterval)
+	if cfg != nil {
+		for _, t := range cfg.Heartbeat.Tasks {
+			every := time.Duration(t.EveryMinutes) * time.Minute
+			if err := hb.RegisterPrompt(t.Name, every, t.Prompt, t.Notify); err != nil {
+				slog.Warn("skipping heartbeat task", "task", t.Name, "err", err)
+			}
+		}
+		if fu := cfg.Heartbeat.FollowUps; fu.Enabled {
+			hb.EnableFollowUps(agent.FollowUpPolicy{
+				Every:     time.Duration(fu.EveryMinutes) * time.Minute,
+				MaxPerDay: fu.MaxPerDay,
+				MinGap:    time.Duration(fu.MinGapMinutes) * time.Minute,
+				IdleFor:   time.Duration(fu.IdleMinutes) * time.Minute,
+			})
+		}
+		if wr := cfg.Heartbeat.WeeklyReport; wr.Enabled {
+			if err := hb.EnableWeeklyReport(agent.WeeklyReportPolicy{ChatID: wr.ChatID, Day: wr.Day, At: wr.At}); err != nil {
+				slog.Warn("weekly report disabled", "err", err)
+			}
+		}
+		// Operational alerts go to the admin chat, checked by the heartbeat for disk issues
+		if a := cfg.Alerts; a.AdminChatID != "" {
+			nanoCore.EnableAdminAlerts(agent.AdminAlertPolicy{
+				ChatID:        a.AdminChatID,
+				MinGap:        time.Duration(a.MinGapMinutes) * time.Minute,
+				DiskMinFreeMB: a.DiskMinFreeMB,
+			})
+			hb.EnableDiskCheck()
+			slog.Info("admin alerts enabled", "chat_id", a.AdminChatID)
+		}
+		slog.Info("heartbeat tasks registered", "tasks", strings.Join(hb.Tasks(), ","))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Export OpenTelemetry spans when an OTLP endpoint is configured
+	stopTelemetry := startTelemetry(cfg)
+
+	// 4. Start Background Heartbeat & Cron Service
+	if hbEnabled {
+		go hb.Start(ctx)
+	} else {
+		slog.Warn("heartbeat disabled by config; memory consolidation will not run")
+	}
+	nanoCore.StartCronService(ctx)
+
+	feedInterval := agent.DefaultFeedPollInterval
+	if cfg != nil && cfg.Feeds.PollMinutes > 0 {
+		feedInterval = time.Duration(cfg.Feeds.PollMinutes) * time.Minute
+	}
+	nanoCore.StartFeedService(ctx, feedInterval)
+
+	watchInterval := agent.DefaultWatchInterval
+	if cfg != nil && cfg.Watch.PollSeconds > 0 {
+		watchInterval = time.Duration(cfg.Watch.PollSeconds) * time.Second
+	}
+	nanoCore.StartWatchService(ctx, watchInterval)
+
+	indexInterval := agent.DefaultMemoryIndexInterval
+	if cfg != nil && cfg.MemoryIndex.SyncMinutes > 0 {
+		indexInterval = time.Duration(cfg.MemoryIndex.SyncMinutes) * time.Minute
+	}
+	nanoCore.StartMemoryIndex(ctx, indexInterval)
+	slog.Info("background heartbeat and cron started")
+
+	// Serve /healthz and /readyz; probes read the config in effect
+	var liveCfg atomic.Pointer[config.AppConfig]
+	liveCfg.Store(cfg)
+	if cfg == nil {
+		liveCfg.Store(&config.AppConfig{TelegramToken: tgToken, ProviderType: providerType, ProviderModel: modelName, ProviderAPIKey: providerAPIKey})
+	}
+	healthCfg := config.HealthConfig{Listen: os.Getenv("LITTLECLAW_HEALTH_LISTEN")}
+	if cfg != nil {
+		healthCfg.CheckSeconds = cfg.Health.CheckSeconds
+		if healthCfg.Listen == "" {
+			healthCfg.Listen = cfg.Health.Listen
+		}
+	}
+	checker := newHealthChecker(healthCfg, liveCfg.Load, workspace, msgBus)
+	if cfg != nil && cfg.Health.RequireAuth {
+		auth, err := newHTTPAuth(cfg.HTTPAuth)
+		if err != nil {
+			fatal("invalid http_auth configuration", "err", err)
+		}
+		checker.SetAuth(auth.Middleware)
+		slog.Info("health endpoints require an API key", "keys", len(cfg.HTTPAuth.APIKeys))
+	}
+	if healthCfg.Listen != "" {
+		go func() {
+			if err := checker.Serve(ctx, healthCfg.Listen); err != nil {
+				slog.Warn("health endpoints unavailable", "err", err)
+			}
+		}()
+	}
+
+	// Download links for files over Telegram's upload limit
+	if cfg != nil && cfg.FileLinks.Listen != "" {
+		links, err := filelinks.New(cfg.FileLinks.BaseURL, time.Duration(cfg.FileLinks.TTLHours)*time.Hour)
+		if err != nil {
+			fatal("invalid file_links configuration", "err", err)
+		}
+		nanoCore.SetFileLinker(links)
+		go func() {
+			if err := links.Serve(ctx, cfg.FileLinks.Listen); err != nil {
+				slog.Warn("file links unavailable", "err", err)
+			}
+		}()
+	}
+
+	// 5. Start Telegram Listener
+	if err := tgChannel.Start(ctx); err != nil {
+		fatal("failed to start Telegram channel", "err", err)
+	}
+	slog.Info("telegram channel started, listening for messages")
+	if !profileCfg.Disabled {
+		go func() {
+			if err := tgChannel.SetupProfile(profile, filepath.Join(baseDir, telegram.ProfileFile)); err != nil {
+				slog.Warn("failed to set the bot profile", "err", err)
+			}
+		}()
+	}
+	checker.SetReady(true)
+
+	// Outbound messages are routed by channel name; new channels register here
+	dispatcher := bus.NewDispatcher()
+	// Replies are written in Markdown; each channel gets them in the markup
+	// and message sizes it supports
+	dispatcher.Adapt = format.Adapt
+	dispatcher.Register("telegram", tgChannel)
+
+	// Messages that fail to send are kept on disk and retried with backoff
+	outbox, err := bus.OpenOutbox(filepath.Join(workspace, bus.OutboxFile))
+	if err != nil {
+		fatal("failed to open the outbox", "err", err)
+	}
+	outbox.OnGiveUp = nanoCore.NoteDeliveryFailure
+	if n := outbox.Len(); n > 0 {
+		slog.Info("redelivering messages left from the last run", "count", n)
+	}
+	go outbox.Run(ctx, dispatcher)
+
+	// Answer `littleclaw status` on the control socket
+	ctrl := control.NewServer()
+	ctrl.HandleJSON("GET /status", func(*http.Request) (any, error) {
+		return control.Status{
+			PID:            os.Getpid(),
+			Profile:        config.Profile(),
+			StartedAt:      startedAt,
+			Channels:       dispatcher.Channels(),
+			InboundQueued:  len(msgBus.Inbound),
+			OutboundQueued: len(msgBus.Outbound),
+			Delivery:       dispatcher.Stats(),
+			Outbox:         outbox.Len(),
+			Agent:          nanoCore.Status(),
+		}, nil
+	})
+	handleCronControl(ctrl, nanoCore)
+	go func() {
+		if err := ctrl.Serve(ctx, filepath.Join(baseDir, control.SocketName)); err != nil {
+			slog.Warn("control socket unavailable", "err", err)
+		}
+	}()
+
+	// 6. Start Message Processing Loop
+	// Agent runs for incoming messages are capped by a worker pool
+	var workersCfg config.WorkersConfig
+	if cfg != nil {
+		workersCfg = cfg.Workers
+	}
+	workers := bus.NewWorkerPool(workersCfg.Size, workersCfg.Queue)
+	workers.Start(ctx, nanoCore.RunAgentLoop)
+	size, queue := workers.Capacity()
+	slog.Info("message workers started", "workers", size, "queue", queue)
+
+	// Outbound messages go to their channel most urgent first; the ones left
+	// at shutdown come back on unsent
+	unsent := make(chan []bus.OutboundMessage, 1)
+	go func() {
+		unsent <- msgBus.RunOutbound(ctx, func(outMsg bus.OutboundMessage) {
+			err := dispatcher.Deliver(ctx, outMsg)
+			if errors.Is(err, bus.ErrNoSender) {
+				slog.Debug("dropping outbound message", "channel", outMsg.Channel, "chat_id", outMsg.ChatID)
+			} else if bus.IsPermanent(err) {
+				slog.Error("outbound message not deliverable", "channel", outMsg.Channel, "chat_id", outMsg.ChatID, "err", err)
+				nanoCore.NoteDeliveryFailure(outMsg, err)
+			} else if err != nil {
+				// The outbox retries it, so its temporary files stay until then
+				outbox.Add(outMsg, err)
+				return
+			} else {
+				outbox.Reconnected(outMsg.Channel)
+			}
+			outMsg.RemoveTempFiles()
+		})
+	}()
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case inMsg := <-msgBus.Inbound:
+				// Route inbound message to the NanoCore
+				slog.Info("received message", "chat_id", inMsg.ChatID, "sender", inMsg.SenderID, "chars", len(inMsg.Content))
+				slog.Debug("message content", "chat_id", inMsg.ChatID, "content", inMsg.Content)
+				if agent.IsChatCommand(inMsg.Content) {
+					go nanoCore.RunAgentLoop(ctx, inMsg)
+				} else if !workers.Submit(inMsg) {
+					slog.Warn("worker queue full, rejecting message", "chat_id", inMsg.ChatID, "queued", workers.Queued())
+					msgBus.SendOutbound(bus.OutboundMessage{
+						Channel:          inMsg.Channel,
+						ChatID:           inMsg.ChatID,
+						ThreadID:         inMsg.ThreadID,
+						ReplyToMessageID: inMsg.MessageID,
+						Content:          "⏳ I'm busy with other requests right now. Please send that again in a minute.",
+					})
+				}
+
+			case decision := <-msgBus.Approvals:
+				err := nanoCore.ResolveApproval(decision)
+				if err != nil {
+					slog.Warn("approval decision not applied", "approval_id", decision.ID, "sender", decision.SenderID, "err", err)
+				}
+				if decision.Result != nil {
+					decision.Result <- err
+				}
+			}
+		}
+	}()
+
+	// Apply config.json changes on save or SIGHUP, without dropping the Telegram connection
+	if cfg != nil {
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		go func() {
+			current := cfg
+			config.Watch(ctx, config.DefaultWatchInterval, hup, func(next *config.AppConfig) {
+				applyOverrides(next)
+				current = reloadConfig(current, next, nanoCore, tgChannel)
+				liveCfg.Store(current)
+			})
+		}()
+	}
+
+	// Wait for termination signal
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	<-sigChan
+
+	slog.Info("shutting down")
+	cancel()
+	// Keep replies still waiting in the queue for the next start
+	for _, outMsg := range <-unsent {
+		if outMsg.Channel != "internal" {
+			outbox.Add(outMsg, errors.New("not sent before shutdown"))
+		}
+	}
+	stopTelemetry()
 }
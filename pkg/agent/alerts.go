@@ -0,0 +1,163 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/events"
+)
+
+// Admin alert kinds, also the first part of the key alerts are rate limited by.
+const (
+	AdminAlertProviderAuth = "provider_auth" // the provider rejected the API key
+	AdminAlertCron         = "cron"          // a cron job failed AlertAfter runs in a row
+	AdminAlertPanic        = "panic"         // a goroutine panicked and was recovered
+	AdminAlertDisk         = "disk"          // the workspace disk is nearly full or not writable
+	AdminAlertDelivery     = "delivery"      // an outbound message could not be delivered
+)
+
+const (
+	// DefaultAdminAlertGap is the minimum time between two alerts of the same
+	// kind and key.
+	DefaultAdminAlertGap = time.Hour
+	// DefaultDiskMinFreeMB is the free space below which the disk check alerts.
+	DefaultDiskMinFreeMB = 500
+	// DiskCheckInterval is how often the heartbeat checks the workspace disk.
+	DiskCheckInterval = 30 * time.Minute
+)
+
+// authErrorRe matches provider errors caused by a rejected API key.
+var authErrorRe = regexp.MustCompile(`API error (401|403)\b`)
+
+// AdminAlertPolicy says where operational alerts go and how often they may
+// repeat. Zero fields keep the defaults.
+type AdminAlertPolicy struct {
+	ChatID        string
+	Channel       string        // default "telegram"
+	MinGap        time.Duration // default 1h per kind and key
+	DiskMinFreeMB int           // default 500
+}
+
+func (p AdminAlertPolicy) withDefaults() AdminAlertPolicy {
+	if p.Channel == "" {
+		p.Channel = "telegram"
+	}
+	if p.MinGap <= 0 {
+		p.MinGap = DefaultAdminAlertGap
+	}
+	if p.DiskMinFreeMB <= 0 {
+		p.DiskMinFreeMB = DefaultDiskMinFreeMB
+	}
+	return p
+}
+
+// adminAlerts sends alerts to the admin chat, at most one per kind and key
+// per MinGap.
+type adminAlerts struct {
+	policy AdminAlertPolicy
+	msgBus *bus.MessageBus
+
+	mu   sync.Mutex
+	last map[string]time.Time
+}
+
+// EnableAdminAlerts sends provider auth failures, repeated cron failures,
+// recovered panics, and workspace disk problems to p.ChatID.
+func (c *NanoCore) EnableAdminAlerts(p AdminAlertPolicy) {
+	c.alerts = &adminAlerts{policy: p.withDefaults(), msgBus: c.msgBus, last: make(map[string]time.Time)}
+	c.cronService.SetAlertFunc(c.adminAlert)
+	// A rejected API key will not fix itself
+	events.Subscribe(c.events, func(e events.ProviderError) { c.noteAuthError(e.Err) })
+}
+
+// adminAlert sends text to the admin chat unless an alert with the same kind
+// and key went out within MinGap. Without EnableAdminAlerts it only logs.
+func (c *NanoCore) adminAlert(kind, key, text string) {
+	slog.Warn("admin alert", "kind", kind, "key", key, "alert", text)
+	a := c.alerts
+	if a == nil {
+		return
+	}
+	id, now := kind+"/"+key, time.Now()
+	a.mu.Lock()
+	if last, ok := a.last[id]; ok && now.Sub(last) < a.policy.MinGap {
+		a.mu.Unlock()
+		return
+	}
+	a.last[id] = now
+	a.mu.Unlock()
+
+	a.msgBus.SendOutbound(bus.OutboundMessage{
+		Channel: a.policy.Channel,
+		ChatID:  a.policy.ChatID,
+		Content: "🚨 Admin alert: " + text,
+	})
+}
+
+// noteAuthError alerts the admin when err says the provider rejected the API key.
+func (c *NanoCore) noteAuthError(err error) {
+	if err != nil && authErrorRe.MatchString(err.Error()) {
+		provider, _ := c.chatModel()
+		c.adminAlert(AdminAlertProviderAuth, "", fmt.Sprintf("the %s provider rejected the API key: %s", provider.Name(), truncateLabel(err.Error(), 200)))
+	}
+}
+
+// NoteDeliveryFailure records an outbound message that could not be delivered
+// in INTERNAL.md, so the agent can tell the user later, and alerts the admin
+// unless the message was for the admin chat itself.
+func (c *NanoCore) NoteDeliveryFailure(msg bus.OutboundMessage, err error) {
+	c.memoryStore.AppendInternal("DELIVERY", fmt.Sprintf("Message to %s chat %s was not delivered (%s): %s", msg.Channel, msg.ChatID, err, truncateLabel(msg.Content, 200)))
+	if a := c.alerts; a != nil && msg.Channel == a.policy.Channel && msg.ChatID == a.policy.ChatID {
+		slog.Error("admin chat unreachable", "chat_id", msg.ChatID, "err", err)
+		return
+	}
+	c.adminAlert(AdminAlertDelivery, msg.Channel+":"+msg.ChatID, fmt.Sprintf("a message to %s chat %s could not be delivered: %s", msg.Channel, msg.ChatID, truncateLabel(err.Error(), 200)))
+}
+
+// recoverPanic is deferred at the top of goroutines doing agent work, so a
+// panic is logged with its stack and reported instead of crashing the daemon.
+// It must be deferred directly for recover to see the panic.
+func (c *NanoCore) recoverPanic(where string) {
+	if r := recover(); r != nil {
+		slog.Error("recovered panic", "in", where, "panic", r, "stack", string(debug.Stack()))
+		c.adminAlert(AdminAlertPanic, where, fmt.Sprintf("recovered a panic in %s: %v", where, r))
+	}
+}
+
+// EnableDiskCheck registers the disk_check heartbeat task, which alerts the
+// admin when the workspace disk runs low on space or cannot be written.
+func (h *Heartbeat) EnableDiskCheck() {
+	h.Register("disk_check", DiskCheckInterval, h.core.checkDisk)
+}
+
+// checkDisk checks the workspace's free space and that it is writable.
+func (c *NanoCore) checkDisk(context.Context) {
+	minFree := DefaultDiskMinFreeMB
+	if c.alerts != nil {
+		minFree = c.alerts.policy.DiskMinFreeMB
+	}
+	if free, err := diskFreeMB(c.workspace); err != nil {
+		slog.Debug("disk check: free space unknown", "err", err)
+	} else if free < int64(minFree) {
+		c.adminAlert(AdminAlertDisk, "space", fmt.Sprintf("only %d MB free on the workspace disk (%s)", free, c.workspace))
+	}
+
+	f, err := os.CreateTemp(c.workspace, ".disk_check_*")
+	if err == nil {
+		_, err = f.WriteString("ok")
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		os.Remove(f.Name())
+	}
+	if err != nil {
+		c.adminAlert(AdminAlertDisk, "write", fmt.Sprintf("cannot write to the workspace: %v", err))
+	}
+}
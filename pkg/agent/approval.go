@@ -0,0 +1,138 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/tools"
+)
+
+// defaultApprovalTimeout is how long a held action waits for the user's answer.
+const defaultApprovalTimeout = 5 * time.Minute
+
+// approvalGate implements tools.Approver by sending an Approve/Deny prompt to
+// the chat that triggered the action and waiting for the answer on the bus.
+type approvalGate struct {
+	msgBus  *bus.MessageBus
+	timeout time.Duration
+
+	mu      sync.Mutex
+	pending map[string]pendingApproval
+	seq     atomic.Int64
+}
+
+// pendingApproval is a prompt waiting for an answer.
+type pendingApproval struct {
+	ch        chan bool
+	requester string // sender whose run asked; they may answer, as may admins
+}
+
+func newApprovalGate(msgBus *bus.MessageBus, timeout time.Duration) *approvalGate {
+	if timeout <= 0 {
+		timeout = defaultApprovalTimeout
+	}
+	return &approvalGate{
+		msgBus:  msgBus,
+		timeout: timeout,
+		pending: make(map[string]pendingApproval),
+	}
+}
+
+// RequestApproval sends the prompt and blocks until a decision arrives.
+func (g *approvalGate) RequestApproval(ctx context.Context, tool, action string) (bool, error) {
+	chatID, _ := ctx.Value(ctxChatID).(string)
+	channel, _ := ctx.Value(ctxChannel).(string)
+	threadID, _ := ctx.Value(ctxThreadID).(int)
+	if chatID == "" || chatID == "internal_memory" || channel == "internal" {
+		return false, fmt.Errorf("no user is available to approve actions from a background context")
+	}
+
+	id := fmt.Sprintf("%d-%d", time.Now().Unix(), g.seq.Add(1))
+	ch := make(chan bool, 1)
+
+	g.mu.Lock()
+	g.pending[id] = pendingApproval{ch: ch, requester: tools.Requester(ctx)}
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.pending, id)
+		g.mu.Unlock()
+	}()
+
+	slog.Info("approval requested", "approval_id", id, "chat_id", chatID, "tool", tool, "action", action)
+	g.msgBus.SendOutbound(bus.OutboundMessage{
+		Channel:    channel,
+		ChatID:     chatID,
+		ThreadID:   threadID,
+		Content:    fmt.Sprintf("🛑 Approval needed\n\nTool: %s\nAction: %s\n\nAllow this to run?", tool, action),
+		ApprovalID: id,
+	})
+
+	timer := time.NewTimer(g.timeout)
+	defer timer.Stop()
+
+	select {
+	case approved := <-ch:
+		slog.Info("approval resolved", "approval_id", id, "chat_id", chatID, "tool", tool, "approved", approved)
+		return approved, nil
+	case <-timer.C:
+		return false, fmt.Errorf("approval request timed out after %s", g.timeout)
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
+
+// resolve delivers d to the waiting request if allowed says its sender may
+// answer it. The request stays pending when the sender is refused.
+func (g *approvalGate) resolve(d bus.ApprovalDecision, allowed func(requester string) bool) error {
+	g.mu.Lock()
+	p, ok := g.pending[d.ID]
+	switch {
+	case !ok:
+		g.mu.Unlock()
+		return bus.ErrApprovalNotPending
+	case !allowed(p.requester):
+		g.mu.Unlock()
+		return bus.ErrNotApprover
+	}
+	delete(g.pending, d.ID)
+	g.mu.Unlock()
+
+	p.ch <- d.Approved
+	return nil
+}
+
+// EnableApprovals turns on human-in-the-loop approval for risky commands.
+// Commands matching any pattern (or tools.DefaultRiskPatterns when empty) are
+// held until the user taps Approve or Deny.
+func (c *NanoCore) EnableApprovals(patterns []string, timeout time.Duration) error {
+	gate := newApprovalGate(c.msgBus, timeout)
+	if err := c.toolRegistry.SetApprover(gate, patterns); err != nil {
+		return err
+	}
+	c.approvals = gate
+	return nil
+}
+
+// ResolveApproval routes a user's decision to the pending request. Only the
+// sender whose message led to the action, or an admin, may decide; anyone
+// else gets bus.ErrNotApprover and the request keeps waiting. It returns
+// bus.ErrApprovalNotPending if approvals are disabled or the request was
+// already answered.
+func (c *NanoCore) ResolveApproval(d bus.ApprovalDecision) error {
+	if c.approvals == nil {
+		return bus.ErrApprovalNotPending
+	}
+	return c.approvals.resolve(d, func(requester string) bool {
+		if requester != "" && d.SenderID == requester {
+			return true
+		}
+		return c.userRole(bus.InboundMessage{SenderID: d.SenderID}) == tools.RoleAdmin
+	})
+}
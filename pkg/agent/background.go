@@ -0,0 +1,285 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"littleclaw/pkg/providers"
+	"littleclaw/pkg/redact"
+	"littleclaw/pkg/tools"
+)
+
+const (
+	// MaxBackgroundRuns limits how many background commands run at once
+	// (sub-agents have their own limit, MaxConcurrentSubAgents).
+	MaxBackgroundRuns = 5
+	// backgroundRunTimeout bounds a background command's total run time.
+	backgroundRunTimeout = time.Hour
+	// maxFinishedBackgroundRuns is how many completed runs list_background_runs remembers.
+	maxFinishedBackgroundRuns = 20
+)
+
+// backgroundRun is one piece of work running detached from the conversation:
+// a sub-agent or a background exec command.
+type backgroundRun struct {
+	ID          string
+	Kind        string // subagent or exec
+	Description string
+	ChatID      string
+	Channel     string
+	State       string // running, then done, failed, or cancelled (sub-agents: their status)
+	StartedAt   time.Time
+	FinishedAt  time.Time
+
+	cancel context.CancelFunc
+}
+
+// backgroundManager tracks running and recently finished background work so
+// it can be listed and cancelled.
+type backgroundManager struct {
+	mu       sync.Mutex
+	seq      int
+	running  map[string]*backgroundRun
+	finished []*backgroundRun // newest last
+	wg       sync.WaitGroup
+}
+
+func newBackgroundManager() *backgroundManager {
+	return &backgroundManager{running: make(map[string]*backgroundRun)}
+}
+
+// track registers a running run. An empty id gets a new bg-N one; limit > 0
+// caps how many runs of the same kind may run at once.
+func (m *backgroundManager) track(id, kind, description, chatID, channel string, cancel context.CancelFunc, limit int) (*backgroundRun, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if limit > 0 {
+		n := 0
+		for _, r := range m.running {
+			if r.Kind == kind {
+				n++
+			}
+		}
+		if n >= limit {
+			return nil, fmt.Errorf("%d background %s runs are already running; wait for one to finish", n, kind)
+		}
+	}
+	if id == "" {
+		m.seq++
+		id = fmt.Sprintf("bg-%d", m.seq)
+	}
+	run := &backgroundRun{
+		ID:          id,
+		Kind:        kind,
+		Description: description,
+		ChatID:      chatID,
+		Channel:     channel,
+		State:       "running",
+		StartedAt:   time.Now(),
+		cancel:      cancel,
+	}
+	m.running[id] = run
+	m.wg.Add(1)
+	return run, nil
+}
+
+// finish moves a run to the finished list with its final state.
+func (m *backgroundManager) finish(id, state string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	run, ok := m.running[id]
+	if !ok {
+		return
+	}
+	run.State = state
+	run.FinishedAt = time.Now()
+	delete(m.running, id)
+	m.finished = append(m.finished, run)
+	if len(m.finished) > maxFinishedBackgroundRuns {
+		m.finished = m.finished[len(m.finished)-maxFinishedBackgroundRuns:]
+	}
+	m.wg.Done()
+}
+
+// cancelRun cancels a running run. The run reports its own outcome when it stops.
+func (m *backgroundManager) cancelRun(id string) (backgroundRun, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	run, ok := m.running[id]
+	if !ok {
+		for _, r := range m.finished {
+			if r.ID == id {
+				return *r, fmt.Errorf("run %s already finished (%s)", id, r.State)
+			}
+		}
+		return backgroundRun{}, fmt.Errorf("no background run with ID %s", id)
+	}
+	run.cancel()
+	return *run, nil
+}
+
+// list snapshots the runs started from chatID, or every run when chatID is
+// "": those still running by start time, then the finished ones as kept.
+func (m *backgroundManager) list(chatID string) []backgroundRun {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []backgroundRun
+	for _, r := range m.running {
+		if chatID == "" || r.ChatID == chatID {
+			out = append(out, *r)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].StartedAt.Before(out[j].StartedAt) })
+	for _, r := range m.finished {
+		if chatID == "" || r.ChatID == chatID {
+			out = append(out, *r)
+		}
+	}
+	return out
+}
+
+// WaitBackgroundRuns blocks until every background run has finished.
+func (c *NanoCore) WaitBackgroundRuns() {
+	c.background.wg.Wait()
+}
+
+// StartBackground implements tools.BackgroundRunner: fn runs detached from the
+// tool call with its own timeout, can be stopped with cancel_run or /stop, and
+// its outcome is sent to the chat that started it.
+func (c *NanoCore) StartBackground(ctx context.Context, kind, description string, fn func(ctx context.Context) (string, error)) (string, error) {
+	chatID, channel := c.replyTarget(ctx)
+	runCtx, cancel := context.WithTimeout(context.Background(), backgroundRunTimeout)
+	run, err := c.background.track("", kind, description, chatID, channel, cancel, MaxBackgroundRuns)
+	if err != nil {
+		cancel()
+		return "", err
+	}
+	runCtx = context.WithValue(runCtx, ctxChatID, chatID)
+	runCtx = context.WithValue(runCtx, ctxChannel, channel)
+	runCtx = tools.WithCaller(runCtx, chatID, channel)
+	runCtx = tools.WithRequester(runCtx, tools.Requester(ctx))
+
+	go func() {
+		defer c.recoverPanic("background run")
+		defer cancel()
+		stopID := c.runs.add(chatID, &activeRun{cancel: cancel, channel: channel})
+		defer c.runs.remove(chatID, stopID)
+
+		output, err := fn(runCtx)
+		state := "done"
+		switch {
+		case errors.Is(runCtx.Err(), context.Canceled):
+			state = "cancelled"
+		case errors.Is(runCtx.Err(), context.DeadlineExceeded):
+			state, err = "failed", fmt.Errorf("timed out after %s", backgroundRunTimeout)
+		case err != nil:
+			state = "failed"
+		}
+		// Deliver before finishing so WaitBackgroundRuns returns only once the result is out
+		c.deliverBackgroundResult(run, state, output, err)
+		c.background.finish(run.ID, state)
+		slog.Info("background run finished", "run_id", run.ID, "chat_id", run.ChatID, "state", state, "took", time.Since(run.StartedAt).Round(time.Second))
+	}()
+	return run.ID, nil
+}
+
+// deliverBackgroundResult tells the originating chat how a background run
+// ended and records it in the history and session so the agent can follow up.
+func (c *NanoCore) deliverBackgroundResult(run *backgroundRun, state, output string, err error) {
+	icon := map[string]string{"done": "✅", "failed": "❌", "cancelled": "🛑"}[state]
+	content := fmt.Sprintf("%s Background run %s %s: %s", icon, run.ID, state, truncateLabel(run.Description, 80))
+	if err != nil && state == "failed" {
+		content += fmt.Sprintf("\nError: %v", err)
+	}
+	if output = strings.TrimSpace(output); output != "" && state != "cancelled" {
+		content += "\n\n" + c.fitToolResult(run.Kind, nil, output)
+	}
+	// Background exec output bypasses Registry.Execute's redaction.
+	content = redact.String(content)
+	if run.ChatID == "" {
+		c.memoryStore.AppendInternal("ASSISTANT", content)
+		return
+	}
+	c.sendResponse(run.ChatID, 0, run.Channel, content, nil)
+	c.memoryStore.AppendHistory("ASSISTANT", content)
+	c.sessions.appendMessage(run.ChatID, providers.Message{Role: "assistant", Content: content})
+}
+
+// registerBackgroundTools adds list_background_runs and cancel_run.
+func (c *NanoCore) registerBackgroundTools() {
+	c.toolRegistry.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "list_background_runs",
+			Description: "Lists this chat's background work (sub-agents and background exec commands) that is running or recently finished, with its ID, state, and how long it has been running.",
+			Parameters: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *tools.ToolResult {
+		// Descriptions hold commands and tasks, so other chats' runs stay hidden
+		runs := c.background.list(tools.CallerChat(ctx))
+		if len(runs) == 0 {
+			return &tools.ToolResult{ForLLM: "No background runs."}
+		}
+		now := time.Now()
+		var sb strings.Builder
+		for _, r := range runs {
+			sb.WriteString(fmt.Sprintf("- %s [%s] %s: %s (started %s", r.ID, r.State, r.Kind, truncateLabel(r.Description, 80), r.StartedAt.Format("15:04:05")))
+			if r.FinishedAt.IsZero() {
+				sb.WriteString(fmt.Sprintf(", running for %s", now.Sub(r.StartedAt).Round(time.Second)))
+			} else {
+				sb.WriteString(fmt.Sprintf(", took %s", r.FinishedAt.Sub(r.StartedAt).Round(time.Second)))
+			}
+			sb.WriteString(")\n")
+		}
+		return &tools.ToolResult{ForLLM: sb.String()}
+	})
+
+	c.toolRegistry.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "cancel_run",
+			Description: "Cancels a running sub-agent or background exec command by its run ID (see list_background_runs). The user is notified when it stops.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"id": map[string]interface{}{
+						"type":        "string",
+						"description": "The run ID, e.g. bg-2 or sa-1.",
+					},
+				},
+				"required": []string{"id"},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *tools.ToolResult {
+		id, _ := args["id"].(string)
+		id = strings.TrimSpace(id)
+		if id == "" {
+			return &tools.ToolResult{ForLLM: "Error: id is required"}
+		}
+		run, err := c.background.cancelRun(id)
+		if err != nil {
+			return &tools.ToolResult{ForLLM: fmt.Sprintf("Error: %v", err)}
+		}
+		return &tools.ToolResult{ForLLM: fmt.Sprintf("Cancelled %s (%s: %s).", run.ID, run.Kind, truncateLabel(run.Description, 80))}
+	})
+
+	c.toolRegistry.SetToolGroup("background", "list_background_runs", "cancel_run")
+	c.toolRegistry.SetToolGroupKeywords("background", "background", "running", "run", "runs", "cancel", "abort", "kill", "status", "progress", "finished", "done")
+}
@@ -0,0 +1,82 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/providers"
+	"littleclaw/pkg/tools"
+)
+
+// BroadcastPolicy says who may broadcast and to which chats.
+type BroadcastPolicy struct {
+	Admins []string // chat IDs whose runs may use the broadcast tool
+	Chats  []string // the registered chats "all" sends to: "chatID" or "channel:chatID"
+}
+
+// EnableBroadcast registers the broadcast tool, which sends one message to
+// several chats at once. Only runs for p.Admins chats may use it.
+func (c *NanoCore) EnableBroadcast(p BroadcastPolicy) {
+	c.toolRegistry.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "broadcast",
+			Description: fmt.Sprintf("Sends the same message to several chats at once, e.g. an announcement or a notification for every device. Without 'chats' it goes to all %d registered chats. Only the admin may broadcast.", len(p.Chats)),
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"message": map[string]interface{}{
+						"type":        "string",
+						"description": "The text to send",
+					},
+					"chats": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Chat IDs to send to, or \"channel:chatID\" for another channel. Omit for all registered chats.",
+					},
+				},
+				"required": []string{"message"},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *tools.ToolResult {
+		chatID, _ := ctx.Value(ctxChatID).(string)
+		channel, _ := ctx.Value(ctxChannel).(string)
+		if !slices.Contains(p.Admins, chatID) {
+			return &tools.ToolResult{ForLLM: "Error: only the admin can broadcast."}
+		}
+		message, _ := args["message"].(string)
+		if strings.TrimSpace(message) == "" {
+			return &tools.ToolResult{ForLLM: "Error: message is required."}
+		}
+		targets := p.Chats
+		if list, ok := args["chats"].([]interface{}); ok && len(list) > 0 {
+			targets = nil
+			for _, v := range list {
+				if s, _ := v.(string); s == "all" {
+					targets = append(targets, p.Chats...)
+				} else if s != "" {
+					targets = append(targets, s)
+				}
+			}
+		}
+		if channel == "" || channel == "internal" {
+			channel = "telegram"
+		}
+		msg := bus.OutboundMessage{Channel: channel, Broadcast: targets, Content: message}
+		recipients := msg.Recipients()
+		if len(recipients) == 0 {
+			return &tools.ToolResult{ForLLM: "Error: no chats to broadcast to. Pass 'chats' or register some under broadcast.chats in the config."}
+		}
+		c.msgBus.SendOutbound(msg)
+		slog.Info("broadcast sent", "chat_id", chatID, "recipients", len(recipients))
+		return &tools.ToolResult{ForLLM: fmt.Sprintf("Broadcast sent to %d chat(s).", len(recipients))}
+	})
+}
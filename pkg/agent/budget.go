@@ -0,0 +1,97 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"littleclaw/pkg/providers"
+)
+
+// budgetWrapUpPrompt asks the model for a final answer once the run's budget is spent.
+const budgetWrapUpPrompt = "[System] The budget for this request is used up, so no more tools can be called. Reply now with the best answer you can give from what you already have, and say briefly what is still undone."
+
+// runBudget tracks the tokens (and estimated cost) of one RunAgentLoop call
+// against the configured limits. A zero limit is unlimited.
+type runBudget struct {
+	maxTokens       int
+	maxCost         float64 // USD
+	promptPrice     float64 // USD per million prompt tokens
+	completionPrice float64 // USD per million completion tokens
+
+	promptTokens     int
+	completionTokens int
+}
+
+// add counts one LLM call. Providers that report no usage are estimated from
+// the request messages and the reply.
+func (b *runBudget) add(resp *providers.ChatResponse, messages []providers.Message) {
+	prompt, completion := resp.Usage.PromptTokens, resp.Usage.CompletionTokens
+	if resp.Usage.TotalTokens == 0 && prompt+completion == 0 {
+		prompt = estimateMessageTokens(messages)
+		completion = estimateMessageTokens([]providers.Message{{Content: resp.Content, ToolCalls: resp.ToolCalls}})
+	}
+	b.promptTokens += prompt
+	b.completionTokens += completion
+}
+
+// total returns the tokens used so far.
+func (b *runBudget) total() int {
+	return b.promptTokens + b.completionTokens
+}
+
+// cost returns the estimated spend so far in USD.
+func (b *runBudget) cost() float64 {
+	return (float64(b.promptTokens)*b.promptPrice + float64(b.completionTokens)*b.completionPrice) / 1e6
+}
+
+// exceeded reports whether the run has hit a limit.
+func (b *runBudget) exceeded() bool {
+	if b.maxTokens > 0 && b.total() >= b.maxTokens {
+		return true
+	}
+	return b.maxCost > 0 && b.promptPrice+b.completionPrice > 0 && b.cost() >= b.maxCost
+}
+
+// notice is the line appended to a reply cut short by the budget.
+func (b *runBudget) notice() string {
+	used := fmt.Sprintf("%d tokens", b.total())
+	if b.promptPrice+b.completionPrice > 0 {
+		used += fmt.Sprintf(" (~$%.4f)", b.cost())
+	}
+	var limits []string
+	if b.maxTokens > 0 {
+		limits = append(limits, fmt.Sprintf("%d tokens", b.maxTokens))
+	}
+	if b.maxCost > 0 {
+		limits = append(limits, fmt.Sprintf("$%.4f", b.maxCost))
+	}
+	return fmt.Sprintf("⚠️ Stopped early: this request used %s of its budget (%s).", used, strings.Join(limits, " / "))
+}
+
+// runChat makes one of a run's extra LLM calls (mid-turn compaction, the
+// self-check, the budget wrap-up) on the run's provider and model, counting
+// it in the run's budget and usage. It sets the request's model.
+type runChat func(ctx context.Context, req providers.ChatRequest) (*providers.ChatResponse, error)
+
+// wrapUpOverBudget makes one tool-less call asking for a final answer from
+// what the run has so far. draft is used if that call fails.
+func (c *NanoCore) wrapUpOverBudget(ctx context.Context, chat runChat, messages []providers.Message, params resolvedParams, draft string) string {
+	msgs := append(messages[:len(messages):len(messages)], providers.Message{Role: "user", Content: budgetWrapUpPrompt})
+	resp, err := chat(ctx, providers.ChatRequest{
+		Messages:    msgs,
+		Temperature: params.temperature,
+		MaxTokens:   params.maxTokens,
+	})
+	if err != nil || strings.TrimSpace(resp.Content) == "" {
+		if err != nil {
+			slog.Warn("budget wrap-up call failed", "err", err)
+		}
+		if strings.TrimSpace(draft) != "" {
+			return draft
+		}
+		return "I had to stop before finishing this request."
+	}
+	return resp.Content
+}
@@ -0,0 +1,109 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// stopCommand cancels every in-flight agent run of the chat that sends it.
+const stopCommand = "/stop"
+
+// activeRun is one cancellable agent run (a RunAgentLoop call or a sub-agent).
+type activeRun struct {
+	cancel    context.CancelFunc
+	messageID int // inbound message being answered; 0 for sub-agents
+	channel   string
+}
+
+// runRegistry tracks in-flight runs per ChatID so /stop can cancel them.
+type runRegistry struct {
+	mu   sync.Mutex
+	seq  int
+	runs map[string]map[int]*activeRun
+}
+
+func newRunRegistry() *runRegistry {
+	return &runRegistry{runs: make(map[string]map[int]*activeRun)}
+}
+
+// add registers a run and returns its ID for remove.
+func (r *runRegistry) add(chatID string, run *activeRun) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seq++
+	if r.runs[chatID] == nil {
+		r.runs[chatID] = make(map[int]*activeRun)
+	}
+	r.runs[chatID][r.seq] = run
+	return r.seq
+}
+
+// remove forgets a finished run.
+func (r *runRegistry) remove(chatID string, id int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.runs[chatID], id)
+	if len(r.runs[chatID]) == 0 {
+		delete(r.runs, chatID)
+	}
+}
+
+// count returns how many runs are in flight across all chats.
+func (r *runRegistry) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, runs := range r.runs {
+		n += len(runs)
+	}
+	return n
+}
+
+// cancelChat cancels and forgets every run of a chat, returning them.
+func (r *runRegistry) cancelChat(chatID string) []*activeRun {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []*activeRun
+	for _, run := range r.runs[chatID] {
+		run.cancel()
+		out = append(out, run)
+	}
+	delete(r.runs, chatID)
+	return out
+}
+
+// isStopCommand reports whether content is /stop (optionally /stop@botname).
+func isStopCommand(content string) bool {
+	content = strings.ToLower(strings.TrimSpace(content))
+	return content == stopCommand || strings.HasPrefix(content, stopCommand+"@")
+}
+
+// StopChat cancels every in-flight agent run and sub-agent of a chat. Their
+// contexts are cancelled, which also terminates running exec/skill processes.
+// It returns how many runs were stopped.
+func (c *NanoCore) StopChat(chatID string) int {
+	stopped := c.runs.cancelChat(chatID)
+	for _, run := range stopped {
+		// An empty reply to the stopped message clears the channel's typing indicator
+		if run.messageID != 0 {
+			c.sendResponse(chatID, run.messageID, run.channel, "", nil)
+		}
+	}
+	if len(stopped) > 0 {
+		slog.Info("stopped runs", "chat_id", chatID, "runs", len(stopped))
+	}
+	return len(stopped)
+}
+
+// handleStopCommand answers /stop.
+func (c *NanoCore) handleStopCommand(chatID string, messageID int, channel string) {
+	reply := "Nothing is running."
+	if n := c.StopChat(chatID); n > 0 {
+		reply = fmt.Sprintf("🛑 Stopped %d run(s).", n)
+		c.memoryStore.AppendHistory("SYSTEM", fmt.Sprintf("User stopped %d in-flight run(s) with /stop.", n))
+	}
+	c.sendResponse(chatID, messageID, channel, reply, nil)
+}
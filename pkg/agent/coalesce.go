@@ -0,0 +1,94 @@
+package agent
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"littleclaw/pkg/bus"
+)
+
+// Coalescing of the messages tools send to the chat during a run.
+const (
+	CoalesceOff    = "off"    // each tool message goes out right away (default)
+	CoalesceWindow = "window" // tool messages within a short window of the first go out together
+	CoalesceRun    = "run"    // tool messages are held until the run replies or ends
+)
+
+const (
+	// DefaultCoalesceWindow is how long CoalesceWindow holds the first message.
+	DefaultCoalesceWindow = 3 * time.Second
+	// maxCoalescedChars keeps a combined message under Telegram's 4096
+	// character limit; a message that would pass it flushes the ones before.
+	maxCoalescedChars = 3500
+)
+
+// runOutbox sends the tool messages of one RunAgentLoop call, combining them
+// per the chat's coalesce setting.
+type runOutbox struct {
+	c      *NanoCore
+	msg    bus.InboundMessage
+	mode   string
+	window time.Duration
+
+	mu      sync.Mutex
+	content []string
+	files   []string
+	temp    []string
+	timer   *time.Timer
+}
+
+// newRunOutbox returns the outbox for a run.
+func (c *NanoCore) newRunOutbox(msg bus.InboundMessage, mode string, window time.Duration) *runOutbox {
+	return &runOutbox{c: c, msg: msg, mode: mode, window: window}
+}
+
+// send queues a tool message. With coalescing off it goes out right away.
+func (o *runOutbox) send(content string, files, tempFiles []string) {
+	if o.mode != CoalesceWindow && o.mode != CoalesceRun {
+		o.c.sendReplyFiles(o.msg, content, files, tempFiles)
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.size()+len(content) > maxCoalescedChars {
+		o.flushLocked()
+	}
+	if content != "" {
+		o.content = append(o.content, content)
+	}
+	o.files = append(o.files, files...)
+	o.temp = append(o.temp, tempFiles...)
+	if o.mode == CoalesceWindow && o.timer == nil {
+		o.timer = time.AfterFunc(o.window, o.flush)
+	}
+}
+
+// flush sends what is held as one message. The loop calls it before anything
+// else it sends, so the combined message keeps its place in the chat.
+func (o *runOutbox) flush() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.flushLocked()
+}
+
+func (o *runOutbox) flushLocked() {
+	if o.timer != nil {
+		o.timer.Stop()
+		o.timer = nil
+	}
+	if len(o.content) == 0 && len(o.files) == 0 {
+		return
+	}
+	o.c.sendReplyFiles(o.msg, strings.Join(o.content, "\n\n"), o.files, o.temp)
+	o.content, o.files, o.temp = nil, nil, nil
+}
+
+// size is the length of the held messages once joined.
+func (o *runOutbox) size() int {
+	n := 0
+	for _, s := range o.content {
+		n += len(s) + 2
+	}
+	return n
+}
@@ -0,0 +1,160 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"littleclaw/pkg/providers"
+)
+
+const (
+	// midLoopCompactionRatio: when the messages array of a single turn grows past
+	// this fraction of the context window, older tool rounds are summarized.
+	midLoopCompactionRatio = 0.60
+
+	// digestInputChars caps how much tool history is sent to the summarizer.
+	digestInputChars = 24000
+	// fallbackResultChars is how much of each tool result the fallback digest keeps.
+	fallbackResultChars = 200
+
+	compactedPrefix = "[System] Earlier tool calls in this turn were compacted to save context. Digest:\n"
+)
+
+// estimateMessageTokens approximates the prompt size of msgs, tool call
+// arguments included.
+func estimateMessageTokens(msgs []providers.Message) int {
+	chars := 0
+	for _, m := range msgs {
+		chars += len(m.Content)
+		if len(m.ToolCalls) > 0 {
+			if b, err := json.Marshal(m.ToolCalls); err == nil {
+				chars += len(b)
+			}
+		}
+	}
+	return chars / CharsPerToken
+}
+
+// contextWindow returns the configured or estimated context window in tokens.
+func (c *NanoCore) contextWindow() int {
+	if c.ContextWindowEst > 0 {
+		return c.ContextWindowEst
+	}
+	_, model := c.chatModel()
+	return EstimateContextWindow(model)
+}
+
+// compactSpan finds the tool rounds that can be folded into a digest: every
+// message after the turn's user message (turnStart) up to, but not including,
+// the most recent assistant tool call, which the model still needs verbatim.
+// ok is false when there is less than one full earlier round.
+func compactSpan(messages []providers.Message, turnStart int) (from, to int, ok bool) {
+	last := -1
+	for i := len(messages) - 1; i > turnStart; i-- {
+		if messages[i].Role == "assistant" && len(messages[i].ToolCalls) > 0 {
+			last = i
+			break
+		}
+	}
+	from = turnStart + 1
+	if last <= from {
+		return 0, 0, false
+	}
+	return from, last, true
+}
+
+// maybeCompactMessages summarizes older tool rounds of the current turn into
+// a single digest message once the estimated prompt crosses
+// midLoopCompactionRatio of the context window. Tool calls and their results
+// are removed together so the remaining array stays valid for the provider.
+func (c *NanoCore) maybeCompactMessages(ctx context.Context, chat runChat, messages []providers.Message, turnStart int) []providers.Message {
+	limit := int(float64(c.contextWindow()) * midLoopCompactionRatio)
+	if estimateMessageTokens(messages) <= limit {
+		return messages
+	}
+	from, to, ok := compactSpan(messages, turnStart)
+	if !ok {
+		return messages
+	}
+
+	digest, err := c.summarizeToolRounds(ctx, chat, messages[from:to])
+	if err != nil {
+		slog.Warn("mid-turn compaction: summarizer failed, using a truncated digest", "err", err)
+		digest = fallbackDigest(messages[from:to])
+	}
+
+	out := make([]providers.Message, 0, len(messages)-(to-from)+1)
+	out = append(out, messages[:from]...)
+	out = append(out, providers.Message{Role: "user", Content: compactedPrefix + digest})
+	out = append(out, messages[to:]...)
+	slog.Info("mid-turn compaction: folded messages into a digest", "messages", to-from,
+		"tokens_before", estimateMessageTokens(messages), "tokens_after", estimateMessageTokens(out))
+	return out
+}
+
+// summarizeToolRounds asks the model for a compact digest of tool activity.
+func (c *NanoCore) summarizeToolRounds(ctx context.Context, chat runChat, span []providers.Message) (string, error) {
+	transcript := renderToolRounds(span, 0)
+	if len(transcript) > digestInputChars {
+		transcript = transcript[:digestInputChars] + "\n...(truncated)"
+	}
+	resp, err := chat(ctx, providers.ChatRequest{
+		Messages: []providers.Message{
+			{Role: "system", Content: "You compress an AI agent's tool activity into a digest the agent will use to continue its task. Keep every fact it may still need: file paths, IDs, names, numbers, URLs, commands run, errors, and what is already done. Drop raw output that was only read in passing. Plain text, terse bullet lines starting with -, no preamble."},
+			{Role: "user", Content: transcript},
+		},
+		Temperature: 0.2,
+	})
+	if err != nil {
+		return "", err
+	}
+	digest := strings.TrimSpace(resp.Content)
+	if digest == "" || len(resp.ToolCalls) > 0 {
+		return "", fmt.Errorf("empty digest")
+	}
+	return digest, nil
+}
+
+// fallbackDigest lists each tool call with the start of its result, for when
+// the summarizer is unavailable.
+func fallbackDigest(span []providers.Message) string {
+	return renderToolRounds(span, fallbackResultChars)
+}
+
+// renderToolRounds writes tool calls and results as plain text; resultChars > 0
+// truncates each result.
+func renderToolRounds(span []providers.Message, resultChars int) string {
+	names := make(map[string]string)
+	var sb strings.Builder
+	for _, m := range span {
+		switch {
+		case m.Role == "assistant" && len(m.ToolCalls) > 0:
+			if note := strings.TrimSpace(m.Content); note != "" {
+				sb.WriteString(fmt.Sprintf("- note: %s\n", note))
+			}
+			for _, tc := range m.ToolCalls {
+				fn, _ := tc["function"].(map[string]interface{})
+				name, _ := fn["name"].(string)
+				args, _ := fn["arguments"].(string)
+				if id, ok := tc["id"].(string); ok {
+					names[id] = name
+				}
+				sb.WriteString(fmt.Sprintf("- called %s(%s)\n", name, args))
+			}
+		case m.Role == "tool":
+			result := strings.TrimSpace(m.Content)
+			if resultChars > 0 && len(result) > resultChars {
+				result = result[:resultChars] + "..."
+			}
+			sb.WriteString(fmt.Sprintf("  %s -> %s\n", names[m.ToolCallID], strings.ReplaceAll(result, "\n", "\n  ")))
+		case m.Role == "user" && m.Content == toolReflectionPrompt:
+			// reflection prompts carry no information
+		default:
+			sb.WriteString(fmt.Sprintf("- %s: %s\n", m.Role, strings.TrimSpace(m.Content)))
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -3,16 +3,27 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"runtime/debug"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"littleclaw/pkg/bus"
+	"littleclaw/pkg/events"
 	"littleclaw/pkg/memory"
+	"littleclaw/pkg/redact"
+	"littleclaw/pkg/telemetry"
+	"littleclaw/pkg/tools"
 
 	"github.com/robfig/cron/v3"
 )
@@ -25,30 +36,123 @@ type CronJobState struct {
 	LastDurationMs    int64  `json:"lastDurationMs,omitempty"`
 	ConsecutiveErrors int    `json:"consecutiveErrors"`
 	LastError         string `json:"lastError,omitempty"`
+	LastExitCode      int    `json:"lastExitCode,omitempty"` // shell jobs; -1 if the command could not run
+	LastOutput        string `json:"lastOutput,omitempty"`   // start of the last run's output
 }
 
+// DefaultCronJobTimeout bounds a job run unless the job or SetDefaultTimeout
+// says otherwise. A run that takes longer is cancelled and recorded as an error.
+const DefaultCronJobTimeout = 10 * time.Minute
+
+// Cron job types.
+const (
+	CronJobShell   = "shell"   // Command runs with sh -c; its output is the result
+	CronJobAgent   = "agent"   // Command is an instruction run through the agent loop
+	CronJobMessage = "message" // Command is reminder text sent to the user as is
+)
+
 // CronJob represents a single scheduled task persisted to disk.
 type CronJob struct {
-	ID       string       `json:"id"`
-	Schedule string       `json:"schedule"` // robfig cron expression, e.g. "@every 10s" or "*/5 * * * *"
-	Command  string       `json:"command"`  // shell command OR description for the LLM to run in exec
-	ChatID   string       `json:"chat_id"`  // Telegram chat ID to reply to
-	Channel  string       `json:"channel"`  // channel to respond on (e.g. "telegram")
-	Label    string       `json:"label"`    // human-readable label shown to user
-	Silent   bool         `json:"silent"`   // if true, output is logged internally but not sent to user
-	Once     bool         `json:"once"`     // if true, job is removed after one execution
-	State    CronJobState `json:"state"`
+	ID         string            `json:"id"`
+	Type       string            `json:"type,omitempty"`        // CronJobShell (default), CronJobAgent, or CronJobMessage
+	Schedule   string            `json:"schedule"`              // robfig cron expression, e.g. "@every 10s" or "*/5 * * * *"; "@at <RFC3339>" fires once
+	Command    string            `json:"command"`               // shell command, or the instruction for agent jobs
+	ChatID     string            `json:"chat_id"`               // Telegram chat ID to reply to
+	Channel    string            `json:"channel"`               // channel to respond on (e.g. "telegram")
+	Label      string            `json:"label"`                 // human-readable label shown to user
+	Silent     bool              `json:"silent"`                // if true, output is logged internally but not sent to user
+	Once       bool              `json:"once"`                  // if true, job is removed after one execution
+	Enabled    *bool             `json:"enabled,omitempty"`     // nil or true runs on schedule; false is paused
+	Timeout    int               `json:"timeout_sec,omitempty"` // seconds a run may take; 0 uses the service default
+	Retries    int               `json:"retries,omitempty"`     // extra attempts after a failed run, with exponential backoff
+	Alert      string            `json:"alert,omitempty"`       // failure alerts: AlertAlways, AlertConsecutive, or AlertNever
+	AlertAfter int               `json:"alert_after,omitempty"` // consecutive failures before an AlertConsecutive alert (default 3)
+	Summarize  bool              `json:"summarize,omitempty"`   // shell jobs: send the output through the agent as a digest instead of raw
+	Digest     string            `json:"digest,omitempty"`      // optional formatting instruction for Summarize
+	Env        map[string]string `json:"env,omitempty"`         // shell jobs: extra environment variables, e.g. API keys
+	WorkDir    string            `json:"workdir,omitempty"`     // shell jobs: directory relative to the workspace (default: the workspace)
+	State      CronJobState      `json:"state"`
+}
+
+// Failure alert policies for CronJob.Alert.
+const (
+	AlertAlways      = "always"      // every failed run is reported (default for non-silent jobs)
+	AlertConsecutive = "consecutive" // report once AlertAfter runs in a row have failed, and the recovery
+	AlertNever       = "never"       // failures are only recorded (default for silent jobs)
+)
+
+const (
+	// defaultAlertAfter is the AlertConsecutive threshold when AlertAfter is unset.
+	defaultAlertAfter = 3
+	// MaxCronRetries caps CronJob.Retries.
+	MaxCronRetries = 5
+	// DefaultCronRetryBackoff is the wait before the first retry; it doubles
+	// for each further attempt up to maxCronRetryBackoff.
+	DefaultCronRetryBackoff = 30 * time.Second
+	maxCronRetryBackoff     = 10 * time.Minute
+)
+
+// alertPolicy returns the job's effective failure alert policy.
+func (j *CronJob) alertPolicy() string {
+	switch j.Alert {
+	case AlertAlways, AlertConsecutive, AlertNever:
+		return j.Alert
+	}
+	if j.Silent {
+		return AlertNever
+	}
+	return AlertAlways
+}
+
+// alertThreshold returns how many consecutive failures trigger an AlertConsecutive alert.
+func (j *CronJob) alertThreshold() int {
+	if j.AlertAfter > 0 {
+		return j.AlertAfter
+	}
+	return defaultAlertAfter
 }
 
+// envNameRe matches valid environment variable names.
+var envNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// IsEnabled reports whether the job runs on its schedule (i.e. is not paused).
+func (j *CronJob) IsEnabled() bool { return j.Enabled == nil || *j.Enabled }
+
+// IsAgent reports whether the job runs an instruction through the agent loop.
+func (j *CronJob) IsAgent() bool { return j.Type == CronJobAgent }
+
 // CronRunRecord is one line appended to the per-job JSONL run log.
 type CronRunRecord struct {
 	Ts          int64  `json:"ts"`
 	JobID       string `json:"jobId"`
-	Action      string `json:"action"`             // always "finished"
-	Status      string `json:"status"`             // "ok" | "error"
+	Action      string `json:"action"` // always "finished"
+	Status      string `json:"status"` // "ok" | "error"
 	DurationMs  int64  `json:"durationMs"`
 	NextRunAtMs int64  `json:"nextRunAtMs,omitempty"`
 	Error       string `json:"error,omitempty"`
+	ExitCode    int    `json:"exitCode,omitempty"`
+	Output      string `json:"output,omitempty"`   // first cronOutputSnippetChars of the output
+	Attempts    int    `json:"attempts,omitempty"` // runs including retries, when more than one
+}
+
+// cronDigestInputChars caps the output handed to the agent for a digest; longer
+// output keeps its head and tail.
+const cronDigestInputChars = 20000
+
+// defaultDigestInstruction is used when a summarized job sets no Digest.
+const defaultDigestInstruction = "Turn it into a short, readable digest for the user: lead with what matters (results, errors, changes), drop noise, and keep any numbers or names they would want."
+
+// cronOutputSnippetChars caps the output kept in run records and job state.
+const cronOutputSnippetChars = 500
+
+// cronRunResult is the outcome of one job execution.
+type cronRunResult struct {
+	message  string // what the user is sent
+	status   string // "ok" | "error"
+	err      string
+	exitCode int
+	output   string
+	timedOut bool
 }
 
 // CronService manages persistent, file-backed cron jobs and runs them on schedule.
@@ -57,11 +161,26 @@ type CronService struct {
 	jobs         map[string]*CronJob
 	entryIDs     map[string]cron.EntryID
 	cronRunner   *cron.Cron
-	dataFile     string // absolute path to CRON.json
-	RunsDir      string // absolute path to cron/runs/ directory
+	dataFile     string        // absolute path to CRON.json
+	RunsDir      string        // absolute path to cron/runs/ directory
+	RetryBackoff time.Duration // wait before a job's first retry (DefaultCronRetryBackoff)
 	workspaceDir string
 	msgBus       *bus.MessageBus
 	memStore     *memory.Store
+
+	// agentTrigger runs agent jobs through the agent loop (see SetAgentTrigger).
+	agentTrigger func(ctx context.Context, msg bus.InboundMessage)
+	ctx          context.Context // from Start; cancels running jobs on shutdown
+
+	// alert reports repeated failures and panics to the admin (see SetAlertFunc).
+	alert func(kind, key, text string)
+	// events gets a CronFired after every run (see SetEvents).
+	events *events.Bus
+
+	defaultTimeout time.Duration // 0 means DefaultCronJobTimeout
+
+	// sandbox runs shell jobs in an OS sandbox (see SetSandbox); nil runs them directly.
+	sandbox atomic.Pointer[tools.Sandbox]
 }
 
 // NewCronService creates a CronService backed by $workspace/CRON.json.
@@ -73,40 +192,105 @@ func NewCronService(workspaceDir string, msgBus *bus.MessageBus, mem *memory.Sto
 		cronRunner:   cron.New(cron.WithSeconds()),
 		dataFile:     filepath.Join(workspaceDir, "CRON.json"),
 		RunsDir:      runsDir,
+		RetryBackoff: DefaultCronRetryBackoff,
 		workspaceDir: workspaceDir,
 		msgBus:       msgBus,
 		memStore:     mem,
 	}
 }
 
+// SetAgentTrigger sets the function agent jobs are run with, normally
+// NanoCore.RunAgentLoop. Without it agent jobs fail.
+func (cs *CronService) SetAgentTrigger(trigger func(ctx context.Context, msg bus.InboundMessage)) {
+	cs.agentTrigger = trigger
+}
+
+// SetAlertFunc sets where repeated job failures and panics in job runs are
+// reported (see NanoCore.EnableAdminAlerts).
+func (cs *CronService) SetAlertFunc(alert func(kind, key, text string)) {
+	cs.alert = alert
+}
+
+// SetEvents sets the bus job runs are published on.
+func (cs *CronService) SetEvents(b *events.Bus) {
+	cs.events = b
+}
+
+// SetSandbox runs shell jobs inside s; nil runs them directly.
+func (cs *CronService) SetSandbox(s *tools.Sandbox) {
+	cs.sandbox.Store(s)
+}
+
+// SetDefaultTimeout sets how long a job run may take when the job does not
+// set its own timeout. Zero restores DefaultCronJobTimeout.
+func (cs *CronService) SetDefaultTimeout(d time.Duration) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.defaultTimeout = d
+}
+
+// baseContext returns the context passed to Start, or a background context
+// before the service has started.
+func (cs *CronService) baseContext() context.Context {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if cs.ctx == nil {
+		return context.Background()
+	}
+	return cs.ctx
+}
+
+// runContext returns the context a run of job executes under, derived from
+// parent and bounded by the job's timeout, and the timeout itself.
+func (cs *CronService) runContext(parent context.Context, job *CronJob) (context.Context, context.CancelFunc, time.Duration) {
+	cs.mu.Lock()
+	timeout := cs.defaultTimeout
+	cs.mu.Unlock()
+	if job.Timeout > 0 {
+		timeout = time.Duration(job.Timeout) * time.Second
+	}
+	if timeout <= 0 {
+		timeout = DefaultCronJobTimeout
+	}
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	return ctx, cancel, timeout
+}
+
 // Start loads persisted jobs and begins the cron scheduler.
 func (cs *CronService) Start(ctx context.Context) error {
+	cs.mu.Lock()
+	cs.ctx = ctx
+	cs.mu.Unlock()
+
 	// Ensure the runs directory exists
 	if err := os.MkdirAll(cs.RunsDir, 0755); err != nil {
 		return fmt.Errorf("failed to create cron runs dir: %w", err)
 	}
 
 	if err := cs.load(); err != nil {
-		log.Printf("⏰ CronService: no existing jobs loaded (%v), starting fresh\n", err)
+		slog.Info("cron service: no existing jobs loaded, starting fresh", "err", err)
 	}
 
-	// Schedule all loaded jobs
+	// Schedule all loaded jobs; paused ones stay unscheduled
 	cs.mu.Lock()
 	for id, job := range cs.jobs {
+		if !job.IsEnabled() {
+			continue
+		}
 		if err := cs.schedule(job); err != nil {
-			log.Printf("⏰ CronService: failed to schedule job %s: %v\n", id, err)
+			slog.Warn("cron service: failed to schedule job", "job_id", id, "err", err)
 		}
 	}
 	cs.mu.Unlock()
 
 	cs.cronRunner.Start()
-	log.Printf("⏰ CronService started with %d job(s)\n", len(cs.jobs))
+	slog.Info("cron service started", "jobs", len(cs.jobs))
 
 	// Stop when context is cancelled
 	go func() {
 		<-ctx.Done()
 		cs.cronRunner.Stop()
-		log.Println("⏰ CronService stopped")
+		slog.Info("cron service stopped")
 	}()
 
 	return nil
@@ -118,17 +302,22 @@ func (cs *CronService) AddJob(job *CronJob) error {
 	defer cs.mu.Unlock()
 
 	// If a job with this ID already exists, remove it first (un-schedule)
-	if oldJob, exists := cs.jobs[job.ID]; exists {
-		log.Printf("⏰ CronService: replacing existing job %s\n", job.ID)
-		if entryID, ok := cs.entryIDs[oldJob.ID]; ok {
-			cs.cronRunner.Remove(entryID)
-			delete(cs.entryIDs, oldJob.ID)
-		}
+	if _, exists := cs.jobs[job.ID]; exists {
+		slog.Info("cron service: replacing existing job", "job_id", job.ID)
+		cs.unschedule(job.ID)
 	}
 
+	if err := cs.validate(job); err != nil {
+		return err
+	}
 	if err := cs.schedule(job); err != nil {
 		return fmt.Errorf("invalid schedule %q: %w", job.Schedule, err)
 	}
+	if !job.IsEnabled() {
+		// Validated above; a paused job is kept off the scheduler
+		cs.unschedule(job.ID)
+		job.State.NextRunAtMs = 0
+	}
 
 	// Populate NextRunAtMs from the freshly-added robfig entry
 	if entryID, ok := cs.entryIDs[job.ID]; ok {
@@ -142,22 +331,114 @@ func (cs *CronService) AddJob(job *CronJob) error {
 	return cs.save()
 }
 
+// UpdateJob applies patch to a copy of the job and, if the result still has a
+// valid schedule, atomically replaces and reschedules it. The ID cannot change.
+// On error the job is left as it was.
+func (cs *CronService) UpdateJob(id string, patch func(j *CronJob)) (*CronJob, error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	old, ok := cs.jobs[id]
+	if !ok {
+		return nil, fmt.Errorf("job %q not found", id)
+	}
+	updated := *old
+	updated.Env = maps.Clone(old.Env)
+	patch(&updated)
+	updated.ID = id
+	if err := cs.validate(&updated); err != nil {
+		return nil, err
+	}
+
+	cs.unschedule(id)
+	if err := cs.schedule(&updated); err != nil {
+		if old.IsEnabled() {
+			_ = cs.schedule(old)
+		}
+		return nil, fmt.Errorf("invalid schedule %q: %w", updated.Schedule, err)
+	}
+	updated.State.NextRunAtMs = 0
+	if !updated.IsEnabled() {
+		cs.unschedule(id)
+	} else if entry := cs.cronRunner.Entry(cs.entryIDs[id]); !entry.Next.IsZero() {
+		updated.State.NextRunAtMs = entry.Next.UnixMilli()
+	}
+
+	cs.jobs[id] = &updated
+	return &updated, cs.save()
+}
+
 // RemoveJob removes a running job by its ID.
 func (cs *CronService) RemoveJob(id string) error {
 	cs.mu.Lock()
 	defer cs.mu.Unlock()
 
-	entryID, ok := cs.entryIDs[id]
-	if !ok {
+	if _, ok := cs.jobs[id]; !ok {
 		return fmt.Errorf("job %q not found", id)
 	}
 
-	cs.cronRunner.Remove(entryID)
-	delete(cs.entryIDs, id)
+	cs.unschedule(id)
 	delete(cs.jobs, id)
 	return cs.save()
 }
 
+// PauseJob stops a job from running on its schedule without deleting it.
+func (cs *CronService) PauseJob(id string) error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	job, ok := cs.jobs[id]
+	if !ok {
+		return fmt.Errorf("job %q not found", id)
+	}
+	if !job.IsEnabled() {
+		return fmt.Errorf("job %q is already paused", id)
+	}
+	cs.unschedule(id)
+	enabled := false
+	job.Enabled = &enabled
+	job.State.NextRunAtMs = 0
+	return cs.save()
+}
+
+// ResumeJob puts a paused job back on its schedule. A one-time job whose time
+// passed while paused fires right away.
+func (cs *CronService) ResumeJob(id string) error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	job, ok := cs.jobs[id]
+	if !ok {
+		return fmt.Errorf("job %q not found", id)
+	}
+	if job.IsEnabled() {
+		return fmt.Errorf("job %q is not paused", id)
+	}
+	if err := cs.schedule(job); err != nil {
+		return fmt.Errorf("invalid schedule %q: %w", job.Schedule, err)
+	}
+	job.Enabled = nil
+	if entry := cs.cronRunner.Entry(cs.entryIDs[id]); !entry.Next.IsZero() {
+		job.State.NextRunAtMs = entry.Next.UnixMilli()
+	}
+	return cs.save()
+}
+
+// FindJob returns the job with the given ID, or else the first job with that label.
+func (cs *CronService) FindJob(idOrLabel string) *CronJob {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if j, ok := cs.jobs[idOrLabel]; ok {
+		return j
+	}
+	for _, j := range cs.jobs {
+		if j.Label == idOrLabel {
+			return j
+		}
+	}
+	return nil
+}
+
 // ListJobs returns all currently scheduled jobs.
 func (cs *CronService) ListJobs() []*CronJob {
 	cs.mu.Lock()
@@ -172,17 +453,71 @@ func (cs *CronService) ListJobs() []*CronJob {
 
 // schedule adds a job to the robfig cron runner (must hold mu).
 func (cs *CronService) schedule(job *CronJob) error {
-	entryID, err := cs.cronRunner.AddFunc(job.Schedule, cs.runnerFor(job))
+	at, isAt, err := parseAtSchedule(job.Schedule)
 	if err != nil {
 		return err
 	}
+	var entryID cron.EntryID
+	if isAt {
+		entryID = cs.cronRunner.Schedule(&onceSchedule{at: at}, cron.FuncJob(cs.runnerFor(job)))
+	} else if entryID, err = cs.cronRunner.AddFunc(job.Schedule, cs.runnerFor(job)); err != nil {
+		return err
+	}
 	cs.entryIDs[job.ID] = entryID
 	return nil
 }
 
+// validate checks a job's environment variable names and working directory.
+func (cs *CronService) validate(job *CronJob) error {
+	for name := range job.Env {
+		if !envNameRe.MatchString(name) {
+			return fmt.Errorf("invalid environment variable name %q", name)
+		}
+	}
+	if job.WorkDir != "" {
+		if _, err := cs.jobDir(job); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jobDir returns the absolute directory a shell job runs in, which must be
+// inside the workspace.
+func (cs *CronService) jobDir(job *CronJob) (string, error) {
+	if job.WorkDir == "" {
+		return cs.workspaceDir, nil
+	}
+	if filepath.IsAbs(job.WorkDir) {
+		return "", fmt.Errorf("workdir %q must be relative to the workspace", job.WorkDir)
+	}
+	dir := filepath.Clean(filepath.Join(cs.workspaceDir, job.WorkDir))
+	if dir != cs.workspaceDir && !strings.HasPrefix(dir, cs.workspaceDir+string(filepath.Separator)) {
+		return "", fmt.Errorf("workdir %q escapes the workspace", job.WorkDir)
+	}
+	return dir, nil
+}
+
+// unschedule removes a job from the robfig cron runner (must hold mu).
+func (cs *CronService) unschedule(id string) {
+	if entryID, ok := cs.entryIDs[id]; ok {
+		cs.cronRunner.Remove(entryID)
+		delete(cs.entryIDs, id)
+	}
+}
+
 // runnerFor returns the function that executes the job and messages the user.
 func (cs *CronService) runnerFor(job *CronJob) func() {
 	return func() {
+		defer func() {
+			if r := recover(); r != nil {
+				slog.Error("cron service: job panicked", "job_id", job.ID, "panic", r, "stack", string(debug.Stack()))
+				if cs.alert != nil {
+					cs.alert(AdminAlertPanic, "cron "+job.ID, fmt.Sprintf("cron job `%s` panicked: %v", job.Label, r))
+				}
+			}
+		}()
+
 		// Verify job still exists (it might have been removed by a near-simultaneous tick for a one-time job)
 		cs.mu.Lock()
 		_, exists := cs.jobs[job.ID]
@@ -192,49 +527,37 @@ func (cs *CronService) runnerFor(job *CronJob) func() {
 		}
 
 		if job.Once {
-			log.Printf("⏰ CronService: firing one-time job %s, removing immediately\n", job.ID)
+			slog.Info("cron service: firing one-time job", "job_id", job.ID, "label", job.Label, "chat_id", job.ChatID)
 			_ = cs.RemoveJob(job.ID)
 		} else {
-			log.Printf("⏰ CronService: firing job %s (%s)\n", job.ID, job.Label)
+			slog.Info("cron service: firing job", "job_id", job.ID, "label", job.Label, "chat_id", job.ChatID)
 		}
 
 		start := time.Now()
-		cmd := exec.Command("sh", "-c", job.Command)
-		cmd.Dir = cs.workspaceDir
-
-		output, err := cmd.CombinedOutput()
+		res, attempts := cs.runWithRetries(job)
 		durationMs := time.Since(start).Milliseconds()
 
-		var msg string
-		var runStatus string
-		var runErr string
-
-		if err != nil {
-			runStatus = "error"
-			runErr = err.Error()
-			msg = fmt.Sprintf("⚠️ Cron job `%s` failed:\n```\n%s\n```", job.Label, output)
-		} else {
-			runStatus = "ok"
-			trimmed := string(output)
-			if trimmed == "" {
-				trimmed = "(no output)"
-			}
-			msg = trimmed
-		}
-
 		// Update in-memory state and persist
+		consecutive, prevConsecutive := 0, 0
+		if res.status == "error" {
+			consecutive = 1 // one-time jobs have no live state
+		}
 		cs.mu.Lock()
 		if liveJob, ok := cs.jobs[job.ID]; ok {
+			prevConsecutive = liveJob.State.ConsecutiveErrors
 			liveJob.State.LastRunAtMs = start.UnixMilli()
 			liveJob.State.LastDurationMs = durationMs
-			liveJob.State.LastStatus = runStatus
-			if runStatus == "error" {
+			liveJob.State.LastStatus = res.status
+			liveJob.State.LastExitCode = res.exitCode
+			liveJob.State.LastOutput = res.output
+			if res.status == "error" {
 				liveJob.State.ConsecutiveErrors++
-				liveJob.State.LastError = runErr
+				liveJob.State.LastError = res.err
 			} else {
 				liveJob.State.ConsecutiveErrors = 0
 				liveJob.State.LastError = ""
 			}
+			consecutive = liveJob.State.ConsecutiveErrors
 			// Refresh next run time from the scheduler
 			if entryID, ok := cs.entryIDs[job.ID]; ok {
 				entry := cs.cronRunner.Entry(entryID)
@@ -247,58 +570,335 @@ func (cs *CronService) runnerFor(job *CronJob) func() {
 		cs.mu.Unlock()
 
 		// Append run record to per-job JSONL log
-		cs.RecordRun(job.ID, runStatus, runErr, durationMs)
+		cs.appendRunRecord(CronRunRecord{
+			JobID:      job.ID,
+			Status:     res.status,
+			DurationMs: durationMs,
+			Error:      res.err,
+			ExitCode:   res.exitCode,
+			Output:     res.output,
+			Attempts:   attempts,
+		})
+		cs.events.Publish(events.CronFired{
+			JobID:       job.ID,
+			Label:       job.Label,
+			Status:      res.status,
+			Duration:    time.Duration(durationMs) * time.Millisecond,
+			Error:       res.err,
+			Attempts:    attempts,
+			Consecutive: consecutive,
+		})
 
-		// Send result to the user's Telegram chat if not silent
-		if !job.Silent && job.ChatID != "" && job.Channel != "" {
+		// Send the result to the user's chat: output of successful runs unless silent
+		// (agent jobs reply on their own), failures as the alert policy says
+		notice := cs.notice(job, res, attempts, consecutive, prevConsecutive)
+		if notice != "" && res.status == "ok" && job.Summarize && job.ChatID != "" && cs.agentTrigger != nil {
+			cs.sendDigest(job, notice)
+		} else if notice != "" && job.ChatID != "" && job.Channel != "" {
 			cs.msgBus.SendOutbound(bus.OutboundMessage{
-				Channel: job.Channel,
-				ChatID:  job.ChatID,
-				Content: msg,
+				Channel:  job.Channel,
+				ChatID:   job.ChatID,
+				Content:  notice,
+				Priority: bus.PriorityCron,
 			})
 		}
 
+		// Failures the user may never hear about (silent jobs, other policies) reach the admin
+		if res.status == "error" && consecutive == job.alertThreshold() && cs.alert != nil {
+			cs.alert(AdminAlertCron, job.ID, fmt.Sprintf("cron job `%s` has failed %d runs in a row. Latest error: %s", job.Label, consecutive, truncateLabel(res.err, 200)))
+		}
+
 		// Log to INTERNAL.md for agent reflection
-		logMsg := fmt.Sprintf("[Cron Job Runtime] Job '%s' (%s) fired. Status: %s. Duration: %dms. Result: %s", job.Label, job.ID, runStatus, durationMs, msg)
+		logMsg := fmt.Sprintf("[Cron Job Runtime] Job '%s' (%s) fired. Status: %s. Duration: %dms. Result: %s", job.Label, job.ID, res.status, durationMs, res.message)
 		cs.memStore.AppendInternal("CRON", logMsg)
 	}
 }
 
-// recordRun appends a CronRunRecord to the per-job JSONL file in the runs directory.
+// runWithRetries runs a job, retrying failed runs up to job.Retries times with
+// exponential backoff. It returns the last result and the number of attempts.
+func (cs *CronService) runWithRetries(job *CronJob) (cronRunResult, int) {
+	ctx := cs.baseContext()
+	retries := min(job.Retries, MaxCronRetries)
+	backoff := cs.RetryBackoff
+
+	res := cs.runOnce(job)
+	attempts := 1
+	for res.status == "error" && attempts <= retries {
+		slog.Warn("cron service: job failed, retrying", "job_id", job.ID, "err", res.err, "backoff", backoff, "attempt", attempts, "retries", retries)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return res, attempts
+		}
+		backoff = min(backoff*2, maxCronRetryBackoff)
+		res = cs.runOnce(job)
+		attempts++
+	}
+	return res, attempts
+}
+
+// runOnce runs a job a single time according to its type.
+func (cs *CronService) runOnce(job *CronJob) cronRunResult {
+	ctx, span := telemetry.Start(cs.baseContext(), "cron.run", "job_id", job.ID, "job_label", job.Label, "job_type", job.Type)
+	defer span.End()
+
+	var res cronRunResult
+	if job.IsAgent() {
+		res = cs.runAgentJob(ctx, job)
+	} else if job.Type == CronJobMessage {
+		res = cronRunResult{message: "⏰ " + job.Command, status: "ok", output: job.Command}
+	} else {
+		res = cs.runShellJob(ctx, job)
+	}
+	res.output = outputSnippet(res.output)
+	if res.status == "error" {
+		span.SetError(errors.New(res.err))
+	}
+	return res
+}
+
+// notice returns what to send the user after a run, or "" to send nothing.
+// consecutive is the failure streak including this run, prev the one before it.
+func (cs *CronService) notice(job *CronJob, res cronRunResult, attempts, consecutive, prev int) string {
+	policy := job.alertPolicy()
+	if res.status != "error" {
+		var msg string
+		if !job.Silent && !job.IsAgent() {
+			msg = res.message
+		}
+		if policy == AlertConsecutive && prev >= job.alertThreshold() {
+			recovered := fmt.Sprintf("✅ Cron job `%s` is working again after %d failed run(s).", job.Label, prev)
+			msg = strings.TrimSpace(recovered + "\n\n" + msg)
+		}
+		return msg
+	}
+
+	// Runs cut short by shutdown are not worth an alert
+	cs.mu.Lock()
+	ctx := cs.ctx
+	cs.mu.Unlock()
+	if ctx != nil && ctx.Err() != nil {
+		return ""
+	}
+
+	msg := res.message
+	if attempts > 1 {
+		msg += fmt.Sprintf("\n(failed %d attempts)", attempts)
+	}
+	switch policy {
+	case AlertNever:
+		return ""
+	case AlertConsecutive:
+		if consecutive != job.alertThreshold() {
+			return ""
+		}
+		return fmt.Sprintf("🚨 Cron job `%s` has failed %d runs in a row. Latest failure:\n%s", job.Label, consecutive, msg)
+	}
+	return msg
+}
+
+// sendDigest hands a job's output to the agent loop in the job's chat with a
+// summarization instruction; the agent sends the digest itself.
+func (cs *CronService) sendDigest(job *CronJob, output string) {
+	if len(output) > cronDigestInputChars {
+		half := cronDigestInputChars / 2
+		output = output[:half] + "\n\n... [output truncated] ...\n\n" + output[len(output)-half:]
+	}
+	instruction := job.Digest
+	if instruction == "" {
+		instruction = defaultDigestInstruction
+	}
+
+	// The command line can carry a secret too (e.g. a token argument); the
+	// prompt reaches the model and the session history.
+	prompt := redact.String(fmt.Sprintf("[CRON OUTPUT: %s]\nThe scheduled command `%s` produced the output below. %s Reply with the digest only; do not run the command again.\n```\n%s\n```",
+		job.Label, job.Command, instruction, output))
+
+	ctx, cancel, _ := cs.runContext(cs.baseContext(), job)
+	defer cancel()
+	cs.agentTrigger(ctx, bus.InboundMessage{
+		Channel:  job.Channel,
+		SenderID: "system",
+		ChatID:   job.ChatID,
+		Content:  prompt,
+		Priority: bus.PriorityCron,
+	})
+}
+
+// runShellJob runs a shell job with sh -c in the workspace.
+func (cs *CronService) runShellJob(parent context.Context, job *CronJob) cronRunResult {
+	ctx, cancel, timeout := cs.runContext(parent, job)
+	defer cancel()
+
+	dir, err := cs.jobDir(job)
+	if err == nil {
+		var info os.FileInfo
+		if info, err = os.Stat(dir); err == nil && !info.IsDir() {
+			err = fmt.Errorf("%s is not a directory", job.WorkDir)
+		}
+	}
+	if err != nil {
+		return cronRunResult{
+			message:  fmt.Sprintf("⚠️ Cron job `%s` cannot run: %v", job.Label, err),
+			status:   "error",
+			err:      err.Error(),
+			exitCode: -1,
+		}
+	}
+
+	cmd := cs.sandbox.Load().Command(ctx, cs.workspaceDir, dir, "sh", "-c", job.Command)
+	if len(job.Env) > 0 {
+		cmd.Env = os.Environ()
+		for name, value := range job.Env {
+			cmd.Env = append(cmd.Env, name+"="+value)
+		}
+	}
+	tools.GracefulCancel(cmd)
+
+	start := time.Now()
+	raw, err := cmd.CombinedOutput()
+	cs.publishCommand(job, start, err)
+	// The output goes to the chat, the run records, and digest prompts, so
+	// secrets are masked first, including the values the job's Env injected.
+	output := redact.String(string(raw))
+	if len(job.Env) > 0 {
+		output = redact.New(slices.Collect(maps.Values(job.Env))...).String(output)
+	}
+	res := cronRunResult{status: "ok", output: output}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		res.status, res.exitCode, res.timedOut = "error", -1, true
+		res.err = fmt.Sprintf("timed out after %s", timeout)
+		res.message = fmt.Sprintf("⏱️ Cron job `%s` timed out after %s and was stopped.", job.Label, timeout)
+		if len(output) > 0 {
+			res.message += fmt.Sprintf("\nOutput so far:\n```\n%s\n```", outputSnippet(output))
+		}
+		return res
+	}
+	if err != nil {
+		res.status, res.err, res.exitCode = "error", err.Error(), -1
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			res.exitCode = exitErr.ExitCode()
+		}
+		res.message = fmt.Sprintf("⚠️ Cron job `%s` failed:\n```\n%s\n```", job.Label, output)
+		return res
+	}
+	res.message = res.output
+	if res.message == "" {
+		res.message = "(no output)"
+	}
+	return res
+}
+
+// publishCommand publishes the CommandExecuted event for a shell job run
+// started at start that finished with err.
+func (cs *CronService) publishCommand(job *CronJob, start time.Time, err error) {
+	e := events.CommandExecuted{
+		Time:        start,
+		Source:      "cron",
+		Command:     job.Command,
+		RequestedBy: "cron:" + job.ID,
+		ChatID:      job.ChatID,
+		Channel:     job.Channel,
+		ExitCode:    tools.ExitCode(err),
+		Duration:    time.Since(start),
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	cs.events.Publish(e)
+}
+
+// outputSnippet trims output to cronOutputSnippetChars for run records.
+func outputSnippet(s string) string {
+	s = strings.TrimSpace(s)
+	if r := []rune(s); len(r) > cronOutputSnippetChars {
+		return string(r[:cronOutputSnippetChars]) + "…"
+	}
+	return s
+}
+
+// runAgentJob sends an agent job's instruction through the agent loop in the
+// job's chat, which replies to the user itself. Silent jobs run on the
+// internal channel so nothing is sent.
+func (cs *CronService) runAgentJob(parent context.Context, job *CronJob) cronRunResult {
+	if cs.agentTrigger == nil {
+		return cronRunResult{message: "agent jobs are not available", status: "error", err: "no agent loop configured"}
+	}
+	ctx, cancel, timeout := cs.runContext(parent, job)
+	defer cancel()
+
+	in := bus.InboundMessage{
+		Channel:  job.Channel,
+		SenderID: "system",
+		ChatID:   job.ChatID,
+		Content:  fmt.Sprintf("[SCHEDULED TASK: %s]\n%s", job.Label, job.Command),
+		Priority: bus.PriorityCron,
+	}
+	if job.Silent {
+		in.Channel, in.ChatID = "internal", "internal_memory"
+	}
+	cs.agentTrigger(ctx, in)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return cronRunResult{
+			message:  fmt.Sprintf("⏱️ Scheduled task `%s` timed out after %s and was stopped.", job.Label, timeout),
+			status:   "error",
+			err:      fmt.Sprintf("timed out after %s", timeout),
+			timedOut: true,
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return cronRunResult{message: "agent run interrupted", status: "error", err: err.Error()}
+	}
+	return cronRunResult{message: "agent run finished", status: "ok"}
+}
+
+// RunJobNow runs a job immediately and waits for it to finish, outside its
+// schedule. One-time jobs are removed as if they had fired.
+func (cs *CronService) RunJobNow(id string) error {
+	cs.mu.Lock()
+	job, ok := cs.jobs[id]
+	cs.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("job %q not found", id)
+	}
+	cs.runnerFor(job)()
+	return nil
+}
+
+// RecordRun appends a CronRunRecord to the per-job JSONL file in the runs directory.
 func (cs *CronService) RecordRun(jobID, status, errMsg string, durationMs int64) {
+	cs.appendRunRecord(CronRunRecord{JobID: jobID, Status: status, Error: errMsg, DurationMs: durationMs})
+}
+
+// appendRunRecord fills in the timestamp and next run of rec and appends it
+// to the job's run log.
+func (cs *CronService) appendRunRecord(rec CronRunRecord) {
 	if err := os.MkdirAll(cs.RunsDir, 0755); err != nil {
-		log.Printf("⏰ CronService: failed to create runs dir: %v\n", err)
+		slog.Warn("cron service: failed to create runs dir", "err", err)
 		return
 	}
 
 	// Compute nextRunAtMs from live state if available
 	cs.mu.Lock()
-	var nextRunAtMs int64
-	if j, ok := cs.jobs[jobID]; ok {
-		nextRunAtMs = j.State.NextRunAtMs
+	if j, ok := cs.jobs[rec.JobID]; ok {
+		rec.NextRunAtMs = j.State.NextRunAtMs
 	}
 	cs.mu.Unlock()
 
-	record := CronRunRecord{
-		Ts:          time.Now().UnixMilli(),
-		JobID:       jobID,
-		Action:      "finished",
-		Status:      status,
-		DurationMs:  durationMs,
-		NextRunAtMs: nextRunAtMs,
-		Error:       errMsg,
-	}
+	rec.Ts = time.Now().UnixMilli()
+	rec.Action = "finished"
 
-	data, err := json.Marshal(record)
+	data, err := json.Marshal(rec)
 	if err != nil {
-		log.Printf("⏰ CronService: failed to marshal run record: %v\n", err)
+		slog.Warn("cron service: failed to marshal run record", "job_id", rec.JobID, "err", err)
 		return
 	}
 
-	logPath := filepath.Join(cs.RunsDir, jobID+".jsonl")
+	logPath := filepath.Join(cs.RunsDir, rec.JobID+".jsonl")
 	f, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
-		log.Printf("⏰ CronService: failed to open run log %s: %v\n", logPath, err)
+		slog.Warn("cron service: failed to open run log", "path", logPath, "err", err)
 		return
 	}
 	defer f.Close()
@@ -390,7 +990,32 @@ func (cs *CronService) save() error {
 		return err
 	}
 
-	return os.WriteFile(cs.dataFile, data, 0644)
+	// 0600: jobs may carry secrets in Env
+	return os.WriteFile(cs.dataFile, data, 0600)
+}
+
+// PrepareCronJob checks a job created outside the agent (e.g. by `littleclaw
+// cron add`) and fills in its ID. check vets shell commands, normally against
+// the exec policy.
+func PrepareCronJob(job *CronJob, check func(cmd string) error) error {
+	if job.Label == "" || job.Schedule == "" || job.Command == "" {
+		return errors.New("label, schedule, and command are all required")
+	}
+	switch job.Type {
+	case "", CronJobShell:
+		job.Type = ""
+		if err := check(job.Command); err != nil {
+			return fmt.Errorf("command blocked by exec policy: %w", err)
+		}
+	case CronJobAgent, CronJobMessage:
+	default:
+		return fmt.Errorf("unknown type %q (use shell, agent, or message)", job.Type)
+	}
+	if job.ID == "" {
+		job.ID = GenerateJobID(job.Label)
+	}
+	job.Retries = min(job.Retries, MaxCronRetries)
+	return nil
 }
 
 // GenerateJobID creates a simple unique ID from a label
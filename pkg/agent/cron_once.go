@@ -0,0 +1,225 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"littleclaw/pkg/providers"
+	"littleclaw/pkg/tools"
+)
+
+// atSchedulePrefix marks a one-shot schedule: "@at <RFC3339 time>".
+const atSchedulePrefix = "@at "
+
+// AtSchedule returns the one-shot schedule expression for t.
+func AtSchedule(t time.Time) string {
+	return atSchedulePrefix + t.Format(time.RFC3339)
+}
+
+// parseAtSchedule parses an "@at" expression; ok is false for other schedules.
+func parseAtSchedule(spec string) (at time.Time, ok bool, err error) {
+	if !strings.HasPrefix(spec, atSchedulePrefix) {
+		return time.Time{}, false, nil
+	}
+	at, err = time.Parse(time.RFC3339, strings.TrimSpace(strings.TrimPrefix(spec, atSchedulePrefix)))
+	return at, true, err
+}
+
+// onceSchedule is a robfig schedule that fires a single time at at. A time
+// already in the past (e.g. missed while the bot was down) fires right away.
+type onceSchedule struct {
+	at        time.Time
+	scheduled time.Time // the activation handed to the runner; zero until then
+}
+
+// Next implements cron.Schedule. It is only called from the runner goroutine.
+func (s *onceSchedule) Next(t time.Time) time.Time {
+	if !s.scheduled.IsZero() && !t.Before(s.scheduled) {
+		return time.Time{} // already fired
+	}
+	if s.at.After(t) {
+		s.scheduled = s.at
+	} else {
+		s.scheduled = t
+	}
+	return s.scheduled
+}
+
+var (
+	relativeWhenRe = regexp.MustCompile(`^(?:in\s+)?(\d+(?:\.\d+)?|an?)\s*(s|secs?|seconds?|m|mins?|minutes?|h|hrs?|hours?|d|days?|w|weeks?)$`)
+	clockWhenRe    = regexp.MustCompile(`^(today|tomorrow)?\s*(?:at\s+)?(\d{1,2}:\d{2})$`)
+)
+
+// ParseWhen turns a reminder time into an absolute time after now. It accepts
+// relative durations ("in 45 minutes", "in 2h", "90m", "in an hour"), clock
+// times ("18:30", "tomorrow at 9:00"; a bare time that has passed means
+// tomorrow), and local dates ("2026-05-01 09:00", RFC 3339).
+func ParseWhen(when string, now time.Time) (time.Time, error) {
+	s := strings.ToLower(strings.TrimSpace(when))
+	if s == "" {
+		return time.Time{}, fmt.Errorf("time is empty")
+	}
+
+	var at time.Time
+	if m := relativeWhenRe.FindStringSubmatch(s); m != nil {
+		n := 1.0
+		if m[1] != "a" && m[1] != "an" {
+			n, _ = strconv.ParseFloat(m[1], 64)
+		}
+		at = now.Add(time.Duration(n * float64(whenUnit(m[2]))))
+	} else if d, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(s, "in "))); err == nil {
+		at = now.Add(d)
+	} else if m := clockWhenRe.FindStringSubmatch(s); m != nil {
+		clock, err := time.ParseInLocation("15:04", m[2], now.Location())
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid time %q", m[2])
+		}
+		at = time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+		if m[1] == "tomorrow" || (m[1] == "" && !at.After(now)) {
+			at = at.AddDate(0, 0, 1)
+		}
+	} else if t, err := time.Parse(time.RFC3339, strings.TrimSpace(when)); err == nil {
+		at = t
+	} else {
+		parsed := false
+		for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02 15:04:05"} {
+			if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+				at, parsed = t, true
+				break
+			}
+		}
+		if !parsed {
+			return time.Time{}, fmt.Errorf("cannot understand time %q (try 'in 45 minutes', '18:30', 'tomorrow at 9:00', or '2006-01-02 15:04')", when)
+		}
+	}
+
+	if !at.After(now) {
+		return time.Time{}, fmt.Errorf("%s is in the past", at.Format("2006-01-02 15:04"))
+	}
+	return at, nil
+}
+
+// whenUnit maps a relative-time unit to its duration.
+func whenUnit(u string) time.Duration {
+	switch u[0] {
+	case 's':
+		return time.Second
+	case 'm':
+		return time.Minute
+	case 'h':
+		return time.Hour
+	case 'd':
+		return 24 * time.Hour
+	default: // weeks
+		return 7 * 24 * time.Hour
+	}
+}
+
+// registerScheduleOnceTool adds schedule_once, which runs a reminder, shell
+// command, or agent instruction a single time and then removes the job.
+func (c *NanoCore) registerScheduleOnceTool() {
+	c.toolRegistry.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "schedule_once",
+			Description: "Schedule a one-time reminder or task at a specific time. Give either 'message' (sent to the user as-is when the time comes) or 'command' (a shell command whose output is sent, or with type 'agent' an instruction you carry out then). The job is removed after it fires. Use add_cron for recurring tasks.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"when": map[string]interface{}{
+						"type":        "string",
+						"description": "When to fire, in the user's local time: relative ('in 45 minutes', 'in 2h') or absolute ('18:30', 'tomorrow at 9:00', '2026-05-01 09:00').",
+					},
+					"message": map[string]interface{}{
+						"type":        "string",
+						"description": "Reminder text to send to the user.",
+					},
+					"command": map[string]interface{}{
+						"type":        "string",
+						"description": "Shell command to run, or the instruction for type 'agent'.",
+					},
+					"type": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{CronJobShell, CronJobAgent},
+						"description": "How to run 'command': 'shell' (default) or 'agent'.",
+					},
+					"label": map[string]interface{}{
+						"type":        "string",
+						"description": "Optional short label (defaults to the start of the message or command).",
+					},
+					"timeout_seconds": map[string]interface{}{
+						"type":        "integer",
+						"description": "Optional limit on how long the command may run (default 10 minutes).",
+					},
+				},
+				"required": []string{"when"},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *tools.ToolResult {
+		when, _ := args["when"].(string)
+		message, _ := args["message"].(string)
+		command, _ := args["command"].(string)
+		jobType, _ := args["type"].(string)
+		label, _ := args["label"].(string)
+		timeout, _ := args["timeout_seconds"].(float64)
+		message, command = strings.TrimSpace(message), strings.TrimSpace(command)
+
+		if (message == "") == (command == "") {
+			return &tools.ToolResult{ForLLM: "Error: give exactly one of message or command."}
+		}
+		at, err := ParseWhen(when, time.Now())
+		if err != nil {
+			return &tools.ToolResult{ForLLM: fmt.Sprintf("Error: %v", err)}
+		}
+
+		payload := command
+		switch {
+		case message != "":
+			jobType, payload = CronJobMessage, message
+		case jobType == "" || jobType == CronJobShell:
+			jobType = ""
+			if err := c.toolRegistry.CheckCommand(command); err != nil {
+				return &tools.ToolResult{ForLLM: fmt.Sprintf("Command blocked by exec policy: %v", err)}
+			}
+			if held := c.approveScheduledCommand(ctx, "schedule_once", command); held != nil {
+				return held
+			}
+		case jobType != CronJobAgent:
+			return &tools.ToolResult{ForLLM: fmt.Sprintf("Error: unknown type %q (use 'shell' or 'agent').", jobType)}
+		}
+
+		chatID, channel := c.replyTarget(ctx)
+		if chatID == "" {
+			return &tools.ToolResult{ForLLM: "Error: Cannot schedule a reminder from internal context without a prior user interaction."}
+		}
+		if label = strings.TrimSpace(label); label == "" {
+			label = truncateLabel(payload, 40)
+		}
+
+		job := &CronJob{
+			ID:       GenerateJobID(label) + "_" + at.Format("0102T1504"),
+			Type:     jobType,
+			Label:    label,
+			Schedule: AtSchedule(at),
+			Command:  payload,
+			ChatID:   chatID,
+			Channel:  channel,
+			Once:     true,
+			Timeout:  int(timeout),
+		}
+		if err := c.cronService.AddJob(job); err != nil {
+			return &tools.ToolResult{ForLLM: fmt.Sprintf("Failed to schedule: %v", err)}
+		}
+		return &tools.ToolResult{
+			ForLLM: fmt.Sprintf("Scheduled '%s' (ID: %s) for %s (in %s). It runs once and is then removed.", label, job.ID, at.Format("Mon 2006-01-02 15:04 MST"), time.Until(at).Round(time.Minute)),
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"littleclaw/pkg/bus"
+)
+
+const (
+	// debugCommand toggles debug mode in the chat that sends it.
+	debugCommand = "/debug"
+
+	// debugArgsChars and debugResultChars cap what a debug line shows of a
+	// tool call's arguments and result.
+	debugArgsChars   = 200
+	debugResultChars = 300
+)
+
+// debugChats holds the chats that turned on debug mode with /debug on. It is
+// not persisted, so a restart turns debug mode off everywhere.
+type debugChats struct {
+	mu sync.Mutex
+	on map[string]bool
+}
+
+func newDebugChats() *debugChats {
+	return &debugChats{on: make(map[string]bool)}
+}
+
+func (d *debugChats) set(chatID string, on bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if on {
+		d.on[chatID] = true
+	} else {
+		delete(d.on, chatID)
+	}
+}
+
+func (d *debugChats) enabled(chatID string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.on[chatID]
+}
+
+// parseDebugCommand reports whether content is /debug (optionally
+// /debug@botname) and returns its argument, if any.
+func parseDebugCommand(content string) (arg string, ok bool) {
+	fields := strings.Fields(strings.ToLower(content))
+	if len(fields) == 0 || len(fields) > 2 {
+		return "", false
+	}
+	if cmd, _, _ := strings.Cut(fields[0], "@"); cmd != debugCommand {
+		return "", false
+	}
+	if len(fields) == 2 {
+		arg = fields[1]
+	}
+	return arg, true
+}
+
+// handleDebugCommand answers /debug on, /debug off, and /debug.
+func (c *NanoCore) handleDebugCommand(chatID string, messageID int, channel, arg string) {
+	var reply string
+	switch arg {
+	case "on":
+		c.debug.set(chatID, true)
+		slog.Info("debug mode on", "chat_id", chatID)
+		reply = "🐞 Debug mode on. I'll show each tool call and the start of its result while I work. Send /debug off to stop."
+	case "off":
+		c.debug.set(chatID, false)
+		slog.Info("debug mode off", "chat_id", chatID)
+		reply = "Debug mode off."
+	case "":
+		reply = "Debug mode is off. Send /debug on to see each tool call while I work."
+		if c.debug.enabled(chatID) {
+			reply = "🐞 Debug mode is on. Send /debug off to stop."
+		}
+	default:
+		reply = "Usage: /debug on or /debug off"
+	}
+	c.sendResponse(chatID, messageID, channel, reply, nil)
+}
+
+// runDebug mirrors a run's tool calls to a chat in debug mode; a nil
+// runDebug sends nothing.
+type runDebug struct {
+	c     *NanoCore
+	msg   bus.InboundMessage
+	start time.Time
+	tools int
+}
+
+// newRunDebug returns the debug reporter for a run, or nil unless the chat
+// turned debug mode on. Internal runs never get one.
+func (c *NanoCore) newRunDebug(msg bus.InboundMessage) *runDebug {
+	if msg.Channel == "internal" || !c.debug.enabled(msg.ChatID) {
+		return nil
+	}
+	return &runDebug{c: c, msg: msg, start: time.Now()}
+}
+
+// toolCall sends one line for a finished tool call: its arguments, how long
+// it took, and the start of the result the model saw.
+func (d *runDebug) toolCall(name string, args map[string]interface{}, result string, took time.Duration) {
+	if d == nil {
+		return
+	}
+	d.tools++
+	argsText := "{}"
+	if len(args) > 0 {
+		if data, err := json.Marshal(args); err == nil {
+			argsText = string(data)
+		}
+	}
+	text := fmt.Sprintf("🐞 %s %s (%s)\n→ %s", name, truncateLabel(argsText, debugArgsChars),
+		took.Round(time.Millisecond), truncateLabel(result, debugResultChars))
+	d.c.sendNotice(d.msg, text)
+}
+
+// done sends the closing line of the run.
+func (d *runDebug) done(calls, tokens int) {
+	if d == nil {
+		return
+	}
+	text := fmt.Sprintf("🐞 Run finished in %s: %d model call(s), %d tool call(s), %d tokens.",
+		time.Since(d.start).Round(100*time.Millisecond), calls, d.tools, tokens)
+	d.c.sendNotice(d.msg, text)
+}
@@ -0,0 +1,11 @@
+//go:build !linux && !darwin
+
+package agent
+
+import "errors"
+
+// diskFreeMB is not implemented on this platform; the disk check only tests
+// that the workspace is writable.
+func diskFreeMB(string) (int64, error) {
+	return 0, errors.New("not supported on this platform")
+}
@@ -0,0 +1,15 @@
+//go:build linux || darwin
+
+package agent
+
+import "syscall"
+
+// diskFreeMB returns the space available to unprivileged users on the
+// filesystem holding path, in MB.
+func diskFreeMB(path string) (int64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, err
+	}
+	return int64(uint64(st.Bavail) * uint64(st.Bsize) / (1 << 20)), nil
+}
@@ -0,0 +1,380 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/feeds"
+	"littleclaw/pkg/providers"
+	"littleclaw/pkg/tools"
+)
+
+const (
+	// DefaultFeedPollInterval is how often subscribed feeds are checked.
+	DefaultFeedPollInterval = 30 * time.Minute
+	// maxSeenPerFeed bounds the remembered item IDs per feed.
+	maxSeenPerFeed = 500
+	// maxItemsPerDigest caps how many new items one message lists.
+	maxItemsPerDigest = 5
+)
+
+// FeedSubscription is one RSS/Atom feed persisted in FEEDS.json.
+type FeedSubscription struct {
+	ID          string   `json:"id"`
+	URL         string   `json:"url"`
+	Title       string   `json:"title"`
+	ChatID      string   `json:"chat_id"`
+	Channel     string   `json:"channel"`
+	Seen        []string `json:"seen"` // item IDs already delivered, newest last
+	AddedAtMs   int64    `json:"addedAtMs"`
+	LastCheckMs int64    `json:"lastCheckMs,omitempty"`
+	LastError   string   `json:"lastError,omitempty"`
+}
+
+// FeedService polls subscribed feeds and pushes new items to the subscriber's chat.
+type FeedService struct {
+	mu       sync.Mutex
+	subs     map[string]*FeedSubscription
+	dataFile string // absolute path to FEEDS.json
+	msgBus   *bus.MessageBus
+	interval time.Duration
+}
+
+// NewFeedService creates a FeedService backed by $workspace/FEEDS.json.
+func NewFeedService(workspaceDir string, msgBus *bus.MessageBus) *FeedService {
+	fs := &FeedService{
+		subs:     make(map[string]*FeedSubscription),
+		dataFile: filepath.Join(workspaceDir, "FEEDS.json"),
+		msgBus:   msgBus,
+		interval: DefaultFeedPollInterval,
+	}
+	if err := fs.load(); err != nil && !os.IsNotExist(err) {
+		slog.Warn("feed service: failed to load feeds", "err", err)
+	}
+	return fs
+}
+
+// SetInterval changes the polling interval; it takes effect when Start is called.
+func (fs *FeedService) SetInterval(d time.Duration) {
+	if d > 0 {
+		fs.interval = d
+	}
+}
+
+// Start polls all feeds on the configured interval until ctx is cancelled.
+func (fs *FeedService) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(fs.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				slog.Info("feed service stopped")
+				return
+			case <-ticker.C:
+				fs.Poll(ctx)
+			}
+		}
+	}()
+	slog.Info("feed service started", "feeds", len(fs.List()), "interval", fs.interval)
+}
+
+// Subscribe fetches a feed once and stores it. Items already in the feed are
+// marked as seen so only items published afterwards are delivered.
+func (fs *FeedService) Subscribe(ctx context.Context, url, chatID, channel string) (*FeedSubscription, error) {
+	url = strings.TrimSpace(url)
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return nil, fmt.Errorf("feed URL must start with http:// or https://")
+	}
+	feed, err := feeds.Fetch(ctx, nil, url)
+	if err != nil {
+		return nil, fmt.Errorf("could not read feed: %w", err)
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	for _, s := range fs.subs {
+		if s.URL == url && s.ChatID == chatID {
+			return nil, fmt.Errorf("already subscribed to %s (ID: %s)", url, s.ID)
+		}
+	}
+
+	title := feed.Title
+	if title == "" {
+		title = url
+	}
+	id := GenerateJobID("feed_" + title)
+	for n := 2; fs.subs[id] != nil; n++ {
+		id = fmt.Sprintf("%s_%d", GenerateJobID("feed_"+title), n)
+	}
+	sub := &FeedSubscription{
+		ID:        id,
+		URL:       url,
+		Title:     title,
+		ChatID:    chatID,
+		Channel:   channel,
+		AddedAtMs: time.Now().UnixMilli(),
+	}
+	for _, it := range feed.Items {
+		sub.Seen = append(sub.Seen, it.ID)
+	}
+	sub.trimSeen()
+	fs.subs[sub.ID] = sub
+	return sub, fs.save()
+}
+
+// Unsubscribe removes a feed by ID, URL, or title.
+func (fs *FeedService) Unsubscribe(key string) (*FeedSubscription, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	for id, s := range fs.subs {
+		if s.ID == key || s.URL == key || strings.EqualFold(s.Title, key) {
+			delete(fs.subs, id)
+			return s, fs.save()
+		}
+	}
+	return nil, fmt.Errorf("no feed matching %q", key)
+}
+
+// List returns subscriptions sorted by title.
+func (fs *FeedService) List() []*FeedSubscription {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	out := make([]*FeedSubscription, 0, len(fs.subs))
+	for _, s := range fs.subs {
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Title < out[j].Title })
+	return out
+}
+
+// Poll checks every feed once and sends a digest of new items to each
+// subscriber. It returns the number of new items delivered.
+func (fs *FeedService) Poll(ctx context.Context) int {
+	delivered := 0
+	for _, sub := range fs.List() {
+		feed, err := feeds.Fetch(ctx, nil, sub.URL)
+
+		fs.mu.Lock()
+		live, ok := fs.subs[sub.ID]
+		if !ok {
+			fs.mu.Unlock()
+			continue
+		}
+		live.LastCheckMs = time.Now().UnixMilli()
+		if err != nil {
+			live.LastError = err.Error()
+			_ = fs.save()
+			fs.mu.Unlock()
+			slog.Warn("feed service: poll failed", "feed_id", live.ID, "url", live.URL, "err", err)
+			continue
+		}
+		live.LastError = ""
+
+		seen := make(map[string]bool, len(live.Seen))
+		for _, id := range live.Seen {
+			seen[id] = true
+		}
+		var fresh []feeds.Item
+		for _, it := range feed.Items {
+			if !seen[it.ID] {
+				fresh = append(fresh, it)
+				live.Seen = append(live.Seen, it.ID)
+			}
+		}
+		live.trimSeen()
+		_ = fs.save()
+		chatID, channel, title := live.ChatID, live.Channel, live.Title
+		fs.mu.Unlock()
+
+		if len(fresh) == 0 || chatID == "" || channel == "" {
+			continue
+		}
+		fs.msgBus.SendOutbound(bus.OutboundMessage{
+			Channel:  channel,
+			ChatID:   chatID,
+			Content:  FormatFeedDigest(title, fresh),
+			Priority: bus.PriorityCron,
+		})
+		delivered += len(fresh)
+	}
+	return delivered
+}
+
+// FormatFeedDigest renders new feed items as a chat message.
+func FormatFeedDigest(title string, items []feeds.Item) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📰 %d new in %s\n", len(items), title))
+	for i, it := range items {
+		if i == maxItemsPerDigest {
+			sb.WriteString(fmt.Sprintf("\n…and %d more", len(items)-maxItemsPerDigest))
+			break
+		}
+		sb.WriteString(fmt.Sprintf("\n• %s", it.Title))
+		if it.Link != "" {
+			sb.WriteString("\n  " + it.Link)
+		}
+		if it.Summary != "" {
+			sb.WriteString("\n  " + it.Summary)
+		}
+	}
+	return sb.String()
+}
+
+// trimSeen keeps only the most recent maxSeenPerFeed item IDs.
+func (s *FeedSubscription) trimSeen() {
+	if len(s.Seen) > maxSeenPerFeed {
+		s.Seen = s.Seen[len(s.Seen)-maxSeenPerFeed:]
+	}
+}
+
+func (fs *FeedService) load() error {
+	data, err := os.ReadFile(fs.dataFile)
+	if err != nil {
+		return err
+	}
+	var subs []*FeedSubscription
+	if err := json.Unmarshal(data, &subs); err != nil {
+		return err
+	}
+	for _, s := range subs {
+		fs.subs[s.ID] = s
+	}
+	return nil
+}
+
+// save persists subscriptions; callers must hold fs.mu.
+func (fs *FeedService) save() error {
+	subs := make([]*FeedSubscription, 0, len(fs.subs))
+	for _, s := range fs.subs {
+		subs = append(subs, s)
+	}
+	sort.Slice(subs, func(i, j int) bool { return subs[i].AddedAtMs < subs[j].AddedAtMs })
+	data, err := json.MarshalIndent(subs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fs.dataFile, data, 0644)
+}
+
+// registerFeedTools adds subscribe_feed, list_feeds, and unsubscribe_feed.
+func (c *NanoCore) registerFeedTools() {
+	// subscribe_feed
+	c.toolRegistry.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "subscribe_feed",
+			Description: "Subscribes the user to an RSS or Atom feed. New items are checked in the background and sent to this chat automatically. Only items published after subscribing are delivered.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"url": map[string]interface{}{
+						"type":        "string",
+						"description": "The feed URL (RSS or Atom), not the website homepage.",
+					},
+				},
+				"required": []string{"url"},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *tools.ToolResult {
+		url, _ := args["url"].(string)
+		if url == "" {
+			return &tools.ToolResult{ForLLM: "Error: url is required."}
+		}
+		chatID, channel := c.replyTarget(ctx)
+		if chatID == "" {
+			return &tools.ToolResult{ForLLM: "Error: Cannot subscribe to a feed without a prior user interaction."}
+		}
+
+		sub, err := c.feedService.Subscribe(ctx, url, chatID, channel)
+		if err != nil {
+			return &tools.ToolResult{ForLLM: fmt.Sprintf("Error subscribing to feed: %v", err)}
+		}
+		return &tools.ToolResult{ForLLM: fmt.Sprintf("Subscribed to '%s' (ID: %s). %d existing item(s) skipped; the feed is checked every %s and new items are sent to this chat.",
+			sub.Title, sub.ID, len(sub.Seen), c.feedService.interval)}
+	})
+
+	// list_feeds
+	c.toolRegistry.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "list_feeds",
+			Description: "Lists the RSS/Atom feeds the user is subscribed to, with their IDs, URLs, and last check status.",
+			Parameters: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *tools.ToolResult {
+		subs := c.feedService.List()
+		if len(subs) == 0 {
+			return &tools.ToolResult{ForLLM: "No feed subscriptions."}
+		}
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("%d feed subscription(s):\n", len(subs)))
+		for _, s := range subs {
+			last := "never"
+			if s.LastCheckMs > 0 {
+				last = time.UnixMilli(s.LastCheckMs).Format("2006-01-02 15:04")
+			}
+			sb.WriteString(fmt.Sprintf("\n**%s** (ID: `%s`)\n  URL: %s\n  Last check: %s\n", s.Title, s.ID, s.URL, last))
+			if s.LastError != "" {
+				sb.WriteString(fmt.Sprintf("  ⚠️ Last error: %s\n", s.LastError))
+			}
+		}
+		return &tools.ToolResult{ForLLM: sb.String()}
+	})
+
+	// unsubscribe_feed
+	c.toolRegistry.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "unsubscribe_feed",
+			Description: "Stops delivering a feed. Accepts the feed ID, URL, or title from list_feeds.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"feed": map[string]interface{}{
+						"type":        "string",
+						"description": "Feed ID, URL, or title.",
+					},
+				},
+				"required": []string{"feed"},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *tools.ToolResult {
+		key, _ := args["feed"].(string)
+		if key == "" {
+			return &tools.ToolResult{ForLLM: "Error: feed is required."}
+		}
+		sub, err := c.feedService.Unsubscribe(key)
+		if err != nil {
+			return &tools.ToolResult{ForLLM: fmt.Sprintf("Error: %v", err)}
+		}
+		return &tools.ToolResult{ForLLM: fmt.Sprintf("Unsubscribed from '%s'.", sub.Title)}
+	})
+
+	c.toolRegistry.SetToolGroup("feeds", "subscribe_feed", "list_feeds", "unsubscribe_feed")
+	c.toolRegistry.SetToolGroupKeywords("feeds", "rss", "atom", "feed", "subscribe", "unsubscribe", "blog", "news", "follow")
+}
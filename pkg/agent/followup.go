@@ -0,0 +1,235 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/providers"
+)
+
+const (
+	// DefaultFollowUpEvery is how often the heartbeat looks for open loops.
+	DefaultFollowUpEvery = 2 * time.Hour
+	// DefaultFollowUpMaxPerDay caps proactive follow-ups per calendar day.
+	DefaultFollowUpMaxPerDay = 2
+	// DefaultFollowUpMinGap is the minimum time between two follow-ups.
+	DefaultFollowUpMinGap = 4 * time.Hour
+	// DefaultFollowUpIdle skips follow-ups while the user was active this recently.
+	DefaultFollowUpIdle = 30 * time.Minute
+
+	// maxOpenLoops caps the candidate lines shown to the LLM.
+	maxOpenLoops = 15
+	// maxFollowUpRecords is how many sent follow-ups FOLLOWUPS.json keeps.
+	maxFollowUpRecords = 20
+)
+
+// openLoopRe matches lines that may hold something unfinished.
+var openLoopRe = regexp.MustCompile(`(?i)\b(remind me|later|tomorrow|next week|todo|to-do|pending|follow[- ]?up|don'?t forget|need to|have to|will check|waiting (for|on)|get back to|not yet|haven'?t|deadline|due)\b`)
+
+// FollowUpPolicy limits proactive follow-up messages. Zero fields keep the defaults.
+type FollowUpPolicy struct {
+	Every     time.Duration // how often to look for open loops (default 2h)
+	MaxPerDay int           // follow-ups per calendar day (default 2)
+	MinGap    time.Duration // minimum time between follow-ups (default 4h)
+	IdleFor   time.Duration // skip while the user was active this recently (default 30m)
+}
+
+func (p FollowUpPolicy) withDefaults() FollowUpPolicy {
+	if p.Every <= 0 {
+		p.Every = DefaultFollowUpEvery
+	}
+	if p.MaxPerDay <= 0 {
+		p.MaxPerDay = DefaultFollowUpMaxPerDay
+	}
+	if p.MinGap <= 0 {
+		p.MinGap = DefaultFollowUpMinGap
+	}
+	if p.IdleFor <= 0 {
+		p.IdleFor = DefaultFollowUpIdle
+	}
+	return p
+}
+
+// followUpRecord is one sent follow-up, kept for the caps and to avoid repeats.
+type followUpRecord struct {
+	At      time.Time `json:"at"`
+	Message string    `json:"message"`
+}
+
+// followUps is the proactive follow-up routine: it scans memory and recent
+// history for open loops and lets the LLM decide whether to message the user.
+type followUps struct {
+	core   *NanoCore
+	policy FollowUpPolicy
+	path   string // memory/FOLLOWUPS.json
+
+	mu sync.Mutex
+}
+
+// EnableFollowUps registers the follow_up heartbeat task. Besides the
+// heartbeat's quiet hours, it never sends more than p allows.
+func (h *Heartbeat) EnableFollowUps(p FollowUpPolicy) {
+	f := &followUps{
+		core:   h.core,
+		policy: p.withDefaults(),
+		path:   filepath.Join(h.core.memoryStore.MemoryDir(), "FOLLOWUPS.json"),
+	}
+	h.Register("follow_up", f.policy.Every, f.run)
+}
+
+// run performs one follow-up check.
+func (f *followUps) run(ctx context.Context) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, now := f.core, time.Now()
+	if c.InQuietHours(now) {
+		return
+	}
+	c.chatMu.Lock()
+	chatID, channel, lastUser := c.lastChatID, c.lastChannel, c.lastUserAt
+	c.chatMu.Unlock()
+	if chatID == "" || chatID == "internal_memory" {
+		return
+	}
+	if now.Sub(lastUser) < f.policy.IdleFor {
+		slog.Debug("follow-up: user recently active, not interrupting", "chat_id", chatID, "idle", now.Sub(lastUser).Round(time.Second))
+		return
+	}
+
+	sent := f.load()
+	if reason := f.capped(sent, now); reason != "" {
+		slog.Debug("follow-up: skipped", "chat_id", chatID, "reason", reason)
+		return
+	}
+	loops := c.findOpenLoops()
+	if len(loops) == 0 {
+		slog.Debug("follow-up: no open loops found", "chat_id", chatID)
+		return
+	}
+
+	msg := bus.InboundMessage{ChatID: chatID, Channel: channel, Priority: bus.PriorityCron}
+	params := c.paramsFor(chatID)
+	provider, model := c.chatModel()
+	resp, err := provider.Chat(ctx, providers.ChatRequest{
+		Model: model,
+		Messages: []providers.Message{
+			{Role: "system", Content: c.buildSystemPromptWithHistory("", params.historyBytes, c.promptVarsFor(msg, now))},
+			{Role: "user", Content: followUpPrompt(loops, sent)},
+		},
+		Temperature: params.temperature,
+		MaxTokens:   params.maxTokens,
+	})
+	if err != nil {
+		c.noteProviderError(err)
+		slog.Warn("follow-up check failed", "chat_id", chatID, "err", err)
+		return
+	}
+	reply := strings.TrimSpace(resp.Content)
+	if reply == "" || reply == noCheckIn {
+		slog.Debug("follow-up: nothing worth raising", "chat_id", chatID)
+		return
+	}
+
+	c.sendReply(msg, reply, nil)
+	c.memoryStore.AppendHistory("ASSISTANT", reply)
+	c.sessions.appendMessage(chatID, providers.Message{Role: "assistant", Content: reply})
+	slog.Info("sent a follow-up", "chat_id", chatID)
+
+	sent = append(sent, followUpRecord{At: now, Message: reply})
+	if len(sent) > maxFollowUpRecords {
+		sent = sent[len(sent)-maxFollowUpRecords:]
+	}
+	if err := f.save(sent); err != nil {
+		slog.Warn("failed to record follow-up", "err", err)
+	}
+}
+
+// capped returns why no follow-up may be sent at now, or "".
+func (f *followUps) capped(sent []followUpRecord, now time.Time) string {
+	today := now.Format("2006-01-02")
+	n := 0
+	for _, r := range sent {
+		if r.At.Local().Format("2006-01-02") == today {
+			n++
+		}
+	}
+	if n >= f.policy.MaxPerDay {
+		return fmt.Sprintf("daily cap reached (%d)", f.policy.MaxPerDay)
+	}
+	if len(sent) > 0 {
+		if since := now.Sub(sent[len(sent)-1].At); since < f.policy.MinGap {
+			return fmt.Sprintf("last follow-up was %s ago", since.Round(time.Minute))
+		}
+	}
+	return ""
+}
+
+func (f *followUps) load() []followUpRecord {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil
+	}
+	var sent []followUpRecord
+	if err := json.Unmarshal(data, &sent); err != nil {
+		slog.Warn("ignoring unreadable follow-up records", "path", f.path, "err", err)
+		return nil
+	}
+	return sent
+}
+
+func (f *followUps) save(sent []followUpRecord) error {
+	data, err := json.MarshalIndent(sent, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(f.path, data, 0644)
+}
+
+// findOpenLoops returns lines from recent history and core memory that look
+// unfinished, most recent last.
+func (c *NanoCore) findOpenLoops() []string {
+	text := c.memoryStore.ReadLongTerm() + "\n" + c.memoryStore.ReadRecentHistory(historyBudgetBytes)
+	var loops []string
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "---") || !openLoopRe.MatchString(line) || seen[line] {
+			continue
+		}
+		seen[line] = true
+		loops = append(loops, truncateLabel(line, 200))
+	}
+	if len(loops) > maxOpenLoops {
+		loops = loops[len(loops)-maxOpenLoops:]
+	}
+	return loops
+}
+
+// followUpPrompt asks the LLM to pick at most one open loop worth raising.
+func followUpPrompt(loops []string, sent []followUpRecord) string {
+	var sb strings.Builder
+	sb.WriteString("[SYSTEM FOLLOW-UP CHECK]\n")
+	sb.WriteString("These lines from memory and recent conversations may be open loops (pending tasks, \"remind me later\", unanswered questions):\n")
+	for _, l := range loops {
+		sb.WriteString("- " + l + "\n")
+	}
+	if len(sent) > 0 {
+		sb.WriteString("\nFollow-ups you already sent (do not repeat them):\n")
+		for _, r := range sent {
+			sb.WriteString(fmt.Sprintf("- %s: %s\n", r.At.Local().Format("2006-01-02 15:04"), truncateLabel(r.Message, 200)))
+		}
+	}
+	sb.WriteString("\nDecide whether ONE short, friendly follow-up message would genuinely help the user right now: a reminder they asked for, a task they said they would do, or a question left unanswered. Skip anything already resolved, already followed up, or not time-sensitive.\n")
+	sb.WriteString(fmt.Sprintf("If there is nothing worth raising, reply with exactly %s. Otherwise reply with only the message.", noCheckIn))
+	return sb.String()
+}
@@ -3,18 +3,89 @@ package agent
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"sync"
 	"time"
 
 	"littleclaw/pkg/bus"
+	"littleclaw/pkg/events"
 )
 
+// DefaultHeartbeatInterval is how often the heartbeat runs when not configured.
+const DefaultHeartbeatInterval = 5 * time.Minute
+
+// QuietHours is a daily time-of-day window with no background LLM calls. The
+// window may wrap past midnight (e.g. 23:00-07:00); the zero value is disabled.
+type QuietHours struct {
+	start, end int // minutes since midnight
+}
+
+// ParseQuietHours parses "HH:MM" start and end times. Equal times are rejected.
+func ParseQuietHours(start, end string) (QuietHours, error) {
+	s, err := parseClock(start)
+	if err != nil {
+		return QuietHours{}, fmt.Errorf("quiet hours start: %w", err)
+	}
+	e, err := parseClock(end)
+	if err != nil {
+		return QuietHours{}, fmt.Errorf("quiet hours end: %w", err)
+	}
+	if s == e {
+		return QuietHours{}, fmt.Errorf("quiet hours start and end are both %s", start)
+	}
+	return QuietHours{start: s, end: e}, nil
+}
+
+func parseClock(v string) (int, error) {
+	t, err := time.Parse("15:04", v)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not HH:MM", v)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Contains reports whether t's local time of day falls in the window.
+func (q QuietHours) Contains(t time.Time) bool {
+	if q.start == q.end {
+		return false
+	}
+	m := t.Hour()*60 + t.Minute()
+	if q.start < q.end {
+		return m >= q.start && m < q.end
+	}
+	return m >= q.start || m < q.end
+}
+
+// String formats the window as HH:MM-HH:MM.
+func (q QuietHours) String() string {
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", q.start/60, q.start%60, q.end/60, q.end%60)
+}
+
+// SetQuietHours sets the window in which background tasks skip LLM calls.
+func (c *NanoCore) SetQuietHours(q QuietHours) {
+	c.chatMu.Lock()
+	c.quietHours = q
+	c.chatMu.Unlock()
+}
+
+// InQuietHours reports whether t falls in the configured quiet hours.
+func (c *NanoCore) InQuietHours(t time.Time) bool {
+	c.chatMu.Lock()
+	defer c.chatMu.Unlock()
+	return c.quietHours.Contains(t)
+}
+
 // Heartbeat runs a periodic background loop for the agent to perform
-// autonomous tasks, mainly memory consolidation and summarization.
+// autonomous tasks. Each tick runs the registered HeartbeatTasks that are due
+// (see heartbeat_tasks.go); the built-in ones handle memory consolidation,
+// summarization, and pre-compaction.
 type Heartbeat struct {
 	core     *NanoCore
 	interval time.Duration
 
+	mu    sync.Mutex
+	tasks []*HeartbeatTask // run in registration order
+
 	// Exported fields for external test inspection.
 	Core     *NanoCore
 	Interval time.Duration
@@ -22,12 +93,16 @@ type Heartbeat struct {
 
 // NewHeartbeat creates a new background Heartbeat daemon.
 func NewHeartbeat(core *NanoCore, interval time.Duration) *Heartbeat {
-	return &Heartbeat{
+	h := &Heartbeat{
 		core:     core,
 		interval: interval,
 		Core:     core,
 		Interval: interval,
 	}
+	h.Register("summarization", 0, h.triggerSummarization)
+	h.Register("consolidation", 0, h.triggerConsolidation)
+	h.Register("pre_compaction", 0, h.checkPreCompaction)
+	return h
 }
 
 // Start begins the heartbeat ticker. It blocks until ctx is canceled.
@@ -41,7 +116,7 @@ func (h *Heartbeat) Start(ctx context.Context) {
 	for {
 		select {
 		case <-ctx.Done():
-			log.Println("Heartbeat stopping...")
+			slog.Info("heartbeat stopping")
 			return
 		case <-ticker.C:
 			h.tick(ctx)
@@ -49,11 +124,37 @@ func (h *Heartbeat) Start(ctx context.Context) {
 	}
 }
 
-// tick runs all heartbeat tasks: consolidation, summarization, and pre-compaction check.
+// tick runs every registered task that is due, in registration order.
+// During quiet hours it does nothing; pending history stays dirty for the next tick.
 func (h *Heartbeat) tick(ctx context.Context) {
-	h.triggerSummarization(ctx)
-	h.triggerConsolidation(ctx)
-	h.checkPreCompaction(ctx)
+	now := time.Now()
+	if h.core.InQuietHours(now) {
+		slog.Debug("heartbeat: quiet hours, skipping background tasks")
+		return
+	}
+
+	h.mu.Lock()
+	var due []*HeartbeatTask
+	for _, t := range h.tasks {
+		if t.due(now) {
+			t.lastRun = now
+			due = append(due, t)
+		}
+	}
+	h.mu.Unlock()
+
+	for _, t := range due {
+		if ctx.Err() != nil {
+			return
+		}
+		h.runTask(ctx, t)
+	}
+}
+
+// runTask runs one task, recovering a panic so the heartbeat keeps going.
+func (h *Heartbeat) runTask(ctx context.Context, t *HeartbeatTask) {
+	defer h.core.recoverPanic("heartbeat task " + t.Name)
+	t.Run(ctx)
 }
 
 // triggerConsolidation pushes an internal message to the core to process memory.
@@ -61,16 +162,17 @@ func (h *Heartbeat) tick(ctx context.Context) {
 func (h *Heartbeat) triggerConsolidation(ctx context.Context) {
 	// Only consolidate if there is actually new content to process
 	if !h.core.memoryStore.IsDirtyAndClear() {
-		log.Println("💤 Heartbeat: No new history since last consolidation, skipping.")
+		slog.Debug("heartbeat: no new history since last consolidation, skipping")
 		return
 	}
 
-	log.Println("💓 Heartbeat triggered: Initiating memory consolidation...")
+	slog.Info("heartbeat: consolidating memory")
 
 	internalMsg := bus.InboundMessage{
 		Channel:  "internal",
 		SenderID: "system",
 		ChatID:   "internal_memory",
+		Priority: bus.PriorityInternal,
 		Content: `[SYSTEM CONSOLIDATION REQUEST]
 Review the recent conversational history provided in your system prompt.
 Extract any core facts, user preferences, projects, or entity relationships that should be remembered long-term.
@@ -83,7 +185,14 @@ RULES:
 5. Be concise. Do not chat. Only use tools to read and write memory.`,
 	}
 
+	start := time.Now()
 	h.core.RunAgentLoop(ctx, internalMsg)
+	entities, _ := h.core.memoryStore.ListEntities()
+	h.core.events.Publish(events.MemoryConsolidated{
+		Duration:    time.Since(start),
+		MemoryBytes: len(h.core.memoryStore.ReadLongTerm()),
+		Entities:    len(entities),
+	})
 }
 
 // triggerSummarization checks if yesterday's daily log needs summarization and triggers it.
@@ -93,12 +202,13 @@ func (h *Heartbeat) triggerSummarization(ctx context.Context) {
 		return
 	}
 
-	log.Printf("📝 Heartbeat: Yesterday's log (%s) exceeds threshold, triggering summarization...", date)
+	slog.Info("heartbeat: summarizing yesterday's log", "date", date)
 
 	internalMsg := bus.InboundMessage{
 		Channel:  "internal",
 		SenderID: "system",
 		ChatID:   "internal_memory",
+		Priority: bus.PriorityInternal,
 		Content: fmt.Sprintf(`[SYSTEM SUMMARIZATION REQUEST]
 The conversation log for %s is too large to include in full context. Summarize it into a concise digest.
 
@@ -123,12 +233,13 @@ func (h *Heartbeat) checkPreCompaction(ctx context.Context) {
 		return
 	}
 
-	log.Println("⚡ Heartbeat: Context window pressure detected, triggering pre-compaction flush...")
+	slog.Info("heartbeat: context window pressure, flushing before compaction")
 
 	internalMsg := bus.InboundMessage{
 		Channel:  "internal",
 		SenderID: "system",
 		ChatID:   "internal_memory",
+		Priority: bus.PriorityInternal,
 		Content: `[SYSTEM PRE-COMPACTION FLUSH]
 Context window is filling up. Capture any durable memories to disk NOW before they are lost.
 
@@ -0,0 +1,153 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"littleclaw/pkg/bus"
+)
+
+// HeartbeatTask is a background routine run by the heartbeat on its own cadence.
+type HeartbeatTask struct {
+	Name  string
+	Every time.Duration // minimum time between runs; 0 runs on every tick
+	Run   func(ctx context.Context)
+
+	lastRun time.Time
+}
+
+// due reports whether the task should run at now.
+func (t *HeartbeatTask) due(now time.Time) bool {
+	return t.lastRun.IsZero() || now.Sub(t.lastRun) >= t.Every
+}
+
+// builtinHeartbeatPrompt is an optional routine that can be enabled by name.
+type builtinHeartbeatPrompt struct {
+	prompt func(now time.Time) string
+	notify bool // reply to the user by default
+}
+
+// builtinHeartbeatPrompts are the optional routines RegisterPrompt can enable
+// without a custom prompt.
+var builtinHeartbeatPrompts = map[string]builtinHeartbeatPrompt{
+	"entity_dedup": {prompt: func(time.Time) string {
+		return `Review the entity files for duplicates and stale facts.
+
+RULES:
+1. Use 'list_entities', then 'read_entity' on entities that look like the same person, project, or topic under different names.
+2. Merge duplicates into the best-named entity with 'write_entity', keeping every fact, and note the merged name as an alias.
+3. Fix contradictions by keeping the most recent information.
+4. Do NOT chat. Only use tools.`
+	}},
+	"journal": {prompt: func(now time.Time) string {
+		date := now.Format("2006-01-02")
+		return fmt.Sprintf(`Write today's journal entry.
+
+RULES:
+1. Use 'search_history' and the recent history in your system prompt to see what happened today (%s).
+2. Append a short entry (5-10 lines: what was worked on, decisions, open threads) to journal/%s.md with 'append_file'. Create the journal folder if needed.
+3. If nothing happened today, do nothing.
+4. Do NOT chat. Only use tools.`, date, date)
+	}},
+	"check_in": {notify: true, prompt: func(now time.Time) string {
+		return fmt.Sprintf(`It is %s. Decide whether a short proactive check-in would help the user right now: an open task or promise from recent conversations, a follow-up on something they mentioned, or an upcoming event.
+If there is something worth raising, write ONE brief, friendly message about it. If there is nothing useful to say, reply with exactly NO_CHECKIN.`, now.Format("Monday 15:04"))
+	}},
+}
+
+// noCheckIn is what a notify task replies when it has nothing to say; such
+// replies are not sent.
+const noCheckIn = "NO_CHECKIN"
+
+// Register adds a background routine, replacing any task with the same name.
+func (h *Heartbeat) Register(name string, every time.Duration, run func(ctx context.Context)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	task := &HeartbeatTask{Name: name, Every: every, Run: run}
+	for i, t := range h.tasks {
+		if t.Name == name {
+			h.tasks[i] = task
+			return
+		}
+	}
+	h.tasks = append(h.tasks, task)
+}
+
+// Unregister removes a routine by name and reports whether it existed.
+func (h *Heartbeat) Unregister(name string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, t := range h.tasks {
+		if t.Name == name {
+			h.tasks = append(h.tasks[:i], h.tasks[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Tasks returns the registered routine names in run order.
+func (h *Heartbeat) Tasks() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	names := make([]string, len(h.tasks))
+	for i, t := range h.tasks {
+		names[i] = t.Name
+	}
+	return names
+}
+
+// RegisterPrompt adds a routine that sends prompt through the agent loop. An
+// empty prompt selects the built-in routine with that name (entity_dedup,
+// journal, or check_in). Notify runs it in the last user chat so the reply
+// reaches the user; otherwise it runs on the internal channel.
+func (h *Heartbeat) RegisterPrompt(name string, every time.Duration, prompt string, notify bool) error {
+	build := func(time.Time) string { return prompt }
+	if prompt == "" {
+		builtin, ok := builtinHeartbeatPrompts[name]
+		if !ok {
+			return fmt.Errorf("heartbeat task %q needs a prompt (built-in tasks: %s)", name, builtinHeartbeatNames())
+		}
+		build = builtin.prompt
+		notify = notify || builtin.notify
+	}
+	h.Register(name, every, func(ctx context.Context) {
+		h.runPromptTask(ctx, name, build(time.Now()), notify)
+	})
+	return nil
+}
+
+// runPromptTask runs one prompt routine through the agent loop.
+func (h *Heartbeat) runPromptTask(ctx context.Context, name, prompt string, notify bool) {
+	msg := bus.InboundMessage{
+		Channel:  "internal",
+		SenderID: "system",
+		ChatID:   "internal_memory",
+		Content:  fmt.Sprintf("[SYSTEM HEARTBEAT TASK: %s]\n%s", name, prompt),
+		Priority: bus.PriorityInternal,
+	}
+	if notify {
+		h.core.chatMu.Lock()
+		msg.ChatID, msg.Channel = h.core.lastChatID, h.core.lastChannel
+		h.core.chatMu.Unlock()
+		if msg.ChatID == "" || msg.ChatID == "internal_memory" {
+			slog.Debug("heartbeat task: no user chat to notify yet, skipping", "task", name)
+			return
+		}
+		msg.Content += fmt.Sprintf("\nIf there is nothing worth telling the user, reply with exactly %s.", noCheckIn)
+	}
+	slog.Info("heartbeat task running", "task", name)
+	h.core.RunAgentLoop(ctx, msg)
+}
+
+func builtinHeartbeatNames() string {
+	names := make([]string, 0, len(builtinHeartbeatPrompts))
+	for n := range builtinHeartbeatPrompts {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return fmt.Sprint(names)
+}
@@ -4,31 +4,41 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
+	"maps"
+	"slices"
 	"strings"
 	"sync"
+	"text/template"
 	"time"
 
 	"littleclaw/pkg/bus"
+	"littleclaw/pkg/calendar"
+	"littleclaw/pkg/events"
 	"littleclaw/pkg/memory"
 	"littleclaw/pkg/providers"
+	"littleclaw/pkg/telemetry"
 	"littleclaw/pkg/tools"
+	"littleclaw/pkg/weather"
 	"littleclaw/pkg/workspace"
 )
 
 type contextKey string
 
 const (
-	ctxChatID  contextKey = "chatID"
-	ctxChannel contextKey = "channel"
+	ctxChatID   contextKey = "chatID"
+	ctxChannel  contextKey = "channel"
+	ctxThreadID contextKey = "threadID" // forum topic of the message, if any
 
 	// Context budget constants (in estimated tokens; 1 token ~= 4 chars)
 	maxContextTokens     = 8000  // total token budget for the system prompt
 	identityBudgetTokens = 800   // identity files (SOUL, IDENTITY, USER)
+	personaBudgetTokens  = 400   // standing instructions from SYSTEM.md
 	CoreBudgetTokens     = 2000  // MEMORY.md
 	historyBudgetBytes   = 16000 // ~4000 tokens, expanded from 4000 bytes
 	entityBudgetTokens   = 800   // auto-surfaced entities
 	cronBudgetTokens     = 400   // cron summaries
+	taskBudgetTokens     = 300   // open tasks from TASKS.json
 
 	// CharsPerToken is the default ratio for the simple truncation helper.
 	CharsPerToken = 4
@@ -36,6 +46,15 @@ const (
 	// MaxToolResultChars caps the length of a single tool result in the messages array.
 	MaxToolResultChars = 3000
 
+	// continuePrompt asks the model to resume a reply cut off by max_tokens.
+	continuePrompt = "[System] Your previous reply was cut off by the length limit. Continue exactly where it stopped, without repeating anything or adding a preamble."
+
+	// DefaultMaxContinuations is how many continue turns a truncated reply may get.
+	DefaultMaxContinuations = 2
+
+	// toolReflectionPrompt follows each batch of tool results; it is not kept in sessions.
+	toolReflectionPrompt = "[System] Tool execution finished. Analyze the results and proceed or respond to the user."
+
 	// preCompactionThreshold: when prompt tokens exceed this fraction of the model's
 	// apparent context window, trigger an early memory consolidation.
 	preCompactionThreshold = 0.80
@@ -43,21 +62,54 @@ const (
 
 // NanoCore represents the central Agent ReAct Loop.
 type NanoCore struct {
-	provider     providers.Provider
 	memoryStore  *memory.Store
 	toolRegistry *tools.Registry
 	msgBus       *bus.MessageBus
 	wsMgr        *workspace.Manager
 	workspace    string
-	providerType string
-	modelName    string
 	cronService  *CronService
+	feedService  *FeedService
+	taskStore    *TaskStore
+	watchService *WatchService
 	tavilyAPIKey string
+	events       *events.Bus   // shared with the tool registry (see Events)
+	approvals    *approvalGate // nil unless EnableApprovals was called
+	alerts       *adminAlerts  // nil unless EnableAdminAlerts was called (see alerts.go)
+	traces       *TracePolicy  // nil unless EnableTraces was called (see trace.go)
+	sessions     *sessionStore // per-chat in-memory turns (see session.go)
+	subAgents    *subAgentManager
+	background   *backgroundManager   // sub-agents and background commands (see background.go)
+	memoryIndex  *memory.PgIndex      // semantic search over memory, nil unless enabled (see semantic.go)
+	runs         *runRegistry         // in-flight runs per chat, for /stop (see cancel.go)
+	debug        *debugChats          // chats with /debug on (see debug.go)
+	roles        map[string]AgentRole // delegation targets (see roles.go)
+	userRoles    userRoles            // permission tier per sender (see user_roles.go)
+	limits       *rateLimiter         // per-sender message, run, and token limits (see ratelimit.go)
+	contextTmpl  *template.Template   // custom context block, nil for the default (see prompt_vars.go)
+	location     *time.Location       // zone for the prompt's date and time, nil for local
+
+	// Chat model, swappable at runtime by SetModel
+	modelMu      sync.RWMutex
+	provider     providers.Provider
+	providerType string
+	modelName    string
+	router       *ModelRouter // cheap model for simple messages, nil when off (see router.go)
+
+	// Loop parameters (see params.go)
+	paramsMu   sync.Mutex
+	params     AgentParams
+	chatParams map[string]AgentParams
 
 	// Protected by chatMu for concurrent goroutine access
 	chatMu      sync.Mutex
 	lastChatID  string
 	lastChannel string
+	lastUserAt  time.Time  // last message from the user (not system-triggered)
+	quietHours  QuietHours // no background LLM calls in this window (see heartbeat.go)
+
+	// Reported by Status (see status.go)
+	statusMu        sync.Mutex
+	lastProviderErr providerError
 
 	// Pre-compaction tracking
 	LastPromptTokens int
@@ -87,24 +139,170 @@ func NewNanoCore(provider providers.Provider, providerType, modelName, workspace
 		providerType: providerType,
 		modelName:    modelName,
 		cronService:  cronSvc,
+		feedService:  NewFeedService(workspaceDir, msgBus),
+		taskStore:    NewTaskStore(workspaceDir),
+		sessions:     newSessionStore(),
+		subAgents:    newSubAgentManager(),
+		background:   newBackgroundManager(),
+		runs:         newRunRegistry(),
+		debug:        newDebugChats(),
+		limits:       newRateLimiter(),
 		tavilyAPIKey: tavilyAPIKey,
 	}
 
+	// File watches trigger the agent loop in the watcher's chat
+	nc.watchService = NewWatchService(workspaceDir, nc.RunAgentLoop)
+	// Agent cron jobs run their instruction through the agent loop
+	cronSvc.SetAgentTrigger(nc.RunAgentLoop)
+
 	// Initialize registry
 	nc.toolRegistry = tools.NewRegistry(workspaceDir, memStore, wsMgr, tavilyAPIKey)
+	nc.toolRegistry.SetBackgroundRunner(nc)
+	nc.events = nc.toolRegistry.Events()
+	cronSvc.SetEvents(nc.events)
 
 	nc.registerMemoryTools()
+	nc.registerPersonaTool()
+	nc.registerModelsTool()
+	nc.registerSessionTools()
+	nc.registerUsageTool()
 	nc.registerCronTools()
+	nc.registerFeedTools()
+	nc.registerTaskTools()
+	nc.registerWatchTools()
+	nc.registerSubAgentTools()
+	nc.registerBackgroundTools()
 	nc.registerWorkspaceTools()
 
 	return nc, nil
 }
 
+// SetExecPolicy replaces the allow/deny rules applied to exec, skills, and cron commands.
+func (c *NanoCore) SetExecPolicy(p *tools.ExecPolicy) {
+	c.toolRegistry.SetExecPolicy(p)
+}
+
+// SetSandbox runs exec, skills, plugins, and cron shell jobs inside s; nil
+// runs them directly. The audit logs are read-only inside it, so call
+// SetExecAuditLog first.
+func (c *NanoCore) SetSandbox(s *tools.Sandbox) {
+	if s != nil {
+		s = s.WithReadOnly(c.toolRegistry.AuditLogPaths()...)
+	}
+	c.toolRegistry.SetSandbox(s)
+	c.cronService.SetSandbox(s)
+}
+
+// SetExecAuditLog appends every shell command run by exec, skills, and cron
+// jobs to the exec audit log at path.
+func (c *NanoCore) SetExecAuditLog(path string) {
+	c.toolRegistry.SetExecAuditLog(path)
+}
+
+// CheckCommand reports whether the exec policy allows cmd.
+func (c *NanoCore) CheckCommand(cmd string) error {
+	return c.toolRegistry.CheckCommand(cmd)
+}
+
+// approveScheduledCommand holds a shell command a cron tool is about to
+// schedule for the same approval exec would need, since nobody is asked when
+// the job fires. It returns nil if the job may be saved.
+func (c *NanoCore) approveScheduledCommand(ctx context.Context, tool, command string) *tools.ToolResult {
+	held := c.toolRegistry.CheckApproval(ctx, tool, command)
+	if held == nil {
+		return nil
+	}
+	return &tools.ToolResult{ForLLM: "The job was not scheduled. " + held.ForLLM}
+}
+
+// SetSQLConnections configures the databases the sql_query tool can reach.
+func (c *NanoCore) SetSQLConnections(conns map[string]tools.SQLConnection) error {
+	return c.toolRegistry.SetSQLConnections(conns)
+}
+
+// SetCalendar connects a calendar backend and enables the calendar tools.
+func (c *NanoCore) SetCalendar(cal calendar.Client) {
+	c.toolRegistry.SetCalendar(cal)
+}
+
+// SetToolTimeouts sets the default and per-tool timeouts for tool calls.
+func (c *NanoCore) SetToolTimeouts(def time.Duration, perTool map[string]time.Duration) {
+	c.toolRegistry.SetToolTimeouts(def, perTool)
+}
+
+// SetToolSelection sends at most limit tool definitions per request, chosen by
+// relevance to the message; 0 sends every tool.
+func (c *NanoCore) SetToolSelection(limit int) {
+	c.toolRegistry.SetToolSelection(limit)
+}
+
+// SetWeather replaces the get_weather backend.
+func (c *NanoCore) SetWeather(p weather.Provider) {
+	c.toolRegistry.SetWeather(p)
+}
+
+// SetFileLinker lets send_telegram_file share oversized files as download links.
+func (c *NanoCore) SetFileLinker(l tools.FileLinker) {
+	c.toolRegistry.SetFileLinker(l)
+}
+
+// EnableDesktopTools registers the screenshot and clipboard tools.
+func (c *NanoCore) EnableDesktopTools() {
+	c.toolRegistry.EnableDesktopTools()
+}
+
+// SetVisionModel enables analyze_image backed by the given provider and model.
+func (c *NanoCore) SetVisionModel(p providers.Provider, model string) {
+	c.toolRegistry.SetVisionModel(p, model)
+}
+
+// SetModel switches the chat provider and model, e.g. after a config reload.
+// Runs already in flight finish with the previous ones.
+func (c *NanoCore) SetModel(p providers.Provider, providerType, model string) {
+	c.modelMu.Lock()
+	changed := model != c.modelName
+	c.provider, c.providerType, c.modelName = p, providerType, model
+	c.modelMu.Unlock()
+
+	// Re-estimate the context window for the new model unless it is configured
+	if changed {
+		c.paramsMu.Lock()
+		if c.params.ContextWindow == 0 {
+			c.ContextWindowEst = 0
+		}
+		c.paramsMu.Unlock()
+	}
+}
+
+// chatModel returns the current chat provider and model.
+func (c *NanoCore) chatModel() (providers.Provider, string) {
+	c.modelMu.RLock()
+	defer c.modelMu.RUnlock()
+	return c.provider, c.modelName
+}
+
+// Events returns the bus tool calls, cron runs, memory consolidations, and
+// provider errors are published on.
+func (c *NanoCore) Events() *events.Bus { return c.events }
+
 // MemoryStore returns the underlying memory store (for external test access).
 func (c *NanoCore) MemoryStore() *memory.Store { return c.memoryStore }
 
+// CronService returns the scheduler behind the cron tools.
+func (c *NanoCore) CronService() *CronService { return c.cronService }
+
+// IsChatCommand reports whether content is a chat command answered without
+// the model (/stop, /new, /stats, /debug). Such messages are cheap, and /stop
+// must get through while every worker is busy, so they skip the worker queue.
+func IsChatCommand(content string) bool {
+	_, debug := parseDebugCommand(content)
+	return isStopCommand(content) || isNewCommand(content) || isStatsCommand(content) || debug
+}
+
 // RunAgentLoop processes an incoming user message through a multi-step reasoning loop.
 func (c *NanoCore) RunAgentLoop(ctx context.Context, msg bus.InboundMessage) {
+	defer c.recoverPanic("agent run")
+
 	// Update heartbeat so there's always a "last active" timestamp
 	_ = c.memoryStore.UpdateHeartbeat()
 
@@ -113,18 +311,77 @@ func (c *NanoCore) RunAgentLoop(ctx context.Context, msg bus.InboundMessage) {
 		c.chatMu.Lock()
 		c.lastChatID = msg.ChatID
 		c.lastChannel = msg.Channel
+		if msg.SenderID != "system" {
+			c.lastUserAt = time.Now()
+		}
 		c.chatMu.Unlock()
 	}
 
 	// Inject ChatID and Channel into context for cron jobs/tools to use
 	ctx = context.WithValue(ctx, ctxChatID, msg.ChatID)
 	ctx = context.WithValue(ctx, ctxChannel, msg.Channel)
+	ctx = context.WithValue(ctx, ctxThreadID, msg.ThreadID)
+	ctx = tools.WithCaller(ctx, msg.ChatID, msg.Channel)
+	ctx = tools.WithRequester(ctx, msg.SenderID)
+	role := c.userRole(msg)
+	ctx = tools.WithUserRole(ctx, role)
+
+	// /stop cancels the chat's in-flight runs instead of starting a new one
+	if isStopCommand(msg.Content) {
+		if !c.refuseCommand(msg, role, stopCommand) {
+			c.handleStopCommand(msg.ChatID, msg.MessageID, msg.Channel)
+		}
+		return
+	}
+	// /new starts a fresh conversation, keeping long-term memory
+	if isNewCommand(msg.Content) {
+		if !c.refuseCommand(msg, role, newCommand) {
+			c.handleNewCommand(msg)
+		}
+		return
+	}
+	// /stats reports token usage and cost without calling the model
+	if isStatsCommand(msg.Content) {
+		c.handleStatsCommand(msg.ChatID, msg.MessageID, msg.Channel)
+		return
+	}
+	// /debug on|off mirrors tool calls to the chat during runs
+	if arg, ok := parseDebugCommand(msg.Content); ok {
+		if !c.refuseCommand(msg, role, debugCommand) {
+			c.handleDebugCommand(msg.ChatID, msg.MessageID, msg.Channel, arg)
+		}
+		return
+	}
+	// Per-sender limits keep one busy user from draining the budget or the workers
+	release, reason, notify := c.limits.acquire(msg, time.Now())
+	if release == nil {
+		slog.Warn("rate limited", "chat_id", msg.ChatID, "sender", msg.SenderID, "reason", reason)
+		if notify {
+			c.sendResponse(msg.ChatID, msg.MessageID, msg.Channel, "⏳ "+reason, nil)
+		}
+		return
+	}
+	defer release()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	runID := c.runs.add(msg.ChatID, &activeRun{cancel: cancel, messageID: msg.MessageID, channel: msg.Channel})
+	defer c.runs.remove(msg.ChatID, runID)
+
+	// Save attached photos where analyze_image can reach them
+	if images, err := c.toolRegistry.SaveInboundImages(ctx, msg.Media); err != nil {
+		slog.Warn("failed to save attached image", "chat_id", msg.ChatID, "err", err)
+	} else {
+		for _, img := range images {
+			msg.Content = strings.TrimSpace(msg.Content + fmt.Sprintf("\n[Image attached: %s — use analyze_image to see it]", img))
+		}
+	}
 
 	// 1. Initialize user prompt first (needed for entity auto-surfacing)
 	userPrompt := msg.Content
 	if userPrompt == "" {
 		// Log and avoid sending empty prompts to the model which can trigger native language hallucinations
-		log.Printf("⚠️ Received empty message content from %s. Ignoring to prevent hallucination.", msg.SenderID)
+		slog.Warn("ignoring empty message", "chat_id", msg.ChatID, "sender", msg.SenderID)
 		return
 	}
 
@@ -132,13 +389,26 @@ func (c *NanoCore) RunAgentLoop(ctx context.Context, msg bus.InboundMessage) {
 		userPrompt = fmt.Sprintf("Context (User is replying to this previous message):\n\"%s\"\n\nUser's message: %s", msg.ReplyTo, msg.Content)
 	}
 
-	// 2. Build initial context (System Prompt + Memory), using the user message for entity surfacing
-	sysPrompt := c.BuildSystemPromptWithQuery(msg.Content)
-
-	messages := []providers.Message{
-		{Role: "system", Content: sysPrompt},
-		{Role: "user", Content: userPrompt}, // Omit media for brevity in this foundational version
+	// 2. Build initial context (System Prompt + Memory), using the user message for entity surfacing.
+	// A live session already carries the recent turns, so the file-based history is only
+	// injected when the chat has none.
+	params := c.paramsFor(msg.ChatID)
+	// The whole run uses one model, even if a config reload switches it meanwhile
+	provider, model := c.chatModel()
+	var session []providers.Message
+	if msg.Channel != "internal" {
+		session = c.sessions.get(sessionKey(msg.ChatID, msg.ThreadID))
 	}
+	historyBytes := params.historyBytes
+	if len(session) > 0 {
+		historyBytes = 0
+	}
+	sysPrompt := c.buildSystemPromptWithHistory(msg.Content, historyBytes, c.promptVarsFor(msg, time.Now())) + rolePrompt(role)
+
+	messages := []providers.Message{{Role: "system", Content: sysPrompt}}
+	messages = append(messages, session...)
+	messages = append(messages, providers.Message{Role: "user", Content: userPrompt}) // Omit media for brevity in this foundational version
+	turnStart := len(messages) - 1
 
 	// 3. Log user message to history
 	if msg.Channel == "internal" {
@@ -153,40 +423,160 @@ func (c *NanoCore) RunAgentLoop(ctx context.Context, msg bus.InboundMessage) {
 		c.memoryStore.AppendHistory("USER", userPrompt)
 	}
 
-	maxIterations := 10
 	iteration := 0
 
-	for iteration < maxIterations {
+	// Pick the tool definitions once per message so they stay stable across
+	// iterations, leaving out the ones the sender's role may not use
+	toolDefs := tools.AllowedDefinitions(role, c.toolRegistry.SelectDefinitions(userPrompt))
+
+	// Simple messages may go to the router's cheap model instead
+	chatModelName := model
+	if p, m, ok := c.routeModel(ctx, msg, messages); ok {
+		provider, model = p, m
+	}
+
+	// Parts of a reply that hit the length limit, awaiting continuation
+	var partial strings.Builder
+	continuations, partsStart := 0, 0
+
+	// Whether the self-check already ran this turn (it runs at most once)
+	verified := false
+
+	// Tokens and estimated cost of this run, checked against the configured budget
+	budget := params.budget
+
+	// Tool call trace for chats in debug mode, which replaces the status messages
+	debug := c.newRunDebug(msg)
+
+	// Status messages while tools run, so a long run is not just a typing indicator
+	progressLevel := params.progress
+	if debug != nil {
+		progressLevel = ProgressOff
+	}
+	progress := c.newRunProgress(msg, progressLevel)
+	defer progress.toolDone()
+
+	// Tool messages to the chat, combined per the chat's coalesce setting
+	outbox := c.newRunOutbox(msg, params.coalesce, params.coalesceWindow)
+	defer outbox.flush()
+
+	// Trace file of this run, written however it ends
+	tracer := c.newRunTracer(msg, provider.Name(), model)
+	defer func() { tracer.write(messages) }()
+
+	// OpenTelemetry span of the run; model and tool calls become its children
+	ctx, span := telemetry.Start(ctx, "agent.run", "chat_id", msg.ChatID, "channel", msg.Channel,
+		"gen_ai.system", provider.Name(), "gen_ai.request.model", model)
+	defer span.End()
+
+	// Token usage of the run, for /stats and the run span
+	calls := 0
+	defer func() {
+		debug.done(calls, budget.total())
+		span.SetAttributes("iterations", iteration, "llm_calls", calls, "gen_ai.usage.input_tokens", budget.promptTokens,
+			"gen_ai.usage.output_tokens", budget.completionTokens, "cost_usd", budget.cost())
+		c.limits.addTokens(msg, budget.total(), time.Now())
+		c.recordUsage(UsageRecord{
+			Time:             time.Now(),
+			ChatID:           msg.ChatID,
+			SenderID:         msg.SenderID,
+			Provider:         provider.Name(),
+			Model:            model,
+			Calls:            calls,
+			PromptTokens:     budget.promptTokens,
+			CompletionTokens: budget.completionTokens,
+			CostUSD:          budget.cost(),
+		})
+	}()
+
+	// Compaction, the self-check, and the budget wrap-up run on the same model
+	// and count toward the same budget, /stats, and daily token cap
+	chat := func(ctx context.Context, req providers.ChatRequest) (*providers.ChatResponse, error) {
+		req.Model = model
+		resp, err := provider.Chat(ctx, req)
+		calls++
+		if err == nil {
+			budget.add(resp, req.Messages)
+		}
+		return resp, err
+	}
+
+	for iteration < params.maxIterations {
 		iteration++
 
+		if ctx.Err() != nil {
+			slog.Info("agent run stopped", "chat_id", msg.ChatID)
+			tracer.end(TraceStopped, nil)
+			return
+		}
+
+		// Fold older tool rounds of this turn into a digest if the prompt is filling
+		// the context window (not while a continued reply is being stitched)
+		if partial.Len() == 0 {
+			messages = c.maybeCompactMessages(ctx, chat, messages, turnStart)
+		}
+
 		req := providers.ChatRequest{
-			Model:       c.modelName,
+			Model:       model,
 			Messages:    messages,
-			Tools:       c.toolRegistry.GetDefinitions(),
-			Temperature: 0.7,
+			Tools:       toolDefs,
+			Temperature: params.temperature,
+			MaxTokens:   params.maxTokens,
 		}
 
-		resp, err := c.provider.Chat(ctx, req)
+		callStart := time.Now()
+		resp, err := provider.Chat(ctx, req)
+		calls++
+		tracer.llmCall(iteration, callStart, resp, err)
 		if err != nil {
-			c.sendResponse(msg.ChatID, msg.MessageID, msg.Channel, fmt.Sprintf("⚠ API Error: %v", err), nil)
+			if ctx.Err() != nil {
+				slog.Info("agent run stopped", "chat_id", msg.ChatID)
+				tracer.end(TraceStopped, nil)
+				return
+			}
+			tracer.end(TraceError, err)
+			span.SetError(err)
+			c.noteProviderError(err)
+			outbox.flush()
+			c.sendReply(msg, fmt.Sprintf("⚠ API Error: %v", err), nil)
 			return
 		}
 
 		// Log token usage for observability and adaptive context sizing
 		if resp.Usage.TotalTokens > 0 {
-			log.Printf("📊 Token usage: prompt=%d completion=%d total=%d (iteration %d)",
-				resp.Usage.PromptTokens, resp.Usage.CompletionTokens, resp.Usage.TotalTokens, iteration)
+			slog.Debug("token usage", "chat_id", msg.ChatID, "prompt", resp.Usage.PromptTokens,
+				"completion", resp.Usage.CompletionTokens, "total", resp.Usage.TotalTokens, "iteration", iteration)
 
 			// Track for pre-compaction awareness
 			c.LastPromptTokens = resp.Usage.PromptTokens
 			if c.ContextWindowEst == 0 && resp.Usage.PromptTokens > 0 {
 				// Heuristic: estimate context window from first response.
 				// Most models use 128k, but we use a conservative estimate.
-				c.ContextWindowEst = EstimateContextWindow(c.modelName)
+				c.ContextWindowEst = EstimateContextWindow(chatModelName)
 			}
 		}
 
+		// Over budget with work still pending: ask for a final answer from what the
+		// run has so far instead of calling more tools or continuing the reply
+		budget.add(resp, messages)
+		if budget.exceeded() && (len(resp.ToolCalls) > 0 || resp.FinishReason == providers.FinishReasonLength) {
+			slog.Info("run budget used up, wrapping up", "chat_id", msg.ChatID, "tokens", budget.total(), "cost_usd", budget.cost())
+			content := resp.Content
+			if len(resp.ToolCalls) > 0 {
+				content = c.wrapUpOverBudget(ctx, chat, messages, params, resp.Content)
+			}
+			resp = &providers.ChatResponse{Content: strings.TrimSpace(content) + "\n\n" + budget.notice()}
+			verified = true
+		}
+
 		if len(resp.ToolCalls) > 0 {
+			// A continued reply that turned into tool calls: deliver the text so far as is
+			if partial.Len() > 0 {
+				outbox.flush()
+				c.sendReply(msg, partial.String(), nil)
+				partial.Reset()
+			}
+
 			// Add LLM's tool call intention to the message history
 			messages = append(messages, providers.Message{
 				Role:      "assistant",
@@ -203,14 +593,23 @@ func (c *NanoCore) RunAgentLoop(ctx context.Context, msg bus.InboundMessage) {
 				_ = json.Unmarshal([]byte(argsStr), &args)
 
 				// Execute securely
+				progress.toolStarted(toolName, args)
+				started := time.Now()
 				result := c.toolRegistry.Execute(ctx, toolName, args)
+				progress.toolDone()
+				slog.Debug("tool call", "chat_id", msg.ChatID, "tool", toolName,
+					"took", time.Since(started).Round(time.Millisecond), "result_chars", len(result.ForLLM))
 
-				// Append tool result to messages (truncated to prevent context blowup)
+				// Append tool result to messages (oversized ones are spilled to a file)
+				content := c.fitToolResult(toolName, args, result.ForLLM)
 				messages = append(messages, providers.Message{
 					Role:       "tool",
-					Content:    TruncateToolResult(result.ForLLM),
+					Content:    content,
 					ToolCallID: tc["id"].(string),
 				})
+				tracer.toolCall(TraceToolCall{Name: toolName, Args: args, Result: content, ForUser: result.ForUser,
+					Files: result.Files, DurationMs: time.Since(started).Milliseconds()})
+				debug.toolCall(toolName, args, content, time.Since(started))
 
 				// If the tool has direct user output (e.g., shell command execution logs) or files
 				if result.ForUser != "" || len(result.Files) > 0 {
@@ -218,7 +617,7 @@ func (c *NanoCore) RunAgentLoop(ctx context.Context, msg bus.InboundMessage) {
 					if toolName != "send_telegram_file" && result.ForUser != "" {
 						outMsg = fmt.Sprintf("🛠 Tool `%s`: %s", toolName, result.ForUser)
 					}
-					c.sendResponse(msg.ChatID, msg.MessageID, msg.Channel, outMsg, result.Files)
+					outbox.send(outMsg, result.Files, result.TempFiles)
 
 					// Log tool outputs directly to memory history so the agent remembers
 					historyMsg := outMsg
@@ -240,14 +639,58 @@ func (c *NanoCore) RunAgentLoop(ctx context.Context, msg bus.InboundMessage) {
 			// Add a reflection prompt so the LLM decides what to do next
 			messages = append(messages, providers.Message{
 				Role:    "user",
-				Content: "[System] Tool execution finished. Analyze the results and proceed or respond to the user.",
+				Content: toolReflectionPrompt,
 			})
 			continue // Loop back and call LLM again
 		}
 
+		// A reply cut off by max_tokens gets "continue" turns; the parts are stitched
+		// together and sent as one message
+		if resp.FinishReason == providers.FinishReasonLength && resp.Content != "" && continuations < params.maxContinuations {
+			if continuations == 0 {
+				partsStart = len(messages)
+			}
+			continuations++
+			partial.WriteString(resp.Content)
+			slog.Debug("reply truncated, requesting continuation", "chat_id", msg.ChatID, "continuation", continuations, "max", params.maxContinuations)
+			messages = append(messages,
+				providers.Message{Role: "assistant", Content: resp.Content},
+				providers.Message{Role: "user", Content: continuePrompt},
+			)
+			continue
+		}
+
 		// If no tools, it's a final response
+		if partial.Len() > 0 {
+			partial.WriteString(resp.Content)
+			resp.Content = partial.String()
+			messages = messages[:partsStart]
+		}
+
+		// Optional self-check of the draft against the request and tool results;
+		// a rejected draft goes back to the model once with the problems found
+		if params.verify && !verified && resp.Content != "" && iteration < params.maxIterations && turnUsedTools(messages, turnStart) {
+			verified = true
+			if issues := c.verifyReply(ctx, chat, userPrompt, messages[turnStart+1:], resp.Content); issues != "" {
+				messages = append(messages,
+					providers.Message{Role: "assistant", Content: resp.Content},
+					providers.Message{Role: "user", Content: verifyRetryPrompt + issues + verifyRetrySuffix},
+				)
+				continue
+			}
+		}
+
+		// A proactive heartbeat task with nothing to say stays silent
+		if msg.SenderID == "system" && strings.TrimSpace(resp.Content) == noCheckIn {
+			slog.Debug("nothing to tell the user", "chat_id", msg.ChatID)
+			tracer.end(TraceSilent, nil)
+			return
+		}
+
 		if resp.Content != "" {
-			c.sendResponse(msg.ChatID, msg.MessageID, msg.Channel, resp.Content, nil)
+			messages = append(messages, providers.Message{Role: "assistant", Content: resp.Content})
+			outbox.flush()
+			c.sendReply(msg, resp.Content, nil)
 			if msg.Channel == "internal" {
 				c.memoryStore.AppendInternal("ASSISTANT", resp.Content)
 			} else {
@@ -257,8 +700,31 @@ func (c *NanoCore) RunAgentLoop(ctx context.Context, msg bus.InboundMessage) {
 		break
 	}
 
-	if iteration >= maxIterations {
-		log.Printf("agent loop hit max iterations (%d) for chat %s", maxIterations, msg.ChatID)
+	if iteration >= params.maxIterations {
+		slog.Warn("agent loop hit max iterations", "chat_id", msg.ChatID, "max", params.maxIterations)
+		if messages[len(messages)-1].Role != "assistant" {
+			tracer.end(TraceMaxIterations, nil)
+		}
+	}
+
+	// Keep this turn, tool traces included, for the chat's next message (unless
+	// it cleared the session)
+	if msg.Channel != "internal" && !turnCalledTool(messages, turnStart, "clear_session") {
+		kept := make([]providers.Message, 0, len(messages))
+		for _, m := range messages[1:] {
+			if m.Role == "user" && m.Content == toolReflectionPrompt {
+				continue
+			}
+			if isVerifyRetry(m) {
+				// Drop the rejected draft along with the self-check prompt
+				if n := len(kept); n > 0 && kept[n-1].Role == "assistant" {
+					kept = kept[:n-1]
+				}
+				continue
+			}
+			kept = append(kept, m)
+		}
+		c.sessions.save(sessionKey(msg.ChatID, msg.ThreadID), kept)
 	}
 }
 
@@ -269,6 +735,13 @@ func (c *NanoCore) buildSystemPrompt() string {
 // buildSystemPromptWithQuery assembles the full system prompt with token-budgeted sections.
 // The optional query is used for lightweight entity auto-surfacing.
 func (c *NanoCore) BuildSystemPromptWithQuery(query string) string {
+	return c.buildSystemPromptWithHistory(query, c.paramsFor("").historyBytes, c.promptVarsFor(bus.InboundMessage{}, time.Now()))
+}
+
+// buildSystemPromptWithHistory builds the system prompt with up to historyBytes
+// of the daily log tail; 0 leaves it out when the caller already has the recent
+// turns in its session. vars fill the current-context block.
+func (c *NanoCore) buildSystemPromptWithHistory(query string, historyBytes int, vars PromptVars) string {
 	var builder strings.Builder
 	// FORMATTING RULE must come first so the LLM sees it before anything else
 	builder.WriteString("=== OUTPUT FORMAT RULE (MANDATORY) ===\n")
@@ -279,16 +752,28 @@ func (c *NanoCore) BuildSystemPromptWithQuery(query string) string {
 	builder.WriteString("GOOD EXAMPLE: Profile | Skills: | plain dashes for lists | CAPS for emphasis\n")
 	builder.WriteString("ALWAYS write in plain text. Emoji are fine. Backticks for inline code are fine.\n")
 	builder.WriteString("======================================\n\n")
-	builder.WriteString("You are Littleclaw, an ultra-fast, deeply personalized AI agent.\n")
-	builder.WriteString("TONE: Use extremely simple, direct language. No fluff, no formal greetings. Be brief.\n")
+	// Persona from SYSTEM.md, falling back to the built-in name and tone
+	persona := c.memoryStore.ReadPersona().WithDefaults()
+	builder.WriteString(fmt.Sprintf("You are %s, an ultra-fast, deeply personalized AI agent.\n", persona.Name))
+	builder.WriteString("TONE: " + persona.Tone + "\n")
+	if persona.Language != "" {
+		builder.WriteString(fmt.Sprintf("LANGUAGE: Reply in %s unless the user explicitly asks for another language.\n", persona.Language))
+	}
 	builder.WriteString("CONFIRMATION: ALWAYS ask for confirmation or clarify intent before taking irreversible actions (like deleting files, clearing memory, or running complex scripts) unless the user explicitly gave a direct command.\n")
 	builder.WriteString("If a task is ambiguous, ASK a simple question instead of guessing.\n")
+	if persona.Instructions != "" {
+		builder.WriteString("STANDING INSTRUCTIONS:\n")
+		builder.WriteString(TruncateToTokenBudget(persona.Instructions, personaBudgetTokens))
+		builder.WriteString("\n")
+	}
+	builder.WriteString("PERSONA: To change your name, tone, reply language, or standing instructions, use `set_persona` (never write_file on SYSTEM.md).\n")
 	builder.WriteString("MEMORY: Use `update_core_memory`, `append_core_memory`, `read_core_memory`, `search_history`, `list_entities`, `read_entity`, `write_entity`, `read_internal_log` tools only — never write_file/append_file for memory.\n")
 	builder.WriteString("MEMORY BEST PRACTICES:\n")
 	builder.WriteString("- Prefer `append_core_memory` for adding new facts. Only use `update_core_memory` when reorganizing/cleaning up.\n")
 	builder.WriteString("- Always `read_core_memory` before `update_core_memory` to avoid losing existing information.\n")
 	builder.WriteString("- Use `search_history` to recall past conversations before guessing.\n")
 	builder.WriteString("WEB: Use `web_search` and `web_fetch` tools for real-time internet access.\n")
+	builder.WriteString("SUB-AGENTS: For long, self-contained jobs (research, multi-step file work), use `spawn` to run a sub-agent in the background; it reports back to the chat when done.\n")
 
 	// Workspace structure context
 	builder.WriteString("\n=== WORKSPACE STRUCTURE ===\n")
@@ -297,11 +782,21 @@ func (c *NanoCore) BuildSystemPromptWithQuery(query string) string {
 	builder.WriteString("- skills/    : executable scripts loaded as agent tools (tracked in skills/tracker.json)\n")
 	builder.WriteString("- tools/     : utility programs and helpers (tracked in tools/tracker.json)\n")
 	builder.WriteString("- memory/    : RESERVED — use memory tools only, never write_file here\n")
+	builder.WriteString("- tool_outputs/ : full copies of oversized tool results (pruned after 7 days)\n")
 	builder.WriteString("Any other folders are custom and created on demand. Use `list_workspace` to see them.\n")
 	builder.WriteString("Use `create_workspace_folder` to create a new folder for anything that needs its own space.\n")
 	builder.WriteString("When writing a script, ALWAYS put it in scripts/ or skills/. NEVER dump files in the workspace root.\n")
 	builder.WriteString("Use `track_item` to register scripts/tools with a description so you remember them later.\n")
-	builder.WriteString("===========================\n")
+	builder.WriteString("To change part of an existing file, use `edit_file` (search/replace or unified diff) instead of rewriting it with write_file.\n")
+	builder.WriteString("Manage files with `list_files`, `stat_file`, `move_file`, and `delete_file` rather than `exec` with ls/mv/rm.\n")
+	builder.WriteString("Bundle files with `compress` (set send=true to deliver the archive) and unpack received archives with `extract` instead of zip/tar via `exec`.\n")
+	builder.WriteString("To add a skill, use `create_skill` (it syntax-checks and registers the script in one step) instead of write_file + reload_skills.\n")
+	builder.WriteString("Skills can declare a typed schema in a header right after the shebang: `# ---`, `# description: ...`, `# params:`, `#   name: string|integer|number|boolean[, required][, help]`, `# ---`. Params arrive as positional args and ARG_<NAME> env vars.\n")
+	builder.WriteString("===========================\n\n")
+
+	// Current date, time, and chat details so relative dates are computed, not guessed
+	builder.WriteString(c.renderContext(vars))
+	builder.WriteString("\n")
 
 	// Inject identity + personalized memory (token-budgeted)
 	identityCtx := c.memoryStore.ReadIdentityContext()
@@ -334,9 +829,15 @@ func (c *NanoCore) BuildSystemPromptWithQuery(query string) string {
 		builder.WriteString(summary)
 	}
 
-	// Auto-surface relevant entities based on user query (trigram + keyword similarity)
-	if query != "" {
-		entityCtx := c.memoryStore.FindRelevantEntities(query, entityBudgetTokens*CharsPerToken)
+	// Open tasks from TASKS.json so goals carry across sessions
+	if tasks := c.buildTaskSummary(); tasks != "" {
+		builder.WriteString("\nOpen Tasks (manage with add_task / complete_task / list_tasks):\n")
+		builder.WriteString(TruncateToTokenBudget(tasks, taskBudgetTokens))
+	}
+
+	// Auto-surface relevant entities based on user query (name and content matches)
+	if topK := c.paramsFor(vars.ChatID).entityTopK; query != "" && topK > 0 {
+		entityCtx := c.memoryStore.RelevantEntities(query, topK, entityBudgetTokens*CharsPerToken)
 		if entityCtx != "" {
 			builder.WriteString("\n\n=== RELEVANT ENTITY CONTEXT ===\n")
 			builder.WriteString(entityCtx)
@@ -345,7 +846,10 @@ func (c *NanoCore) BuildSystemPromptWithQuery(query string) string {
 	}
 
 	// Inject Short-Term Conversation Context from daily logs
-	recentHistory := c.memoryStore.ReadRecentHistory(historyBudgetBytes)
+	recentHistory := ""
+	if historyBytes > 0 {
+		recentHistory = c.memoryStore.ReadRecentHistory(historyBytes)
+	}
 	if recentHistory != "" {
 		builder.WriteString("\nRecent Conversational History:\n")
 		builder.WriteString(recentHistory)
@@ -365,7 +869,9 @@ func (c *NanoCore) buildCronSummary() string {
 	var sb strings.Builder
 	for _, j := range jobs {
 		statusEmoji := "⏳"
-		if j.State.LastStatus == "ok" {
+		if !j.IsEnabled() {
+			statusEmoji = "⏸️"
+		} else if j.State.LastStatus == "ok" {
 			statusEmoji = "✅"
 		} else if j.State.LastStatus == "error" {
 			statusEmoji = "❌"
@@ -417,6 +923,37 @@ func TruncateToolResult(s string) string {
 	return s[:MaxToolResultChars] + "\n...(truncated)"
 }
 
+// sendReply answers msg in its chat (and forum topic) at msg's priority, so
+// replies to cron and heartbeat runs queue behind replies to the user.
+func (c *NanoCore) sendReply(msg bus.InboundMessage, content string, files []string) {
+	c.sendReplyFiles(msg, content, files, nil)
+}
+
+// sendReplyFiles is sendReply with tempFiles, deleted once the reply is delivered.
+func (c *NanoCore) sendReplyFiles(msg bus.InboundMessage, content string, files, tempFiles []string) {
+	c.msgBus.SendOutbound(bus.OutboundMessage{
+		Channel:          msg.Channel,
+		ChatID:           msg.ChatID,
+		ThreadID:         msg.ThreadID,
+		ReplyToMessageID: msg.MessageID,
+		Content:          content,
+		Files:            files,
+		Priority:         msg.Priority,
+		TempFiles:        tempFiles,
+	})
+}
+
+// sendNotice posts content in msg's chat and forum topic without replying to
+// msg, so the typing indicator keeps going.
+func (c *NanoCore) sendNotice(msg bus.InboundMessage, content string) {
+	c.msgBus.SendOutbound(bus.OutboundMessage{
+		Channel:  msg.Channel,
+		ChatID:   msg.ChatID,
+		ThreadID: msg.ThreadID,
+		Content:  content,
+	})
+}
+
 func (c *NanoCore) sendResponse(chatID string, replyToMessageID int, channel, content string, files []string) {
 	c.msgBus.SendOutbound(bus.OutboundMessage{
 		Channel:          channel,
@@ -746,15 +1283,72 @@ func (c *NanoCore) registerMemoryTools() {
 		}
 		return &tools.ToolResult{ForLLM: "[Recent Internal Log]\n\n" + content}
 	})
+
+	c.toolRegistry.SetToolGroup(tools.ToolGroupCore, "read_core_memory", "append_core_memory", "search_history")
+	c.toolRegistry.SetToolGroup("memory", "update_core_memory", "read_entity", "write_entity", "write_summary", "read_internal_log", "list_entities")
+	c.toolRegistry.SetToolGroupKeywords("memory", "remember", "memory", "forget", "entity", "person", "people", "history", "summary", "recall", "know", "internal")
+}
+
+// replyTarget returns the chat a tool call should deliver to: the caller's
+// chat, or the last user chat when running from an internal loop. chatID is
+// empty if no user has messaged yet.
+func (c *NanoCore) replyTarget(ctx context.Context) (chatID, channel string) {
+	chatID, _ = ctx.Value(ctxChatID).(string)
+	channel, _ = ctx.Value(ctxChannel).(string)
+	if chatID == "internal_memory" || chatID == "" {
+		c.chatMu.Lock()
+		chatID, channel = c.lastChatID, c.lastChannel
+		c.chatMu.Unlock()
+	}
+	if chatID == "internal_memory" {
+		return "", ""
+	}
+	return chatID, channel
+}
+
+// StartFeedService starts polling subscribed RSS/Atom feeds in the background.
+func (c *NanoCore) StartFeedService(ctx context.Context, interval time.Duration) {
+	c.feedService.SetInterval(interval)
+	c.feedService.Start(ctx)
+}
+
+// StartWatchService starts scanning watched workspace paths in the background.
+func (c *NanoCore) StartWatchService(ctx context.Context, interval time.Duration) {
+	c.watchService.SetInterval(interval)
+	c.watchService.Start(ctx)
 }
 
 // StartCronService starts the cron scheduler in the background.
 func (c *NanoCore) StartCronService(ctx context.Context) {
 	if err := c.cronService.Start(ctx); err != nil {
-		fmt.Printf("⚠️ CronService failed to start: %v\n", err)
+		slog.Error("cron service failed to start", "err", err)
 	}
 }
 
+// SetCronJobTimeout sets how long a cron job run may take unless the job sets
+// its own timeout. Zero keeps DefaultCronJobTimeout.
+func (c *NanoCore) SetCronJobTimeout(d time.Duration) {
+	c.cronService.SetDefaultTimeout(d)
+}
+
+// stringMapArg converts a JSON object tool argument to a string map,
+// formatting non-string values.
+func stringMapArg(v interface{}) map[string]string {
+	obj, ok := v.(map[string]interface{})
+	if !ok || len(obj) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(obj))
+	for k, val := range obj {
+		if str, ok := val.(string); ok {
+			out[k] = str
+		} else if val != nil {
+			out[k] = fmt.Sprint(val)
+		}
+	}
+	return out
+}
+
 // registerCronTools adds tools that allow the LLM to manage cron jobs.
 func (c *NanoCore) registerCronTools() {
 	// add_cron
@@ -766,7 +1360,7 @@ func (c *NanoCore) registerCronTools() {
 			Parameters  map[string]interface{} `json:"parameters"`
 		}{
 			Name:        "add_cron",
-			Description: "Schedule a recurring background task using a cron expression. The command is a shell command that runs inside the workspace on each tick. Its stdout will be sent directly to the user. Use '@every Xs' for intervals (e.g. '@every 10s', '@every 1h') or standard 5-field cron syntax.",
+			Description: "Schedule a recurring background task using a cron expression. By default the command is a shell command that runs inside the workspace on each tick, and its stdout is sent directly to the user. With type 'agent' the command is instead a natural-language instruction (e.g. 'Summarize my unread RSS items') that you carry out with your tools on each tick, replying to the user yourself. Use '@every Xs' for intervals (e.g. '@every 10s', '@every 1h') or standard 5-field cron syntax.",
 			Parameters: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
@@ -780,7 +1374,12 @@ func (c *NanoCore) registerCronTools() {
 					},
 					"command": map[string]interface{}{
 						"type":        "string",
-						"description": "The shell command to run on each tick. Its stdout is sent to the user.",
+						"description": "The shell command to run on each tick (its stdout is sent to the user), or the instruction to carry out for type 'agent'.",
+					},
+					"type": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{CronJobShell, CronJobAgent},
+						"description": "'shell' (default) runs the command in a shell; 'agent' runs it as an instruction through the agent, for tasks that need reasoning or tools.",
 					},
 					"once": map[string]interface{}{
 						"type":        "boolean",
@@ -790,6 +1389,40 @@ func (c *NanoCore) registerCronTools() {
 						"type":        "boolean",
 						"description": "Set to true if the output should only be logged internally and NOT sent to the user. Use this for background maintenance or quiet monitoring.",
 					},
+					"timeout_seconds": map[string]interface{}{
+						"type":        "integer",
+						"description": "Optional limit on how long one run may take before it is stopped (default 10 minutes). Raise it for long jobs such as backups.",
+					},
+					"retries": map[string]interface{}{
+						"type":        "integer",
+						"description": "Optional number of retries (max 5) when a run fails, with growing waits in between. Useful for flaky network-dependent jobs.",
+					},
+					"alert": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{AlertAlways, AlertConsecutive, AlertNever},
+						"description": "When to tell the user about failures: 'always' (default), 'consecutive' (only once alert_after runs in a row have failed, plus when it recovers), or 'never' (default for silent jobs).",
+					},
+					"alert_after": map[string]interface{}{
+						"type":        "integer",
+						"description": "Consecutive failures before alerting with alert 'consecutive' (default 3).",
+					},
+					"summarize": map[string]interface{}{
+						"type":        "boolean",
+						"description": "For shell jobs with long or noisy output (logs, reports): send the output to you on each run to turn into a readable digest instead of sending it raw.",
+					},
+					"digest": map[string]interface{}{
+						"type":        "string",
+						"description": "Optional instruction for the digest when summarize is true (e.g. 'list only failed backups as bullets').",
+					},
+					"env": map[string]interface{}{
+						"type":                 "object",
+						"additionalProperties": map[string]interface{}{"type": "string"},
+						"description":          "Shell jobs: environment variables to set, e.g. {\"API_TOKEN\": \"...\"}. Use this for secrets instead of putting them in the command.",
+					},
+					"workdir": map[string]interface{}{
+						"type":        "string",
+						"description": "Shell jobs: folder to run in, relative to the workspace (e.g. 'repos/site'). Defaults to the workspace root.",
+					},
 				},
 				"required": []string{"label", "schedule", "command"},
 			},
@@ -800,36 +1433,62 @@ func (c *NanoCore) registerCronTools() {
 		command, _ := args["command"].(string)
 		once, _ := args["once"].(bool)
 		silent, _ := args["silent"].(bool)
+		jobType, _ := args["type"].(string)
+		timeout, _ := args["timeout_seconds"].(float64)
+		retries, _ := args["retries"].(float64)
+		alert, _ := args["alert"].(string)
+		alertAfter, _ := args["alert_after"].(float64)
+		summarize, _ := args["summarize"].(bool)
+		digest, _ := args["digest"].(string)
+		workdir, _ := args["workdir"].(string)
+		env := stringMapArg(args["env"])
 
 		if label == "" || schedule == "" || command == "" {
 			return &tools.ToolResult{ForLLM: "Error: label, schedule, and command are all required."}
 		}
-
-		// Extract chatID and channel from context
-		chatID, _ := ctx.Value(ctxChatID).(string)
-		channel, _ := ctx.Value(ctxChannel).(string)
-
-		// If we are in an internal loop (consolidation), use the last known user context
-		if chatID == "internal_memory" || chatID == "" {
-			c.chatMu.Lock()
-			chatID = c.lastChatID
-			channel = c.lastChannel
-			c.chatMu.Unlock()
+		switch alert {
+		case "", AlertAlways, AlertConsecutive, AlertNever:
+		default:
+			return &tools.ToolResult{ForLLM: fmt.Sprintf("Error: unknown alert policy %q (use 'always', 'consecutive', or 'never').", alert)}
+		}
+		switch jobType {
+		case "", CronJobShell:
+			jobType = ""
+			if err := c.toolRegistry.CheckCommand(command); err != nil {
+				return &tools.ToolResult{ForLLM: fmt.Sprintf("Cron command blocked by exec policy: %v", err)}
+			}
+			if held := c.approveScheduledCommand(ctx, "add_cron", command); held != nil {
+				return held
+			}
+		case CronJobAgent:
+			// Tools the agent uses when the job fires go through the usual policy checks
+		default:
+			return &tools.ToolResult{ForLLM: fmt.Sprintf("Error: unknown type %q (use 'shell' or 'agent').", jobType)}
 		}
 
-		if chatID == "internal_memory" || chatID == "" {
+		chatID, channel := c.replyTarget(ctx)
+		if chatID == "" {
 			return &tools.ToolResult{ForLLM: "Error: Cannot schedule cron job from internal context without a prior user interaction. Please wait for the user to message first."}
 		}
 
 		job := &CronJob{
-			ID:       GenerateJobID(label),
-			Label:    label,
-			Schedule: schedule,
-			Command:  command,
-			ChatID:   chatID,
-			Channel:  channel,
-			Once:     once,
-			Silent:   silent,
+			ID:         GenerateJobID(label),
+			Type:       jobType,
+			Label:      label,
+			Schedule:   schedule,
+			Command:    command,
+			ChatID:     chatID,
+			Channel:    channel,
+			Once:       once,
+			Silent:     silent,
+			Timeout:    int(timeout),
+			Retries:    min(int(retries), MaxCronRetries),
+			Alert:      alert,
+			AlertAfter: int(alertAfter),
+			Summarize:  summarize && jobType == "",
+			Digest:     strings.TrimSpace(digest),
+			Env:        env,
+			WorkDir:    strings.TrimSpace(workdir),
 		}
 
 		if err := c.cronService.AddJob(job); err != nil {
@@ -889,6 +1548,107 @@ func (c *NanoCore) registerCronTools() {
 		return &tools.ToolResult{ForLLM: fmt.Sprintf("Cron job '%s' removed successfully.", jobID)}
 	})
 
+	// update_cron
+	c.toolRegistry.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "update_cron",
+			Description: "Edit an existing cron job in place. Only the fields you pass change; everything else, including the command, ID, and run history, is kept. Prefer this over remove_cron + add_cron.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"job_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The ID or Label of the cron job to edit.",
+					},
+					"schedule": map[string]interface{}{
+						"type":        "string",
+						"description": "New cron schedule, e.g. '@every 2h' or '0 8 * * *'.",
+					},
+					"command": map[string]interface{}{
+						"type":        "string",
+						"description": "New shell command, or the new instruction for agent jobs.",
+					},
+					"label": map[string]interface{}{
+						"type":        "string",
+						"description": "New human-readable label. The job ID stays the same.",
+					},
+					"env": map[string]interface{}{
+						"type":                 "object",
+						"additionalProperties": map[string]interface{}{"type": "string"},
+						"description":          "Environment variables to set or change; an empty value removes the variable.",
+					},
+					"workdir": map[string]interface{}{
+						"type":        "string",
+						"description": "New working folder relative to the workspace; '.' resets it to the workspace root.",
+					},
+				},
+				"required": []string{"job_id"},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *tools.ToolResult {
+		jobID, _ := args["job_id"].(string)
+		schedule, _ := args["schedule"].(string)
+		command, _ := args["command"].(string)
+		label, _ := args["label"].(string)
+		workdir, _ := args["workdir"].(string)
+		env := stringMapArg(args["env"])
+		schedule, command, label, workdir = strings.TrimSpace(schedule), strings.TrimSpace(command), strings.TrimSpace(label), strings.TrimSpace(workdir)
+
+		job := c.cronService.FindJob(jobID)
+		if job == nil {
+			return &tools.ToolResult{ForLLM: fmt.Sprintf("Error: cron job '%s' not found. Use list_cron to see jobs.", jobID)}
+		}
+		if schedule == "" && command == "" && label == "" && workdir == "" && len(env) == 0 {
+			return &tools.ToolResult{ForLLM: "Error: give at least one of schedule, command, label, workdir, or env to change."}
+		}
+		if command != "" && (job.Type == "" || job.Type == CronJobShell) {
+			if err := c.toolRegistry.CheckCommand(command); err != nil {
+				return &tools.ToolResult{ForLLM: fmt.Sprintf("Cron command blocked by exec policy: %v", err)}
+			}
+			if held := c.approveScheduledCommand(ctx, "update_cron", command); held != nil {
+				return held
+			}
+		}
+
+		updated, err := c.cronService.UpdateJob(job.ID, func(j *CronJob) {
+			if schedule != "" {
+				j.Schedule = schedule
+			}
+			if command != "" {
+				j.Command = command
+			}
+			if label != "" {
+				j.Label = label
+			}
+			if workdir == "." {
+				j.WorkDir = ""
+			} else if workdir != "" {
+				j.WorkDir = workdir
+			}
+			for name, value := range env {
+				if value == "" {
+					delete(j.Env, name)
+					continue
+				}
+				if j.Env == nil {
+					j.Env = make(map[string]string)
+				}
+				j.Env[name] = value
+			}
+		})
+		if err != nil {
+			return &tools.ToolResult{ForLLM: fmt.Sprintf("Failed to update cron job: %v", err)}
+		}
+		return &tools.ToolResult{
+			ForLLM: fmt.Sprintf("Cron job updated (ID: %s).\n  Label:    %s\n  Schedule: %s\n  Command:  %s", updated.ID, updated.Label, updated.Schedule, updated.Command),
+		}
+	})
+
 	// list_cron
 	c.toolRegistry.RegisterTool(providers.ToolDefinition{
 		Type: "function",
@@ -919,6 +1679,9 @@ func (c *NanoCore) registerCronTools() {
 			} else if j.State.LastStatus == "error" {
 				statusEmoji = "❌ error"
 			}
+			if !j.IsEnabled() {
+				statusEmoji = "⏸️ paused (last: " + statusEmoji + ")"
+			}
 
 			lastRun := "never"
 			if j.State.LastRunAtMs > 0 {
@@ -931,13 +1694,25 @@ func (c *NanoCore) registerCronTools() {
 
 			sb.WriteString(fmt.Sprintf("**%s** (ID: `%s`)\n", j.Label, j.ID))
 			sb.WriteString(fmt.Sprintf("  Schedule:  %s\n", j.Schedule))
-			sb.WriteString(fmt.Sprintf("  Command:   %s\n", j.Command))
+			if j.IsAgent() {
+				sb.WriteString(fmt.Sprintf("  Task:      %s (agent)\n", j.Command))
+			} else if j.Type == CronJobMessage {
+				sb.WriteString(fmt.Sprintf("  Message:   %s\n", j.Command))
+			} else {
+				sb.WriteString(fmt.Sprintf("  Command:   %s\n", j.Command))
+			}
 			sb.WriteString(fmt.Sprintf("  Status:    %s\n", statusEmoji))
 			sb.WriteString(fmt.Sprintf("  Last run:  %s", lastRun))
 			if j.State.LastDurationMs > 0 {
 				sb.WriteString(fmt.Sprintf(" (%dms)", j.State.LastDurationMs))
 			}
+			if j.State.LastExitCode != 0 {
+				sb.WriteString(fmt.Sprintf(" exit code %d", j.State.LastExitCode))
+			}
 			sb.WriteString("\n")
+			if j.State.LastOutput != "" {
+				sb.WriteString(fmt.Sprintf("  Output:    %s\n", truncateLabel(j.State.LastOutput, 200)))
+			}
 			sb.WriteString(fmt.Sprintf("  Next run:  %s\n", nextRun))
 			if j.State.ConsecutiveErrors > 0 {
 				sb.WriteString(fmt.Sprintf("  ⚠️  %d consecutive error(s) — last: %s\n", j.State.ConsecutiveErrors, j.State.LastError))
@@ -945,8 +1720,154 @@ func (c *NanoCore) registerCronTools() {
 			if j.Once {
 				sb.WriteString("  One-time: yes (removed after first run)\n")
 			}
+			if j.Timeout > 0 {
+				sb.WriteString(fmt.Sprintf("  Timeout:   %ds\n", j.Timeout))
+			}
+			if j.Retries > 0 {
+				sb.WriteString(fmt.Sprintf("  Retries:   %d\n", j.Retries))
+			}
+			if j.Summarize {
+				sb.WriteString("  Digest:    output summarized by the agent\n")
+			}
+			if j.WorkDir != "" {
+				sb.WriteString(fmt.Sprintf("  Workdir:   %s\n", j.WorkDir))
+			}
+			if len(j.Env) > 0 {
+				// Values may be secrets; only the names are shown
+				names := slices.Sorted(maps.Keys(j.Env))
+				sb.WriteString(fmt.Sprintf("  Env:       %s\n", strings.Join(names, ", ")))
+			}
+			switch j.alertPolicy() {
+			case AlertConsecutive:
+				sb.WriteString(fmt.Sprintf("  Alerts:    after %d failures in a row\n", j.alertThreshold()))
+			case AlertNever:
+				sb.WriteString("  Alerts:    never\n")
+			}
+			sb.WriteString("\n")
+		}
+		return &tools.ToolResult{ForLLM: sb.String()}
+	})
+
+	// cron_history
+	c.toolRegistry.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "cron_history",
+			Description: "Show the recent runs of a cron job: time, status, duration, exit code, and the start of the output. Use it to check whether a job has been succeeding.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"job_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The ID or Label of the cron job.",
+					},
+					"limit": map[string]interface{}{
+						"type":        "integer",
+						"description": "How many recent runs to show (default 10, max 50).",
+					},
+				},
+				"required": []string{"job_id"},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *tools.ToolResult {
+		jobID, _ := args["job_id"].(string)
+		if jobID == "" {
+			return &tools.ToolResult{ForLLM: "Error: job_id is required."}
+		}
+		limit := 10
+		if l, ok := args["limit"].(float64); ok && l > 0 {
+			limit = min(int(l), 50)
+		}
+		if j := c.cronService.FindJob(jobID); j != nil {
+			jobID = j.ID
+		}
+
+		runs := c.cronService.GetRecentRuns(jobID, limit)
+		if len(runs) == 0 {
+			return &tools.ToolResult{ForLLM: fmt.Sprintf("No recorded runs for cron job '%s'.", jobID)}
+		}
+		ok := 0
+		for _, r := range runs {
+			if r.Status == "ok" {
+				ok++
+			}
+		}
+
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("Last %d run(s) of '%s' (%d ok, %d failed), newest first:\n\n", len(runs), jobID, ok, len(runs)-ok))
+		for i := len(runs) - 1; i >= 0; i-- {
+			r := runs[i]
+			status := "✅ ok"
+			if r.Status != "ok" {
+				status = "❌ " + r.Status
+			}
+			sb.WriteString(fmt.Sprintf("- %s %s (%dms)", time.UnixMilli(r.Ts).Format("2006-01-02 15:04:05"), status, r.DurationMs))
+			if r.ExitCode != 0 {
+				sb.WriteString(fmt.Sprintf(" exit code %d", r.ExitCode))
+			}
+			if r.Error != "" {
+				sb.WriteString(" — " + r.Error)
+			}
 			sb.WriteString("\n")
+			if r.Output != "" {
+				sb.WriteString(fmt.Sprintf("  Output: %s\n", truncateLabel(r.Output, 200)))
+			}
 		}
 		return &tools.ToolResult{ForLLM: sb.String()}
 	})
+
+	// pause_cron / resume_cron
+	for _, name := range []string{"pause_cron", "resume_cron"} {
+		pause := name == "pause_cron"
+		description := "Pause a cron job so it stops running on its schedule, without deleting it. Use resume_cron to turn it back on."
+		if !pause {
+			description = "Resume a paused cron job so it runs on its schedule again."
+		}
+		c.toolRegistry.RegisterTool(providers.ToolDefinition{
+			Type: "function",
+			Function: struct {
+				Name        string                 `json:"name"`
+				Description string                 `json:"description"`
+				Parameters  map[string]interface{} `json:"parameters"`
+			}{
+				Name:        name,
+				Description: description,
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"job_id": map[string]interface{}{
+							"type":        "string",
+							"description": "The ID or Label of the cron job.",
+						},
+					},
+					"required": []string{"job_id"},
+				},
+			},
+		}, func(ctx context.Context, args map[string]interface{}) *tools.ToolResult {
+			jobID, _ := args["job_id"].(string)
+			job := c.cronService.FindJob(jobID)
+			if job == nil {
+				return &tools.ToolResult{ForLLM: fmt.Sprintf("Error: cron job '%s' not found. Use list_cron to see jobs.", jobID)}
+			}
+			if pause {
+				if err := c.cronService.PauseJob(job.ID); err != nil {
+					return &tools.ToolResult{ForLLM: fmt.Sprintf("Failed to pause cron job: %v", err)}
+				}
+				return &tools.ToolResult{ForLLM: fmt.Sprintf("Cron job '%s' paused. It keeps its settings and history.", job.Label)}
+			}
+			if err := c.cronService.ResumeJob(job.ID); err != nil {
+				return &tools.ToolResult{ForLLM: fmt.Sprintf("Failed to resume cron job: %v", err)}
+			}
+			return &tools.ToolResult{ForLLM: fmt.Sprintf("Cron job '%s' resumed.", job.Label)}
+		})
+	}
+
+	c.registerScheduleOnceTool()
+
+	c.toolRegistry.SetToolGroup("schedule", "add_cron", "remove_cron", "update_cron", "list_cron", "cron_history", "pause_cron", "resume_cron", "schedule_once")
+	c.toolRegistry.SetToolGroupKeywords("schedule", "cron", "remind", "reminder", "every", "daily", "weekly", "hourly", "recurring", "tomorrow", "morning", "evening", "job", "schedule", "later", "pause", "resume", "reschedule")
 }
@@ -0,0 +1,83 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"littleclaw/pkg/providers"
+	"littleclaw/pkg/tools"
+)
+
+// maxListedModels caps list_models output; OpenRouter alone serves hundreds.
+const maxListedModels = 100
+
+// registerModelsTool adds list_models, which shows the models the chat
+// provider serves so a model can be picked by its exact ID.
+func (c *NanoCore) registerModelsTool() {
+	c.toolRegistry.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "list_models",
+			Description: "Lists the models the current LLM provider serves, with their exact IDs, and marks the one in use. Use when the user asks which models are available or wants to switch model.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"filter": map[string]interface{}{
+						"type":        "string",
+						"description": "Only list models whose ID or description contains this text, e.g. 'llama' or 'claude'.",
+					},
+				},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *tools.ToolResult {
+		provider, current := c.chatModel()
+		models, err := provider.ListModels(ctx)
+		if err != nil {
+			return &tools.ToolResult{ForLLM: fmt.Sprintf("Error listing %s models: %v", provider.Name(), err)}
+		}
+
+		filter, _ := args["filter"].(string)
+		filter = strings.ToLower(strings.TrimSpace(filter))
+		var matched []providers.ModelInfo
+		for _, m := range models {
+			if filter == "" || strings.Contains(strings.ToLower(m.ID+" "+m.Description), filter) {
+				matched = append(matched, m)
+			}
+		}
+		if len(matched) == 0 {
+			if filter != "" {
+				return &tools.ToolResult{ForLLM: fmt.Sprintf("No %s models match %q (%d models in total).", provider.Name(), filter, len(models))}
+			}
+			return &tools.ToolResult{ForLLM: fmt.Sprintf("%s lists no models.", provider.Name())}
+		}
+
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "%d %s models (current: %s):\n", len(matched), provider.Name(), current)
+		for i, m := range matched {
+			if i == maxListedModels {
+				fmt.Fprintf(&sb, "... and %d more; narrow the list with filter.\n", len(matched)-i)
+				break
+			}
+			sb.WriteString("- " + m.ID)
+			if m.ID == current {
+				sb.WriteString(" [current]")
+			}
+			if m.Description != "" && m.Description != m.ID {
+				sb.WriteString(" — " + m.Description)
+			}
+			if m.ContextLength > 0 {
+				fmt.Fprintf(&sb, " (%dk context)", m.ContextLength/1000)
+			}
+			sb.WriteString("\n")
+		}
+		return &tools.ToolResult{ForLLM: sb.String()}
+	})
+
+	c.toolRegistry.SetToolGroup("models", "list_models")
+	c.toolRegistry.SetToolGroupKeywords("models", "model", "models", "llm", "gpt", "claude", "llama", "grok", "gemini", "switch")
+}
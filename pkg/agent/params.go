@@ -0,0 +1,201 @@
+package agent
+
+import (
+	"time"
+
+	"littleclaw/pkg/memory"
+)
+
+const (
+	// DefaultMaxIterations caps tool-call rounds per message.
+	DefaultMaxIterations = 10
+	// DefaultTemperature is the sampling temperature sent with every request.
+	DefaultTemperature = 0.7
+)
+
+// AgentParams tunes the ReAct loop. Zero values (and nil pointers) keep the
+// defaults, so a per-chat override only needs the fields it changes.
+type AgentParams struct {
+	MaxIterations    int      // tool-call rounds per message (default 10)
+	Temperature      *float64 // sampling temperature (default 0.7)
+	MaxTokens        int      // completion token cap sent to the provider (default: provider's)
+	HistoryBytes     int      // daily-log tail injected into the system prompt (default 16000)
+	EntityTopK       *int     // relevant entities summarized in the system prompt (default 3, 0 disables)
+	ContextWindow    int      // model context window in tokens, for pre-compaction (default: estimated)
+	MaxContinuations *int     // continue turns for replies cut off by max_tokens (default 2, 0 disables)
+	Verify           *bool    // self-check the final reply of turns that used tools (default off)
+	TokenBudget      int      // tokens one run may use across iterations before wrapping up (default unlimited)
+	CostBudget       float64  // estimated USD one run may spend, priced with PromptPrice/CompletionPrice (default unlimited)
+	PromptPrice      float64  // USD per million prompt tokens, for CostBudget
+	CompletionPrice  float64  // USD per million completion tokens, for CostBudget
+	Progress         string   // status messages during long runs: ProgressOff, ProgressNormal (default), ProgressVerbose
+	Coalesce         string   // tool messages to the chat: CoalesceOff (default), CoalesceWindow, CoalesceRun
+	CoalesceSeconds  int      // how long CoalesceWindow holds messages (default 3)
+}
+
+// merge returns p with every field that o sets replaced by o's value.
+func (p AgentParams) merge(o AgentParams) AgentParams {
+	if o.MaxIterations > 0 {
+		p.MaxIterations = o.MaxIterations
+	}
+	if o.Temperature != nil {
+		p.Temperature = o.Temperature
+	}
+	if o.MaxTokens > 0 {
+		p.MaxTokens = o.MaxTokens
+	}
+	if o.HistoryBytes > 0 {
+		p.HistoryBytes = o.HistoryBytes
+	}
+	if o.EntityTopK != nil {
+		p.EntityTopK = o.EntityTopK
+	}
+	if o.ContextWindow > 0 {
+		p.ContextWindow = o.ContextWindow
+	}
+	if o.MaxContinuations != nil {
+		p.MaxContinuations = o.MaxContinuations
+	}
+	if o.Verify != nil {
+		p.Verify = o.Verify
+	}
+	if o.TokenBudget > 0 {
+		p.TokenBudget = o.TokenBudget
+	}
+	if o.CostBudget > 0 {
+		p.CostBudget = o.CostBudget
+	}
+	if o.PromptPrice > 0 {
+		p.PromptPrice = o.PromptPrice
+	}
+	if o.CompletionPrice > 0 {
+		p.CompletionPrice = o.CompletionPrice
+	}
+	if o.Progress != "" {
+		p.Progress = o.Progress
+	}
+	if o.Coalesce != "" {
+		p.Coalesce = o.Coalesce
+	}
+	if o.CoalesceSeconds > 0 {
+		p.CoalesceSeconds = o.CoalesceSeconds
+	}
+	return p
+}
+
+// resolvedParams are AgentParams with the defaults filled in.
+type resolvedParams struct {
+	maxIterations    int
+	temperature      float64
+	maxTokens        int
+	historyBytes     int
+	entityTopK       int
+	maxContinuations int
+	verify           bool
+	budget           runBudget
+	progress         string
+	coalesce         string
+	coalesceWindow   time.Duration
+}
+
+func (p AgentParams) resolve() resolvedParams {
+	r := resolvedParams{
+		maxIterations:    DefaultMaxIterations,
+		temperature:      DefaultTemperature,
+		maxTokens:        p.MaxTokens,
+		historyBytes:     historyBudgetBytes,
+		entityTopK:       memory.DefaultEntityTopK,
+		maxContinuations: DefaultMaxContinuations,
+		progress:         ProgressNormal,
+		coalesce:         CoalesceOff,
+		coalesceWindow:   DefaultCoalesceWindow,
+	}
+	if p.MaxIterations > 0 {
+		r.maxIterations = p.MaxIterations
+	}
+	if p.Temperature != nil {
+		r.temperature = *p.Temperature
+	}
+	if p.HistoryBytes > 0 {
+		r.historyBytes = p.HistoryBytes
+	}
+	if p.EntityTopK != nil && *p.EntityTopK >= 0 {
+		r.entityTopK = *p.EntityTopK
+	}
+	if p.MaxContinuations != nil && *p.MaxContinuations >= 0 {
+		r.maxContinuations = *p.MaxContinuations
+	}
+	if p.Verify != nil {
+		r.verify = *p.Verify
+	}
+	if p.Progress == ProgressOff || p.Progress == ProgressVerbose {
+		r.progress = p.Progress
+	}
+	if p.Coalesce == CoalesceWindow || p.Coalesce == CoalesceRun {
+		r.coalesce = p.Coalesce
+	}
+	if p.CoalesceSeconds > 0 {
+		r.coalesceWindow = time.Duration(p.CoalesceSeconds) * time.Second
+	}
+	r.budget = runBudget{
+		maxTokens:       p.TokenBudget,
+		maxCost:         p.CostBudget,
+		promptPrice:     p.PromptPrice,
+		completionPrice: p.CompletionPrice,
+	}
+	return r
+}
+
+// SetAgentParams replaces the default loop parameters for every chat.
+func (c *NanoCore) SetAgentParams(p AgentParams) {
+	c.paramsMu.Lock()
+	defer c.paramsMu.Unlock()
+	c.params = p
+	if p.ContextWindow > 0 {
+		c.ContextWindowEst = p.ContextWindow
+	}
+}
+
+// SetChatParams overrides loop parameters for one chat; fields left at zero
+// fall back to the defaults from SetAgentParams.
+func (c *NanoCore) SetChatParams(chatID string, p AgentParams) {
+	c.paramsMu.Lock()
+	defer c.paramsMu.Unlock()
+	if c.chatParams == nil {
+		c.chatParams = make(map[string]AgentParams)
+	}
+	c.chatParams[chatID] = p
+}
+
+// ReplaceChatParams swaps every per-chat override for the given ones at once,
+// e.g. after a config reload removed a chat.
+func (c *NanoCore) ReplaceChatParams(chats map[string]AgentParams) {
+	c.paramsMu.Lock()
+	defer c.paramsMu.Unlock()
+	c.chatParams = make(map[string]AgentParams, len(chats))
+	for id, p := range chats {
+		c.chatParams[id] = p
+	}
+}
+
+// SetMaxContinuations sets how many "continue" turns a reply cut off by the
+// provider's length limit may get; 0 disables auto-continue.
+func (c *NanoCore) SetMaxContinuations(n int) {
+	if n < 0 {
+		return
+	}
+	c.paramsMu.Lock()
+	defer c.paramsMu.Unlock()
+	c.params.MaxContinuations = &n
+}
+
+// paramsFor returns the effective loop parameters for a chat.
+func (c *NanoCore) paramsFor(chatID string) resolvedParams {
+	c.paramsMu.Lock()
+	defer c.paramsMu.Unlock()
+	p := c.params
+	if o, ok := c.chatParams[chatID]; ok {
+		p = p.merge(o)
+	}
+	return p.resolve()
+}
@@ -0,0 +1,91 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"littleclaw/pkg/memory"
+	"littleclaw/pkg/providers"
+	"littleclaw/pkg/tools"
+)
+
+// registerPersonaTool adds set_persona, which edits memory/SYSTEM.md.
+func (c *NanoCore) registerPersonaTool() {
+	c.toolRegistry.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "set_persona",
+			Description: "Changes your persona (memory/SYSTEM.md): your name, tone, reply language, and standing instructions that apply to every conversation. Only the fields given are changed. Call with no fields to show the current persona; reset=true restores the defaults.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"name": map[string]interface{}{
+						"type":        "string",
+						"description": "The name you go by, e.g. 'Jarvis'.",
+					},
+					"tone": map[string]interface{}{
+						"type":        "string",
+						"description": "How you write, e.g. 'Warm and playful, short sentences.'",
+					},
+					"language": map[string]interface{}{
+						"type":        "string",
+						"description": "Language to reply in, e.g. 'German'. Use 'none' to clear.",
+					},
+					"instructions": map[string]interface{}{
+						"type":        "string",
+						"description": "Standing instructions, replacing the current ones, e.g. 'Always give prices in EUR.' Use 'none' to clear.",
+					},
+					"reset": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Delete SYSTEM.md and go back to the default persona.",
+					},
+				},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *tools.ToolResult {
+		if reset, _ := args["reset"].(bool); reset {
+			if err := c.memoryStore.WritePersona(memory.Persona{}); err != nil {
+				return &tools.ToolResult{ForLLM: fmt.Sprintf("Error resetting persona: %v", err)}
+			}
+			return &tools.ToolResult{ForLLM: "Persona reset to defaults (SYSTEM.md removed)."}
+		}
+
+		current := c.memoryStore.ReadPersona()
+		update := memory.Persona{}
+		update.Name, _ = args["name"].(string)
+		update.Tone, _ = args["tone"].(string)
+		update.Language, _ = args["language"].(string)
+		update.Instructions, _ = args["instructions"].(string)
+		update.Name = strings.TrimSpace(update.Name)
+		update.Tone = strings.TrimSpace(update.Tone)
+		update.Language = strings.TrimSpace(update.Language)
+		update.Instructions = strings.TrimSpace(update.Instructions)
+
+		if update.IsZero() {
+			if current.IsZero() {
+				return &tools.ToolResult{ForLLM: "No custom persona set (no SYSTEM.md); using defaults.\n\n" + current.WithDefaults().Render()}
+			}
+			return &tools.ToolResult{ForLLM: current.Render()}
+		}
+
+		next := current.Merge(update)
+		if strings.EqualFold(update.Language, "none") {
+			next.Language = ""
+		}
+		if strings.EqualFold(update.Instructions, "none") {
+			next.Instructions = ""
+		}
+		if err := c.memoryStore.WritePersona(next); err != nil {
+			return &tools.ToolResult{ForLLM: fmt.Sprintf("Error saving persona: %v", err)}
+		}
+		return &tools.ToolResult{ForLLM: "Persona updated; it applies from the next message.\n\n" + next.WithDefaults().Render()}
+	})
+
+	c.toolRegistry.SetToolGroup("persona", "set_persona")
+	c.toolRegistry.SetToolGroupKeywords("persona", "persona", "personality", "name", "call", "tone", "language", "speak", "reply", "instructions", "always", "behave", "style")
+}
@@ -0,0 +1,142 @@
+package agent
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"littleclaw/pkg/bus"
+)
+
+// Progress verbosity for status messages during long runs.
+const (
+	ProgressOff     = "off"     // no status messages
+	ProgressNormal  = "normal"  // once a run passes progressDelay, at most one per progressInterval (default)
+	ProgressVerbose = "verbose" // one for every tool call, right away
+)
+
+const (
+	// progressDelay is how long a run goes before status messages start.
+	progressDelay = 5 * time.Second
+	// progressInterval is the minimum gap between status messages in normal mode.
+	progressInterval = 15 * time.Second
+)
+
+// toolStatusText maps tools to the status shown while they run; tools with
+// more useful detail are handled in toolStatus.
+var toolStatusText = map[string]string{
+	"read_file":       "📄 Reading files…",
+	"write_file":      "✏️ Writing files…",
+	"append_file":     "✏️ Writing files…",
+	"edit_file":       "✏️ Editing files…",
+	"list_files":      "📂 Looking through files…",
+	"list_workspace":  "📂 Looking through files…",
+	"search_history":  "🧠 Searching past conversations…",
+	"read_entity":     "🧠 Checking memory…",
+	"list_entities":   "🧠 Checking memory…",
+	"spawn":           "🤖 Starting a background helper…",
+	"delegate":        "🤖 Asking a specialist…",
+	"get_weather":     "🌦 Checking the weather…",
+	"list_events":     "📅 Checking the calendar…",
+	"find_free_slot":  "📅 Checking the calendar…",
+	"compress":        "📦 Packing files…",
+	"extract":         "📦 Unpacking files…",
+	"sql_query":       "🗄 Querying the database…",
+	"git":             "🛠 Running git…",
+	"analyze_image":   "🖼 Looking at the image…",
+	"take_screenshot": "🖥 Taking a screenshot…",
+}
+
+// toolStatus returns a short user-facing line describing a tool call.
+func toolStatus(name string, args map[string]interface{}) string {
+	switch name {
+	case "web_search":
+		if q, _ := args["query"].(string); q != "" {
+			return fmt.Sprintf("🔍 Searching the web for \"%s\"…", truncateLabel(q, 60))
+		}
+		return "🔍 Searching the web…"
+	case "web_fetch":
+		if u, err := url.Parse(fmt.Sprint(args["url"])); err == nil && u.Host != "" {
+			return "🌐 Reading " + u.Host + "…"
+		}
+		return "🌐 Reading a web page…"
+	case "exec":
+		if cmd, _ := args["command"].(string); cmd != "" {
+			return fmt.Sprintf("🛠 Running `%s`…", truncateLabel(strings.TrimSpace(cmd), 60))
+		}
+	}
+	if text, ok := toolStatusText[name]; ok {
+		return text
+	}
+	return fmt.Sprintf("⚙️ Using %s…", name)
+}
+
+// runProgress sends status messages for one RunAgentLoop call.
+type runProgress struct {
+	c     *NanoCore
+	msg   bus.InboundMessage
+	level string
+	start time.Time
+
+	mu       sync.Mutex
+	timer    *time.Timer
+	last     time.Time
+	lastText string
+}
+
+// newRunProgress returns the reporter for a run; internal and system-triggered
+// runs get none.
+func (c *NanoCore) newRunProgress(msg bus.InboundMessage, level string) *runProgress {
+	if level == ProgressOff || msg.Channel == "internal" || msg.SenderID == "system" {
+		return nil
+	}
+	return &runProgress{c: c, msg: msg, level: level, start: time.Now()}
+}
+
+// toolStarted reports a tool call. In normal mode the status only goes out if
+// the run has already taken progressDelay, or once it does while the tool runs.
+func (p *runProgress) toolStarted(name string, args map[string]interface{}) {
+	if p == nil {
+		return
+	}
+	text := toolStatus(name, args)
+	if p.level == ProgressVerbose {
+		p.send(text, 0)
+		return
+	}
+	if wait := progressDelay - time.Since(p.start); wait > 0 {
+		p.mu.Lock()
+		p.timer = time.AfterFunc(wait, func() { p.send(text, progressInterval) })
+		p.mu.Unlock()
+		return
+	}
+	p.send(text, progressInterval)
+}
+
+// toolDone cancels a status that has not gone out yet.
+func (p *runProgress) toolDone() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.timer != nil {
+		p.timer.Stop()
+		p.timer = nil
+	}
+}
+
+// send delivers text unless it repeats the previous status or comes within
+// minGap of it.
+func (p *runProgress) send(text string, minGap time.Duration) {
+	p.mu.Lock()
+	if text == p.lastText || (!p.last.IsZero() && time.Since(p.last) < minGap) {
+		p.mu.Unlock()
+		return
+	}
+	p.last, p.lastText = time.Now(), text
+	p.mu.Unlock()
+	p.c.sendNotice(p.msg, text)
+}
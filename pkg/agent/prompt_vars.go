@@ -0,0 +1,103 @@
+package agent
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"text/template"
+	"time"
+
+	"littleclaw/pkg/bus"
+)
+
+// DefaultContextTemplate is the system prompt block that tells the agent where
+// and when it is running. Custom templates see the same PromptVars fields.
+const DefaultContextTemplate = `=== CURRENT CONTEXT ===
+Now: {{.Weekday}} {{.Date}} {{.Time}} ({{.Timezone}})
+Workspace: {{.Workspace}}
+{{- if .Channel}}
+Channel: {{.Channel}}{{if .UserName}} | User: {{.UserName}}{{end}}
+{{- end}}
+Use this date and time for "today", "tomorrow", and other relative dates (e.g. when scheduling cron jobs). Never guess the date.
+=======================
+`
+
+var defaultContextTmpl = template.Must(template.New("context").Parse(DefaultContextTemplate))
+
+// PromptVars are the values available to the context template.
+type PromptVars struct {
+	Date      string // 2006-01-02
+	Time      string // 15:04
+	Weekday   string // Monday
+	Timezone  string // zone name and UTC offset, e.g. "Europe/Berlin (UTC+02:00)"
+	Workspace string // absolute workspace path
+	UserName  string // sender's display name, if the channel knows it
+	Channel   string // "telegram", "cli", "internal", ...
+	ChatID    string
+}
+
+// SetContextTemplate replaces the context block of the system prompt with a
+// text/template over PromptVars; an empty tmpl restores the default.
+func (c *NanoCore) SetContextTemplate(tmpl string) error {
+	if strings.TrimSpace(tmpl) == "" {
+		c.contextTmpl = nil
+		return nil
+	}
+	t, err := template.New("context").Parse(tmpl)
+	if err != nil {
+		return fmt.Errorf("invalid context template: %w", err)
+	}
+	c.contextTmpl = t
+	return nil
+}
+
+// SetTimezone sets the zone used for the date and time in the system prompt
+// (default: the host's local zone).
+func (c *NanoCore) SetTimezone(loc *time.Location) {
+	c.location = loc
+}
+
+// localNow returns the current time in the zone set by SetTimezone.
+func (c *NanoCore) localNow() time.Time {
+	if c.location != nil {
+		return time.Now().In(c.location)
+	}
+	return time.Now()
+}
+
+// promptVarsFor collects the template values for a message handled at now.
+func (c *NanoCore) promptVarsFor(msg bus.InboundMessage, now time.Time) PromptVars {
+	if c.location != nil {
+		now = now.In(c.location)
+	}
+	zone := now.Location().String()
+	if zone == "Local" {
+		zone, _ = now.Zone()
+	}
+	return PromptVars{
+		Date:      now.Format("2006-01-02"),
+		Time:      now.Format("15:04"),
+		Weekday:   now.Weekday().String(),
+		Timezone:  fmt.Sprintf("%s (UTC%s)", zone, now.Format("-07:00")),
+		Workspace: c.workspace,
+		UserName:  msg.SenderName,
+		Channel:   msg.Channel,
+		ChatID:    msg.ChatID,
+	}
+}
+
+// renderContext executes the context template, falling back to the default
+// when a custom template fails.
+func (c *NanoCore) renderContext(vars PromptVars) string {
+	var sb strings.Builder
+	if c.contextTmpl != nil {
+		err := c.contextTmpl.Execute(&sb, vars)
+		if err == nil {
+			return sb.String()
+		}
+		slog.Warn("context template failed, using the default", "err", err)
+		sb.Reset()
+	}
+	_ = defaultContextTmpl.Execute(&sb, vars)
+	return sb.String()
+}
@@ -0,0 +1,172 @@
+package agent
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"littleclaw/pkg/bus"
+)
+
+// RateLimitPolicy caps how much one sender can use the agent; zero fields
+// are unlimited. System runs, the CLI, and Exempt senders are never limited.
+type RateLimitPolicy struct {
+	MessagesPerMinute int      // messages handled per sender per rolling minute
+	ConcurrentRuns    int      // runs one sender may have in flight
+	DailyTokens       int      // prompt+completion tokens per sender per day
+	Exempt            []string // sender IDs without limits, e.g. the owner
+}
+
+// rateLimiter tracks each sender's recent messages, runs, and tokens.
+type rateLimiter struct {
+	mu      sync.Mutex
+	p       RateLimitPolicy
+	seeded  bool // today's tokens were read from UsageFile
+	senders map[string]*senderUsage
+}
+
+type senderUsage struct {
+	recent   []time.Time // handled messages in the last minute
+	running  int
+	day      string // day tokens were counted on, "2006-01-02"
+	tokens   int
+	notified time.Time // last "slow down" reply, so a flood gets one
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{senders: make(map[string]*senderUsage)}
+}
+
+// SetRateLimits applies p to every later message; usage counted so far is
+// kept. Today's token spend per sender is read back from the usage log, so a
+// restart does not reset it.
+func (c *NanoCore) SetRateLimits(p RateLimitPolicy) {
+	l := c.limits
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.p = p
+	if p.DailyTokens > 0 && !l.seeded {
+		l.seeded = true
+		now := time.Now()
+		tokens, err := readSenderTokens(c.workspace, time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()))
+		if err != nil {
+			slog.Warn("failed to read today's usage per sender", "err", err)
+		}
+		day := now.Format(time.DateOnly)
+		for sender, n := range tokens {
+			u := l.usage(sender)
+			u.day, u.tokens = day, n
+		}
+	}
+}
+
+// usage returns the counters of sender, creating them; l.mu must be held.
+func (l *rateLimiter) usage(sender string) *senderUsage {
+	u := l.senders[sender]
+	if u == nil {
+		u = &senderUsage{}
+		l.senders[sender] = u
+	}
+	return u
+}
+
+// exempt reports whether msg is never limited.
+func (l *rateLimiter) exempt(msg bus.InboundMessage) bool {
+	return msg.SenderID == "" || msg.SenderID == "system" || msg.Channel == "internal" || msg.Channel == "cli" ||
+		slices.Contains(l.p.Exempt, msg.SenderID)
+}
+
+// acquire counts msg against its sender's limits. On success it returns a
+// release func to call when the run ends; otherwise it returns the reason and
+// whether the sender should be told (once a minute, so a flood is not
+// answered message by message).
+func (l *rateLimiter) acquire(msg bus.InboundMessage, now time.Time) (release func(), reason string, notify bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.exempt(msg) {
+		return func() {}, "", false
+	}
+	u := l.usage(msg.SenderID)
+
+	cutoff := now.Add(-time.Minute)
+	recent := u.recent[:0]
+	for _, t := range u.recent {
+		if t.After(cutoff) {
+			recent = append(recent, t)
+		}
+	}
+	u.recent = recent
+	if u.day != now.Format(time.DateOnly) {
+		u.day, u.tokens = now.Format(time.DateOnly), 0
+	}
+
+	switch p := l.p; {
+	case p.MessagesPerMinute > 0 && len(u.recent) >= p.MessagesPerMinute:
+		reason = fmt.Sprintf("You can send up to %d messages a minute. Please wait a moment.", p.MessagesPerMinute)
+	case p.ConcurrentRuns > 0 && u.running >= p.ConcurrentRuns:
+		reason = "I'm still working on your last request. Send this again when it's done, or /stop it."
+	case p.DailyTokens > 0 && u.tokens >= p.DailyTokens:
+		reason = fmt.Sprintf("You've used today's allowance of %s tokens. It resets at midnight.", formatTokens(p.DailyTokens))
+	}
+	if reason != "" {
+		notify = now.Sub(u.notified) >= time.Minute
+		if notify {
+			u.notified = now
+		}
+		return nil, reason, notify
+	}
+
+	u.recent = append(u.recent, now)
+	u.running++
+	return func() {
+		l.mu.Lock()
+		u.running--
+		l.mu.Unlock()
+	}, "", false
+}
+
+// addTokens adds a finished run's tokens to its sender's daily spend.
+func (l *rateLimiter) addTokens(msg bus.InboundMessage, tokens int, now time.Time) {
+	if tokens == 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.exempt(msg) {
+		return
+	}
+	u := l.usage(msg.SenderID)
+	if day := now.Format(time.DateOnly); u.day != day {
+		u.day, u.tokens = day, 0
+	}
+	u.tokens += tokens
+}
+
+// readSenderTokens sums the UsageFile tokens per sender since the given time.
+func readSenderTokens(workspaceDir string, since time.Time) (map[string]int, error) {
+	tokens := make(map[string]int)
+	f, err := os.Open(filepath.Join(workspaceDir, UsageFile))
+	if os.IsNotExist(err) {
+		return tokens, nil
+	}
+	if err != nil {
+		return tokens, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec UsageRecord
+		if json.Unmarshal(scanner.Bytes(), &rec) != nil || rec.SenderID == "" || rec.Time.Before(since) {
+			continue
+		}
+		tokens[rec.SenderID] += rec.PromptTokens + rec.CompletionTokens
+	}
+	return tokens, scanner.Err()
+}
@@ -0,0 +1,153 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"littleclaw/pkg/providers"
+	"littleclaw/pkg/tools"
+)
+
+// AgentRole is a named specialist the main agent can hand work to with the
+// delegate tool, e.g. a "researcher" with web tools on a large model.
+type AgentRole struct {
+	Name          string
+	Description   string             // when to use this role; shown to the main agent
+	Provider      providers.Provider // nil uses the chat provider
+	Model         string             // empty uses the chat model
+	Tools         []string           // tool names the role may use; empty allows every sub-agent tool
+	Instructions  string             // extra system prompt for the role
+	MaxIterations int                // tool-call budget (default DefaultSubAgentIterations)
+}
+
+// SetAgentRoles configures the delegation roles and registers the delegate
+// tool. Roles with an empty name are skipped.
+func (c *NanoCore) SetAgentRoles(roles []AgentRole) {
+	c.roles = make(map[string]AgentRole, len(roles))
+	for _, r := range roles {
+		if r.Name = strings.TrimSpace(r.Name); r.Name != "" {
+			c.roles[r.Name] = r
+		}
+	}
+	if len(c.roles) == 0 {
+		return
+	}
+	c.registerDelegateTool()
+}
+
+// roleNames returns the configured role names, sorted.
+func (c *NanoCore) roleNames() []string {
+	names := make([]string, 0, len(c.roles))
+	for n := range c.roles {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// roleSpec builds the sub-agent settings for a role.
+func (c *NanoCore) roleSpec(r AgentRole) subAgentSpec {
+	provider, model := c.chatModel()
+	spec := subAgentSpec{
+		provider:      provider,
+		model:         model,
+		defs:          c.subAgentToolDefs(r.Tools),
+		maxIterations: DefaultSubAgentIterations,
+	}
+	if r.Provider != nil {
+		spec.provider = r.Provider
+	}
+	if r.Model != "" {
+		spec.model = r.Model
+	}
+	if r.MaxIterations > 0 {
+		spec.maxIterations = r.MaxIterations
+	}
+
+	var sb strings.Builder
+	sb.WriteString(subAgentSystemPrompt)
+	sb.WriteString(fmt.Sprintf("\n\nYOUR ROLE: %s", r.Name))
+	if r.Description != "" {
+		sb.WriteString(" - " + r.Description)
+	}
+	if r.Instructions != "" {
+		sb.WriteString("\n" + r.Instructions)
+	}
+	spec.systemPrompt = sb.String()
+	return spec
+}
+
+// registerDelegateTool adds delegate, which runs a task with a role and
+// returns the role's report to the main agent.
+func (c *NanoCore) registerDelegateTool() {
+	names := c.roleNames()
+	var roster strings.Builder
+	for _, n := range names {
+		roster.WriteString(fmt.Sprintf("\n- %s", n))
+		if d := c.roles[n].Description; d != "" {
+			roster.WriteString(": " + d)
+		}
+	}
+
+	c.toolRegistry.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "delegate",
+			Description: "Hands a task to a specialist agent and waits for its report, which you then use to answer the user. The specialist works with its own model and tools and does not see this conversation, so include all context it needs. Specialists:" + roster.String(),
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"agent": map[string]interface{}{
+						"type":        "string",
+						"enum":        names,
+						"description": "The specialist to use.",
+					},
+					"task": map[string]interface{}{
+						"type":        "string",
+						"description": "Complete instructions for the specialist.",
+					},
+				},
+				"required": []string{"agent", "task"},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *tools.ToolResult {
+		name, _ := args["agent"].(string)
+		task, _ := args["task"].(string)
+		task = strings.TrimSpace(task)
+		role, ok := c.roles[name]
+		if !ok {
+			return &tools.ToolResult{ForLLM: fmt.Sprintf("Error: unknown agent %q (available: %s)", name, strings.Join(c.roleNames(), ", "))}
+		}
+		if task == "" {
+			return &tools.ToolResult{ForLLM: "Error: task is required"}
+		}
+
+		spec := c.roleSpec(role)
+		if len(role.Tools) > 0 && len(spec.defs) == 0 {
+			return &tools.ToolResult{ForLLM: fmt.Sprintf("Error: none of %s's tools are available", name)}
+		}
+
+		chatID, channel := c.replyTarget(ctx)
+		run, err := c.subAgents.start(fmt.Sprintf("[%s] %s", name, task), chatID, channel)
+		if err != nil {
+			return &tools.ToolResult{ForLLM: fmt.Sprintf("Error: %v", err)}
+		}
+		report, status, iterations, files, tempFiles := c.subAgentLoop(ctx, spec, task)
+		c.subAgents.finish(run, status, iterations)
+
+		return &tools.ToolResult{
+			ForLLM:    fmt.Sprintf("[%s %s after %d iteration(s)]\n%s", name, status, iterations, report),
+			Files:     files,
+			TempFiles: tempFiles,
+		}
+	})
+
+	c.toolRegistry.SetToolGroup("subagents", "delegate")
+	c.toolRegistry.SetToolGroupKeywords("subagents", names...)
+}
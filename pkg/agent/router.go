@@ -0,0 +1,160 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/providers"
+)
+
+const (
+	// DefaultRouterMaxChars is the longest message the router may call simple.
+	DefaultRouterMaxChars = 280
+	// DefaultRouterMaxContextTokens is the longest conversation, in estimated
+	// tokens, the router hands to the cheap model.
+	DefaultRouterMaxContextTokens = 6000
+
+	// routerClassifyTimeout bounds the cheap model's verdict; on timeout the
+	// run goes to the chat model.
+	routerClassifyTimeout = 10 * time.Second
+)
+
+// ModelRouter sends simple messages (greetings, chit-chat, quick questions)
+// to a cheap model and keeps the chat model for tool-heavy or long-context
+// work. Zero fields use the defaults.
+type ModelRouter struct {
+	Provider         providers.Provider // cheap model's provider; nil uses the chat provider
+	Model            string             // cheap model; empty disables routing
+	UseModel         bool               // also have the cheap model confirm what the heuristic calls simple
+	MaxChars         int                // longer messages are complex (default DefaultRouterMaxChars)
+	MaxContextTokens int                // so are longer conversations (default DefaultRouterMaxContextTokens)
+}
+
+// complexWords mark requests that need reasoning or tools even when no
+// tool's keywords match.
+var complexWords = []string{
+	"run", "command", "code", "script", "program", "debug", "fix", "install",
+	"analyze", "analyse", "summarize", "summarise", "compare", "explain", "plan",
+	"research", "translate", "calculate", "remind", "schedule", "step",
+}
+
+const routerClassifyPrompt = `Classify the user's message for routing. Reply with one word:
+SIMPLE - a greeting, thanks, small talk, or a quick question answerable in a sentence or two without tools.
+COMPLEX - anything that needs tools, files, the web, code, calculations, several steps, or a long answer.`
+
+// SetModelRouter enables routing simple messages to r.Model; an empty Model
+// disables it. Runs already in flight keep their model.
+func (c *NanoCore) SetModelRouter(r ModelRouter) {
+	if r.MaxChars <= 0 {
+		r.MaxChars = DefaultRouterMaxChars
+	}
+	if r.MaxContextTokens <= 0 {
+		r.MaxContextTokens = DefaultRouterMaxContextTokens
+	}
+	c.modelMu.Lock()
+	defer c.modelMu.Unlock()
+	if r.Model == "" {
+		c.router = nil
+		return
+	}
+	c.router = &r
+}
+
+// routeModel returns the cheap provider and model when the router is on and
+// the run looks simple. messages is the run's prompt; ok is false to keep
+// the chat model.
+func (c *NanoCore) routeModel(ctx context.Context, msg bus.InboundMessage, messages []providers.Message) (providers.Provider, string, bool) {
+	c.modelMu.RLock()
+	r, chat := c.router, c.provider
+	c.modelMu.RUnlock()
+	// Cron jobs, heartbeat work, and other system runs are tool work
+	if r == nil || msg.Channel == "internal" || msg.SenderID == "system" {
+		return nil, "", false
+	}
+	provider := r.Provider
+	if provider == nil {
+		provider = chat
+	}
+
+	if reason := c.complexReason(r, msg, messages); reason != "" {
+		slog.Debug("routed to the chat model", "chat_id", msg.ChatID, "reason", reason)
+		return nil, "", false
+	}
+	if r.UseModel {
+		simple, err := classifyMessage(ctx, provider, r.Model, msg.Content)
+		if err != nil {
+			slog.Warn("router classification failed, using the chat model", "chat_id", msg.ChatID, "err", err)
+			return nil, "", false
+		}
+		if !simple {
+			slog.Debug("routed to the chat model", "chat_id", msg.ChatID, "reason", "classified complex")
+			return nil, "", false
+		}
+	}
+	slog.Debug("routed to the cheap model", "chat_id", msg.ChatID, "model", r.Model)
+	return provider, r.Model, true
+}
+
+// complexReason says why msg needs the chat model, or "" if it looks simple.
+func (c *NanoCore) complexReason(r *ModelRouter, msg bus.InboundMessage, messages []providers.Message) string {
+	text := strings.ToLower(msg.Content)
+	switch {
+	case utf8.RuneCountInString(msg.Content) > r.MaxChars:
+		return "long message"
+	case len(msg.Media) > 0 || strings.Contains(text, "[image attached:"):
+		return "attachment"
+	case strings.Contains(text, "```") || strings.Contains(text, "http://") || strings.Contains(text, "https://"):
+		return "code or link"
+	}
+	// The system prompt is about the same every run, so only the
+	// conversation counts
+	if len(messages) > 1 && estimateMessageTokens(messages[1:]) > r.MaxContextTokens {
+		return "long conversation"
+	}
+	if names := c.toolRegistry.RelevantTools(msg.Content); len(names) > 0 {
+		return "needs tools: " + strings.Join(names, ", ")
+	}
+	for _, w := range strings.FieldsFunc(text, func(r rune) bool { return !('a' <= r && r <= 'z') }) {
+		for _, cw := range complexWords {
+			if wordMatches(w, cw) {
+				return "asks for " + cw
+			}
+		}
+	}
+	return ""
+}
+
+// wordMatches is w or an inflection of keyword ("summarizing", "reminders").
+func wordMatches(w, keyword string) bool {
+	return w == keyword || (len(keyword) >= 4 && strings.HasPrefix(w, keyword))
+}
+
+// classifyMessage asks model whether content is simple enough for it.
+func classifyMessage(ctx context.Context, p providers.Provider, model, content string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, routerClassifyTimeout)
+	defer cancel()
+	resp, err := p.Chat(ctx, providers.ChatRequest{
+		Model: model,
+		Messages: []providers.Message{
+			{Role: "system", Content: routerClassifyPrompt},
+			{Role: "user", Content: content},
+		},
+		MaxTokens: 5,
+	})
+	if err != nil {
+		return false, err
+	}
+	verdict := strings.ToUpper(strings.TrimSpace(resp.Content))
+	switch {
+	case strings.HasPrefix(verdict, "SIMPLE"):
+		return true, nil
+	case strings.HasPrefix(verdict, "COMPLEX"):
+		return false, nil
+	}
+	return false, fmt.Errorf("unexpected verdict %q", resp.Content)
+}
@@ -0,0 +1,88 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"littleclaw/pkg/memory"
+	"littleclaw/pkg/providers"
+	"littleclaw/pkg/tools"
+)
+
+const (
+	// DefaultMemoryIndexInterval is how often memory is synced to the index.
+	DefaultMemoryIndexInterval = 5 * time.Minute
+
+	// semanticMatchChars caps each match semantic_search returns.
+	semanticMatchChars = 800
+)
+
+// EnableMemoryIndex registers semantic_search, which finds memory by meaning
+// in ix. StartMemoryIndex keeps ix in sync with the workspace.
+func (c *NanoCore) EnableMemoryIndex(ix *memory.PgIndex) {
+	c.memoryIndex = ix
+	c.toolRegistry.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "semantic_search",
+			Description: "Searches all of memory (MEMORY.md, entities, every daily log, and summaries) by meaning rather than exact words, e.g. 'what did we decide about the holiday budget'. Returns the closest passages with their source. Use search_history for exact words or dates.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"query": map[string]interface{}{
+						"type":        "string",
+						"description": "What to look for, in plain words.",
+					},
+					"limit": map[string]interface{}{
+						"type":        "integer",
+						"description": "How many passages to return (default 5, max 20).",
+					},
+				},
+				"required": []string{"query"},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *tools.ToolResult {
+		query, _ := args["query"].(string)
+		if query = strings.TrimSpace(query); query == "" {
+			return &tools.ToolResult{ForLLM: "Error: query is required."}
+		}
+		limit := 5
+		if n, ok := args["limit"].(float64); ok && n > 0 {
+			limit = min(int(n), 20)
+		}
+
+		matches, err := ix.Search(ctx, query, limit)
+		if err != nil {
+			return &tools.ToolResult{ForLLM: fmt.Sprintf("Error searching memory: %v", err)}
+		}
+		if len(matches) == 0 {
+			return &tools.ToolResult{ForLLM: "No memory indexed yet, or nothing close to that. Try search_history."}
+		}
+		var sb strings.Builder
+		for _, m := range matches {
+			content := m.Content
+			if len(content) > semanticMatchChars {
+				content = strings.ToValidUTF8(content[:semanticMatchChars], "") + "..."
+			}
+			fmt.Fprintf(&sb, "[%s] (similarity %.2f)\n%s\n\n", m.Source, m.Score, content)
+		}
+		return &tools.ToolResult{ForLLM: strings.TrimSpace(sb.String())}
+	})
+
+	c.toolRegistry.SetToolGroup(tools.ToolGroupCore, "semantic_search")
+}
+
+// StartMemoryIndex syncs memory to the index now and then every interval
+// until ctx ends. It does nothing unless EnableMemoryIndex was called.
+func (c *NanoCore) StartMemoryIndex(ctx context.Context, interval time.Duration) {
+	if c.memoryIndex == nil {
+		return
+	}
+	go c.memoryIndex.Run(ctx, c.memoryStore, interval)
+}
@@ -0,0 +1,223 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/providers"
+	"littleclaw/pkg/tools"
+)
+
+const (
+	// DefaultSessionTTL is how long an idle chat keeps its in-memory session.
+	DefaultSessionTTL = 30 * time.Minute
+	// DefaultSessionMaxMessages caps the messages kept per session.
+	DefaultSessionMaxMessages = 40
+	// sessionMaxChars caps the total content kept per session (~6000 tokens).
+	sessionMaxChars = 24000
+
+	// newCommand starts a fresh conversation in the chat that sends it.
+	newCommand = "/new"
+)
+
+// chatSession is the live message array for one chat, reused across turns.
+type chatSession struct {
+	messages []providers.Message
+	lastUsed time.Time
+}
+
+// sessionStore keeps recent turns per ChatID in memory, including tool calls
+// and results, so follow-up messages see the exact earlier exchange. The daily
+// log files remain the durable record; an expired session falls back to them.
+type sessionStore struct {
+	mu          sync.Mutex
+	sessions    map[string]*chatSession
+	ttl         time.Duration
+	maxMessages int
+}
+
+func newSessionStore() *sessionStore {
+	return &sessionStore{
+		sessions:    make(map[string]*chatSession),
+		ttl:         DefaultSessionTTL,
+		maxMessages: DefaultSessionMaxMessages,
+	}
+}
+
+// get returns a copy of the chat's session messages, or nil if it has none or
+// it has been idle longer than the TTL.
+func (s *sessionStore) get(chatID string) []providers.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expire(time.Now())
+	sess, ok := s.sessions[chatID]
+	if !ok {
+		return nil
+	}
+	return append([]providers.Message(nil), sess.messages...)
+}
+
+// save replaces the chat's session with msgs (system prompt excluded),
+// trimmed to the size caps.
+func (s *sessionStore) save(chatID string, msgs []providers.Message) {
+	msgs = trimSession(msgs, s.maxMessages, sessionMaxChars)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(msgs) == 0 {
+		delete(s.sessions, chatID)
+		return
+	}
+	s.sessions[chatID] = &chatSession{messages: msgs, lastUsed: time.Now()}
+}
+
+// appendMessage adds m to the chat's live session, if it has one, so
+// out-of-band replies (e.g. sub-agent reports) are visible on the next turn.
+func (s *sessionStore) appendMessage(chatID string, m providers.Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sess, ok := s.sessions[chatID]; ok {
+		sess.messages = append(sess.messages, m)
+		sess.lastUsed = time.Now()
+	}
+}
+
+// reset drops the chat's session.
+func (s *sessionStore) reset(chatID string) {
+	s.mu.Lock()
+	delete(s.sessions, chatID)
+	s.mu.Unlock()
+}
+
+// expire drops idle sessions; callers must hold s.mu.
+func (s *sessionStore) expire(now time.Time) {
+	for id, sess := range s.sessions {
+		if now.Sub(sess.lastUsed) > s.ttl {
+			delete(s.sessions, id)
+		}
+	}
+}
+
+// trimSession drops the oldest messages until both caps are met, then skips
+// ahead to a user message so the session never starts with tool results or an
+// assistant tool call whose results were cut off.
+func trimSession(msgs []providers.Message, maxMessages, maxChars int) []providers.Message {
+	total := 0
+	for _, m := range msgs {
+		total += len(m.Content)
+	}
+	start := 0
+	for start < len(msgs) && (len(msgs)-start > maxMessages || total > maxChars) {
+		total -= len(msgs[start].Content)
+		start++
+	}
+	for start < len(msgs) && msgs[start].Role != "user" {
+		start++
+	}
+	return append([]providers.Message(nil), msgs[start:]...)
+}
+
+// SetSessionLimits configures the per-chat in-memory session: idle TTL and
+// maximum messages kept. A non-positive value keeps the default.
+func (c *NanoCore) SetSessionLimits(ttl time.Duration, maxMessages int) {
+	c.sessions.mu.Lock()
+	defer c.sessions.mu.Unlock()
+	if ttl > 0 {
+		c.sessions.ttl = ttl
+	}
+	if maxMessages > 0 {
+		c.sessions.maxMessages = maxMessages
+	}
+}
+
+// ResetSession forgets the in-memory session for a chat; the next message
+// starts from the file-based history again.
+func (c *NanoCore) ResetSession(chatID string) {
+	c.sessions.reset(chatID)
+}
+
+// ClearSession starts a fresh conversation for a chat: its in-memory session
+// is dropped and the recent-history window of the system prompt starts empty.
+// MEMORY.md, entities, and the daily logs are left alone. A forum topic is
+// cleared by its session key, "<chat>/<topic>".
+func (c *NanoCore) ClearSession(chatID string) error {
+	c.sessions.reset(chatID)
+	if err := c.memoryStore.ResetRecentHistory(); err != nil {
+		return fmt.Errorf("reset recent history: %w", err)
+	}
+	slog.Info("started a fresh conversation", "chat_id", chatID)
+	return nil
+}
+
+// isNewCommand reports whether content is /new (optionally /new@botname).
+func isNewCommand(content string) bool {
+	content = strings.ToLower(strings.TrimSpace(content))
+	return content == newCommand || strings.HasPrefix(content, newCommand+"@")
+}
+
+// handleNewCommand answers /new, clearing the session of the chat or forum
+// topic it was sent in.
+func (c *NanoCore) handleNewCommand(msg bus.InboundMessage) {
+	reply := "🆕 Fresh start. I still remember everything in long-term memory."
+	if err := c.ClearSession(sessionKey(msg.ChatID, msg.ThreadID)); err != nil {
+		reply = fmt.Sprintf("⚠ Could not start fresh: %v", err)
+	}
+	c.sendReply(msg, reply, nil)
+}
+
+// sessionKey identifies a conversation: the chat, or one forum topic of it,
+// so each topic keeps its own session.
+func sessionKey(chatID string, threadID int) string {
+	if threadID == 0 {
+		return chatID
+	}
+	return chatID + "/" + strconv.Itoa(threadID)
+}
+
+// turnCalledTool reports whether the current turn called the named tool.
+func turnCalledTool(messages []providers.Message, turnStart int, name string) bool {
+	for _, m := range messages[turnStart+1:] {
+		for _, tc := range m.ToolCalls {
+			if fn, _ := tc["function"].(map[string]interface{}); fn["name"] == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// registerSessionTools adds clear_session, the tool form of /new.
+func (c *NanoCore) registerSessionTools() {
+	c.toolRegistry.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "clear_session",
+			Description: "Start a fresh conversation: forget the recent back-and-forth of this chat while keeping long-term memory (MEMORY.md, entities) intact. Use when the user asks to start over, change topic completely, or clear the conversation.",
+			Parameters: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *tools.ToolResult {
+		chatID, _ := ctx.Value(ctxChatID).(string)
+		if chatID == "" || chatID == "internal_memory" {
+			return &tools.ToolResult{ForLLM: "Error: clear_session only works in a user chat."}
+		}
+		threadID, _ := ctx.Value(ctxThreadID).(int)
+		if err := c.ClearSession(sessionKey(chatID, threadID)); err != nil {
+			return &tools.ToolResult{ForLLM: fmt.Sprintf("Error: %v", err)}
+		}
+		return &tools.ToolResult{ForLLM: "Conversation cleared. Long-term memory is untouched. Confirm briefly to the user."}
+	})
+	c.toolRegistry.SetToolGroup("memory", "clear_session")
+	c.toolRegistry.SetToolGroupKeywords("memory", "fresh", "over", "clear", "reset", "conversation")
+}
@@ -0,0 +1,85 @@
+package agent
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// SpillDir is the workspace folder holding tool outputs too large for the context.
+	SpillDir = "tool_outputs"
+
+	// spillHeadChars and spillTailChars size the excerpt the LLM sees of a spilled output.
+	spillHeadChars = 2000
+	spillTailChars = 800
+
+	// spillMaxAge is how long spilled outputs are kept.
+	spillMaxAge = 7 * 24 * time.Hour
+)
+
+// fitToolResult returns a tool result small enough for the messages array.
+// Results over MaxToolResultChars are written in full to SpillDir, and the LLM
+// gets their head and tail plus the path to read the rest from. read_file
+// results are not copied again since the file is already in the workspace.
+func (c *NanoCore) fitToolResult(toolName string, args map[string]interface{}, result string) string {
+	if len(result) <= MaxToolResultChars {
+		return result
+	}
+
+	var note string
+	if path, _ := args["path"].(string); toolName == "read_file" && path != "" {
+		note = fmt.Sprintf("[%s is %d chars; the middle is omitted. Read it in parts with read_file start_line/max_lines.]", path, len(result))
+	} else {
+		rel, err := c.spillToolResult(toolName, result)
+		if err != nil {
+			slog.Warn("could not save the full tool output", "tool", toolName, "err", err)
+			return TruncateToolResult(result)
+		}
+		note = fmt.Sprintf("[Output was %d chars; the middle is omitted. Full output saved to %s. Read it in parts with read_file start_line/max_lines, or search it with exec grep.]", len(result), rel)
+	}
+
+	head := strings.ToValidUTF8(result[:spillHeadChars], "")
+	tail := strings.ToValidUTF8(result[len(result)-spillTailChars:], "")
+	return note + "\n" + head + "\n...\n" + tail
+}
+
+// spillToolResult writes output to a new file under SpillDir, pruning old
+// ones, and returns its workspace-relative path.
+func (c *NanoCore) spillToolResult(toolName, output string) (string, error) {
+	dir := filepath.Join(c.workspace, SpillDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	pruneSpilled(dir, time.Now().Add(-spillMaxAge))
+
+	f, err := os.CreateTemp(dir, time.Now().Format("20060102-150405")+"_"+toolName+"_*.txt")
+	if err != nil {
+		return "", err
+	}
+	_, err = f.WriteString(output)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return filepath.Join(SpillDir, filepath.Base(f.Name())), nil
+}
+
+// pruneSpilled removes spilled outputs last written before cutoff.
+func pruneSpilled(dir string, cutoff time.Time) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if info, err := e.Info(); err == nil && !e.IsDir() && info.ModTime().Before(cutoff) {
+			os.Remove(filepath.Join(dir, e.Name()))
+		}
+	}
+}
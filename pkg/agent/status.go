@@ -0,0 +1,74 @@
+package agent
+
+import (
+	"sort"
+	"time"
+
+	"littleclaw/pkg/events"
+)
+
+// Status is a snapshot of the agent's state for `littleclaw status`.
+type Status struct {
+	Provider       string    `json:"provider"`
+	Model          string    `json:"model"`
+	ActiveRuns     int       `json:"active_runs"`     // agent runs, sub-agents, and background commands in flight
+	BackgroundRuns int       `json:"background_runs"` // running sub-agents and background commands
+	CronJobs       int       `json:"cron_jobs"`
+	CronPaused     int       `json:"cron_paused"`
+	NextCron       string    `json:"next_cron,omitempty"` // label of the next job to run
+	NextCronAt     time.Time `json:"next_cron_at,omitempty"`
+
+	LastProviderError   string    `json:"last_provider_error,omitempty"`
+	LastProviderErrorAt time.Time `json:"last_provider_error_at,omitempty"`
+}
+
+// providerError is the most recent failed LLM call.
+type providerError struct {
+	msg string
+	at  time.Time
+}
+
+// noteProviderError records a failed LLM call for Status and publishes it
+// (admin alerts subscribe to catch auth failures).
+func (c *NanoCore) noteProviderError(err error) {
+	c.statusMu.Lock()
+	c.lastProviderErr = providerError{msg: err.Error(), at: time.Now()}
+	c.statusMu.Unlock()
+	provider, model := c.chatModel()
+	c.events.Publish(events.ProviderError{Provider: provider.Name(), Model: model, Err: err})
+}
+
+// Status returns a snapshot of the agent's state.
+func (c *NanoCore) Status() Status {
+	c.modelMu.RLock()
+	s := Status{Provider: c.providerType, Model: c.modelName}
+	c.modelMu.RUnlock()
+
+	s.ActiveRuns = c.runs.count()
+	for _, r := range c.background.list("") {
+		if r.FinishedAt.IsZero() {
+			s.BackgroundRuns++
+		}
+	}
+
+	jobs := c.cronService.ListJobs()
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].State.NextRunAtMs < jobs[j].State.NextRunAtMs })
+	for _, j := range jobs {
+		s.CronJobs++
+		if !j.IsEnabled() {
+			s.CronPaused++
+			continue
+		}
+		if s.NextCron == "" && j.State.NextRunAtMs > 0 {
+			s.NextCron, s.NextCronAt = j.Label, time.UnixMilli(j.State.NextRunAtMs)
+			if s.NextCron == "" {
+				s.NextCron = j.ID
+			}
+		}
+	}
+
+	c.statusMu.Lock()
+	s.LastProviderError, s.LastProviderErrorAt = c.lastProviderErr.msg, c.lastProviderErr.at
+	c.statusMu.Unlock()
+	return s
+}
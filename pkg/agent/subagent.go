@@ -0,0 +1,408 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/providers"
+	"littleclaw/pkg/tools"
+)
+
+const (
+	// DefaultSubAgentIterations is a sub-agent's tool-call budget when spawn does not set one.
+	DefaultSubAgentIterations = 8
+	// maxSubAgentIterations caps the budget spawn may ask for.
+	maxSubAgentIterations = 20
+	// MaxConcurrentSubAgents limits how many sub-agents run at once.
+	MaxConcurrentSubAgents = 3
+	// subAgentTimeout bounds a sub-agent's total run time.
+	subAgentTimeout = 10 * time.Minute
+	// maxFinishedSubAgents is how many completed runs list_subagents remembers.
+	maxFinishedSubAgents = 10
+
+	subAgentSystemPrompt = `You are a sub-agent of Littleclaw, working on ONE delegated task in the user's workspace.
+You cannot talk to the user or ask questions: make reasonable assumptions and finish the task with the tools you have.
+Stay within the task. Do not write to memory files.
+When done, reply with a concise report for the main agent: what you did, what you found (facts, numbers, file paths), and anything that failed.`
+)
+
+// subAgentDeniedTools are never available to sub-agents: no recursion, and no
+// lasting changes to memory, schedules, subscriptions, persona, or skills.
+var subAgentDeniedTools = map[string]bool{
+	"spawn":              true,
+	"list_subagents":     true,
+	"cancel_run":         true,
+	"delegate":           true,
+	"update_core_memory": true,
+	"append_core_memory": true,
+	"write_entity":       true,
+	"write_summary":      true,
+	"add_cron":           true,
+	"remove_cron":        true,
+	"set_persona":        true,
+	"subscribe_feed":     true,
+	"unsubscribe_feed":   true,
+	"watch_path":         true,
+	"unwatch_path":       true,
+	"create_skill":       true,
+	"install_skill_pack": true,
+	"reload_skills":      true,
+}
+
+// subAgentRun is one spawned sub-agent.
+type subAgentRun struct {
+	ID         string
+	Task       string
+	ChatID     string
+	Channel    string
+	Status     string // running, done, incomplete, failed, stopped
+	Iterations int
+	Requester  string // sender whose message started it, for the exec audit log
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+// subAgentManager tracks running and recently finished sub-agents.
+type subAgentManager struct {
+	mu       sync.Mutex
+	seq      int
+	running  map[string]*subAgentRun
+	finished []*subAgentRun // newest last
+	wg       sync.WaitGroup
+}
+
+func newSubAgentManager() *subAgentManager {
+	return &subAgentManager{running: make(map[string]*subAgentRun)}
+}
+
+// start registers a new run, or fails when MaxConcurrentSubAgents are busy.
+func (m *subAgentManager) start(task, chatID, channel string) (*subAgentRun, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.running) >= MaxConcurrentSubAgents {
+		return nil, fmt.Errorf("%d sub-agents are already running; wait for one to finish", len(m.running))
+	}
+	m.seq++
+	run := &subAgentRun{
+		ID:        fmt.Sprintf("sa-%d", m.seq),
+		Task:      task,
+		ChatID:    chatID,
+		Channel:   channel,
+		Status:    "running",
+		StartedAt: time.Now(),
+	}
+	m.running[run.ID] = run
+	m.wg.Add(1)
+	return run, nil
+}
+
+// finish moves a run to the finished list.
+func (m *subAgentManager) finish(run *subAgentRun, status string, iterations int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	run.Status = status
+	run.Iterations = iterations
+	run.FinishedAt = time.Now()
+	delete(m.running, run.ID)
+	m.finished = append(m.finished, run)
+	if len(m.finished) > maxFinishedSubAgents {
+		m.finished = m.finished[len(m.finished)-maxFinishedSubAgents:]
+	}
+	m.wg.Done()
+}
+
+// list returns the sub-agents spawned from chatID ("" for any chat), the
+// running ones first in the order they were spawned.
+func (m *subAgentManager) list(chatID string) []subAgentRun {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []subAgentRun
+	for _, r := range m.running {
+		if chatID == "" || r.ChatID == chatID {
+			out = append(out, *r)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].StartedAt.Before(out[j].StartedAt) })
+	for _, r := range m.finished {
+		if chatID == "" || r.ChatID == chatID {
+			out = append(out, *r)
+		}
+	}
+	return out
+}
+
+// WaitSubAgents blocks until every running sub-agent has reported back.
+func (c *NanoCore) WaitSubAgents() {
+	c.subAgents.wg.Wait()
+}
+
+// subAgentToolDefs returns the definitions a sub-agent may use: every
+// registered tool not in subAgentDeniedTools, narrowed to only if non-empty.
+func (c *NanoCore) subAgentToolDefs(only []string) []providers.ToolDefinition {
+	want := make(map[string]bool, len(only))
+	for _, n := range only {
+		want[n] = true
+	}
+	var defs []providers.ToolDefinition
+	for _, def := range c.toolRegistry.GetDefinitions() {
+		name := def.Function.Name
+		if subAgentDeniedTools[name] || (len(only) > 0 && !want[name]) {
+			continue
+		}
+		defs = append(defs, def)
+	}
+	return defs
+}
+
+// subAgentSpec is what a sub-agent runs with: spawn uses the chat provider
+// and the default prompt, delegate a configured role's settings.
+type subAgentSpec struct {
+	provider      providers.Provider
+	model         string
+	systemPrompt  string
+	defs          []providers.ToolDefinition
+	maxIterations int
+}
+
+// runSubAgent works on run.Task in the background and delivers a completion
+// report to the originating chat.
+func (c *NanoCore) runSubAgent(run *subAgentRun, spec subAgentSpec) {
+	defer c.recoverPanic("sub-agent")
+	ctx, cancel := context.WithTimeout(context.Background(), subAgentTimeout)
+	defer cancel()
+	ctx = context.WithValue(ctx, ctxChatID, run.ChatID)
+	ctx = context.WithValue(ctx, ctxChannel, run.Channel)
+	ctx = tools.WithCaller(ctx, run.ChatID, run.Channel)
+	ctx = tools.WithRequester(ctx, run.Requester)
+	runID := c.runs.add(run.ChatID, &activeRun{cancel: cancel, channel: run.Channel})
+	defer c.runs.remove(run.ChatID, runID)
+	// Listed by list_background_runs and cancellable with cancel_run; the
+	// sub-agent limit is enforced by subAgents.start
+	c.background.track(run.ID, "subagent", run.Task, run.ChatID, run.Channel, cancel, 0)
+
+	report, status, iterations, files, tempFiles := c.subAgentLoop(ctx, spec, run.Task)
+
+	// Deliver before finishing so WaitSubAgents returns only once the report is out
+	c.deliverSubAgentReport(run, status, report, files, tempFiles)
+	c.subAgents.finish(run, status, iterations)
+	c.background.finish(run.ID, status)
+	slog.Info("sub-agent finished", "run_id", run.ID, "status", status, "iterations", iterations)
+}
+
+// subAgentLoop works on task in its own messages array with the spec's tool
+// set and iteration budget, and returns the final report and its status
+// (done, incomplete, failed, stopped). Tool output meant for the user is not
+// sent directly; files (and the temporary ones among them) are collected for
+// the caller instead.
+func (c *NanoCore) subAgentLoop(ctx context.Context, spec subAgentSpec, task string) (report, status string, iteration int, files, tempFiles []string) {
+	allowed := make(map[string]bool, len(spec.defs))
+	for _, def := range spec.defs {
+		allowed[def.Function.Name] = true
+	}
+	chatID, _ := ctx.Value(ctxChatID).(string)
+	params := c.paramsFor(chatID)
+	messages := []providers.Message{
+		{Role: "system", Content: spec.systemPrompt},
+		{Role: "user", Content: task},
+	}
+
+	status = "incomplete"
+	for iteration < spec.maxIterations {
+		iteration++
+		resp, err := spec.provider.Chat(ctx, providers.ChatRequest{
+			Model:       spec.model,
+			Messages:    messages,
+			Tools:       spec.defs,
+			Temperature: params.temperature,
+			MaxTokens:   params.maxTokens,
+		})
+		if errors.Is(ctx.Err(), context.Canceled) {
+			report, status = "Stopped by the user.", "stopped"
+			break
+		}
+		if err != nil {
+			c.noteProviderError(err)
+			report, status = fmt.Sprintf("Failed: %v", err), "failed"
+			break
+		}
+		if len(resp.ToolCalls) == 0 {
+			report, status = strings.TrimSpace(resp.Content), "done"
+			break
+		}
+
+		messages = append(messages, providers.Message{Role: "assistant", Content: resp.Content, ToolCalls: resp.ToolCalls})
+		for _, tc := range resp.ToolCalls {
+			fn, _ := tc["function"].(map[string]interface{})
+			name, _ := fn["name"].(string)
+			argsStr, _ := fn["arguments"].(string)
+			id, _ := tc["id"].(string)
+
+			var result *tools.ToolResult
+			var args map[string]interface{}
+			if !allowed[name] {
+				result = &tools.ToolResult{ForLLM: fmt.Sprintf("Error: tool %s is not available to sub-agents", name)}
+			} else {
+				_ = json.Unmarshal([]byte(argsStr), &args)
+				result = c.toolRegistry.Execute(ctx, name, args)
+			}
+			files = append(files, result.Files...)
+			tempFiles = append(tempFiles, result.TempFiles...)
+			messages = append(messages, providers.Message{Role: "tool", Content: c.fitToolResult(name, args, result.ForLLM), ToolCallID: id})
+		}
+	}
+	if status == "incomplete" {
+		report = fmt.Sprintf("Stopped after %d iterations without a final report.", spec.maxIterations)
+	}
+	if report == "" {
+		report = "(no report)"
+	}
+	return report, status, iteration, files, tempFiles
+}
+
+// deliverSubAgentReport sends the report to the originating chat and records
+// it in the chat history and session so the main agent can build on it.
+func (c *NanoCore) deliverSubAgentReport(run *subAgentRun, status, report string, files, tempFiles []string) {
+	content := fmt.Sprintf("🤖 Sub-agent %s %s: %s\n\n%s", run.ID, status, truncateLabel(run.Task, 80), report)
+	if run.ChatID == "" {
+		c.memoryStore.AppendInternal("ASSISTANT", content)
+		bus.OutboundMessage{TempFiles: tempFiles}.RemoveTempFiles()
+		return
+	}
+	c.msgBus.SendOutbound(bus.OutboundMessage{
+		Channel:   run.Channel,
+		ChatID:    run.ChatID,
+		Content:   content,
+		Files:     files,
+		TempFiles: tempFiles,
+	})
+	c.memoryStore.AppendHistory("ASSISTANT", content)
+	c.sessions.appendMessage(run.ChatID, providers.Message{Role: "assistant", Content: content})
+}
+
+// truncateLabel shortens s to n runes for one-line display.
+func truncateLabel(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > n {
+		return string(r[:n]) + "…"
+	}
+	return s
+}
+
+// registerSubAgentTools adds spawn and list_subagents.
+func (c *NanoCore) registerSubAgentTools() {
+	c.toolRegistry.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "spawn",
+			Description: "Starts a sub-agent that works on a self-contained task in the background with its own context and tool budget, then reports back to this chat. Use it for long research or multi-step jobs that should not block the conversation. Sub-agents cannot ask the user questions, spawn further sub-agents, or change memory, cron jobs, feeds, watches, persona, or skills.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"task": map[string]interface{}{
+						"type":        "string",
+						"description": "Complete instructions for the sub-agent, including all context it needs (it does not see this conversation).",
+					},
+					"tools": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Optional: restrict the sub-agent to these tool names.",
+					},
+					"max_iterations": map[string]interface{}{
+						"type":        "integer",
+						"description": fmt.Sprintf("Tool-call rounds the sub-agent may use (default %d, max %d).", DefaultSubAgentIterations, maxSubAgentIterations),
+					},
+				},
+				"required": []string{"task"},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *tools.ToolResult {
+		task, _ := args["task"].(string)
+		task = strings.TrimSpace(task)
+		if task == "" {
+			return &tools.ToolResult{ForLLM: "Error: task is required"}
+		}
+
+		var only []string
+		if raw, ok := args["tools"].([]interface{}); ok {
+			for _, v := range raw {
+				if s, ok := v.(string); ok && s != "" {
+					only = append(only, s)
+				}
+			}
+		}
+		defs := c.subAgentToolDefs(only)
+		if len(only) > 0 && len(defs) == 0 {
+			return &tools.ToolResult{ForLLM: "Error: none of the requested tools are available to sub-agents"}
+		}
+
+		maxIterations := DefaultSubAgentIterations
+		if n, ok := args["max_iterations"].(float64); ok && n > 0 {
+			maxIterations = int(n)
+		}
+		if maxIterations > maxSubAgentIterations {
+			maxIterations = maxSubAgentIterations
+		}
+
+		chatID, channel := c.replyTarget(ctx)
+		run, err := c.subAgents.start(task, chatID, channel)
+		if err != nil {
+			return &tools.ToolResult{ForLLM: fmt.Sprintf("Error: %v", err)}
+		}
+		run.Requester = tools.Requester(ctx)
+		provider, model := c.chatModel()
+		go c.runSubAgent(run, subAgentSpec{
+			provider:      provider,
+			model:         model,
+			systemPrompt:  subAgentSystemPrompt,
+			defs:          defs,
+			maxIterations: maxIterations,
+		})
+
+		return &tools.ToolResult{ForLLM: fmt.Sprintf("Sub-agent %s started with %d tools and up to %d iterations. Its report will be delivered to the user's chat when it finishes; tell the user it is working on it.", run.ID, len(defs), maxIterations)}
+	})
+
+	c.toolRegistry.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "list_subagents",
+			Description: "Lists this chat's running sub-agents and the most recently finished ones with their status.",
+			Parameters: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *tools.ToolResult {
+		runs := c.subAgents.list(tools.CallerChat(ctx))
+		if len(runs) == 0 {
+			return &tools.ToolResult{ForLLM: "No sub-agents have been spawned."}
+		}
+		var sb strings.Builder
+		for _, r := range runs {
+			sb.WriteString(fmt.Sprintf("- %s [%s] %s (started %s", r.ID, r.Status, truncateLabel(r.Task, 80), r.StartedAt.Format("15:04:05")))
+			if !r.FinishedAt.IsZero() {
+				sb.WriteString(fmt.Sprintf(", %d iteration(s), took %s", r.Iterations, r.FinishedAt.Sub(r.StartedAt).Round(time.Second)))
+			}
+			sb.WriteString(")\n")
+		}
+		return &tools.ToolResult{ForLLM: sb.String()}
+	})
+
+	c.toolRegistry.SetToolGroup("subagents", "spawn", "list_subagents")
+	c.toolRegistry.SetToolGroupKeywords("subagents", "spawn", "subagent", "sub-agent", "agent", "background", "delegate", "parallel", "research", "investigate")
+}
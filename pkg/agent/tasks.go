@@ -0,0 +1,376 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"littleclaw/pkg/providers"
+	"littleclaw/pkg/tools"
+)
+
+// maxDoneTasks bounds how many completed tasks TASKS.json keeps.
+const maxDoneTasks = 100
+
+// Task priorities, highest first.
+var taskPriorities = []string{"high", "normal", "low"}
+
+// Task is one tracked goal or to-do persisted in TASKS.json.
+type Task struct {
+	ID          int    `json:"id"`
+	Title       string `json:"title"`
+	Notes       string `json:"notes,omitempty"`
+	Due         string `json:"due,omitempty"` // YYYY-MM-DD
+	Priority    string `json:"priority"`
+	Done        bool   `json:"done"`
+	CreatedAtMs int64  `json:"createdAtMs"`
+	DoneAtMs    int64  `json:"doneAtMs,omitempty"`
+}
+
+// overdue reports whether an open task's due date is before today.
+func (t *Task) overdue(now time.Time) bool {
+	return !t.Done && t.Due != "" && t.Due < now.Format("2006-01-02")
+}
+
+// String renders the task as one line, e.g. "#3 [high] File taxes (due 2026-04-15, overdue)".
+func (t *Task) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("#%d ", t.ID))
+	if t.Priority != "normal" {
+		sb.WriteString("[" + t.Priority + "] ")
+	}
+	sb.WriteString(t.Title)
+	switch {
+	case t.Done:
+		sb.WriteString(fmt.Sprintf(" (done %s)", time.UnixMilli(t.DoneAtMs).Format("2006-01-02")))
+	case t.overdue(time.Now()):
+		sb.WriteString(fmt.Sprintf(" (due %s, overdue)", t.Due))
+	case t.Due != "":
+		sb.WriteString(fmt.Sprintf(" (due %s)", t.Due))
+	}
+	return sb.String()
+}
+
+// TaskStore keeps the user's tasks in $workspace/TASKS.json.
+type TaskStore struct {
+	mu       sync.Mutex
+	tasks    []*Task
+	dataFile string
+}
+
+// NewTaskStore creates a TaskStore backed by $workspace/TASKS.json.
+func NewTaskStore(workspaceDir string) *TaskStore {
+	ts := &TaskStore{dataFile: filepath.Join(workspaceDir, "TASKS.json")}
+	if err := ts.load(); err != nil && !os.IsNotExist(err) {
+		slog.Warn("task store: failed to load tasks", "err", err)
+	}
+	return ts
+}
+
+// Add creates an open task. due is YYYY-MM-DD, "today", or "tomorrow";
+// priority is high, normal (default), or low.
+func (ts *TaskStore) Add(title, notes, due, priority string) (*Task, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil, fmt.Errorf("title is required")
+	}
+	due, err := parseTaskDue(due, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	priority = strings.ToLower(strings.TrimSpace(priority))
+	if priority == "" {
+		priority = "normal"
+	}
+	if taskPriorityRank(priority) < 0 {
+		return nil, fmt.Errorf("unknown priority %q (use high, normal, or low)", priority)
+	}
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	id := 1
+	for _, t := range ts.tasks {
+		id = max(id, t.ID+1)
+	}
+	task := &Task{
+		ID:          id,
+		Title:       title,
+		Notes:       strings.TrimSpace(notes),
+		Due:         due,
+		Priority:    priority,
+		CreatedAtMs: time.Now().UnixMilli(),
+	}
+	ts.tasks = append(ts.tasks, task)
+	return task, ts.save()
+}
+
+// Complete marks an open task done. key is its ID ("3" or "#3") or a
+// case-insensitive part of its title that matches exactly one open task.
+func (ts *TaskStore) Complete(key string) (*Task, error) {
+	key = strings.TrimSpace(key)
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	var match *Task
+	if id, err := strconv.Atoi(strings.TrimPrefix(key, "#")); err == nil {
+		for _, t := range ts.tasks {
+			if t.ID == id {
+				match = t
+			}
+		}
+	} else if key != "" {
+		for _, t := range ts.tasks {
+			if t.Done || !strings.Contains(strings.ToLower(t.Title), strings.ToLower(key)) {
+				continue
+			}
+			if match != nil {
+				return nil, fmt.Errorf("%q matches more than one open task (#%d, #%d); use the ID", key, match.ID, t.ID)
+			}
+			match = t
+		}
+	}
+	if match == nil {
+		return nil, fmt.Errorf("no task matching %q", key)
+	}
+	if match.Done {
+		return nil, fmt.Errorf("task #%d is already done", match.ID)
+	}
+	match.Done = true
+	match.DoneAtMs = time.Now().UnixMilli()
+	return match, ts.save()
+}
+
+// List returns copies of the tasks: open ones first by priority, due date,
+// and ID, then (with includeDone) completed ones, most recent first.
+func (ts *TaskStore) List(includeDone bool) []Task {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	var out []Task
+	for _, t := range ts.tasks {
+		if includeDone || !t.Done {
+			out = append(out, *t)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Done != b.Done {
+			return !a.Done
+		}
+		if a.Done {
+			return a.DoneAtMs > b.DoneAtMs
+		}
+		if ra, rb := taskPriorityRank(a.Priority), taskPriorityRank(b.Priority); ra != rb {
+			return ra < rb
+		}
+		if a.Due != b.Due {
+			return b.Due == "" || (a.Due != "" && a.Due < b.Due)
+		}
+		return a.ID < b.ID
+	})
+	return out
+}
+
+func (ts *TaskStore) load() error {
+	data, err := os.ReadFile(ts.dataFile)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &ts.tasks)
+}
+
+// save persists tasks, keeping only the newest maxDoneTasks completed ones;
+// callers must hold ts.mu.
+func (ts *TaskStore) save() error {
+	var done []*Task
+	for _, t := range ts.tasks {
+		if t.Done {
+			done = append(done, t)
+		}
+	}
+	if len(done) > maxDoneTasks {
+		sort.Slice(done, func(i, j int) bool { return done[i].DoneAtMs > done[j].DoneAtMs })
+		drop := make(map[*Task]bool)
+		for _, t := range done[maxDoneTasks:] {
+			drop[t] = true
+		}
+		kept := ts.tasks[:0]
+		for _, t := range ts.tasks {
+			if !drop[t] {
+				kept = append(kept, t)
+			}
+		}
+		ts.tasks = kept
+	}
+	data, err := json.MarshalIndent(ts.tasks, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(ts.dataFile, data, 0644)
+}
+
+// parseTaskDue normalizes a due date to YYYY-MM-DD.
+func parseTaskDue(due string, now time.Time) (string, error) {
+	switch d := strings.ToLower(strings.TrimSpace(due)); d {
+	case "":
+		return "", nil
+	case "today":
+		return now.Format("2006-01-02"), nil
+	case "tomorrow":
+		return now.AddDate(0, 0, 1).Format("2006-01-02"), nil
+	default:
+		t, err := time.Parse("2006-01-02", d)
+		if err != nil {
+			return "", fmt.Errorf("invalid due date %q (use YYYY-MM-DD)", due)
+		}
+		return t.Format("2006-01-02"), nil
+	}
+}
+
+// taskPriorityRank returns the sort rank of a priority, or -1 if unknown.
+func taskPriorityRank(p string) int {
+	for i, name := range taskPriorities {
+		if p == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// buildTaskSummary lists open tasks for the system prompt.
+func (c *NanoCore) buildTaskSummary() string {
+	var sb strings.Builder
+	for _, t := range c.taskStore.List(false) {
+		sb.WriteString("- " + t.String() + "\n")
+	}
+	return sb.String()
+}
+
+// registerTaskTools adds add_task, complete_task, and list_tasks.
+func (c *NanoCore) registerTaskTools() {
+	// add_task
+	c.toolRegistry.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "add_task",
+			Description: "Adds a task or goal to the user's task list (TASKS.json). Use it whenever the user mentions something they need to do or want tracked. Open tasks are shown in your system prompt in every session.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"title": map[string]interface{}{
+						"type":        "string",
+						"description": "Short description of the task.",
+					},
+					"notes": map[string]interface{}{
+						"type":        "string",
+						"description": "Optional details.",
+					},
+					"due": map[string]interface{}{
+						"type":        "string",
+						"description": "Optional due date: YYYY-MM-DD, 'today', or 'tomorrow'.",
+					},
+					"priority": map[string]interface{}{
+						"type":        "string",
+						"enum":        taskPriorities,
+						"description": "Optional priority (default normal).",
+					},
+				},
+				"required": []string{"title"},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *tools.ToolResult {
+		title, _ := args["title"].(string)
+		notes, _ := args["notes"].(string)
+		due, _ := args["due"].(string)
+		priority, _ := args["priority"].(string)
+		task, err := c.taskStore.Add(title, notes, due, priority)
+		if err != nil {
+			return &tools.ToolResult{ForLLM: fmt.Sprintf("Error: %v", err)}
+		}
+		return &tools.ToolResult{ForLLM: fmt.Sprintf("Added task %s.", task)}
+	})
+
+	// complete_task
+	c.toolRegistry.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "complete_task",
+			Description: "Marks a task as done. Accepts the task ID (e.g. 3 or #3) or a unique part of its title.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"task": map[string]interface{}{
+						"type":        "string",
+						"description": "Task ID or part of its title.",
+					},
+				},
+				"required": []string{"task"},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *tools.ToolResult {
+		key := fmt.Sprint(args["task"])
+		if f, ok := args["task"].(float64); ok {
+			key = strconv.Itoa(int(f))
+		}
+		task, err := c.taskStore.Complete(key)
+		if err != nil {
+			return &tools.ToolResult{ForLLM: fmt.Sprintf("Error: %v", err)}
+		}
+		return &tools.ToolResult{ForLLM: fmt.Sprintf("Completed task #%d: %s.", task.ID, task.Title)}
+	})
+
+	// list_tasks
+	c.toolRegistry.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "list_tasks",
+			Description: "Lists the user's open tasks with IDs, priorities, due dates, and notes; optionally also recently completed ones.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"include_done": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Also list completed tasks (default false).",
+					},
+				},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *tools.ToolResult {
+		includeDone, _ := args["include_done"].(bool)
+		tasks := c.taskStore.List(includeDone)
+		if len(tasks) == 0 {
+			return &tools.ToolResult{ForLLM: "No tasks."}
+		}
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("%d task(s):\n", len(tasks)))
+		for _, t := range tasks {
+			sb.WriteString("- " + t.String() + "\n")
+			if t.Notes != "" {
+				sb.WriteString("  Notes: " + t.Notes + "\n")
+			}
+		}
+		return &tools.ToolResult{ForLLM: sb.String()}
+	})
+
+	c.toolRegistry.SetToolGroup("tasks", "add_task", "complete_task", "list_tasks")
+	c.toolRegistry.SetToolGroupKeywords("tasks", "task", "tasks", "todo", "to-do", "goal", "goals", "done", "finished", "complete", "pending", "need")
+}
@@ -0,0 +1,131 @@
+package agent_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"littleclaw/pkg/agent"
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/providers"
+)
+
+// authFailingProvider fails every call as a provider with a bad API key does.
+type authFailingProvider struct{}
+
+func (authFailingProvider) Chat(ctx context.Context, req providers.ChatRequest) (*providers.ChatResponse, error) {
+	return nil, errors.New(`API error 401: {"error": "invalid api key"}`)
+}
+
+func (authFailingProvider) Name() string { return "openai" }
+
+func (authFailingProvider) ListModels(ctx context.Context) ([]providers.ModelInfo, error) {
+	return nil, nil
+}
+
+// panickingProvider panics on every call.
+type panickingProvider struct{}
+
+func (panickingProvider) Chat(ctx context.Context, req providers.ChatRequest) (*providers.ChatResponse, error) {
+	panic("nil map write")
+}
+
+func (panickingProvider) Name() string { return "panicking" }
+
+func (panickingProvider) ListModels(ctx context.Context) ([]providers.ModelInfo, error) {
+	return nil, nil
+}
+
+// adminMessages returns the outbound messages sent to the admin chat.
+func adminMessages(msgBus *bus.MessageBus) []string {
+	var out []string
+	for _, m := range drainOutbound(msgBus) {
+		if m.ChatID == "admin" {
+			out = append(out, m.Content)
+		}
+	}
+	return out
+}
+
+func TestAdminAlerts_ProviderAuthFailure(t *testing.T) {
+	nc, msgBus := newTestAgent(t, authFailingProvider{})
+	nc.EnableAdminAlerts(agent.AdminAlertPolicy{ChatID: "admin"})
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "hi"})
+	alerts := adminMessages(msgBus)
+	if len(alerts) != 1 || !strings.Contains(alerts[0], "openai provider rejected the API key") {
+		t.Fatalf("expected one auth alert, got %q", alerts)
+	}
+
+	// The same alert is not repeated within MinGap
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "hi again"})
+	if alerts := adminMessages(msgBus); len(alerts) != 0 {
+		t.Errorf("expected no repeated alert, got %q", alerts)
+	}
+}
+
+func TestAdminAlerts_OtherProviderErrorsIgnored(t *testing.T) {
+	nc, msgBus := newTestAgent(t, failingProvider{})
+	nc.EnableAdminAlerts(agent.AdminAlertPolicy{ChatID: "admin"})
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "hi"})
+	if alerts := adminMessages(msgBus); len(alerts) != 0 {
+		t.Errorf("a 503 is not an auth failure, got %q", alerts)
+	}
+}
+
+func TestAdminAlerts_RecoveredPanic(t *testing.T) {
+	nc, msgBus := newTestAgent(t, panickingProvider{})
+	nc.EnableAdminAlerts(agent.AdminAlertPolicy{ChatID: "admin"})
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "hi"})
+	alerts := adminMessages(msgBus)
+	if len(alerts) != 1 || !strings.Contains(alerts[0], "panic in agent run: nil map write") {
+		t.Errorf("expected a panic alert, got %q", alerts)
+	}
+}
+
+func TestAdminAlerts_RepeatedCronFailures(t *testing.T) {
+	cs, _ := newTestCronServiceWithBus(t)
+	var alerts []string
+	cs.SetAlertFunc(func(kind, key, text string) {
+		alerts = append(alerts, kind+" "+key+": "+text)
+	})
+	// Silent jobs never tell the user about failures
+	job := &agent.CronJob{ID: "sync", Schedule: "@every 1h", Command: "exit 1", Label: "sync", ChatID: "user123", Channel: "telegram", Silent: true}
+	if err := cs.AddJob(job); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 4; i++ {
+		if err := cs.RunJobNow("sync"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(alerts) != 1 || !strings.HasPrefix(alerts[0], "cron sync: ") || !strings.Contains(alerts[0], "failed 3 runs in a row") {
+		t.Errorf("expected one alert at the third failure, got %q", alerts)
+	}
+}
+
+func TestAdminAlerts_DeliveryFailure(t *testing.T) {
+	nc, msgBus := newTestAgent(t, &mockProvider{})
+	nc.EnableAdminAlerts(agent.AdminAlertPolicy{ChatID: "admin"})
+
+	nc.NoteDeliveryFailure(bus.OutboundMessage{Channel: "telegram", ChatID: "42", Content: "your backup finished"}, errors.New("Forbidden: bot was blocked by the user"))
+	alerts := adminMessages(msgBus)
+	if len(alerts) != 1 || !strings.Contains(alerts[0], "telegram chat 42 could not be delivered") {
+		t.Fatalf("expected one delivery alert, got %q", alerts)
+	}
+	data, err := os.ReadFile(nc.MemoryStore().InternalFile())
+	if err != nil || !strings.Contains(string(data), "your backup finished") {
+		t.Errorf("expected the failure in INTERNAL.md, got %q (err %v)", data, err)
+	}
+
+	// A message the admin chat itself could not get is only logged
+	nc.NoteDeliveryFailure(bus.OutboundMessage{Channel: "telegram", ChatID: "admin", Content: "🚨 Admin alert: ..."}, errors.New("chat not found"))
+	if alerts := adminMessages(msgBus); len(alerts) != 0 {
+		t.Errorf("expected no alert about the admin chat, got %q", alerts)
+	}
+}
@@ -0,0 +1,220 @@
+package agent_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"littleclaw/pkg/agent"
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/providers"
+	"littleclaw/pkg/tools"
+)
+
+// ---------------------------------------------------------------------------
+// Approval mode tests
+// ---------------------------------------------------------------------------
+
+func TestApproval_PromptsUserAndRunsOnApprove(t *testing.T) {
+	provider := &mockProvider{
+		responses: []providers.ChatResponse{
+			{ToolCalls: []map[string]interface{}{{
+				"id": "call_1",
+				"function": map[string]interface{}{
+					"name":      "exec",
+					"arguments": `{"command": "rm -f nothing.txt; echo removed"}`,
+				},
+			}}},
+			{Content: "Done."},
+		},
+	}
+	nc, msgBus := newTestAgent(t, provider)
+	if err := nc.EnableApprovals(nil, time.Second); err != nil {
+		t.Fatalf("EnableApprovals: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		nc.RunAgentLoop(context.Background(), bus.InboundMessage{
+			ChatID:  "user123",
+			Channel: "telegram",
+			Content: "delete nothing.txt",
+		})
+		close(done)
+	}()
+
+	var prompt bus.OutboundMessage
+	select {
+	case prompt = <-msgBus.Outbound:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected an approval prompt on the outbound bus")
+	}
+	if prompt.ApprovalID == "" {
+		t.Fatalf("expected ApprovalID on prompt, got %+v", prompt)
+	}
+
+	if err := nc.ResolveApproval(bus.ApprovalDecision{ID: prompt.ApprovalID, Approved: true}); err != nil {
+		t.Fatalf("ResolveApproval on a pending request: %v", err)
+	}
+	<-done
+
+	if len(provider.requests) < 2 {
+		t.Fatalf("expected a second LLM call, got %d", len(provider.requests))
+	}
+	msgs := provider.requests[1].Messages
+	var toolResult string
+	for _, m := range msgs {
+		if m.Role == "tool" {
+			toolResult = m.Content
+		}
+	}
+	if !strings.Contains(toolResult, "removed") {
+		t.Errorf("expected approved command output in tool result, got %q", toolResult)
+	}
+}
+
+func TestApproval_InternalContextDenied(t *testing.T) {
+	provider := &mockProvider{
+		responses: []providers.ChatResponse{
+			{ToolCalls: []map[string]interface{}{{
+				"id": "call_1",
+				"function": map[string]interface{}{
+					"name":      "exec",
+					"arguments": `{"command": "rm -f x"}`,
+				},
+			}}},
+			{Content: "ok"},
+		},
+	}
+	nc, msgBus := newTestAgent(t, provider)
+	_ = nc.EnableApprovals(nil, time.Second)
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{
+		ChatID:  "internal_memory",
+		Channel: "internal",
+		Content: "cleanup",
+	})
+
+	for _, m := range drainOutbound(msgBus) {
+		if m.ApprovalID != "" {
+			t.Error("internal runs must not prompt for approval")
+		}
+	}
+}
+
+func TestResolveApproval_UnknownID(t *testing.T) {
+	nc, _ := newTestAgent(t, &mockProvider{})
+	_ = nc.EnableApprovals(nil, time.Second)
+
+	if err := nc.ResolveApproval(bus.ApprovalDecision{ID: "missing", Approved: true}); !errors.Is(err, bus.ErrApprovalNotPending) {
+		t.Errorf("ResolveApproval on an unknown ID = %v, want ErrApprovalNotPending", err)
+	}
+}
+
+func TestResolveApproval_OnlyRequesterOrAdmin(t *testing.T) {
+	provider := &mockProvider{
+		responses: []providers.ChatResponse{
+			{ToolCalls: []map[string]interface{}{{
+				"id": "call_1",
+				"function": map[string]interface{}{
+					"name":      "exec",
+					"arguments": `{"command": "rm -f nothing.txt"}`,
+				},
+			}}},
+			{Content: "Done."},
+		},
+	}
+	nc, msgBus := newTestAgent(t, provider)
+	nc.SetUserRoles(agent.UserRoles{Users: map[string]tools.UserRole{
+		"owner": tools.RoleAdmin, "cohost": tools.RoleAdmin, "guest": tools.RoleStandard,
+	}})
+	if err := nc.EnableApprovals(nil, 2*time.Second); err != nil {
+		t.Fatalf("EnableApprovals: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		nc.RunAgentLoop(context.Background(), bus.InboundMessage{
+			ChatID:   "group1",
+			Channel:  "telegram",
+			SenderID: "owner",
+			Content:  "delete nothing.txt",
+		})
+		close(done)
+	}()
+
+	var prompt bus.OutboundMessage
+	select {
+	case prompt = <-msgBus.Outbound:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected an approval prompt on the outbound bus")
+	}
+
+	if err := nc.ResolveApproval(bus.ApprovalDecision{ID: prompt.ApprovalID, Approved: true, SenderID: "guest"}); !errors.Is(err, bus.ErrNotApprover) {
+		t.Fatalf("a standard user answering the owner's prompt = %v, want ErrNotApprover", err)
+	}
+	// The refusal leaves the prompt open for another admin
+	if err := nc.ResolveApproval(bus.ApprovalDecision{ID: prompt.ApprovalID, Approved: false, SenderID: "cohost"}); err != nil {
+		t.Fatalf("an admin answering: %v", err)
+	}
+	<-done
+
+	var toolResult string
+	for _, m := range provider.requests[1].Messages {
+		if m.Role == "tool" {
+			toolResult = m.Content
+		}
+	}
+	if !strings.Contains(strings.ToLower(toolResult), "denied") {
+		t.Errorf("expected the admin's denial in the tool result, got %q", toolResult)
+	}
+}
+
+func TestApproval_RiskyCronCommandIsHeld(t *testing.T) {
+	provider := &mockProvider{
+		responses: []providers.ChatResponse{
+			{ToolCalls: []map[string]interface{}{{
+				"id": "call_1",
+				"function": map[string]interface{}{
+					"name":      "add_cron",
+					"arguments": `{"label": "cleanup", "schedule": "@every 1m", "command": "rm -f build.log"}`,
+				},
+			}}},
+			{Content: "Not scheduled."},
+		},
+	}
+	nc, msgBus := newTestAgent(t, provider)
+	if err := nc.EnableApprovals(nil, time.Second); err != nil {
+		t.Fatalf("EnableApprovals: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		nc.RunAgentLoop(context.Background(), bus.InboundMessage{
+			ChatID:  "user123",
+			Channel: "telegram",
+			Content: "delete build.log every minute",
+		})
+		close(done)
+	}()
+
+	var prompt bus.OutboundMessage
+	select {
+	case prompt = <-msgBus.Outbound:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the cron command to be held for approval")
+	}
+	if prompt.ApprovalID == "" || !strings.Contains(prompt.Content, "rm -f build.log") {
+		t.Fatalf("expected an approval prompt for the command, got %+v", prompt)
+	}
+	if err := nc.ResolveApproval(bus.ApprovalDecision{ID: prompt.ApprovalID, Approved: false}); err != nil {
+		t.Fatalf("ResolveApproval: %v", err)
+	}
+	<-done
+
+	if jobs := nc.CronService().ListJobs(); len(jobs) != 0 {
+		t.Errorf("a denied command was scheduled: %+v", jobs[0])
+	}
+}
@@ -0,0 +1,139 @@
+package agent_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/providers"
+)
+
+func backgroundToolCall(id, name, args string) providers.ChatResponse {
+	return providers.ChatResponse{ToolCalls: []map[string]interface{}{
+		{
+			"id": id,
+			"function": map[string]interface{}{
+				"name":      name,
+				"arguments": args,
+			},
+		},
+	}}
+}
+
+// lastToolResult returns the content of the last tool message in req.
+func lastToolResult(req providers.ChatRequest) string {
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role == "tool" {
+			return req.Messages[i].Content
+		}
+	}
+	return ""
+}
+
+func TestExecBackground_NotifiesOnCompletion(t *testing.T) {
+	provider := &mockProvider{responses: []providers.ChatResponse{
+		backgroundToolCall("call_1", "exec", `{"command": "sleep 0.2; echo build ok", "background": true}`),
+		{Content: "The build is running in the background."},
+	}}
+	nc, msgBus := newTestAgent(t, provider)
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "build it"})
+
+	if got := lastToolResult(provider.requests[1]); !strings.Contains(got, "run bg-1") {
+		t.Fatalf("expected the run ID in the tool result, got %q", got)
+	}
+
+	nc.WaitBackgroundRuns()
+	var notice string
+	for _, m := range drainOutbound(msgBus) {
+		if strings.Contains(m.Content, "Background run bg-1") {
+			notice = m.Content
+		}
+	}
+	if !strings.Contains(notice, "done") || !strings.Contains(notice, "build ok") {
+		t.Errorf("expected a completion notice with the output, got %q", notice)
+	}
+}
+
+func TestExecBackground_RedactsOutput(t *testing.T) {
+	provider := &mockProvider{responses: []providers.ChatResponse{
+		backgroundToolCall("call_1", "exec", `{"command": "echo api_key=abcdef123456", "background": true}`),
+		{Content: "Running it."},
+	}}
+	nc, msgBus := newTestAgent(t, provider)
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "print the key"})
+
+	nc.WaitBackgroundRuns()
+	var notice string
+	for _, m := range drainOutbound(msgBus) {
+		if strings.Contains(m.Content, "Background run bg-1") {
+			notice = m.Content
+		}
+	}
+	if strings.Contains(notice, "abcdef123456") || !strings.Contains(notice, "api_key=[REDACTED]") {
+		t.Errorf("expected the secret to be redacted, got %q", notice)
+	}
+}
+
+func TestCancelRun_StopsBackgroundExec(t *testing.T) {
+	provider := &mockProvider{responses: []providers.ChatResponse{
+		backgroundToolCall("call_1", "exec", `{"command": "sleep 30", "background": true}`),
+		{Content: "Started."},
+		backgroundToolCall("call_2", "list_background_runs", `{}`),
+		backgroundToolCall("call_3", "cancel_run", `{"id": "bg-1"}`),
+		{Content: "Cancelled it."},
+	}}
+	nc, msgBus := newTestAgent(t, provider)
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "run the slow job"})
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "actually stop it"})
+
+	listed := lastToolResult(provider.requests[3])
+	if !strings.Contains(listed, "bg-1 [running] exec: sleep 30") {
+		t.Errorf("expected the running command to be listed, got %q", listed)
+	}
+	if got := lastToolResult(provider.requests[4]); !strings.HasPrefix(got, "Cancelled bg-1") {
+		t.Errorf("unexpected cancel_run result %q", got)
+	}
+
+	nc.WaitBackgroundRuns()
+	var notice string
+	for _, m := range drainOutbound(msgBus) {
+		if strings.Contains(m.Content, "Background run bg-1") {
+			notice = m.Content
+		}
+	}
+	if !strings.Contains(notice, "cancelled") {
+		t.Errorf("expected a cancellation notice, got %q", notice)
+	}
+}
+
+func TestListBackgroundRuns_OnlyCallersChat(t *testing.T) {
+	provider := &mockProvider{responses: []providers.ChatResponse{
+		backgroundToolCall("call_1", "exec", `{"command": "sleep 30", "background": true}`),
+		{Content: "Started."},
+		backgroundToolCall("call_2", "list_background_runs", `{}`),
+		{Content: "Nothing here."},
+		backgroundToolCall("call_3", "list_background_runs", `{}`),
+		{Content: "One run."},
+		backgroundToolCall("call_4", "cancel_run", `{"id": "bg-1"}`),
+		{Content: "Cancelled it."},
+	}}
+	nc, _ := newTestAgent(t, provider)
+	ctx := context.Background()
+
+	nc.RunAgentLoop(ctx, bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "run the slow job"})
+	nc.RunAgentLoop(ctx, bus.InboundMessage{ChatID: "other", Channel: "telegram", Content: "what is running?"})
+	nc.RunAgentLoop(ctx, bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "what is running?"})
+	nc.RunAgentLoop(ctx, bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "stop it"})
+	nc.WaitBackgroundRuns()
+
+	if got := lastToolResult(provider.requests[3]); got != "No background runs." {
+		t.Errorf("another chat's run was listed: %q", got)
+	}
+	if got := lastToolResult(provider.requests[5]); !strings.Contains(got, "bg-1 [running] exec: sleep 30") {
+		t.Errorf("expected the chat's own run, got %q", got)
+	}
+}
@@ -0,0 +1,70 @@
+package agent_test
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"littleclaw/pkg/agent"
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/providers"
+)
+
+func broadcastCall(id, args string) providers.ChatResponse {
+	return providers.ChatResponse{ToolCalls: []map[string]interface{}{
+		{
+			"id": id,
+			"function": map[string]interface{}{
+				"name":      "broadcast",
+				"arguments": args,
+			},
+		},
+	}}
+}
+
+func TestBroadcast_SendsToRegisteredChats(t *testing.T) {
+	provider := &mockProvider{responses: []providers.ChatResponse{
+		broadcastCall("call_1", `{"message": "Server maintenance at 22:00"}`),
+		{Content: "Announced."},
+	}}
+	nc, msgBus := newTestAgent(t, provider)
+	nc.EnableBroadcast(agent.BroadcastPolicy{Admins: []string{"admin"}, Chats: []string{"111", "222", "slack:C9"}})
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "admin", Channel: "telegram", Content: "tell everyone about the maintenance"})
+	var got []string
+	for _, m := range drainOutbound(msgBus) {
+		if m.Content == "Server maintenance at 22:00" {
+			got = append(got, m.Channel+":"+m.ChatID)
+		}
+	}
+	sort.Strings(got)
+	if strings.Join(got, ",") != "slack:C9,telegram:111,telegram:222" {
+		t.Errorf("unexpected broadcast recipients: %q", got)
+	}
+}
+
+func TestBroadcast_AdminOnly(t *testing.T) {
+	provider := &mockProvider{responses: []providers.ChatResponse{
+		broadcastCall("call_1", `{"message": "hi all", "chats": ["111"]}`),
+		{Content: "I can't do that."},
+	}}
+	nc, msgBus := newTestAgent(t, provider)
+	nc.EnableBroadcast(agent.BroadcastPolicy{Admins: []string{"admin"}, Chats: []string{"111"}})
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "message everyone"})
+	for _, m := range drainOutbound(msgBus) {
+		if m.Content == "hi all" {
+			t.Errorf("non-admin broadcast was sent to %s", m.ChatID)
+		}
+	}
+	refused := false
+	for _, m := range provider.requests[len(provider.requests)-1].Messages {
+		if m.Role == "tool" && strings.Contains(m.Content, "only the admin") {
+			refused = true
+		}
+	}
+	if !refused {
+		t.Error("expected the tool to refuse a non-admin chat")
+	}
+}
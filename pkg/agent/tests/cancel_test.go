@@ -0,0 +1,100 @@
+package agent_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/providers"
+)
+
+// execThenBlockProvider asks for a long exec first, then blocks every later
+// call until its context is cancelled.
+type execThenBlockProvider struct {
+	mu      sync.Mutex
+	calls   int
+	started chan struct{}
+}
+
+func (p *execThenBlockProvider) Chat(ctx context.Context, req providers.ChatRequest) (*providers.ChatResponse, error) {
+	p.mu.Lock()
+	p.calls++
+	first := p.calls == 1
+	p.mu.Unlock()
+	if first {
+		close(p.started)
+		return &providers.ChatResponse{ToolCalls: []map[string]interface{}{
+			{
+				"id": "call_sleep",
+				"function": map[string]interface{}{
+					"name":      "exec",
+					"arguments": `{"command": "sleep 30"}`,
+				},
+			},
+		}}, nil
+	}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (p *execThenBlockProvider) Name() string { return "mock" }
+
+func (p *execThenBlockProvider) ListModels(ctx context.Context) ([]providers.ModelInfo, error) {
+	return nil, nil
+}
+
+func TestStopCommand_CancelsRunAndExec(t *testing.T) {
+	provider := &execThenBlockProvider{started: make(chan struct{})}
+	nc, msgBus := newTestAgent(t, provider)
+	ctx := context.Background()
+
+	done := make(chan struct{})
+	go func() {
+		nc.RunAgentLoop(ctx, bus.InboundMessage{ChatID: "user123", Channel: "telegram", MessageID: 7, Content: "run a long job"})
+		close(done)
+	}()
+	<-provider.started
+	time.Sleep(300 * time.Millisecond) // let exec start
+
+	start := time.Now()
+	nc.RunAgentLoop(ctx, bus.InboundMessage{ChatID: "user123", Channel: "telegram", MessageID: 8, Content: "/stop"})
+
+	select {
+	case <-done:
+	case <-time.After(15 * time.Second):
+		t.Fatal("run did not stop")
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("stopping took %s; exec was not killed", elapsed)
+	}
+
+	var confirmed, cleared bool
+	for _, m := range drainOutbound(msgBus) {
+		switch {
+		case m.ReplyToMessageID == 8 && m.Content == "🛑 Stopped 1 run(s).":
+			confirmed = true
+		case m.ReplyToMessageID == 7 && m.Content == "":
+			cleared = true
+		case strings.Contains(m.Content, "API Error"):
+			t.Errorf("stopped run reported an error: %q", m.Content)
+		}
+	}
+	if !confirmed || !cleared {
+		t.Errorf("expected a confirmation and a cleared reply (confirmed=%v cleared=%v)", confirmed, cleared)
+	}
+}
+
+func TestStopCommand_NothingRunning(t *testing.T) {
+	provider := &mockProvider{}
+	nc, msgBus := newTestAgent(t, provider)
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "/stop@littleclaw_bot"})
+
+	out := drainOutbound(msgBus)
+	if len(provider.requests) != 0 || len(out) != 1 || out[0].Content != "Nothing is running." {
+		t.Errorf("expected a plain answer without calling the model, got %d requests and %+v", len(provider.requests), out)
+	}
+}
@@ -0,0 +1,114 @@
+package agent_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"littleclaw/pkg/agent"
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/providers"
+)
+
+// sendTwoFilesThenAnswer sends a.txt and b.txt with captions, then replies.
+func sendTwoFilesThenAnswer() *mockProvider {
+	sendFile := func(id, path, caption string) map[string]interface{} {
+		return map[string]interface{}{
+			"id": id,
+			"function": map[string]interface{}{
+				"name":      "send_telegram_file",
+				"arguments": `{"path": "` + path + `", "caption": "` + caption + `"}`,
+			},
+		}
+	}
+	return &mockProvider{responses: []providers.ChatResponse{
+		{ToolCalls: []map[string]interface{}{sendFile("call_1", "a.txt", "first")}},
+		{ToolCalls: []map[string]interface{}{sendFile("call_2", "b.txt", "second")}},
+		{Content: "Done."},
+	}}
+}
+
+func newCoalesceAgent(t *testing.T) (*agent.NanoCore, *bus.MessageBus) {
+	t.Helper()
+	nc, msgBus := newTestAgent(t, sendTwoFilesThenAnswer())
+	workspace := filepath.Dir(nc.MemoryStore().MemoryDir())
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(workspace, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return nc, msgBus
+}
+
+func runCoalesced(t *testing.T, p agent.AgentParams) []bus.OutboundMessage {
+	t.Helper()
+	nc, msgBus := newCoalesceAgent(t)
+	p.Progress = agent.ProgressOff
+	nc.SetAgentParams(p)
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "send both"})
+	return drainOutbound(msgBus)
+}
+
+func TestCoalesce_OffSendsEachMessage(t *testing.T) {
+	out := runCoalesced(t, agent.AgentParams{})
+	if len(out) != 3 || out[0].Content != "first" || out[1].Content != "second" || out[2].Content != "Done." {
+		t.Fatalf("got %+v", out)
+	}
+}
+
+func TestCoalesce_RunCombinesToolMessages(t *testing.T) {
+	for _, mode := range []string{agent.CoalesceRun, agent.CoalesceWindow} {
+		out := runCoalesced(t, agent.AgentParams{Coalesce: mode, CoalesceSeconds: 60})
+		if len(out) != 2 {
+			t.Fatalf("%s: got %d messages: %+v", mode, len(out), out)
+		}
+		if out[0].Content != "first\n\nsecond" || len(out[0].Files) != 2 {
+			t.Errorf("%s: combined message = %+v", mode, out[0])
+		}
+		if out[1].Content != "Done." {
+			t.Errorf("%s: final reply = %+v", mode, out[1])
+		}
+	}
+}
+
+func TestCoalesce_PerChatOverride(t *testing.T) {
+	nc, msgBus := newCoalesceAgent(t)
+	nc.SetAgentParams(agent.AgentParams{Progress: agent.ProgressOff})
+	nc.SetChatParams("user123", agent.AgentParams{Coalesce: agent.CoalesceRun})
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "send both"})
+	if out := drainOutbound(msgBus); len(out) != 2 {
+		t.Fatalf("got %d messages: %+v", len(out), out)
+	}
+}
+
+func TestCoalesce_KeepsTempFiles(t *testing.T) {
+	provider := &mockProvider{responses: []providers.ChatResponse{
+		{ToolCalls: []map[string]interface{}{{
+			"id": "call_1",
+			"function": map[string]interface{}{
+				"name":      "send_telegram_file",
+				"arguments": `{"path": "big.log", "if_too_large": "compress"}`,
+			},
+		}}},
+		{Content: "Sent it compressed."},
+	}}
+	nc, msgBus := newTestAgent(t, provider)
+	workspace := filepath.Dir(nc.MemoryStore().MemoryDir())
+	f, err := os.Create(filepath.Join(workspace, "big.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Truncate(60 << 20)
+	_ = f.Close()
+	nc.SetAgentParams(agent.AgentParams{Progress: agent.ProgressOff, Coalesce: agent.CoalesceRun})
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "send the log"})
+
+	out := drainOutbound(msgBus)
+	if len(out) != 2 || len(out[0].Files) != 1 || len(out[0].TempFiles) != 2 || out[0].TempFiles[0] != out[0].Files[0] {
+		t.Fatalf("expected the compressed copy marked temporary, got %+v", out)
+	}
+}
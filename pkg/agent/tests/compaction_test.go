@@ -0,0 +1,102 @@
+package agent_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"littleclaw/pkg/agent"
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/providers"
+)
+
+func readFileCall(id, path string) providers.ChatResponse {
+	return providers.ChatResponse{ToolCalls: []map[string]interface{}{
+		{
+			"id": id,
+			"function": map[string]interface{}{
+				"name":      "read_file",
+				"arguments": `{"path": "` + path + `"}`,
+			},
+		},
+	}}
+}
+
+// runCompactionTurn runs two large read_file rounds against a tiny context
+// window so the second request must fold the first round into a digest.
+func runCompactionTurn(t *testing.T, digest string) (*mockProvider, []bus.OutboundMessage) {
+	t.Helper()
+	provider := &mockProvider{responses: []providers.ChatResponse{
+		readFileCall("call_1", "a.txt"),
+		readFileCall("call_2", "b.txt"),
+		{Content: digest},
+		{Content: "done"},
+	}}
+	dir := t.TempDir()
+	msgBus := bus.NewMessageBus()
+	nc, err := agent.NewNanoCore(provider, "mock", "test-model", dir, msgBus, "")
+	if err != nil {
+		t.Fatalf("agent.NewNanoCore() error = %v", err)
+	}
+	nc.SetAgentParams(agent.AgentParams{ContextWindow: 1000})
+	for name, word := range map[string]string{"a.txt": "alpha ", "b.txt": "bravo "} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(strings.Repeat(word, 400)), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "compare a.txt and b.txt"})
+	if len(provider.requests) != 4 {
+		t.Fatalf("expected 4 requests (3 loop + 1 digest), got %d", len(provider.requests))
+	}
+	return provider, drainOutbound(msgBus)
+}
+
+func TestCompaction_SummarizesOlderToolRounds(t *testing.T) {
+	provider, out := runCompactionTurn(t, "- a.txt is 400 repetitions of alpha")
+
+	if len(provider.requests[2].Tools) != 0 {
+		t.Errorf("digest request should not offer tools")
+	}
+	msgs := provider.requests[3].Messages
+	var digest, kept string
+	for _, m := range msgs {
+		if m.Role == "tool" && m.ToolCallID == "call_2" {
+			kept = m.Content
+		}
+		if m.Role == "tool" && m.ToolCallID == "call_1" {
+			t.Errorf("first tool round should have been compacted")
+		}
+		if m.Role == "user" && strings.Contains(m.Content, "compacted") {
+			digest = m.Content
+		}
+	}
+	if !strings.Contains(digest, "400 repetitions of alpha") {
+		t.Errorf("expected the summarizer's digest in the messages, got %q", digest)
+	}
+	if !strings.Contains(kept, "bravo") {
+		t.Errorf("latest tool round must be kept verbatim, got %q", kept)
+	}
+	if len(out) != 1 || out[0].Content != "done" {
+		t.Errorf("expected the final reply, got %+v", out)
+	}
+}
+
+func TestCompaction_FallsBackWhenSummarizerFails(t *testing.T) {
+	provider, _ := runCompactionTurn(t, "")
+
+	for _, m := range provider.requests[3].Messages {
+		if m.Role == "user" && strings.Contains(m.Content, "compacted") {
+			if !strings.Contains(m.Content, "read_file") || !strings.Contains(m.Content, "alpha") {
+				t.Errorf("fallback digest should list the call and the start of its result, got %q", m.Content)
+			}
+			if len(m.Content) > 1000 {
+				t.Errorf("fallback digest should be truncated, got %d chars", len(m.Content))
+			}
+			return
+		}
+	}
+	t.Error("expected a fallback digest message")
+}
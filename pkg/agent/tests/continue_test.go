@@ -0,0 +1,58 @@
+package agent_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/providers"
+)
+
+func TestAutoContinue_StitchesTruncatedReply(t *testing.T) {
+	provider := &mockProvider{responses: []providers.ChatResponse{
+		{Content: "The three steps are: one, tw", FinishReason: providers.FinishReasonLength},
+		{Content: "o, and thr", FinishReason: providers.FinishReasonLength},
+		{Content: "ee.", FinishReason: "stop"},
+		{Content: "You're welcome."},
+	}}
+	nc, msgBus := newTestAgent(t, provider)
+	ctx := context.Background()
+
+	nc.RunAgentLoop(ctx, bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "list the steps"})
+
+	out := drainOutbound(msgBus)
+	if len(out) != 1 || out[0].Content != "The three steps are: one, two, and three." {
+		t.Fatalf("expected one stitched reply, got %+v", out)
+	}
+	if len(provider.requests) != 3 {
+		t.Fatalf("expected 2 continuation requests, got %d requests", len(provider.requests))
+	}
+	last := provider.requests[1].Messages[len(provider.requests[1].Messages)-1]
+	if last.Role != "user" || !strings.Contains(last.Content, "cut off by the length limit") {
+		t.Errorf("expected a continue prompt, got %+v", last)
+	}
+
+	// The session keeps only the stitched reply
+	nc.RunAgentLoop(ctx, bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "thanks"})
+	msgs := provider.requests[3].Messages
+	if len(msgs) != 4 || msgs[2].Content != "The three steps are: one, two, and three." {
+		t.Errorf("expected the stitched reply in the session, got %+v", msgs[1:])
+	}
+}
+
+func TestAutoContinue_RespectsLimit(t *testing.T) {
+	provider := &mockProvider{responses: []providers.ChatResponse{
+		{Content: "part one ", FinishReason: providers.FinishReasonLength},
+		{Content: "part two", FinishReason: providers.FinishReasonLength},
+	}}
+	nc, msgBus := newTestAgent(t, provider)
+	nc.SetMaxContinuations(1)
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "go"})
+
+	out := drainOutbound(msgBus)
+	if len(provider.requests) != 2 || len(out) != 1 || out[0].Content != "part one part two" {
+		t.Errorf("expected one continuation then delivery, got %d requests and %+v", len(provider.requests), out)
+	}
+}
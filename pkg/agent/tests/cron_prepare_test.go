@@ -0,0 +1,35 @@
+package agent_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"littleclaw/pkg/agent"
+)
+
+func TestPrepareCronJob(t *testing.T) {
+	allow := func(string) error { return nil }
+
+	job := &agent.CronJob{Label: "Nightly backup", Schedule: "0 0 3 * * *", Command: "tar czf b.tgz notes", Type: agent.CronJobShell, Retries: 99}
+	if err := agent.PrepareCronJob(job, allow); err != nil {
+		t.Fatalf("PrepareCronJob() error = %v", err)
+	}
+	if job.ID != "Nightly_backup" || job.Type != "" || job.Retries != agent.MaxCronRetries {
+		t.Errorf("prepared job = ID %q, type %q, retries %d", job.ID, job.Type, job.Retries)
+	}
+
+	deny := func(string) error { return errors.New("denied") }
+	if err := agent.PrepareCronJob(&agent.CronJob{Label: "x", Schedule: "@every 1h", Command: "mkfs x"}, deny); err == nil || !strings.Contains(err.Error(), "exec policy") {
+		t.Errorf("expected a shell job to be checked, got %v", err)
+	}
+	if err := agent.PrepareCronJob(&agent.CronJob{Label: "x", Schedule: "@every 1h", Command: "summarize my inbox", Type: agent.CronJobAgent}, deny); err != nil {
+		t.Errorf("expected an agent job to skip the command check, got %v", err)
+	}
+	if err := agent.PrepareCronJob(&agent.CronJob{Label: "x", Schedule: "@every 1h", Command: "y", Type: "python"}, allow); err == nil {
+		t.Error("expected an unknown type to be rejected")
+	}
+	if err := agent.PrepareCronJob(&agent.CronJob{Label: "x", Command: "y"}, allow); err == nil {
+		t.Error("expected a missing schedule to be rejected")
+	}
+}
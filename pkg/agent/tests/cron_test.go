@@ -2,6 +2,7 @@ package agent_test
 
 import (
 	"littleclaw/pkg/agent"
+	"context"
 	"encoding/json"
 	"os"
 	"path/filepath"
@@ -413,3 +414,797 @@ func TestRecordRun_ErrorStatus(t *testing.T) {
 		t.Errorf("expected error message 'something went wrong', got %q", rec.Error)
 	}
 }
+
+// ---------------------------------------------------------------------------
+// Agent job tests
+// ---------------------------------------------------------------------------
+
+func TestRunJobNow_AgentJobTriggersAgentLoop(t *testing.T) {
+	cs, _ := newTestCronService(t)
+	if err := cs.Start(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	var got []bus.InboundMessage
+	cs.SetAgentTrigger(func(_ context.Context, msg bus.InboundMessage) { got = append(got, msg) })
+
+	job := &agent.CronJob{
+		ID:       "rss",
+		Type:     agent.CronJobAgent,
+		Schedule: "@every 24h",
+		Command:  "Summarize my unread RSS items",
+		Label:    "rss digest",
+		ChatID:   "user123",
+		Channel:  "telegram",
+	}
+	if err := cs.AddJob(job); err != nil {
+		t.Fatalf("AddJob() error = %v", err)
+	}
+	if err := cs.RunJobNow("rss"); err != nil {
+		t.Fatalf("RunJobNow() error = %v", err)
+	}
+
+	if len(got) != 1 {
+		t.Fatalf("expected 1 agent run, got %d", len(got))
+	}
+	if got[0].ChatID != "user123" || got[0].Channel != "telegram" || got[0].SenderID != "system" {
+		t.Errorf("agent run not in the job's chat: %+v", got[0])
+	}
+	if !strings.Contains(got[0].Content, "Summarize my unread RSS items") {
+		t.Errorf("instruction missing from agent prompt: %q", got[0].Content)
+	}
+	if st := cs.Jobs()["rss"].State; st.LastStatus != "ok" {
+		t.Errorf("LastStatus = %q, want ok", st.LastStatus)
+	}
+}
+
+func TestRunJobNow_SilentAgentJobRunsInternally(t *testing.T) {
+	cs, _ := newTestCronService(t)
+	if err := cs.Start(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	var got bus.InboundMessage
+	cs.SetAgentTrigger(func(_ context.Context, msg bus.InboundMessage) { got = msg })
+
+	job := &agent.CronJob{
+		ID:       "tidy",
+		Type:     agent.CronJobAgent,
+		Schedule: "@every 24h",
+		Command:  "Tidy up the notes folder",
+		Label:    "tidy",
+		ChatID:   "user123",
+		Channel:  "telegram",
+		Silent:   true,
+	}
+	if err := cs.AddJob(job); err != nil {
+		t.Fatalf("AddJob() error = %v", err)
+	}
+	if err := cs.RunJobNow("tidy"); err != nil {
+		t.Fatalf("RunJobNow() error = %v", err)
+	}
+	if got.Channel != "internal" || got.ChatID != "internal_memory" {
+		t.Errorf("silent agent job should run internally, got %+v", got)
+	}
+}
+
+func TestRunJobNow_AgentJobWithoutTriggerFails(t *testing.T) {
+	cs, _ := newTestCronService(t)
+	if err := cs.Start(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	job := &agent.CronJob{
+		ID:       "orphan",
+		Type:     agent.CronJobAgent,
+		Schedule: "@every 24h",
+		Command:  "Do something",
+		Label:    "orphan",
+	}
+	if err := cs.AddJob(job); err != nil {
+		t.Fatalf("AddJob() error = %v", err)
+	}
+	if err := cs.RunJobNow("orphan"); err != nil {
+		t.Fatalf("RunJobNow() error = %v", err)
+	}
+	if st := cs.Jobs()["orphan"].State; st.LastStatus != "error" || st.ConsecutiveErrors != 1 {
+		t.Errorf("expected an error run, got %+v", st)
+	}
+	if err := cs.RunJobNow("missing"); err == nil {
+		t.Error("RunJobNow() on an unknown job should return an error")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// One-shot job tests
+// ---------------------------------------------------------------------------
+
+func TestParseWhen(t *testing.T) {
+	now := time.Date(2026, 3, 10, 14, 0, 0, 0, time.Local)
+	cases := []struct {
+		in   string
+		want time.Time
+	}{
+		{"in 45 minutes", now.Add(45 * time.Minute)},
+		{"in 2h", now.Add(2 * time.Hour)},
+		{"90m", now.Add(90 * time.Minute)},
+		{"in 1h30m", now.Add(90 * time.Minute)},
+		{"in an hour", now.Add(time.Hour)},
+		{"in 3 days", now.Add(72 * time.Hour)},
+		{"18:30", time.Date(2026, 3, 10, 18, 30, 0, 0, time.Local)},
+		{"9:00", time.Date(2026, 3, 11, 9, 0, 0, 0, time.Local)},
+		{"tomorrow at 9:00", time.Date(2026, 3, 11, 9, 0, 0, 0, time.Local)},
+		{"2026-05-01 09:00", time.Date(2026, 5, 1, 9, 0, 0, 0, time.Local)},
+	}
+	for _, tc := range cases {
+		got, err := agent.ParseWhen(tc.in, now)
+		if err != nil {
+			t.Errorf("ParseWhen(%q) error = %v", tc.in, err)
+			continue
+		}
+		if !got.Equal(tc.want) {
+			t.Errorf("ParseWhen(%q) = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+
+	for _, bad := range []string{"", "someday", "2020-01-01 09:00", "today at 9:00"} {
+		if _, err := agent.ParseWhen(bad, now); err == nil {
+			t.Errorf("ParseWhen(%q) should fail", bad)
+		}
+	}
+}
+
+func TestOneShotJob_FiresOnceAndIsRemoved(t *testing.T) {
+	dir := t.TempDir()
+	msgBus := bus.NewMessageBus()
+	mem, err := memory.NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := agent.NewCronService(dir, msgBus, mem)
+	if err := cs.Start(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	job := &agent.CronJob{
+		ID:       "dentist",
+		Type:     agent.CronJobMessage,
+		Schedule: agent.AtSchedule(time.Now().Add(2 * time.Second)),
+		Command:  "Leave for the dentist",
+		Label:    "dentist",
+		ChatID:   "user123",
+		Channel:  "telegram",
+		Once:     true,
+	}
+	if err := cs.AddJob(job); err != nil {
+		t.Fatalf("AddJob() error = %v", err)
+	}
+	time.Sleep(200 * time.Millisecond)
+	if len(cs.ListJobs()) != 1 || len(msgBus.Outbound) != 0 {
+		t.Fatal("one-shot job fired before its time")
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(cs.ListJobs()) > 0 && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	if len(cs.ListJobs()) != 0 {
+		t.Fatal("one-shot job should be removed after firing")
+	}
+
+	select {
+	case out := <-msgBus.Outbound:
+		if out.ChatID != "user123" || out.Content != "⏰ Leave for the dentist" {
+			t.Errorf("unexpected reminder: %+v", out)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("reminder was not sent")
+	}
+}
+
+func TestOneShotJob_MissedWhileDownFiresOnStart(t *testing.T) {
+	dir := t.TempDir()
+	msgBus := bus.NewMessageBus()
+	mem, err := memory.NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jobs := []*agent.CronJob{{
+		ID:       "missed",
+		Type:     agent.CronJobMessage,
+		Schedule: agent.AtSchedule(time.Now().Add(-time.Hour)),
+		Command:  "Call mom",
+		Label:    "missed",
+		ChatID:   "user123",
+		Channel:  "telegram",
+		Once:     true,
+	}}
+	data, _ := json.Marshal(jobs)
+	if err := os.WriteFile(filepath.Join(dir, "CRON.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cs := agent.NewCronService(dir, msgBus, mem)
+	if err := cs.Start(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case out := <-msgBus.Outbound:
+		if out.Content != "⏰ Call mom" {
+			t.Errorf("unexpected reminder: %+v", out)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("missed reminder was not sent on start")
+	}
+}
+
+func TestAddJob_InvalidAtSchedule(t *testing.T) {
+	cs, _ := newTestCronService(t)
+	job := &agent.CronJob{ID: "bad", Schedule: "@at tomorrow", Command: "echo hi", Label: "bad"}
+	if err := cs.AddJob(job); err == nil {
+		t.Error("AddJob() with an invalid @at time should return an error")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Run history tests
+// ---------------------------------------------------------------------------
+
+func TestRunJobNow_RecordsExitCodeAndOutput(t *testing.T) {
+	cs, _ := newTestCronService(t)
+	if err := cs.Start(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	job := &agent.CronJob{
+		ID:       "backup",
+		Schedule: "@every 24h",
+		Command:  "echo copying; exit 3",
+		Label:    "nightly backup",
+		Silent:   true,
+	}
+	if err := cs.AddJob(job); err != nil {
+		t.Fatalf("AddJob() error = %v", err)
+	}
+	if err := cs.RunJobNow("backup"); err != nil {
+		t.Fatalf("RunJobNow() error = %v", err)
+	}
+
+	st := cs.FindJob("nightly backup").State
+	if st.LastStatus != "error" || st.LastExitCode != 3 || st.LastOutput != "copying" {
+		t.Errorf("unexpected job state: %+v", st)
+	}
+
+	runs := cs.GetRecentRuns("backup", 10)
+	if len(runs) != 1 {
+		t.Fatalf("expected 1 run record, got %d", len(runs))
+	}
+	if r := runs[0]; r.Status != "error" || r.ExitCode != 3 || r.Output != "copying" || r.Action != "finished" || r.Ts == 0 {
+		t.Errorf("unexpected run record: %+v", r)
+	}
+
+	cs.Jobs()["backup"].Command = "echo done"
+	if err := cs.RunJobNow("backup"); err != nil {
+		t.Fatal(err)
+	}
+	st = cs.FindJob("backup").State
+	if st.LastStatus != "ok" || st.LastExitCode != 0 || st.ConsecutiveErrors != 0 {
+		t.Errorf("successful run should reset the error state: %+v", st)
+	}
+	if runs := cs.GetRecentRuns("backup", 10); len(runs) != 2 || runs[1].Output != "done" {
+		t.Errorf("expected a second run with output 'done', got %+v", runs)
+	}
+}
+
+func TestFindJob_ByIDOrLabel(t *testing.T) {
+	cs, _ := newTestCronService(t)
+	if err := cs.AddJob(&agent.CronJob{ID: "j1", Schedule: "@every 1h", Command: "true", Label: "Morning news"}); err != nil {
+		t.Fatal(err)
+	}
+	if cs.FindJob("j1") == nil || cs.FindJob("Morning news") == nil {
+		t.Error("FindJob should match by ID and by label")
+	}
+	if cs.FindJob("nope") != nil {
+		t.Error("FindJob should return nil for unknown jobs")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Pause/resume tests
+// ---------------------------------------------------------------------------
+
+func TestPauseAndResumeJob(t *testing.T) {
+	cs, dir := newTestCronService(t)
+	if err := cs.Start(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.AddJob(&agent.CronJob{ID: "noisy", Schedule: "@every 1h", Command: "echo hi", Label: "noisy"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cs.PauseJob("noisy"); err != nil {
+		t.Fatalf("PauseJob() error = %v", err)
+	}
+	if err := cs.PauseJob("noisy"); err == nil {
+		t.Error("pausing a paused job should fail")
+	}
+	job := cs.FindJob("noisy")
+	if job == nil || job.IsEnabled() || job.State.NextRunAtMs != 0 {
+		t.Fatalf("job should be kept but paused: %+v", job)
+	}
+
+	// The paused flag is persisted and survives a restart
+	cs2, _ := newTestCronService2(t, dir)
+	if err := cs2.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if cs2.Jobs()["noisy"].IsEnabled() {
+		t.Error("paused flag should be persisted to CRON.json")
+	}
+
+	if err := cs.ResumeJob("noisy"); err != nil {
+		t.Fatalf("ResumeJob() error = %v", err)
+	}
+	if err := cs.ResumeJob("noisy"); err == nil {
+		t.Error("resuming a running job should fail")
+	}
+	job = cs.FindJob("noisy")
+	if !job.IsEnabled() || job.State.NextRunAtMs == 0 {
+		t.Errorf("resumed job should be scheduled again: %+v", job)
+	}
+}
+
+func TestRemoveJob_Paused(t *testing.T) {
+	cs, _ := newTestCronService(t)
+	if err := cs.AddJob(&agent.CronJob{ID: "p", Schedule: "@every 1h", Command: "true", Label: "p"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.PauseJob("p"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.RemoveJob("p"); err != nil {
+		t.Errorf("RemoveJob() on a paused job error = %v", err)
+	}
+}
+
+func TestCronJob_EnabledDefaultsToTrue(t *testing.T) {
+	var job agent.CronJob
+	if err := json.Unmarshal([]byte(`{"id":"old","schedule":"@every 1h","command":"true"}`), &job); err != nil {
+		t.Fatal(err)
+	}
+	if !job.IsEnabled() {
+		t.Error("jobs saved before the enabled flag existed should stay enabled")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Timeout tests
+// ---------------------------------------------------------------------------
+
+func TestRunJobNow_ShellJobTimesOut(t *testing.T) {
+	dir := t.TempDir()
+	msgBus := bus.NewMessageBus()
+	mem, err := memory.NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := agent.NewCronService(dir, msgBus, mem)
+	if err := cs.Start(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	job := &agent.CronJob{
+		ID:       "hung",
+		Schedule: "@every 24h",
+		Command:  "echo started; sleep 30",
+		Label:    "hung",
+		ChatID:   "user123",
+		Channel:  "telegram",
+		Timeout:  1,
+	}
+	if err := cs.AddJob(job); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	if err := cs.RunJobNow("hung"); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("timed-out job took %s to stop", elapsed)
+	}
+
+	st := cs.FindJob("hung").State
+	if st.LastStatus != "error" || !strings.Contains(st.LastError, "timed out") || st.LastOutput != "started" {
+		t.Errorf("unexpected state after timeout: %+v", st)
+	}
+	out := drainOutbound(msgBus)
+	if len(out) != 1 || !strings.Contains(out[0].Content, "timed out after 1s") {
+		t.Errorf("expected a timeout message to the user, got %+v", out)
+	}
+}
+
+func TestRunJobNow_AgentJobTimesOut(t *testing.T) {
+	dir := t.TempDir()
+	msgBus := bus.NewMessageBus()
+	mem, err := memory.NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := agent.NewCronService(dir, msgBus, mem)
+	if err := cs.Start(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	cs.SetDefaultTimeout(100 * time.Millisecond)
+	cs.SetAgentTrigger(func(ctx context.Context, _ bus.InboundMessage) { <-ctx.Done() })
+
+	job := &agent.CronJob{
+		ID:       "slow",
+		Type:     agent.CronJobAgent,
+		Schedule: "@every 24h",
+		Command:  "Research everything",
+		Label:    "slow",
+		ChatID:   "user123",
+		Channel:  "telegram",
+	}
+	if err := cs.AddJob(job); err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.RunJobNow("slow"); err != nil {
+		t.Fatal(err)
+	}
+	if st := cs.FindJob("slow").State; st.LastStatus != "error" {
+		t.Errorf("LastStatus = %q, want error", st.LastStatus)
+	}
+	out := drainOutbound(msgBus)
+	if len(out) != 1 || !strings.Contains(out[0].Content, "timed out") {
+		t.Errorf("expected a timeout message to the user, got %+v", out)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Retry and alert policy tests
+// ---------------------------------------------------------------------------
+
+// newTestCronServiceWithBus creates a started CronService whose outbound
+// messages can be inspected.
+func newTestCronServiceWithBus(t *testing.T) (*agent.CronService, *bus.MessageBus) {
+	t.Helper()
+	dir := t.TempDir()
+	msgBus := bus.NewMessageBus()
+	mem, err := memory.NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := agent.NewCronService(dir, msgBus, mem)
+	cs.RetryBackoff = 10 * time.Millisecond
+	if err := cs.Start(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	return cs, msgBus
+}
+
+func TestRunJobNow_RetriesFailedRun(t *testing.T) {
+	cs, msgBus := newTestCronServiceWithBus(t)
+	job := &agent.CronJob{
+		ID:       "flaky",
+		Schedule: "@every 1h",
+		Command:  "if [ -f tried ]; then echo fetched; else touch tried; exit 1; fi",
+		Label:    "flaky",
+		ChatID:   "user123",
+		Channel:  "telegram",
+		Retries:  2,
+	}
+	if err := cs.AddJob(job); err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.RunJobNow("flaky"); err != nil {
+		t.Fatal(err)
+	}
+
+	runs := cs.GetRecentRuns("flaky", 10)
+	if len(runs) != 1 || runs[0].Status != "ok" || runs[0].Attempts != 2 {
+		t.Fatalf("expected one ok run after 2 attempts, got %+v", runs)
+	}
+	out := drainOutbound(msgBus)
+	if len(out) != 1 || strings.TrimSpace(out[0].Content) != "fetched" {
+		t.Errorf("expected only the successful output, got %+v", out)
+	}
+}
+
+func TestRunJobNow_ConsecutiveAlertPolicy(t *testing.T) {
+	cs, msgBus := newTestCronServiceWithBus(t)
+	job := &agent.CronJob{
+		ID:         "net",
+		Schedule:   "@every 1h",
+		Command:    "exit 1",
+		Label:      "net check",
+		ChatID:     "user123",
+		Channel:    "telegram",
+		Silent:     true,
+		Alert:      agent.AlertConsecutive,
+		AlertAfter: 2,
+	}
+	if err := cs.AddJob(job); err != nil {
+		t.Fatal(err)
+	}
+
+	var alerts []string
+	for i := 0; i < 3; i++ {
+		if err := cs.RunJobNow("net"); err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range drainOutbound(msgBus) {
+			alerts = append(alerts, m.Content)
+		}
+	}
+	if len(alerts) != 1 || !strings.Contains(alerts[0], "failed 2 runs in a row") {
+		t.Fatalf("expected a single alert at the second failure, got %q", alerts)
+	}
+
+	cs.Jobs()["net"].Command = "true"
+	if err := cs.RunJobNow("net"); err != nil {
+		t.Fatal(err)
+	}
+	out := drainOutbound(msgBus)
+	if len(out) != 1 || !strings.Contains(out[0].Content, "working again after 3 failed run(s)") {
+		t.Errorf("expected a recovery notice, got %+v", out)
+	}
+}
+
+func TestRunJobNow_SilentJobFailuresNotSentByDefault(t *testing.T) {
+	cs, msgBus := newTestCronServiceWithBus(t)
+	job := &agent.CronJob{ID: "quiet", Schedule: "@every 1h", Command: "exit 1", Label: "quiet", ChatID: "user123", Channel: "telegram", Silent: true}
+	if err := cs.AddJob(job); err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.RunJobNow("quiet"); err != nil {
+		t.Fatal(err)
+	}
+	if out := drainOutbound(msgBus); len(out) != 0 {
+		t.Errorf("silent job failures should not be sent without an alert policy, got %+v", out)
+	}
+
+	cs.Jobs()["quiet"].Alert = agent.AlertAlways
+	if err := cs.RunJobNow("quiet"); err != nil {
+		t.Fatal(err)
+	}
+	if out := drainOutbound(msgBus); len(out) != 1 {
+		t.Errorf("alert 'always' should report the failure of a silent job, got %+v", out)
+	}
+}
+
+func TestRunJobNow_SummarizedOutputGoesThroughAgent(t *testing.T) {
+	cs, msgBus := newTestCronServiceWithBus(t)
+	var got []bus.InboundMessage
+	cs.SetAgentTrigger(func(_ context.Context, msg bus.InboundMessage) { got = append(got, msg) })
+
+	job := &agent.CronJob{
+		ID:        "logs",
+		Schedule:  "@every 24h",
+		Command:   "echo 'backup ok: 12 files'; echo 'backup FAILED: photos'",
+		Label:     "backup log",
+		ChatID:    "user123",
+		Channel:   "telegram",
+		Summarize: true,
+		Digest:    "List only failures.",
+	}
+	if err := cs.AddJob(job); err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.RunJobNow("logs"); err != nil {
+		t.Fatal(err)
+	}
+
+	if out := drainOutbound(msgBus); len(out) != 0 {
+		t.Errorf("raw output should not be sent when summarized, got %+v", out)
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected one agent run for the digest, got %d", len(got))
+	}
+	if got[0].ChatID != "user123" || !strings.Contains(got[0].Content, "backup FAILED: photos") || !strings.Contains(got[0].Content, "List only failures.") {
+		t.Errorf("unexpected digest prompt: %+v", got[0])
+	}
+
+	// Failures are still reported raw
+	cs.Jobs()["logs"].Command = "echo broken; exit 2"
+	if err := cs.RunJobNow("logs"); err != nil {
+		t.Fatal(err)
+	}
+	if out := drainOutbound(msgBus); len(out) != 1 || !strings.Contains(out[0].Content, "failed") {
+		t.Errorf("expected the raw failure alert, got %+v", out)
+	}
+	if len(got) != 1 {
+		t.Errorf("failed runs should not be summarized, got %d agent runs", len(got))
+	}
+}
+
+func TestRunJobNow_RedactsShellOutput(t *testing.T) {
+	cs, msgBus := newTestCronServiceWithBus(t)
+	var got []bus.InboundMessage
+	cs.SetAgentTrigger(func(_ context.Context, msg bus.InboundMessage) { got = append(got, msg) })
+
+	job := &agent.CronJob{
+		ID:       "keys",
+		Schedule: "@every 24h",
+		Command:  `echo "db $DB_PASS"; echo api_key=abcdef123456`,
+		Label:    "keys",
+		ChatID:   "user123",
+		Channel:  "telegram",
+		Env:      map[string]string{"DB_PASS": "correct-horse-battery"},
+	}
+	if err := cs.AddJob(job); err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.RunJobNow("keys"); err != nil {
+		t.Fatal(err)
+	}
+	out := drainOutbound(msgBus)
+	if len(out) != 1 {
+		t.Fatalf("expected one message, got %+v", out)
+	}
+	for _, secret := range []string{"correct-horse-battery", "abcdef123456"} {
+		if strings.Contains(out[0].Content, secret) {
+			t.Errorf("chat message leaks %q: %q", secret, out[0].Content)
+		}
+	}
+
+	cs.Jobs()["keys"].Summarize = true
+	if err := cs.RunJobNow("keys"); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected one digest run, got %d", len(got))
+	}
+	for _, secret := range []string{"correct-horse-battery", "abcdef123456"} {
+		if strings.Contains(got[0].Content, secret) {
+			t.Errorf("digest prompt leaks %q: %q", secret, got[0].Content)
+		}
+	}
+	if !strings.Contains(got[0].Content, "db [REDACTED]") {
+		t.Errorf("expected the redacted output in the digest prompt, got %q", got[0].Content)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// UpdateJob tests
+// ---------------------------------------------------------------------------
+
+func TestUpdateJob_PatchesInPlace(t *testing.T) {
+	cs, dir := newTestCronService(t)
+	if err := cs.Start(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	job := &agent.CronJob{ID: "backup", Schedule: "@every 1h", Command: "tar czf b.tgz notes", Label: "backup", Silent: true}
+	if err := cs.AddJob(job); err != nil {
+		t.Fatal(err)
+	}
+	cs.Jobs()["backup"].State.ConsecutiveErrors = 2
+
+	updated, err := cs.UpdateJob("backup", func(j *agent.CronJob) {
+		j.Schedule = "0 0 3 * * *"
+		j.Label = "nightly backup"
+		j.ID = "renamed"
+	})
+	if err != nil {
+		t.Fatalf("UpdateJob() error = %v", err)
+	}
+	if updated.ID != "backup" || updated.Command != "tar czf b.tgz notes" || !updated.Silent || updated.State.ConsecutiveErrors != 2 {
+		t.Errorf("unchanged fields should be kept: %+v", updated)
+	}
+	if updated.State.NextRunAtMs == 0 {
+		t.Error("updated job should be rescheduled")
+	}
+
+	cs2, _ := newTestCronService2(t, dir)
+	if err := cs2.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if j := cs2.Jobs()["backup"]; j == nil || j.Schedule != "0 0 3 * * *" || j.Label != "nightly backup" {
+		t.Errorf("update should be persisted, got %+v", j)
+	}
+}
+
+func TestUpdateJob_InvalidScheduleKeepsJob(t *testing.T) {
+	cs, _ := newTestCronService(t)
+	if err := cs.Start(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.AddJob(&agent.CronJob{ID: "j", Schedule: "@every 1h", Command: "true", Label: "j"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cs.UpdateJob("j", func(j *agent.CronJob) { j.Schedule = "whenever" }); err == nil {
+		t.Fatal("UpdateJob() with an invalid schedule should fail")
+	}
+	if j := cs.FindJob("j"); j.Schedule != "@every 1h" {
+		t.Errorf("job should be unchanged, got schedule %q", j.Schedule)
+	}
+	if _, err := cs.UpdateJob("missing", func(*agent.CronJob) {}); err == nil {
+		t.Error("UpdateJob() on an unknown job should fail")
+	}
+}
+
+func TestUpdateJob_PausedJobStaysPaused(t *testing.T) {
+	cs, _ := newTestCronService(t)
+	if err := cs.Start(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.AddJob(&agent.CronJob{ID: "p", Schedule: "@every 1h", Command: "true", Label: "p"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.PauseJob("p"); err != nil {
+		t.Fatal(err)
+	}
+	updated, err := cs.UpdateJob("p", func(j *agent.CronJob) { j.Schedule = "@every 2h" })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.IsEnabled() || updated.State.NextRunAtMs != 0 {
+		t.Errorf("paused job should stay paused after an update: %+v", updated)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Env and workdir tests
+// ---------------------------------------------------------------------------
+
+func TestRunJobNow_UsesEnvAndWorkDir(t *testing.T) {
+	cs, dir := newTestCronService(t)
+	if err := cs.Start(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "repos", "site"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	job := &agent.CronJob{
+		ID:       "deploy",
+		Schedule: "@every 24h",
+		Command:  `echo "$DEPLOY_TOKEN in $(basename "$PWD")"`,
+		Label:    "deploy",
+		Silent:   true,
+		Env:      map[string]string{"DEPLOY_TOKEN": "s3cret"},
+		WorkDir:  "repos/site",
+	}
+	if err := cs.AddJob(job); err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.RunJobNow("deploy"); err != nil {
+		t.Fatal(err)
+	}
+	if out := cs.FindJob("deploy").State.LastOutput; out != "s3cret in site" {
+		t.Errorf("LastOutput = %q, want %q", out, "s3cret in site")
+	}
+
+	// A working directory that disappeared fails the run instead of running elsewhere
+	if err := os.RemoveAll(filepath.Join(dir, "repos")); err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.RunJobNow("deploy"); err != nil {
+		t.Fatal(err)
+	}
+	if st := cs.FindJob("deploy").State; st.LastStatus != "error" {
+		t.Errorf("missing workdir should fail the run, got %+v", st)
+	}
+}
+
+func TestAddJob_ValidatesEnvAndWorkDir(t *testing.T) {
+	cs, _ := newTestCronService(t)
+	bad := []*agent.CronJob{
+		{ID: "a", Schedule: "@every 1h", Command: "true", WorkDir: "../outside"},
+		{ID: "b", Schedule: "@every 1h", Command: "true", WorkDir: "/etc"},
+		{ID: "c", Schedule: "@every 1h", Command: "true", Env: map[string]string{"BAD-NAME": "x"}},
+	}
+	for _, job := range bad {
+		if err := cs.AddJob(job); err == nil {
+			t.Errorf("AddJob(%+v) should fail", job)
+		}
+	}
+
+	if err := cs.AddJob(&agent.CronJob{ID: "ok", Schedule: "@every 1h", Command: "true", Env: map[string]string{"A": "1"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cs.UpdateJob("ok", func(j *agent.CronJob) { j.Env["B-"] = "2" }); err == nil {
+		t.Error("UpdateJob() with an invalid env name should fail")
+	}
+	if env := cs.FindJob("ok").Env; len(env) != 1 {
+		t.Errorf("failed update should not touch the job's env, got %v", env)
+	}
+}
@@ -0,0 +1,53 @@
+package agent_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/providers"
+)
+
+func TestDebugCommand_MirrorsToolCalls(t *testing.T) {
+	provider := &mockProvider{responses: []providers.ChatResponse{
+		readFileCall("call_1", "notes.txt"),
+		{Content: "Your notes are empty."},
+		readFileCall("call_2", "notes.txt"),
+		{Content: "Still empty."},
+	}}
+	nc, msgBus := newTestAgent(t, provider)
+	send := func(content string) []bus.OutboundMessage {
+		nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: content})
+		return drainOutbound(msgBus)
+	}
+
+	out := send("/debug on")
+	if len(provider.requests) != 0 || len(out) != 1 || !strings.Contains(out[0].Content, "Debug mode on") {
+		t.Fatalf("/debug on should answer without the model, got %d requests and %+v", len(provider.requests), out)
+	}
+	if out := send("/debug"); len(out) != 1 || !strings.Contains(out[0].Content, "is on") {
+		t.Errorf("expected the status, got %+v", out)
+	}
+
+	out = send("read my notes")
+	var trace, summary bool
+	for _, m := range out {
+		if strings.HasPrefix(m.Content, "🐞 read_file") && strings.Contains(m.Content, `{"path":"notes.txt"}`) && strings.Contains(m.Content, "→ ") {
+			trace = true
+		}
+		if strings.HasPrefix(m.Content, "🐞 Run finished") && strings.Contains(m.Content, "2 model call(s), 1 tool call(s)") {
+			summary = true
+		}
+	}
+	if !trace || !summary {
+		t.Errorf("expected a tool call line and a summary, got %+v", out)
+	}
+
+	send("/debug@littleclaw_bot off")
+	for _, m := range send("read them again") {
+		if strings.HasPrefix(m.Content, "🐞") {
+			t.Errorf("unexpected debug output after /debug off: %q", m.Content)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package agent_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/events"
+	"littleclaw/pkg/providers"
+)
+
+func TestEvents_ToolExecuted(t *testing.T) {
+	provider := &mockProvider{responses: []providers.ChatResponse{
+		readFileCall("call_1", "notes.txt"),
+		{Content: "done"},
+	}}
+	nc, _ := newTestAgent(t, provider)
+	if err := os.WriteFile(filepath.Join(filepath.Dir(nc.MemoryStore().MemoryDir()), "notes.txt"), []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var got []events.ToolExecuted
+	events.Subscribe(nc.Events(), func(e events.ToolExecuted) { got = append(got, e) })
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "read notes.txt"})
+
+	if len(got) != 1 || got[0].Tool != "read_file" || got[0].ChatID != "user123" || got[0].Failed {
+		t.Errorf("expected one successful read_file event for user123, got %+v", got)
+	}
+}
+
+func TestEvents_ProviderError(t *testing.T) {
+	nc, _ := newTestAgent(t, failingProvider{})
+	var got []events.ProviderError
+	events.Subscribe(nc.Events(), func(e events.ProviderError) { got = append(got, e) })
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "hi"})
+
+	if len(got) == 0 || got[0].Provider != "failing" || got[0].Model != "test-model" || got[0].Err == nil {
+		t.Errorf("expected a provider error event, got %+v", got)
+	}
+}
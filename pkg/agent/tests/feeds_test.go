@@ -0,0 +1,131 @@
+package agent_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"littleclaw/pkg/agent"
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/providers"
+)
+
+// testFeedServer serves an RSS feed whose items can be changed between polls.
+type testFeedServer struct {
+	mu    sync.Mutex
+	items []string
+}
+
+func (s *testFeedServer) set(items ...string) {
+	s.mu.Lock()
+	s.items = items
+	s.mu.Unlock()
+}
+
+func (s *testFeedServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var sb strings.Builder
+	sb.WriteString(`<rss version="2.0"><channel><title>Test Feed</title>`)
+	for _, id := range s.items {
+		sb.WriteString(fmt.Sprintf(`<item><title>Story %s</title><link>https://example.com/%s</link><guid>%s</guid></item>`, id, id, id))
+	}
+	sb.WriteString(`</channel></rss>`)
+	_, _ = w.Write([]byte(sb.String()))
+}
+
+func TestFeedService_DeliversOnlyNewItems(t *testing.T) {
+	fsrv := &testFeedServer{}
+	fsrv.set("a", "b")
+	srv := httptest.NewServer(fsrv)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	msgBus := bus.NewMessageBus()
+	svc := agent.NewFeedService(dir, msgBus)
+
+	sub, err := svc.Subscribe(context.Background(), srv.URL, "user123", "telegram")
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	if sub.Title != "Test Feed" || len(sub.Seen) != 2 {
+		t.Errorf("unexpected subscription %+v", sub)
+	}
+	if _, err := svc.Subscribe(context.Background(), srv.URL, "user123", "telegram"); err == nil {
+		t.Error("duplicate subscription should be rejected")
+	}
+
+	if n := svc.Poll(context.Background()); n != 0 {
+		t.Errorf("existing items must not be delivered, got %d", n)
+	}
+
+	fsrv.set("c", "a", "b")
+	if n := svc.Poll(context.Background()); n != 1 {
+		t.Fatalf("expected 1 new item, got %d", n)
+	}
+	msgs := drainOutbound(msgBus)
+	if len(msgs) != 1 || msgs[0].ChatID != "user123" || !strings.Contains(msgs[0].Content, "Story c") || strings.Contains(msgs[0].Content, "Story a") {
+		t.Errorf("unexpected digest %+v", msgs)
+	}
+
+	// Subscriptions and seen items survive a restart
+	reloaded := agent.NewFeedService(dir, msgBus)
+	if n := reloaded.Poll(context.Background()); n != 0 {
+		t.Errorf("reloaded service re-delivered %d item(s)", n)
+	}
+	if _, err := reloaded.Unsubscribe("Test Feed"); err != nil || len(reloaded.List()) != 0 {
+		t.Errorf("unsubscribe by title failed: %v", err)
+	}
+}
+
+func TestFeedService_RecordsErrors(t *testing.T) {
+	fsrv := &testFeedServer{}
+	srv := httptest.NewServer(fsrv)
+	svc := agent.NewFeedService(t.TempDir(), bus.NewMessageBus())
+	if _, err := svc.Subscribe(context.Background(), srv.URL, "user123", "telegram"); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	srv.Close()
+
+	svc.Poll(context.Background())
+	if subs := svc.List(); len(subs) != 1 || subs[0].LastError == "" {
+		t.Errorf("expected the fetch error to be recorded, got %+v", subs)
+	}
+}
+
+func TestSubscribeFeedTool(t *testing.T) {
+	fsrv := &testFeedServer{}
+	fsrv.set("x")
+	srv := httptest.NewServer(fsrv)
+	defer srv.Close()
+
+	provider := &mockProvider{responses: []providers.ChatResponse{
+		{ToolCalls: []map[string]interface{}{{
+			"id": "call_1",
+			"function": map[string]interface{}{
+				"name":      "subscribe_feed",
+				"arguments": fmt.Sprintf(`{"url": %q}`, srv.URL),
+			},
+		}}},
+		{Content: "Subscribed!"},
+	}}
+	nc, _ := newTestAgent(t, provider)
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "follow this feed"})
+
+	if len(provider.requests) < 2 {
+		t.Fatalf("expected a follow-up request after the tool call, got %d", len(provider.requests))
+	}
+	var toolResult string
+	for _, m := range provider.requests[1].Messages {
+		if m.Role == "tool" {
+			toolResult = m.Content
+		}
+	}
+	if !strings.Contains(toolResult, "Subscribed to 'Test Feed'") {
+		t.Errorf("unexpected tool result %q", toolResult)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"littleclaw/pkg/bus"
 	"littleclaw/pkg/providers"
 )
 
@@ -229,3 +230,203 @@ func TestHeartbeat_TriggersSummarizationWhenLogLarge(t *testing.T) {
 		t.Error("expected NeedsSummarization=false for fresh temp store with no yesterday log")
 	}
 }
+
+// TestQuietHours_Contains checks plain and midnight-wrapping windows.
+func TestQuietHours_Contains(t *testing.T) {
+	at := func(h, m int) time.Time { return time.Date(2026, 3, 1, h, m, 0, 0, time.Local) }
+
+	night, err := agent.ParseQuietHours("23:00", "07:00")
+	if err != nil {
+		t.Fatalf("ParseQuietHours() error = %v", err)
+	}
+	for _, tc := range []struct {
+		t    time.Time
+		want bool
+	}{
+		{at(23, 0), true}, {at(2, 30), true}, {at(6, 59), true}, {at(7, 0), false}, {at(12, 0), false},
+	} {
+		if got := night.Contains(tc.t); got != tc.want {
+			t.Errorf("23:00-07:00 Contains(%s) = %v, want %v", tc.t.Format("15:04"), got, tc.want)
+		}
+	}
+
+	lunch, _ := agent.ParseQuietHours("12:00", "13:30")
+	if !lunch.Contains(at(13, 29)) || lunch.Contains(at(13, 30)) || lunch.Contains(at(11, 59)) {
+		t.Error("12:00-13:30 window is wrong")
+	}
+	if (agent.QuietHours{}).Contains(at(3, 0)) {
+		t.Error("zero QuietHours should be disabled")
+	}
+	for _, bad := range [][2]string{{"25:00", "07:00"}, {"23:00", "7am"}, {"08:00", "08:00"}} {
+		if _, err := agent.ParseQuietHours(bad[0], bad[1]); err == nil {
+			t.Errorf("ParseQuietHours(%q, %q) should fail", bad[0], bad[1])
+		}
+	}
+}
+
+// TestHeartbeat_SkipsTickDuringQuietHours verifies no LLM call is made in
+// quiet hours and the dirty flag survives for a later tick.
+func TestHeartbeat_SkipsTickDuringQuietHours(t *testing.T) {
+	provider := &mockProvider{responses: []providers.ChatResponse{{Content: "Memory consolidated."}}}
+	nc, _ := newTestAgent(t, provider)
+	_ = nc.MemoryStore().AppendHistory("user", "some new content")
+
+	now := time.Now()
+	quiet, err := agent.ParseQuietHours(now.Add(-time.Hour).Format("15:04"), now.Add(time.Hour).Format("15:04"))
+	if err != nil {
+		t.Fatalf("ParseQuietHours() error = %v", err)
+	}
+	nc.SetQuietHours(quiet)
+
+	hb := agent.NewHeartbeat(nc, time.Hour)
+	hb.Tick(context.Background())
+	if provider.callIndex != 0 {
+		t.Fatalf("expected no LLM calls during quiet hours, got %d", provider.callIndex)
+	}
+
+	nc.SetQuietHours(agent.QuietHours{})
+	hb.Tick(context.Background())
+	if provider.callIndex == 0 {
+		t.Error("expected consolidation once quiet hours are over")
+	}
+}
+
+// TestHeartbeat_TaskCadence verifies registered tasks run in order and only
+// when their cadence is due.
+func TestHeartbeat_TaskCadence(t *testing.T) {
+	nc, _ := newTestAgent(t, &mockProvider{})
+	hb := agent.NewHeartbeat(nc, time.Hour)
+
+	var hourly, everyTick int
+	hb.Register("hourly", time.Hour, func(context.Context) { hourly++ })
+	hb.Register("every_tick", 0, func(context.Context) { everyTick++ })
+
+	want := "summarization,consolidation,pre_compaction,hourly,every_tick"
+	if got := strings.Join(hb.Tasks(), ","); got != want {
+		t.Errorf("Tasks() = %s, want %s", got, want)
+	}
+
+	hb.Tick(context.Background())
+	hb.Tick(context.Background())
+	if hourly != 1 || everyTick != 2 {
+		t.Errorf("hourly ran %d times, every_tick %d times; want 1 and 2", hourly, everyTick)
+	}
+
+	if !hb.Unregister("hourly") || hb.Unregister("hourly") {
+		t.Error("Unregister should remove the task once")
+	}
+}
+
+// TestHeartbeat_RegisterPrompt checks built-in and custom prompt routines.
+func TestHeartbeat_RegisterPrompt(t *testing.T) {
+	provider := &mockProvider{responses: []providers.ChatResponse{{Content: "done"}}}
+	nc, _ := newTestAgent(t, provider)
+	hb := agent.NewHeartbeat(nc, time.Hour)
+
+	if err := hb.RegisterPrompt("standup", 0, "", false); err == nil {
+		t.Error("expected an error for an unknown built-in without a prompt")
+	}
+	if err := hb.RegisterPrompt("journal", 24*time.Hour, "", false); err != nil {
+		t.Fatalf("RegisterPrompt(journal) error = %v", err)
+	}
+	for _, name := range []string{"summarization", "consolidation", "pre_compaction"} {
+		hb.Unregister(name)
+	}
+
+	hb.Tick(context.Background())
+	if len(provider.requests) != 1 {
+		t.Fatalf("expected the journal task to call the LLM once, got %d", len(provider.requests))
+	}
+	msgs := provider.requests[0].Messages
+	if last := msgs[len(msgs)-1].Content; !strings.Contains(last, "[SYSTEM HEARTBEAT TASK: journal]") || !strings.Contains(last, "journal/"+time.Now().Format("2006-01-02")+".md") {
+		t.Errorf("unexpected journal prompt: %q", last)
+	}
+}
+
+// TestHeartbeat_CheckInNotifiesUser verifies check_in replies reach the last
+// user chat and NO_CHECKIN replies stay silent.
+func TestHeartbeat_CheckInNotifiesUser(t *testing.T) {
+	provider := &mockProvider{responses: []providers.ChatResponse{
+		{Content: "Noted."},
+		{Content: "NO_CHECKIN"},
+		{Content: "How did the dentist appointment go?"},
+	}}
+	nc, msgBus := newTestAgent(t, provider)
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "dentist at 3pm"})
+	drainOutbound(msgBus)
+
+	hb := agent.NewHeartbeat(nc, time.Hour)
+	for _, name := range []string{"summarization", "consolidation", "pre_compaction"} {
+		hb.Unregister(name)
+	}
+	if err := hb.RegisterPrompt("check_in", 0, "", false); err != nil {
+		t.Fatalf("RegisterPrompt(check_in) error = %v", err)
+	}
+
+	hb.Tick(context.Background())
+	if out := drainOutbound(msgBus); len(out) != 0 {
+		t.Errorf("NO_CHECKIN should not be sent, got %+v", out)
+	}
+
+	hb.Tick(context.Background())
+	out := drainOutbound(msgBus)
+	if len(out) != 1 || out[0].ChatID != "user123" || out[0].Content != "How did the dentist appointment go?" {
+		t.Errorf("expected a check-in to user123, got %+v", out)
+	}
+}
+
+// TestHeartbeat_FollowUps verifies a follow-up on an open loop reaches the
+// user once and the daily cap stops further LLM calls.
+func TestHeartbeat_FollowUps(t *testing.T) {
+	provider := &mockProvider{responses: []providers.ChatResponse{
+		{Content: "Sure."},
+		{Content: "Did you get to call the dentist?"},
+	}}
+	nc, msgBus := newTestAgent(t, provider)
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "remind me later to call the dentist"})
+	drainOutbound(msgBus)
+
+	hb := agent.NewHeartbeat(nc, time.Hour)
+	for _, name := range []string{"summarization", "consolidation", "pre_compaction"} {
+		hb.Unregister(name)
+	}
+	hb.EnableFollowUps(agent.FollowUpPolicy{Every: time.Nanosecond, MaxPerDay: 1, IdleFor: time.Nanosecond})
+
+	hb.Tick(context.Background())
+	out := drainOutbound(msgBus)
+	if len(out) != 1 || out[0].ChatID != "user123" || out[0].Content != "Did you get to call the dentist?" {
+		t.Fatalf("expected a follow-up to user123, got %+v", out)
+	}
+	req := provider.requests[1]
+	if last := req.Messages[len(req.Messages)-1].Content; len(req.Tools) != 0 || !strings.Contains(last, "[SYSTEM FOLLOW-UP CHECK]") || !strings.Contains(last, "call the dentist") {
+		t.Errorf("unexpected follow-up request: %q", last)
+	}
+
+	hb.Tick(context.Background())
+	if len(provider.requests) != 2 || len(drainOutbound(msgBus)) != 0 {
+		t.Errorf("the daily cap should stop the second follow-up, got %d requests", len(provider.requests))
+	}
+}
+
+// TestHeartbeat_FollowUpsNeedOpenLoops verifies no LLM call is made when
+// nothing looks unfinished or the user is active.
+func TestHeartbeat_FollowUpsNeedOpenLoops(t *testing.T) {
+	provider := &mockProvider{responses: []providers.ChatResponse{{Content: "Hi there."}}}
+	nc, _ := newTestAgent(t, provider)
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "hello"})
+
+	hb := agent.NewHeartbeat(nc, time.Hour)
+	for _, name := range []string{"summarization", "consolidation", "pre_compaction"} {
+		hb.Unregister(name)
+	}
+	hb.EnableFollowUps(agent.FollowUpPolicy{Every: time.Nanosecond, IdleFor: time.Nanosecond})
+	hb.Tick(context.Background())
+
+	_ = nc.MemoryStore().AppendHistory("USER", "remind me later about taxes")
+	hb.EnableFollowUps(agent.FollowUpPolicy{Every: time.Nanosecond}) // user active within the default 30m
+	hb.Tick(context.Background())
+
+	if len(provider.requests) != 1 {
+		t.Errorf("expected no follow-up LLM call, got %d requests", len(provider.requests))
+	}
+}
@@ -35,6 +35,10 @@ func (m *mockProvider) Chat(ctx context.Context, req providers.ChatRequest) (*pr
 
 func (m *mockProvider) Name() string { return "mock" }
 
+func (m *mockProvider) ListModels(ctx context.Context) ([]providers.ModelInfo, error) {
+	return nil, nil
+}
+
 // newTestAgent creates a agent.NanoCore backed by a temp directory and a mock provider.
 func newTestAgent(t *testing.T, provider providers.Provider) (*agent.NanoCore, *bus.MessageBus) {
 	t.Helper()
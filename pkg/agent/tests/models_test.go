@@ -0,0 +1,69 @@
+package agent_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/providers"
+)
+
+// modelsProvider is a mockProvider that lists models, or fails to.
+type modelsProvider struct {
+	*mockProvider
+	models []providers.ModelInfo
+	err    error
+}
+
+func (p *modelsProvider) ListModels(ctx context.Context) ([]providers.ModelInfo, error) {
+	return p.models, p.err
+}
+
+func TestListModels_MarksCurrentAndFilters(t *testing.T) {
+	provider := &modelsProvider{
+		mockProvider: &mockProvider{responses: []providers.ChatResponse{
+			backgroundToolCall("call_1", "list_models", `{}`),
+			{Content: "Here they are."},
+			backgroundToolCall("call_2", "list_models", `{"filter": "LLAMA"}`),
+			{Content: "One llama."},
+		}},
+		models: []providers.ModelInfo{
+			{ID: "gpt-4o", Description: "openai", ContextLength: 128000},
+			{ID: "meta/llama-3", Description: "Meta: Llama 3"},
+			{ID: "test-model"},
+		},
+	}
+	nc, _ := newTestAgent(t, provider)
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "which models can you use?"})
+	got := lastToolResult(provider.requests[1])
+	for _, want := range []string{"3 mock models (current: test-model)", "- test-model [current]", "- gpt-4o — openai (128k context)"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("list_models result missing %q:\n%s", want, got)
+		}
+	}
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "any llamas?"})
+	got = lastToolResult(provider.requests[3])
+	if !strings.Contains(got, "meta/llama-3") || strings.Contains(got, "gpt-4o") {
+		t.Errorf("filtered result = %q", got)
+	}
+}
+
+func TestListModels_ReportsProviderError(t *testing.T) {
+	provider := &modelsProvider{
+		mockProvider: &mockProvider{responses: []providers.ChatResponse{
+			backgroundToolCall("call_1", "list_models", `{}`),
+			{Content: "Couldn't list them."},
+		}},
+		err: errors.New("API error 401: bad key"),
+	}
+	nc, _ := newTestAgent(t, provider)
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "list models"})
+	if got := lastToolResult(provider.requests[1]); !strings.Contains(got, "Error listing mock models: API error 401") {
+		t.Errorf("result = %q", got)
+	}
+}
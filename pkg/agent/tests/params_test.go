@@ -0,0 +1,154 @@
+package agent_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"littleclaw/pkg/agent"
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/providers"
+)
+
+// loopingProvider always asks for another tool call.
+type loopingProvider struct {
+	requests []providers.ChatRequest
+}
+
+func (p *loopingProvider) Chat(ctx context.Context, req providers.ChatRequest) (*providers.ChatResponse, error) {
+	p.requests = append(p.requests, req)
+	return &providers.ChatResponse{ToolCalls: []map[string]interface{}{{
+		"id": "call_1",
+		"function": map[string]interface{}{
+			"name":      "read_core_memory",
+			"arguments": "{}",
+		},
+	}}}, nil
+}
+
+func (p *loopingProvider) Name() string { return "looping" }
+
+func (p *loopingProvider) ListModels(ctx context.Context) ([]providers.ModelInfo, error) {
+	return nil, nil
+}
+
+func TestAgentParams_DefaultsAndPerChatOverrides(t *testing.T) {
+	provider := &loopingProvider{}
+	nc, _ := newTestAgent(t, provider)
+	ctx := context.Background()
+
+	nc.RunAgentLoop(ctx, bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "hi"})
+	if len(provider.requests) != agent.DefaultMaxIterations || provider.requests[0].Temperature != agent.DefaultTemperature {
+		t.Fatalf("expected %d requests at temperature %v, got %d at %v",
+			agent.DefaultMaxIterations, agent.DefaultTemperature, len(provider.requests), provider.requests[0].Temperature)
+	}
+
+	cool, hot := 0.2, 1.1
+	nc.SetAgentParams(agent.AgentParams{MaxIterations: 3, Temperature: &cool})
+	nc.SetChatParams("power_user", agent.AgentParams{MaxIterations: 5, Temperature: &hot, MaxTokens: 4096})
+
+	provider.requests = nil
+	nc.RunAgentLoop(ctx, bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "hi"})
+	if len(provider.requests) != 3 || provider.requests[0].Temperature != cool || provider.requests[0].MaxTokens != 0 {
+		t.Errorf("expected 3 requests at %v with no max_tokens, got %d: %+v", cool, len(provider.requests), provider.requests[0])
+	}
+
+	provider.requests = nil
+	nc.RunAgentLoop(ctx, bus.InboundMessage{ChatID: "power_user", Channel: "telegram", Content: "hi"})
+	if len(provider.requests) != 5 || provider.requests[0].Temperature != hot || provider.requests[0].MaxTokens != 4096 {
+		t.Errorf("expected the per-chat override, got %d requests: %+v", len(provider.requests), provider.requests[0])
+	}
+}
+
+func TestAgentParams_ContextWindowOverride(t *testing.T) {
+	nc, _ := newTestAgent(t, &mockProvider{})
+	nc.SetAgentParams(agent.AgentParams{ContextWindow: 1000})
+	nc.LastPromptTokens = 900
+	if !nc.IsApproachingContextLimit() {
+		t.Error("a configured context window should drive pre-compaction")
+	}
+}
+
+// budgetProvider keeps calling tools at 600 tokens a round until it is asked
+// without tools, then answers with 800 tokens.
+type budgetProvider struct {
+	requests []providers.ChatRequest
+}
+
+func (p *budgetProvider) Chat(ctx context.Context, req providers.ChatRequest) (*providers.ChatResponse, error) {
+	p.requests = append(p.requests, req)
+	if len(req.Tools) == 0 {
+		return &providers.ChatResponse{Content: "Partial answer from what I found.",
+			Usage: providers.Usage{PromptTokens: 700, CompletionTokens: 100, TotalTokens: 800}}, nil
+	}
+	resp, _ := (&loopingProvider{}).Chat(ctx, req)
+	resp.Usage = providers.Usage{PromptTokens: 500, CompletionTokens: 100, TotalTokens: 600}
+	return resp, nil
+}
+
+func (p *budgetProvider) Name() string { return "budget" }
+
+func (p *budgetProvider) ListModels(ctx context.Context) ([]providers.ModelInfo, error) {
+	return nil, nil
+}
+
+func TestAgentParams_TokenBudgetWrapsUp(t *testing.T) {
+	provider := &budgetProvider{}
+	nc, msgBus := newTestAgent(t, provider)
+	nc.SetAgentParams(agent.AgentParams{TokenBudget: 1000, PromptPrice: 3, CompletionPrice: 15})
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "research this"})
+
+	// Two tool rounds reach the budget, then one tool-less wrap-up call
+	if len(provider.requests) != 3 {
+		t.Fatalf("expected 3 requests, got %d", len(provider.requests))
+	}
+	if len(provider.requests[2].Tools) != 0 {
+		t.Error("the wrap-up call should offer no tools")
+	}
+
+	var reply string
+	for _, out := range drainOutbound(msgBus) {
+		reply = out.Content
+	}
+	// The wrap-up call counts toward the run's usage too
+	if !strings.Contains(reply, "Partial answer") || !strings.Contains(reply, "Stopped early") || !strings.Contains(reply, "2000 tokens (~$0.0096)") {
+		t.Errorf("expected the wrap-up answer with a budget notice, got %q", reply)
+	}
+}
+
+func TestSetModel_SwitchesProviderForNewRuns(t *testing.T) {
+	first := &mockProvider{responses: []providers.ChatResponse{{Content: "from first"}}}
+	nc, _ := newTestAgent(t, first)
+
+	second := &mockProvider{responses: []providers.ChatResponse{{Content: "from second"}}}
+	nc.SetModel(second, "openai", "gpt-4o")
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "hi"})
+
+	if len(first.requests) != 0 {
+		t.Errorf("expected the old provider to get no requests, got %d", len(first.requests))
+	}
+	if len(second.requests) != 1 || second.requests[0].Model != "gpt-4o" {
+		t.Fatalf("expected one gpt-4o request to the new provider, got %+v", second.requests)
+	}
+}
+
+func TestAgentParams_EntityTopK(t *testing.T) {
+	provider := &mockProvider{}
+	nc, _ := newTestAgent(t, provider)
+	_ = nc.MemoryStore().WriteEntity("Project Phoenix", "Billing migration to Postgres, due in March.")
+	ask := func(chatID string) string {
+		provider.requests = nil
+		nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: chatID, Channel: "telegram", Content: "when is the postgres migration due?"})
+		return provider.requests[0].Messages[0].Content
+	}
+
+	if prompt := ask("user123"); !strings.Contains(prompt, "[Entity: project_phoenix") {
+		t.Errorf("expected the matching entity in the system prompt, got:\n%s", prompt)
+	}
+	none := 0
+	nc.SetChatParams("quiet", agent.AgentParams{EntityTopK: &none})
+	if prompt := ask("quiet"); strings.Contains(prompt, "RELEVANT ENTITY CONTEXT") {
+		t.Error("entity_top_k 0 should leave entities out")
+	}
+}
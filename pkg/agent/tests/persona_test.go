@@ -0,0 +1,58 @@
+package agent_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/providers"
+)
+
+func TestSetPersona_ChangesSystemPrompt(t *testing.T) {
+	provider := &mockProvider{responses: []providers.ChatResponse{
+		{ToolCalls: []map[string]interface{}{
+			{
+				"id": "call_1",
+				"function": map[string]interface{}{
+					"name":      "set_persona",
+					"arguments": `{"name": "Jarvis", "language": "German", "instructions": "Always give prices in EUR."}`,
+				},
+			},
+		}},
+		{Content: "Done."},
+	}}
+	nc, _ := newTestAgent(t, provider)
+
+	if prompt := nc.BuildSystemPromptWithQuery(""); !strings.Contains(prompt, "You are Littleclaw") {
+		t.Fatalf("expected the default persona, got prompt without it")
+	}
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "call yourself Jarvis and speak German"})
+
+	prompt := nc.BuildSystemPromptWithQuery("")
+	for _, want := range []string{"You are Jarvis", "Reply in German", "Always give prices in EUR.", "TONE: Use extremely simple"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("system prompt missing %q", want)
+		}
+	}
+
+	// Clearing a field keeps the rest
+	provider.responses = append(provider.responses,
+		providers.ChatResponse{ToolCalls: []map[string]interface{}{
+			{
+				"id": "call_2",
+				"function": map[string]interface{}{
+					"name":      "set_persona",
+					"arguments": `{"language": "none"}`,
+				},
+			},
+		}},
+		providers.ChatResponse{Content: "Ok."},
+	)
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "reply in any language"})
+	prompt = nc.BuildSystemPromptWithQuery("")
+	if strings.Contains(prompt, "Reply in German") || !strings.Contains(prompt, "You are Jarvis") {
+		t.Errorf("expected language cleared and name kept")
+	}
+}
@@ -0,0 +1,51 @@
+package agent_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"littleclaw/pkg/agent"
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/providers"
+)
+
+func echoThenAnswer() *mockProvider {
+	return &mockProvider{responses: []providers.ChatResponse{
+		{ToolCalls: []map[string]interface{}{{
+			"id": "call_1",
+			"function": map[string]interface{}{
+				"name":      "exec",
+				"arguments": `{"command": "echo hi"}`,
+			},
+		}}},
+		{Content: "Done."},
+	}}
+}
+
+func TestProgress_VerboseReportsEachTool(t *testing.T) {
+	nc, msgBus := newTestAgent(t, echoThenAnswer())
+	nc.SetAgentParams(agent.AgentParams{Progress: agent.ProgressVerbose})
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "say hi"})
+
+	out := drainOutbound(msgBus)
+	if len(out) == 0 || out[0].Content != "🛠 Running `echo hi`…" {
+		t.Fatalf("expected a status message first, got %+v", out)
+	}
+}
+
+func TestProgress_QuietForShortRuns(t *testing.T) {
+	for _, level := range []string{"", agent.ProgressOff} {
+		nc, msgBus := newTestAgent(t, echoThenAnswer())
+		nc.SetAgentParams(agent.AgentParams{Progress: level})
+
+		nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "say hi"})
+
+		for _, m := range drainOutbound(msgBus) {
+			if strings.HasPrefix(m.Content, "🛠 Running") {
+				t.Errorf("progress %q: unexpected status %q for a short run", level, m.Content)
+			}
+		}
+	}
+}
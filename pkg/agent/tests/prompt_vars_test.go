@@ -0,0 +1,58 @@
+package agent_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/providers"
+)
+
+func TestSystemPrompt_CurrentContext(t *testing.T) {
+	provider := &mockProvider{responses: []providers.ChatResponse{{Content: "Hi Ada."}}}
+	nc, _ := newTestAgent(t, provider)
+	loc := time.FixedZone("Test/Zone", 2*3600)
+	nc.SetTimezone(loc)
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", SenderName: "Ada", Content: "what day is it?"})
+
+	prompt := provider.requests[0].Messages[0].Content
+	now := time.Now().In(loc)
+	for _, want := range []string{
+		"=== CURRENT CONTEXT ===",
+		now.Weekday().String() + " " + now.Format("2006-01-02"),
+		"(Test/Zone (UTC+02:00))",
+		"Channel: telegram | User: Ada",
+		"Workspace: /",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("system prompt missing %q", want)
+		}
+	}
+}
+
+func TestSetContextTemplate(t *testing.T) {
+	nc, _ := newTestAgent(t, &mockProvider{})
+
+	if err := nc.SetContextTemplate("Today is {{.Date"); err == nil {
+		t.Error("expected an error for a malformed template")
+	}
+
+	if err := nc.SetContextTemplate("TODAY={{.Date}} TZ={{.Timezone}}\n"); err != nil {
+		t.Fatalf("SetContextTemplate() error = %v", err)
+	}
+	prompt := nc.BuildSystemPromptWithQuery("")
+	if !strings.Contains(prompt, "TODAY="+time.Now().Format("2006-01-02")) || strings.Contains(prompt, "=== CURRENT CONTEXT ===") {
+		t.Errorf("expected the custom context block only, got:\n%s", prompt)
+	}
+
+	// A template that fails at run time falls back to the default block
+	if err := nc.SetContextTemplate("{{.Missing}}"); err != nil {
+		t.Fatalf("SetContextTemplate() error = %v", err)
+	}
+	if prompt := nc.BuildSystemPromptWithQuery(""); !strings.Contains(prompt, "=== CURRENT CONTEXT ===") {
+		t.Error("expected the default context block after a failing template")
+	}
+}
@@ -0,0 +1,129 @@
+package agent_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"littleclaw/pkg/agent"
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/providers"
+)
+
+// heldProvider answers once release is closed, signalling each call on started.
+type heldProvider struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (p *heldProvider) Chat(ctx context.Context, req providers.ChatRequest) (*providers.ChatResponse, error) {
+	p.started <- struct{}{}
+	<-p.release
+	return &providers.ChatResponse{Content: "done"}, nil
+}
+
+func (p *heldProvider) Name() string { return "mock" }
+
+func (p *heldProvider) ListModels(ctx context.Context) ([]providers.ModelInfo, error) {
+	return nil, nil
+}
+
+func TestRateLimits_MessagesPerMinute(t *testing.T) {
+	provider := &mockProvider{}
+	nc, msgBus := newTestAgent(t, provider)
+	nc.SetRateLimits(agent.RateLimitPolicy{MessagesPerMinute: 2, Exempt: []string{"owner"}})
+	send := func(sender string) []bus.OutboundMessage {
+		nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "group", Channel: "telegram", SenderID: sender, Content: "hi"})
+		return drainOutbound(msgBus)
+	}
+
+	send("guest")
+	send("guest")
+	out := send("guest")
+	if len(out) != 1 || !strings.Contains(out[0].Content, "up to 2 messages a minute") {
+		t.Fatalf("expected a slow-down reply, got %+v", out)
+	}
+	if out := send("guest"); len(out) != 0 {
+		t.Errorf("a flood should be told only once, got %+v", out)
+	}
+	if len(provider.requests) != 2 {
+		t.Errorf("limited messages reached the model: %d requests", len(provider.requests))
+	}
+
+	for i := 0; i < 3; i++ {
+		send("owner")
+	}
+	if len(provider.requests) != 5 {
+		t.Errorf("exempt senders should not be limited, got %d requests", len(provider.requests))
+	}
+}
+
+func TestRateLimits_ConcurrentRuns(t *testing.T) {
+	provider := &heldProvider{started: make(chan struct{}, 1), release: make(chan struct{})}
+	nc, msgBus := newTestAgent(t, provider)
+	nc.SetRateLimits(agent.RateLimitPolicy{ConcurrentRuns: 1})
+
+	done := make(chan struct{})
+	go func() {
+		nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "group", Channel: "telegram", SenderID: "guest", Content: "slow question"})
+		close(done)
+	}()
+	<-provider.started
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "group", Channel: "telegram", SenderID: "guest", Content: "another one"})
+	out := drainOutbound(msgBus)
+	if len(out) != 1 || !strings.Contains(out[0].Content, "still working on your last request") {
+		t.Errorf("expected the second run to be refused, got %+v", out)
+	}
+
+	close(provider.release)
+	<-done
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "group", Channel: "telegram", SenderID: "guest", Content: "now?"})
+	select {
+	case <-provider.started:
+	case <-time.After(time.Second):
+		t.Error("a run should be allowed once the previous one finished")
+	}
+}
+
+func TestRateLimits_DailyTokens(t *testing.T) {
+	provider := &mockProvider{responses: []providers.ChatResponse{
+		{Content: "answer", Usage: providers.Usage{PromptTokens: 900, CompletionTokens: 200}},
+		{Content: "answer"},
+	}}
+	nc, msgBus := newTestAgent(t, provider)
+	nc.SetRateLimits(agent.RateLimitPolicy{DailyTokens: 1000})
+	send := func(sender string) []bus.OutboundMessage {
+		nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "group", Channel: "telegram", SenderID: sender, Content: "question"})
+		return drainOutbound(msgBus)
+	}
+
+	send("guest")
+	out := send("guest")
+	if len(out) != 1 || !strings.Contains(out[0].Content, "today's allowance of 1.0k tokens") {
+		t.Errorf("expected the daily allowance to be used up, got %+v", out)
+	}
+	send("other")
+	if len(provider.requests) != 2 {
+		t.Errorf("other senders have their own allowance, got %d requests", len(provider.requests))
+	}
+}
+
+func TestRateLimits_DailyTokensSurviveRestart(t *testing.T) {
+	provider := &mockProvider{}
+	nc, msgBus := newTestAgent(t, provider)
+	workspace := filepath.Dir(nc.MemoryStore().MemoryDir())
+	rec := `{"time":"` + time.Now().Format(time.RFC3339) + `","sender_id":"guest","provider":"mock","model":"m","calls":1,"prompt_tokens":4000,"completion_tokens":1000}` + "\n"
+	if err := os.WriteFile(filepath.Join(workspace, agent.UsageFile), []byte(rec), 0644); err != nil {
+		t.Fatal(err)
+	}
+	nc.SetRateLimits(agent.RateLimitPolicy{DailyTokens: 5000})
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "group", Channel: "telegram", SenderID: "guest", Content: "question"})
+	if out := drainOutbound(msgBus); len(out) != 1 || !strings.Contains(out[0].Content, "allowance") || len(provider.requests) != 0 {
+		t.Errorf("expected today's logged usage to count, got %+v and %d requests", out, len(provider.requests))
+	}
+}
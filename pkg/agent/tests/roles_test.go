@@ -0,0 +1,89 @@
+package agent_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"littleclaw/pkg/agent"
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/providers"
+)
+
+func delegateCall(args string) providers.ChatResponse {
+	return providers.ChatResponse{ToolCalls: []map[string]interface{}{
+		{
+			"id": "call_delegate",
+			"function": map[string]interface{}{
+				"name":      "delegate",
+				"arguments": args,
+			},
+		},
+	}}
+}
+
+func TestDelegate_RoutesToRole(t *testing.T) {
+	specialist := &mockProvider{responses: []providers.ChatResponse{{Content: "Found 3 results."}}}
+	provider := &mockProvider{responses: []providers.ChatResponse{
+		delegateCall(`{"agent": "researcher", "task": "Find three results."}`),
+		{Content: "The researcher found 3 results."},
+	}}
+	nc, msgBus := newTestAgent(t, provider)
+	nc.SetAgentRoles([]agent.AgentRole{
+		{Name: "researcher", Description: "web research", Provider: specialist, Model: "big-model", Tools: []string{"list_entities"}, Instructions: "Cite sources."},
+		{Name: "coder", Description: "writes scripts"},
+	})
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "research this"})
+
+	var delegateDef *providers.ToolDefinition
+	for _, def := range provider.requests[0].Tools {
+		if def.Function.Name == "delegate" {
+			delegateDef = &def
+		}
+	}
+	if delegateDef == nil || !strings.Contains(delegateDef.Function.Description, "- researcher: web research") {
+		t.Fatalf("expected delegate to list the roles, got %+v", delegateDef)
+	}
+
+	if len(specialist.requests) != 1 {
+		t.Fatalf("expected the role's provider to be used, got %d requests", len(specialist.requests))
+	}
+	req := specialist.requests[0]
+	if req.Model != "big-model" || len(req.Tools) != 1 || req.Tools[0].Function.Name != "list_entities" {
+		t.Errorf("expected the role's model and tools, got model %q with %d tools", req.Model, len(req.Tools))
+	}
+	if sys := req.Messages[0].Content; !strings.Contains(sys, "YOUR ROLE: researcher - web research") || !strings.Contains(sys, "Cite sources.") {
+		t.Errorf("expected the role prompt, got %q", sys)
+	}
+
+	var result string
+	for _, m := range provider.requests[1].Messages {
+		if m.Role == "tool" {
+			result = m.Content
+		}
+	}
+	if !strings.Contains(result, "[researcher done after 1 iteration(s)]") || !strings.Contains(result, "Found 3 results.") {
+		t.Errorf("expected the report as the tool result, got %q", result)
+	}
+	if out := drainOutbound(msgBus); len(out) != 1 || out[0].Content != "The researcher found 3 results." {
+		t.Errorf("expected only the main agent's reply, got %+v", out)
+	}
+}
+
+func TestDelegate_UnknownRole(t *testing.T) {
+	provider := &mockProvider{responses: []providers.ChatResponse{
+		delegateCall(`{"agent": "designer", "task": "Draw."}`),
+		{Content: "No such agent."},
+	}}
+	nc, _ := newTestAgent(t, provider)
+	nc.SetAgentRoles([]agent.AgentRole{{Name: "coder"}})
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "draw"})
+
+	for _, m := range provider.requests[1].Messages {
+		if m.Role == "tool" && !strings.Contains(m.Content, `unknown agent "designer" (available: coder)`) {
+			t.Errorf("unexpected result %q", m.Content)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package agent_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"littleclaw/pkg/agent"
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/providers"
+)
+
+func TestModelRouter_SimpleMessagesGoToCheapModel(t *testing.T) {
+	premium := &mockProvider{}
+	cheap := &mockProvider{}
+	nc, _ := newTestAgent(t, premium)
+	nc.SetModelRouter(agent.ModelRouter{Provider: cheap, Model: "cheap-model"})
+
+	for _, content := range []string{"hi there!", "thanks, that's great"} {
+		nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: content})
+	}
+	if len(cheap.requests) != 2 || len(premium.requests) != 0 {
+		t.Fatalf("cheap got %d requests, premium %d; want 2 and 0", len(cheap.requests), len(premium.requests))
+	}
+	if got := cheap.requests[0].Model; got != "cheap-model" {
+		t.Errorf("model = %q, want cheap-model", got)
+	}
+}
+
+func TestModelRouter_ComplexMessagesKeepChatModel(t *testing.T) {
+	for _, msg := range []bus.InboundMessage{
+		{Content: "Will I need an umbrella? Check the weather forecast."},
+		{Content: "can you summarize this for me"},
+		{Content: "what's on https://example.com"},
+		{Content: strings.Repeat("tell me more about that ", 20)},
+		{Content: "hi there!", Channel: "internal"},
+	} {
+		premium := &mockProvider{}
+		cheap := &mockProvider{}
+		nc, _ := newTestAgent(t, premium)
+		nc.SetModelRouter(agent.ModelRouter{Provider: cheap, Model: "cheap-model"})
+
+		msg.ChatID = "user123"
+		if msg.Channel == "" {
+			msg.Channel = "telegram"
+		}
+		nc.RunAgentLoop(context.Background(), msg)
+		if len(cheap.requests) != 0 || len(premium.requests) == 0 {
+			t.Errorf("%q: cheap got %d requests, premium %d; want only premium", msg.Content, len(cheap.requests), len(premium.requests))
+		}
+	}
+}
+
+func TestModelRouter_LongConversationKeepsChatModel(t *testing.T) {
+	premium := &mockProvider{responses: []providers.ChatResponse{{Content: strings.Repeat("a long answer ", 200)}}}
+	nc, _ := newTestAgent(t, premium)
+	nc.SetModelRouter(agent.ModelRouter{Model: "cheap-model", MaxContextTokens: 100})
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "hello"})
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "ok, thanks"})
+	if len(premium.requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(premium.requests))
+	}
+	// Without a router provider the chat provider serves the cheap model
+	if got := premium.requests[0].Model; got != "cheap-model" {
+		t.Errorf("first message model = %q, want cheap-model", got)
+	}
+	if got := premium.requests[1].Model; got != "test-model" {
+		t.Errorf("model after a long reply = %q, want test-model", got)
+	}
+}
+
+func TestModelRouter_ModelClassifier(t *testing.T) {
+	premium := &mockProvider{}
+	cheap := &mockProvider{responses: []providers.ChatResponse{
+		{Content: "COMPLEX"},
+		{Content: "SIMPLE"},
+		{Content: "Hey!"},
+	}}
+	nc, _ := newTestAgent(t, premium)
+	nc.SetModelRouter(agent.ModelRouter{Provider: cheap, Model: "cheap-model", UseModel: true})
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "who won the 1998 world cup final?"})
+	if len(cheap.requests) != 1 || len(premium.requests) != 1 {
+		t.Fatalf("after COMPLEX: cheap got %d requests, premium %d; want 1 and 1", len(cheap.requests), len(premium.requests))
+	}
+	if len(cheap.requests[0].Tools) != 0 {
+		t.Error("the classification request should carry no tools")
+	}
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "hey"})
+	if len(cheap.requests) != 3 || len(premium.requests) != 1 {
+		t.Errorf("after SIMPLE: cheap got %d requests, premium %d; want 3 and 1", len(cheap.requests), len(premium.requests))
+	}
+}
@@ -0,0 +1,148 @@
+package agent_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/providers"
+)
+
+func TestSession_ReusesPreviousTurnWithToolTrace(t *testing.T) {
+	provider := &mockProvider{responses: []providers.ChatResponse{
+		{ToolCalls: []map[string]interface{}{{
+			"id": "call_1",
+			"function": map[string]interface{}{
+				"name":      "write_file",
+				"arguments": `{"path": "notes/todo.txt", "content": "buy milk"}`,
+			},
+		}}},
+		{Content: "Saved it to notes/todo.txt."},
+		{Content: "It says: buy milk."},
+	}}
+	nc, _ := newTestAgent(t, provider)
+	ctx := context.Background()
+
+	nc.RunAgentLoop(ctx, bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "save 'buy milk' to my todo"})
+	nc.RunAgentLoop(ctx, bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "what did you write?"})
+
+	if len(provider.requests) != 3 {
+		t.Fatalf("expected 3 requests, got %d", len(provider.requests))
+	}
+	msgs := provider.requests[2].Messages
+	var roles []string
+	for _, m := range msgs[1:] {
+		roles = append(roles, m.Role)
+	}
+	if got := strings.Join(roles, ","); got != "user,assistant,tool,assistant,user" {
+		t.Errorf("expected the previous turn with its tool trace, got roles %s", got)
+	}
+	if msgs[len(msgs)-2].Content != "Saved it to notes/todo.txt." {
+		t.Errorf("previous reply should be kept verbatim, got %q", msgs[len(msgs)-2].Content)
+	}
+	for _, m := range msgs {
+		if strings.Contains(m.Content, "[System] Tool execution finished") {
+			t.Error("reflection prompts should not be kept in the session")
+		}
+	}
+	if strings.Contains(msgs[0].Content, "Recent Conversational History") {
+		t.Error("file history should be left out while a session is live")
+	}
+
+	// Other chats and reset sessions start from the file history again
+	nc.ResetSession("user123")
+	nc.RunAgentLoop(ctx, bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "hello again"})
+	msgs = provider.requests[3].Messages
+	if len(msgs) != 2 || !strings.Contains(msgs[0].Content, "Recent Conversational History") {
+		t.Errorf("expected a fresh context after reset, got %d messages", len(msgs))
+	}
+}
+
+func TestSession_ExpiresAndTrims(t *testing.T) {
+	provider := &mockProvider{}
+	nc, _ := newTestAgent(t, provider)
+	ctx := context.Background()
+
+	nc.SetSessionLimits(0, 4)
+	for i := 0; i < 4; i++ {
+		nc.RunAgentLoop(ctx, bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "ping"})
+	}
+	last := provider.requests[len(provider.requests)-1].Messages
+	if len(last) != 6 || last[1].Role != "user" {
+		t.Errorf("expected system + 4 session messages + user, got %d (first role %q)", len(last), last[1].Role)
+	}
+
+	nc.SetSessionLimits(time.Nanosecond, 0)
+	time.Sleep(time.Millisecond)
+	nc.RunAgentLoop(ctx, bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "ping"})
+	if last := provider.requests[len(provider.requests)-1].Messages; len(last) != 2 {
+		t.Errorf("expired session should not be reused, got %d messages", len(last))
+	}
+}
+
+func TestSession_InternalMessagesAreNotKept(t *testing.T) {
+	provider := &mockProvider{}
+	nc, _ := newTestAgent(t, provider)
+	ctx := context.Background()
+
+	nc.RunAgentLoop(ctx, bus.InboundMessage{ChatID: "internal_memory", Channel: "internal", Content: "consolidate"})
+	nc.RunAgentLoop(ctx, bus.InboundMessage{ChatID: "internal_memory", Channel: "internal", Content: "consolidate"})
+	if last := provider.requests[1].Messages; len(last) != 2 {
+		t.Errorf("internal loops should not build a session, got %d messages", len(last))
+	}
+}
+
+func TestNewCommand_ClearsSessionKeepsMemory(t *testing.T) {
+	provider := &mockProvider{responses: []providers.ChatResponse{
+		{Content: "Noted."},
+		{Content: "Hello!"},
+	}}
+	nc, msgBus := newTestAgent(t, provider)
+	ctx := context.Background()
+	_ = nc.MemoryStore().WriteLongTerm("- Likes green tea")
+
+	nc.RunAgentLoop(ctx, bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "my secret word is pineapple"})
+	drainOutbound(msgBus)
+
+	nc.RunAgentLoop(ctx, bus.InboundMessage{ChatID: "user123", Channel: "telegram", MessageID: 5, Content: "/new"})
+	if out := drainOutbound(msgBus); len(out) != 1 || !strings.Contains(out[0].Content, "Fresh start") {
+		t.Fatalf("expected the /new confirmation, got %+v", out)
+	}
+	if len(provider.requests) != 1 {
+		t.Fatalf("/new should not call the LLM, got %d requests", len(provider.requests))
+	}
+
+	nc.RunAgentLoop(ctx, bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "hi"})
+	msgs := provider.requests[1].Messages
+	if len(msgs) != 2 {
+		t.Errorf("expected no session after /new, got %d messages", len(msgs))
+	}
+	if strings.Contains(msgs[0].Content, "pineapple") || !strings.Contains(msgs[0].Content, "Likes green tea") {
+		t.Error("expected the recent history cleared and long-term memory kept")
+	}
+}
+
+func TestClearSessionTool_DropsTurn(t *testing.T) {
+	provider := &mockProvider{responses: []providers.ChatResponse{
+		{Content: "Noted."},
+		{ToolCalls: []map[string]interface{}{{
+			"id":       "call_1",
+			"function": map[string]interface{}{"name": "clear_session", "arguments": `{}`},
+		}}},
+		{Content: "Cleared."},
+		{Content: "Hi!"},
+	}}
+	nc, _ := newTestAgent(t, provider)
+	ctx := context.Background()
+
+	nc.RunAgentLoop(ctx, bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "my secret word is pineapple"})
+	nc.RunAgentLoop(ctx, bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "let's start over"})
+	nc.RunAgentLoop(ctx, bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "hi"})
+
+	msgs := provider.requests[3].Messages
+	if len(msgs) != 2 || strings.Contains(msgs[0].Content, "pineapple") {
+		t.Errorf("expected a fresh context after clear_session, got %d messages", len(msgs))
+	}
+}
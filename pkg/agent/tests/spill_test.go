@@ -0,0 +1,57 @@
+package agent_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"littleclaw/pkg/agent"
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/providers"
+)
+
+func TestLargeToolOutput_SpilledToFile(t *testing.T) {
+	provider := &mockProvider{responses: []providers.ChatResponse{
+		{ToolCalls: []map[string]interface{}{{
+			"id": "call_1",
+			"function": map[string]interface{}{
+				"name":      "exec",
+				"arguments": `{"command": "seq 1 20000"}`,
+			},
+		}}},
+		{Content: "Done."},
+	}}
+	dir := t.TempDir()
+	nc, err := agent.NewNanoCore(provider, "mock", "test-model", dir, bus.NewMessageBus(), "")
+	if err != nil {
+		t.Fatalf("agent.NewNanoCore() error = %v", err)
+	}
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "count"})
+
+	msgs := provider.requests[1].Messages
+	var toolMsg string
+	for _, m := range msgs {
+		if m.Role == "tool" {
+			toolMsg = m.Content
+		}
+	}
+	if len(toolMsg) > agent.MaxToolResultChars+500 || !strings.Contains(toolMsg, "\n1\n2\n3\n") || !strings.HasSuffix(strings.TrimSpace(toolMsg), "19999\n20000") {
+		t.Fatalf("expected a head/tail excerpt, got %d chars: %.200q", len(toolMsg), toolMsg)
+	}
+
+	m := regexp.MustCompile(`saved to (` + agent.SpillDir + `/\S+\.txt)`).FindStringSubmatch(toolMsg)
+	if m == nil {
+		t.Fatalf("expected the spill path in the tool result: %.300q", toolMsg)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, m[1]))
+	if err != nil {
+		t.Fatalf("spilled file: %v", err)
+	}
+	if !strings.Contains(string(data), "\n10000\n") || !strings.HasSuffix(strings.TrimSpace(string(data)), "\n20000") {
+		t.Errorf("spilled file does not hold the full output (%d bytes)", len(data))
+	}
+}
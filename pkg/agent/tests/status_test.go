@@ -0,0 +1,38 @@
+package agent_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/providers"
+)
+
+// failingProvider fails every call.
+type failingProvider struct{}
+
+func (failingProvider) Chat(ctx context.Context, req providers.ChatRequest) (*providers.ChatResponse, error) {
+	return nil, errors.New("503 upstream unavailable")
+}
+
+func (failingProvider) Name() string { return "failing" }
+
+func (failingProvider) ListModels(ctx context.Context) ([]providers.ModelInfo, error) {
+	return nil, nil
+}
+
+func TestStatus_ReportsModelAndLastProviderError(t *testing.T) {
+	nc, _ := newTestAgent(t, failingProvider{})
+
+	if s := nc.Status(); s.Model != "test-model" || s.LastProviderError != "" || s.ActiveRuns != 0 {
+		t.Fatalf("unexpected initial status %+v", s)
+	}
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "hi"})
+
+	s := nc.Status()
+	if s.LastProviderError != "503 upstream unavailable" || s.LastProviderErrorAt.IsZero() {
+		t.Errorf("expected the provider error in the status, got %+v", s)
+	}
+}
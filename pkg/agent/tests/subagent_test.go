@@ -0,0 +1,158 @@
+package agent_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/providers"
+)
+
+// subAgentProvider answers the main loop and sub-agents from separate scripts,
+// told apart by the system prompt, and is safe for concurrent use.
+type subAgentProvider struct {
+	mu       sync.Mutex
+	main     []providers.ChatResponse
+	sub      []providers.ChatResponse
+	subReqs  []providers.ChatRequest
+	mainReqs []providers.ChatRequest
+}
+
+func (p *subAgentProvider) Chat(ctx context.Context, req providers.ChatRequest) (*providers.ChatResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	script, reqs := &p.main, &p.mainReqs
+	if strings.HasPrefix(req.Messages[0].Content, "You are a sub-agent") {
+		script, reqs = &p.sub, &p.subReqs
+	}
+	*reqs = append(*reqs, req)
+	if len(*script) == 0 {
+		return &providers.ChatResponse{Content: "(mock exhausted)"}, nil
+	}
+	resp := (*script)[0]
+	*script = (*script)[1:]
+	return &resp, nil
+}
+
+func (p *subAgentProvider) Name() string { return "mock" }
+
+func (p *subAgentProvider) ListModels(ctx context.Context) ([]providers.ModelInfo, error) {
+	return nil, nil
+}
+
+func spawnCall(args string) providers.ChatResponse {
+	return providers.ChatResponse{ToolCalls: []map[string]interface{}{
+		{
+			"id": "call_spawn",
+			"function": map[string]interface{}{
+				"name":      "spawn",
+				"arguments": args,
+			},
+		},
+	}}
+}
+
+func TestSpawn_RunsSubAgentAndReportsBack(t *testing.T) {
+	provider := &subAgentProvider{
+		main: []providers.ChatResponse{
+			spawnCall(`{"task": "Count the entities and report.", "max_iterations": 3}`),
+			{Content: "I've started a sub-agent for that."},
+		},
+		sub: []providers.ChatResponse{
+			{ToolCalls: []map[string]interface{}{
+				{
+					"id": "call_a",
+					"function": map[string]interface{}{
+						"name":      "list_entities",
+						"arguments": `{}`,
+					},
+				},
+				{
+					"id": "call_b",
+					"function": map[string]interface{}{
+						"name":      "append_core_memory",
+						"arguments": `{"content": "should be refused"}`,
+					},
+				},
+			}},
+			{Content: "There are no entities yet."},
+		},
+	}
+	nc, msgBus := newTestAgent(t, provider)
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "count my entities in the background"})
+	nc.WaitSubAgents()
+
+	if len(provider.subReqs) != 2 {
+		t.Fatalf("expected 2 sub-agent requests, got %d", len(provider.subReqs))
+	}
+	first := provider.subReqs[0]
+	if len(first.Messages) != 2 || first.Messages[1].Content != "Count the entities and report." {
+		t.Errorf("sub-agent should start from an isolated context, got %+v", first.Messages)
+	}
+	for _, def := range first.Tools {
+		if name := def.Function.Name; name == "spawn" || name == "append_core_memory" {
+			t.Errorf("sub-agent was offered denied tool %s", name)
+		}
+	}
+	var refused bool
+	for _, m := range provider.subReqs[1].Messages {
+		if m.Role == "tool" && m.ToolCallID == "call_b" && strings.Contains(m.Content, "not available to sub-agents") {
+			refused = true
+		}
+	}
+	if !refused {
+		t.Error("expected append_core_memory to be refused")
+	}
+
+	var report string
+	for _, m := range drainOutbound(msgBus) {
+		if strings.Contains(m.Content, "Sub-agent sa-1") {
+			report = m.Content
+			if m.ChatID != "user123" {
+				t.Errorf("report went to %q", m.ChatID)
+			}
+		}
+	}
+	if !strings.Contains(report, "done") || !strings.Contains(report, "There are no entities yet.") {
+		t.Errorf("expected a completion report, got %q", report)
+	}
+}
+
+func TestSpawn_IterationBudget(t *testing.T) {
+	loop := providers.ChatResponse{ToolCalls: []map[string]interface{}{
+		{
+			"id": "call_loop",
+			"function": map[string]interface{}{
+				"name":      "list_entities",
+				"arguments": `{}`,
+			},
+		},
+	}}
+	provider := &subAgentProvider{
+		main: []providers.ChatResponse{
+			spawnCall(`{"task": "Loop forever.", "max_iterations": 2, "tools": ["list_entities"]}`),
+			{Content: "Started."},
+		},
+		sub: []providers.ChatResponse{loop, loop, loop},
+	}
+	nc, msgBus := newTestAgent(t, provider)
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "go"})
+	nc.WaitSubAgents()
+
+	if len(provider.subReqs) != 2 || len(provider.subReqs[0].Tools) != 1 {
+		t.Fatalf("expected 2 requests with only list_entities, got %d requests", len(provider.subReqs))
+	}
+	var found bool
+	for _, m := range drainOutbound(msgBus) {
+		if strings.Contains(m.Content, "incomplete") && strings.Contains(m.Content, "Stopped after 2 iterations") {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected an incomplete report")
+	}
+}
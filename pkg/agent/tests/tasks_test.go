@@ -0,0 +1,99 @@
+package agent_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"littleclaw/pkg/agent"
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/providers"
+)
+
+func TestTaskStore_AddCompleteList(t *testing.T) {
+	dir := t.TempDir()
+	ts := agent.NewTaskStore(dir)
+
+	if _, err := ts.Add("", "", "", ""); err == nil {
+		t.Error("expected an error for an empty title")
+	}
+	if _, err := ts.Add("x", "", "next friday", ""); err == nil {
+		t.Error("expected an error for an unparseable due date")
+	}
+	if _, err := ts.Add("x", "", "", "urgent"); err == nil {
+		t.Error("expected an error for an unknown priority")
+	}
+
+	ts.Add("Water the plants", "", "", "low")
+	ts.Add("File taxes", "receipts in the drawer", "2020-04-15", "")
+	ts.Add("Book flights", "", "tomorrow", "high")
+	ts.Add("Call the bank", "", "", "")
+
+	var got []string
+	for _, task := range ts.List(false) {
+		got = append(got, task.Title)
+	}
+	if want := "Book flights,File taxes,Call the bank,Water the plants"; strings.Join(got, ",") != want {
+		t.Errorf("open tasks = %v, want %s", got, want)
+	}
+	if s := ts.List(false)[1].String(); s != "#2 File taxes (due 2020-04-15, overdue)" {
+		t.Errorf("unexpected task line %q", s)
+	}
+
+	if _, err := ts.Complete("the"); err == nil {
+		t.Error("expected an ambiguous match error (plants, bank)")
+	}
+	if task, err := ts.Complete("taxes"); err != nil || task.ID != 2 {
+		t.Fatalf("Complete(taxes) = %+v, %v", task, err)
+	}
+	if _, err := ts.Complete("#2"); err == nil {
+		t.Error("expected an error completing a done task")
+	}
+
+	// Reloaded from TASKS.json
+	reloaded := agent.NewTaskStore(dir)
+	if open, all := len(reloaded.List(false)), len(reloaded.List(true)); open != 3 || all != 4 {
+		t.Errorf("after reload: %d open, %d total; want 3 and 4", open, all)
+	}
+	if task, _ := reloaded.Add("Fifth", "", "", ""); task.ID != 5 {
+		t.Errorf("expected IDs to continue at 5, got %d", task.ID)
+	}
+}
+
+func TestTaskTools_OpenTasksInSystemPrompt(t *testing.T) {
+	provider := &mockProvider{responses: []providers.ChatResponse{
+		{ToolCalls: []map[string]interface{}{{
+			"id": "call_1",
+			"function": map[string]interface{}{
+				"name":      "add_task",
+				"arguments": `{"title": "Renew passport", "due": "today", "priority": "high"}`,
+			},
+		}}},
+		{Content: "Added."},
+		{ToolCalls: []map[string]interface{}{{
+			"id": "call_2",
+			"function": map[string]interface{}{
+				"name":      "complete_task",
+				"arguments": `{"task": 1}`,
+			},
+		}}},
+		{Content: "Nice work."},
+	}}
+	nc, _ := newTestAgent(t, provider)
+	ctx := context.Background()
+
+	nc.RunAgentLoop(ctx, bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "I need to renew my passport today"})
+	want := "#1 [high] Renew passport (due " + time.Now().Format("2006-01-02") + ")"
+	if prompt := nc.BuildSystemPromptWithQuery(""); !strings.Contains(prompt, "Open Tasks") || !strings.Contains(prompt, want) {
+		t.Errorf("system prompt missing the open task %q", want)
+	}
+
+	nc.RunAgentLoop(ctx, bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "passport renewed!"})
+	if msgs := provider.requests[3].Messages; !strings.Contains(msgs[len(msgs)-2].Content, "Completed task #1") {
+		t.Errorf("unexpected complete_task result: %q", msgs[len(msgs)-2].Content)
+	}
+	if prompt := nc.BuildSystemPromptWithQuery(""); strings.Contains(prompt, "Open Tasks") {
+		t.Error("completed tasks should leave the system prompt")
+	}
+}
@@ -0,0 +1,76 @@
+package agent_test
+
+import (
+	"context"
+	"testing"
+
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/providers"
+)
+
+// hasUserMessage reports whether req carries a user message with content,
+// outside the system prompt.
+func hasUserMessage(req providers.ChatRequest, content string) bool {
+	for _, m := range req.Messages {
+		if m.Role == "user" && m.Content == content {
+			return true
+		}
+	}
+	return false
+}
+
+func TestTopics_RepliesGoToTheTopic(t *testing.T) {
+	nc, msgBus := newTestAgent(t, &mockProvider{responses: []providers.ChatResponse{{Content: "Hi there"}}})
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "-100", Channel: "telegram", MessageID: 5, ThreadID: 7, Content: "hello"})
+
+	out := drainOutbound(msgBus)
+	if len(out) != 1 || out[0].ThreadID != 7 || out[0].ChatID != "-100" {
+		t.Fatalf("got %+v", out)
+	}
+}
+
+func TestTopics_EachTopicHasItsOwnSession(t *testing.T) {
+	provider := &mockProvider{responses: []providers.ChatResponse{
+		{Content: "reply A"}, {Content: "reply B"}, {Content: "reply A2"},
+	}}
+	nc, msgBus := newTestAgent(t, provider)
+	send := func(thread int, content string) {
+		nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "-100", Channel: "telegram", ThreadID: thread, Content: content})
+		drainOutbound(msgBus)
+	}
+
+	send(7, "topic seven")
+	send(8, "topic eight")
+	send(7, "back in seven")
+
+	if hasUserMessage(provider.requests[1], "topic seven") {
+		t.Error("topic 8 saw topic 7's session")
+	}
+	if !hasUserMessage(provider.requests[2], "topic seven") {
+		t.Error("topic 7 lost its session")
+	}
+	if hasUserMessage(provider.requests[2], "topic eight") {
+		t.Error("topic 7 saw topic 8's session")
+	}
+}
+
+func TestTopics_NewClearsOnlyItsTopic(t *testing.T) {
+	provider := &mockProvider{responses: []providers.ChatResponse{
+		{Content: "reply A"}, {Content: "reply B"}, {Content: "reply B2"},
+	}}
+	nc, msgBus := newTestAgent(t, provider)
+	send := func(thread int, content string) {
+		nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "-100", Channel: "telegram", ThreadID: thread, Content: content})
+		drainOutbound(msgBus)
+	}
+
+	send(7, "topic seven")
+	send(8, "topic eight")
+	send(7, "/new")
+	send(8, "still eight")
+
+	if !hasUserMessage(provider.requests[2], "topic eight") {
+		t.Error("/new in topic 7 cleared topic 8's session")
+	}
+}
@@ -0,0 +1,130 @@
+package agent_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"littleclaw/pkg/agent"
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/providers"
+)
+
+// newTracedAgent returns an agent writing traces into its workspace, and that
+// workspace's trace directory.
+func newTracedAgent(t *testing.T, provider providers.Provider, p agent.TracePolicy) (*agent.NanoCore, string) {
+	t.Helper()
+	dir := t.TempDir()
+	nc, err := agent.NewNanoCore(provider, "mock", "test-model", dir, bus.NewMessageBus(), "")
+	if err != nil {
+		t.Fatalf("agent.NewNanoCore() error = %v", err)
+	}
+	nc.EnableTraces(p)
+	return nc, filepath.Join(dir, agent.TraceDir)
+}
+
+func readTraces(t *testing.T, dir string) []agent.RunTrace {
+	t.Helper()
+	entries, _ := os.ReadDir(dir)
+	var traces []agent.RunTrace
+	for _, e := range entries {
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var tr agent.RunTrace
+		if err := json.Unmarshal(data, &tr); err != nil {
+			t.Fatalf("%s is not a trace: %v", e.Name(), err)
+		}
+		traces = append(traces, tr)
+	}
+	return traces
+}
+
+func TestTrace_RecordsStepsAndToolCalls(t *testing.T) {
+	provider := &mockProvider{responses: []providers.ChatResponse{
+		readFileCall("call_1", "notes.txt"),
+		{Content: "Done.", Usage: providers.Usage{PromptTokens: 100, CompletionTokens: 5, TotalTokens: 105}},
+	}}
+	nc, dir := newTracedAgent(t, provider, agent.TracePolicy{})
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user:123", Channel: "telegram", Content: "read my notes"})
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Fatalf("expected one trace file, got %d", len(entries))
+	}
+	if name := entries[0].Name(); filepath.Ext(name) != ".json" || name[len(name)-len("-user_123.json"):] != "-user_123.json" {
+		t.Errorf("unexpected trace file name %q", name)
+	}
+
+	tr := readTraces(t, dir)[0]
+	if tr.ChatID != "user:123" || tr.Model != "test-model" || tr.Outcome != agent.TraceReplied {
+		t.Errorf("unexpected trace header: %+v", tr)
+	}
+	if len(tr.Steps) != 2 || len(tr.Steps[0].ToolCalls) != 1 || tr.Steps[0].ToolCalls[0].Name != "read_file" {
+		t.Fatalf("expected a read_file step and a reply step, got %+v", tr.Steps)
+	}
+	if tr.Steps[0].ToolCalls[0].Result == "" || tr.Steps[1].Content != "Done." {
+		t.Errorf("expected the tool result and the reply, got %+v", tr.Steps)
+	}
+	if tr.Usage.TotalTokens != 105 {
+		t.Errorf("usage = %+v, want 105 total tokens", tr.Usage)
+	}
+	if last := tr.Messages[len(tr.Messages)-1]; last.Role != "assistant" || last.Content != "Done." {
+		t.Errorf("expected the reply as the last message, got %+v", last)
+	}
+}
+
+func TestTrace_RecordsProviderError(t *testing.T) {
+	nc, dir := newTracedAgent(t, failingProvider{}, agent.TracePolicy{})
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "42", Channel: "telegram", Content: "hi"})
+
+	traces := readTraces(t, dir)
+	if len(traces) != 1 || traces[0].Outcome != agent.TraceError || traces[0].Error == "" || traces[0].Steps[0].Error == "" {
+		t.Errorf("expected an error trace, got %+v", traces)
+	}
+}
+
+func TestTrace_Retention(t *testing.T) {
+	provider := &mockProvider{}
+	for i := 0; i < 4; i++ {
+		provider.responses = append(provider.responses, providers.ChatResponse{Content: fmt.Sprintf("reply %d", i)})
+	}
+	nc, dir := newTracedAgent(t, provider, agent.TracePolicy{MaxFiles: 2, MaxAge: 24 * time.Hour})
+
+	// A trace from last week is pruned by age
+	os.MkdirAll(dir, 0755)
+	old := filepath.Join(dir, "20200101-000000.000-42.json")
+	os.WriteFile(old, []byte("{}"), 0600)
+	lastWeek := time.Now().Add(-7 * 24 * time.Hour)
+	os.Chtimes(old, lastWeek, lastWeek)
+
+	for i := 0; i < 4; i++ {
+		nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "42", Channel: "telegram", Content: "hi"})
+		time.Sleep(2 * time.Millisecond) // distinct file names
+	}
+
+	traces := readTraces(t, dir)
+	if len(traces) != 2 {
+		t.Fatalf("expected 2 traces kept, got %d", len(traces))
+	}
+	if traces[1].Steps[0].Content != "reply 3" {
+		t.Errorf("expected the newest traces kept, got %q last", traces[1].Steps[0].Content)
+	}
+}
+
+func TestTrace_OffByDefault(t *testing.T) {
+	provider := &mockProvider{responses: []providers.ChatResponse{{Content: "hi"}}}
+	nc, _ := newTestAgent(t, provider)
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "42", Channel: "telegram", Content: "hi"})
+
+	if _, err := os.Stat(filepath.Join(nc.MemoryStore().MemoryDir(), "..", agent.TraceDir)); !os.IsNotExist(err) {
+		t.Error("no traces should be written unless enabled")
+	}
+}
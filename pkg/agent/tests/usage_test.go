@@ -0,0 +1,125 @@
+package agent_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"littleclaw/pkg/agent"
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/providers"
+)
+
+func TestUsage_RecordedPerRun(t *testing.T) {
+	provider := &mockProvider{responses: []providers.ChatResponse{
+		readFileCall("call_1", "notes.txt"),
+		{Content: "Done.", Usage: providers.Usage{PromptTokens: 1000, CompletionTokens: 200, TotalTokens: 1200}},
+	}}
+	dir := t.TempDir()
+	nc, err := agent.NewNanoCore(provider, "mock", "test-model", dir, bus.NewMessageBus(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	nc.SetAgentParams(agent.AgentParams{PromptPrice: 3, CompletionPrice: 15})
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "42", Channel: "telegram", Content: "read my notes"})
+
+	data, err := os.ReadFile(filepath.Join(dir, agent.UsageFile))
+	if err != nil {
+		t.Fatalf("expected %s: %v", agent.UsageFile, err)
+	}
+	var rec agent.UsageRecord
+	if err := json.Unmarshal([]byte(strings.TrimSpace(string(data))), &rec); err != nil {
+		t.Fatalf("expected one record, got %q", data)
+	}
+	if rec.ChatID != "42" || rec.Provider != "mock" || rec.Model != "test-model" || rec.Calls != 2 {
+		t.Errorf("unexpected record: %+v", rec)
+	}
+	// The tool-call response reported no usage, so it is estimated on top of the reported 1000/200
+	if rec.PromptTokens <= 1000 || rec.CompletionTokens < 200 || rec.CostUSD <= 0.006 {
+		t.Errorf("unexpected tokens or cost: %+v", rec)
+	}
+}
+
+func TestUsage_CountsSelfCheckCalls(t *testing.T) {
+	provider := &mockProvider{responses: []providers.ChatResponse{
+		readFileCall("call_1", "notes.txt"),
+		{Content: "Done.", Usage: providers.Usage{PromptTokens: 1000, CompletionTokens: 200, TotalTokens: 1200}},
+		{Content: "OK", Usage: providers.Usage{PromptTokens: 400, CompletionTokens: 1, TotalTokens: 401}},
+	}}
+	dir := t.TempDir()
+	nc, err := agent.NewNanoCore(provider, "mock", "test-model", dir, bus.NewMessageBus(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	on := true
+	nc.SetAgentParams(agent.AgentParams{Verify: &on})
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "42", Channel: "telegram", Content: "read my notes"})
+
+	data, _ := os.ReadFile(filepath.Join(dir, agent.UsageFile))
+	var rec agent.UsageRecord
+	if err := json.Unmarshal([]byte(strings.TrimSpace(string(data))), &rec); err != nil {
+		t.Fatalf("expected one record, got %q", data)
+	}
+	if rec.Calls != 3 || rec.PromptTokens <= 1400 || rec.CompletionTokens < 201 {
+		t.Errorf("expected the self-check call in the run's usage, got %+v", rec)
+	}
+	if check := provider.requests[2]; check.Model != "test-model" {
+		t.Errorf("expected the self-check on the run's model, got %q", check.Model)
+	}
+}
+
+func TestStatsCommand(t *testing.T) {
+	provider := &mockProvider{responses: []providers.ChatResponse{
+		{Content: "Hi.", Usage: providers.Usage{PromptTokens: 1500, CompletionTokens: 20, TotalTokens: 1520}},
+	}}
+	nc, msgBus := newTestAgent(t, provider)
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "42", Channel: "telegram", Content: "hello"})
+	drainOutbound(msgBus)
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "42", Channel: "telegram", Content: "/stats"})
+
+	if len(provider.requests) != 1 {
+		t.Errorf("/stats should not call the model, got %d requests", len(provider.requests))
+	}
+	out := drainOutbound(msgBus)
+	if len(out) != 1 {
+		t.Fatalf("expected one reply, got %d", len(out))
+	}
+	for _, want := range []string{"Today: 1 request(s), 1 model call(s), 1.5k tokens (1.5k in / 20 out)", "mock/test-model", "This month"} {
+		if !strings.Contains(out[0].Content, want) {
+			t.Errorf("report missing %q:\n%s", want, out[0].Content)
+		}
+	}
+}
+
+func TestReadUsage_Periods(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	var lines []string
+	for _, rec := range []agent.UsageRecord{
+		{Time: now.AddDate(0, -1, 0), Provider: "openai", Model: "gpt-4o", Calls: 1, PromptTokens: 100},
+		{Time: now.AddDate(0, 0, -3), Provider: "openai", Model: "gpt-4o", Calls: 2, PromptTokens: 200, CostUSD: 0.5},
+		{Time: now.Add(-time.Hour), Provider: "ollama", Model: "llama3.2", Calls: 1, PromptTokens: 50},
+	} {
+		data, _ := json.Marshal(rec)
+		lines = append(lines, string(data))
+	}
+	os.WriteFile(filepath.Join(dir, agent.UsageFile), []byte(strings.Join(lines, "\n")+"\nnot json\n"), 0644)
+
+	s, err := agent.ReadUsage(dir, time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s[0].Total.Runs != 1 || s[0].Total.PromptTokens != 50 {
+		t.Errorf("today = %+v", s[0].Total)
+	}
+	if s[1].Total.Runs != 2 || s[1].Total.Calls != 3 || s[1].Total.CostUSD != 0.5 || len(s[1].ByModel) != 2 {
+		t.Errorf("month = %+v (%d models)", s[1].Total, len(s[1].ByModel))
+	}
+}
@@ -0,0 +1,125 @@
+package agent_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"littleclaw/pkg/agent"
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/providers"
+	"littleclaw/pkg/tools"
+)
+
+func offeredTool(req providers.ChatRequest, name string) bool {
+	for _, def := range req.Tools {
+		if def.Function.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func toolResult(req providers.ChatRequest) string {
+	for _, m := range req.Messages {
+		if m.Role == "tool" {
+			return m.Content
+		}
+	}
+	return ""
+}
+
+func TestUserRoles_StandardUserCannotExec(t *testing.T) {
+	provider := &mockProvider{responses: []providers.ChatResponse{
+		{ToolCalls: []map[string]interface{}{{
+			"id":       "call_1",
+			"function": map[string]interface{}{"name": "exec", "arguments": `{"command": "touch pwned"}`},
+		}}},
+		{Content: "Sorry, I can't."},
+	}}
+	nc, msgBus := newTestAgent(t, provider)
+	nc.SetUserRoles(agent.UserRoles{Users: map[string]tools.UserRole{"owner": tools.RoleAdmin, "guest": tools.RoleStandard}})
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "group", Channel: "telegram", SenderID: "guest", Content: "run touch pwned"})
+	drainOutbound(msgBus)
+
+	if len(provider.requests) != 2 {
+		t.Fatalf("expected 2 model calls, got %d", len(provider.requests))
+	}
+	first := provider.requests[0]
+	if offeredTool(first, "exec") || offeredTool(first, "add_cron") || offeredTool(first, "update_core_memory") {
+		t.Error("admin-only tools should not be offered to a standard user")
+	}
+	if !offeredTool(first, "read_file") || !offeredTool(first, "add_task") {
+		t.Error("safe tools should still be offered")
+	}
+	if !strings.Contains(first.Messages[0].Content, "standard role") {
+		t.Error("expected the system prompt to mention the user's role")
+	}
+	if got := toolResult(provider.requests[1]); !strings.Contains(got, "not available to standard users") {
+		t.Errorf("expected exec to be refused, got %q", got)
+	}
+}
+
+func TestUserRoles_UnlistedSenderGetsDefault(t *testing.T) {
+	provider := &mockProvider{responses: []providers.ChatResponse{{Content: "hi"}, {Content: "hi"}}}
+	nc, msgBus := newTestAgent(t, provider)
+	nc.SetUserRoles(agent.UserRoles{Users: map[string]tools.UserRole{"owner": tools.RoleAdmin}})
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "group", Channel: "telegram", SenderID: "stranger", Content: "hello"})
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "owner", Channel: "telegram", SenderID: "owner", Content: "hello"})
+	drainOutbound(msgBus)
+
+	if offeredTool(provider.requests[0], "add_task") || !offeredTool(provider.requests[0], "read_file") {
+		t.Error("an unlisted sender should get the read_only tools")
+	}
+	if !offeredTool(provider.requests[1], "exec") {
+		t.Error("the admin should be offered exec")
+	}
+}
+
+func TestUserRoles_ChatCommands(t *testing.T) {
+	provider := &mockProvider{}
+	nc, msgBus := newTestAgent(t, provider)
+	nc.SetUserRoles(agent.UserRoles{Users: map[string]tools.UserRole{
+		"owner":  tools.RoleAdmin,
+		"guest":  tools.RoleStandard,
+		"viewer": tools.RoleReadOnly,
+	}})
+	send := func(sender, content string) string {
+		nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "group", Channel: "telegram", SenderID: sender, Content: content})
+		out := drainOutbound(msgBus)
+		if len(out) != 1 {
+			t.Fatalf("%s %s: expected one reply, got %+v", sender, content, out)
+		}
+		return out[0].Content
+	}
+
+	if got := send("guest", "/debug on"); !strings.Contains(got, "not available to standard users") {
+		t.Errorf("/debug should be admin-only, got %q", got)
+	}
+	if got := send("owner", "/debug on"); !strings.Contains(got, "Debug mode on") {
+		t.Errorf("the admin should be able to use /debug, got %q", got)
+	}
+	if got := send("viewer", "/new"); !strings.Contains(got, "not available to read_only users") {
+		t.Errorf("/new should need the standard role, got %q", got)
+	}
+	if got := send("guest", "/new"); !strings.Contains(got, "Fresh start") {
+		t.Errorf("a standard user should be able to use /new, got %q", got)
+	}
+	if got := send("viewer", "/stats"); strings.Contains(got, "not available") {
+		t.Errorf("/stats should be open to everyone, got %q", got)
+	}
+}
+
+func TestUserRoles_NoUsersKeepsEveryoneAdmin(t *testing.T) {
+	provider := &mockProvider{responses: []providers.ChatResponse{{Content: "hi"}}}
+	nc, msgBus := newTestAgent(t, provider)
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", SenderID: "anyone", Content: "hello"})
+	drainOutbound(msgBus)
+
+	if !offeredTool(provider.requests[0], "exec") {
+		t.Error("without configured users every sender should be an admin")
+	}
+}
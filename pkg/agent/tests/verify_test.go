@@ -0,0 +1,93 @@
+package agent_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"littleclaw/pkg/agent"
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/providers"
+)
+
+func memoryToolCall() providers.ChatResponse {
+	return providers.ChatResponse{ToolCalls: []map[string]interface{}{
+		{
+			"id": "call_1",
+			"function": map[string]interface{}{
+				"name":      "read_core_memory",
+				"arguments": `{}`,
+			},
+		},
+	}}
+}
+
+func newVerifyingAgent(t *testing.T, provider *mockProvider) (*agent.NanoCore, *bus.MessageBus) {
+	t.Helper()
+	nc, msgBus := newTestAgent(t, provider)
+	on := true
+	nc.SetAgentParams(agent.AgentParams{Verify: &on})
+	return nc, msgBus
+}
+
+func TestVerify_RevisesFlaggedDraft(t *testing.T) {
+	provider := &mockProvider{responses: []providers.ChatResponse{
+		memoryToolCall(),
+		{Content: "Your city is Paris."},
+		{Content: "- The user also asked for the timezone."},
+		{Content: "Your city is Paris, timezone CET."},
+		{Content: "Bye."},
+	}}
+	nc, msgBus := newVerifyingAgent(t, provider)
+	ctx := context.Background()
+
+	nc.RunAgentLoop(ctx, bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "what's my city and timezone?"})
+
+	out := drainOutbound(msgBus)
+	if len(out) != 1 || out[0].Content != "Your city is Paris, timezone CET." {
+		t.Fatalf("expected only the revised reply, got %+v", out)
+	}
+	check := provider.requests[2]
+	if len(check.Tools) != 0 || !strings.Contains(check.Messages[1].Content, "DRAFT REPLY:\nYour city is Paris.") {
+		t.Errorf("expected a tool-less self-check of the draft, got %+v", check)
+	}
+	retry := provider.requests[3].Messages
+	if last := retry[len(retry)-1]; !strings.Contains(last.Content, "also asked for the timezone") {
+		t.Errorf("expected the problems in the retry prompt, got %q", last.Content)
+	}
+
+	// The rejected draft and the self-check prompt stay out of the session
+	nc.RunAgentLoop(ctx, bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "thanks"})
+	for _, m := range provider.requests[4].Messages {
+		if m.Content == "Your city is Paris." || strings.Contains(m.Content, "self-check") {
+			t.Errorf("session kept self-check traffic: %+v", m)
+		}
+	}
+}
+
+func TestVerify_PassingDraftIsSent(t *testing.T) {
+	provider := &mockProvider{responses: []providers.ChatResponse{
+		memoryToolCall(),
+		{Content: "Memory is empty."},
+		{Content: "OK"},
+	}}
+	nc, msgBus := newVerifyingAgent(t, provider)
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "what do you remember?"})
+
+	out := drainOutbound(msgBus)
+	if len(provider.requests) != 3 || len(out) != 1 || out[0].Content != "Memory is empty." {
+		t.Errorf("expected the draft sent after one check, got %d requests and %+v", len(provider.requests), out)
+	}
+}
+
+func TestVerify_SkipsTurnsWithoutTools(t *testing.T) {
+	provider := &mockProvider{responses: []providers.ChatResponse{{Content: "Hi!"}}}
+	nc, msgBus := newVerifyingAgent(t, provider)
+
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "hello"})
+
+	if out := drainOutbound(msgBus); len(provider.requests) != 1 || len(out) != 1 {
+		t.Errorf("expected no self-check for a plain reply, got %d requests", len(provider.requests))
+	}
+}
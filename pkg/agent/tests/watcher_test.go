@@ -0,0 +1,81 @@
+package agent_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"littleclaw/pkg/agent"
+	"littleclaw/pkg/bus"
+)
+
+func TestWatchService_ReportsSettledChanges(t *testing.T) {
+	dir := t.TempDir()
+	reports := filepath.Join(dir, "reports")
+	_ = os.MkdirAll(reports, 0755)
+	_ = os.WriteFile(filepath.Join(reports, "old.pdf"), []byte("old"), 0644)
+
+	var triggered []bus.InboundMessage
+	svc := agent.NewWatchService(dir, func(ctx context.Context, msg bus.InboundMessage) {
+		triggered = append(triggered, msg)
+	})
+	w, err := svc.Watch("reports", "*.pdf", "user123", "telegram")
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	if _, err := svc.Watch("reports", "*.pdf", "user123", "telegram"); err == nil {
+		t.Error("duplicate watch should be rejected")
+	}
+
+	ctx := context.Background()
+	if n := svc.Poll(ctx); n != 0 {
+		t.Errorf("baseline files must not be reported, got %d", n)
+	}
+
+	_ = os.WriteFile(filepath.Join(reports, "q3.pdf"), []byte("report"), 0644)
+	_ = os.WriteFile(filepath.Join(reports, "notes.txt"), []byte("ignored"), 0644)
+	if n := svc.Poll(ctx); n != 0 {
+		t.Errorf("a new file is reported only once it has settled, got %d", n)
+	}
+	if n := svc.Poll(ctx); n != 1 {
+		t.Fatalf("expected 1 settled change, got %d", n)
+	}
+	if len(triggered) != 1 || triggered[0].ChatID != "user123" || triggered[0].Channel != "telegram" {
+		t.Fatalf("unexpected triggers %+v", triggered)
+	}
+	content := triggered[0].Content
+	if !strings.Contains(content, "[SYSTEM FILE WATCH "+w.ID+"]") || !strings.Contains(content, "created reports/q3.pdf") || strings.Contains(content, "notes.txt") {
+		t.Errorf("unexpected trigger content:\n%s", content)
+	}
+
+	_ = os.Remove(filepath.Join(reports, "old.pdf"))
+	if n := svc.Poll(ctx); n != 1 || !strings.Contains(triggered[1].Content, "deleted reports/old.pdf") {
+		t.Errorf("expected a deletion to be reported, got %d: %+v", n, triggered)
+	}
+
+	// Watches persist, and a reloaded service starts from a fresh baseline
+	reloaded := agent.NewWatchService(dir, nil)
+	if len(reloaded.List()) != 1 || reloaded.Poll(ctx) != 0 {
+		t.Error("expected the watch to reload without reporting existing files")
+	}
+	if _, err := reloaded.Unwatch("reports"); err != nil || len(reloaded.List()) != 0 {
+		t.Errorf("Unwatch by path failed: %v", err)
+	}
+}
+
+func TestWatchService_IgnoresMemoryFiles(t *testing.T) {
+	dir := t.TempDir()
+	var triggered int
+	svc := agent.NewWatchService(dir, func(ctx context.Context, msg bus.InboundMessage) { triggered++ })
+	if _, err := svc.Watch(".", "", "user123", "telegram"); err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	_ = os.WriteFile(filepath.Join(dir, "MEMORY.md"), []byte("changed"), 0644)
+	svc.Poll(context.Background())
+	svc.Poll(context.Background())
+	if triggered != 0 {
+		t.Errorf("memory file changes must not trigger the agent, got %d", triggered)
+	}
+}
@@ -0,0 +1,102 @@
+package agent_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"littleclaw/pkg/agent"
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/providers"
+)
+
+// ownerMessages returns the outbound messages sent to the owner chat.
+func ownerMessages(msgBus *bus.MessageBus) []string {
+	var out []string
+	for _, m := range drainOutbound(msgBus) {
+		if m.ChatID == "owner" {
+			out = append(out, m.Content)
+		}
+	}
+	return out
+}
+
+func TestWeeklyReport_SentOncePerWeek(t *testing.T) {
+	provider := &mockProvider{responses: []providers.ChatResponse{
+		readFileCall("call_1", "notes.txt"),
+		{Content: "Your notes are empty."},
+	}}
+	nc, msgBus := newTestAgent(t, provider)
+	hb := agent.NewHeartbeat(nc, time.Minute)
+	// Due every day at midnight, so the slot has always passed when the test runs
+	today := strings.ToLower(time.Now().Weekday().String())
+	if err := hb.EnableWeeklyReport(agent.WeeklyReportPolicy{ChatID: "owner", Day: today, At: "00:00"}); err != nil {
+		t.Fatalf("EnableWeeklyReport() error = %v", err)
+	}
+
+	// The first run only records the starting point
+	hb.Tick(context.Background())
+	statePath := filepath.Join(nc.MemoryStore().MemoryDir(), "WEEKLY_REPORT.json")
+	if _, err := os.Stat(statePath); err != nil {
+		t.Fatalf("expected the report state to be saved: %v", err)
+	}
+	if reports := ownerMessages(msgBus); len(reports) != 0 {
+		t.Fatalf("expected no report on the first run, got %q", reports)
+	}
+
+	// A week of activity
+	nc.RunAgentLoop(context.Background(), bus.InboundMessage{ChatID: "user123", Channel: "telegram", Content: "read my notes"})
+	job := &agent.CronJob{ID: "sync", Schedule: "@every 1h", Command: "exit 1", Label: "sync", ChatID: "user123", Channel: "telegram", Silent: true}
+	if err := nc.CronService().AddJob(job); err != nil {
+		t.Fatal(err)
+	}
+	if err := nc.CronService().RunJobNow("sync"); err != nil {
+		t.Fatal(err)
+	}
+	_ = nc.MemoryStore().WriteEntity("Alice", "Alice is a backend engineer.")
+	drainOutbound(msgBus)
+
+	// Pretend the last report went out eight days ago
+	lastWeek := time.Now().AddDate(0, 0, -8).Format(time.RFC3339)
+	if err := os.WriteFile(statePath, []byte(`{"last_sent": "`+lastWeek+`", "memory_bytes": 0, "entities": 0}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rerun := agent.NewHeartbeat(nc, time.Minute)
+	if err := rerun.EnableWeeklyReport(agent.WeeklyReportPolicy{ChatID: "owner", Day: today, At: "00:00"}); err != nil {
+		t.Fatal(err)
+	}
+	rerun.Tick(context.Background())
+
+	reports := ownerMessages(msgBus)
+	if len(reports) != 1 {
+		t.Fatalf("expected one report to the owner, got %q", reports)
+	}
+	report := reports[0]
+	for _, want := range []string{"📅 Weekly report", "model call(s)", "read_file 1", "1 run(s), 0 ok, 1 failed (sync ×1)", "1 entities (+1)"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q:\n%s", want, report)
+		}
+	}
+
+	// Not sent again until next week
+	again := agent.NewHeartbeat(nc, time.Minute)
+	_ = again.EnableWeeklyReport(agent.WeeklyReportPolicy{ChatID: "owner", Day: today, At: "00:00"})
+	again.Tick(context.Background())
+	if reports := ownerMessages(msgBus); len(reports) != 0 {
+		t.Errorf("expected no second report this week, got %q", reports)
+	}
+}
+
+func TestWeeklyReport_RejectsBadSchedule(t *testing.T) {
+	nc, _ := newTestAgent(t, &mockProvider{})
+	hb := agent.NewHeartbeat(nc, time.Minute)
+	if err := hb.EnableWeeklyReport(agent.WeeklyReportPolicy{Day: "someday"}); err == nil {
+		t.Error("expected an error for an unknown weekday")
+	}
+	if err := hb.EnableWeeklyReport(agent.WeeklyReportPolicy{Day: "fri", At: "9am"}); err == nil {
+		t.Error("expected an error for a bad time")
+	}
+}
@@ -0,0 +1,218 @@
+package agent
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/providers"
+)
+
+const (
+	// TraceDir is the workspace folder holding per-run trace files.
+	TraceDir = "traces"
+
+	// DefaultTraceMaxFiles caps how many trace files are kept.
+	DefaultTraceMaxFiles = 200
+	// DefaultTraceMaxAge is how long trace files are kept.
+	DefaultTraceMaxAge = 7 * 24 * time.Hour
+
+	// traceTimeFormat starts trace file names so they sort by time.
+	traceTimeFormat = "20060102-150405.000"
+)
+
+// Run outcomes recorded in traces.
+const (
+	TraceReplied       = "replied"
+	TraceSilent        = "silent"
+	TraceStopped       = "stopped"
+	TraceError         = "error"
+	TraceMaxIterations = "max_iterations"
+)
+
+// traceChatRe matches characters not allowed in the chat part of a file name.
+var traceChatRe = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// TracePolicy limits the trace files kept. Zero fields keep the defaults.
+type TracePolicy struct {
+	MaxFiles int           // default 200
+	MaxAge   time.Duration // default 7 days
+}
+
+func (p TracePolicy) withDefaults() TracePolicy {
+	if p.MaxFiles <= 0 {
+		p.MaxFiles = DefaultTraceMaxFiles
+	}
+	if p.MaxAge <= 0 {
+		p.MaxAge = DefaultTraceMaxAge
+	}
+	return p
+}
+
+// RunTrace is the record of one RunAgentLoop call written to TraceDir.
+type RunTrace struct {
+	ChatID     string              `json:"chat_id"`
+	Channel    string              `json:"channel"`
+	SenderID   string              `json:"sender_id,omitempty"`
+	Provider   string              `json:"provider"`
+	Model      string              `json:"model"`
+	StartedAt  time.Time           `json:"started_at"`
+	DurationMs int64               `json:"duration_ms"`
+	Outcome    string              `json:"outcome"`
+	Error      string              `json:"error,omitempty"`
+	Usage      providers.Usage     `json:"usage"`
+	Steps      []TraceStep         `json:"steps"`
+	Messages   []providers.Message `json:"messages"` // the conversation as last sent to the model, plus the reply
+}
+
+// TraceStep is one LLM call and the tool calls it asked for.
+type TraceStep struct {
+	Iteration    int             `json:"iteration"`
+	DurationMs   int64           `json:"duration_ms"`
+	Usage        providers.Usage `json:"usage"`
+	FinishReason string          `json:"finish_reason,omitempty"`
+	Content      string          `json:"content,omitempty"`
+	Error        string          `json:"error,omitempty"`
+	ToolCalls    []TraceToolCall `json:"tool_calls,omitempty"`
+}
+
+// TraceToolCall is one executed tool call.
+type TraceToolCall struct {
+	Name       string                 `json:"name"`
+	Args       map[string]interface{} `json:"args,omitempty"`
+	Result     string                 `json:"result"` // as the model saw it
+	ForUser    string                 `json:"for_user,omitempty"`
+	Files      []string               `json:"files,omitempty"`
+	DurationMs int64                  `json:"duration_ms"`
+}
+
+// EnableTraces writes a RunTrace for every agent run, keeping at most what p
+// allows.
+func (c *NanoCore) EnableTraces(p TracePolicy) {
+	p = p.withDefaults()
+	c.traces = &p
+}
+
+// runTracer collects the trace of one run; a nil tracer records nothing.
+type runTracer struct {
+	c     *NanoCore
+	trace RunTrace
+}
+
+// newRunTracer starts the trace for a run, or returns nil when traces are off.
+func (c *NanoCore) newRunTracer(msg bus.InboundMessage, provider, model string) *runTracer {
+	if c.traces == nil {
+		return nil
+	}
+	return &runTracer{c: c, trace: RunTrace{
+		ChatID:    msg.ChatID,
+		Channel:   msg.Channel,
+		SenderID:  msg.SenderID,
+		Provider:  provider,
+		Model:     model,
+		StartedAt: time.Now(),
+		Outcome:   TraceReplied,
+	}}
+}
+
+// llmCall records a model call that started at start.
+func (t *runTracer) llmCall(iteration int, start time.Time, resp *providers.ChatResponse, err error) {
+	if t == nil {
+		return
+	}
+	step := TraceStep{Iteration: iteration, DurationMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		step.Error = err.Error()
+	} else {
+		step.Usage, step.FinishReason, step.Content = resp.Usage, resp.FinishReason, resp.Content
+		t.trace.Usage.PromptTokens += resp.Usage.PromptTokens
+		t.trace.Usage.CompletionTokens += resp.Usage.CompletionTokens
+		t.trace.Usage.TotalTokens += resp.Usage.TotalTokens
+	}
+	t.trace.Steps = append(t.trace.Steps, step)
+}
+
+// toolCall records a tool call made in the latest step.
+func (t *runTracer) toolCall(call TraceToolCall) {
+	if t == nil || len(t.trace.Steps) == 0 {
+		return
+	}
+	step := &t.trace.Steps[len(t.trace.Steps)-1]
+	step.ToolCalls = append(step.ToolCalls, call)
+}
+
+// end sets how the run ended; err is optional.
+func (t *runTracer) end(outcome string, err error) {
+	if t == nil {
+		return
+	}
+	t.trace.Outcome = outcome
+	if err != nil {
+		t.trace.Error = err.Error()
+	}
+}
+
+// write saves the trace with the run's final messages.
+func (t *runTracer) write(messages []providers.Message) {
+	if t == nil {
+		return
+	}
+	t.trace.DurationMs = time.Since(t.trace.StartedAt).Milliseconds()
+	t.trace.Messages = messages
+	if _, err := t.c.writeTrace(&t.trace); err != nil {
+		slog.Warn("failed to write run trace", "chat_id", t.trace.ChatID, "err", err)
+	}
+}
+
+// writeTrace saves tr as TraceDir/<timestamp>-<chat>.json, pruning old
+// traces, and returns its path.
+func (c *NanoCore) writeTrace(tr *RunTrace) (string, error) {
+	dir := filepath.Join(c.workspace, TraceDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(tr, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	chat := strings.Trim(traceChatRe.ReplaceAllString(tr.ChatID, "_"), "_")
+	if chat == "" {
+		chat = "unknown"
+	}
+	path := filepath.Join(dir, tr.StartedAt.Format(traceTimeFormat)+"-"+chat+".json")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return "", err
+	}
+	pruneTraces(dir, *c.traces, time.Now())
+	return path, nil
+}
+
+// pruneTraces removes trace files beyond p.MaxFiles, oldest first, and those
+// older than p.MaxAge.
+func pruneTraces(dir string, p TracePolicy, now time.Time) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	cutoff := now.Add(-p.MaxAge)
+	for i, name := range names {
+		path := filepath.Join(dir, name)
+		info, err := os.Stat(path)
+		if i < len(names)-p.MaxFiles || (err == nil && info.ModTime().Before(cutoff)) {
+			os.Remove(path)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"littleclaw/pkg/providers"
+	"littleclaw/pkg/tools"
+)
+
+const (
+	// UsageFile is the append-only log of agent runs' token usage in the workspace root.
+	UsageFile = "USAGE.jsonl"
+
+	// statsCommand replies with the usage report in the chat that sends it.
+	statsCommand = "/stats"
+
+	// statsTopTools is how many tools the usage report lists.
+	statsTopTools = 5
+)
+
+// usageMu serializes appends to UsageFile.
+var usageMu sync.Mutex
+
+// UsageRecord is one line of USAGE.jsonl: the model calls of one agent run.
+type UsageRecord struct {
+	Time             time.Time `json:"time"`
+	ChatID           string    `json:"chat_id,omitempty"`
+	SenderID         string    `json:"sender_id,omitempty"`
+	Provider         string    `json:"provider"`
+	Model            string    `json:"model"`
+	Calls            int       `json:"calls"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	CostUSD          float64   `json:"cost_usd,omitempty"` // estimated from the configured prices; 0 when none are set
+}
+
+// recordUsage appends a run's usage to UsageFile.
+func (c *NanoCore) recordUsage(rec UsageRecord) {
+	if rec.Calls == 0 {
+		return
+	}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return
+	}
+	usageMu.Lock()
+	defer usageMu.Unlock()
+	f, err := os.OpenFile(filepath.Join(c.workspace, UsageFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		slog.Warn("failed to open usage log", "err", err)
+		return
+	}
+	defer f.Close()
+	f.Write(append(data, '\n'))
+}
+
+// UsageTotals sums usage records.
+type UsageTotals struct {
+	Runs             int
+	Calls            int
+	PromptTokens     int
+	CompletionTokens int
+	CostUSD          float64
+}
+
+func (t *UsageTotals) add(rec UsageRecord) {
+	t.Runs++
+	t.Calls += rec.Calls
+	t.PromptTokens += rec.PromptTokens
+	t.CompletionTokens += rec.CompletionTokens
+	t.CostUSD += rec.CostUSD
+}
+
+// UsageSummary is the usage of a period, overall and per provider/model.
+type UsageSummary struct {
+	Since   time.Time
+	Total   UsageTotals
+	ByModel map[string]*UsageTotals // keyed by "provider/model"
+}
+
+// ReadUsage sums the UsageFile records since each of the given times.
+func ReadUsage(workspaceDir string, since ...time.Time) ([]UsageSummary, error) {
+	summaries := make([]UsageSummary, len(since))
+	for i, t := range since {
+		summaries[i] = UsageSummary{Since: t, ByModel: map[string]*UsageTotals{}}
+	}
+	f, err := os.Open(filepath.Join(workspaceDir, UsageFile))
+	if os.IsNotExist(err) {
+		return summaries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec UsageRecord
+		if json.Unmarshal(scanner.Bytes(), &rec) != nil {
+			continue
+		}
+		for i := range summaries {
+			s := &summaries[i]
+			if rec.Time.Before(s.Since) {
+				continue
+			}
+			s.Total.add(rec)
+			key := rec.Provider + "/" + rec.Model
+			if s.ByModel[key] == nil {
+				s.ByModel[key] = &UsageTotals{}
+			}
+			s.ByModel[key].add(rec)
+		}
+	}
+	return summaries, scanner.Err()
+}
+
+// UsageReport renders today's and this month's usage and the month's most
+// used tools.
+func UsageReport(workspaceDir string, now time.Time) (string, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	summaries, err := ReadUsage(workspaceDir, today, month)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("📊 Usage\n")
+	writeUsagePeriod(&sb, "Today", summaries[0])
+	writeUsagePeriod(&sb, "This month ("+now.Format("January")+")", summaries[1])
+
+	stats, err := tools.ReadToolStats(workspaceDir, month)
+	if err != nil {
+		return "", err
+	}
+	if len(stats) > 0 {
+		sb.WriteString("\nTop tools this month:\n")
+		for i, s := range stats {
+			if i == statsTopTools {
+				break
+			}
+			sb.WriteString(fmt.Sprintf("- %s: %d call(s)", s.Tool, s.Calls))
+			if s.Failures > 0 {
+				sb.WriteString(fmt.Sprintf(", %d failed", s.Failures))
+			}
+			sb.WriteString("\n")
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n"), nil
+}
+
+func writeUsagePeriod(sb *strings.Builder, label string, s UsageSummary) {
+	sb.WriteString("\n" + label + ": ")
+	if s.Total.Runs == 0 {
+		sb.WriteString("no requests\n")
+		return
+	}
+	sb.WriteString(formatUsageTotals(s.Total) + "\n")
+	keys := make([]string, 0, len(s.ByModel))
+	for k := range s.ByModel {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := s.ByModel[keys[i]], s.ByModel[keys[j]]
+		if a.PromptTokens+a.CompletionTokens != b.PromptTokens+b.CompletionTokens {
+			return a.PromptTokens+a.CompletionTokens > b.PromptTokens+b.CompletionTokens
+		}
+		return keys[i] < keys[j]
+	})
+	if len(keys) > 1 {
+		for _, k := range keys {
+			sb.WriteString(fmt.Sprintf("  • %s: %s\n", k, formatUsageTotals(*s.ByModel[k])))
+		}
+	} else {
+		sb.WriteString("  • " + keys[0] + "\n")
+	}
+}
+
+// formatUsageTotals renders e.g. "12 requests, 31 model calls, 48.2k tokens (45.0k in / 3.2k out), ~$0.0412".
+func formatUsageTotals(t UsageTotals) string {
+	s := fmt.Sprintf("%d request(s), %d model call(s), %s tokens (%s in / %s out)", t.Runs, t.Calls,
+		formatTokens(t.PromptTokens+t.CompletionTokens), formatTokens(t.PromptTokens), formatTokens(t.CompletionTokens))
+	if t.CostUSD > 0 {
+		s += fmt.Sprintf(", ~$%.4f", t.CostUSD)
+	}
+	return s
+}
+
+// formatTokens abbreviates token counts: 950, 12.3k, 4.1M.
+func formatTokens(n int) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1e6)
+	case n >= 1000:
+		return fmt.Sprintf("%.1fk", float64(n)/1e3)
+	}
+	return fmt.Sprint(n)
+}
+
+// isStatsCommand reports whether content is /stats (optionally /stats@botname).
+func isStatsCommand(content string) bool {
+	content = strings.ToLower(strings.TrimSpace(content))
+	return content == statsCommand || strings.HasPrefix(content, statsCommand+"@")
+}
+
+// handleStatsCommand answers /stats.
+func (c *NanoCore) handleStatsCommand(chatID string, messageID int, channel string) {
+	reply, err := UsageReport(c.workspace, c.localNow())
+	if err != nil {
+		reply = fmt.Sprintf("⚠ Could not read usage: %v", err)
+	}
+	c.sendResponse(chatID, messageID, channel, reply, nil)
+}
+
+// registerUsageTool adds usage_report, the tool form of /stats.
+func (c *NanoCore) registerUsageTool() {
+	c.toolRegistry.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "usage_report",
+			Description: "Summarizes today's and this month's requests, model calls, tokens, and estimated cost per provider/model, plus the most used tools. Use when the user asks how much they have used or spent.",
+			Parameters: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *tools.ToolResult {
+		report, err := UsageReport(c.workspace, c.localNow())
+		if err != nil {
+			return &tools.ToolResult{ForLLM: fmt.Sprintf("Error reading usage: %v", err)}
+		}
+		return &tools.ToolResult{ForLLM: report}
+	})
+
+	c.toolRegistry.SetToolGroup("diagnostics", "usage_report")
+	c.toolRegistry.SetToolGroupKeywords("diagnostics", "tokens", "cost", "spent", "spend", "bill")
+}
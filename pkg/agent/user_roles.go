@@ -0,0 +1,84 @@
+package agent
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/tools"
+)
+
+// UserRoles gives senders a permission tier (see tools.UserRole).
+type UserRoles struct {
+	Users   map[string]tools.UserRole // sender ID -> role
+	Default tools.UserRole            // role of senders not in Users (default read_only)
+}
+
+// userRoles holds the active UserRoles; SetUserRoles may replace them while
+// messages are being handled.
+type userRoles struct {
+	mu sync.RWMutex
+	p  UserRoles
+}
+
+// SetUserRoles limits what each sender may do. Without any users every
+// sender is an admin, as before roles existed.
+func (c *NanoCore) SetUserRoles(p UserRoles) {
+	if p.Default == "" {
+		p.Default = tools.RoleReadOnly
+	}
+	c.userRoles.mu.Lock()
+	c.userRoles.p = p
+	c.userRoles.mu.Unlock()
+}
+
+// userRole returns the role msg is handled with. The system's own runs and
+// local channels (the CLI, internal triggers) act as the admin.
+func (c *NanoCore) userRole(msg bus.InboundMessage) tools.UserRole {
+	if msg.SenderID == "system" || msg.Channel == "internal" || msg.Channel == "cli" {
+		return tools.RoleAdmin
+	}
+	c.userRoles.mu.RLock()
+	defer c.userRoles.mu.RUnlock()
+	if len(c.userRoles.p.Users) == 0 {
+		return tools.RoleAdmin
+	}
+	if role, ok := c.userRoles.p.Users[msg.SenderID]; ok {
+		return role
+	}
+	return c.userRoles.p.Default
+}
+
+// roleRank orders roles from least to most privileged.
+var roleRank = map[tools.UserRole]int{tools.RoleReadOnly: 0, tools.RoleStandard: 1, tools.RoleAdmin: 2}
+
+// commandRoles is the least privileged role that may use each chat command;
+// commands not listed (/stats) are open to everyone. /stop and /new affect
+// everyone in the chat, and /debug shows tool arguments.
+var commandRoles = map[string]tools.UserRole{
+	stopCommand:  tools.RoleStandard,
+	newCommand:   tools.RoleStandard,
+	debugCommand: tools.RoleAdmin,
+}
+
+// refuseCommand answers a chat command role may not use, reporting whether
+// it did.
+func (c *NanoCore) refuseCommand(msg bus.InboundMessage, role tools.UserRole, command string) bool {
+	need, ok := commandRoles[command]
+	if !ok || roleRank[role] >= roleRank[need] {
+		return false
+	}
+	slog.Info("refused chat command", "chat_id", msg.ChatID, "sender", msg.SenderID, "command", command, "role", role)
+	c.sendResponse(msg.ChatID, msg.MessageID, msg.Channel, fmt.Sprintf("⛔ %s is not available to %s users.", command, role), nil)
+	return true
+}
+
+// rolePrompt tells the model about a non-admin sender, so it explains a
+// missing tool instead of guessing at a workaround.
+func rolePrompt(role tools.UserRole) string {
+	if role == tools.RoleAdmin {
+		return ""
+	}
+	return fmt.Sprintf("\n\n## Permissions\nThe current user has the %s role and may only use the tools offered in this conversation. If they ask for something that needs another tool (running commands, scheduling jobs, writing files, changing memory), tell them only the admin can do that.", role)
+}
@@ -0,0 +1,72 @@
+package agent
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"littleclaw/pkg/providers"
+)
+
+const (
+	// verifyResultChars is how much of each tool result the self-check sees.
+	verifyResultChars = 600
+	// verifyInputChars caps the tool transcript sent to the self-check.
+	verifyInputChars = 16000
+
+	// verifyRetryPrompt asks the model to fix a draft the self-check rejected.
+	verifyRetryPrompt = "[System] A self-check of your draft reply found problems:\n"
+	verifyRetrySuffix = "\nFix them (use tools if needed) and reply again with the complete, corrected answer. Do not mention the self-check."
+
+	verifySystemPrompt = "You review an AI assistant's draft reply before it is sent. Compare it with the user's request and the tool results. Look for requirements of the request that are not met, claims that contradict or are not backed by the tool results, and tool errors the draft ignores or hides. If the draft is fine, reply with exactly OK. Otherwise list each problem on its own line starting with -, nothing else."
+)
+
+// turnUsedTools reports whether the current turn (after turnStart) made any tool calls.
+func turnUsedTools(messages []providers.Message, turnStart int) bool {
+	for _, m := range messages[turnStart+1:] {
+		if m.Role == "assistant" && len(m.ToolCalls) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyReply runs the self-verification pass: a tool-less LLM call that
+// re-reads the request and this turn's tool activity and checks the draft.
+// It returns the problems found, or "" if the draft passes or the check
+// itself fails (the draft is then sent as is).
+func (c *NanoCore) verifyReply(ctx context.Context, chat runChat, request string, turn []providers.Message, draft string) string {
+	transcript := renderToolRounds(turn, verifyResultChars)
+	if len(transcript) > verifyInputChars {
+		transcript = transcript[len(transcript)-verifyInputChars:]
+	}
+
+	var sb strings.Builder
+	sb.WriteString("USER REQUEST:\n" + request + "\n\n")
+	sb.WriteString("TOOL CALLS AND RESULTS:\n" + transcript + "\n\n")
+	sb.WriteString("DRAFT REPLY:\n" + draft)
+
+	resp, err := chat(ctx, providers.ChatRequest{
+		Messages: []providers.Message{
+			{Role: "system", Content: verifySystemPrompt},
+			{Role: "user", Content: sb.String()},
+		},
+		Temperature: 0,
+	})
+	if err != nil {
+		slog.Warn("self-check failed, sending draft unchecked", "err", err)
+		return ""
+	}
+
+	verdict := strings.TrimSpace(resp.Content)
+	if verdict == "" || strings.EqualFold(strings.TrimRight(verdict, ".!"), "OK") {
+		return ""
+	}
+	slog.Info("self-check flagged the draft reply, asking for a revision")
+	return verdict
+}
+
+// isVerifyRetry reports whether m is the prompt that sent a draft back for revision.
+func isVerifyRetry(m providers.Message) bool {
+	return m.Role == "user" && strings.HasPrefix(m.Content, verifyRetryPrompt)
+}
@@ -0,0 +1,467 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/providers"
+	"littleclaw/pkg/tools"
+)
+
+const (
+	// DefaultWatchInterval is how often watched paths are scanned.
+	DefaultWatchInterval = 10 * time.Second
+	// maxWatchedFiles bounds a single scan so a huge tree can't stall the poller.
+	maxWatchedFiles = 5000
+	// maxChangesPerEvent caps how many changed files one trigger lists.
+	maxChangesPerEvent = 20
+)
+
+// FileWatch is one watched workspace path persisted in WATCHES.json.
+type FileWatch struct {
+	ID        string `json:"id"`
+	Path      string `json:"path"`              // relative to the workspace
+	Pattern   string `json:"pattern,omitempty"` // file name glob, e.g. "*.pdf"
+	ChatID    string `json:"chat_id"`
+	Channel   string `json:"channel"`
+	AddedAtMs int64  `json:"addedAtMs"`
+}
+
+// FileChange is a settled change to a watched file.
+type FileChange struct {
+	Path string // relative to the workspace
+	Kind string // "created", "modified", or "deleted"
+	Size int64
+}
+
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// WatchService polls watched paths and triggers the agent when matching files
+// change. A change is only reported once the file has stopped changing for one
+// scan, so a report still being written triggers once, when it's complete.
+type WatchService struct {
+	mu           sync.Mutex
+	watches      map[string]*FileWatch
+	settled      map[string]map[string]fileState // watch ID -> path -> last reported state
+	pending      map[string]map[string]fileState // watch ID -> path -> state seen changing
+	dataFile     string                          // absolute path to WATCHES.json
+	workspaceDir string
+	interval     time.Duration
+	trigger      func(ctx context.Context, msg bus.InboundMessage)
+}
+
+// NewWatchService creates a WatchService backed by $workspace/WATCHES.json.
+// trigger receives one internal message per watch whenever files change.
+func NewWatchService(workspaceDir string, trigger func(ctx context.Context, msg bus.InboundMessage)) *WatchService {
+	ws := &WatchService{
+		watches:      make(map[string]*FileWatch),
+		settled:      make(map[string]map[string]fileState),
+		pending:      make(map[string]map[string]fileState),
+		dataFile:     filepath.Join(workspaceDir, "WATCHES.json"),
+		workspaceDir: workspaceDir,
+		interval:     DefaultWatchInterval,
+		trigger:      trigger,
+	}
+	if err := ws.load(); err != nil && !os.IsNotExist(err) {
+		slog.Warn("watch service: failed to load watches", "err", err)
+	}
+	return ws
+}
+
+// SetInterval changes the scan interval; it takes effect when Start is called.
+func (ws *WatchService) SetInterval(d time.Duration) {
+	if d > 0 {
+		ws.interval = d
+	}
+}
+
+// Start scans all watches on the configured interval until ctx is cancelled.
+func (ws *WatchService) Start(ctx context.Context) {
+	ws.Poll(ctx) // take the baseline so changes made while stopped aren't reported
+	go func() {
+		ticker := time.NewTicker(ws.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				slog.Info("watch service stopped")
+				return
+			case <-ticker.C:
+				ws.Poll(ctx)
+			}
+		}
+	}()
+	slog.Info("watch service started", "watches", len(ws.List()), "interval", ws.interval)
+}
+
+// Watch starts watching rel (a file or directory inside the workspace) for
+// files whose names match pattern. Existing files are taken as the baseline.
+func (ws *WatchService) Watch(rel, pattern, chatID, channel string) (*FileWatch, error) {
+	rel = filepath.ToSlash(filepath.Clean(rel))
+	if pattern != "" {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	for _, w := range ws.watches {
+		if w.Path == rel && w.Pattern == pattern && w.ChatID == chatID {
+			return nil, fmt.Errorf("already watching %s (ID: %s)", rel, w.ID)
+		}
+	}
+
+	id := GenerateJobID("watch_" + rel)
+	for n := 2; ws.watches[id] != nil; n++ {
+		id = fmt.Sprintf("%s_%d", GenerateJobID("watch_"+rel), n)
+	}
+	w := &FileWatch{
+		ID:        id,
+		Path:      rel,
+		Pattern:   pattern,
+		ChatID:    chatID,
+		Channel:   channel,
+		AddedAtMs: time.Now().UnixMilli(),
+	}
+	ws.watches[id] = w
+	ws.settled[id] = ws.scan(w)
+	ws.pending[id] = make(map[string]fileState)
+	return w, ws.save()
+}
+
+// Unwatch removes a watch by ID or path.
+func (ws *WatchService) Unwatch(key string) (*FileWatch, error) {
+	key = strings.TrimSuffix(filepath.ToSlash(key), "/")
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	for id, w := range ws.watches {
+		if w.ID == key || w.Path == key {
+			delete(ws.watches, id)
+			delete(ws.settled, id)
+			delete(ws.pending, id)
+			return w, ws.save()
+		}
+	}
+	return nil, fmt.Errorf("no watch matching %q", key)
+}
+
+// List returns watches sorted by path.
+func (ws *WatchService) List() []*FileWatch {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	out := make([]*FileWatch, 0, len(ws.watches))
+	for _, w := range ws.watches {
+		out = append(out, w)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out
+}
+
+// Poll scans every watch once and triggers the agent for each watch with
+// settled changes. It returns the number of changes reported.
+func (ws *WatchService) Poll(ctx context.Context) int {
+	type event struct {
+		w       FileWatch
+		changes []FileChange
+	}
+	var events []event
+
+	ws.mu.Lock()
+	for id, w := range ws.watches {
+		current := ws.scan(w)
+		if ws.settled[id] == nil {
+			// First scan after a restart: record the baseline only
+			ws.settled[id] = current
+			ws.pending[id] = make(map[string]fileState)
+			continue
+		}
+		if changes := ws.diff(id, current); len(changes) > 0 {
+			events = append(events, event{w: *w, changes: changes})
+		}
+	}
+	ws.mu.Unlock()
+
+	reported := 0
+	for _, ev := range events {
+		reported += len(ev.changes)
+		slog.Info("watch service: changes detected", "watch_id", ev.w.ID, "path", ev.w.Path, "changes", len(ev.changes))
+		if ws.trigger != nil && ev.w.ChatID != "" {
+			ws.trigger(ctx, bus.InboundMessage{
+				Channel:  ev.w.Channel,
+				SenderID: "system",
+				ChatID:   ev.w.ChatID,
+				Content:  FormatWatchEvent(&ev.w, ev.changes),
+				Priority: bus.PriorityCron,
+			})
+		}
+	}
+	return reported
+}
+
+// diff compares a scan against the settled state and returns changes whose
+// files looked the same in this scan as in the previous one; callers must
+// hold ws.mu.
+func (ws *WatchService) diff(id string, current map[string]fileState) []FileChange {
+	settled, pending := ws.settled[id], ws.pending[id]
+	var changes []FileChange
+	for p, st := range current {
+		old, known := settled[p]
+		if known && old == st {
+			delete(pending, p)
+			continue
+		}
+		if prev, ok := pending[p]; !ok || prev != st {
+			pending[p] = st // still changing; wait for the next scan
+			continue
+		}
+		kind := "modified"
+		if !known {
+			kind = "created"
+		}
+		changes = append(changes, FileChange{Path: p, Kind: kind, Size: st.size})
+		settled[p] = st
+		delete(pending, p)
+	}
+	for p := range settled {
+		if _, ok := current[p]; !ok {
+			changes = append(changes, FileChange{Path: p, Kind: "deleted"})
+			delete(settled, p)
+		}
+	}
+	for p := range pending {
+		if _, ok := current[p]; !ok {
+			delete(pending, p)
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// scan lists the regular files under a watch that match its pattern.
+// Memory files, .git directories, and WATCHES.json are never reported.
+func (ws *WatchService) scan(w *FileWatch) map[string]fileState {
+	out := make(map[string]fileState)
+	root := filepath.Join(ws.workspaceDir, filepath.FromSlash(w.Path))
+	_ = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" || tools.IsProtectedMemoryPath(d.Name(), filepath.Dir(p)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || p == ws.dataFile || tools.IsProtectedMemoryPath(d.Name(), filepath.Dir(p)) {
+			return nil
+		}
+		if w.Pattern != "" {
+			if ok, _ := filepath.Match(w.Pattern, d.Name()); !ok {
+				return nil
+			}
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if len(out) == maxWatchedFiles {
+			return filepath.SkipAll
+		}
+		rel, _ := filepath.Rel(ws.workspaceDir, p)
+		out[filepath.ToSlash(rel)] = fileState{size: info.Size(), modTime: info.ModTime()}
+		return nil
+	})
+	return out
+}
+
+// FormatWatchEvent renders the internal message sent to the agent for a batch of changes.
+func FormatWatchEvent(w *FileWatch, changes []FileChange) string {
+	var sb strings.Builder
+	target := "`" + w.Path + "`"
+	if w.Pattern != "" {
+		target += fmt.Sprintf(" (pattern `%s`)", w.Pattern)
+	}
+	sb.WriteString(fmt.Sprintf("[SYSTEM FILE WATCH %s]\nFiles changed under the watched path %s:\n", w.ID, target))
+	for i, ch := range changes {
+		if i == maxChangesPerEvent {
+			sb.WriteString(fmt.Sprintf("- …and %d more\n", len(changes)-maxChangesPerEvent))
+			break
+		}
+		if ch.Kind == "deleted" {
+			sb.WriteString(fmt.Sprintf("- deleted %s\n", ch.Path))
+		} else {
+			sb.WriteString(fmt.Sprintf("- %s %s (%d bytes)\n", ch.Kind, ch.Path, ch.Size))
+		}
+	}
+	sb.WriteString("\nThe user asked to be notified about these changes. Read the files if that helps, then tell the user briefly what changed and anything notable. Do not modify the files unless the user asked for that when setting up the watch.")
+	return sb.String()
+}
+
+func (ws *WatchService) load() error {
+	data, err := os.ReadFile(ws.dataFile)
+	if err != nil {
+		return err
+	}
+	var watches []*FileWatch
+	if err := json.Unmarshal(data, &watches); err != nil {
+		return err
+	}
+	for _, w := range watches {
+		ws.watches[w.ID] = w
+	}
+	return nil
+}
+
+// save persists watches; callers must hold ws.mu.
+func (ws *WatchService) save() error {
+	watches := make([]*FileWatch, 0, len(ws.watches))
+	for _, w := range ws.watches {
+		watches = append(watches, w)
+	}
+	sort.Slice(watches, func(i, j int) bool { return watches[i].AddedAtMs < watches[j].AddedAtMs })
+	data, err := json.MarshalIndent(watches, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(ws.dataFile, data, 0644)
+}
+
+// registerWatchTools adds watch_path, list_watches, and unwatch_path.
+func (c *NanoCore) registerWatchTools() {
+	// watch_path
+	c.toolRegistry.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "watch_path",
+			Description: "Watches a workspace file or folder. When matching files are created, modified, or deleted (e.g. a cron job drops a report), you are triggered with a [SYSTEM FILE WATCH] message in this chat so you can tell the user proactively.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"path": map[string]interface{}{
+						"type":        "string",
+						"description": "Relative file or folder in the workspace, e.g. 'reports'. Folders are watched recursively; the folder may not exist yet.",
+					},
+					"pattern": map[string]interface{}{
+						"type":        "string",
+						"description": "Optional file name glob, e.g. '*.pdf'. Default: all files.",
+					},
+				},
+				"required": []string{"path"},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *tools.ToolResult {
+		p, _ := args["path"].(string)
+		pattern, _ := args["pattern"].(string)
+		if p == "" {
+			return &tools.ToolResult{ForLLM: "Error: path is required."}
+		}
+		abs, err := c.wsMgr.ResolvePath(p)
+		if err != nil {
+			return &tools.ToolResult{ForLLM: fmt.Sprintf("Error: %v", err)}
+		}
+		if abs == filepath.Clean(c.workspace) {
+			return &tools.ToolResult{ForLLM: "Error: refusing to watch the whole workspace; pick the folder where files will appear."}
+		}
+		if tools.IsProtectedMemoryPath(filepath.Base(abs), filepath.Dir(abs)) {
+			return &tools.ToolResult{ForLLM: "Error: memory files cannot be watched."}
+		}
+		chatID, channel := c.replyTarget(ctx)
+		if chatID == "" {
+			return &tools.ToolResult{ForLLM: "Error: Cannot watch a path without a prior user interaction."}
+		}
+		rel, _ := filepath.Rel(c.workspace, abs)
+
+		w, err := c.watchService.Watch(rel, pattern, chatID, channel)
+		if err != nil {
+			return &tools.ToolResult{ForLLM: fmt.Sprintf("Error watching path: %v", err)}
+		}
+		return &tools.ToolResult{ForLLM: fmt.Sprintf("Watching %s (ID: %s). Changes are checked every %s; you will be triggered when matching files change.",
+			w.Path, w.ID, c.watchService.interval)}
+	})
+
+	// list_watches
+	c.toolRegistry.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "list_watches",
+			Description: "Lists watched workspace paths with their IDs and patterns.",
+			Parameters: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *tools.ToolResult {
+		watches := c.watchService.List()
+		if len(watches) == 0 {
+			return &tools.ToolResult{ForLLM: "No watched paths."}
+		}
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("%d watched path(s):\n", len(watches)))
+		for _, w := range watches {
+			pattern := w.Pattern
+			if pattern == "" {
+				pattern = "*"
+			}
+			sb.WriteString(fmt.Sprintf("\n**%s** (ID: `%s`)\n  Pattern: %s\n  Since: %s\n", w.Path, w.ID, pattern, time.UnixMilli(w.AddedAtMs).Format("2006-01-02 15:04")))
+		}
+		return &tools.ToolResult{ForLLM: sb.String()}
+	})
+
+	// unwatch_path
+	c.toolRegistry.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "unwatch_path",
+			Description: "Stops watching a path. Accepts the watch ID or path from list_watches.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"watch": map[string]interface{}{
+						"type":        "string",
+						"description": "Watch ID or path.",
+					},
+				},
+				"required": []string{"watch"},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *tools.ToolResult {
+		key, _ := args["watch"].(string)
+		if key == "" {
+			return &tools.ToolResult{ForLLM: "Error: watch is required."}
+		}
+		w, err := c.watchService.Unwatch(key)
+		if err != nil {
+			return &tools.ToolResult{ForLLM: fmt.Sprintf("Error: %v", err)}
+		}
+		return &tools.ToolResult{ForLLM: fmt.Sprintf("Stopped watching %s.", w.Path)}
+	})
+
+	c.toolRegistry.SetToolGroup("watch", "watch_path", "list_watches", "unwatch_path")
+	c.toolRegistry.SetToolGroupKeywords("watch", "watch", "monitor", "notify", "appear", "drop", "change", "unwatch")
+}
@@ -0,0 +1,276 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/tools"
+)
+
+const (
+	// DefaultWeeklyReportDay and DefaultWeeklyReportAt schedule the weekly
+	// report when the policy leaves them empty.
+	DefaultWeeklyReportDay = "monday"
+	DefaultWeeklyReportAt  = "09:00"
+
+	// weeklyReportCheckEvery is how often the heartbeat checks whether the
+	// report is due.
+	weeklyReportCheckEvery = 15 * time.Minute
+	// weeklyCronRuns caps the run records read per cron job.
+	weeklyCronRuns = 1000
+)
+
+// WeeklyReportPolicy says when the weekly report is sent and where. Zero
+// fields keep the defaults.
+type WeeklyReportPolicy struct {
+	ChatID  string // empty sends to the chat the user last wrote from
+	Channel string // default "telegram" when ChatID is set
+	Day     string // weekday name, default "monday"
+	At      string // "HH:MM" local time, default "09:00"
+}
+
+// weeklyReportState is memory/WEEKLY_REPORT.json: when the last report went
+// out and the memory sizes it reported, for next week's growth figures.
+type weeklyReportState struct {
+	LastSent    time.Time `json:"last_sent"`
+	MemoryBytes int       `json:"memory_bytes"`
+	Entities    int       `json:"entities"`
+}
+
+// weeklyReport is the weekly_report heartbeat task.
+type weeklyReport struct {
+	core    *NanoCore
+	policy  WeeklyReportPolicy
+	weekday time.Weekday
+	at      int    // minutes since midnight
+	path    string // memory/WEEKLY_REPORT.json
+
+	mu sync.Mutex
+}
+
+// EnableWeeklyReport registers the weekly_report heartbeat task, which sends
+// a digest of the past week's requests, tool calls, cron runs, memory growth,
+// and spend once a week. It is built from the workspace logs without an LLM call.
+func (h *Heartbeat) EnableWeeklyReport(p WeeklyReportPolicy) error {
+	if p.Day == "" {
+		p.Day = DefaultWeeklyReportDay
+	}
+	if p.At == "" {
+		p.At = DefaultWeeklyReportAt
+	}
+	if p.ChatID != "" && p.Channel == "" {
+		p.Channel = "telegram"
+	}
+	weekday, err := parseWeekday(p.Day)
+	if err != nil {
+		return err
+	}
+	at, err := parseClock(p.At)
+	if err != nil {
+		return fmt.Errorf("weekly report time: %w", err)
+	}
+	r := &weeklyReport{
+		core:    h.core,
+		policy:  p,
+		weekday: weekday,
+		at:      at,
+		path:    filepath.Join(h.core.memoryStore.MemoryDir(), "WEEKLY_REPORT.json"),
+	}
+	h.Register("weekly_report", weeklyReportCheckEvery, r.run)
+	return nil
+}
+
+// parseWeekday parses an English weekday name or its three-letter form.
+func parseWeekday(v string) (time.Weekday, error) {
+	v = strings.ToLower(strings.TrimSpace(v))
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if v == name || v == name[:3] {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("%q is not a weekday", v)
+}
+
+// lastSlot returns the most recent scheduled report time at or before now.
+func (r *weeklyReport) lastSlot(now time.Time) time.Time {
+	slot := time.Date(now.Year(), now.Month(), now.Day(), r.at/60, r.at%60, 0, 0, now.Location())
+	slot = slot.AddDate(0, 0, -((int(now.Weekday()) - int(r.weekday) + 7) % 7))
+	if slot.After(now) {
+		slot = slot.AddDate(0, 0, -7)
+	}
+	return slot
+}
+
+// run sends the report once the scheduled time has passed since the last one.
+func (r *weeklyReport) run(ctx context.Context) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, now := r.core, r.core.localNow()
+	state, ok := r.load()
+	if !ok {
+		// First run: start counting from now so the first report covers a full period
+		if err := r.save(c.weeklySnapshot(now)); err != nil {
+			slog.Warn("failed to record weekly report state", "err", err)
+		}
+		return
+	}
+	if !state.LastSent.Before(r.lastSlot(now)) {
+		return
+	}
+
+	chatID, channel := r.policy.ChatID, r.policy.Channel
+	if chatID == "" {
+		c.chatMu.Lock()
+		chatID, channel = c.lastChatID, c.lastChannel
+		c.chatMu.Unlock()
+	}
+	if chatID == "" || chatID == "internal_memory" {
+		slog.Debug("weekly report: no chat to send it to")
+		return
+	}
+
+	report, next := c.buildWeeklyReport(state, now)
+	c.sendReply(bus.InboundMessage{ChatID: chatID, Channel: channel, Priority: bus.PriorityCron}, report, nil)
+	c.memoryStore.AppendInternal("ASSISTANT", report)
+	slog.Info("sent the weekly report", "chat_id", chatID)
+	if err := r.save(next); err != nil {
+		slog.Warn("failed to record weekly report state", "err", err)
+	}
+}
+
+func (r *weeklyReport) load() (weeklyReportState, bool) {
+	var state weeklyReportState
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		return state, false
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		slog.Warn("ignoring unreadable weekly report state", "path", r.path, "err", err)
+		return state, false
+	}
+	return state, true
+}
+
+func (r *weeklyReport) save(state weeklyReportState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, data, 0644)
+}
+
+// weeklySnapshot records now and the current memory sizes.
+func (c *NanoCore) weeklySnapshot(now time.Time) weeklyReportState {
+	entities, _ := c.memoryStore.ListEntities()
+	return weeklyReportState{LastSent: now, MemoryBytes: len(c.memoryStore.ReadLongTerm()), Entities: len(entities)}
+}
+
+// buildWeeklyReport renders the activity since prev.LastSent and returns it with
+// the state to compare the next report against.
+func (c *NanoCore) buildWeeklyReport(prev weeklyReportState, now time.Time) (string, weeklyReportState) {
+	since := prev.LastSent
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📅 Weekly report (%s – %s)\n", since.Format("Jan 2"), now.Format("Jan 2")))
+
+	sb.WriteString("\nRequests: ")
+	if summaries, err := ReadUsage(c.workspace, since); err != nil {
+		sb.WriteString(fmt.Sprintf("unknown (%v)\n", err))
+	} else if summaries[0].Total.Runs == 0 {
+		sb.WriteString("none\n")
+	} else {
+		sb.WriteString(formatUsageTotals(summaries[0].Total) + "\n")
+	}
+
+	sb.WriteString("Tools: ")
+	if stats, err := tools.ReadToolStats(c.workspace, since); err != nil {
+		sb.WriteString(fmt.Sprintf("unknown (%v)\n", err))
+	} else if len(stats) == 0 {
+		sb.WriteString("none used\n")
+	} else {
+		calls, failures := 0, 0
+		var top []string
+		for i, s := range stats {
+			calls += s.Calls
+			failures += s.Failures
+			if i < statsTopTools {
+				top = append(top, fmt.Sprintf("%s %d", s.Tool, s.Calls))
+			}
+		}
+		sb.WriteString(fmt.Sprintf("%d call(s), %d failed; top: %s\n", calls, failures, strings.Join(top, ", ")))
+	}
+
+	sb.WriteString("Cron: " + c.weeklyCronSummary(since) + "\n")
+
+	next := c.weeklySnapshot(now)
+	sb.WriteString(fmt.Sprintf("Memory: MEMORY.md %s (%s), %d entities (%s)",
+		formatByteSize(next.MemoryBytes), signed(next.MemoryBytes-prev.MemoryBytes, formatByteSize),
+		next.Entities, signed(next.Entities-prev.Entities, func(n int) string { return fmt.Sprint(n) })))
+	return sb.String(), next
+}
+
+// weeklyCronSummary counts the cron runs since the given time and names the
+// jobs that failed.
+func (c *NanoCore) weeklyCronSummary(since time.Time) string {
+	ok, failed := 0, 0
+	var failing []string
+	for _, job := range c.cronService.ListJobs() {
+		jobFailed := 0
+		for _, run := range c.cronService.GetRecentRuns(job.ID, weeklyCronRuns) {
+			if run.Ts < since.UnixMilli() {
+				continue
+			}
+			if run.Status == "ok" {
+				ok++
+			} else {
+				jobFailed++
+			}
+		}
+		if jobFailed > 0 {
+			name := job.Label
+			if name == "" {
+				name = job.ID
+			}
+			failed += jobFailed
+			failing = append(failing, fmt.Sprintf("%s ×%d", name, jobFailed))
+		}
+	}
+	if ok+failed == 0 {
+		return "no runs"
+	}
+	s := fmt.Sprintf("%d run(s), %d ok, %d failed", ok+failed, ok, failed)
+	if len(failing) > 0 {
+		sort.Strings(failing)
+		s += " (" + strings.Join(failing, ", ") + ")"
+	}
+	return s
+}
+
+// formatByteSize abbreviates a byte count: 512 B, 4.1 KB, 2.3 MB.
+func formatByteSize(n int) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+// signed formats a change with its sign, e.g. "+2" or "-1.5 KB".
+func signed(n int, format func(int) string) string {
+	if n < 0 {
+		return "-" + format(-n)
+	}
+	return "+" + format(n)
+}
@@ -343,4 +343,7 @@ func (c *NanoCore) registerWorkspaceTools() {
 			ForLLM: fmt.Sprintf("Recorded %s run of '%s' in %s/tracker.json at %s.", status, name, folder, ts),
 		}
 	})
+
+	c.toolRegistry.SetToolGroup("workspace", "list_workspace", "create_workspace_folder", "track_item", "list_tracked", "get_tracker_json", "record_script_run")
+	c.toolRegistry.SetToolGroupKeywords("workspace", "workspace", "project", "track", "tracker", "folder", "script", "run")
 }
@@ -0,0 +1,30 @@
+package bus
+
+import "strings"
+
+// Recipients splits a broadcast into one message per chat. Broadcast entries
+// are chat IDs on msg's channel or "channel:chatID" for another channel;
+// duplicates are sent once. A message without Broadcast is returned as is.
+func (msg OutboundMessage) Recipients() []OutboundMessage {
+	if len(msg.Broadcast) == 0 {
+		return []OutboundMessage{msg}
+	}
+	out := make([]OutboundMessage, 0, len(msg.Broadcast))
+	seen := make(map[string]bool, len(msg.Broadcast))
+	for _, target := range msg.Broadcast {
+		m := msg
+		m.Broadcast = nil
+		m.ReplyToMessageID = 0
+		m.ChatID = strings.TrimSpace(target)
+		if channel, chatID, ok := strings.Cut(m.ChatID, ":"); ok {
+			m.Channel, m.ChatID = channel, chatID
+		}
+		key := m.Channel + ":" + m.ChatID
+		if m.ChatID == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, m)
+	}
+	return out
+}
@@ -1,43 +1,110 @@
 package bus
 
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"sync"
+)
+
 // InboundMessage represents a message received from a channel (e.g., Telegram)
 type InboundMessage struct {
-	Channel   string
-	SenderID  string
-	ChatID    string
-	MessageID int      // Message ID of the incoming message
-	Content   string
-	ReplyTo   string   // Content of the message being replied to (if any)
-	Media     []string // URLs or local paths to media
+	Channel    string
+	SenderID   string
+	SenderName string // display name of the sender, if the channel knows it
+	ChatID     string
+	MessageID  int // Message ID of the incoming message
+	ThreadID   int // Forum topic the message was posted in; 0 outside topics
+	Content    string
+	ReplyTo    string   // Content of the message being replied to (if any)
+	Media      []string // URLs or local paths to media
+	Priority   Priority // PriorityInteractive for user messages; replies inherit it
 }
 
 // OutboundMessage represents a message to be sent to a channel
 type OutboundMessage struct {
 	Channel          string
 	ChatID           string
-	ReplyToMessageID int      // ID of the message this is responding to, for reaction handling
+	ReplyToMessageID int // ID of the message this is responding to, for reaction handling
+	ThreadID         int // Forum topic to post in; 0 posts to the chat itself
 	Content          string
+	Markup           Markup   // How Content is formatted; set by the Dispatcher's Adapt hook, empty for plain text
 	Files            []string // List of absolute file paths to send
+	ApprovalID       string   // If set, the channel renders Approve/Deny buttons for this request
+	Priority         Priority // Order in the outbound queue when it backs up (see RunOutbound)
+	Broadcast        []string // If set, one copy goes to each of these chats instead of ChatID (see Recipients)
+	TempFiles        []string // Paths removed, in order, once the message is delivered or given up (see RemoveTempFiles)
+}
+
+// RemoveTempFiles deletes m's TempFiles; list a directory after its files to
+// remove it too. Call it once m is delivered or given up, not while it waits
+// for a redelivery that still needs them.
+func (m OutboundMessage) RemoveTempFiles() {
+	for _, p := range m.TempFiles {
+		if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+			slog.Warn("failed to remove a temporary file", "path", p, "err", err)
+		}
+	}
+}
+
+// ApprovalDecision is a user's answer to an approval prompt.
+type ApprovalDecision struct {
+	ID       string
+	Approved bool
+	SenderID string
+	Result   chan<- error // if set (buffered), receives nil once the decision is applied, or why it was not
 }
 
+var (
+	// ErrApprovalNotPending means the prompt was already answered or expired.
+	ErrApprovalNotPending = errors.New("approval is no longer pending")
+	// ErrNotApprover means the sender may not answer the prompt: only the
+	// user who triggered the action or an admin can.
+	ErrNotApprover = errors.New("only the requester or an admin can answer this")
+)
+
 // MessageBus routes messages between channels and the agent core
 type MessageBus struct {
-	Inbound  chan InboundMessage
-	Outbound chan OutboundMessage
+	Inbound   chan InboundMessage
+	Outbound  chan OutboundMessage
+	Approvals chan ApprovalDecision
+
+	// Middleware run by SendInbound and SendOutbound (see middleware.go)
+	mwMu       sync.RWMutex
+	inboundMW  []InboundMiddleware
+	outboundMW []OutboundMiddleware
 }
 
 // NewMessageBus creates a new initialized MessageBus
 func NewMessageBus() *MessageBus {
 	return &MessageBus{
-		Inbound:  make(chan InboundMessage, 100),
-		Outbound: make(chan OutboundMessage, 100),
+		Inbound:   make(chan InboundMessage, 100),
+		Outbound:  make(chan OutboundMessage, 100),
+		Approvals: make(chan ApprovalDecision, 10),
 	}
 }
 
+// SendInbound queues msg for the agent after running the inbound middleware,
+// which may change or drop it.
 func (b *MessageBus) SendInbound(msg InboundMessage) {
+	msg, ok := b.runInbound(msg)
+	if !ok {
+		return
+	}
 	b.Inbound <- msg
 }
 
+// SendOutbound queues msg for its channel after running the outbound
+// middleware, which may change or drop it. A broadcast is queued as one
+// message per recipient.
 func (b *MessageBus) SendOutbound(msg OutboundMessage) {
-	b.Outbound <- msg
+	for _, m := range msg.Recipients() {
+		if m, ok := b.runOutbound(m); ok {
+			b.Outbound <- m
+		}
+	}
+}
+
+func (b *MessageBus) SendApproval(d ApprovalDecision) {
+	b.Approvals <- d
 }
@@ -0,0 +1,43 @@
+package bus
+
+// Markup is how a channel renders formatted text.
+type Markup string
+
+const (
+	MarkupPlain    Markup = "plain"    // no formatting; Markdown is stripped
+	MarkupMarkdown Markup = "markdown" // Markdown is shown as is
+	MarkupHTML     Markup = "html"     // Telegram's HTML subset
+)
+
+// Capabilities is what a channel can show. The agent writes Markdown and
+// attaches files; the Dispatcher's Adapt hook reshapes each message to fit.
+type Capabilities struct {
+	Markup          Markup
+	MaxMessageChars int  // longest text one message may carry; 0 means no limit
+	Files           bool // attachments can be sent
+	Threads         bool // OutboundMessage.ThreadID is honored
+}
+
+// PlainText is assumed for senders that do not declare their capabilities.
+var PlainText = Capabilities{Markup: MarkupPlain}
+
+// CapableSender is a Sender that declares what its channel can show.
+type CapableSender interface {
+	Sender
+	Capabilities() Capabilities
+}
+
+// Capabilities returns what channel can show: the sender's declaration,
+// PlainText for senders without one, and false for unknown channels.
+func (d *Dispatcher) Capabilities(channel string) (Capabilities, bool) {
+	d.mu.RLock()
+	s, ok := d.senders[channel]
+	d.mu.RUnlock()
+	if !ok {
+		return Capabilities{}, false
+	}
+	if cs, ok := s.(CapableSender); ok {
+		return cs.Capabilities(), true
+	}
+	return PlainText, true
+}
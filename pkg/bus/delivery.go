@@ -0,0 +1,120 @@
+package bus
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultDeliveryAttempts is how many times Deliver tries a message before
+	// handing it back to the caller (normally for the outbox).
+	DefaultDeliveryAttempts = 3
+	// DeliveryBackoff is the default first wait between attempts; it doubles
+	// after every failure unless the channel asks for a longer one.
+	DeliveryBackoff = time.Second
+	// DeliveryMaxWait is the longest Deliver waits in place. A channel asking
+	// for more (a long flood wait) gets the message back through the outbox.
+	DeliveryMaxWait = 30 * time.Second
+)
+
+// DeliveryError is returned by a Sender to say whether a failed send is
+// worth retrying. Errors of other types are treated as temporary.
+type DeliveryError struct {
+	Err        error
+	Permanent  bool          // retrying will not help, e.g. the chat does not exist
+	RetryAfter time.Duration // wait the channel asked for, 0 if it did not say
+}
+
+func (e *DeliveryError) Error() string { return e.Err.Error() }
+func (e *DeliveryError) Unwrap() error { return e.Err }
+
+// IsPermanent reports whether err says a message can never be delivered.
+func IsPermanent(err error) bool {
+	var de *DeliveryError
+	return errors.Is(err, ErrNoSender) || errors.As(err, &de) && de.Permanent
+}
+
+// RetryAfter returns the wait err asks for before the next attempt, or 0.
+func RetryAfter(err error) time.Duration {
+	var de *DeliveryError
+	if errors.As(err, &de) {
+		return de.RetryAfter
+	}
+	return 0
+}
+
+// DeliveryStats counts the outcome of the messages passed to Deliver.
+type DeliveryStats struct {
+	Delivered int `json:"delivered"`
+	Retried   int `json:"retried"` // delivered after at least one failed attempt
+	Failed    int `json:"failed"`  // permanent failures and messages out of attempts
+}
+
+// deliveryStats is the Dispatcher's running DeliveryStats.
+type deliveryStats struct {
+	mu sync.Mutex
+	DeliveryStats
+}
+
+func (s *deliveryStats) add(err error, attempts int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case err != nil:
+		s.Failed++
+	case attempts > 1:
+		s.Delivered++
+		s.Retried++
+	default:
+		s.Delivered++
+	}
+}
+
+// Deliver dispatches msg, retrying temporary failures up to Attempts times
+// with backoff. A wait the channel asks for (Telegram's retry_after) is
+// honored; when it exceeds DeliveryMaxWait, Deliver stops and returns the
+// error so the message can be retried later. Messages for channels without a
+// sender are not counted.
+func (d *Dispatcher) Deliver(ctx context.Context, msg OutboundMessage) error {
+	attempts := d.Attempts
+	if attempts < 1 {
+		attempts = DefaultDeliveryAttempts
+	}
+	backoff := d.Backoff
+	if backoff <= 0 {
+		backoff = DeliveryBackoff
+	}
+	for attempt := 1; ; attempt++ {
+		err := d.Dispatch(ctx, msg)
+		if errors.Is(err, ErrNoSender) {
+			return err
+		}
+		if err == nil || IsPermanent(err) || attempt >= attempts {
+			d.stats.add(err, attempt)
+			return err
+		}
+		wait := max(backoff, RetryAfter(err))
+		if wait > DeliveryMaxWait {
+			d.stats.add(err, attempt)
+			return err
+		}
+		slog.Warn("outbound message failed, retrying", "channel", msg.Channel, "chat_id", msg.ChatID, "attempt", attempt, "wait", wait, "err", err)
+		select {
+		case <-ctx.Done():
+			d.stats.add(err, attempt)
+			return err
+		case <-time.After(wait):
+		}
+		backoff *= 2
+	}
+}
+
+// Stats returns the delivery counts since the Dispatcher was created.
+func (d *Dispatcher) Stats() DeliveryStats {
+	d.stats.mu.Lock()
+	defer d.stats.mu.Unlock()
+	return d.stats.DeliveryStats
+}
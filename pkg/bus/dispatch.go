@@ -0,0 +1,97 @@
+package bus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrNoSender is returned by Dispatch for a message whose channel has no
+// registered sender, such as the agent's "internal" runs.
+var ErrNoSender = errors.New("no sender registered for channel")
+
+// Sender delivers outbound messages to one channel's chats.
+type Sender interface {
+	Send(ctx context.Context, msg OutboundMessage) error
+}
+
+// SenderFunc adapts a function to the Sender interface.
+type SenderFunc func(ctx context.Context, msg OutboundMessage) error
+
+func (f SenderFunc) Send(ctx context.Context, msg OutboundMessage) error { return f(ctx, msg) }
+
+// Dispatcher routes outbound messages to the sender registered for their
+// Channel, so each channel only has to register itself once.
+type Dispatcher struct {
+	// Attempts and Backoff tune Deliver; zero values keep
+	// DefaultDeliveryAttempts and DeliveryBackoff.
+	Attempts int
+	Backoff  time.Duration
+
+	// Adapt, if set, reshapes each message for its channel's Capabilities
+	// before it is sent: rendering its Markdown, splitting long text into
+	// several messages, and so on (see pkg/format).
+	Adapt func(msg OutboundMessage, caps Capabilities) []OutboundMessage
+
+	mu      sync.RWMutex
+	senders map[string]Sender
+	stats   deliveryStats
+}
+
+// NewDispatcher creates a Dispatcher with no channels.
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{senders: make(map[string]Sender)}
+}
+
+// Register sets the sender for channel, replacing any previous one. A nil
+// sender removes the channel.
+func (d *Dispatcher) Register(channel string, s Sender) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if s == nil {
+		delete(d.senders, channel)
+		return
+	}
+	d.senders[channel] = s
+}
+
+// Channels returns the registered channel names, sorted.
+func (d *Dispatcher) Channels() []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	names := make([]string, 0, len(d.senders))
+	for name := range d.senders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Dispatch sends msg through its channel's sender, adapted to the channel
+// when Adapt is set. It returns an error wrapping ErrNoSender when the
+// channel is not registered. If one part of an adapted message fails, the
+// rest are not sent, and a retry sends the whole message again.
+func (d *Dispatcher) Dispatch(ctx context.Context, msg OutboundMessage) error {
+	d.mu.RLock()
+	s, ok := d.senders[msg.Channel]
+	d.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w %q", ErrNoSender, msg.Channel)
+	}
+	if d.Adapt == nil {
+		return s.Send(ctx, msg)
+	}
+	caps := PlainText
+	if cs, ok := s.(CapableSender); ok {
+		caps = cs.Capabilities()
+	}
+	for _, part := range d.Adapt(msg, caps) {
+		if err := s.Send(ctx, part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
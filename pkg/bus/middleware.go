@@ -0,0 +1,75 @@
+package bus
+
+import (
+	"errors"
+	"log/slog"
+)
+
+// ErrDrop is returned by middleware to drop a message without logging a warning.
+var ErrDrop = errors.New("message dropped")
+
+// InboundMiddleware inspects or rewrites a message before it reaches the
+// agent. Returning an error drops the message.
+type InboundMiddleware func(msg InboundMessage) (InboundMessage, error)
+
+// OutboundMiddleware inspects or rewrites a message before it reaches its
+// channel. Returning an error drops the message.
+type OutboundMiddleware func(msg OutboundMessage) (OutboundMessage, error)
+
+// UseInbound appends middleware run, in order, on every SendInbound. It suits
+// cross-cutting concerns like filtering, translation, metrics, and rate
+// limiting that would otherwise be repeated in every channel.
+func (b *MessageBus) UseInbound(mw ...InboundMiddleware) {
+	b.mwMu.Lock()
+	defer b.mwMu.Unlock()
+	b.inboundMW = append(b.inboundMW, mw...)
+}
+
+// UseOutbound appends middleware run, in order, on every SendOutbound.
+func (b *MessageBus) UseOutbound(mw ...OutboundMiddleware) {
+	b.mwMu.Lock()
+	defer b.mwMu.Unlock()
+	b.outboundMW = append(b.outboundMW, mw...)
+}
+
+// runInbound passes msg through the inbound middleware. It reports false when
+// one of them dropped the message.
+func (b *MessageBus) runInbound(msg InboundMessage) (InboundMessage, bool) {
+	b.mwMu.RLock()
+	chain := b.inboundMW
+	b.mwMu.RUnlock()
+	for _, mw := range chain {
+		next, err := mw(msg)
+		if err != nil {
+			logDrop("inbound", msg.Channel, msg.ChatID, err)
+			return msg, false
+		}
+		msg = next
+	}
+	return msg, true
+}
+
+// runOutbound passes msg through the outbound middleware. It reports false
+// when one of them dropped the message.
+func (b *MessageBus) runOutbound(msg OutboundMessage) (OutboundMessage, bool) {
+	b.mwMu.RLock()
+	chain := b.outboundMW
+	b.mwMu.RUnlock()
+	for _, mw := range chain {
+		next, err := mw(msg)
+		if err != nil {
+			logDrop("outbound", msg.Channel, msg.ChatID, err)
+			return msg, false
+		}
+		msg = next
+	}
+	return msg, true
+}
+
+func logDrop(direction, channel, chatID string, err error) {
+	if errors.Is(err, ErrDrop) {
+		slog.Debug("bus middleware dropped a message", "direction", direction, "channel", channel, "chat_id", chatID)
+		return
+	}
+	slog.Warn("bus middleware rejected a message", "direction", direction, "channel", channel, "chat_id", chatID, "err", err)
+}
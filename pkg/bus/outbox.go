@@ -0,0 +1,215 @@
+package bus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// OutboxFile is the outbox's file name in the workspace root.
+	OutboxFile = "OUTBOX.json"
+
+	// OutboxRetryMin and OutboxRetryMax bound the backoff between redelivery
+	// attempts of one message; it doubles after every failure.
+	OutboxRetryMin = 10 * time.Second
+	OutboxRetryMax = 10 * time.Minute
+	// DefaultOutboxMaxAge is how long an undelivered message is retried
+	// before it is given up.
+	DefaultOutboxMaxAge = 24 * time.Hour
+
+	// outboxPoll is how often Run looks for messages due for a retry.
+	outboxPoll = 5 * time.Second
+)
+
+// outboxEntry is an undelivered message and its retry state.
+type outboxEntry struct {
+	Msg         OutboundMessage `json:"msg"`
+	Attempts    int             `json:"attempts"`
+	FirstFailed time.Time       `json:"first_failed"`
+	NextAt      time.Time       `json:"next_at"`
+	LastError   string          `json:"last_error,omitempty"`
+}
+
+// Outbox keeps outbound messages that could not be delivered in a JSON file
+// and retries them with backoff, so replies and cron results survive a
+// channel outage or a restart.
+type Outbox struct {
+	path   string
+	MaxAge time.Duration // give up on a message after this long (default 24h)
+	// OnGiveUp, if set, is called for every message the outbox gives up on.
+	OnGiveUp func(msg OutboundMessage, err error)
+
+	mu      sync.Mutex
+	entries []*outboxEntry
+	wake    chan struct{}
+}
+
+// OpenOutbox loads the undelivered messages saved at path, if any. They are
+// due for a retry right away.
+func OpenOutbox(path string) (*Outbox, error) {
+	o := &Outbox{path: path, MaxAge: DefaultOutboxMaxAge, wake: make(chan struct{}, 1)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return o, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &o.entries); err != nil {
+		return nil, err
+	}
+	for _, e := range o.entries {
+		e.NextAt = time.Time{}
+	}
+	return o, nil
+}
+
+// Len returns the number of undelivered messages.
+func (o *Outbox) Len() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.entries)
+}
+
+// Add saves a message whose delivery failed with err, for a retry after
+// OutboxRetryMin or the wait err asks for, whichever is longer.
+func (o *Outbox) Add(msg OutboundMessage, err error) {
+	now := time.Now()
+	e := &outboxEntry{Msg: msg, Attempts: 1, FirstFailed: now, NextAt: now.Add(max(OutboxRetryMin, RetryAfter(err)))}
+	if err != nil {
+		e.LastError = err.Error()
+	}
+	o.mu.Lock()
+	o.entries = append(o.entries, e)
+	o.saveLocked()
+	o.mu.Unlock()
+	slog.Warn("outbound message queued for redelivery", "channel", msg.Channel, "chat_id", msg.ChatID, "err", err)
+}
+
+// Reconnected makes channel's undelivered messages due now. Call it when a
+// send on the channel succeeds again.
+func (o *Outbox) Reconnected(channel string) {
+	o.mu.Lock()
+	found := false
+	for _, e := range o.entries {
+		if e.Msg.Channel == channel {
+			e.NextAt = time.Time{}
+			found = true
+		}
+	}
+	o.mu.Unlock()
+	if found {
+		select {
+		case o.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Run retries due messages through d until ctx is canceled.
+func (o *Outbox) Run(ctx context.Context, d *Dispatcher) {
+	ticker := time.NewTicker(outboxPoll)
+	defer ticker.Stop()
+	for {
+		o.Retry(ctx, d, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-o.wake:
+		}
+	}
+}
+
+// Retry sends the messages due at now, in the order they failed, and returns
+// how many were delivered. Messages that fail permanently or are older than
+// MaxAge are given up.
+func (o *Outbox) Retry(ctx context.Context, d *Dispatcher, now time.Time) int {
+	o.mu.Lock()
+	var due []*outboxEntry
+	for _, e := range o.entries {
+		if !e.NextAt.After(now) {
+			due = append(due, e)
+		}
+	}
+	o.mu.Unlock()
+	if len(due) == 0 {
+		return 0
+	}
+
+	done := make(map[*outboxEntry]bool)
+	delivered := 0
+	for _, e := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		err := d.Dispatch(ctx, e.Msg)
+		switch {
+		case err == nil:
+			done[e] = true
+			delivered++
+			e.Msg.RemoveTempFiles()
+			slog.Info("redelivered outbound message", "channel", e.Msg.Channel, "chat_id", e.Msg.ChatID, "attempts", e.Attempts+1)
+		case IsPermanent(err) || now.Sub(e.FirstFailed) >= o.MaxAge:
+			done[e] = true
+			slog.Error("giving up on outbound message", "channel", e.Msg.Channel, "chat_id", e.Msg.ChatID, "attempts", e.Attempts+1, "err", err)
+			if o.OnGiveUp != nil {
+				o.OnGiveUp(e.Msg, err)
+			}
+			e.Msg.RemoveTempFiles()
+		default:
+			o.mu.Lock()
+			e.Attempts++
+			e.LastError = err.Error()
+			e.NextAt = now.Add(max(retryBackoff(e.Attempts), RetryAfter(err)))
+			o.mu.Unlock()
+		}
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	kept := o.entries[:0]
+	for _, e := range o.entries {
+		if !done[e] {
+			kept = append(kept, e)
+		}
+	}
+	o.entries = kept
+	o.saveLocked()
+	return delivered
+}
+
+// retryBackoff returns the wait after the given number of failed attempts.
+func retryBackoff(attempts int) time.Duration {
+	wait := OutboxRetryMin
+	for i := 1; i < attempts && wait < OutboxRetryMax; i++ {
+		wait *= 2
+	}
+	return min(wait, OutboxRetryMax)
+}
+
+// saveLocked writes the entries to disk, removing the file when there are
+// none. The caller holds o.mu.
+func (o *Outbox) saveLocked() {
+	if len(o.entries) == 0 {
+		if err := os.Remove(o.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			slog.Warn("failed to clear outbox", "path", o.path, "err", err)
+		}
+		return
+	}
+	data, err := json.MarshalIndent(o.entries, "", "  ")
+	if err == nil {
+		tmp := o.path + ".tmp"
+		if err = os.WriteFile(tmp, data, 0600); err == nil {
+			err = os.Rename(tmp, o.path)
+		}
+	}
+	if err != nil {
+		slog.Warn("failed to save outbox", "path", o.path, "err", err)
+	}
+}
@@ -0,0 +1,63 @@
+package bus
+
+import "context"
+
+const (
+	// DefaultWorkers is how many inbound messages are processed at once.
+	DefaultWorkers = 4
+	// DefaultWorkerQueue is how many inbound messages may wait for a worker.
+	DefaultWorkerQueue = 32
+)
+
+// WorkerPool processes inbound messages on a fixed number of goroutines with
+// a bounded queue in front, so a burst of messages cannot start an unbounded
+// number of agent runs.
+type WorkerPool struct {
+	workers int
+	queue   chan InboundMessage
+}
+
+// NewWorkerPool creates a pool; values below 1 keep the defaults.
+func NewWorkerPool(workers, queueSize int) *WorkerPool {
+	if workers < 1 {
+		workers = DefaultWorkers
+	}
+	if queueSize < 1 {
+		queueSize = DefaultWorkerQueue
+	}
+	return &WorkerPool{workers: workers, queue: make(chan InboundMessage, queueSize)}
+}
+
+// Start runs handle on the queued messages until ctx is canceled. Messages
+// still queued then are not handled.
+func (p *WorkerPool) Start(ctx context.Context, handle func(ctx context.Context, msg InboundMessage)) {
+	for i := 0; i < p.workers; i++ {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case msg := <-p.queue:
+					handle(ctx, msg)
+				}
+			}
+		}()
+	}
+}
+
+// Submit queues msg for a worker. It reports false, without blocking, when
+// the queue is full.
+func (p *WorkerPool) Submit(msg InboundMessage) bool {
+	select {
+	case p.queue <- msg:
+		return true
+	default:
+		return false
+	}
+}
+
+// Queued returns the number of messages waiting for a worker.
+func (p *WorkerPool) Queued() int { return len(p.queue) }
+
+// Capacity returns the worker count and the queue size.
+func (p *WorkerPool) Capacity() (workers, queue int) { return p.workers, cap(p.queue) }
@@ -0,0 +1,105 @@
+package bus
+
+import "context"
+
+// Priority orders messages waiting on the bus; lower values go first, so the
+// zero value is the most urgent.
+type Priority int
+
+const (
+	PriorityInteractive Priority = iota // user messages and the replies to them
+	PriorityCron                        // scheduled output: cron jobs, feeds, watches
+	PriorityInternal                    // heartbeat and other background traffic
+
+	numPriorities = int(PriorityInternal) + 1
+)
+
+// String returns the priority's name.
+func (p Priority) String() string {
+	switch p {
+	case PriorityInteractive:
+		return "interactive"
+	case PriorityCron:
+		return "cron"
+	case PriorityInternal:
+		return "internal"
+	}
+	return "unknown"
+}
+
+// priorityQueue holds items first in, first out within each priority.
+type priorityQueue[T any] struct {
+	levels [numPriorities][]T
+}
+
+func (q *priorityQueue[T]) push(p Priority, item T) {
+	i := min(max(int(p), 0), numPriorities-1)
+	q.levels[i] = append(q.levels[i], item)
+}
+
+// pop removes the oldest item of the most urgent non-empty level.
+func (q *priorityQueue[T]) pop() (T, bool) {
+	for i := range q.levels {
+		if len(q.levels[i]) > 0 {
+			item := q.levels[i][0]
+			q.levels[i] = q.levels[i][1:]
+			return item, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+func (q *priorityQueue[T]) len() int {
+	n := 0
+	for _, l := range q.levels {
+		n += len(l)
+	}
+	return n
+}
+
+// RunOutbound hands outbound messages to send one at a time, most urgent
+// first: before each send it takes every message already waiting, so a
+// backlog of cron or heartbeat output cannot delay replies to the user. It
+// returns when ctx is canceled, with the messages it did not send in the
+// order it would have sent them.
+func (b *MessageBus) RunOutbound(ctx context.Context, send func(OutboundMessage)) []OutboundMessage {
+	var q priorityQueue[OutboundMessage]
+	for {
+		if q.len() == 0 {
+			select {
+			case <-ctx.Done():
+				return b.drainOutbound(&q)
+			case msg := <-b.Outbound:
+				q.push(msg.Priority, msg)
+			}
+		}
+		for waiting := true; waiting; {
+			select {
+			case msg := <-b.Outbound:
+				q.push(msg.Priority, msg)
+			default:
+				waiting = false
+			}
+		}
+		if ctx.Err() != nil {
+			return b.drainOutbound(&q)
+		}
+		msg, _ := q.pop()
+		send(msg)
+	}
+}
+
+// drainOutbound adds the messages still in the Outbound channel to q and
+// returns everything in q, most urgent first.
+func (b *MessageBus) drainOutbound(q *priorityQueue[OutboundMessage]) []OutboundMessage {
+	for len(b.Outbound) > 0 {
+		msg := <-b.Outbound
+		q.push(msg.Priority, msg)
+	}
+	var rest []OutboundMessage
+	for msg, ok := q.pop(); ok; msg, ok = q.pop() {
+		rest = append(rest, msg)
+	}
+	return rest
+}
@@ -0,0 +1,35 @@
+package bus_test
+
+import (
+	"testing"
+
+	"littleclaw/pkg/bus"
+)
+
+func TestSendOutbound_ExpandsBroadcast(t *testing.T) {
+	b := bus.NewMessageBus()
+	b.SendOutbound(bus.OutboundMessage{
+		Channel:          "telegram",
+		ReplyToMessageID: 7,
+		Content:          "hello",
+		Broadcast:        []string{"111", " 222 ", "telegram:111", "slack:C9", ""},
+	})
+
+	var got []string
+	for len(b.Outbound) > 0 {
+		m := <-b.Outbound
+		if m.Content != "hello" || m.ReplyToMessageID != 0 || len(m.Broadcast) != 0 {
+			t.Errorf("unexpected copy: %+v", m)
+		}
+		got = append(got, m.Channel+":"+m.ChatID)
+	}
+	want := []string{"telegram:111", "telegram:222", "slack:C9"}
+	if len(got) != len(want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("recipient %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
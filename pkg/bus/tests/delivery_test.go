@@ -0,0 +1,112 @@
+package bus_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"littleclaw/pkg/bus"
+)
+
+// scriptedSender returns the next error from errs on each send, then nil.
+type scriptedSender struct {
+	errs  []error
+	calls int
+}
+
+func (s *scriptedSender) Send(ctx context.Context, msg bus.OutboundMessage) error {
+	s.calls++
+	if len(s.errs) == 0 {
+		return nil
+	}
+	err := s.errs[0]
+	s.errs = s.errs[1:]
+	return err
+}
+
+func newDeliveryDispatcher(s bus.Sender) *bus.Dispatcher {
+	d := bus.NewDispatcher()
+	d.Backoff = time.Millisecond
+	d.Register("telegram", s)
+	return d
+}
+
+func TestDeliver_RetriesTemporaryFailures(t *testing.T) {
+	sender := &scriptedSender{errs: []error{
+		&bus.DeliveryError{Err: errors.New("Too Many Requests"), RetryAfter: 20 * time.Millisecond},
+		errors.New("connection reset"),
+	}}
+	d := newDeliveryDispatcher(sender)
+
+	start := time.Now()
+	if err := d.Deliver(context.Background(), bus.OutboundMessage{Channel: "telegram", ChatID: "1", Content: "hi"}); err != nil {
+		t.Fatalf("Deliver() error = %v", err)
+	}
+	if sender.calls != 3 {
+		t.Errorf("expected 3 attempts, got %d", sender.calls)
+	}
+	if waited := time.Since(start); waited < 20*time.Millisecond {
+		t.Errorf("expected the retry_after wait to be honored, waited %v", waited)
+	}
+	if s := d.Stats(); s.Delivered != 1 || s.Retried != 1 || s.Failed != 0 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+}
+
+func TestDeliver_StopsOnPermanentFailure(t *testing.T) {
+	sender := &scriptedSender{errs: []error{&bus.DeliveryError{Err: errors.New("Forbidden: bot was blocked by the user"), Permanent: true}}}
+	d := newDeliveryDispatcher(sender)
+
+	err := d.Deliver(context.Background(), bus.OutboundMessage{Channel: "telegram", ChatID: "1", Content: "hi"})
+	if !bus.IsPermanent(err) {
+		t.Fatalf("expected a permanent error, got %v", err)
+	}
+	if sender.calls != 1 {
+		t.Errorf("expected no retries, got %d attempts", sender.calls)
+	}
+	if s := d.Stats(); s.Failed != 1 || s.Delivered != 0 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+}
+
+func TestDeliver_LongFloodWaitIsLeftToTheOutbox(t *testing.T) {
+	sender := &scriptedSender{errs: []error{&bus.DeliveryError{Err: errors.New("Too Many Requests"), RetryAfter: time.Hour}}}
+	d := newDeliveryDispatcher(sender)
+
+	sendErr := d.Deliver(context.Background(), bus.OutboundMessage{Channel: "telegram", ChatID: "1", Content: "hi"})
+	if sendErr == nil || bus.IsPermanent(sendErr) || bus.RetryAfter(sendErr) != time.Hour {
+		t.Fatalf("expected the temporary error back, got %v", sendErr)
+	}
+	if sender.calls != 1 {
+		t.Errorf("expected Deliver not to wait an hour, got %d attempts", sender.calls)
+	}
+
+	// The outbox holds the message at least as long as the channel asked
+	outbox, err := bus.OpenOutbox(filepath.Join(t.TempDir(), bus.OutboxFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	outbox.Add(bus.OutboundMessage{Channel: "telegram", ChatID: "1", Content: "hi"}, sendErr)
+	if n := outbox.Retry(context.Background(), d, time.Now().Add(30*time.Minute)); n != 0 {
+		t.Errorf("expected no redelivery before retry_after, delivered %d", n)
+	}
+}
+
+func TestOutbox_GivesUpOnPermanentFailure(t *testing.T) {
+	sender := &scriptedSender{errs: []error{&bus.DeliveryError{Err: errors.New("Bad Request: chat not found"), Permanent: true}}}
+	d := newDeliveryDispatcher(sender)
+	outbox, err := bus.OpenOutbox(filepath.Join(t.TempDir(), bus.OutboxFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gaveUp []string
+	outbox.OnGiveUp = func(msg bus.OutboundMessage, err error) { gaveUp = append(gaveUp, msg.Content) }
+
+	outbox.Add(bus.OutboundMessage{Channel: "telegram", ChatID: "1", Content: "hi"}, errors.New("timeout"))
+	outbox.Retry(context.Background(), d, time.Now().Add(bus.OutboxRetryMin))
+	if outbox.Len() != 0 || len(gaveUp) != 1 || gaveUp[0] != "hi" {
+		t.Errorf("expected the message given up and reported, kept %d, reported %q", outbox.Len(), gaveUp)
+	}
+}
@@ -0,0 +1,101 @@
+package bus_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"littleclaw/pkg/bus"
+)
+
+func TestDispatcher_RoutesByChannel(t *testing.T) {
+	d := bus.NewDispatcher()
+	var telegram, slack []string
+	d.Register("telegram", bus.SenderFunc(func(ctx context.Context, msg bus.OutboundMessage) error {
+		telegram = append(telegram, msg.Content)
+		return nil
+	}))
+	d.Register("slack", bus.SenderFunc(func(ctx context.Context, msg bus.OutboundMessage) error {
+		slack = append(slack, msg.Content)
+		return errors.New("rate limited")
+	}))
+
+	if err := d.Dispatch(context.Background(), bus.OutboundMessage{Channel: "telegram", ChatID: "1", Content: "hi"}); err != nil {
+		t.Errorf("Dispatch() error = %v", err)
+	}
+	if err := d.Dispatch(context.Background(), bus.OutboundMessage{Channel: "slack", ChatID: "C1", Content: "hello"}); err == nil || err.Error() != "rate limited" {
+		t.Errorf("expected the sender's error, got %v", err)
+	}
+	if !reflect.DeepEqual(telegram, []string{"hi"}) || !reflect.DeepEqual(slack, []string{"hello"}) {
+		t.Errorf("messages went to the wrong sender: telegram=%q slack=%q", telegram, slack)
+	}
+	if got := d.Channels(); !reflect.DeepEqual(got, []string{"slack", "telegram"}) {
+		t.Errorf("Channels() = %q", got)
+	}
+}
+
+func TestDispatcher_UnknownChannel(t *testing.T) {
+	d := bus.NewDispatcher()
+	d.Register("telegram", bus.SenderFunc(func(context.Context, bus.OutboundMessage) error { return nil }))
+	d.Register("telegram", nil)
+
+	err := d.Dispatch(context.Background(), bus.OutboundMessage{Channel: "telegram", ChatID: "1", Content: "hi"})
+	if !errors.Is(err, bus.ErrNoSender) {
+		t.Errorf("expected ErrNoSender after removing the channel, got %v", err)
+	}
+	if len(d.Channels()) != 0 {
+		t.Errorf("expected no channels, got %q", d.Channels())
+	}
+}
+
+// htmlSender records messages and declares a channel that shows HTML.
+type htmlSender struct{ got []bus.OutboundMessage }
+
+func (s *htmlSender) Send(ctx context.Context, msg bus.OutboundMessage) error {
+	s.got = append(s.got, msg)
+	return nil
+}
+
+func (s *htmlSender) Capabilities() bus.Capabilities {
+	return bus.Capabilities{Markup: bus.MarkupHTML, MaxMessageChars: 10}
+}
+
+func TestDispatcher_Capabilities(t *testing.T) {
+	d := bus.NewDispatcher()
+	d.Register("telegram", &htmlSender{})
+	d.Register("plain", bus.SenderFunc(func(context.Context, bus.OutboundMessage) error { return nil }))
+
+	if caps, ok := d.Capabilities("telegram"); !ok || caps.Markup != bus.MarkupHTML {
+		t.Errorf("telegram capabilities = %+v, %v", caps, ok)
+	}
+	if caps, ok := d.Capabilities("plain"); !ok || caps != bus.PlainText {
+		t.Errorf("undeclared capabilities = %+v, %v; want PlainText", caps, ok)
+	}
+	if _, ok := d.Capabilities("slack"); ok {
+		t.Error("unknown channel reported capabilities")
+	}
+}
+
+func TestDispatcher_AdaptSendsEachPart(t *testing.T) {
+	d := bus.NewDispatcher()
+	s := &htmlSender{}
+	d.Register("telegram", s)
+	var seen bus.Capabilities
+	d.Adapt = func(msg bus.OutboundMessage, caps bus.Capabilities) []bus.OutboundMessage {
+		seen = caps
+		first, second := msg, msg
+		first.Content, second.Content = "part 1", "part 2"
+		return []bus.OutboundMessage{first, second}
+	}
+
+	if err := d.Dispatch(context.Background(), bus.OutboundMessage{Channel: "telegram", ChatID: "1", Content: "long"}); err != nil {
+		t.Fatal(err)
+	}
+	if seen.MaxMessageChars != 10 {
+		t.Errorf("Adapt got capabilities %+v", seen)
+	}
+	if len(s.got) != 2 || s.got[0].Content != "part 1" || s.got[1].Content != "part 2" {
+		t.Errorf("sent %+v", s.got)
+	}
+}
@@ -0,0 +1,70 @@
+package bus_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"littleclaw/pkg/bus"
+)
+
+func TestMiddleware_RewritesInOrder(t *testing.T) {
+	b := bus.NewMessageBus()
+	b.UseInbound(
+		func(msg bus.InboundMessage) (bus.InboundMessage, error) {
+			msg.Content = strings.TrimSpace(msg.Content)
+			return msg, nil
+		},
+		func(msg bus.InboundMessage) (bus.InboundMessage, error) {
+			msg.Content = strings.ToUpper(msg.Content)
+			return msg, nil
+		},
+	)
+	var seen []string
+	b.UseOutbound(func(msg bus.OutboundMessage) (bus.OutboundMessage, error) {
+		seen = append(seen, msg.Content)
+		msg.Content = strings.ReplaceAll(msg.Content, "darn", "****")
+		return msg, nil
+	})
+
+	b.SendInbound(bus.InboundMessage{ChatID: "1", Content: "  hello  "})
+	if got := (<-b.Inbound).Content; got != "HELLO" {
+		t.Errorf("inbound content = %q, want HELLO", got)
+	}
+	b.SendOutbound(bus.OutboundMessage{ChatID: "1", Content: "darn it"})
+	if got := (<-b.Outbound).Content; got != "**** it" {
+		t.Errorf("outbound content = %q", got)
+	}
+	if len(seen) != 1 || seen[0] != "darn it" {
+		t.Errorf("outbound middleware saw %q", seen)
+	}
+}
+
+func TestMiddleware_DropsOnError(t *testing.T) {
+	b := bus.NewMessageBus()
+	calls := 0
+	b.UseInbound(
+		func(msg bus.InboundMessage) (bus.InboundMessage, error) {
+			if msg.SenderID == "spammer" {
+				return msg, bus.ErrDrop
+			}
+			return msg, nil
+		},
+		func(msg bus.InboundMessage) (bus.InboundMessage, error) {
+			calls++
+			return msg, nil
+		},
+	)
+	b.UseOutbound(func(msg bus.OutboundMessage) (bus.OutboundMessage, error) {
+		return msg, errors.New("rate limited")
+	})
+
+	b.SendInbound(bus.InboundMessage{SenderID: "spammer", Content: "buy now"})
+	b.SendOutbound(bus.OutboundMessage{ChatID: "1", Content: "hi"})
+	if len(b.Inbound) != 0 || len(b.Outbound) != 0 {
+		t.Errorf("expected both messages dropped, got %d inbound and %d outbound", len(b.Inbound), len(b.Outbound))
+	}
+	if calls != 0 {
+		t.Errorf("later middleware ran %d times after a drop", calls)
+	}
+}
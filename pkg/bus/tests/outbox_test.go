@@ -0,0 +1,111 @@
+package bus_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"littleclaw/pkg/bus"
+)
+
+// flakySender fails until up is set.
+type flakySender struct {
+	up   bool
+	sent []string
+}
+
+func (s *flakySender) Send(ctx context.Context, msg bus.OutboundMessage) error {
+	if !s.up {
+		return errors.New("telegram unreachable")
+	}
+	s.sent = append(s.sent, msg.Content)
+	return nil
+}
+
+func TestOutbox_RedeliversAfterRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), bus.OutboxFile)
+	sender := &flakySender{}
+	d := bus.NewDispatcher()
+	d.Register("telegram", sender)
+
+	outbox, err := bus.OpenOutbox(path)
+	if err != nil {
+		t.Fatalf("OpenOutbox() error = %v", err)
+	}
+	outbox.Add(bus.OutboundMessage{Channel: "telegram", ChatID: "1", Content: "backup finished"}, errors.New("telegram unreachable"))
+	outbox.Add(bus.OutboundMessage{Channel: "telegram", ChatID: "1", Content: "disk is fine"}, errors.New("telegram unreachable"))
+
+	// Not due before the first backoff, and still failing after it
+	now := time.Now()
+	if n := outbox.Retry(context.Background(), d, now); n != 0 {
+		t.Errorf("expected nothing due yet, delivered %d", n)
+	}
+	if n := outbox.Retry(context.Background(), d, now.Add(bus.OutboxRetryMin)); n != 0 || outbox.Len() != 2 {
+		t.Errorf("expected both messages kept while the channel is down, delivered %d, kept %d", n, outbox.Len())
+	}
+
+	// A restart picks the messages up from disk and sends them in order
+	reopened, err := bus.OpenOutbox(path)
+	if err != nil {
+		t.Fatalf("OpenOutbox() error = %v", err)
+	}
+	sender.up = true
+	if n := reopened.Retry(context.Background(), d, time.Now()); n != 2 {
+		t.Fatalf("expected 2 redelivered messages, got %d", n)
+	}
+	if len(sender.sent) != 2 || sender.sent[0] != "backup finished" || sender.sent[1] != "disk is fine" {
+		t.Errorf("unexpected deliveries: %q", sender.sent)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected the outbox file removed once empty, stat error = %v", err)
+	}
+}
+
+func TestOutbox_RemovesTempFilesOnceDelivered(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "outgoing")
+	_ = os.Mkdir(dir, 0755)
+	part := filepath.Join(dir, "video.mp4.part01")
+	_ = os.WriteFile(part, []byte("x"), 0644)
+
+	sender := &flakySender{}
+	d := bus.NewDispatcher()
+	d.Register("telegram", sender)
+	outbox, err := bus.OpenOutbox(filepath.Join(t.TempDir(), bus.OutboxFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := bus.OutboundMessage{Channel: "telegram", ChatID: "1", Files: []string{part}, TempFiles: []string{part, dir}}
+	outbox.Add(msg, errors.New("telegram unreachable"))
+
+	// Kept while the message waits for a retry
+	outbox.Retry(context.Background(), d, time.Now().Add(bus.OutboxRetryMin))
+	if _, err := os.Stat(part); err != nil {
+		t.Fatalf("temporary file removed before delivery: %v", err)
+	}
+
+	sender.up = true
+	if n := outbox.Retry(context.Background(), d, time.Now().Add(time.Hour)); n != 1 {
+		t.Fatalf("expected the message redelivered, got %d", n)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("expected the temporary files and their directory removed, stat error = %v", err)
+	}
+}
+
+func TestOutbox_GivesUpAfterMaxAge(t *testing.T) {
+	d := bus.NewDispatcher()
+	d.Register("telegram", &flakySender{})
+	outbox, err := bus.OpenOutbox(filepath.Join(t.TempDir(), bus.OutboxFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	outbox.MaxAge = time.Hour
+	outbox.Add(bus.OutboundMessage{Channel: "telegram", ChatID: "1", Content: "stale"}, errors.New("telegram unreachable"))
+
+	if n := outbox.Retry(context.Background(), d, time.Now().Add(2*time.Hour)); n != 0 || outbox.Len() != 0 {
+		t.Errorf("expected the stale message dropped, delivered %d, kept %d", n, outbox.Len())
+	}
+}
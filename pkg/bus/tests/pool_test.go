@@ -0,0 +1,53 @@
+package bus_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"littleclaw/pkg/bus"
+)
+
+func TestWorkerPool_RejectsWhenFull(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pool := bus.NewWorkerPool(1, 1)
+	started := make(chan string, 2)
+	release := make(chan struct{})
+	pool.Start(ctx, func(ctx context.Context, msg bus.InboundMessage) {
+		started <- msg.Content
+		<-release
+	})
+
+	if !pool.Submit(bus.InboundMessage{Content: "first"}) {
+		t.Fatal("expected the first message accepted")
+	}
+	<-started // the only worker is now busy
+	if !pool.Submit(bus.InboundMessage{Content: "second"}) {
+		t.Fatal("expected the second message to wait in the queue")
+	}
+	if pool.Submit(bus.InboundMessage{Content: "third"}) {
+		t.Error("expected the third message rejected while the queue is full")
+	}
+	if pool.Queued() != 1 {
+		t.Errorf("Queued() = %d, want 1", pool.Queued())
+	}
+
+	close(release)
+	select {
+	case got := <-started:
+		if got != "second" {
+			t.Errorf("expected the queued message next, got %q", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("queued message was never handled")
+	}
+}
+
+func TestWorkerPool_Defaults(t *testing.T) {
+	workers, queue := bus.NewWorkerPool(0, -1).Capacity()
+	if workers != bus.DefaultWorkers || queue != bus.DefaultWorkerQueue {
+		t.Errorf("Capacity() = %d, %d; want the defaults", workers, queue)
+	}
+}
@@ -0,0 +1,40 @@
+package bus_test
+
+import (
+	"context"
+	"testing"
+
+	"littleclaw/pkg/bus"
+)
+
+func TestRunOutbound_MostUrgentFirst(t *testing.T) {
+	b := bus.NewMessageBus()
+	b.SendOutbound(bus.OutboundMessage{ChatID: "1", Content: "cron 1", Priority: bus.PriorityCron})
+	b.SendOutbound(bus.OutboundMessage{ChatID: "1", Content: "consolidation", Priority: bus.PriorityInternal})
+	b.SendOutbound(bus.OutboundMessage{ChatID: "1", Content: "cron 2", Priority: bus.PriorityCron})
+	b.SendOutbound(bus.OutboundMessage{ChatID: "1", Content: "reply"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var sent []string
+	rest := b.RunOutbound(ctx, func(msg bus.OutboundMessage) {
+		sent = append(sent, msg.Content)
+		if len(sent) == 2 {
+			cancel()
+		}
+	})
+
+	if len(sent) != 2 || sent[0] != "reply" || sent[1] != "cron 1" {
+		t.Errorf("expected the reply first, then cron output in order, got %q", sent)
+	}
+	if len(rest) != 2 || rest[0].Content != "cron 2" || rest[1].Content != "consolidation" {
+		t.Errorf("expected the unsent messages back most urgent first, got %+v", rest)
+	}
+}
+
+func TestPriority_String(t *testing.T) {
+	for p, want := range map[bus.Priority]string{bus.PriorityInteractive: "interactive", bus.PriorityCron: "cron", bus.PriorityInternal: "internal"} {
+		if got := p.String(); got != want {
+			t.Errorf("Priority(%d).String() = %q, want %q", p, got, want)
+		}
+	}
+}
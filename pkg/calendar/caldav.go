@@ -0,0 +1,137 @@
+package calendar
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"littleclaw/pkg/httpclient"
+)
+
+const httpTimeout = 20 * time.Second
+
+// CalDAVClient talks to a single CalDAV calendar collection (Nextcloud,
+// iCloud, Fastmail, Radicale, ...) using HTTP basic auth.
+type CalDAVClient struct {
+	url      string // collection URL, e.g. https://host/remote.php/dav/calendars/me/personal/
+	username string
+	password string
+	loc      *time.Location
+	http     *http.Client
+}
+
+// NewCalDAVClient creates a client for the calendar collection at url.
+func NewCalDAVClient(url, username, password string, loc *time.Location) *CalDAVClient {
+	if !strings.HasSuffix(url, "/") {
+		url += "/"
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+	return &CalDAVClient{url: url, username: username, password: password, loc: loc, http: httpclient.New(httpTimeout)}
+}
+
+// calendarQuery asks the server for events in a time range with recurrences expanded.
+const calendarQuery = `<?xml version="1.0" encoding="utf-8"?>
+<C:calendar-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+  <D:prop>
+    <C:calendar-data>
+      <C:expand start="%[1]s" end="%[2]s"/>
+    </C:calendar-data>
+  </D:prop>
+  <C:filter>
+    <C:comp-filter name="VCALENDAR">
+      <C:comp-filter name="VEVENT">
+        <C:time-range start="%[1]s" end="%[2]s"/>
+      </C:comp-filter>
+    </C:comp-filter>
+  </C:filter>
+</C:calendar-query>`
+
+type multistatus struct {
+	Responses []struct {
+		CalendarData string `xml:"propstat>prop>calendar-data"`
+	} `xml:"response"`
+}
+
+func (c *CalDAVClient) do(ctx context.Context, method, url, contentType, body string, headers map[string]string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(c.username, c.password)
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	return c.http.Do(req)
+}
+
+// ListEvents implements Client.
+func (c *CalDAVClient) ListEvents(ctx context.Context, from, to time.Time) ([]Event, error) {
+	const f = "20060102T150405Z"
+	body := fmt.Sprintf(calendarQuery, from.UTC().Format(f), to.UTC().Format(f))
+
+	resp, err := c.do(ctx, "REPORT", c.url, "application/xml; charset=utf-8", body, map[string]string{"Depth": "1"})
+	if err != nil {
+		return nil, fmt.Errorf("caldav request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 5<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusMultiStatus && resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("caldav REPORT returned %s", resp.Status)
+	}
+
+	var ms multistatus
+	if err := xml.Unmarshal(data, &ms); err != nil {
+		return nil, fmt.Errorf("invalid caldav response: %w", err)
+	}
+
+	var events []Event
+	for _, r := range ms.Responses {
+		evs, err := ParseICS(r.CalendarData, c.loc)
+		if err != nil {
+			return nil, err
+		}
+		for _, ev := range evs {
+			// Expanded results can include instances just outside the range
+			if ev.End.After(from) && ev.Start.Before(to) {
+				events = append(events, ev)
+			}
+		}
+	}
+	sortEvents(events)
+	return events, nil
+}
+
+// CreateEvent implements Client.
+func (c *CalDAVClient) CreateEvent(ctx context.Context, ev Event) (Event, error) {
+	if ev.ID == "" {
+		b := make([]byte, 12)
+		_, _ = rand.Read(b)
+		ev.ID = hex.EncodeToString(b) + "@littleclaw"
+	}
+
+	resp, err := c.do(ctx, http.MethodPut, c.url+strings.TrimSuffix(ev.ID, "@littleclaw")+".ics",
+		"text/calendar; charset=utf-8", BuildICS(ev), map[string]string{"If-None-Match": "*"})
+	if err != nil {
+		return Event{}, fmt.Errorf("caldav request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return Event{}, fmt.Errorf("caldav PUT returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return ev, nil
+}
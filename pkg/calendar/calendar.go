@@ -0,0 +1,109 @@
+package calendar
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Event is a single calendar entry, normalized across backends.
+type Event struct {
+	ID          string
+	Title       string
+	Start       time.Time
+	End         time.Time
+	AllDay      bool
+	Location    string
+	Description string
+}
+
+// Client is implemented by every calendar backend (CalDAV, Google Calendar).
+type Client interface {
+	// ListEvents returns events overlapping [from, to), sorted by start time.
+	ListEvents(ctx context.Context, from, to time.Time) ([]Event, error)
+	// CreateEvent adds an event and returns it with its backend ID filled in.
+	CreateEvent(ctx context.Context, ev Event) (Event, error)
+}
+
+// Slot is a free time range.
+type Slot struct {
+	Start time.Time
+	End   time.Time
+}
+
+// FindFreeSlots returns gaps of at least duration between busy events within
+// [from, to), restricted to working hours [dayStart, dayEnd) (hours of day in
+// from's location). All-day events do not block time.
+func FindFreeSlots(busy []Event, from, to time.Time, duration time.Duration, dayStart, dayEnd int) []Slot {
+	events := make([]Event, 0, len(busy))
+	for _, ev := range busy {
+		if !ev.AllDay {
+			events = append(events, ev)
+		}
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].Start.Before(events[j].Start) })
+
+	loc := from.Location()
+	var slots []Slot
+	for day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc); day.Before(to); day = day.AddDate(0, 0, 1) {
+		windowStart := maxTime(day.Add(time.Duration(dayStart)*time.Hour), from)
+		windowEnd := minTime(day.Add(time.Duration(dayEnd)*time.Hour), to)
+
+		cursor := windowStart
+		for _, ev := range events {
+			if !ev.End.After(cursor) || !ev.Start.Before(windowEnd) {
+				continue
+			}
+			if ev.Start.Sub(cursor) >= duration {
+				slots = append(slots, Slot{Start: cursor, End: ev.Start})
+			}
+			cursor = maxTime(cursor, ev.End)
+		}
+		if windowEnd.Sub(cursor) >= duration {
+			slots = append(slots, Slot{Start: cursor, End: windowEnd})
+		}
+	}
+	return slots
+}
+
+// sortEvents orders events by start time.
+func sortEvents(events []Event) {
+	sort.Slice(events, func(i, j int) bool { return events[i].Start.Before(events[j].Start) })
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+// FormatEvents renders events as one line each, in loc, for the LLM or a chat message.
+func FormatEvents(events []Event, loc *time.Location) string {
+	if len(events) == 0 {
+		return "No events."
+	}
+	var lines []string
+	for _, ev := range events {
+		var when string
+		if ev.AllDay {
+			when = ev.Start.In(loc).Format("Mon Jan 2") + " (all day)"
+		} else {
+			when = ev.Start.In(loc).Format("Mon Jan 2 15:04") + "-" + ev.End.In(loc).Format("15:04")
+		}
+		line := when + "  " + ev.Title
+		if ev.Location != "" {
+			line += " @ " + ev.Location
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
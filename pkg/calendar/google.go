@@ -0,0 +1,198 @@
+package calendar
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"littleclaw/pkg/httpclient"
+)
+
+// GoogleClient uses the Google Calendar v3 REST API with an OAuth refresh
+// token (obtained once via Google's OAuth consent flow and stored in config).
+type GoogleClient struct {
+	calendarID   string
+	clientID     string
+	clientSecret string
+	refreshToken string
+	loc          *time.Location
+	http         *http.Client
+
+	// Overridable for tests
+	TokenURL string
+	APIBase  string
+
+	mu          sync.Mutex
+	accessToken string
+	expiry      time.Time
+}
+
+// NewGoogleClient creates a client for calendarID ("primary" when empty).
+func NewGoogleClient(calendarID, clientID, clientSecret, refreshToken string, loc *time.Location) *GoogleClient {
+	if calendarID == "" {
+		calendarID = "primary"
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+	return &GoogleClient{
+		calendarID:   calendarID,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		refreshToken: refreshToken,
+		loc:          loc,
+		http:         httpclient.New(httpTimeout),
+		TokenURL:     "https://oauth2.googleapis.com/token",
+		APIBase:      "https://www.googleapis.com/calendar/v3",
+	}
+}
+
+// token returns a valid access token, refreshing it when it is about to expire.
+func (g *GoogleClient) token(ctx context.Context) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.accessToken != "" && time.Now().Before(g.expiry.Add(-time.Minute)) {
+		return g.accessToken, nil
+	}
+
+	form := url.Values{
+		"client_id":     {g.clientID},
+		"client_secret": {g.clientSecret},
+		"refresh_token": {g.refreshToken},
+		"grant_type":    {"refresh_token"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := g.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("token refresh failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var tok struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+		Error       string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		return "", fmt.Errorf("invalid token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || tok.AccessToken == "" {
+		return "", fmt.Errorf("token refresh returned %s: %s", resp.Status, tok.Error)
+	}
+	g.accessToken = tok.AccessToken
+	g.expiry = time.Now().Add(time.Duration(tok.ExpiresIn) * time.Second)
+	return g.accessToken, nil
+}
+
+func (g *GoogleClient) do(ctx context.Context, method, endpoint string, body []byte, out interface{}) error {
+	tok, err := g.token(ctx)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+tok)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := g.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("google calendar request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("google calendar returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+type googleTime struct {
+	DateTime string `json:"dateTime,omitempty"`
+	Date     string `json:"date,omitempty"`
+}
+
+type googleEvent struct {
+	ID          string     `json:"id,omitempty"`
+	Summary     string     `json:"summary"`
+	Location    string     `json:"location,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Start       googleTime `json:"start"`
+	End         googleTime `json:"end"`
+}
+
+func (g *GoogleClient) parseTime(t googleTime) (time.Time, bool) {
+	if t.Date != "" {
+		d, _ := time.ParseInLocation("2006-01-02", t.Date, g.loc)
+		return d, true
+	}
+	d, _ := time.Parse(time.RFC3339, t.DateTime)
+	return d, false
+}
+
+func (g *GoogleClient) eventsURL() string {
+	return g.APIBase + "/calendars/" + url.PathEscape(g.calendarID) + "/events"
+}
+
+// ListEvents implements Client.
+func (g *GoogleClient) ListEvents(ctx context.Context, from, to time.Time) ([]Event, error) {
+	q := url.Values{
+		"timeMin":      {from.Format(time.RFC3339)},
+		"timeMax":      {to.Format(time.RFC3339)},
+		"singleEvents": {"true"},
+		"orderBy":      {"startTime"},
+		"maxResults":   {"250"},
+	}
+	var page struct {
+		Items []googleEvent `json:"items"`
+	}
+	if err := g.do(ctx, http.MethodGet, g.eventsURL()+"?"+q.Encode(), nil, &page); err != nil {
+		return nil, err
+	}
+
+	events := make([]Event, 0, len(page.Items))
+	for _, it := range page.Items {
+		start, allDay := g.parseTime(it.Start)
+		end, _ := g.parseTime(it.End)
+		events = append(events, Event{
+			ID: it.ID, Title: it.Summary, Start: start, End: end, AllDay: allDay,
+			Location: it.Location, Description: it.Description,
+		})
+	}
+	sortEvents(events)
+	return events, nil
+}
+
+// CreateEvent implements Client.
+func (g *GoogleClient) CreateEvent(ctx context.Context, ev Event) (Event, error) {
+	body := googleEvent{Summary: ev.Title, Location: ev.Location, Description: ev.Description}
+	if ev.AllDay {
+		body.Start.Date, body.End.Date = ev.Start.Format("2006-01-02"), ev.End.Format("2006-01-02")
+	} else {
+		body.Start.DateTime, body.End.DateTime = ev.Start.Format(time.RFC3339), ev.End.Format(time.RFC3339)
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return Event{}, err
+	}
+
+	var created googleEvent
+	if err := g.do(ctx, http.MethodPost, g.eventsURL(), data, &created); err != nil {
+		return Event{}, err
+	}
+	ev.ID = created.ID
+	return ev, nil
+}
@@ -0,0 +1,129 @@
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ParseICS extracts VEVENTs from iCalendar data. Only the properties the agent
+// needs are read; recurrence is expected to be expanded by the server.
+func ParseICS(data string, loc *time.Location) ([]Event, error) {
+	// Unfold continuation lines (RFC 5545 §3.1)
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	data = strings.ReplaceAll(data, "\n ", "")
+	data = strings.ReplaceAll(data, "\n\t", "")
+
+	var events []Event
+	var cur *Event
+	for _, line := range strings.Split(data, "\n") {
+		switch {
+		case line == "BEGIN:VEVENT":
+			cur = &Event{}
+			continue
+		case line == "END:VEVENT":
+			if cur != nil {
+				if cur.End.IsZero() {
+					cur.End = cur.Start
+					if cur.AllDay {
+						cur.End = cur.Start.AddDate(0, 0, 1)
+					}
+				}
+				events = append(events, *cur)
+			}
+			cur = nil
+			continue
+		}
+		if cur == nil {
+			continue
+		}
+
+		nameParams, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		parts := strings.Split(nameParams, ";")
+		name, params := strings.ToUpper(parts[0]), parts[1:]
+
+		switch name {
+		case "UID":
+			cur.ID = value
+		case "SUMMARY":
+			cur.Title = unescapeText(value)
+		case "LOCATION":
+			cur.Location = unescapeText(value)
+		case "DESCRIPTION":
+			cur.Description = unescapeText(value)
+		case "DTSTART", "DTEND":
+			t, allDay, err := parseICSTime(value, params, loc)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			if name == "DTSTART" {
+				cur.Start, cur.AllDay = t, allDay
+			} else {
+				cur.End = t
+			}
+		}
+	}
+	sortEvents(events)
+	return events, nil
+}
+
+func parseICSTime(value string, params []string, loc *time.Location) (time.Time, bool, error) {
+	for _, p := range params {
+		k, v, _ := strings.Cut(p, "=")
+		switch strings.ToUpper(k) {
+		case "VALUE":
+			if strings.EqualFold(v, "DATE") {
+				t, err := time.ParseInLocation("20060102", value, loc)
+				return t, true, err
+			}
+		case "TZID":
+			if tz, err := time.LoadLocation(strings.Trim(v, `"`)); err == nil {
+				loc = tz
+			}
+		}
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+	if len(value) == 8 {
+		t, err := time.ParseInLocation("20060102", value, loc)
+		return t, true, err
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err
+}
+
+var (
+	icsUnescaper = strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`)
+	icsEscaper   = strings.NewReplacer(`\`, `\\`, "\n", `\n`, ",", `\,`, ";", `\;`)
+)
+
+func unescapeText(s string) string { return icsUnescaper.Replace(s) }
+
+// BuildICS renders a single event as a VCALENDAR document.
+func BuildICS(ev Event) string {
+	var sb strings.Builder
+	sb.WriteString("BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//littleclaw//EN\r\nBEGIN:VEVENT\r\n")
+	sb.WriteString("UID:" + ev.ID + "\r\n")
+	sb.WriteString("DTSTAMP:" + time.Now().UTC().Format("20060102T150405Z") + "\r\n")
+	if ev.AllDay {
+		sb.WriteString("DTSTART;VALUE=DATE:" + ev.Start.Format("20060102") + "\r\n")
+		sb.WriteString("DTEND;VALUE=DATE:" + ev.End.Format("20060102") + "\r\n")
+	} else {
+		sb.WriteString("DTSTART:" + ev.Start.UTC().Format("20060102T150405Z") + "\r\n")
+		sb.WriteString("DTEND:" + ev.End.UTC().Format("20060102T150405Z") + "\r\n")
+	}
+	sb.WriteString("SUMMARY:" + icsEscaper.Replace(ev.Title) + "\r\n")
+	if ev.Location != "" {
+		sb.WriteString("LOCATION:" + icsEscaper.Replace(ev.Location) + "\r\n")
+	}
+	if ev.Description != "" {
+		sb.WriteString("DESCRIPTION:" + icsEscaper.Replace(ev.Description) + "\r\n")
+	}
+	sb.WriteString("END:VEVENT\r\nEND:VCALENDAR\r\n")
+	return sb.String()
+}
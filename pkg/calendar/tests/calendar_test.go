@@ -0,0 +1,195 @@
+package calendar_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"littleclaw/pkg/calendar"
+)
+
+const sampleICS = "BEGIN:VCALENDAR\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:b@x\r\n" +
+	"DTSTART:20250310T140000Z\r\n" +
+	"DTEND:20250310T150000Z\r\n" +
+	"SUMMARY:Design review\\, round 2\r\n" +
+	"LOCATION:Room 4\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:a@x\r\n" +
+	"DTSTART;TZID=UTC:20250310T090000\r\n" +
+	"DTEND;TZID=UTC:20250310T093000\r\n" +
+	"SUMMARY:Stand\r\n" +
+	" up\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:c@x\r\n" +
+	"DTSTART;VALUE=DATE:20250310\r\n" +
+	"SUMMARY:Holiday\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParseICS(t *testing.T) {
+	events, err := calendar.ParseICS(sampleICS, time.UTC)
+	if err != nil {
+		t.Fatalf("ParseICS: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(events))
+	}
+
+	// Sorted by start: all-day (00:00), standup (09:00), review (14:00)
+	if !events[0].AllDay || events[0].Title != "Holiday" {
+		t.Errorf("expected all-day holiday first, got %+v", events[0])
+	}
+	if !events[0].End.Equal(events[0].Start.AddDate(0, 0, 1)) {
+		t.Errorf("all-day event without DTEND should last one day, got %v", events[0].End)
+	}
+	if events[1].Title != "Standup" {
+		t.Errorf("folded SUMMARY not unfolded: %q", events[1].Title)
+	}
+	if events[2].Title != "Design review, round 2" || events[2].Location != "Room 4" {
+		t.Errorf("unexpected event: %+v", events[2])
+	}
+}
+
+func TestBuildICSRoundTrip(t *testing.T) {
+	start := time.Date(2025, 3, 10, 14, 0, 0, 0, time.UTC)
+	in := calendar.Event{ID: "x@y", Title: "Lunch; with Sam", Start: start, End: start.Add(time.Hour), Description: "line1\nline2"}
+
+	out, err := calendar.ParseICS(calendar.BuildICS(in), time.UTC)
+	if err != nil || len(out) != 1 {
+		t.Fatalf("round trip failed: %v %v", out, err)
+	}
+	got := out[0]
+	if got.Title != in.Title || got.Description != in.Description || !got.Start.Equal(in.Start) || !got.End.Equal(in.End) {
+		t.Errorf("round trip mismatch: %+v", got)
+	}
+}
+
+func TestFindFreeSlots(t *testing.T) {
+	day := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	at := func(h, m int) time.Time { return day.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute) }
+
+	busy := []calendar.Event{
+		{Title: "holiday", Start: day, End: day.AddDate(0, 0, 1), AllDay: true},
+		{Title: "b", Start: at(11, 0), End: at(12, 0)},
+		{Title: "a", Start: at(9, 0), End: at(10, 30)},
+		{Title: "overlap", Start: at(11, 30), End: at(13, 0)},
+	}
+	slots := calendar.FindFreeSlots(busy, day, day.AddDate(0, 0, 1), time.Hour, 9, 18)
+
+	if len(slots) != 1 {
+		t.Fatalf("expected 1 slot, got %v", slots)
+	}
+	if !slots[0].Start.Equal(at(13, 0)) || !slots[0].End.Equal(at(18, 0)) {
+		t.Errorf("unexpected slot %v-%v", slots[0].Start, slots[0].End)
+	}
+
+	short := calendar.FindFreeSlots(busy, day, day.AddDate(0, 0, 1), 30*time.Minute, 9, 18)
+	if len(short) != 2 || !short[0].Start.Equal(at(10, 30)) {
+		t.Errorf("expected the 10:30 gap for a 30 minute slot, got %v", short)
+	}
+}
+
+func TestCalDAVClient(t *testing.T) {
+	var putBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, _ := r.BasicAuth(); user != "me" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.Method {
+		case "REPORT":
+			body, _ := io.ReadAll(r.Body)
+			if !strings.Contains(string(body), `time-range start="20250310T000000Z"`) {
+				t.Errorf("REPORT body missing time range: %s", body)
+			}
+			w.WriteHeader(http.StatusMultiStatus)
+			io.WriteString(w, `<?xml version="1.0"?><d:multistatus xmlns:d="DAV:" xmlns:cal="urn:ietf:params:xml:ns:caldav">
+<d:response><d:href>/cal/a.ics</d:href><d:propstat><d:prop><cal:calendar-data>`+sampleICS+`</cal:calendar-data></d:prop></d:propstat></d:response>
+</d:multistatus>`)
+		case http.MethodPut:
+			if r.Header.Get("If-None-Match") != "*" {
+				t.Error("PUT should not overwrite existing events")
+			}
+			b, _ := io.ReadAll(r.Body)
+			putBody = string(b)
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer srv.Close()
+
+	c := calendar.NewCalDAVClient(srv.URL+"/cal", "me", "secret", time.UTC)
+	from := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	events, err := c.ListEvents(context.Background(), from, from.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("ListEvents: %v", err)
+	}
+	if len(events) != 3 {
+		t.Errorf("expected 3 events, got %d", len(events))
+	}
+
+	ev, err := c.CreateEvent(context.Background(), calendar.Event{Title: "New", Start: from, End: from.Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("CreateEvent: %v", err)
+	}
+	if ev.ID == "" || !strings.Contains(putBody, "SUMMARY:New") {
+		t.Errorf("unexpected create result %+v, body %q", ev, putBody)
+	}
+}
+
+func TestGoogleClient(t *testing.T) {
+	tokenCalls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			tokenCalls++
+			_ = r.ParseForm()
+			if r.Form.Get("refresh_token") != "rt" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "at", "expires_in": 3600})
+		case r.Header.Get("Authorization") != "Bearer at":
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.Method == http.MethodGet:
+			if r.URL.Query().Get("singleEvents") != "true" {
+				t.Error("recurring events should be expanded")
+			}
+			io.WriteString(w, `{"items":[
+				{"id":"1","summary":"Gym","start":{"dateTime":"2025-03-10T07:00:00Z"},"end":{"dateTime":"2025-03-10T08:00:00Z"}},
+				{"id":"2","summary":"Trip","start":{"date":"2025-03-10"},"end":{"date":"2025-03-11"}}]}`)
+		case r.Method == http.MethodPost:
+			io.WriteString(w, `{"id":"new-id"}`)
+		}
+	}))
+	defer srv.Close()
+
+	g := calendar.NewGoogleClient("", "cid", "cs", "rt", time.UTC)
+	g.TokenURL = srv.URL + "/token"
+	g.APIBase = srv.URL
+
+	from := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	events, err := g.ListEvents(context.Background(), from, from.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("ListEvents: %v", err)
+	}
+	if len(events) != 2 || !events[0].AllDay || events[1].Title != "Gym" {
+		t.Errorf("unexpected events: %+v", events)
+	}
+
+	ev, err := g.CreateEvent(context.Background(), calendar.Event{Title: "Call", Start: from, End: from.Add(time.Hour)})
+	if err != nil || ev.ID != "new-id" {
+		t.Errorf("CreateEvent = %+v, %v", ev, err)
+	}
+	if tokenCalls != 1 {
+		t.Errorf("access token should be cached, refreshed %d times", tokenCalls)
+	}
+}
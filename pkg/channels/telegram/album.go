@@ -0,0 +1,111 @@
+package telegram
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"littleclaw/pkg/bus"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// maxAlbum is the most items Telegram puts in one media group; a group
+	// needs at least two.
+	maxAlbum = 10
+	// photoLimit is the largest image Telegram accepts as a photo; bigger
+	// ones go as documents.
+	photoLimit = 10 << 20
+	// maxCaption is the longest caption a media group can carry.
+	maxCaption = 1024
+)
+
+// SplitAlbums sorts outgoing files into albums of images sent as one media
+// group each, and the rest, sent one by one as documents. Albums are as even
+// as possible, so eleven images make albums of six and five rather than ten
+// and a straggler. A lone image is sent as a document.
+func SplitAlbums(files []string) (albums [][]string, rest []string) {
+	var images []string
+	for _, f := range files {
+		if isPhoto(f) {
+			images = append(images, f)
+		} else {
+			rest = append(rest, f)
+		}
+	}
+	if len(images) < 2 {
+		return nil, append(images, rest...)
+	}
+	n := (len(images) + maxAlbum - 1) / maxAlbum
+	for i := 0; i < n; i++ {
+		albums = append(albums, images[i*len(images)/n:(i+1)*len(images)/n])
+	}
+	return albums, rest
+}
+
+// isPhoto reports whether the file at path can go in an album as a photo.
+func isPhoto(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jpg", ".jpeg", ".png", ".webp":
+	default:
+		return false
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.Size() <= photoLimit
+}
+
+// fitsCaption reports whether text can be an album caption.
+func fitsCaption(text string) bool {
+	return text != "" && utf8.RuneCountInString(text) <= maxCaption
+}
+
+// sendAlbum sends images as one media group to chat id, in topic threadID
+// when it is non-zero, with caption, formatted as markup, under the first. If Telegram rejects the
+// images as photos (odd dimensions, say), they go as documents instead and
+// the caption is sent as text.
+func (t *Channel) sendAlbum(id int64, threadID int, images []string, caption string, markup bus.Markup) error {
+	media := make([]map[string]string, len(images))
+	files := make([]tgbotapi.RequestFile, len(images))
+	for i, path := range images {
+		name := fmt.Sprintf("photo%d", i)
+		media[i] = map[string]string{"type": "photo", "media": "attach://" + name}
+		files[i] = tgbotapi.RequestFile{Name: name, Data: tgbotapi.FilePath(path)}
+	}
+	if caption != "" {
+		media[0]["caption"] = caption
+		if markup == bus.MarkupHTML {
+			media[0]["parse_mode"] = tgbotapi.ModeHTML
+		}
+	}
+	data, err := json.Marshal(media)
+	if err != nil {
+		return err
+	}
+	params := tgbotapi.Params{"media": string(data)}
+	params.AddNonZero64("chat_id", id)
+	params.AddNonZero("message_thread_id", threadID)
+
+	_, err = t.bot.UploadFiles("sendMediaGroup", params, files)
+	// UploadFiles leaves the error code unset, so any API error other than
+	// flood control counts as a rejection
+	var apiErr *tgbotapi.Error
+	if err == nil || !errors.As(err, &apiErr) || apiErr.RetryAfter > 0 {
+		return err
+	}
+	slog.Warn("album rejected, sending the images as documents", "images", len(images), "err", err)
+	for _, path := range images {
+		if err := t.sendDocument(id, threadID, path); err != nil {
+			return fmt.Errorf("failed to send file %s: %w", path, err)
+		}
+	}
+	if caption != "" {
+		return t.sendText(id, threadID, caption, markup, nil)
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package telegram
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ChatsFile keeps the group chats approved with /allowchat. It lives in the
+// profile directory, out of reach of the file tools.
+const ChatsFile = "TELEGRAM_CHATS.json"
+
+const (
+	allowChatCommand = "allowchat"
+	denyChatCommand  = "denychat"
+)
+
+// chatAllowlist decides which group chats the bot works in. Private chats
+// only need an allowed user; groups must also be in the config list or
+// approved by a chat admin with /allowchat.
+type chatAllowlist struct {
+	configured map[string]bool // telegram_allowed_chats
+	optedIn    map[string]bool // approved with /allowchat, saved to path
+	admins     map[string]bool // users who may approve chats; empty means any allowed user
+	path       string
+}
+
+// SetAllowedChats replaces the group chats approved in the config. It is
+// safe to call while the channel is running.
+func (t *Channel) SetAllowedChats(chats []string) {
+	t.allowMu.Lock()
+	defer t.allowMu.Unlock()
+	t.chats.configured = toSet(chats)
+}
+
+// SetChatAdmins sets who may approve a group with /allowchat or drop it with
+// /denychat; with none, any allowed user may.
+func (t *Channel) SetChatAdmins(users []string) {
+	t.allowMu.Lock()
+	defer t.allowMu.Unlock()
+	t.chats.admins = toSet(users)
+}
+
+// LoadChatOptIns reads the chats approved with /allowchat from path, where
+// later approvals are saved. A missing file means none.
+func (t *Channel) LoadChatOptIns(path string) error {
+	optedIn := make(map[string]bool)
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(data) > 0 {
+		var chats []string
+		if err := json.Unmarshal(data, &chats); err != nil {
+			return fmt.Errorf("parse %s: %w", path, err)
+		}
+		optedIn = toSet(chats)
+	}
+	t.allowMu.Lock()
+	defer t.allowMu.Unlock()
+	t.chats.optedIn, t.chats.path = optedIn, path
+	return nil
+}
+
+// ChatAllowed reports whether the bot works in chatID. Private chats are
+// always allowed, since the user allowlist already covers them.
+func (t *Channel) ChatAllowed(chatID string, private bool) bool {
+	if private {
+		return true
+	}
+	t.allowMu.RLock()
+	defer t.allowMu.RUnlock()
+	return t.chats.configured[chatID] || t.chats.optedIn[chatID]
+}
+
+// AllowChat approves a group chat and saves the approval.
+func (t *Channel) AllowChat(chatID string) error {
+	return t.setOptIn(chatID, true)
+}
+
+// DenyChat withdraws a group chat's /allowchat approval. Chats listed in the
+// config stay allowed.
+func (t *Channel) DenyChat(chatID string) error {
+	return t.setOptIn(chatID, false)
+}
+
+func (t *Channel) setOptIn(chatID string, allowed bool) error {
+	t.allowMu.Lock()
+	defer t.allowMu.Unlock()
+	if t.chats.optedIn == nil {
+		t.chats.optedIn = make(map[string]bool)
+	}
+	if allowed {
+		t.chats.optedIn[chatID] = true
+	} else {
+		delete(t.chats.optedIn, chatID)
+	}
+	if t.chats.path == "" {
+		return nil
+	}
+	chats := make([]string, 0, len(t.chats.optedIn))
+	for id := range t.chats.optedIn {
+		chats = append(chats, id)
+	}
+	sort.Strings(chats)
+	data, err := json.MarshalIndent(chats, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(t.chats.path, data, 0600)
+}
+
+// isChatAdmin reports whether userID may approve chats.
+func (t *Channel) isChatAdmin(userID string) bool {
+	t.allowMu.RLock()
+	defer t.allowMu.RUnlock()
+	return len(t.chats.admins) == 0 || t.chats.admins[userID]
+}
+
+// handleChatCommand answers /allowchat and /denychat in a group and reports
+// whether msg was one of them. Only chat admins get an answer; the commands
+// work in groups the bot otherwise ignores.
+func (t *Channel) handleChatCommand(msg *tgbotapi.Message, userID, chatID string) bool {
+	cmd := msg.Command()
+	if cmd != allowChatCommand && cmd != denyChatCommand {
+		return false
+	}
+	if !t.isChatAdmin(userID) {
+		slog.Warn("ignored chat approval from a non-admin", "chat_id", chatID, "sender", userID, "command", cmd)
+		return true
+	}
+
+	var reply string
+	switch cmd {
+	case allowChatCommand:
+		if err := t.AllowChat(chatID); err != nil {
+			slog.Error("failed to save chat approval", "chat_id", chatID, "err", err)
+			reply = fmt.Sprintf("⚠ Could not save the approval: %v", err)
+		} else {
+			slog.Info("chat approved", "chat_id", chatID, "by", userID)
+			reply = "✅ I'll work in this chat now. Send /denychat to stop."
+		}
+	case denyChatCommand:
+		if err := t.DenyChat(chatID); err != nil {
+			slog.Error("failed to save chat approval", "chat_id", chatID, "err", err)
+			reply = fmt.Sprintf("⚠ Could not save the change: %v", err)
+		} else {
+			slog.Info("chat approval withdrawn", "chat_id", chatID, "by", userID)
+			reply = "👋 I'll ignore this chat from now on."
+			if t.ChatAllowed(chatID, false) {
+				reply = "This chat is approved in the config, so I'll keep working here."
+			}
+		}
+	}
+	if _, err := t.bot.Send(tgbotapi.NewMessage(msg.Chat.ID, reply)); err != nil {
+		slog.Error("failed to answer chat command", "chat_id", chatID, "err", err)
+	}
+	return true
+}
+
+func toSet(items []string) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, s := range items {
+		if s != "" {
+			set[s] = true
+		}
+	}
+	return set
+}
@@ -0,0 +1,151 @@
+package telegram
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ProfileFile records the bot profile last set through the Bot API, so it is
+// only sent again when it changes. It lives in the profile directory.
+const ProfileFile = "TELEGRAM_PROFILE.json"
+
+// Bot API limits on the profile texts.
+const (
+	maxDescription        = 512
+	maxShortDescription   = 120
+	maxCommandDescription = 256
+)
+
+var commandName = regexp.MustCompile(`^[a-z0-9_]{1,32}$`)
+
+// Command is one entry of the bot's command menu.
+type Command struct {
+	Command     string `json:"command"` // without the leading slash
+	Description string `json:"description"`
+}
+
+// Profile is what users see of the bot before talking to it: the command
+// menu, the short description on its profile page, and the description in
+// an empty chat.
+type Profile struct {
+	Commands         []Command `json:"commands"`
+	ShortDescription string    `json:"short_description"`
+	Description      string    `json:"description"`
+}
+
+// DefaultCommands are the commands the bot answers in every chat.
+var DefaultCommands = []Command{
+	{"new", "Start a fresh conversation"},
+	{"stop", "Stop what I'm working on"},
+	{"stats", "Show token usage and cost"},
+	{"debug", "Show tool calls as they happen (on/off)"},
+}
+
+// groupCommands are added to the menu in group chats.
+var groupCommands = []Command{
+	{allowChatCommand, "Let me work in this group"},
+	{denyChatCommand, "Make me ignore this group"},
+}
+
+// DefaultProfile describes an assistant called name.
+func DefaultProfile(name string) Profile {
+	return Profile{
+		Commands:         DefaultCommands,
+		ShortDescription: truncateRunes(name+" is a personal AI assistant with long-term memory.", maxShortDescription),
+		Description: truncateRunes(fmt.Sprintf("Hi, I'm %s, a personal AI assistant. I remember what you tell me, "+
+			"search the web, run scheduled jobs, and work with your files and notes. "+
+			"Send me a message, a voice note, or a file to get started.", name), maxDescription),
+	}
+}
+
+// Validate checks p against the Bot API limits.
+func (p Profile) Validate() error {
+	if n := utf8.RuneCountInString(p.ShortDescription); n > maxShortDescription {
+		return fmt.Errorf("short description is %d characters; the limit is %d", n, maxShortDescription)
+	}
+	if n := utf8.RuneCountInString(p.Description); n > maxDescription {
+		return fmt.Errorf("description is %d characters; the limit is %d", n, maxDescription)
+	}
+	for _, c := range p.Commands {
+		if !commandName.MatchString(c.Command) {
+			return fmt.Errorf("command %q must be 1-32 lowercase letters, digits, or underscores", c.Command)
+		}
+		if n := utf8.RuneCountInString(c.Description); n == 0 || n > maxCommandDescription {
+			return fmt.Errorf("command %q needs a description of 1-%d characters", c.Command, maxCommandDescription)
+		}
+	}
+	return nil
+}
+
+// SetupProfile sets the bot's command menu, short description, and
+// description to p, unless the profile recorded at path already matches.
+// It records p at path once Telegram has accepted all of it. The channel
+// must be started.
+func (t *Channel) SetupProfile(p Profile, path string) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	var applied Profile
+	if data, err := os.ReadFile(path); err == nil && json.Unmarshal(data, &applied) == nil && reflect.DeepEqual(applied, p) {
+		slog.Debug("bot profile is up to date")
+		return nil
+	}
+
+	if err := t.setCommands(p.Commands, ""); err != nil {
+		return fmt.Errorf("set commands: %w", err)
+	}
+	if err := t.setCommands(append(append([]Command{}, p.Commands...), groupCommands...), "all_group_chats"); err != nil {
+		return fmt.Errorf("set group commands: %w", err)
+	}
+	params := tgbotapi.Params{"short_description": p.ShortDescription}
+	if _, err := t.bot.MakeRequest("setMyShortDescription", params); err != nil {
+		return fmt.Errorf("set short description: %w", err)
+	}
+	params = tgbotapi.Params{"description": p.Description}
+	if _, err := t.bot.MakeRequest("setMyDescription", params); err != nil {
+		return fmt.Errorf("set description: %w", err)
+	}
+	slog.Info("bot profile set", "commands", len(p.Commands))
+
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// setCommands replaces the command menu for scope, a BotCommandScope type;
+// "" is the default scope.
+func (t *Channel) setCommands(commands []Command, scope string) error {
+	if commands == nil {
+		commands = []Command{} // null is rejected; an empty list clears the menu
+	}
+	params := tgbotapi.Params{}
+	if err := params.AddInterface("commands", commands); err != nil {
+		return err
+	}
+	if scope != "" {
+		if err := params.AddInterface("scope", map[string]string{"type": scope}); err != nil {
+			return err
+		}
+	}
+	_, err := t.bot.MakeRequest("setMyCommands", params)
+	return err
+}
+
+// truncateRunes shortens s to at most n runes, ending it with "…" if cut.
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	r := []rune(s)
+	return strings.TrimSpace(string(r[:n-1])) + "…"
+}
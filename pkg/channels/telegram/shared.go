@@ -0,0 +1,98 @@
+package telegram
+
+import (
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// vCardFields maps the vCard properties worth keeping to their labels.
+var vCardFields = []struct{ prop, label string }{
+	{"EMAIL", "Email"},
+	{"ORG", "Organization"},
+	{"TITLE", "Title"},
+	{"ADR", "Address"},
+	{"BDAY", "Birthday"},
+	{"URL", "Website"},
+	{"NOTE", "Note"},
+}
+
+// DescribeContact turns a shared contact into a note for the agent with a
+// ready-made entity, so "save this contact" is one write_entity call.
+func DescribeContact(c *tgbotapi.Contact) string {
+	name := strings.TrimSpace(c.FirstName + " " + c.LastName)
+	if name == "" {
+		name = c.PhoneNumber
+	}
+
+	lines := []string{"- Name: " + name}
+	if c.PhoneNumber != "" {
+		lines = append(lines, "- Phone: "+c.PhoneNumber)
+	}
+	if c.UserID != 0 {
+		lines = append(lines, fmt.Sprintf("- Telegram user ID: %d", c.UserID))
+	}
+	lines = append(lines, vCardLines(c.VCard)...)
+
+	return fmt.Sprintf("[Contact shared: %s]\nSuggested entity %q (save it with write_entity if the user wants to keep this contact):\n%s",
+		name, name, strings.Join(lines, "\n"))
+}
+
+// vCardLines returns the useful vCard properties as "- Label: value" lines.
+// Telegram includes a vCard only when the sender's contact has more than a
+// phone number.
+func vCardLines(vcard string) []string {
+	var lines []string
+	for _, raw := range strings.Split(strings.ReplaceAll(vcard, "\r\n", "\n"), "\n") {
+		key, value, ok := strings.Cut(raw, ":")
+		if !ok || strings.TrimSpace(value) == "" {
+			continue
+		}
+		// Properties may carry parameters, as in "EMAIL;TYPE=work"
+		prop, _, _ := strings.Cut(strings.ToUpper(key), ";")
+		for _, f := range vCardFields {
+			if prop == f.prop {
+				value = strings.Trim(strings.ReplaceAll(value, ";", " "), " ")
+				lines = append(lines, fmt.Sprintf("- %s: %s", f.label, strings.Join(strings.Fields(value), " ")))
+			}
+		}
+	}
+	return lines
+}
+
+// DescribePoll lists a poll's question, options, and vote counts for the
+// agent, so it can discuss or suggest an answer.
+func DescribePoll(p *tgbotapi.Poll) string {
+	var traits []string
+	if p.Type == "quiz" {
+		traits = append(traits, "quiz")
+	}
+	if p.IsAnonymous {
+		traits = append(traits, "anonymous")
+	}
+	if p.AllowsMultipleAnswers {
+		traits = append(traits, "multiple answers allowed")
+	}
+	if p.IsClosed {
+		traits = append(traits, "closed")
+	}
+	traits = append(traits, plural(p.TotalVoterCount, "vote"))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "[Poll: %q (%s)]", p.Question, strings.Join(traits, ", "))
+	for i, o := range p.Options {
+		fmt.Fprintf(&b, "\n%d. %s — %s", i+1, o.Text, plural(o.VoterCount, "vote"))
+	}
+	if p.Explanation != "" {
+		fmt.Fprintf(&b, "\nExplanation: %s", p.Explanation)
+	}
+	return b.String()
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return "1 " + word
+	}
+	return fmt.Sprintf("%d %ss", n, word)
+}
@@ -3,31 +3,56 @@ package telegram
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"littleclaw/pkg/bus"
+	"littleclaw/pkg/httpclient"
 	"littleclaw/pkg/providers"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
+// botAPITimeout bounds Bot API calls; it must outlast the 60s long poll.
+const botAPITimeout = 90 * time.Second
+
+// downloadClient fetches voice messages and other files from Telegram.
+var downloadClient = httpclient.New(2 * time.Minute)
+
+// uploadLimit is the largest file the Bot API accepts from a bot. Larger
+// files fail with an opaque "Request Entity Too Large", so they are caught
+// before the upload.
+const uploadLimit = 50 << 20
+
+// maxMessageChars is the longest text Telegram takes in one message; longer
+// replies are split by the Dispatcher's adapter (see pkg/format).
+const maxMessageChars = 4096
+
 // Channel represents the Telegram integration
 type Channel struct {
 	bot                  *tgbotapi.BotAPI
 	bus                  *bus.MessageBus
 	token                string
-	allowFrom            map[string]bool // Set of allowed user IDs
 	transcriptionOptions providers.TranscriptionProvider
+	workspace            string // received videos are saved under its inbox/ (see video.go)
+
+	allowMu   sync.RWMutex
+	allowFrom map[string]bool // Set of allowed user IDs
+	chats     chatAllowlist   // group chats the bot works in (see chats.go)
 
 	typingMu      sync.Mutex
 	typingCancels map[int]context.CancelFunc
+
+	threads threadMemory // forum topics of recent messages (see topics.go)
 }
 
 // NewChannel creates a new Telegram channel
@@ -44,6 +69,25 @@ func NewChannel(token string, allowedUsers []string, messageBus *bus.MessageBus)
 	}
 }
 
+// SetAllowedUsers replaces the allowed user IDs; an empty list allows everyone.
+// It is safe to call while the channel is running.
+func (t *Channel) SetAllowedUsers(users []string) {
+	allowMap := make(map[string]bool, len(users))
+	for _, u := range users {
+		allowMap[u] = true
+	}
+	t.allowMu.Lock()
+	t.allowFrom = allowMap
+	t.allowMu.Unlock()
+}
+
+// isAllowed reports whether userID may talk to the bot.
+func (t *Channel) isAllowed(userID string) bool {
+	t.allowMu.RLock()
+	defer t.allowMu.RUnlock()
+	return len(t.allowFrom) == 0 || t.allowFrom[userID]
+}
+
 // SetTranscriptionProvider attaches a transcription engine to the channel
 func (t *Channel) SetTranscriptionProvider(p providers.TranscriptionProvider) {
 	t.transcriptionOptions = p
@@ -51,26 +95,27 @@ func (t *Channel) SetTranscriptionProvider(p providers.TranscriptionProvider) {
 
 // Start connects to Telegram and begins listening for messages
 func (t *Channel) Start(ctx context.Context) error {
-	bot, err := tgbotapi.NewBotAPI(t.token)
+	bot, err := tgbotapi.NewBotAPIWithClient(t.token, tgbotapi.APIEndpoint, httpclient.New(botAPITimeout))
 	if err != nil {
 		return fmt.Errorf("failed to init bot: %w", err)
 	}
 	t.bot = bot
 
-	u := tgbotapi.NewUpdate(0)
-	u.Timeout = 60
-	updates := bot.GetUpdatesChan(u)
+	updates := t.pollUpdates(ctx)
 
 	go func() {
 		for {
 			select {
 			case <-ctx.Done():
-				t.bot.StopReceivingUpdates()
 				return
 			case update, ok := <-updates:
 				if !ok {
 					return
 				}
+				if update.CallbackQuery != nil {
+					t.handleCallback(update.CallbackQuery)
+					continue
+				}
 				if update.Message == nil {
 					continue
 				}
@@ -79,11 +124,21 @@ func (t *Channel) Start(ctx context.Context) error {
 				chatID := strconv.FormatInt(update.Message.Chat.ID, 10)
 
 				// Security check: only process allowed users
-				if len(t.allowFrom) > 0 && !t.allowFrom[userID] {
+				if !t.isAllowed(userID) {
 					continue
 				}
+				// ...and, in groups, approved chats
+				if private := update.Message.Chat.IsPrivate(); !private {
+					if t.handleChatCommand(update.Message, userID, chatID) {
+						continue
+					}
+					if !t.ChatAllowed(chatID, private) {
+						slog.Debug("ignoring message from an unapproved chat", "chat_id", chatID, "sender", userID)
+						continue
+					}
+				}
 
-				t.handleIncoming(update, userID, chatID)
+				t.handleIncoming(update.Update, update.threadID, userID, chatID)
 			}
 		}
 	}()
@@ -112,13 +167,16 @@ func (t *Channel) setReaction(chatID string, messageID int, emoji string) {
 	t.bot.MakeRequest("setMessageReaction", req)
 }
 
-func (t *Channel) keepTyping(ctx context.Context, chatID string) {
+func (t *Channel) keepTyping(ctx context.Context, chatID string, threadID int) {
 	cID, err := strconv.ParseInt(chatID, 10, 64)
 	if err != nil {
 		return
 	}
-	action := tgbotapi.NewChatAction(cID, tgbotapi.ChatTyping)
-	t.bot.Send(action)
+	// Typing in a forum topic needs its thread, which the library cannot send
+	params := tgbotapi.Params{"action": tgbotapi.ChatTyping}
+	params.AddNonZero64("chat_id", cID)
+	params.AddNonZero("message_thread_id", threadID)
+	t.bot.MakeRequest("sendChatAction", params)
 
 	ticker := time.NewTicker(4 * time.Second)
 	defer ticker.Stop()
@@ -128,12 +186,12 @@ func (t *Channel) keepTyping(ctx context.Context, chatID string) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			t.bot.Send(action)
+			t.bot.MakeRequest("sendChatAction", params)
 		}
 	}
 }
 
-func (t *Channel) handleIncoming(update tgbotapi.Update, userID, chatID string) {
+func (t *Channel) handleIncoming(update tgbotapi.Update, threadID int, userID, chatID string) {
 	text := update.Message.Text
 	if update.Message.Caption != "" {
 		text = update.Message.Caption
@@ -151,6 +209,12 @@ func (t *Channel) handleIncoming(update tgbotapi.Update, userID, chatID string)
 			}
 			replyTo += fmt.Sprintf("[Document: %s]", update.Message.ReplyToMessage.Document.FileName)
 		}
+		if p := update.Message.ReplyToMessage.Poll; p != nil {
+			replyTo = strings.TrimSpace(replyTo + "\n" + DescribePoll(p))
+		}
+		if c := update.Message.ReplyToMessage.Contact; c != nil {
+			replyTo = strings.TrimSpace(replyTo + "\n" + DescribeContact(c))
+		}
 	}
 
 	var mediaURLs []string
@@ -168,20 +232,20 @@ func (t *Channel) handleIncoming(update tgbotapi.Update, userID, chatID string)
 	// Handle voice messages (transcription)
 	if update.Message.Voice != nil && t.transcriptionOptions != nil {
 		voice := update.Message.Voice
-		log.Printf("🎙️ Received voice message (ID: %s, duration: %ds). Transcribing...", voice.FileID, voice.Duration)
+		slog.Info("transcribing voice message", "chat_id", chatID, "file_id", voice.FileID, "duration_s", voice.Duration)
 		fileURL, err := t.bot.GetFileDirectURL(voice.FileID)
 		if err != nil {
-			log.Printf("❌ Failed to get voice file URL: %v", err)
+			slog.Error("failed to get voice file URL", "chat_id", chatID, "err", err)
 		} else {
 			// Download to temporary file
-			resp, err := http.Get(fileURL)
+			resp, err := downloadClient.Get(fileURL)
 			if err != nil {
-				log.Printf("❌ Failed to download voice file: %v", err)
+				slog.Error("failed to download voice file", "chat_id", chatID, "err", err)
 			} else {
 				defer resp.Body.Close()
 				tmpFile, err := os.CreateTemp("", "voice_*.ogg")
 				if err != nil {
-					log.Printf("❌ Failed to create temp file for voice: %v", err)
+					slog.Error("failed to create temp file for voice", "err", err)
 				} else {
 					defer os.Remove(tmpFile.Name())
 					io.Copy(tmpFile, resp.Body)
@@ -190,9 +254,9 @@ func (t *Channel) handleIncoming(update tgbotapi.Update, userID, chatID string)
 					// Transcribe
 					transcription, err := t.transcriptionOptions.Transcribe(context.Background(), tmpFile.Name())
 					if err != nil {
-						log.Printf("❌ Transcription failed: %v", err)
+						slog.Error("transcription failed", "chat_id", chatID, "err", err)
 					} else {
-						log.Printf("✅ Transcription successful: %s", transcription)
+						slog.Debug("transcription done", "chat_id", chatID, "text", transcription)
 						if text != "" {
 							text += "\n"
 						}
@@ -205,6 +269,26 @@ func (t *Channel) handleIncoming(update tgbotapi.Update, userID, chatID string)
 
 	msgID := update.Message.MessageID
 
+	// Shared contacts and polls are described so the agent can save or discuss them
+	if c := update.Message.Contact; c != nil {
+		text = strings.TrimSpace(text + "\n" + DescribeContact(c))
+	}
+	if p := update.Message.Poll; p != nil {
+		text = strings.TrimSpace(text + "\n" + DescribePoll(p))
+	}
+
+	// Videos, video notes, and GIFs are saved to the inbox and described
+	if v := videoFrom(update.Message); v != nil {
+		note, thumbURL := t.handleVideo(chatID, msgID, v)
+		if text != "" {
+			text += "\n"
+		}
+		text += note
+		if thumbURL != "" {
+			mediaURLs = append(mediaURLs, thumbURL)
+		}
+	}
+
 	t.typingMu.Lock()
 	if cancel, exists := t.typingCancels[msgID]; exists {
 		cancel()
@@ -213,22 +297,80 @@ func (t *Channel) handleIncoming(update tgbotapi.Update, userID, chatID string)
 	t.typingCancels[msgID] = cancel
 	t.typingMu.Unlock()
 
-	go t.keepTyping(ctx, chatID)
+	go t.keepTyping(ctx, chatID, threadID)
 	t.setReaction(chatID, msgID, "👍")
+	t.threads.remember(chatID, msgID, threadID)
 
 	t.bus.SendInbound(bus.InboundMessage{
-		Channel:   "telegram",
-		SenderID:  userID,
-		ChatID:    chatID,
-		MessageID: msgID,
-		Content:   text,
-		ReplyTo:   replyTo,
-		Media:     mediaURLs,
+		Channel:    "telegram",
+		SenderID:   userID,
+		SenderName: senderName(update.Message.From),
+		ChatID:     chatID,
+		MessageID:  msgID,
+		ThreadID:   threadID,
+		Content:    text,
+		ReplyTo:    replyTo,
+		Media:      mediaURLs,
 	})
 }
 
-// SendMessage sends a response back to the Telegram chat
-func (t *Channel) SendMessage(ctx context.Context, chatID string, replyToMessageID int, content string, files []string) error {
+// senderName returns the user's first (and last) name, or their @username.
+func senderName(u *tgbotapi.User) string {
+	if u == nil {
+		return ""
+	}
+	if name := strings.TrimSpace(u.FirstName + " " + u.LastName); name != "" {
+		return name
+	}
+	return u.UserName
+}
+
+// Send delivers an outbound message from the bus, as an approval prompt when
+// it carries an ApprovalID. It makes Channel a bus.Sender; failures come back
+// as a *bus.DeliveryError (see deliveryError).
+func (t *Channel) Send(ctx context.Context, msg bus.OutboundMessage) error {
+	threadID := msg.ThreadID
+	if threadID == 0 && msg.ReplyToMessageID != 0 {
+		threadID = t.threads.lookup(msg.ChatID, msg.ReplyToMessageID)
+	}
+	if msg.ApprovalID != "" {
+		return deliveryError(t.SendApprovalRequest(ctx, msg.ChatID, threadID, msg.ApprovalID, msg.Content, msg.Markup))
+	}
+	return deliveryError(t.SendMessage(ctx, msg.ChatID, threadID, msg.ReplyToMessageID, msg.Content, msg.Markup, msg.Files))
+}
+
+// Capabilities makes Channel a bus.CapableSender: Telegram's HTML subset,
+// 4096 characters a message, files, and forum topics.
+func (t *Channel) Capabilities() bus.Capabilities {
+	return bus.Capabilities{Markup: bus.MarkupHTML, MaxMessageChars: maxMessageChars, Files: true, Threads: true}
+}
+
+// deliveryError says whether a failed send may be retried. Flood control
+// (429, with the wait in retry_after), server errors (5xx), and network errors
+// are temporary. Other API errors, such as a chat that does not exist or a
+// user who blocked the bot, are permanent, as are a bad chat ID and a missing
+// file.
+func deliveryError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var apiErr *tgbotapi.Error
+	switch {
+	case errors.As(err, &apiErr) && apiErr.Code == http.StatusTooManyRequests:
+		return &bus.DeliveryError{Err: err, RetryAfter: time.Duration(apiErr.RetryAfter) * time.Second}
+	case errors.As(err, &apiErr) && apiErr.Code >= 500:
+		return &bus.DeliveryError{Err: err}
+	case errors.As(err, &apiErr) && apiErr.Code >= 400:
+		return &bus.DeliveryError{Err: err, Permanent: true}
+	case errors.Is(err, strconv.ErrSyntax) || errors.Is(err, strconv.ErrRange) || errors.Is(err, os.ErrNotExist):
+		return &bus.DeliveryError{Err: err, Permanent: true}
+	}
+	return &bus.DeliveryError{Err: err}
+}
+
+// SendMessage sends a response back to the Telegram chat, in forum topic
+// threadID when it is non-zero, with content formatted as markup.
+func (t *Channel) SendMessage(ctx context.Context, chatID string, threadID, replyToMessageID int, content string, markup bus.Markup, files []string) error {
 	id, err := strconv.ParseInt(chatID, 10, 64)
 	if err != nil {
 		return fmt.Errorf("invalid chat ID: %w", err)
@@ -246,22 +388,131 @@ func (t *Channel) SendMessage(ctx context.Context, chatID string, replyToMessage
 		t.typingMu.Unlock()
 	}
 
-	// 1. Send all attached files
+	// 1. Send all attached files, images as albums captioned with the
+	// content when it fits
+	albums, files := SplitAlbums(files)
+	for i, album := range albums {
+		caption := ""
+		if i == 0 && fitsCaption(content) {
+			caption, content = content, ""
+		}
+		if err := t.sendAlbum(id, threadID, album, caption, markup); err != nil {
+			return fmt.Errorf("failed to send album: %w", err)
+		}
+	}
 	for _, file := range files {
-		// Use native tgbotapi Document sender
-		doc := tgbotapi.NewDocument(id, tgbotapi.FilePath(file))
-		if _, err := t.bot.Send(doc); err != nil {
+		if info, err := os.Stat(file); err == nil && info.Size() > uploadLimit {
+			slog.Warn("file too large for telegram", "file", file, "bytes", info.Size())
+			if err := t.sendText(id, threadID, oversizedFileNotice(filepath.Base(file), info.Size()), "", nil); err != nil {
+				return fmt.Errorf("failed to send text message: %w", err)
+			}
+			continue
+		}
+		if err := t.sendDocument(id, threadID, file); err != nil {
 			return fmt.Errorf("failed to send file %s: %w", file, err)
 		}
 	}
 
 	// 2. Send the text content if present
 	if content != "" {
-		msg := tgbotapi.NewMessage(id, content)
-		if _, err := t.bot.Send(msg); err != nil {
+		if err := t.sendText(id, threadID, content, markup, nil); err != nil {
 			return fmt.Errorf("failed to send text message: %w", err)
 		}
 	}
 
 	return nil
 }
+
+// oversizedFileNotice is what the user gets instead of a file over the
+// upload limit.
+func oversizedFileNotice(name string, size int64) string {
+	return fmt.Sprintf("⚠️ Could not send %s: it is %.1f MB and Telegram bots can only send files up to %d MB. Ask me to compress it, split it into parts, or share a download link.",
+		name, float64(size)/(1<<20), uploadLimit>>20)
+}
+
+// SendApprovalRequest sends a prompt, formatted as markup, with inline
+// Approve / Deny buttons. The user's tap comes back through handleCallback as
+// a bus.ApprovalDecision.
+func (t *Channel) SendApprovalRequest(ctx context.Context, chatID string, threadID int, approvalID, content string, markup bus.Markup) error {
+	id, err := strconv.ParseInt(chatID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid chat ID: %w", err)
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Approve", "approve:"+approvalID),
+			tgbotapi.NewInlineKeyboardButtonData("❌ Deny", "deny:"+approvalID),
+		),
+	)
+	if err := t.sendText(id, threadID, content, markup, keyboard); err != nil {
+		return fmt.Errorf("failed to send approval request: %w", err)
+	}
+	return nil
+}
+
+// handleCallback processes inline keyboard taps (currently only approval prompts).
+func (t *Channel) handleCallback(cb *tgbotapi.CallbackQuery) {
+	userID := strconv.FormatInt(cb.From.ID, 10)
+	if !t.isAllowed(userID) {
+		return
+	}
+	if cb.Message != nil && !t.ChatAllowed(strconv.FormatInt(cb.Message.Chat.ID, 10), cb.Message.Chat.IsPrivate()) {
+		return
+	}
+
+	action, approvalID, found := strings.Cut(cb.Data, ":")
+	if !found || (action != "approve" && action != "deny") {
+		return
+	}
+	approved := action == "approve"
+
+	result := make(chan error, 1)
+	t.bus.SendApproval(bus.ApprovalDecision{
+		ID:       approvalID,
+		Approved: approved,
+		SenderID: userID,
+		Result:   result,
+	})
+	// Wait for the agent off the update loop, so other updates keep flowing
+	go t.answerApproval(cb, approved, result)
+}
+
+// approvalAnswerTimeout bounds the wait for the agent to apply a decision.
+const approvalAnswerTimeout = 10 * time.Second
+
+// answerApproval tells the tapping user what became of their decision. A
+// refused sender gets a notice and the buttons stay for someone who may
+// answer.
+func (t *Channel) answerApproval(cb *tgbotapi.CallbackQuery, approved bool, result <-chan error) {
+	var refused error
+	select {
+	case refused = <-result:
+	case <-time.After(approvalAnswerTimeout):
+		refused = bus.ErrApprovalNotPending
+	}
+
+	answer := "Denied"
+	if approved {
+		answer = "Approved"
+	}
+	switch {
+	case errors.Is(refused, bus.ErrNotApprover):
+		answer = "⛔ Only the person who asked, or an admin, can answer this."
+	case refused != nil:
+		answer = "This request is no longer pending."
+	}
+	if _, err := t.bot.Request(tgbotapi.NewCallback(cb.ID, answer)); err != nil {
+		slog.Error("failed to answer callback query", "err", err)
+	}
+	if refused != nil || cb.Message == nil {
+		return
+	}
+
+	// Replace the buttons with the decision so the prompt can't be answered twice
+	edit := tgbotapi.NewEditMessageText(cb.Message.Chat.ID, cb.Message.MessageID,
+		fmt.Sprintf("%s\n\n➡️ %s", cb.Message.Text, answer))
+	if _, err := t.bot.Send(edit); err != nil {
+		slog.Error("failed to update approval message", "err", err)
+	}
+}
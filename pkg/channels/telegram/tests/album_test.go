@@ -0,0 +1,64 @@
+package telegram_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"littleclaw/pkg/channels/telegram"
+)
+
+// touch creates small files named names in a temp dir and returns their paths.
+func touch(t *testing.T, names ...string) []string {
+	t.Helper()
+	dir := t.TempDir()
+	var paths []string
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func TestSplitAlbums(t *testing.T) {
+	files := touch(t, "a.png", "report.pdf", "b.JPG", "c.webp", "anim.gif")
+	albums, rest := telegram.SplitAlbums(files)
+	if want := [][]string{{files[0], files[2], files[3]}}; !reflect.DeepEqual(albums, want) {
+		t.Errorf("albums = %v, want %v", albums, want)
+	}
+	if want := []string{files[1], files[4]}; !reflect.DeepEqual(rest, want) {
+		t.Errorf("rest = %v, want %v", rest, want)
+	}
+}
+
+func TestSplitAlbums_LoneImageIsADocument(t *testing.T) {
+	files := touch(t, "notes.txt", "a.png")
+	albums, rest := telegram.SplitAlbums(files)
+	if len(albums) != 0 || len(rest) != 2 {
+		t.Errorf("albums = %v, rest = %v", albums, rest)
+	}
+}
+
+func TestSplitAlbums_EvenBatches(t *testing.T) {
+	var names []string
+	for i := 0; i < 11; i++ {
+		names = append(names, fmt.Sprintf("%02d.jpg", i))
+	}
+	albums, rest := telegram.SplitAlbums(touch(t, names...))
+	if len(albums) != 2 || len(albums[0]) != 5 || len(albums[1]) != 6 || len(rest) != 0 {
+		t.Errorf("got %d albums %v, rest %v", len(albums), albums, rest)
+	}
+}
+
+func TestSplitAlbums_MissingImageIsNotInAnAlbum(t *testing.T) {
+	files := append(touch(t, "a.png", "b.png"), "/nonexistent/c.png")
+	albums, rest := telegram.SplitAlbums(files)
+	if len(albums) != 1 || len(albums[0]) != 2 || len(rest) != 1 {
+		t.Errorf("albums = %v, rest = %v", albums, rest)
+	}
+}
@@ -0,0 +1,52 @@
+package telegram_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/channels/telegram"
+)
+
+func TestChatAllowed(t *testing.T) {
+	ch := telegram.NewChannel("token", []string{"42"}, bus.NewMessageBus())
+	ch.SetAllowedChats([]string{"-100"})
+
+	if !ch.ChatAllowed("42", true) {
+		t.Error("private chats should be allowed")
+	}
+	if !ch.ChatAllowed("-100", false) {
+		t.Error("configured group should be allowed")
+	}
+	if ch.ChatAllowed("-200", false) {
+		t.Error("an unlisted group should be ignored")
+	}
+}
+
+func TestChatOptIns_Persist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), telegram.ChatsFile)
+	ch := telegram.NewChannel("token", nil, bus.NewMessageBus())
+	if err := ch.LoadChatOptIns(path); err != nil {
+		t.Fatalf("LoadChatOptIns() on a missing file = %v", err)
+	}
+	if err := ch.AllowChat("-300"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ch.AllowChat("-400"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ch.DenyChat("-400"); err != nil {
+		t.Fatal(err)
+	}
+
+	restarted := telegram.NewChannel("token", nil, bus.NewMessageBus())
+	if err := restarted.LoadChatOptIns(path); err != nil {
+		t.Fatal(err)
+	}
+	if !restarted.ChatAllowed("-300", false) {
+		t.Error("an approved chat should stay approved after a restart")
+	}
+	if restarted.ChatAllowed("-400", false) {
+		t.Error("a denied chat should stay denied")
+	}
+}
@@ -0,0 +1,48 @@
+package telegram_test
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"littleclaw/pkg/channels/telegram"
+)
+
+func TestDefaultProfile(t *testing.T) {
+	p := telegram.DefaultProfile("Clawde")
+	if err := p.Validate(); err != nil {
+		t.Fatalf("default profile invalid: %v", err)
+	}
+	if !strings.Contains(p.ShortDescription, "Clawde") || !strings.Contains(p.Description, "Clawde") {
+		t.Errorf("profile does not name the assistant: %+v", p)
+	}
+	if len(p.Commands) == 0 || p.Commands[0].Command != "new" {
+		t.Errorf("commands = %+v", p.Commands)
+	}
+}
+
+func TestDefaultProfile_LongNameIsTruncated(t *testing.T) {
+	p := telegram.DefaultProfile(strings.Repeat("ü", 200))
+	if err := p.Validate(); err != nil {
+		t.Fatalf("profile invalid: %v", err)
+	}
+	if n := utf8.RuneCountInString(p.ShortDescription); n != 120 || !strings.HasSuffix(p.ShortDescription, "…") {
+		t.Errorf("short description has %d runes: %q", n, p.ShortDescription)
+	}
+}
+
+func TestProfileValidate(t *testing.T) {
+	for name, p := range map[string]telegram.Profile{
+		"slash in command":     {Commands: []telegram.Command{{Command: "/new", Description: "x"}}},
+		"uppercase command":    {Commands: []telegram.Command{{Command: "New", Description: "x"}}},
+		"no description":       {Commands: []telegram.Command{{Command: "new"}}},
+		"long short desc":      {ShortDescription: strings.Repeat("a", 121)},
+		"long description":     {Description: strings.Repeat("a", 513)},
+		"long command name":    {Commands: []telegram.Command{{Command: strings.Repeat("a", 33), Description: "x"}}},
+		"long command details": {Commands: []telegram.Command{{Command: "new", Description: strings.Repeat("a", 257)}}},
+	} {
+		if err := p.Validate(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package telegram_test
+
+import (
+	"strings"
+	"testing"
+
+	"littleclaw/pkg/channels/telegram"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestDescribeContact(t *testing.T) {
+	note := telegram.DescribeContact(&tgbotapi.Contact{
+		PhoneNumber: "+15551234567",
+		FirstName:   "Jane",
+		LastName:    "Doe",
+		UserID:      42,
+		VCard:       "BEGIN:VCARD\r\nVERSION:3.0\r\nFN:Jane Doe\r\nEMAIL;TYPE=work:jane@example.com\r\nORG:Acme;Research\r\nEND:VCARD",
+	})
+	for _, want := range []string{
+		"[Contact shared: Jane Doe]",
+		`Suggested entity "Jane Doe"`,
+		"write_entity",
+		"- Phone: +15551234567",
+		"- Telegram user ID: 42",
+		"- Email: jane@example.com",
+		"- Organization: Acme Research",
+	} {
+		if !strings.Contains(note, want) {
+			t.Errorf("note missing %q:\n%s", want, note)
+		}
+	}
+	if strings.Contains(note, "VERSION") || strings.Contains(note, "FN") {
+		t.Errorf("note kept vCard boilerplate:\n%s", note)
+	}
+}
+
+func TestDescribeContact_PhoneOnly(t *testing.T) {
+	note := telegram.DescribeContact(&tgbotapi.Contact{PhoneNumber: "+15550000000"})
+	if !strings.HasPrefix(note, "[Contact shared: +15550000000]") {
+		t.Errorf("note = %q", note)
+	}
+}
+
+func TestDescribePoll(t *testing.T) {
+	note := telegram.DescribePoll(&tgbotapi.Poll{
+		Question:              "Where should we eat?",
+		Options:               []tgbotapi.PollOption{{Text: "Pizza", VoterCount: 1}, {Text: "Sushi", VoterCount: 3}},
+		TotalVoterCount:       4,
+		IsAnonymous:           true,
+		AllowsMultipleAnswers: true,
+		Type:                  "regular",
+	})
+	want := "[Poll: \"Where should we eat?\" (anonymous, multiple answers allowed, 4 votes)]\n1. Pizza — 1 vote\n2. Sushi — 3 votes"
+	if note != want {
+		t.Errorf("got\n%s\nwant\n%s", note, want)
+	}
+}
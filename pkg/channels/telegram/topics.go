@@ -0,0 +1,187 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/format"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Forum topics. The Bot API library predates them, so the channel reads
+// message_thread_id from the raw updates itself and adds it when sending.
+
+const (
+	// pollTimeout is the getUpdates long-poll timeout in seconds.
+	pollTimeout = 60
+	// pollRetry is the pause after a failed getUpdates call.
+	pollRetry = 3 * time.Second
+	// maxRememberedThreads bounds how many inbound messages keep their topic
+	// for replies that do not carry one.
+	maxRememberedThreads = 1000
+)
+
+// topicUpdate is an update together with the forum topic of its message;
+// threadID is 0 outside forum topics.
+type topicUpdate struct {
+	tgbotapi.Update
+	threadID int
+}
+
+// rawTopic is the part of an update the library does not decode.
+type rawTopic struct {
+	Message *struct {
+		MessageThreadID int  `json:"message_thread_id"`
+		IsTopicMessage  bool `json:"is_topic_message"`
+	} `json:"message"`
+}
+
+// threadMemory remembers the topic of recent inbound messages, so a reply to
+// one lands in its topic even when the outbound message names none (command
+// answers, for example).
+type threadMemory struct {
+	mu    sync.Mutex
+	byMsg map[string]int
+	order []string
+}
+
+func threadKey(chatID string, messageID int) string {
+	return chatID + ":" + strconv.Itoa(messageID)
+}
+
+// remember records the topic of a message; messages outside topics are skipped.
+func (m *threadMemory) remember(chatID string, messageID, threadID int) {
+	if threadID == 0 {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.byMsg == nil {
+		m.byMsg = make(map[string]int)
+	}
+	key := threadKey(chatID, messageID)
+	if _, ok := m.byMsg[key]; !ok {
+		m.order = append(m.order, key)
+	}
+	m.byMsg[key] = threadID
+	for len(m.order) > maxRememberedThreads {
+		delete(m.byMsg, m.order[0])
+		m.order = m.order[1:]
+	}
+}
+
+// lookup returns the topic of a remembered message, or 0.
+func (m *threadMemory) lookup(chatID string, messageID int) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.byMsg[threadKey(chatID, messageID)]
+}
+
+// pollUpdates long-polls getUpdates until ctx is cancelled, like the
+// library's GetUpdatesChan but keeping each message's forum topic.
+func (t *Channel) pollUpdates(ctx context.Context) <-chan topicUpdate {
+	ch := make(chan topicUpdate, 100)
+	go func() {
+		defer close(ch)
+		offset := 0
+		for ctx.Err() == nil {
+			params := tgbotapi.Params{}
+			params.AddNonZero("offset", offset)
+			params.AddNonZero("timeout", pollTimeout)
+			resp, err := t.bot.MakeRequest("getUpdates", params)
+			if err == nil {
+				var updates []tgbotapi.Update
+				var topics []rawTopic
+				if err = json.Unmarshal(resp.Result, &updates); err == nil {
+					err = json.Unmarshal(resp.Result, &topics)
+				}
+				if err == nil {
+					for i, u := range updates {
+						if u.UpdateID < offset {
+							continue
+						}
+						offset = u.UpdateID + 1
+						tu := topicUpdate{Update: u}
+						if m := topics[i].Message; m != nil && m.IsTopicMessage {
+							tu.threadID = m.MessageThreadID
+						}
+						select {
+						case ch <- tu:
+						case <-ctx.Done():
+							return
+						}
+					}
+					continue
+				}
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Warn("failed to get telegram updates, retrying", "err", err, "in", pollRetry)
+			select {
+			case <-time.After(pollRetry):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// sendText sends text, formatted as markup, to chat id, in topic threadID
+// when it is non-zero. keyboard, if not nil, is the reply_markup. HTML that
+// Telegram can't parse is sent again as plain text.
+func (t *Channel) sendText(id int64, threadID int, text string, markup bus.Markup, keyboard interface{}) error {
+	if markup != bus.MarkupHTML {
+		return t.sendMessage(id, threadID, text, "", keyboard)
+	}
+	err := t.sendMessage(id, threadID, text, tgbotapi.ModeHTML, keyboard)
+	if err != nil && strings.Contains(err.Error(), "can't parse entities") {
+		slog.Warn("telegram rejected the formatting, sending plain text", "err", err)
+		err = t.sendMessage(id, threadID, format.StripHTML(text), "", keyboard)
+	}
+	return err
+}
+
+func (t *Channel) sendMessage(id int64, threadID int, text, parseMode string, keyboard interface{}) error {
+	if threadID == 0 {
+		msg := tgbotapi.NewMessage(id, text)
+		msg.ParseMode = parseMode
+		if keyboard != nil {
+			msg.ReplyMarkup = keyboard
+		}
+		_, err := t.bot.Send(msg)
+		return err
+	}
+	params := tgbotapi.Params{}
+	params.AddNonZero64("chat_id", id)
+	params.AddNonZero("message_thread_id", threadID)
+	params.AddNonEmpty("text", text)
+	params.AddNonEmpty("parse_mode", parseMode)
+	if err := params.AddInterface("reply_markup", keyboard); err != nil {
+		return err
+	}
+	_, err := t.bot.MakeRequest("sendMessage", params)
+	return err
+}
+
+// sendDocument uploads the file at path to chat id, in topic threadID when
+// it is non-zero.
+func (t *Channel) sendDocument(id int64, threadID int, path string) error {
+	if threadID == 0 {
+		_, err := t.bot.Send(tgbotapi.NewDocument(id, tgbotapi.FilePath(path)))
+		return err
+	}
+	params := tgbotapi.Params{}
+	params.AddNonZero64("chat_id", id)
+	params.AddNonZero("message_thread_id", threadID)
+	_, err := t.bot.UploadFiles("sendDocument", params, []tgbotapi.RequestFile{{Name: "document", Data: tgbotapi.FilePath(path)}})
+	return err
+}
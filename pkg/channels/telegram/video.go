@@ -0,0 +1,205 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// InboxDir is where received videos and GIFs are saved, relative to the
+// workspace.
+const InboxDir = "inbox"
+
+const (
+	// downloadLimit is the largest file the Bot API lets a bot download.
+	downloadLimit = 20 << 20
+	// ffmpegTimeout bounds one thumbnail or audio extraction.
+	ffmpegTimeout = 2 * time.Minute
+)
+
+// video is what the channel needs from a video, video note, or animation.
+type video struct {
+	kind     string // shown to the agent: "Video", "Video note", or "GIF"
+	fileID   string
+	fileName string
+	duration int // seconds
+	width    int
+	height   int
+	size     int
+	thumb    *tgbotapi.PhotoSize
+	hasAudio bool
+}
+
+// SetWorkspace saves received videos and GIFs under dir/inbox, so the agent
+// can work with them. Without it they are only described.
+func (t *Channel) SetWorkspace(dir string) {
+	t.workspace = dir
+}
+
+// videoFrom returns the video attached to m, if any. Telegram sends GIFs as
+// silent MP4 animations.
+func videoFrom(m *tgbotapi.Message) *video {
+	switch {
+	case m.Animation != nil:
+		a := m.Animation
+		return &video{kind: "GIF", fileID: a.FileID, fileName: a.FileName, duration: a.Duration,
+			width: a.Width, height: a.Height, size: a.FileSize, thumb: a.Thumbnail}
+	case m.Video != nil:
+		v := m.Video
+		return &video{kind: "Video", fileID: v.FileID, fileName: v.FileName, duration: v.Duration,
+			width: v.Width, height: v.Height, size: v.FileSize, thumb: v.Thumbnail, hasAudio: true}
+	case m.VideoNote != nil:
+		v := m.VideoNote
+		return &video{kind: "Video note", fileID: v.FileID, duration: v.Duration,
+			width: v.Length, height: v.Length, size: v.FileSize, thumb: v.Thumbnail, hasAudio: true}
+	}
+	return nil
+}
+
+// handleVideo saves v into the workspace inbox, pulls out its audio track
+// (transcribed when a transcriber is set) and, lacking a Telegram thumbnail,
+// a still frame. It returns a note describing the attachment for the agent
+// and the thumbnail's URL, if Telegram has one, for the image pipeline.
+func (t *Channel) handleVideo(chatID string, msgID int, v *video) (note, thumbURL string) {
+	if v.thumb != nil {
+		if u, err := t.bot.GetFileDirectURL(v.thumb.FileID); err == nil {
+			thumbURL = u
+		}
+	}
+
+	details := []string{formatClipLength(v.duration)}
+	if v.width > 0 && v.height > 0 {
+		details = append(details, fmt.Sprintf("%dx%d", v.width, v.height))
+	}
+	if v.size > 0 {
+		details = append(details, fmt.Sprintf("%.1f MB", float64(v.size)/(1<<20)))
+	}
+	describe := func(where string, extra ...string) string {
+		return fmt.Sprintf("[%s attached: %s (%s)%s]", v.kind, where, strings.Join(details, ", "), strings.Join(extra, ""))
+	}
+
+	if v.size > downloadLimit {
+		return describe(v.name(), fmt.Sprintf(" — too large to download; bots can only fetch files up to %d MB", downloadLimit>>20)), thumbURL
+	}
+	if t.workspace == "" {
+		return describe(v.name(), " — not saved"), thumbURL
+	}
+
+	rel := filepath.Join(InboxDir, fmt.Sprintf("%s-%d-%s", time.Now().Format("20060102-150405"), msgID, v.name()))
+	path := filepath.Join(t.workspace, rel)
+	if err := t.download(v.fileID, path); err != nil {
+		slog.Error("failed to save video", "chat_id", chatID, "err", err)
+		return describe(v.name(), " — download failed"), thumbURL
+	}
+	slog.Info("saved video to inbox", "chat_id", chatID, "kind", v.kind, "path", rel)
+
+	var extra []string
+	var transcript string
+	if _, err := exec.LookPath("ffmpeg"); err == nil {
+		base := strings.TrimSuffix(rel, filepath.Ext(rel))
+		if v.hasAudio {
+			if err := runFFmpeg("-i", path, "-vn", "-ac", "1", "-ar", "16000", filepath.Join(t.workspace, base+".wav")); err != nil {
+				slog.Debug("no audio track extracted", "path", rel, "err", err)
+			} else {
+				extra = append(extra, "; audio: "+base+".wav")
+				transcript = t.transcribe(chatID, filepath.Join(t.workspace, base+".wav"))
+			}
+		}
+		if thumbURL == "" {
+			if err := runFFmpeg("-i", path, "-frames:v", "1", filepath.Join(t.workspace, base+".jpg")); err != nil {
+				slog.Debug("no thumbnail extracted", "path", rel, "err", err)
+			} else {
+				extra = append(extra, "; thumbnail: "+base+".jpg")
+			}
+		}
+	}
+
+	note = describe(rel, extra...)
+	if transcript != "" {
+		note += "\n[Video Transcription]: " + transcript
+	}
+	return note, thumbURL
+}
+
+// name is the file name to save the video under.
+func (v *video) name() string {
+	if name := filepath.Base(v.fileName); v.fileName != "" && name != "." && name != "/" {
+		return name
+	}
+	switch v.kind {
+	case "GIF":
+		return "animation.mp4"
+	case "Video note":
+		return "video_note.mp4"
+	}
+	return "video.mp4"
+}
+
+// download saves the Telegram file fileID to path.
+func (t *Channel) download(fileID, path string) error {
+	fileURL, err := t.bot.GetFileDirectURL(fileID)
+	if err != nil {
+		return err
+	}
+	resp, err := downloadClient.Get(fileURL)
+	if err != nil {
+		// The URL embeds the bot token, so don't echo it back
+		return fmt.Errorf("download failed")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download failed (status %d)", resp.StatusCode)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	return f.Close()
+}
+
+// transcribe returns the speech in the audio file at path, or "" without a
+// transcriber or on failure.
+func (t *Channel) transcribe(chatID, path string) string {
+	if t.transcriptionOptions == nil {
+		return ""
+	}
+	text, err := t.transcriptionOptions.Transcribe(context.Background(), path)
+	if err != nil {
+		slog.Error("transcription failed", "chat_id", chatID, "err", err)
+		return ""
+	}
+	return strings.TrimSpace(text)
+}
+
+// runFFmpeg runs ffmpeg quietly with args, overwriting the output.
+func runFFmpeg(args ...string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), ffmpegTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "ffmpeg", append([]string{"-y", "-loglevel", "error"}, args...)...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// formatClipLength formats seconds as m:ss.
+func formatClipLength(seconds int) string {
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
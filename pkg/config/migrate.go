@@ -0,0 +1,122 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+// CurrentVersion is the config_version this build writes. Bump it and append
+// to migrations when a change would break older config files.
+const CurrentVersion = 2
+
+// rawConfig is config.json as top-level keys, so migrations can rename and
+// move fields before it is decoded into AppConfig.
+type rawConfig map[string]json.RawMessage
+
+// migration upgrades a config from version to-1 to version to.
+type migration struct {
+	to      int
+	summary string
+	apply   func(rawConfig) error
+}
+
+// migrations run in order; files without config_version are version 1.
+var migrations = []migration{
+	{2, "move transcription_* into the transcription section", migrateTranscription},
+}
+
+// migrate upgrades raw to CurrentVersion in place and returns the version it
+// started from.
+func migrate(raw rawConfig) (int, error) {
+	from := 1
+	if v, ok := raw["config_version"]; ok {
+		if err := json.Unmarshal(v, &from); err != nil {
+			return 0, fmt.Errorf("invalid config_version: %w", err)
+		}
+	}
+	if from > CurrentVersion {
+		return from, fmt.Errorf("config_version %d is newer than this littleclaw supports (%d); please upgrade littleclaw", from, CurrentVersion)
+	}
+	for _, m := range migrations {
+		if m.to <= from {
+			continue
+		}
+		if err := m.apply(raw); err != nil {
+			return from, fmt.Errorf("migrating config to version %d (%s): %w", m.to, m.summary, err)
+		}
+	}
+	raw["config_version"] = json.RawMessage(fmt.Sprint(CurrentVersion))
+	return from, nil
+}
+
+// migrateTranscription moves the flat transcription_* fields into the
+// transcription section, unless the section is already set.
+func migrateTranscription(raw rawConfig) error {
+	fields := map[string]string{
+		"transcription_provider": "provider",
+		"transcription_apikey":   "apikey",
+		"transcription_baseurl":  "baseurl",
+		"transcription_model":    "model",
+	}
+	section := map[string]string{}
+	if s, ok := raw["transcription"]; ok {
+		if err := json.Unmarshal(s, &section); err != nil {
+			return err
+		}
+	}
+	for old, name := range fields {
+		v, ok := raw[old]
+		if !ok {
+			continue
+		}
+		delete(raw, old)
+		var value string
+		if err := json.Unmarshal(v, &value); err != nil {
+			return fmt.Errorf("%s: %w", old, err)
+		}
+		if _, set := section[name]; !set && value != "" {
+			section[name] = value
+		}
+	}
+	data, err := json.Marshal(section)
+	if err != nil {
+		return err
+	}
+	raw["transcription"] = data
+	return nil
+}
+
+// upgradeFile migrates the config at path if it is older than
+// CurrentVersion, keeping the original as config.json.v<N>.bak. It returns
+// the config, migrated or not.
+func upgradeFile(path string, data []byte) ([]byte, error) {
+	var raw rawConfig
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse config JSON: %w", err)
+	}
+	from, err := migrate(raw)
+	if err != nil {
+		return nil, err
+	}
+	if from == CurrentVersion {
+		return data, nil
+	}
+	upgraded, err := json.MarshalIndent(raw, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	backup := fmt.Sprintf("%s.v%d.bak", path, from)
+	if err := os.WriteFile(backup, data, 0600); err != nil {
+		slog.Warn("config upgraded in memory only; could not back it up", "path", path, "err", err)
+		return upgraded, nil
+	}
+	if err := os.WriteFile(path, upgraded, 0600); err != nil {
+		slog.Warn("config upgraded in memory only; could not write it", "path", path, "err", err)
+		return upgraded, nil
+	}
+	slog.Info("upgraded config", "path", path, "from", from, "to", CurrentVersion, "backup", backup)
+	return upgraded, nil
+}
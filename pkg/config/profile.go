@@ -0,0 +1,120 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// ProfileEnv selects a profile when --profile is not given.
+const ProfileEnv = "LITTLECLAW_PROFILE"
+
+// profileNameRe limits profile names to what is safe as a directory name.
+var profileNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,31}$`)
+
+// profile is the active profile; "" is the default one in ~/.littleclaw.
+var profile string
+
+// configPath and workspaceDir override the profile's config.json and
+// workspace locations when set (see SetConfigPath, SetWorkspaceDir).
+var configPath, workspaceDir string
+
+// SetProfile makes Load, Save, Dir, and WorkspaceDir use the named profile,
+// which lives in ~/.littleclaw/profiles/<name>/ with its own config.json,
+// workspace, and PID file. "" or "default" selects ~/.littleclaw itself.
+func SetProfile(name string) error {
+	if name == "default" {
+		name = ""
+	}
+	if name != "" && !profileNameRe.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q: use letters, digits, '-' and '_' (up to 32)", name)
+	}
+	profile = name
+	return nil
+}
+
+// Profile returns the active profile name, or "" for the default profile.
+func Profile() string {
+	return profile
+}
+
+// Dir returns the active profile's directory, creating it if needed.
+func Dir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not find home directory: %w", err)
+	}
+	dir := filepath.Join(home, ".littleclaw")
+	if profile != "" {
+		dir = filepath.Join(dir, "profiles", profile)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("could not create littleclaw directory: %w", err)
+	}
+	return dir, nil
+}
+
+// SetConfigPath makes Load and Save use path instead of the profile's
+// config.json; "" restores the default.
+func SetConfigPath(path string) error {
+	if path == "" {
+		configPath = ""
+		return nil
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("invalid config path %q: %w", path, err)
+	}
+	configPath = abs
+	return nil
+}
+
+// SetWorkspaceDir makes WorkspaceDir return dir instead of the profile's
+// workspace; "" restores the default.
+func SetWorkspaceDir(dir string) error {
+	if dir == "" {
+		workspaceDir = ""
+		return nil
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("invalid workspace path %q: %w", dir, err)
+	}
+	workspaceDir = abs
+	return nil
+}
+
+// WorkspaceDir returns the active profile's workspace directory.
+func WorkspaceDir() (string, error) {
+	if workspaceDir != "" {
+		return workspaceDir, nil
+	}
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "workspace"), nil
+}
+
+// Profiles lists the named profiles under ~/.littleclaw/profiles.
+func Profiles() ([]string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("could not find home directory: %w", err)
+	}
+	entries, err := os.ReadDir(filepath.Join(home, ".littleclaw", "profiles"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() && profileNameRe.MatchString(e.Name()) {
+			names = append(names, e.Name())
+		}
+	}
+	return names, nil
+}
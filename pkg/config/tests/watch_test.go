@@ -0,0 +1,74 @@
+package config_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"littleclaw/pkg/config"
+)
+
+func TestChanged(t *testing.T) {
+	old := &config.AppConfig{ProviderModel: "gpt-4o-mini", TelegramAllowedUser: "1"}
+	cfg := *old
+	cfg.ProviderModel = "gpt-4o"
+	cfg.ExecPolicy.Deny = []string{`\brm\b`}
+
+	got := config.Changed(old, &cfg)
+	if want := []string{"provider_model", "exec_policy"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Changed() = %v, want %v", got, want)
+	}
+	if got := config.Changed(old, old); len(got) != 0 {
+		t.Errorf("Changed() on the same config = %v", got)
+	}
+}
+
+func TestWatch_ReloadsOnChangeAndSignal(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if err := config.SetProfile("watchtest"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { config.SetProfile("") })
+
+	cfg := &config.AppConfig{ProviderModel: "one"}
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	trigger := make(chan os.Signal, 1)
+	got := make(chan string, 4)
+	go config.Watch(ctx, 20*time.Millisecond, trigger, func(c *config.AppConfig) { got <- c.ProviderModel })
+
+	wait := func(want string) {
+		t.Helper()
+		select {
+		case m := <-got:
+			if m != want {
+				t.Fatalf("reloaded model = %q, want %q", m, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("no reload with %q", want)
+		}
+	}
+
+	// A broken file is skipped, the fixed one is applied
+	dir, _ := config.Dir()
+	time.Sleep(50 * time.Millisecond)
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"provider_model": `), 0600); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	cfg.ProviderModel = "two"
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+	wait("two")
+
+	trigger <- os.Interrupt
+	wait("two")
+}
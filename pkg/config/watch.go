@@ -0,0 +1,83 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// DefaultWatchInterval is how often Watch checks config.json for changes.
+const DefaultWatchInterval = 5 * time.Second
+
+// Watch reloads the active profile's config.json when it changes on disk
+// (checked every interval) or a signal arrives on trigger (e.g. SIGHUP), and
+// passes the new config to apply. A config that fails to load is logged and
+// skipped, so a half-saved file never replaces a working one. Watch returns
+// when ctx is done.
+func Watch(ctx context.Context, interval time.Duration, trigger <-chan os.Signal, apply func(*AppConfig)) {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	path, err := getConfigPath()
+	if err != nil {
+		slog.Warn("config reload disabled", "err", err)
+		return
+	}
+	last := fileStamp(path)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-trigger:
+			slog.Info("reloading config", "trigger", "signal")
+		case <-ticker.C:
+			stamp := fileStamp(path)
+			if stamp == last {
+				continue
+			}
+			last = stamp
+			slog.Info("reloading config", "trigger", "file")
+		}
+		cfg, err := Load()
+		if err != nil {
+			slog.Warn("keeping the current config", "err", err)
+			continue
+		}
+		apply(cfg)
+	}
+}
+
+// fileStamp identifies a version of a file by its modification time and size.
+func fileStamp(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d", info.ModTime().UnixNano(), info.Size())
+}
+
+// Changed returns the JSON names of the top-level config fields that differ
+// between old and cfg.
+func Changed(old, cfg *AppConfig) []string {
+	var names []string
+	a, b := reflect.ValueOf(*old), reflect.ValueOf(*cfg)
+	t := a.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if reflect.DeepEqual(a.Field(i).Interface(), b.Field(i).Interface()) {
+			continue
+		}
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "" {
+			name = t.Field(i).Name
+		}
+		names = append(names, name)
+	}
+	return names
+}
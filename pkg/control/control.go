@@ -0,0 +1,186 @@
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"littleclaw/pkg/agent"
+	"littleclaw/pkg/bus"
+)
+
+// SocketName is the control socket's file name in the profile directory.
+const SocketName = "littleclaw.sock"
+
+// ErrNotRunning is returned by the client when no daemon listens on the socket.
+var ErrNotRunning = errors.New("littleclaw is not running")
+
+// Status is what the daemon reports on GET /status.
+type Status struct {
+	PID            int          `json:"pid"`
+	Profile        string       `json:"profile,omitempty"`
+	StartedAt      time.Time    `json:"started_at"`
+	Channels       []string     `json:"channels"`
+	InboundQueued  int          `json:"inbound_queued"`
+	OutboundQueued int          `json:"outbound_queued"`
+	Agent          agent.Status `json:"agent"`
+
+	Delivery bus.DeliveryStats `json:"delivery"`
+	Outbox   int               `json:"outbox"` // undelivered messages waiting for a retry
+}
+
+// Server answers local clients on a unix socket with JSON over HTTP. Only the
+// owner can connect: the socket is created with mode 0600.
+type Server struct {
+	mux *http.ServeMux
+}
+
+// NewServer creates a server with no endpoints.
+func NewServer() *Server {
+	return &Server{mux: http.NewServeMux()}
+}
+
+// HandleJSON registers an endpoint (e.g. "GET /status") whose result is
+// returned as JSON; an error becomes a 400 with {"error": ...}.
+func (s *Server) HandleJSON(pattern string, fn func(r *http.Request) (any, error)) {
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		v, err := fn(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			v = map[string]string{"error": err.Error()}
+		}
+		json.NewEncoder(w).Encode(v)
+	})
+}
+
+// Serve listens on path until ctx is done. A stale socket from a previous run
+// is replaced; one that another live daemon answers on is an error.
+func (s *Server) Serve(ctx context.Context, path string) error {
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		conn.Close()
+		return fmt.Errorf("another littleclaw is already listening on %s", path)
+	}
+	os.Remove(path)
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		ln.Close()
+		return err
+	}
+	srv := &http.Server{Handler: s.mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+		os.Remove(path)
+	}()
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("control socket stopped", "err", err)
+		return err
+	}
+	return nil
+}
+
+// Client talks to a daemon's control socket.
+type Client struct {
+	http *http.Client
+}
+
+// NewClient creates a client for the socket at path.
+func NewClient(path string) *Client {
+	return &Client{http: &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", path)
+			},
+		},
+	}}
+}
+
+// Do sends a request to endpoint (e.g. "/status") with an optional JSON body
+// and decodes the JSON reply into out.
+func (c *Client) Do(ctx context.Context, method, endpoint string, body, out any) error {
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = strings.NewReader(string(data))
+	}
+	req, err := http.NewRequestWithContext(ctx, method, "http://littleclaw"+endpoint, r)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "dial" {
+			return ErrNotRunning
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&e) == nil && e.Error != "" {
+			return errors.New(e.Error)
+		}
+		return fmt.Errorf("control socket answered HTTP %d", resp.StatusCode)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// Status fetches the daemon's status.
+func (c *Client) Status(ctx context.Context) (*Status, error) {
+	var s Status
+	if err := c.Do(ctx, http.MethodGet, "/status", nil, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Format renders a status for the terminal.
+func (s *Status) Format(now time.Time) string {
+	var sb strings.Builder
+	profile := s.Profile
+	if profile == "" {
+		profile = "default"
+	}
+	sb.WriteString(fmt.Sprintf("🟢 Littleclaw is running (PID %d, profile %s)\n", s.PID, profile))
+	sb.WriteString(fmt.Sprintf("Uptime:    %s (since %s)\n", now.Sub(s.StartedAt).Round(time.Second), s.StartedAt.Local().Format("2006-01-02 15:04")))
+	sb.WriteString(fmt.Sprintf("Model:     %s / %s\n", s.Agent.Provider, s.Agent.Model))
+	sb.WriteString(fmt.Sprintf("Channels:  %s\n", strings.Join(s.Channels, ", ")))
+	sb.WriteString(fmt.Sprintf("Queued:    %d inbound, %d outbound\n", s.InboundQueued, s.OutboundQueued))
+	sb.WriteString(fmt.Sprintf("Delivery:  %d sent (%d after retries), %d failed, %d in the outbox\n", s.Delivery.Delivered, s.Delivery.Retried, s.Delivery.Failed, s.Outbox))
+	sb.WriteString(fmt.Sprintf("Runs:      %d active, %d in the background\n", s.Agent.ActiveRuns, s.Agent.BackgroundRuns))
+	sb.WriteString(fmt.Sprintf("Cron:      %d job(s), %d paused", s.Agent.CronJobs, s.Agent.CronPaused))
+	if s.Agent.NextCron != "" {
+		sb.WriteString(fmt.Sprintf("; next: %s at %s", s.Agent.NextCron, s.Agent.NextCronAt.Local().Format("2006-01-02 15:04")))
+	}
+	sb.WriteString("\n")
+	if s.Agent.LastProviderError == "" {
+		sb.WriteString("Last provider error: none\n")
+	} else {
+		sb.WriteString(fmt.Sprintf("Last provider error: %s ago: %s\n", now.Sub(s.Agent.LastProviderErrorAt).Round(time.Second), s.Agent.LastProviderError))
+	}
+	return sb.String()
+}
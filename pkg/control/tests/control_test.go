@@ -0,0 +1,91 @@
+package control_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"littleclaw/pkg/agent"
+	"littleclaw/pkg/control"
+)
+
+// socketPath returns a short socket path; unix socket paths are limited to ~100 bytes.
+func socketPath(t *testing.T) string {
+	dir, err := os.MkdirTemp("", "lc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return filepath.Join(dir, control.SocketName)
+}
+
+func TestStatusOverSocket(t *testing.T) {
+	path := socketPath(t)
+	started := time.Now().Add(-90 * time.Minute)
+
+	srv := control.NewServer()
+	srv.HandleJSON("GET /status", func(*http.Request) (any, error) {
+		return control.Status{
+			PID:       1234,
+			StartedAt: started,
+			Channels:  []string{"telegram"},
+			Agent: agent.Status{
+				Provider:            "openai",
+				Model:               "gpt-4o-mini",
+				CronJobs:            2,
+				LastProviderError:   "429 rate limited",
+				LastProviderErrorAt: time.Now().Add(-time.Minute),
+			},
+		}, nil
+	})
+	srv.HandleJSON("POST /fail", func(*http.Request) (any, error) { return nil, errors.New("nope") })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ctx, path) }()
+	waitForSocket(t, path)
+
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("expected a 0600 socket, got %v (%v)", info.Mode().Perm(), err)
+	}
+	if err := control.NewServer().Serve(context.Background(), path); err == nil {
+		t.Error("expected a second server on a live socket to fail")
+	}
+
+	client := control.NewClient(path)
+	status, err := client.Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	out := status.Format(time.Now())
+	for _, want := range []string{"PID 1234, profile default", "Uptime:    1h30m0s", "openai / gpt-4o-mini", "Cron:      2 job(s)", "ago: 429 rate limited"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("status output missing %q:\n%s", want, out)
+		}
+	}
+	if err := client.Do(context.Background(), http.MethodPost, "/fail", nil, nil); err == nil || err.Error() != "nope" {
+		t.Errorf("expected the handler error, got %v", err)
+	}
+
+	cancel()
+	<-done
+	if _, err := client.Status(context.Background()); !errors.Is(err, control.ErrNotRunning) {
+		t.Errorf("expected ErrNotRunning after shutdown, got %v", err)
+	}
+}
+
+func waitForSocket(t *testing.T, path string) {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		if _, err := os.Stat(path); err == nil {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("control socket did not appear")
+}
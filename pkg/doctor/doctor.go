@@ -0,0 +1,393 @@
+package doctor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"littleclaw/pkg/config"
+	"littleclaw/pkg/httpclient"
+	"littleclaw/pkg/tools"
+)
+
+// Status is the outcome of one check.
+type Status string
+
+const (
+	OK   Status = "ok"
+	Warn Status = "warn" // works, but something optional is missing or degraded
+	Fail Status = "fail" // littleclaw will not run (or a configured feature will not work)
+)
+
+// Result is one check's outcome and, unless it passed, how to fix it.
+type Result struct {
+	Name   string
+	Status Status
+	Detail string
+	Hint   string
+}
+
+// Checker runs the diagnostics for one profile.
+type Checker struct {
+	Config     *config.AppConfig // nil when it failed to load
+	ConfigErr  error
+	ConfigPath string
+	Workspace  string
+	http       *http.Client
+
+	// Overridable for tests
+	TelegramAPI string
+	BaseURLs    map[string]string // provider type -> OpenAI-compatible base URL
+	LookPath    func(file string) (string, error)
+}
+
+// New creates a checker for a config loaded (or not) from configPath and the
+// workspace it runs in.
+func New(cfg *config.AppConfig, cfgErr error, configPath, workspace string) *Checker {
+	c := &Checker{
+		Config:      cfg,
+		ConfigErr:   cfgErr,
+		ConfigPath:  configPath,
+		Workspace:   workspace,
+		http:        httpclient.New(10 * time.Second),
+		TelegramAPI: "https://api.telegram.org",
+		BaseURLs: map[string]string{
+			"openrouter": "https://openrouter.ai/api/v1",
+			"openai":     "https://api.openai.com/v1",
+			"ollama":     "http://localhost:11434/v1",
+			"xai":        "https://api.x.ai/v1",
+		},
+		LookPath: exec.LookPath,
+	}
+	// Ollama's OpenAI-compatible /v1/models lists its models too
+	if cfg != nil && cfg.Ollama.BaseURL != "" {
+		c.BaseURLs["ollama"] = strings.TrimRight(cfg.Ollama.BaseURL, "/") + "/v1"
+	}
+	return c
+}
+
+// Run performs every check. Checks that need a valid config are skipped
+// when it failed to load.
+func (c *Checker) Run(ctx context.Context) []Result {
+	results := []Result{c.checkConfig()}
+	results = append(results, c.checkWorkspace())
+	if c.Config != nil {
+		results = append(results, c.CheckProvider(ctx)...)
+		results = append(results, c.CheckTelegram(ctx))
+		results = append(results, c.checkTranscription())
+		if c.Config.Sandbox.Mode != "" && c.Config.Sandbox.Mode != "none" {
+			results = append(results, c.checkSandbox())
+		}
+		if c.Config.MemoryIndex.DSN != "" {
+			results = append(results, c.checkMemoryIndex())
+		}
+	}
+	results = append(results, c.checkBinaries()...)
+	return results
+}
+
+// Failed reports whether any result is a failure.
+func Failed(results []Result) bool {
+	for _, r := range results {
+		if r.Status == Fail {
+			return true
+		}
+	}
+	return false
+}
+
+// Format renders results one per line, with fix hints indented below.
+func Format(results []Result) string {
+	icons := map[Status]string{OK: "✅", Warn: "⚠️", Fail: "❌"}
+	var sb strings.Builder
+	for _, r := range results {
+		sb.WriteString(fmt.Sprintf("%s %s: %s\n", icons[r.Status], r.Name, r.Detail))
+		if r.Hint != "" && r.Status != OK {
+			sb.WriteString("   → " + r.Hint + "\n")
+		}
+	}
+	return sb.String()
+}
+
+func (c *Checker) checkConfig() Result {
+	res := Result{Name: "Config"}
+	if c.ConfigErr != nil || c.Config == nil {
+		res.Status, res.Detail = Fail, fmt.Sprintf("%v", c.ConfigErr)
+		res.Hint = "Run 'littleclaw configure' (add --profile <name> for a named profile)."
+		return res
+	}
+	cfg := c.Config
+
+	var problems []string
+	if cfg.TelegramToken == "" {
+		problems = append(problems, "telegram_token is empty")
+	}
+	if _, ok := c.BaseURLs[cfg.ProviderType]; !ok {
+		problems = append(problems, fmt.Sprintf("unknown provider_type %q", cfg.ProviderType))
+	}
+	if cfg.ProviderModel == "" {
+		problems = append(problems, "provider_model is empty")
+	}
+	if cfg.ProviderType != "ollama" && cfg.ProviderAPIKey == "" {
+		problems = append(problems, "provider_apikey is empty")
+	}
+	p := cfg.ExecPolicy
+	if _, err := tools.NewExecPolicy(p.Allow, p.Deny, p.AllowlistOnly); err != nil {
+		problems = append(problems, "exec_policy: "+err.Error())
+	}
+	for _, pat := range cfg.Approval.Patterns {
+		if _, err := regexp.Compile(pat); err != nil {
+			problems = append(problems, fmt.Sprintf("approval pattern %q: %v", pat, err))
+		}
+	}
+	if tz := cfg.Agent.Timezone; tz != "" {
+		if _, err := time.LoadLocation(tz); err != nil {
+			problems = append(problems, fmt.Sprintf("agent.timezone: %v", err))
+		}
+	}
+	if len(problems) > 0 {
+		res.Status, res.Detail = Fail, strings.Join(problems, "; ")
+		res.Hint = "Fix these fields in " + c.ConfigPath + " or re-run 'littleclaw configure'."
+		return res
+	}
+
+	res.Status, res.Detail = OK, fmt.Sprintf("%s with %s", cfg.ProviderType, cfg.ProviderModel)
+	if info, err := os.Stat(c.ConfigPath); err == nil && info.Mode().Perm()&0077 != 0 {
+		res.Status = Warn
+		res.Detail += fmt.Sprintf("; config.json is readable by other users (%v)", info.Mode().Perm())
+		res.Hint = "It holds API keys: chmod 600 " + c.ConfigPath
+	}
+	return res
+}
+
+func (c *Checker) checkWorkspace() Result {
+	res := Result{Name: "Workspace"}
+	ws := c.Workspace
+	if err := os.MkdirAll(ws, 0755); err != nil {
+		res.Status, res.Detail = Fail, fmt.Sprintf("cannot create %s: %v", ws, err)
+		res.Hint = "Check the ownership and permissions of " + filepath.Dir(ws) + "."
+		return res
+	}
+	f, err := os.CreateTemp(ws, ".doctor_*")
+	if err != nil {
+		res.Status, res.Detail = Fail, fmt.Sprintf("%s is not writable: %v", ws, err)
+		res.Hint = "Make it writable by this user, e.g. chown -R $USER " + ws
+		return res
+	}
+	f.Close()
+	os.Remove(f.Name())
+	res.Status, res.Detail = OK, ws+" is writable"
+	return res
+}
+
+// CheckProvider lists the provider's models, which checks reachability, the
+// API key, and whether the configured model exists in one request.
+func (c *Checker) CheckProvider(ctx context.Context) []Result {
+	cfg := c.Config
+	reach := Result{Name: "Provider"}
+	model := Result{Name: "Model"}
+	baseURL, ok := c.BaseURLs[cfg.ProviderType]
+	if !ok {
+		return nil // already reported by checkConfig
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(baseURL, "/")+"/models", nil)
+	if err != nil {
+		reach.Status, reach.Detail = Fail, err.Error()
+		return []Result{reach}
+	}
+	if cfg.ProviderAPIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+cfg.ProviderAPIKey)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		reach.Status, reach.Detail = Fail, fmt.Sprintf("cannot reach %s: %v", baseURL, err)
+		reach.Hint = "Check your network connection."
+		if cfg.ProviderType == "ollama" {
+			reach.Hint = "Start Ollama with 'ollama serve'."
+		}
+		return []Result{reach}
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		reach.Status, reach.Detail = Fail, fmt.Sprintf("%s rejected the API key (HTTP %d)", cfg.ProviderType, resp.StatusCode)
+		reach.Hint = "Create a new key in your " + cfg.ProviderType + " account and run 'littleclaw configure'."
+		return []Result{reach}
+	case resp.StatusCode != http.StatusOK:
+		reach.Status, reach.Detail = Fail, fmt.Sprintf("%s answered HTTP %d", baseURL, resp.StatusCode)
+		reach.Hint = "The service may be down; try again later."
+		return []Result{reach}
+	}
+	reach.Status, reach.Detail = OK, baseURL+" is reachable"
+
+	var list struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil || len(list.Data) == 0 {
+		model.Status, model.Detail = Warn, "could not read the model list to check "+cfg.ProviderModel
+		return []Result{reach, model}
+	}
+	for _, m := range list.Data {
+		if m.ID == cfg.ProviderModel || strings.TrimSuffix(m.ID, ":latest") == cfg.ProviderModel {
+			model.Status, model.Detail = OK, cfg.ProviderModel+" is available"
+			return []Result{reach, model}
+		}
+	}
+	model.Status, model.Detail = Fail, fmt.Sprintf("%s is not offered by %s", cfg.ProviderModel, cfg.ProviderType)
+	model.Hint = "Pick a model from the provider's model list and run 'littleclaw configure'."
+	if cfg.ProviderType == "ollama" {
+		model.Hint = "Download it with 'ollama pull " + cfg.ProviderModel + "'."
+	}
+	return []Result{reach, model}
+}
+
+// CheckTelegram checks the bot token with getMe.
+func (c *Checker) CheckTelegram(ctx context.Context) Result {
+	res := Result{Name: "Telegram"}
+	if c.Config.TelegramToken == "" {
+		res.Status, res.Detail = Fail, "no bot token"
+		res.Hint = "Create a bot with @BotFather and run 'littleclaw configure'."
+		return res
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.TelegramAPI+"/bot"+c.Config.TelegramToken+"/getMe", nil)
+	if err != nil {
+		res.Status, res.Detail = Fail, err.Error()
+		return res
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		// The error text includes the URL, and with it the token
+		res.Status, res.Detail = Fail, "cannot reach the Telegram API"
+		res.Hint = "Check your network connection."
+		return res
+	}
+	defer resp.Body.Close()
+	var me struct {
+		OK     bool `json:"ok"`
+		Result struct {
+			Username string `json:"username"`
+		} `json:"result"`
+	}
+	if resp.StatusCode != http.StatusOK || json.NewDecoder(resp.Body).Decode(&me) != nil || !me.OK {
+		res.Status, res.Detail = Fail, fmt.Sprintf("the bot token was rejected (HTTP %d)", resp.StatusCode)
+		res.Hint = "Copy the token again from @BotFather (/token) and run 'littleclaw configure'."
+		return res
+	}
+	res.Status, res.Detail = OK, "bot @"+me.Result.Username
+	if c.Config.TelegramAllowedUser == "" {
+		res.Status = Warn
+		res.Detail += "; no telegram_allowed_user set, so anyone can talk to it"
+		res.Hint = "Set your numeric user ID (ask @userinfobot) in 'littleclaw configure'."
+	}
+	return res
+}
+
+func (c *Checker) checkTranscription() Result {
+	tc := c.Config.TranscriptionSettings()
+	res := Result{Name: "Transcription"}
+	switch tc.Provider {
+	case "", "none":
+		res.Status, res.Detail = OK, "disabled (voice messages are not transcribed)"
+	case "groq":
+		res.Status, res.Detail = OK, "Groq"
+		if tc.APIKey == "" {
+			res.Status, res.Detail = Fail, "Groq is selected but transcription.apikey is empty"
+			res.Hint = "Get a key at console.groq.com and run 'littleclaw configure'."
+		}
+	case "openai":
+		res.Status, res.Detail = OK, "OpenAI-compatible at "+tc.BaseURL
+		if tc.BaseURL == "" {
+			res.Status, res.Detail = Fail, "transcription.baseurl is empty"
+			res.Hint = "Set it to https://api.openai.com/v1 or your local Whisper server."
+		}
+	case "whisper-cli":
+		res.Status, res.Detail = OK, "local whisper CLI"
+		var missing []string
+		for _, bin := range []string{"whisper", "ffmpeg"} {
+			if _, err := c.LookPath(bin); err != nil {
+				missing = append(missing, bin)
+			}
+		}
+		if len(missing) > 0 {
+			res.Status, res.Detail = Fail, "whisper-cli is selected but "+strings.Join(missing, " and ")+" is not installed"
+			res.Hint = "Install with 'pip install openai-whisper' and your package manager's ffmpeg."
+		}
+	default:
+		res.Status, res.Detail = Fail, fmt.Sprintf("unknown transcription.provider %q", tc.Provider)
+		res.Hint = "Use groq, openai, whisper-cli, or none."
+	}
+	return res
+}
+
+// optionalBinaries are external programs some tools rely on.
+// checkSandbox verifies that the configured sandbox binary is installed.
+func (c *Checker) checkSandbox() Result {
+	mode := c.Config.Sandbox.Mode
+	res := Result{Name: "sandbox"}
+	if mode == "bubblewrap" {
+		mode = "bwrap"
+	}
+	if mode != "bwrap" && mode != "firejail" {
+		res.Status, res.Detail, res.Hint = Fail, fmt.Sprintf("unsupported mode %q", mode), `Set sandbox.mode to "bwrap", "firejail", or "none".`
+		return res
+	}
+	path, err := c.LookPath(mode)
+	if err != nil {
+		pkg := mode
+		if mode == "bwrap" {
+			pkg = "bubblewrap"
+		}
+		res.Status, res.Detail = Fail, mode+" not found; exec, skills, and cron jobs cannot run"
+		res.Hint = fmt.Sprintf("Install %s (e.g. apt install %s) or set sandbox.mode to \"none\".", mode, pkg)
+		return res
+	}
+	res.Status, res.Detail = OK, path
+	if c.Config.Sandbox.NoNetwork {
+		res.Detail += " (no network)"
+	}
+	return res
+}
+
+// checkMemoryIndex verifies that psql, which the memory index talks to
+// Postgres through, is installed.
+func (c *Checker) checkMemoryIndex() Result {
+	res := Result{Name: "memory_index"}
+	path, err := c.LookPath("psql")
+	if err != nil {
+		res.Status, res.Detail = Fail, "psql not found; memory cannot be indexed"
+		res.Hint = "Install the Postgres client (e.g. apt install postgresql-client) or remove memory_index from the config."
+		return res
+	}
+	res.Status, res.Detail = OK, path
+	return res
+}
+
+var optionalBinaries = []struct{ name, usedFor, hint string }{
+	{"python3", "Python skills", "Install Python 3 to run .py skills."},
+	{"git", "the git tool and skill packs", "Install git to use the git tool and 'littleclaw skills install'."},
+	{"ffmpeg", "audio conversion", "Install ffmpeg (e.g. apt install ffmpeg, brew install ffmpeg)."},
+}
+
+func (c *Checker) checkBinaries() []Result {
+	var results []Result
+	for _, b := range optionalBinaries {
+		res := Result{Name: b.name}
+		if path, err := c.LookPath(b.name); err == nil {
+			res.Status, res.Detail = OK, path
+		} else {
+			res.Status, res.Detail, res.Hint = Warn, "not found; needed for "+b.usedFor, b.hint
+		}
+		results = append(results, res)
+	}
+	return results
+}
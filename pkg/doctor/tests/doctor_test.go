@@ -0,0 +1,148 @@
+package doctor_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"littleclaw/pkg/config"
+	"littleclaw/pkg/doctor"
+)
+
+// fakeServices serves an OpenAI-style /models list and Telegram getMe.
+func fakeServices(t *testing.T, models string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v1/models":
+			if r.Header.Get("Authorization") != "Bearer good-key" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(models))
+		case r.URL.Path == "/botgood-token/getMe":
+			w.Write([]byte(`{"ok":true,"result":{"username":"claw_bot"}}`))
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"ok":false}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newChecker(t *testing.T, srv *httptest.Server, cfg *config.AppConfig) *doctor.Checker {
+	dir := t.TempDir()
+	c := doctor.New(cfg, nil, filepath.Join(dir, "config.json"), filepath.Join(dir, "workspace"))
+	c.TelegramAPI = srv.URL
+	c.BaseURLs["openai"] = srv.URL + "/v1"
+	c.LookPath = func(file string) (string, error) {
+		if file == "python3" {
+			return "/usr/bin/python3", nil
+		}
+		return "", errors.New("not found")
+	}
+	return c
+}
+
+func byName(results []doctor.Result) map[string]doctor.Result {
+	m := make(map[string]doctor.Result)
+	for _, r := range results {
+		m[r.Name] = r
+	}
+	return m
+}
+
+func TestDoctor_HealthySetup(t *testing.T) {
+	srv := fakeServices(t, `{"data":[{"id":"gpt-4o-mini"},{"id":"gpt-4o"}]}`)
+	c := newChecker(t, srv, &config.AppConfig{
+		TelegramToken:         "good-token",
+		TelegramAllowedUser:   "42",
+		ProviderType:          "openai",
+		ProviderModel:         "gpt-4o-mini",
+		ProviderAPIKey:        "good-key",
+		TranscriptionProvider: "none",
+	})
+
+	results := c.Run(context.Background())
+	if doctor.Failed(results) {
+		t.Fatalf("expected no failures, got:\n%s", doctor.Format(results))
+	}
+	got := byName(results)
+	if r := got["Telegram"]; r.Status != doctor.OK || r.Detail != "bot @claw_bot" {
+		t.Errorf("Telegram = %+v", r)
+	}
+	if r := got["Model"]; r.Status != doctor.OK {
+		t.Errorf("Model = %+v", r)
+	}
+	if r := got["ffmpeg"]; r.Status != doctor.Warn || r.Hint == "" {
+		t.Errorf("expected a warning with a hint for missing ffmpeg, got %+v", r)
+	}
+}
+
+func TestDoctor_ReportsProblemsWithHints(t *testing.T) {
+	srv := fakeServices(t, `{"data":[{"id":"gpt-4o"}]}`)
+	cfg := &config.AppConfig{
+		TelegramToken:         "bad-token",
+		ProviderType:          "openai",
+		ProviderModel:         "gpt-4o-mini",
+		ProviderAPIKey:        "good-key",
+		TranscriptionProvider: "whisper-cli",
+	}
+	cfg.Agent.Timezone = "Mars/Olympus"
+	cfg.Sandbox.Mode = "bwrap"
+	cfg.MemoryIndex.DSN = "postgres://localhost/littleclaw"
+	c := newChecker(t, srv, cfg)
+
+	results := c.Run(context.Background())
+	if !doctor.Failed(results) {
+		t.Fatal("expected failures")
+	}
+	got := byName(results)
+	for name, want := range map[string]string{
+		"Config":        "agent.timezone",
+		"Model":         "not offered",
+		"Telegram":      "rejected",
+		"Transcription": "whisper and ffmpeg",
+		"sandbox":       "bwrap not found",
+		"memory_index":  "psql not found",
+	} {
+		r := got[name]
+		if r.Status != doctor.Fail || !strings.Contains(r.Detail, want) || r.Hint == "" {
+			t.Errorf("%s = %+v, want a failure mentioning %q with a hint", name, r, want)
+		}
+	}
+	if strings.Contains(doctor.Format(results), "bad-token") {
+		t.Error("the report must not include the bot token")
+	}
+
+	// A rejected API key stops before the model check
+	cfg.ProviderAPIKey = "wrong-key"
+	got = byName(c.Run(context.Background()))
+	if r := got["Provider"]; r.Status != doctor.Fail || !strings.Contains(r.Detail, "rejected the API key") {
+		t.Errorf("Provider = %+v", r)
+	}
+	if _, ok := got["Model"]; ok {
+		t.Error("expected no model check after a rejected key")
+	}
+}
+
+func TestDoctor_MissingConfig(t *testing.T) {
+	dir := t.TempDir()
+	c := doctor.New(nil, errors.New("config not found"), filepath.Join(dir, "config.json"), filepath.Join(dir, "workspace"))
+	c.LookPath = func(string) (string, error) { return "", errors.New("not found") }
+	got := byName(c.Run(context.Background()))
+	if r := got["Config"]; r.Status != doctor.Fail || !strings.Contains(r.Hint, "littleclaw configure") {
+		t.Errorf("Config = %+v", r)
+	}
+	if _, ok := got["Telegram"]; ok {
+		t.Error("expected config-dependent checks to be skipped")
+	}
+	if r := got["Workspace"]; r.Status != doctor.OK {
+		t.Errorf("Workspace = %+v", r)
+	}
+}
@@ -0,0 +1,141 @@
+package events
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// Event is something that happened inside the agent. Subscribers receive the
+// concrete types below.
+type Event interface {
+	EventName() string
+}
+
+// ToolExecuted is published after every tool call.
+type ToolExecuted struct {
+	Time     time.Time // when the call started
+	Tool     string
+	Args     map[string]interface{}
+	Duration time.Duration
+	Failed   bool
+	Error    string // first line of a failed result
+	ChatID   string
+	Channel  string
+}
+
+// CommandExecuted is published after every shell command run by exec, a
+// skill, or a cron job.
+type CommandExecuted struct {
+	Time        time.Time // when the command started
+	Source      string    // "exec", "background", "skill", "git", or "cron"
+	Command     string
+	RequestedBy string // sender of the message that led to it, or "cron:<job ID>"
+	ChatID      string
+	Channel     string
+	ExitCode    int // -1 when the command did not exit on its own (timeout, start failure)
+	Duration    time.Duration
+	Error       string
+}
+
+// CronFired is published after every cron job run, retries included.
+type CronFired struct {
+	JobID       string
+	Label       string
+	Status      string // "ok" or "error"
+	Duration    time.Duration
+	Error       string
+	Attempts    int
+	Consecutive int // failed runs in a row, including this one; 0 after a success
+}
+
+// MemoryConsolidated is published after the heartbeat's consolidation run.
+type MemoryConsolidated struct {
+	Duration    time.Duration
+	MemoryBytes int // size of MEMORY.md afterwards
+	Entities    int // entity files afterwards
+}
+
+// ProviderError is published when a model call fails.
+type ProviderError struct {
+	Provider string
+	Model    string
+	Err      error
+}
+
+func (ToolExecuted) EventName() string       { return "tool_executed" }
+func (CommandExecuted) EventName() string    { return "command_executed" }
+func (CronFired) EventName() string          { return "cron_fired" }
+func (MemoryConsolidated) EventName() string { return "memory_consolidated" }
+func (ProviderError) EventName() string      { return "provider_error" }
+
+// Bus delivers published events to the subscribers of their type. Handlers
+// run synchronously in the publishing goroutine, in subscription order, so
+// they should be quick; a panicking handler is logged and skipped.
+type Bus struct {
+	mu     sync.RWMutex
+	nextID int
+	subs   map[string][]subscription
+}
+
+type subscription struct {
+	id int
+	fn func(Event)
+}
+
+// New creates a Bus without subscribers.
+func New() *Bus {
+	return &Bus{subs: make(map[string][]subscription)}
+}
+
+// Subscribe calls fn for every event of type E published on b. The returned
+// function removes the subscription.
+func Subscribe[E Event](b *Bus, fn func(E)) (unsubscribe func()) {
+	var zero E
+	name := zero.EventName()
+	b.mu.Lock()
+	b.nextID++
+	id := b.nextID
+	b.subs[name] = append(b.subs[name], subscription{id: id, fn: func(e Event) {
+		if ev, ok := e.(E); ok {
+			fn(ev)
+		}
+	}})
+	b.mu.Unlock()
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		subs := b.subs[name]
+		for i, s := range subs {
+			if s.id == id {
+				b.subs[name] = append(subs[:i:i], subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Publish delivers e to its subscribers. A nil Bus ignores it.
+func (b *Bus) Publish(e Event) {
+	if b == nil {
+		return
+	}
+	b.mu.RLock()
+	subs := b.subs[e.EventName()]
+	b.mu.RUnlock()
+	for _, s := range subs {
+		deliver(s.fn, e)
+	}
+}
+
+func deliver(fn func(Event), e Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("event handler panicked", "event", e.EventName(), "panic", fmt.Sprint(r), "stack", string(debug.Stack()))
+		}
+	}()
+	fn(e)
+}
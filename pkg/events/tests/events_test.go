@@ -0,0 +1,54 @@
+package events_test
+
+import (
+	"testing"
+
+	"littleclaw/pkg/events"
+)
+
+func TestBus_DeliversByType(t *testing.T) {
+	b := events.New()
+	var tools []string
+	var crons int
+	events.Subscribe(b, func(e events.ToolExecuted) { tools = append(tools, e.Tool) })
+	events.Subscribe(b, func(e events.CronFired) { crons++ })
+
+	b.Publish(events.ToolExecuted{Tool: "read_file"})
+	b.Publish(events.CronFired{JobID: "j1"})
+	b.Publish(events.ToolExecuted{Tool: "exec"})
+
+	if len(tools) != 2 || tools[0] != "read_file" || tools[1] != "exec" {
+		t.Errorf("expected both tool events in order, got %q", tools)
+	}
+	if crons != 1 {
+		t.Errorf("expected 1 cron event, got %d", crons)
+	}
+}
+
+func TestBus_Unsubscribe(t *testing.T) {
+	b := events.New()
+	calls := 0
+	unsubscribe := events.Subscribe(b, func(events.ProviderError) { calls++ })
+	b.Publish(events.ProviderError{})
+	unsubscribe()
+	b.Publish(events.ProviderError{})
+	if calls != 1 {
+		t.Errorf("expected 1 call before unsubscribing, got %d", calls)
+	}
+}
+
+func TestBus_PanickingHandlerIsSkipped(t *testing.T) {
+	b := events.New()
+	reached := false
+	events.Subscribe(b, func(events.MemoryConsolidated) { panic("boom") })
+	events.Subscribe(b, func(events.MemoryConsolidated) { reached = true })
+	b.Publish(events.MemoryConsolidated{})
+	if !reached {
+		t.Error("expected the handler after the panicking one to run")
+	}
+}
+
+func TestBus_NilIgnoresPublish(t *testing.T) {
+	var b *events.Bus
+	b.Publish(events.ToolExecuted{Tool: "exec"})
+}
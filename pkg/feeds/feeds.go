@@ -0,0 +1,217 @@
+package feeds
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"littleclaw/pkg/httpclient"
+)
+
+const (
+	maxFeedBytes   = 5 << 20
+	maxSummaryLen  = 280
+	requestTimeout = 30 * time.Second
+)
+
+// Item is a single feed entry, normalized across RSS, RDF, and Atom.
+type Item struct {
+	ID        string // guid/id, falling back to the link
+	Title     string
+	Link      string
+	Published time.Time // zero when the feed omits it
+	Summary   string    // plain text, truncated
+}
+
+// Feed is a parsed feed document.
+type Feed struct {
+	Title string
+	Items []Item
+}
+
+// rssDoc covers RSS 2.0 (<rss><channel><item>) and RSS 1.0/RDF (<rdf:RDF><item>).
+type rssDoc struct {
+	Channel struct {
+		Title string    `xml:"title"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	Items []rssItem `xml:"item"` // RDF puts items beside the channel
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Links       []string `xml:"link"` // may include an empty <atom:link href=...>
+	GUID        string   `xml:"guid"`
+	Description string   `xml:"description"`
+	Content     string   `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	PubDate     string   `xml:"pubDate"`
+	Date        string   `xml:"http://purl.org/dc/elements/1.1/ date"`
+}
+
+type atomDoc struct {
+	Title   string      `xml:"title"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID    string `xml:"id"`
+	Title string `xml:"title"`
+	Links []struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+	} `xml:"link"`
+	Summary   string `xml:"summary"`
+	Content   string `xml:"content"`
+	Published string `xml:"published"`
+	Updated   string `xml:"updated"`
+}
+
+// Parse decodes an RSS 2.0, RSS 1.0 (RDF), or Atom document.
+func Parse(data []byte) (*Feed, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.Strict = false
+	dec.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		// Feeds declaring legacy charsets are almost always ASCII-compatible
+		return input, nil
+	}
+
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("not a feed: %w", err)
+		}
+		if se, ok := tok.(xml.StartElement); ok {
+			switch strings.ToLower(se.Name.Local) {
+			case "rss", "rdf":
+				var doc rssDoc
+				if err := dec.DecodeElement(&doc, &se); err != nil {
+					return nil, fmt.Errorf("invalid RSS: %w", err)
+				}
+				return fromRSS(doc), nil
+			case "feed":
+				var doc atomDoc
+				if err := dec.DecodeElement(&doc, &se); err != nil {
+					return nil, fmt.Errorf("invalid Atom: %w", err)
+				}
+				return fromAtom(doc), nil
+			default:
+				return nil, fmt.Errorf("not a feed: root element <%s>", se.Name.Local)
+			}
+		}
+	}
+}
+
+func fromRSS(doc rssDoc) *Feed {
+	f := &Feed{Title: strings.TrimSpace(doc.Channel.Title)}
+	for _, it := range append(doc.Channel.Items, doc.Items...) {
+		item := Item{
+			ID:        strings.TrimSpace(it.GUID),
+			Title:     cleanText(it.Title),
+			Link:      strings.TrimSpace(firstNonEmpty(it.Links...)),
+			Published: parseDate(firstNonEmpty(it.PubDate, it.Date)),
+			Summary:   summarize(firstNonEmpty(it.Description, it.Content)),
+		}
+		if item.ID == "" {
+			item.ID = firstNonEmpty(item.Link, item.Title)
+		}
+		f.Items = append(f.Items, item)
+	}
+	return f
+}
+
+func fromAtom(doc atomDoc) *Feed {
+	f := &Feed{Title: cleanText(doc.Title)}
+	for _, e := range doc.Entries {
+		item := Item{
+			ID:        strings.TrimSpace(e.ID),
+			Title:     cleanText(e.Title),
+			Published: parseDate(firstNonEmpty(e.Published, e.Updated)),
+			Summary:   summarize(firstNonEmpty(e.Summary, e.Content)),
+		}
+		for _, l := range e.Links {
+			if l.Rel == "" || l.Rel == "alternate" {
+				item.Link = strings.TrimSpace(l.Href)
+				break
+			}
+		}
+		if item.ID == "" {
+			item.ID = firstNonEmpty(item.Link, item.Title)
+		}
+		f.Items = append(f.Items, item)
+	}
+	return f
+}
+
+var (
+	tagPattern   = regexp.MustCompile(`(?s)<[^>]*>`)
+	spacePattern = regexp.MustCompile(`\s+`)
+	dateLayouts  = []string{time.RFC1123Z, time.RFC1123, time.RFC3339, "Mon, 2 Jan 2006 15:04:05 -0700", "Mon, 2 Jan 2006 15:04:05 MST", "2006-01-02T15:04:05Z07:00", "2006-01-02"}
+)
+
+// cleanText strips markup and entities and collapses whitespace.
+func cleanText(s string) string {
+	s = tagPattern.ReplaceAllString(s, " ")
+	s = html.UnescapeString(s)
+	return strings.TrimSpace(spacePattern.ReplaceAllString(s, " "))
+}
+
+func summarize(s string) string {
+	s = cleanText(s)
+	if r := []rune(s); len(r) > maxSummaryLen {
+		s = strings.TrimSpace(string(r[:maxSummaryLen])) + "…"
+	}
+	return s
+}
+
+func parseDate(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// Fetch downloads and parses the feed at url.
+func Fetch(ctx context.Context, client *http.Client, url string) (*Feed, error) {
+	if client == nil {
+		client = httpclient.New(requestTimeout)
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Littleclaw/1.0 (feed reader)")
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml;q=0.9, */*;q=0.5")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedBytes))
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
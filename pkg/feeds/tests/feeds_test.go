@@ -0,0 +1,102 @@
+package feeds_test
+
+import (
+	"strings"
+	"testing"
+
+	"littleclaw/pkg/feeds"
+)
+
+const rssSample = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom">
+<channel>
+  <title>Example News</title>
+  <atom:link href="https://example.com/feed" rel="self"/>
+  <item>
+    <title>Go 2 released</title>
+    <link>https://example.com/go2</link>
+    <atom:link href="https://example.com/go2" rel="alternate"/>
+    <guid>post-2</guid>
+    <pubDate>Fri, 16 Oct 2026 09:00:00 +0000</pubDate>
+    <description>&lt;p&gt;The &lt;b&gt;long&lt;/b&gt; awaited   release.&lt;/p&gt;</description>
+  </item>
+  <item>
+    <title>No guid here</title>
+    <link>https://example.com/no-guid</link>
+  </item>
+</channel>
+</rss>`
+
+const atomSample = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Atom Blog</title>
+  <entry>
+    <id>tag:example.com,2026:1</id>
+    <title type="html">Hello &amp;amp; welcome</title>
+    <link rel="edit" href="https://example.com/edit/1"/>
+    <link rel="alternate" href="https://example.com/1"/>
+    <updated>2026-10-15T12:00:00Z</updated>
+    <summary>First post</summary>
+  </entry>
+</feed>`
+
+const rdfSample = `<?xml version="1.0"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <channel><title>RDF Feed</title></channel>
+  <item>
+    <title>RDF item</title>
+    <link>https://example.com/rdf</link>
+    <dc:date>2026-10-14T08:00:00Z</dc:date>
+  </item>
+</rdf:RDF>`
+
+func TestParse_RSS(t *testing.T) {
+	f, err := feeds.Parse([]byte(rssSample))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if f.Title != "Example News" || len(f.Items) != 2 {
+		t.Fatalf("unexpected feed %+v", f)
+	}
+	it := f.Items[0]
+	if it.ID != "post-2" || it.Link != "https://example.com/go2" || it.Published.Day() != 16 {
+		t.Errorf("unexpected item %+v", it)
+	}
+	if it.Summary != "The long awaited release." {
+		t.Errorf("summary not cleaned: %q", it.Summary)
+	}
+	if f.Items[1].ID != "https://example.com/no-guid" {
+		t.Errorf("items without a guid should fall back to the link, got %q", f.Items[1].ID)
+	}
+}
+
+func TestParse_Atom(t *testing.T) {
+	f, err := feeds.Parse([]byte(atomSample))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if f.Title != "Atom Blog" || len(f.Items) != 1 {
+		t.Fatalf("unexpected feed %+v", f)
+	}
+	it := f.Items[0]
+	if it.Title != "Hello & welcome" || it.Link != "https://example.com/1" || it.Summary != "First post" || it.Published.IsZero() {
+		t.Errorf("unexpected entry %+v", it)
+	}
+}
+
+func TestParse_RDF(t *testing.T) {
+	f, err := feeds.Parse([]byte(rdfSample))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if f.Title != "RDF Feed" || len(f.Items) != 1 || f.Items[0].Link != "https://example.com/rdf" || f.Items[0].Published.IsZero() {
+		t.Errorf("unexpected feed %+v", f)
+	}
+}
+
+func TestParse_NotAFeed(t *testing.T) {
+	_, err := feeds.Parse([]byte("<!DOCTYPE html><html><body>hi</body></html>"))
+	if err == nil || !strings.Contains(err.Error(), "not a feed") {
+		t.Errorf("expected not-a-feed error, got %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+package filelinks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTTL is how long a link works when the config does not say.
+const DefaultTTL = 24 * time.Hour
+
+// link is one shared file.
+type link struct {
+	path    string
+	expires time.Time
+}
+
+// Server hands out download links for local files too large to send through
+// a chat channel. Each link is an unguessable token that expires after the
+// TTL; nothing outside the shared files is reachable.
+type Server struct {
+	baseURL string
+	ttl     time.Duration
+
+	mu    sync.Mutex
+	links map[string]link
+}
+
+// New returns a server whose links start with baseURL, the address users
+// reach it at (e.g. "https://files.example.com" behind a reverse proxy).
+// ttl <= 0 uses DefaultTTL.
+func New(baseURL string, ttl time.Duration) (*Server, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("base URL %q must be an absolute http(s) URL", baseURL)
+	}
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Server{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		ttl:     ttl,
+		links:   make(map[string]link),
+	}, nil
+}
+
+// TTL is how long new links stay valid.
+func (s *Server) TTL() time.Duration {
+	return s.ttl
+}
+
+// Link shares the file at path and returns its download URL.
+func (s *Server) Link(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return "", errors.New("cannot link a directory")
+	}
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(buf)
+
+	s.mu.Lock()
+	now := time.Now()
+	for t, l := range s.links {
+		if now.After(l.expires) {
+			delete(s.links, t)
+		}
+	}
+	s.links[token] = link{path: path, expires: now.Add(s.ttl)}
+	s.mu.Unlock()
+
+	return s.baseURL + "/files/" + token + "/" + url.PathEscape(filepath.Base(path)), nil
+}
+
+// lookup returns the file behind token if the link is still valid.
+func (s *Server) lookup(token string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l, ok := s.links[token]
+	if !ok {
+		return "", false
+	}
+	if time.Now().After(l.expires) {
+		delete(s.links, token)
+		return "", false
+	}
+	return l.path, true
+}
+
+// Handler serves GET /files/<token>/<name>. The name is only there so
+// browsers save the file under it; the token alone selects the file.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /files/{token}/{name}", func(w http.ResponseWriter, r *http.Request) {
+		path, ok := s.lookup(r.PathValue("token"))
+		if !ok {
+			http.Error(w, "link not found or expired", http.StatusNotFound)
+			return
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			http.Error(w, "file no longer available", http.StatusGone)
+			return
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			http.Error(w, "file no longer available", http.StatusGone)
+			return
+		}
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filepath.Base(path)))
+		http.ServeContent(w, r, filepath.Base(path), info.ModTime(), f)
+	})
+	return mux
+}
+
+// Serve listens on addr until ctx is cancelled.
+func (s *Server) Serve(ctx context.Context, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{Handler: s.Handler(), ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+	slog.Info("file links listening", "addr", ln.Addr().String())
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("file link server stopped", "err", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package filelinks_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"littleclaw/pkg/filelinks"
+)
+
+func TestNew_RejectsBadBaseURL(t *testing.T) {
+	for _, base := range []string{"", "files.example.com", "ftp://files.example.com"} {
+		if _, err := filelinks.New(base, 0); err == nil {
+			t.Errorf("New(%q) succeeded", base)
+		}
+	}
+}
+
+func TestLink_ServesFile(t *testing.T) {
+	s, err := filelinks.New("https://files.example.com/", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "report.pdf")
+	os.WriteFile(path, []byte("pdf bytes"), 0644)
+
+	link, err := s.Link(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(link, "https://files.example.com/files/") || !strings.HasSuffix(link, "/report.pdf") {
+		t.Fatalf("link = %q", link)
+	}
+
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+	resp, err := http.Get(srv.URL + strings.TrimPrefix(link, "https://files.example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "pdf bytes" {
+		t.Fatalf("status %d body %q", resp.StatusCode, body)
+	}
+	if cd := resp.Header.Get("Content-Disposition"); !strings.Contains(cd, "report.pdf") {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+}
+
+func TestLink_UnknownAndExpired(t *testing.T) {
+	s, err := filelinks.New("http://localhost:8090", time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "a.txt")
+	os.WriteFile(path, []byte("a"), 0644)
+	link, err := s.Link(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+	for _, p := range []string{"/files/0123456789abcdef/a.txt", strings.TrimPrefix(link, "http://localhost:8090")} {
+		time.Sleep(5 * time.Millisecond)
+		resp, err := http.Get(srv.URL + p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("GET %s = %d, want 404", p, resp.StatusCode)
+		}
+	}
+}
+
+func TestLink_RejectsDirectories(t *testing.T) {
+	s, _ := filelinks.New("http://localhost:8090", 0)
+	if _, err := s.Link(t.TempDir()); err == nil {
+		t.Error("linked a directory")
+	}
+}
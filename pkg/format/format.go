@@ -0,0 +1,279 @@
+// Package format adapts the agent's replies, written in Markdown with files
+// attached, to what each channel can show (see bus.Capabilities).
+package format
+
+import (
+	"html"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"littleclaw/pkg/bus"
+)
+
+// Adapt turns msg into the messages its channel should send: Markdown
+// rendered in the channel's markup, text over the length limit split into
+// several messages, and attachments listed by name where files can't be
+// sent. Files go with the first message and approval buttons with the last.
+func Adapt(msg bus.OutboundMessage, caps bus.Capabilities) []bus.OutboundMessage {
+	if !caps.Threads {
+		msg.ThreadID = 0
+	}
+	if !caps.Files && len(msg.Files) > 0 {
+		names := make([]string, len(msg.Files))
+		for i, f := range msg.Files {
+			names[i] = filepath.Base(f)
+		}
+		msg.Content = strings.TrimSpace(msg.Content + "\n\n(Files that could not be sent here: " + strings.Join(names, ", ") + ")")
+		msg.Files = nil
+	}
+
+	chunks := Split(msg.Content, caps.MaxMessageChars)
+	parts := make([]bus.OutboundMessage, len(chunks))
+	for i, chunk := range chunks {
+		part := msg
+		if i > 0 {
+			part.Files = nil
+		}
+		if i < len(chunks)-1 {
+			part.ApprovalID = ""
+		}
+		part.Content, part.Markup = Render(chunk, caps.Markup)
+		parts[i] = part
+	}
+	return parts
+}
+
+// Render converts Markdown to markup, returning the text and the markup it
+// is in.
+func Render(md string, markup bus.Markup) (string, bus.Markup) {
+	switch markup {
+	case bus.MarkupHTML:
+		return ToHTML(md), bus.MarkupHTML
+	case bus.MarkupMarkdown:
+		return md, bus.MarkupMarkdown
+	}
+	return ToPlain(md), bus.MarkupPlain
+}
+
+// ToHTML renders Markdown in the HTML subset Telegram accepts: bold, italic,
+// strikethrough, code, links, and quotes. Headings become bold lines and
+// list markers bullets.
+func ToHTML(md string) string {
+	return render(md, true)
+}
+
+// ToPlain strips Markdown down to readable plain text.
+func ToPlain(md string) string {
+	return render(md, false)
+}
+
+// StripHTML undoes ToHTML's markup, for when a channel rejects it.
+func StripHTML(s string) string {
+	return html.UnescapeString(htmlTag.ReplaceAllString(s, ""))
+}
+
+var (
+	htmlTag    = regexp.MustCompile(`<[^>]+>`)
+	heading    = regexp.MustCompile(`^#{1,6}\s+(.*?)\s*#*$`)
+	bullet     = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	rule       = regexp.MustCompile(`^\s*(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+	codeSpan   = regexp.MustCompile("`([^`]+)`")
+	link       = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	boldItalic = regexp.MustCompile(`\*\*\*(\S(?:.*?\S)?)\*\*\*`)
+	boldStars  = regexp.MustCompile(`\*\*(\S(?:.*?\S)?)\*\*`)
+	boldUnders = regexp.MustCompile(`(^|\W)__(\S(?:.*?\S)?)__(\W|$)`)
+	strike     = regexp.MustCompile(`~~(\S(?:.*?\S)?)~~`)
+	italStars  = regexp.MustCompile(`\*(\S(?:[^*]*?\S)?)\*`)
+	italUnders = regexp.MustCompile(`(^|\W)_(\S(?:[^_]*?\S)?)_(\W|$)`)
+)
+
+// render walks md line by line, keeping fenced code blocks verbatim.
+func render(md string, asHTML bool) string {
+	var out []string
+	lines := strings.Split(md, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "```") {
+			lang := strings.TrimSpace(strings.TrimPrefix(trimmed, "```"))
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, lines[i])
+			}
+			out = append(out, codeBlock(strings.Join(code, "\n"), lang, asHTML))
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, ">") {
+			var quote []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				q := strings.TrimPrefix(strings.TrimSpace(lines[i]), ">")
+				quote = append(quote, inline(strings.TrimPrefix(q, " "), asHTML))
+			}
+			i--
+			if asHTML {
+				out = append(out, "<blockquote>"+strings.Join(quote, "\n")+"</blockquote>")
+			} else {
+				out = append(out, "> "+strings.Join(quote, "\n> "))
+			}
+			continue
+		}
+
+		switch {
+		case rule.MatchString(line):
+			out = append(out, "———")
+		case heading.MatchString(trimmed):
+			text := inline(heading.FindStringSubmatch(trimmed)[1], asHTML)
+			if asHTML {
+				text = "<b>" + text + "</b>"
+			}
+			out = append(out, text)
+		case bullet.MatchString(line):
+			m := bullet.FindStringSubmatch(line)
+			out = append(out, m[1]+"• "+inline(m[2], asHTML))
+		default:
+			out = append(out, inline(line, asHTML))
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+func codeBlock(code, lang string, asHTML bool) string {
+	if !asHTML {
+		return code
+	}
+	code = html.EscapeString(code)
+	if lang != "" {
+		return `<pre><code class="language-` + html.EscapeString(lang) + `">` + code + "</code></pre>"
+	}
+	return "<pre>" + code + "</pre>"
+}
+
+// inline renders the emphasis, code spans, and links within one line. Code
+// spans and link targets are left alone.
+func inline(s string, asHTML bool) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range codeSpan.FindAllStringSubmatchIndex(s, -1) {
+		b.WriteString(links(s[last:m[0]], asHTML))
+		code := s[m[2]:m[3]]
+		if asHTML {
+			b.WriteString("<code>" + html.EscapeString(code) + "</code>")
+		} else {
+			b.WriteString(code)
+		}
+		last = m[1]
+	}
+	b.WriteString(links(s[last:], asHTML))
+	return b.String()
+}
+
+// links renders the links in s, with emphasis applied to the rest.
+func links(s string, asHTML bool) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range link.FindAllStringSubmatchIndex(s, -1) {
+		b.WriteString(emphasis(s[last:m[0]], asHTML))
+		text, url := s[m[2]:m[3]], s[m[4]:m[5]]
+		switch {
+		case asHTML:
+			b.WriteString(`<a href="` + html.EscapeString(url) + `">` + emphasis(text, true) + "</a>")
+		case text == url:
+			b.WriteString(url)
+		default:
+			b.WriteString(emphasis(text, false) + " (" + url + ")")
+		}
+		last = m[1]
+	}
+	b.WriteString(emphasis(s[last:], asHTML))
+	return b.String()
+}
+
+// emphasis renders bold, italic, and strikethrough in s, escaping it for
+// HTML first.
+func emphasis(s string, asHTML bool) string {
+	wrap := func(tag string) string {
+		if asHTML {
+			return "<" + tag + ">$1</" + tag + ">"
+		}
+		return "$1"
+	}
+	if asHTML {
+		s = html.EscapeString(s)
+	}
+	s = boldItalic.ReplaceAllString(s, strings.Replace(wrap("b"), "$1", wrap("i"), 1))
+	s = boldStars.ReplaceAllString(s, wrap("b"))
+	s = boldUnders.ReplaceAllString(s, "${1}"+strings.Replace(wrap("b"), "$1", "${2}", 1)+"${3}")
+	s = strike.ReplaceAllString(s, wrap("s"))
+	s = italStars.ReplaceAllString(s, wrap("i"))
+	s = italUnders.ReplaceAllString(s, "${1}"+strings.Replace(wrap("i"), "$1", "${2}", 1)+"${3}")
+	return s
+}
+
+// Split breaks md into pieces of at most max characters, cutting at
+// paragraph breaks, then line breaks, then spaces. A code block cut in two
+// is closed at the end of one piece and reopened at the start of the next.
+// max <= 0 means no limit.
+func Split(md string, max int) []string {
+	if max <= 0 || utf8.RuneCountInString(md) <= max {
+		return []string{md}
+	}
+	const closeFence = "\n```"
+	budget := max
+	if budget > 2*len(closeFence) {
+		budget -= len(closeFence)
+	}
+
+	var parts []string
+	for utf8.RuneCountInString(md) > max {
+		cut, skip := cutPoint(md, budget)
+		part, rest := strings.TrimRight(md[:cut], " \n"), strings.TrimLeft(md[cut+skip:], "\n")
+		if open := openFence(part); open != "" {
+			part += closeFence
+			rest = open + "\n" + rest
+		}
+		parts = append(parts, part)
+		md = rest
+	}
+	return append(parts, md)
+}
+
+// cutPoint returns where to cut s to keep at most budget characters before
+// the cut, and how many separator bytes to drop there.
+func cutPoint(s string, budget int) (cut, skip int) {
+	end, n := len(s), 0
+	for i := range s {
+		if n == budget {
+			end = i
+			break
+		}
+		n++
+	}
+	window := s[:end]
+	for _, sep := range []string{"\n\n", "\n", " "} {
+		// A cut too early leaves pieces needlessly short
+		if i := strings.LastIndex(window, sep); i > end/2 {
+			return i, len(sep)
+		}
+	}
+	return end, 0
+}
+
+// openFence returns the opening line of a code block left open at the end
+// of s, or "".
+func openFence(s string) string {
+	open := ""
+	for _, line := range strings.Split(s, "\n") {
+		if t := strings.TrimSpace(line); strings.HasPrefix(t, "```") {
+			if open == "" {
+				open = t
+			} else {
+				open = ""
+			}
+		}
+	}
+	return open
+}
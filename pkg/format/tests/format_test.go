@@ -0,0 +1,105 @@
+package format_test
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"littleclaw/pkg/bus"
+	"littleclaw/pkg/format"
+)
+
+func TestToHTML(t *testing.T) {
+	for md, want := range map[string]string{
+		"**bold** and *italic*":              "<b>bold</b> and <i>italic</i>",
+		"__bold__ and _italic_ snake_case":   "<b>bold</b> and <i>italic</i> snake_case",
+		"***both*** ~~gone~~":                "<b><i>both</i></b> <s>gone</s>",
+		"a < b & c > d":                      "a &lt; b &amp; c &gt; d",
+		"run `x **y** <z>` now":              "run <code>x **y** &lt;z&gt;</code> now",
+		"see [the docs](https://x.io/a_b_c)": `see <a href="https://x.io/a_b_c">the docs</a>`,
+		"## Title":                           "<b>Title</b>",
+		"- one\n  * two":                     "• one\n  • two",
+		"> quoted\n> more":                   "<blockquote>quoted\nmore</blockquote>",
+		"```go\nif a < b {}\n```":            "<pre><code class=\"language-go\">if a &lt; b {}</code></pre>",
+		"```\n**not bold**\n```":             "<pre>**not bold**</pre>",
+		"2 * 3 * 4":                          "2 * 3 * 4",
+	} {
+		if got := format.ToHTML(md); got != want {
+			t.Errorf("ToHTML(%q)\n got %q\nwant %q", md, got, want)
+		}
+	}
+}
+
+func TestToPlain(t *testing.T) {
+	md := "# Plan\n**Step 1**: read [the docs](https://x.io) and run `make`\n- done"
+	want := "Plan\nStep 1: read the docs (https://x.io) and run make\n• done"
+	if got := format.ToPlain(md); got != want {
+		t.Errorf("got %q\nwant %q", got, want)
+	}
+}
+
+func TestStripHTML(t *testing.T) {
+	md := "**a < b** & `c`"
+	if got := format.StripHTML(format.ToHTML(md)); got != "a < b & c" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	md := strings.Repeat("word ", 30) + "\n\n" + strings.Repeat("more ", 30)
+	parts := format.Split(md, 100)
+	if len(parts) < 2 {
+		t.Fatalf("expected several parts, got %q", parts)
+	}
+	for _, p := range parts {
+		if n := utf8.RuneCountInString(p); n > 100 || n == 0 {
+			t.Errorf("part has %d characters: %q", n, p)
+		}
+	}
+	if joined := strings.Join(strings.Fields(strings.Join(parts, " ")), " "); joined != strings.Join(strings.Fields(md), " ") {
+		t.Errorf("words lost or reordered:\n%s", joined)
+	}
+}
+
+func TestSplit_ReopensCodeBlocks(t *testing.T) {
+	md := "```go\n" + strings.Repeat("x := 1\n", 30) + "```"
+	parts := format.Split(md, 80)
+	if len(parts) < 2 {
+		t.Fatalf("expected several parts, got %q", parts)
+	}
+	for _, p := range parts {
+		if !strings.HasPrefix(p, "```go\n") || !strings.HasSuffix(p, "```") || utf8.RuneCountInString(p) > 80 {
+			t.Errorf("part is not a closed code block within the limit: %q", p)
+		}
+	}
+}
+
+func TestAdapt(t *testing.T) {
+	msg := bus.OutboundMessage{
+		Channel: "telegram", ChatID: "1", ThreadID: 7, ApprovalID: "ap1",
+		Content: strings.Repeat("**hi** ", 40), Files: []string{"/tmp/a.png"},
+	}
+
+	parts := format.Adapt(msg, bus.Capabilities{Markup: bus.MarkupHTML, MaxMessageChars: 100, Files: true, Threads: true})
+	if len(parts) < 2 {
+		t.Fatalf("expected the text to be split, got %d parts", len(parts))
+	}
+	first, last := parts[0], parts[len(parts)-1]
+	if len(first.Files) != 1 || len(last.Files) != 0 {
+		t.Error("files should go with the first part only")
+	}
+	if first.ApprovalID != "" || last.ApprovalID != "ap1" {
+		t.Error("approval buttons should go with the last part only")
+	}
+	if first.Markup != bus.MarkupHTML || !strings.HasPrefix(first.Content, "<b>hi</b>") || first.ThreadID != 7 {
+		t.Errorf("first part = %+v", first)
+	}
+
+	parts = format.Adapt(msg, bus.PlainText)
+	if len(parts) != 1 || parts[0].ThreadID != 0 || len(parts[0].Files) != 0 {
+		t.Fatalf("got %+v", parts)
+	}
+	if c := parts[0].Content; strings.Contains(c, "**") || !strings.HasSuffix(c, "(Files that could not be sent here: a.png)") {
+		t.Errorf("content = %q", c)
+	}
+}
@@ -0,0 +1,188 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Report statuses. A failing non-critical check makes the agent "degraded",
+// which still answers 200 so an orchestrator does not restart it for an
+// outage a restart cannot fix.
+const (
+	StatusOK       = "ok"
+	StatusDegraded = "degraded"
+	StatusFail     = "fail"
+)
+
+// probeTimeout bounds one probe.
+const probeTimeout = 10 * time.Second
+
+// Check is one named probe.
+type Check struct {
+	Name     string
+	Critical bool          // a failure makes /healthz answer 503
+	TTL      time.Duration // reuse the last result this long; 0 probes on every request
+	Probe    func(ctx context.Context) error
+}
+
+// Result is the outcome of one check.
+type Result struct {
+	Name      string    `json:"name"`
+	OK        bool      `json:"ok"`
+	Critical  bool      `json:"critical"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Report is what /healthz returns.
+type Report struct {
+	Status string         `json:"status"`
+	Ready  bool           `json:"ready"`
+	Checks []Result       `json:"checks"`
+	Queues map[string]int `json:"queues,omitempty"`
+}
+
+// cachedCheck is a check with its last result.
+type cachedCheck struct {
+	Check
+	mu   sync.Mutex
+	last *Result
+}
+
+// Checker runs the health checks and serves them over HTTP.
+type Checker struct {
+	mu     sync.Mutex
+	checks []*cachedCheck
+	queues func() map[string]int
+	ready  atomic.Bool
+	auth   func(http.Handler) http.Handler // wraps Handler in Serve; nil serves it as is
+}
+
+// New creates a checker with no checks that is not ready yet.
+func New() *Checker {
+	return &Checker{}
+}
+
+// Add registers a check.
+func (h *Checker) Add(c Check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks = append(h.checks, &cachedCheck{Check: c})
+}
+
+// SetQueues sets the function reporting queue depths for the report.
+func (h *Checker) SetQueues(fn func() map[string]int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.queues = fn
+}
+
+// SetReady marks startup as finished (or not); /readyz answers 503 until then.
+func (h *Checker) SetReady(ready bool) { h.ready.Store(ready) }
+
+// Report runs the checks whose cached results have expired, concurrently.
+func (h *Checker) Report(ctx context.Context) Report {
+	h.mu.Lock()
+	checks, queues := h.checks, h.queues
+	h.mu.Unlock()
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.run(ctx)
+		}()
+	}
+	wg.Wait()
+
+	r := Report{Status: StatusOK, Ready: h.ready.Load(), Checks: results}
+	for _, res := range results {
+		switch {
+		case res.OK:
+		case res.Critical:
+			r.Status = StatusFail
+		case r.Status == StatusOK:
+			r.Status = StatusDegraded
+		}
+	}
+	if queues != nil {
+		r.Queues = queues()
+	}
+	return r
+}
+
+// run returns the cached result or probes again.
+func (c *cachedCheck) run(ctx context.Context) Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.last != nil && time.Since(c.last.CheckedAt) < c.TTL {
+		return *c.last
+	}
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	res := Result{Name: c.Name, OK: true, Critical: c.Critical, CheckedAt: time.Now()}
+	if err := c.Probe(ctx); err != nil {
+		res.OK, res.Error = false, err.Error()
+	}
+	c.last = &res
+	return res
+}
+
+// Handler serves GET /healthz (200 unless a critical check fails) and
+// GET /readyz (200 once ready and healthy), both with the report as JSON.
+func (h *Checker) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		rep := h.Report(r.Context())
+		writeReport(w, rep, rep.Status != StatusFail)
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		rep := h.Report(r.Context())
+		writeReport(w, rep, rep.Ready && rep.Status != StatusFail)
+	})
+	return mux
+}
+
+func writeReport(w http.ResponseWriter, rep Report, ok bool) {
+	w.Header().Set("Content-Type", "application/json")
+	if !ok {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(rep)
+}
+
+// SetAuth makes Serve pass every request through auth first, e.g. an API
+// key check.
+func (h *Checker) SetAuth(auth func(http.Handler) http.Handler) { h.auth = auth }
+
+// Serve listens on addr (e.g. ":8080") until ctx is done.
+func (h *Checker) Serve(ctx context.Context, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	handler := h.Handler()
+	if h.auth != nil {
+		handler = h.auth(handler)
+	}
+	srv := &http.Server{Handler: handler, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+	slog.Info("health endpoints listening", "addr", ln.Addr().String())
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("health server stopped", "err", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"littleclaw/pkg/health"
+)
+
+func get(t *testing.T, h http.Handler, path string) (int, health.Report) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	var rep health.Report
+	if err := json.Unmarshal(rec.Body.Bytes(), &rep); err != nil {
+		t.Fatalf("%s: bad JSON %q", path, rec.Body.String())
+	}
+	return rec.Code, rep
+}
+
+func TestHealthz_Statuses(t *testing.T) {
+	var telegramErr, providerErr error
+	h := health.New()
+	h.Add(health.Check{Name: "telegram", Critical: true, Probe: func(context.Context) error { return telegramErr }})
+	h.Add(health.Check{Name: "provider", Probe: func(context.Context) error { return providerErr }})
+	h.SetQueues(func() map[string]int { return map[string]int{"inbound": 3} })
+
+	code, rep := get(t, h.Handler(), "/healthz")
+	if code != http.StatusOK || rep.Status != health.StatusOK || rep.Queues["inbound"] != 3 || len(rep.Checks) != 2 {
+		t.Errorf("healthy: got %d %+v", code, rep)
+	}
+
+	// A non-critical failure degrades but keeps answering 200
+	providerErr = errors.New("connection refused")
+	code, rep = get(t, h.Handler(), "/healthz")
+	if code != http.StatusOK || rep.Status != health.StatusDegraded || rep.Checks[1].Error != "connection refused" {
+		t.Errorf("degraded: got %d %+v", code, rep)
+	}
+
+	telegramErr = errors.New("cannot reach the Telegram API")
+	code, rep = get(t, h.Handler(), "/healthz")
+	if code != http.StatusServiceUnavailable || rep.Status != health.StatusFail {
+		t.Errorf("failing: got %d %+v", code, rep)
+	}
+}
+
+func TestReadyz(t *testing.T) {
+	h := health.New()
+	if code, _ := get(t, h.Handler(), "/readyz"); code != http.StatusServiceUnavailable {
+		t.Errorf("before SetReady: got %d, want 503", code)
+	}
+	h.SetReady(true)
+	if code, rep := get(t, h.Handler(), "/readyz"); code != http.StatusOK || !rep.Ready {
+		t.Errorf("after SetReady: got %d %+v", code, rep)
+	}
+}
+
+func TestCheck_CachesResults(t *testing.T) {
+	probes := 0
+	h := health.New()
+	h.Add(health.Check{Name: "provider", TTL: time.Hour, Probe: func(context.Context) error {
+		probes++
+		return nil
+	}})
+	h.Report(context.Background())
+	h.Report(context.Background())
+	if probes != 1 {
+		t.Errorf("probed %d times, want 1 within the TTL", probes)
+	}
+}
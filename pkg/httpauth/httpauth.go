@@ -0,0 +1,149 @@
+package httpauth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// minKeyLen is the shortest API key accepted, so a key cannot be guessed
+// from the port alone.
+const minKeyLen = 16
+
+// Key is one API key allowed to call the HTTP endpoints.
+type Key struct {
+	Name              string // shown in logs instead of the key
+	Secret            string
+	RequestsPerMinute int // 0 is unlimited
+}
+
+// Authenticator checks API keys on HTTP requests and limits how often each
+// key may call.
+type Authenticator struct {
+	keys []authKey
+}
+
+type authKey struct {
+	Key
+	hash [sha256.Size]byte
+
+	mu     sync.Mutex
+	recent []time.Time // requests in the last minute
+}
+
+// New creates an Authenticator for keys. Every key needs a unique name and
+// a secret of at least 16 characters.
+func New(keys []Key) (*Authenticator, error) {
+	a := &Authenticator{keys: make([]authKey, len(keys))}
+	names := make(map[string]bool, len(keys))
+	for i, k := range keys {
+		switch {
+		case k.Name == "":
+			return nil, fmt.Errorf("api key %d has no name", i+1)
+		case names[k.Name]:
+			return nil, fmt.Errorf("api key %q is listed twice", k.Name)
+		case len(k.Secret) < minKeyLen:
+			return nil, fmt.Errorf("api key %q is shorter than %d characters", k.Name, minKeyLen)
+		}
+		names[k.Name] = true
+		a.keys[i].Key = k
+		a.keys[i].hash = sha256.Sum256([]byte(k.Secret))
+	}
+	return a, nil
+}
+
+// Errors returned by Check.
+var (
+	ErrNoKey       = errors.New("missing API key")
+	ErrBadKey      = errors.New("invalid API key")
+	ErrRateLimited = errors.New("rate limit exceeded")
+)
+
+// Check authenticates r by its "Authorization: Bearer <key>" or "X-API-Key"
+// header and counts the request against the key's limit. It returns the key
+// name, or an error and, for ErrRateLimited, how long to wait.
+func (a *Authenticator) Check(r *http.Request, now time.Time) (name string, retryAfter time.Duration, err error) {
+	secret := r.Header.Get("X-API-Key")
+	if auth := r.Header.Get("Authorization"); secret == "" && len(auth) > 7 && strings.EqualFold(auth[:7], "bearer ") {
+		secret = strings.TrimSpace(auth[7:])
+	}
+	if secret == "" {
+		return "", 0, ErrNoKey
+	}
+	// Compare fixed-size hashes against every key so timing reveals nothing
+	hash := sha256.Sum256([]byte(secret))
+	var match *authKey
+	for i := range a.keys {
+		if subtle.ConstantTimeCompare(hash[:], a.keys[i].hash[:]) == 1 {
+			match = &a.keys[i]
+		}
+	}
+	if match == nil {
+		return "", 0, ErrBadKey
+	}
+	if wait := match.take(now); wait > 0 {
+		return match.Name, wait, ErrRateLimited
+	}
+	return match.Name, 0, nil
+}
+
+// take records a request, or returns how long until the key may make one.
+func (k *authKey) take(now time.Time) time.Duration {
+	if k.RequestsPerMinute <= 0 {
+		return 0
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	cutoff := now.Add(-time.Minute)
+	recent := k.recent[:0]
+	for _, t := range k.recent {
+		if t.After(cutoff) {
+			recent = append(recent, t)
+		}
+	}
+	k.recent = recent
+	if len(k.recent) >= k.RequestsPerMinute {
+		return k.recent[0].Sub(cutoff)
+	}
+	k.recent = append(k.recent, now)
+	return 0
+}
+
+type keyNameKey struct{}
+
+// KeyName returns the name of the API key that authenticated the request
+// handled with ctx, or "".
+func KeyName(ctx context.Context) string {
+	name, _ := ctx.Value(keyNameKey{}).(string)
+	return name
+}
+
+// Middleware answers 401 to requests without a valid key and 429 to keys
+// over their limit; other requests reach next with the key name in their
+// context (see KeyName).
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, wait, err := a.Check(r, time.Now())
+		switch {
+		case errors.Is(err, ErrRateLimited):
+			slog.Warn("http request over rate limit", "key", name, "path", r.URL.Path)
+			w.Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+			return
+		case err != nil:
+			slog.Warn("rejected http request", "remote", r.RemoteAddr, "path", r.URL.Path, "err", err)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="littleclaw"`)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), keyNameKey{}, name)))
+	})
+}
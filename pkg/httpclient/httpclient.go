@@ -0,0 +1,105 @@
+package httpclient
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"time"
+)
+
+// Options configures the transport shared by the providers, transcribers,
+// channels, and tools; zero fields use the defaults.
+type Options struct {
+	Proxy               string        // http://, https://, or socks5:// URL; empty uses HTTP_PROXY/HTTPS_PROXY
+	DialTimeout         time.Duration // TCP connect (default 10s)
+	TLSHandshakeTimeout time.Duration // default 10s
+	IdleConnTimeout     time.Duration // how long pooled connections stay open (default 90s)
+	MaxIdleConnsPerHost int           // pooled connections per host (default 8)
+}
+
+const (
+	defaultDialTimeout         = 10 * time.Second
+	defaultTLSHandshakeTimeout = 10 * time.Second
+	defaultIdleConnTimeout     = 90 * time.Second
+	defaultMaxIdleConnsPerHost = 8
+)
+
+var shared atomic.Pointer[http.Transport]
+
+func init() {
+	t, _ := NewTransport(Options{})
+	shared.Store(t)
+}
+
+// NewTransport builds a transport for o.
+func NewTransport(o Options) (*http.Transport, error) {
+	proxy := http.ProxyFromEnvironment
+	if o.Proxy != "" {
+		u, err := url.Parse(o.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy %q: %w", o.Proxy, err)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return nil, fmt.Errorf("proxy %q must be an http, https, or socks5 URL", o.Proxy)
+		}
+		if u.Host == "" {
+			return nil, fmt.Errorf("proxy %q has no host", o.Proxy)
+		}
+		proxy = http.ProxyURL(u)
+	}
+	if o.DialTimeout <= 0 {
+		o.DialTimeout = defaultDialTimeout
+	}
+	if o.TLSHandshakeTimeout <= 0 {
+		o.TLSHandshakeTimeout = defaultTLSHandshakeTimeout
+	}
+	if o.IdleConnTimeout <= 0 {
+		o.IdleConnTimeout = defaultIdleConnTimeout
+	}
+	if o.MaxIdleConnsPerHost <= 0 {
+		o.MaxIdleConnsPerHost = defaultMaxIdleConnsPerHost
+	}
+	return &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           (&net.Dialer{Timeout: o.DialTimeout, KeepAlive: 30 * time.Second}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   o.MaxIdleConnsPerHost,
+		IdleConnTimeout:       o.IdleConnTimeout,
+		TLSHandshakeTimeout:   o.TLSHandshakeTimeout,
+		ExpectContinueTimeout: time.Second,
+	}, nil
+}
+
+// Configure replaces the shared transport. Clients from New pick it up on
+// their next request; connections pooled by the old one are closed once idle.
+func Configure(o Options) error {
+	t, err := NewTransport(o)
+	if err != nil {
+		return err
+	}
+	if old := shared.Swap(t); old != nil {
+		old.CloseIdleConnections()
+	}
+	return nil
+}
+
+// sharedTransport sends each request through the current shared transport.
+type sharedTransport struct{}
+
+func (sharedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return shared.Load().RoundTrip(req)
+}
+
+// Transport returns a RoundTripper that always uses the shared transport.
+func Transport() http.RoundTripper { return sharedTransport{} }
+
+// New returns a client on the shared transport. timeout bounds a whole
+// request including reading the body; 0 leaves it to the request context.
+func New(timeout time.Duration) *http.Client {
+	return &http.Client{Transport: sharedTransport{}, Timeout: timeout}
+}
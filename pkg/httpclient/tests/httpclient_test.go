@@ -0,0 +1,60 @@
+package httpclient_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"littleclaw/pkg/httpclient"
+)
+
+func TestConfigure_Proxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		io.WriteString(w, "via proxy")
+	}))
+	defer proxy.Close()
+	t.Cleanup(func() { httpclient.Configure(httpclient.Options{}) })
+
+	// Clients made before Configure pick up the new transport
+	client := httpclient.New(5 * time.Second)
+	if err := httpclient.Configure(httpclient.Options{Proxy: proxy.URL}); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Get("http://api.example.invalid/v1/models")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "via proxy" || proxied != "http://api.example.invalid/v1/models" {
+		t.Errorf("expected the request to go through the proxy, got %q for %q", body, proxied)
+	}
+}
+
+func TestConfigure_InvalidProxy(t *testing.T) {
+	for _, proxy := range []string{"ftp://proxy:21", "http://", "::not a url"} {
+		if err := httpclient.Configure(httpclient.Options{Proxy: proxy}); err == nil {
+			t.Errorf("%q: expected an error", proxy)
+		}
+	}
+}
+
+func TestNew_Timeout(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+	}))
+	defer slow.Close()
+
+	if _, err := httpclient.New(50 * time.Millisecond).Get(slow.URL); err == nil {
+		t.Error("expected the request to time out")
+	}
+	if resp, err := httpclient.New(0).Get(slow.URL); err != nil {
+		t.Errorf("a zero timeout should wait: %v", err)
+	} else {
+		resp.Body.Close()
+	}
+}
@@ -0,0 +1,200 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"littleclaw/pkg/redact"
+)
+
+// level is the minimum level logged; SetLevel changes it while running.
+var level = new(slog.LevelVar)
+
+// ParseLevel parses "debug", "info", "warn", or "error".
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(s) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("invalid log level %q: use debug, info, warn, or error", s)
+}
+
+// SetLevel sets the minimum level logged.
+func SetLevel(l slog.Level) { level.Set(l) }
+
+// Setup points slog's default logger at w, in format "text" (the default: a
+// timestamp, the message, then key=value attributes) or "json", with secrets
+// redacted (see RedactHandler). Lines from
+// the standard log package go through it too, at a level guessed from their
+// marker: ❌ is an error, ⚠️ a warning, anything else info.
+func Setup(w io.Writer, format string) error {
+	var h slog.Handler
+	switch format {
+	case "", "text":
+		h = NewTextHandler(w)
+	case "json":
+		h = slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})
+	default:
+		return fmt.Errorf("invalid log format %q: use text or json", format)
+	}
+	slog.SetDefault(slog.New(RedactHandler{h}))
+	// After SetDefault, which routes the log package to the handler at info
+	log.SetFlags(0)
+	log.SetOutput(stdlogWriter{})
+	return nil
+}
+
+// stdlogWriter turns standard log lines into slog records.
+type stdlogWriter struct{}
+
+func (stdlogWriter) Write(p []byte) (int, error) {
+	l := slog.LevelInfo
+	switch {
+	case bytes.Contains(p, []byte("❌")):
+		l = slog.LevelError
+	case bytes.Contains(p, []byte("⚠")):
+		l = slog.LevelWarn
+	}
+	slog.Default().Log(context.Background(), l, strings.TrimRight(string(p), "\n"))
+	return len(p), nil
+}
+
+// TextHandler writes "2006/01/02 15:04:05 message key=value ..." lines, the
+// format the log package used before slog.
+type TextHandler struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	attrs  string // preformatted attributes from WithAttrs
+	prefix string // group prefix for keys, e.g. "run."
+}
+
+// NewTextHandler creates a TextHandler writing to w at the package level.
+func NewTextHandler(w io.Writer) *TextHandler {
+	return &TextHandler{mu: new(sync.Mutex), w: w}
+}
+
+func (h *TextHandler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= level.Level()
+}
+
+func (h *TextHandler) Handle(_ context.Context, r slog.Record) error {
+	var buf bytes.Buffer
+	t := r.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	buf.WriteString(t.Format("2006/01/02 15:04:05 "))
+	buf.WriteString(r.Message)
+	buf.WriteString(h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		appendAttr(&buf, h.prefix, a)
+		return true
+	})
+	buf.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.w.Write(buf.Bytes())
+	return err
+}
+
+func (h *TextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var buf bytes.Buffer
+	for _, a := range attrs {
+		appendAttr(&buf, h.prefix, a)
+	}
+	h2 := *h
+	h2.attrs += buf.String()
+	return &h2
+}
+
+func (h *TextHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.prefix += name + "."
+	return &h2
+}
+
+// appendAttr writes " key=value", quoting values with spaces or quotes.
+func appendAttr(buf *bytes.Buffer, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		for _, g := range a.Value.Group() {
+			appendAttr(buf, prefix+a.Key+".", g)
+		}
+		return
+	}
+	v := a.Value.String()
+	if v == "" || strings.ContainsAny(v, " =\"\n\t") {
+		v = strconv.Quote(v)
+	}
+	buf.WriteString(" " + prefix + a.Key + "=" + v)
+}
+
+// RedactHandler passes records to another handler with secrets removed from
+// the message and the string and error attributes (see pkg/redact). Setup
+// wraps every handler in it.
+type RedactHandler struct {
+	slog.Handler
+}
+
+func (h RedactHandler) Handle(ctx context.Context, r slog.Record) error {
+	out := slog.NewRecord(r.Time, r.Level, redact.String(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(redactAttr(a))
+		return true
+	})
+	return h.Handler.Handle(ctx, out)
+}
+
+func (h RedactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = redactAttr(a)
+	}
+	return RedactHandler{h.Handler.WithAttrs(redacted)}
+}
+
+func (h RedactHandler) WithGroup(name string) slog.Handler {
+	return RedactHandler{h.Handler.WithGroup(name)}
+}
+
+// redactAttr redacts string and error values, including inside groups.
+func redactAttr(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(redact.String(a.Value.String()))
+	case slog.KindGroup:
+		group := a.Value.Group()
+		redacted := make([]slog.Attr, len(group))
+		for i, g := range group {
+			redacted[i] = redactAttr(g)
+		}
+		a.Value = slog.GroupValue(redacted...)
+	case slog.KindAny:
+		if err, ok := a.Value.Any().(error); ok {
+			a.Value = slog.StringValue(redact.String(err.Error()))
+		}
+	}
+	return a
+}
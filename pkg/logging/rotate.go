@@ -0,0 +1,185 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMaxSize is the size at which the log file is rotated.
+	DefaultMaxSize = 10 << 20
+	// DefaultMaxBackups is how many rotated files are kept.
+	DefaultMaxBackups = 5
+	// DefaultMaxAge is how long rotated files are kept.
+	DefaultMaxAge = 14 * 24 * time.Hour
+
+	// backupTimeFormat stamps rotated files, e.g. littleclaw-20261016-142301.000.log.
+	backupTimeFormat = "20060102-150405.000"
+)
+
+// RotateOptions controls when a RotatingFile rotates and what it keeps. Zero
+// fields keep the defaults.
+type RotateOptions struct {
+	MaxSize    int64         // rotate once the file would grow past this many bytes (default 10 MB)
+	Daily      bool          // also rotate when the date changes
+	MaxBackups int           // rotated files to keep (default 5)
+	MaxAge     time.Duration // delete rotated files older than this (default 14 days)
+}
+
+func (o RotateOptions) withDefaults() RotateOptions {
+	if o.MaxSize <= 0 {
+		o.MaxSize = DefaultMaxSize
+	}
+	if o.MaxBackups <= 0 {
+		o.MaxBackups = DefaultMaxBackups
+	}
+	if o.MaxAge <= 0 {
+		o.MaxAge = DefaultMaxAge
+	}
+	return o
+}
+
+// RotatingFile is a log file that rotates itself by size and, optionally, by
+// day. Rotated files sit next to it with a timestamp in the name and are
+// pruned by count and age.
+type RotatingFile struct {
+	path string
+	opts RotateOptions
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+	day  string // date of the current file's first write, for Daily
+}
+
+// OpenRotatingFile opens path for appending, creating its directory. With
+// Daily, a file last written on an earlier day is rotated first.
+func OpenRotatingFile(path string, opts RotateOptions) (*RotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	r := &RotatingFile{path: path, opts: opts.withDefaults()}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	if r.opts.Daily && r.size > 0 && r.day != today() {
+		if err := r.rotate(); err != nil {
+			r.f.Close()
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Path returns the file being written.
+func (r *RotatingFile) Path() string { return r.path }
+
+// Write appends p, rotating first if p would push the file past MaxSize or the
+// date has changed.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return 0, os.ErrClosed
+	}
+	if r.size > 0 && (r.size+int64(len(p)) > r.opts.MaxSize || (r.opts.Daily && r.day != today())) {
+		if err := r.rotate(); err != nil {
+			return 0, fmt.Errorf("rotate %s: %w", r.path, err)
+		}
+	}
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Close closes the file.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
+
+func (r *RotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.f, r.size, r.day = f, info.Size(), today()
+	if info.Size() > 0 {
+		r.day = info.ModTime().Format("2006-01-02")
+	}
+	return nil
+}
+
+// rotate moves the current file aside, opens a fresh one, and prunes old
+// backups; callers must hold r.mu.
+func (r *RotatingFile) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return err
+	}
+	prefix, ext := r.backupName()
+	if err := os.Rename(r.path, prefix+time.Now().Format(backupTimeFormat)+ext); err != nil {
+		// Keep writing to the old file rather than losing lines
+		if oerr := r.open(); oerr != nil {
+			r.f = nil
+		}
+		return err
+	}
+	if err := r.open(); err != nil {
+		r.f = nil
+		return err
+	}
+	r.prune()
+	return nil
+}
+
+// backupName splits path into the prefix and extension of its backups:
+// "logs/littleclaw.log" gives "logs/littleclaw-" and ".log".
+func (r *RotatingFile) backupName() (string, string) {
+	ext := filepath.Ext(r.path)
+	return strings.TrimSuffix(r.path, ext) + "-", ext
+}
+
+// Backups returns the rotated files, oldest first.
+func (r *RotatingFile) Backups() []string {
+	prefix, ext := r.backupName()
+	matches, _ := filepath.Glob(prefix + "*" + ext)
+	var backups []string
+	for _, m := range matches {
+		stamp := strings.TrimSuffix(strings.TrimPrefix(m, prefix), ext)
+		if _, err := time.Parse(backupTimeFormat, stamp); err == nil {
+			backups = append(backups, m)
+		}
+	}
+	sort.Strings(backups)
+	return backups
+}
+
+// prune deletes backups beyond MaxBackups or older than MaxAge.
+func (r *RotatingFile) prune() {
+	backups := r.Backups()
+	cutoff := time.Now().Add(-r.opts.MaxAge)
+	for i, b := range backups {
+		info, err := os.Stat(b)
+		if i < len(backups)-r.opts.MaxBackups || (err == nil && info.ModTime().Before(cutoff)) {
+			os.Remove(b)
+		}
+	}
+}
+
+func today() string { return time.Now().Format("2006-01-02") }
@@ -0,0 +1,111 @@
+package logging_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"littleclaw/pkg/logging"
+	"littleclaw/pkg/redact"
+)
+
+// capture points the default logger at a buffer for the test.
+func capture(t *testing.T, format string, level slog.Level) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := logging.Setup(&buf, format); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	logging.SetLevel(level)
+	t.Cleanup(func() {
+		logging.Setup(os.Stderr, "text")
+		logging.SetLevel(slog.LevelInfo)
+	})
+	return &buf
+}
+
+func TestParseLevel(t *testing.T) {
+	for in, want := range map[string]slog.Level{"debug": slog.LevelDebug, "INFO": slog.LevelInfo, "warn": slog.LevelWarn, "warning": slog.LevelWarn, "error": slog.LevelError} {
+		if got, err := logging.ParseLevel(in); err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := logging.ParseLevel("loud"); err == nil {
+		t.Error("expected an error for an unknown level")
+	}
+}
+
+func TestSetup_InvalidFormat(t *testing.T) {
+	if err := logging.Setup(&bytes.Buffer{}, "xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestTextHandler_Format(t *testing.T) {
+	buf := capture(t, "text", slog.LevelInfo)
+
+	slog.With("chat_id", "42").Info("cron service: firing job", "job_id", "abc", "label", "daily digest")
+
+	line := buf.String()
+	if !regexp.MustCompile(`^\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2} cron service: firing job chat_id=42 job_id=abc label="daily digest"\n$`).MatchString(line) {
+		t.Errorf("unexpected line: %q", line)
+	}
+}
+
+func TestLevelFilter(t *testing.T) {
+	buf := capture(t, "text", slog.LevelWarn)
+
+	slog.Debug("debug line")
+	slog.Info("info line")
+	slog.Warn("warn line")
+	if out := buf.String(); strings.Contains(out, "debug line") || strings.Contains(out, "info line") || !strings.Contains(out, "warn line") {
+		t.Errorf("expected only the warning, got:\n%s", out)
+	}
+
+	logging.SetLevel(slog.LevelDebug)
+	slog.Debug("debug again", "tool", "exec")
+	if !strings.Contains(buf.String(), "debug again tool=exec") {
+		t.Errorf("expected the debug line after lowering the level, got:\n%s", buf.String())
+	}
+}
+
+func TestStandardLogBridge(t *testing.T) {
+	buf := capture(t, "json", slog.LevelWarn)
+
+	log.Printf("📩 plain info line")
+	log.Printf("⚠️ something odd")
+	log.Printf("❌ something broke")
+
+	var levels []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("not JSON: %q", line)
+		}
+		levels = append(levels, rec["level"].(string))
+	}
+	if strings.Join(levels, ",") != "WARN,ERROR" {
+		t.Errorf("levels = %v, want [WARN ERROR]", levels)
+	}
+}
+
+func TestSetup_RedactsSecrets(t *testing.T) {
+	buf := capture(t, "text", slog.LevelInfo)
+	redact.SetSecrets("configured-secret-value")
+	t.Cleanup(func() { redact.SetSecrets() })
+
+	slog.Info("calling with configured-secret-value", "header", "Authorization: Bearer abcdef123456", "err", errors.New("bad key configured-secret-value"))
+	out := buf.String()
+	if strings.Contains(out, "configured-secret-value") || strings.Contains(out, "abcdef123456") {
+		t.Errorf("secret left in log line %q", out)
+	}
+	if !strings.Contains(out, redact.Placeholder) {
+		t.Errorf("expected the placeholder in %q", out)
+	}
+}
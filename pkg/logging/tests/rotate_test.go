@@ -0,0 +1,113 @@
+package logging_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"littleclaw/pkg/logging"
+)
+
+func openRotating(t *testing.T, path string, opts logging.RotateOptions) *logging.RotatingFile {
+	t.Helper()
+	r, err := logging.OpenRotatingFile(path, opts)
+	if err != nil {
+		t.Fatalf("OpenRotatingFile() error = %v", err)
+	}
+	t.Cleanup(func() { r.Close() })
+	return r
+}
+
+func TestRotatingFile_RotatesBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "littleclaw.log")
+	r := openRotating(t, path, logging.RotateOptions{MaxSize: 100, MaxBackups: 2})
+
+	line := strings.Repeat("x", 39) + "\n"
+	for i := 0; i < 10; i++ {
+		if _, err := r.Write([]byte(line)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		time.Sleep(2 * time.Millisecond) // distinct backup timestamps
+	}
+
+	data, _ := os.ReadFile(path)
+	if len(data) > 100 || len(data) == 0 {
+		t.Errorf("current file is %d bytes, want 1..100", len(data))
+	}
+	backups := r.Backups()
+	if len(backups) != 2 {
+		t.Fatalf("backups = %v, want 2 kept", backups)
+	}
+	for _, b := range backups {
+		if data, _ := os.ReadFile(b); len(data) != 80 {
+			t.Errorf("%s is %d bytes, want 80", b, len(data))
+		}
+	}
+}
+
+func TestRotatingFile_AppendsAcrossOpens(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "littleclaw.log")
+	r := openRotating(t, path, logging.RotateOptions{})
+	r.Write([]byte("one\n"))
+	r.Close()
+
+	r = openRotating(t, path, logging.RotateOptions{})
+	r.Write([]byte("two\n"))
+	if data, _ := os.ReadFile(path); string(data) != "one\ntwo\n" {
+		t.Errorf("file = %q, want both lines", data)
+	}
+	if len(r.Backups()) != 0 {
+		t.Errorf("unexpected backups: %v", r.Backups())
+	}
+}
+
+func TestRotatingFile_DailyRotatesYesterdaysFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "littleclaw.log")
+	if err := os.WriteFile(path, []byte("yesterday\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	yesterday := time.Now().Add(-24 * time.Hour)
+	os.Chtimes(path, yesterday, yesterday)
+
+	r := openRotating(t, path, logging.RotateOptions{Daily: true})
+	r.Write([]byte("today\n"))
+
+	if data, _ := os.ReadFile(path); string(data) != "today\n" {
+		t.Errorf("current file = %q, want only today's line", data)
+	}
+	backups := r.Backups()
+	if len(backups) != 1 {
+		t.Fatalf("backups = %v, want 1", backups)
+	}
+	if data, _ := os.ReadFile(backups[0]); string(data) != "yesterday\n" {
+		t.Errorf("backup = %q", data)
+	}
+}
+
+func TestRotatingFile_PrunesByAge(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "littleclaw.log")
+	old := filepath.Join(dir, "littleclaw-20200101-000000.000.log")
+	unrelated := filepath.Join(dir, "littleclaw-notes.log")
+	for _, f := range []string{old, unrelated} {
+		os.WriteFile(f, []byte("old\n"), 0644)
+		past := time.Now().Add(-30 * 24 * time.Hour)
+		os.Chtimes(f, past, past)
+	}
+
+	r := openRotating(t, path, logging.RotateOptions{MaxSize: 10, MaxAge: 7 * 24 * time.Hour})
+	r.Write([]byte("first line\n"))
+	r.Write([]byte("second line\n")) // rotates and prunes
+
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Error("expected the month-old backup to be pruned")
+	}
+	if _, err := os.Stat(unrelated); err != nil {
+		t.Error("a file that is not a backup should be left alone")
+	}
+	if len(r.Backups()) != 1 {
+		t.Errorf("backups = %v, want the fresh one", r.Backups())
+	}
+}
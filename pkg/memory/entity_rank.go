@@ -0,0 +1,177 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// DefaultEntityTopK is how many entities FindRelevantEntities surfaces.
+const DefaultEntityTopK = 3
+
+const (
+	// minEntityScore is the relevance an entity needs to be surfaced.
+	minEntityScore = 0.15
+	// contentMatchWeight is what an entity scores when its content mentions
+	// every keyword of the message.
+	contentMatchWeight = 0.4
+	// entitySummaryBytes caps each entity's excerpt in the prompt; read_entity
+	// returns the rest.
+	entitySummaryBytes = 600
+)
+
+// entityStopWords are ignored when matching a message against entity content.
+var entityStopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "that": true, "this": true, "with": true,
+	"you": true, "your": true, "from": true, "what": true, "can": true, "please": true,
+	"are": true, "was": true, "have": true, "has": true, "not": true, "but": true,
+	"all": true, "any": true, "into": true, "about": true, "just": true, "like": true,
+	"will": true, "would": true, "could": true, "should": true, "there": true, "them": true,
+	"does": true, "did": true, "how": true, "who": true, "when": true, "where": true,
+	"why": true, "tell": true, "know": true, "think": true, "remember": true,
+}
+
+// EntityMatch is an entity ranked against a message.
+type EntityMatch struct {
+	Name  string
+	Score float64 // name similarity plus keyword overlap with the content
+}
+
+// RankEntities scores every entity against query, by its name (trigram
+// similarity and word matches) and by how many of the query's keywords its
+// content mentions, and returns the best k above the relevance threshold.
+func (s *Store) RankEntities(query string, k int) []EntityMatch {
+	names, err := s.ListEntities()
+	if err != nil || len(names) == 0 || k <= 0 {
+		return nil
+	}
+
+	queryLower := strings.ToLower(query)
+	queryTrigrams := Trigrams(queryLower)
+	keywords := entityKeywords(queryLower)
+
+	var matches []EntityMatch
+	for _, name := range names {
+		score := entityNameScore(strings.ToLower(name), queryLower, queryTrigrams)
+		if len(keywords) > 0 {
+			score += contentMatchWeight * keywordOverlap(s.ReadEntity(name), keywords)
+		}
+		if score > minEntityScore {
+			matches = append(matches, EntityMatch{Name: name, Score: score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Name < matches[j].Name
+	})
+	if len(matches) > k {
+		matches = matches[:k]
+	}
+	return matches
+}
+
+// entityNameScore rates how closely query refers to an entity by name.
+func entityNameScore(nameLower, queryLower string, queryTrigrams map[string]bool) float64 {
+	nameForMatch := strings.ReplaceAll(nameLower, "_", " ")
+	score := TrigramSimilarity(queryTrigrams, Trigrams(nameForMatch))
+
+	// Boost: exact word match (any word >= 3 chars from entity name appears in query)
+	for _, word := range strings.Fields(nameForMatch) {
+		if len(word) >= 3 && strings.Contains(queryLower, word) {
+			score += 0.4
+			break
+		}
+	}
+
+	// Boost: full entity name substring match
+	if strings.Contains(queryLower, nameForMatch) || strings.Contains(queryLower, nameLower) {
+		score += 0.6
+	}
+	return score
+}
+
+// entityKeywords returns the distinct words of a message worth matching.
+func entityKeywords(s string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, w := range splitWords(s) {
+		if len(w) < 3 || entityStopWords[w] || seen[w] {
+			continue
+		}
+		seen[w] = true
+		out = append(out, w)
+	}
+	return out
+}
+
+// keywordOverlap returns the share of keywords that appear as words in
+// content, allowing a plural "s" either way.
+func keywordOverlap(content string, keywords []string) float64 {
+	words := make(map[string]bool)
+	for _, w := range splitWords(strings.ToLower(content)) {
+		words[w] = true
+	}
+	found := 0
+	for _, k := range keywords {
+		if words[k] || words[k+"s"] || words[strings.TrimSuffix(k, "s")] {
+			found++
+		}
+	}
+	return float64(found) / float64(len(keywords))
+}
+
+func splitWords(s string) []string {
+	return strings.FieldsFunc(s, func(c rune) bool {
+		return !unicode.IsLetter(c) && !unicode.IsDigit(c)
+	})
+}
+
+// FindRelevantEntities returns summaries of the DefaultEntityTopK entities
+// most relevant to query, capped at maxBytes total.
+func (s *Store) FindRelevantEntities(query string, maxBytes int) string {
+	return s.RelevantEntities(query, DefaultEntityTopK, maxBytes)
+}
+
+// RelevantEntities returns summaries of the topK entities most relevant to
+// query (see RankEntities), capped at maxBytes total. Each summary is the
+// start of the entity file; the model can read_entity for the rest.
+func (s *Store) RelevantEntities(query string, topK, maxBytes int) string {
+	var parts []string
+	totalLen := 0
+	for _, m := range s.RankEntities(query, topK) {
+		data := s.ReadEntity(m.Name)
+		if data == "" {
+			continue
+		}
+
+		entry := fmt.Sprintf("[Entity: %s (relevance: %.0f%%)]\n%s", m.Name, m.Score*100, entitySummary(data))
+		if totalLen+len(entry) > maxBytes {
+			remaining := maxBytes - totalLen
+			if remaining > 100 {
+				parts = append(parts, entry[:remaining]+"\n...(truncated)")
+			}
+			break
+		}
+		parts = append(parts, entry)
+		totalLen += len(entry)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// entitySummary returns the start of an entity file, cut at a line break
+// when it is longer than entitySummaryBytes.
+func entitySummary(content string) string {
+	content = strings.TrimSpace(content)
+	if len(content) <= entitySummaryBytes {
+		return content
+	}
+	cut := content[:entitySummaryBytes]
+	if i := strings.LastIndex(cut, "\n"); i > entitySummaryBytes/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimSpace(cut) + "\n...(more with read_entity)"
+}
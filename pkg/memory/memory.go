@@ -12,6 +12,8 @@ import (
 	"sync/atomic"
 	"time"
 	"unicode"
+
+	"littleclaw/pkg/redact"
 )
 
 const (
@@ -25,6 +27,8 @@ const (
 	maxSearchResults = 20
 	// maxInternalReadbackBytes caps how much of the internal log is returned by the readback tool.
 	maxInternalReadbackBytes = 4000
+	// historyCacheBytes caps how much of today's log is kept in memory for ReadRecentHistory.
+	historyCacheBytes = 64 * 1024
 )
 
 // Store represents the persistent, multi-tier memory system.
@@ -41,6 +45,19 @@ type Store struct {
 	soulFile      string
 	identityFile  string
 	userFile      string
+	resetFile     string // holds the time of the last ResetRecentHistory
+
+	history *historyCache // recent history in memory; nil until first read
+}
+
+// historyCache holds what ReadRecentHistory needs from the daily logs, so
+// building a prompt does not read them on every turn. It is filled on first
+// use and when the day changes, then kept current by AppendHistory,
+// ResetRecentHistory, and WriteSummary. Guarded by Store.mu.
+type historyCache struct {
+	day       string // date the cache was filled on, "2006-01-02"
+	today     string // today's entries since the last reset, at most historyCacheBytes
+	yesterday string // yesterday's log, or its summary, since the last reset
 }
 
 // NewStore initializes the memory system paths and creates directories holding the knowledge.
@@ -60,6 +77,7 @@ func NewStore(workspace string) (*Store, error) {
 		soulFile:      filepath.Join(memoryDir, "SOUL.md"),
 		identityFile:  filepath.Join(memoryDir, "IDENTITY.md"),
 		userFile:      filepath.Join(memoryDir, "USER.md"),
+		resetFile:     filepath.Join(memoryDir, "HISTORY_RESET"),
 	}
 
 	// Ensure directories exist
@@ -83,6 +101,7 @@ func (s *Store) InternalFile() string  { return s.internalFile }
 func (s *Store) SoulFile() string      { return s.soulFile }
 func (s *Store) IdentityFile() string  { return s.identityFile }
 func (s *Store) UserFile() string      { return s.userFile }
+func (s *Store) MemoryFile() string    { return s.memoryFile }
 
 // DailyLogPath returns the path to the daily log for a given time.
 func (s *Store) DailyLogPath(t time.Time) string { return s.dailyLogPath(t) }
@@ -262,6 +281,55 @@ func (s *Store) pruneMemoryVersions() {
 	}
 }
 
+// memoryVersionRe matches MEMORY.md backups and captures their timestamp.
+var memoryVersionRe = regexp.MustCompile(`^MEMORY_(\d{8}_\d{6})\.md$`)
+
+// ListMemoryVersions returns the timestamps (YYYYMMDD_HHMMSS) of the kept
+// MEMORY.md backups, newest first.
+func (s *Store) ListMemoryVersions() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries, err := os.ReadDir(s.memoryDir)
+	if err != nil {
+		return nil
+	}
+	var versions []string
+	for _, e := range entries {
+		if m := memoryVersionRe.FindStringSubmatch(e.Name()); m != nil && !e.IsDir() {
+			versions = append(versions, m[1])
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(versions)))
+	return versions
+}
+
+// ReadMemoryVersion returns the MEMORY.md backup taken at version.
+func (s *Store) ReadMemoryVersion(version string) (string, error) {
+	name := "MEMORY_" + version + ".md"
+	if !memoryVersionRe.MatchString(name) {
+		return "", fmt.Errorf("invalid memory version %q (want YYYYMMDD_HHMMSS)", version)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, err := os.ReadFile(filepath.Join(s.memoryDir, name))
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("no memory version %s", version)
+	}
+	return string(data), err
+}
+
+// RestoreMemoryVersion replaces MEMORY.md with the backup taken at version.
+// The current MEMORY.md is backed up first, so a restore can be undone.
+func (s *Store) RestoreMemoryVersion(version string) error {
+	content, err := s.ReadMemoryVersion(version)
+	if err != nil {
+		return err
+	}
+	return s.WriteLongTerm(content)
+}
+
 // ---------------------------------------------------------------------------
 // Daily log conversation history (replaces monolithic HISTORY.md)
 // ---------------------------------------------------------------------------
@@ -279,9 +347,10 @@ func (s *Store) AppendHistory(role, content string) error {
 	// Mark dirty so the heartbeat knows there is new content to consolidate
 	s.dirty.Store(true)
 
-	logPath := s.dailyLogPath(time.Now())
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
-	entry := fmt.Sprintf("[%s] %s: %s\n\n", timestamp, strings.ToUpper(role), content)
+	now := time.Now()
+	logPath := s.dailyLogPath(now)
+	timestamp := now.Format("2006-01-02 15:04:05")
+	entry := fmt.Sprintf("[%s] %s: %s\n\n", timestamp, strings.ToUpper(role), redact.String(content))
 
 	f, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
@@ -289,26 +358,33 @@ func (s *Store) AppendHistory(role, content string) error {
 	}
 	defer f.Close()
 
-	_, err = f.WriteString(entry)
-	return err
+	if _, err = f.WriteString(entry); err != nil {
+		s.history = nil // the log may hold part of the entry; reread it
+		return err
+	}
+	if c := s.history; c != nil && c.day == now.Format("2006-01-02") {
+		c.today = trimHead(c.today+entry, historyCacheBytes)
+	}
+	return nil
 }
 
 // ReadRecentHistory returns conversation history from today and yesterday's daily logs,
 // capped at maxBytes. If yesterday's log exceeds MaxDailyLogBytes, its summary is used instead.
+// It is served from memory (see historyCache); the logs are read once a day.
 func (s *Store) ReadRecentHistory(maxBytes int) string {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
 	now := time.Now()
 	today := now
 	yesterday := now.AddDate(0, 0, -1)
+	cache := s.recentHistory(now)
 
 	var parts []string
 	totalLen := 0
 
-	// Load yesterday's content (or its summary if too large)
-	yesterdayContent := s.readDailyLogOrSummary(yesterday)
-	if yesterdayContent != "" {
+	// Yesterday's content (or its summary if too large)
+	if yesterdayContent := cache.yesterday; yesterdayContent != "" {
 		header := fmt.Sprintf("--- %s (yesterday) ---\n%s", yesterday.Format("2006-01-02"), yesterdayContent)
 		if totalLen+len(header) > maxBytes {
 			// Truncate yesterday to fit budget
@@ -324,9 +400,8 @@ func (s *Store) ReadRecentHistory(maxBytes int) string {
 		}
 	}
 
-	// Load today's full content
-	todayContent := s.readDailyLogRaw(today)
-	if todayContent != "" {
+	// Today's content, up to historyCacheBytes
+	if todayContent := cache.today; todayContent != "" {
 		header := fmt.Sprintf("--- %s (today) ---\n%s", today.Format("2006-01-02"), todayContent)
 		if totalLen+len(header) > maxBytes {
 			// Truncate today, keeping the tail (most recent)
@@ -346,6 +421,87 @@ func (s *Store) ReadRecentHistory(maxBytes int) string {
 	return strings.Join(parts, "\n\n")
 }
 
+// recentHistory returns the history cache for now, reading the daily logs if
+// it is empty or was filled on another day. Entries logged before the last
+// reset are left out.
+// Must be called with s.mu held for writing.
+func (s *Store) recentHistory(now time.Time) *historyCache {
+	day := now.Format("2006-01-02")
+	if s.history != nil && s.history.day == day {
+		return s.history
+	}
+
+	c := &historyCache{day: day}
+	resetDay, resetOffset := s.historyReset()
+	yesterday := now.AddDate(0, 0, -1)
+	switch yday := yesterday.Format("2006-01-02"); {
+	case resetDay < yday: // no reset, or an older one
+		c.yesterday = s.readDailyLogOrSummary(yesterday)
+	case resetDay == yday:
+		c.yesterday = tailFrom(s.readDailyLogRaw(yesterday), resetOffset)
+	}
+	c.today = s.readDailyLogRaw(now)
+	if resetDay == day {
+		c.today = tailFrom(c.today, resetOffset)
+	}
+	c.today = trimHead(c.today, historyCacheBytes)
+	s.history = c
+	return c
+}
+
+// trimHead drops whole entries from the front of log until it fits in max
+// bytes.
+func trimHead(log string, max int) string {
+	if len(log) <= max {
+		return log
+	}
+	log = log[len(log)-max:]
+	if i := strings.Index(log, "\n\n["); i >= 0 {
+		return log[i+2:]
+	}
+	return log
+}
+
+// ResetRecentHistory starts a fresh conversation window: ReadRecentHistory
+// leaves out everything logged before now. The daily logs themselves are kept
+// and stay searchable.
+func (s *Store) ResetRecentHistory() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	var size int64
+	if info, err := os.Stat(s.dailyLogPath(now)); err == nil {
+		size = info.Size()
+	}
+	if err := os.WriteFile(s.resetFile, []byte(fmt.Sprintf("%s %d\n", now.Format("2006-01-02"), size)), 0644); err != nil {
+		return err
+	}
+	s.history = &historyCache{day: now.Format("2006-01-02")}
+	return nil
+}
+
+// historyReset returns the day and daily-log offset of the last
+// ResetRecentHistory; day is "" if there was none.
+// Must be called with s.mu held (at least RLock).
+func (s *Store) historyReset() (day string, offset int) {
+	data, err := os.ReadFile(s.resetFile)
+	if err != nil {
+		return "", 0
+	}
+	if _, err := fmt.Sscanf(string(data), "%s %d", &day, &offset); err != nil {
+		return "", 0
+	}
+	return day, offset
+}
+
+// tailFrom returns log from byte offset on.
+func tailFrom(log string, offset int) string {
+	if offset >= len(log) {
+		return ""
+	}
+	return log[offset:]
+}
+
 // readDailyLogRaw reads the full content of a daily log file.
 // Must be called with s.mu held (at least RLock).
 func (s *Store) readDailyLogRaw(t time.Time) string {
@@ -541,6 +697,8 @@ func (s *Store) WriteSummary(date, content string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	// Yesterday's summary replaces its log in the recent history
+	s.history = nil
 	summaryPath := filepath.Join(s.summariesDir, date+".md")
 	return os.WriteFile(summaryPath, []byte(content), 0644)
 }
@@ -562,7 +720,7 @@ func (s *Store) AppendInternal(role, content string) error {
 	}
 
 	timestamp := time.Now().Format("2006-01-02 15:04:05")
-	entry := fmt.Sprintf("[%s] %s: %s\n\n", timestamp, strings.ToUpper(role), content)
+	entry := fmt.Sprintf("[%s] %s: %s\n\n", timestamp, strings.ToUpper(role), redact.String(content))
 
 	f, err := os.OpenFile(s.internalFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
@@ -754,90 +912,35 @@ func (s *Store) ListEntities() ([]string, error) {
 	return entities, nil
 }
 
-// ---------------------------------------------------------------------------
-// Entity auto-surfacing with trigram similarity
-// ---------------------------------------------------------------------------
+// EntitySearchResult is an entity whose name or content matches a search.
+type EntitySearchResult struct {
+	Name string
+	Line string // first matching line, or "" when only the name matched
+}
 
-// FindRelevantEntities scores each entity against the query using trigram similarity
-// and returns the top matches, capped at maxBytes total.
-func (s *Store) FindRelevantEntities(query string, maxBytes int) string {
-	entities, err := s.ListEntities()
-	if err != nil || len(entities) == 0 {
-		return ""
+// SearchEntities returns the entities whose name or content contains query,
+// case-insensitively.
+func (s *Store) SearchEntities(query string) []EntitySearchResult {
+	names, err := s.ListEntities()
+	if err != nil {
+		return nil
 	}
-
 	queryLower := strings.ToLower(query)
-	queryTrigrams := Trigrams(queryLower)
-
-	type scored struct {
-		name  string
-		score float64
-	}
-
-	var candidates []scored
-	for _, name := range entities {
-		nameLower := strings.ToLower(name)
-		nameForMatch := strings.ReplaceAll(nameLower, "_", " ")
-
-		score := TrigramSimilarity(queryTrigrams, Trigrams(nameForMatch))
-
-		// Boost: exact word match (any word >= 3 chars from entity name appears in query)
-		words := strings.Fields(nameForMatch)
-		for _, word := range words {
-			if len(word) >= 3 && strings.Contains(queryLower, word) {
-				score += 0.4
+	var results []EntitySearchResult
+	for _, name := range names {
+		nameMatch := strings.Contains(strings.ToLower(name), queryLower)
+		var line string
+		for _, l := range strings.Split(s.ReadEntity(name), "\n") {
+			if strings.Contains(strings.ToLower(l), queryLower) {
+				line = strings.TrimSpace(l)
 				break
 			}
 		}
-
-		// Boost: full entity name substring match
-		if strings.Contains(queryLower, nameForMatch) || strings.Contains(queryLower, nameLower) {
-			score += 0.6
-		}
-
-		if score > 0.15 { // threshold for relevance
-			candidates = append(candidates, scored{name: name, score: score})
-		}
-	}
-
-	if len(candidates) == 0 {
-		return ""
-	}
-
-	// Sort by score descending
-	sort.Slice(candidates, func(i, j int) bool {
-		return candidates[i].score > candidates[j].score
-	})
-
-	var parts []string
-	totalLen := 0
-
-	for _, c := range candidates {
-		data := s.ReadEntity(c.name)
-		if data == "" {
-			continue
+		if nameMatch || line != "" {
+			results = append(results, EntitySearchResult{Name: name, Line: line})
 		}
-
-		entry := fmt.Sprintf("[Entity: %s (relevance: %.0f%%)]\n%s", c.name, c.score*100, data)
-		entryLen := len(entry)
-
-		if totalLen+entryLen > maxBytes {
-			remaining := maxBytes - totalLen
-			if remaining > 100 {
-				entry = entry[:remaining] + "\n...(truncated)"
-				parts = append(parts, entry)
-			}
-			break
-		}
-
-		parts = append(parts, entry)
-		totalLen += entryLen
 	}
-
-	if len(parts) == 0 {
-		return ""
-	}
-	return strings.Join(parts, "\n\n")
+	return results
 }
 
 // ---------------------------------------------------------------------------
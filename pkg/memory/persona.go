@@ -0,0 +1,140 @@
+package memory
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// DefaultPersonaName is the agent's name when SYSTEM.md does not set one.
+	DefaultPersonaName = "Littleclaw"
+	// DefaultPersonaTone is the tone used when SYSTEM.md does not set one.
+	DefaultPersonaTone = "Use extremely simple, direct language. No fluff, no formal greetings. Be brief."
+
+	personaHeader       = "SYSTEM.md - Persona"
+	personaInstructions = "Standing Instructions:"
+)
+
+// Persona is the agent's configurable personality, stored in memory/SYSTEM.md.
+// Empty fields fall back to the defaults. Instructions holds any free text,
+// so a hand-written SYSTEM.md without the field lines still applies.
+type Persona struct {
+	Name         string
+	Tone         string
+	Language     string
+	Instructions string
+}
+
+// IsZero reports whether p sets nothing.
+func (p Persona) IsZero() bool {
+	return p == Persona{}
+}
+
+// WithDefaults returns p with an empty name and tone filled in.
+func (p Persona) WithDefaults() Persona {
+	if p.Name == "" {
+		p.Name = DefaultPersonaName
+	}
+	if p.Tone == "" {
+		p.Tone = DefaultPersonaTone
+	}
+	return p
+}
+
+// Merge returns p with every non-empty field of o applied on top.
+func (p Persona) Merge(o Persona) Persona {
+	if o.Name != "" {
+		p.Name = o.Name
+	}
+	if o.Tone != "" {
+		p.Tone = o.Tone
+	}
+	if o.Language != "" {
+		p.Language = o.Language
+	}
+	if o.Instructions != "" {
+		p.Instructions = o.Instructions
+	}
+	return p
+}
+
+// Render formats p as SYSTEM.md content.
+func (p Persona) Render() string {
+	var sb strings.Builder
+	sb.WriteString(personaHeader + "\n\n")
+	if p.Name != "" {
+		sb.WriteString("- Name: " + p.Name + "\n")
+	}
+	if p.Tone != "" {
+		sb.WriteString("- Tone: " + p.Tone + "\n")
+	}
+	if p.Language != "" {
+		sb.WriteString("- Language: " + p.Language + "\n")
+	}
+	if p.Instructions != "" {
+		sb.WriteString("\n" + personaInstructions + "\n\n" + p.Instructions + "\n")
+	}
+	return sb.String()
+}
+
+// ParsePersona reads SYSTEM.md content: "- Name:", "- Tone:" and "- Language:"
+// lines set those fields; everything else becomes the standing instructions.
+func ParsePersona(content string) Persona {
+	var p Persona
+	var rest []string
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == personaHeader || trimmed == personaInstructions {
+			continue
+		}
+		if key, value, ok := strings.Cut(strings.TrimPrefix(trimmed, "- "), ":"); ok && strings.HasPrefix(trimmed, "- ") {
+			value = strings.TrimSpace(value)
+			switch strings.ToLower(strings.TrimSpace(key)) {
+			case "name":
+				p.Name = value
+				continue
+			case "tone":
+				p.Tone = value
+				continue
+			case "language":
+				p.Language = value
+				continue
+			}
+		}
+		rest = append(rest, line)
+	}
+	p.Instructions = strings.TrimSpace(strings.Join(rest, "\n"))
+	return p
+}
+
+// PersonaFile returns the path to SYSTEM.md.
+func (s *Store) PersonaFile() string { return filepath.Join(s.memoryDir, "SYSTEM.md") }
+
+// ReadPersona returns the persona from SYSTEM.md; it is zero if the file is
+// missing or empty.
+func (s *Store) ReadPersona() Persona {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, err := os.ReadFile(s.PersonaFile())
+	if err != nil {
+		return Persona{}
+	}
+	return ParsePersona(string(data))
+}
+
+// WritePersona replaces SYSTEM.md with p. A zero persona removes the file,
+// restoring the default personality.
+func (s *Store) WritePersona(p Persona) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if p.IsZero() {
+		if err := os.Remove(s.PersonaFile()); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return os.WriteFile(s.PersonaFile(), []byte(p.Render()), 0644)
+}
@@ -0,0 +1,424 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultPgTable is the table PgIndex keeps memory chunks in.
+	DefaultPgTable = "littleclaw_memory"
+	// pgChunkChars is the most characters embedded as one chunk.
+	pgChunkChars = 1500
+	// pgEmbedBatch is how many chunks go to the embedder per call.
+	pgEmbedBatch = 64
+	// pgTimeout bounds each psql invocation.
+	pgTimeout = 2 * time.Minute
+)
+
+// pgIdentifier is what a table name may look like; it is spliced into SQL.
+var pgIdentifier = regexp.MustCompile(`^[a-z_][a-z0-9_]{0,62}$`)
+
+// EmbedFunc turns texts into embedding vectors, one per text, in order.
+type EmbedFunc func(ctx context.Context, texts []string) ([][]float32, error)
+
+// SemanticMatch is a chunk of memory found by meaning.
+type SemanticMatch struct {
+	Source  string  `json:"source"` // e.g. "entities/alice", "history/2026-03-14"
+	Content string  `json:"content"`
+	Score   float64 `json:"score"` // cosine similarity, 1 is identical
+}
+
+// PgIndex keeps a copy of memory in Postgres with pgvector, so it can be
+// searched by meaning at a scale flat files can't. It is an index, not a
+// memory backend: Store keeps reading and writing the Markdown files, which
+// stay the source of truth. Sync brings the table up to date with them, each
+// document replaced in one transaction, and embeds only chunks it has not
+// seen. Statements run through psql, like sql_query, so no driver is linked
+// into the binary.
+type PgIndex struct {
+	DSN   string
+	Table string
+	Embed EmbedFunc
+
+	mu    sync.Mutex  // one Sync at a time
+	ready atomic.Bool // the table exists
+}
+
+// NewPgIndex returns an index in table (DefaultPgTable if empty) of the
+// database at dsn, a postgres:// URL. psql must be on the PATH.
+func NewPgIndex(dsn, table string, embed EmbedFunc) (*PgIndex, error) {
+	if !strings.HasPrefix(dsn, "postgres://") && !strings.HasPrefix(dsn, "postgresql://") {
+		return nil, fmt.Errorf("dsn must be a postgres:// URL")
+	}
+	if table == "" {
+		table = DefaultPgTable
+	}
+	if !pgIdentifier.MatchString(table) {
+		return nil, fmt.Errorf("invalid table name %q (lowercase letters, digits, and _)", table)
+	}
+	if embed == nil {
+		return nil, fmt.Errorf("an embedder is required")
+	}
+	if _, err := exec.LookPath("psql"); err != nil {
+		return nil, fmt.Errorf("psql is required for the postgres memory index: %w", err)
+	}
+	return &PgIndex{DSN: dsn, Table: table, Embed: embed}, nil
+}
+
+// Run syncs the index with s now and then every interval until ctx ends.
+func (x *PgIndex) Run(ctx context.Context, s *Store, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		if err := x.Sync(ctx, s); err != nil && ctx.Err() == nil {
+			slog.Warn("memory index sync failed", "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync indexes MEMORY.md, entities, daily logs, and summaries that changed
+// since the last sync, and drops documents that no longer exist.
+func (x *PgIndex) Sync(ctx context.Context, s *Store) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	indexed, err := x.chunkHashes(ctx)
+	if err != nil {
+		return err
+	}
+	docs := s.documents()
+	sources := make([]string, 0, len(docs))
+	for source := range docs {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	changed := 0
+	for _, source := range sources {
+		chunks := ChunkText(docs[source], pgChunkChars)
+		hashes := make([]string, len(chunks))
+		for i, c := range chunks {
+			hashes[i] = chunkHash(c)
+		}
+		if slices.Equal(hashes, indexed[source]) {
+			continue
+		}
+		if err := x.replace(ctx, source, chunks, hashes, indexed[source]); err != nil {
+			return fmt.Errorf("indexing %s: %w", source, err)
+		}
+		changed++
+	}
+
+	var gone []string
+	for source := range indexed {
+		if _, ok := docs[source]; !ok {
+			gone = append(gone, pgString(source))
+		}
+	}
+	if len(gone) > 0 {
+		if _, err := x.psql(ctx, fmt.Sprintf("DELETE FROM %s WHERE source IN (%s);", x.Table, strings.Join(gone, ", "))); err != nil {
+			return fmt.Errorf("removing deleted documents: %w", err)
+		}
+	}
+	if changed > 0 || len(gone) > 0 {
+		slog.Info("memory index synced", "updated", changed, "removed", len(gone))
+	}
+	return nil
+}
+
+// Search returns the k chunks closest in meaning to query, best first.
+func (x *PgIndex) Search(ctx context.Context, query string, k int) ([]SemanticMatch, error) {
+	if ready, err := x.tableExists(ctx); err != nil || !ready {
+		return nil, err
+	}
+	vectors, err := x.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("embedding the query: %w", err)
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("got %d embeddings for the query", len(vectors))
+	}
+	vec := pgVector(vectors[0])
+	out, err := x.psql(ctx, fmt.Sprintf(
+		"SELECT coalesce(json_agg(r), '[]') FROM (SELECT source, content, 1 - (embedding <=> %s) AS score FROM %s ORDER BY embedding <=> %s LIMIT %d) r;",
+		vec, x.Table, vec, k))
+	if err != nil {
+		return nil, err
+	}
+	var matches []SemanticMatch
+	if err := json.Unmarshal([]byte(out), &matches); err != nil {
+		return nil, fmt.Errorf("decoding search results: %w", err)
+	}
+	return matches, nil
+}
+
+// chunkHashes returns the hashes of each indexed document's chunks, in order.
+func (x *PgIndex) chunkHashes(ctx context.Context) (map[string][]string, error) {
+	hashes := make(map[string][]string)
+	if ready, err := x.tableExists(ctx); err != nil || !ready {
+		return hashes, err
+	}
+	out, err := x.psql(ctx, fmt.Sprintf(
+		"SELECT coalesce(json_object_agg(source, hashes), '{}') FROM (SELECT source, json_agg(chunk_hash ORDER BY chunk) AS hashes FROM %s GROUP BY source) s;",
+		x.Table))
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(out), &hashes); err != nil {
+		return nil, fmt.Errorf("decoding indexed documents: %w", err)
+	}
+	return hashes, nil
+}
+
+// tableExists reports whether the index table has been created.
+func (x *PgIndex) tableExists(ctx context.Context) (bool, error) {
+	if x.ready.Load() {
+		return true, nil
+	}
+	out, err := x.psql(ctx, fmt.Sprintf("SELECT to_regclass(%s) IS NOT NULL;", pgString(x.Table)))
+	if err != nil {
+		return false, err
+	}
+	x.ready.Store(out == "t")
+	return out == "t", nil
+}
+
+// replace swaps the indexed chunks of source for chunks in one transaction,
+// embedding only chunks whose hash was not indexed before.
+func (x *PgIndex) replace(ctx context.Context, source string, chunks, hashes, old []string) error {
+	known := make(map[string]bool, len(old))
+	for _, h := range old {
+		known[h] = true
+	}
+	var fresh []string
+	for i, c := range chunks {
+		if !known[hashes[i]] {
+			fresh = append(fresh, c)
+		}
+	}
+	vectors, err := x.embedAll(ctx, fresh)
+	if err != nil {
+		return err
+	}
+
+	var sql strings.Builder
+	if !x.ready.Load() {
+		if len(vectors) == 0 {
+			return fmt.Errorf("nothing to embed")
+		}
+		fmt.Fprintf(&sql, `CREATE EXTENSION IF NOT EXISTS vector;
+CREATE TABLE IF NOT EXISTS %[1]s (
+	source     text NOT NULL,
+	chunk      int NOT NULL,
+	content    text NOT NULL,
+	chunk_hash text NOT NULL,
+	embedding  vector(%[2]d) NOT NULL,
+	updated_at timestamptz NOT NULL DEFAULT now(),
+	PRIMARY KEY (source, chunk)
+);
+CREATE INDEX IF NOT EXISTS %[1]s_embedding_idx ON %[1]s USING hnsw (embedding vector_cosine_ops);
+`, x.Table, len(vectors[0]))
+	}
+	// Chunks seen before keep their embedding, copied before the delete
+	fmt.Fprintf(&sql, "CREATE TEMP TABLE prev ON COMMIT DROP AS SELECT DISTINCT ON (chunk_hash) chunk_hash, embedding FROM %s WHERE source = %s;\n", x.Table, pgString(source))
+	fmt.Fprintf(&sql, "DELETE FROM %s WHERE source = %s;\n", x.Table, pgString(source))
+	if len(chunks) > 0 {
+		fmt.Fprintf(&sql, "INSERT INTO %s (source, chunk, content, chunk_hash, embedding) VALUES\n", x.Table)
+		next := 0
+		for i, c := range chunks {
+			embedding := fmt.Sprintf("(SELECT embedding FROM prev WHERE chunk_hash = %s)", pgString(hashes[i]))
+			if !known[hashes[i]] {
+				embedding = pgVector(vectors[next])
+				next++
+			}
+			if i > 0 {
+				sql.WriteString(",\n")
+			}
+			fmt.Fprintf(&sql, "(%s, %d, %s, %s, %s)", pgString(source), i, pgString(c), pgString(hashes[i]), embedding)
+		}
+		sql.WriteString(";\n")
+	}
+	if _, err := x.psql(ctx, sql.String()); err != nil {
+		return err
+	}
+	x.ready.Store(true)
+	return nil
+}
+
+// embedAll embeds texts in batches.
+func (x *PgIndex) embedAll(ctx context.Context, texts []string) ([][]float32, error) {
+	var vectors [][]float32
+	for start := 0; start < len(texts); start += pgEmbedBatch {
+		batch := texts[start:min(start+pgEmbedBatch, len(texts))]
+		v, err := x.Embed(ctx, batch)
+		if err != nil {
+			return nil, fmt.Errorf("embedding: %w", err)
+		}
+		if len(v) != len(batch) {
+			return nil, fmt.Errorf("embedding: got %d vectors for %d texts", len(v), len(batch))
+		}
+		vectors = append(vectors, v...)
+	}
+	return vectors, nil
+}
+
+// psql runs script in one transaction and returns its unaligned output.
+func (x *PgIndex) psql(ctx context.Context, script string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, pgTimeout)
+	defer cancel()
+	dsn, env := pgConn(x.DSN)
+	cmd := exec.CommandContext(ctx, "psql", dsn, "-X", "-q", "-t", "-A", "-v", "ON_ERROR_STOP=1", "--single-transaction", "-f", "-")
+	// Password via env so it never shows up in the process list
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin = strings.NewReader(script)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("psql: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// pgConn moves the password out of a postgres:// URL and into PGPASSWORD.
+func pgConn(dsn string) (string, []string) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return dsn, nil
+	}
+	var env []string
+	if u.User != nil {
+		if pass, ok := u.User.Password(); ok {
+			env = append(env, "PGPASSWORD="+pass)
+			u.User = url.User(u.User.Username())
+		}
+	}
+	if q := u.Query(); q.Has("password") {
+		env = append(env, "PGPASSWORD="+q.Get("password"))
+		q.Del("password")
+		u.RawQuery = q.Encode()
+	}
+	return u.String(), env
+}
+
+// documents returns the memory to index by source: MEMORY.md, entities,
+// daily logs, and summaries. Empty documents are left out.
+func (s *Store) documents() map[string]string {
+	docs := make(map[string]string)
+	add := func(source, content string) {
+		if strings.TrimSpace(content) != "" {
+			docs[source] = content
+		}
+	}
+	add("MEMORY.md", s.ReadLongTerm())
+	if names, err := s.ListEntities(); err == nil {
+		for _, name := range names {
+			add("entities/"+name, s.ReadEntity(name))
+		}
+	}
+	dates := s.ListDailyLogs()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, date := range dates {
+		if data, err := os.ReadFile(filepath.Join(s.memoryDir, date+".md")); err == nil {
+			add("history/"+date, string(data))
+		}
+	}
+	if entries, err := os.ReadDir(s.summariesDir); err == nil {
+		for _, e := range entries {
+			if e.IsDir() || !strings.HasSuffix(e.Name(), ".md") {
+				continue
+			}
+			if data, err := os.ReadFile(filepath.Join(s.summariesDir, e.Name())); err == nil {
+				add("summaries/"+strings.TrimSuffix(e.Name(), ".md"), string(data))
+			}
+		}
+	}
+	return docs
+}
+
+// ChunkText splits s into pieces of at most max characters for embedding,
+// at paragraph breaks where it can, then line breaks, then anywhere. Pieces
+// fill up from the start, so text appended to a log leaves earlier pieces
+// as they were.
+func ChunkText(s string, max int) []string {
+	var chunks []string
+	var cur strings.Builder
+	flush := func() {
+		if t := strings.TrimSpace(cur.String()); t != "" {
+			chunks = append(chunks, t)
+		}
+		cur.Reset()
+	}
+	add := func(piece, sep string) {
+		if cur.Len() > 0 && utf8.RuneCountInString(cur.String())+utf8.RuneCountInString(sep+piece) > max {
+			flush()
+		}
+		if cur.Len() > 0 {
+			cur.WriteString(sep)
+		}
+		cur.WriteString(piece)
+	}
+	for _, para := range strings.Split(s, "\n\n") {
+		if utf8.RuneCountInString(para) <= max {
+			add(para, "\n\n")
+			continue
+		}
+		for _, line := range strings.Split(para, "\n") {
+			for utf8.RuneCountInString(line) > max {
+				cut := len(string([]rune(line)[:max]))
+				add(line[:cut], "\n")
+				line = line[cut:]
+			}
+			add(line, "\n")
+		}
+	}
+	flush()
+	return chunks
+}
+
+func chunkHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:16])
+}
+
+// pgString quotes s as a Postgres string literal.
+func pgString(s string) string {
+	s = strings.ReplaceAll(s, "\x00", "")
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "E'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// pgVector formats v as a pgvector literal.
+func pgVector(v []float32) string {
+	parts := make([]string, len(v))
+	for i, f := range v {
+		parts[i] = strconv.FormatFloat(float64(f), 'g', -1, 32)
+	}
+	return "'[" + strings.Join(parts, ",") + "]'::vector"
+}
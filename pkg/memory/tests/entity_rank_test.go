@@ -0,0 +1,52 @@
+package memory_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRankEntities_MatchesContent(t *testing.T) {
+	store := newTestStore(t)
+	_ = store.WriteEntity("Project Phoenix", "Migration of the billing system to Postgres. Deadline is March.")
+	_ = store.WriteEntity("Bob Jones", "Bob plays guitar.")
+
+	matches := store.RankEntities("how is the postgres migration going?", 3)
+	if len(matches) != 1 || matches[0].Name != "project_phoenix" {
+		t.Fatalf("expected the entity whose content matches, got %+v", matches)
+	}
+
+	// A name mention ranks above a content mention
+	_ = store.WriteEntity("Postgres", "Database we use for billing.")
+	matches = store.RankEntities("how is the postgres migration going?", 3)
+	if len(matches) != 2 || matches[0].Name != "postgres" {
+		t.Errorf("expected the named entity first, got %+v", matches)
+	}
+}
+
+func TestRankEntities_TopK(t *testing.T) {
+	store := newTestStore(t)
+	for i := 0; i < 6; i++ {
+		_ = store.WriteEntity(fmt.Sprintf("garden bed %d", i), "Tomatoes and basil.")
+	}
+	if matches := store.RankEntities("what should I plant with tomatoes?", 2); len(matches) != 2 {
+		t.Errorf("expected the top 2 entities, got %+v", matches)
+	}
+	if matches := store.RankEntities("tomatoes", 0); matches != nil {
+		t.Errorf("k=0 should surface nothing, got %+v", matches)
+	}
+}
+
+func TestRelevantEntities_Summarizes(t *testing.T) {
+	store := newTestStore(t)
+	long := "Alice is a scientist.\n" + strings.Repeat("She works on protein folding.\n", 100) + "END-MARKER"
+	_ = store.WriteEntity("Alice Smith", long)
+
+	ctx := store.RelevantEntities("what does Alice do?", 3, 5000)
+	if !strings.Contains(ctx, "Alice is a scientist.") || strings.Contains(ctx, "END-MARKER") || !strings.Contains(ctx, "read_entity") {
+		t.Errorf("expected the start of the entity with a pointer to read_entity, got %q", ctx)
+	}
+	if len(ctx) > 1000 {
+		t.Errorf("the summary should be short, got %d bytes", len(ctx))
+	}
+}
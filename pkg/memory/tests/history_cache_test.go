@@ -0,0 +1,74 @@
+package memory_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadRecentHistory_ServedFromMemory(t *testing.T) {
+	store := newTestStore(t)
+	_ = store.AppendHistory("user", "first-entry")
+	if h := store.ReadRecentHistory(16000); !strings.Contains(h, "first-entry") {
+		t.Fatalf("expected the first entry, got: %s", h)
+	}
+
+	// Once loaded, the log is not read again for the rest of the day
+	if err := os.WriteFile(store.DailyLogPath(time.Now()), []byte("[ts] USER: rewritten-on-disk\n\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_ = store.AppendHistory("assistant", "second-entry")
+
+	h := store.ReadRecentHistory(16000)
+	if !strings.Contains(h, "first-entry") || !strings.Contains(h, "second-entry") || strings.Contains(h, "rewritten-on-disk") {
+		t.Errorf("expected the cached entries plus the new one, got: %s", h)
+	}
+	if data, _ := os.ReadFile(store.DailyLogPath(time.Now())); !strings.Contains(string(data), "second-entry") {
+		t.Error("appended entries must still be written to the daily log")
+	}
+}
+
+func TestReadRecentHistory_CacheFollowsResetAndSummary(t *testing.T) {
+	store := newTestStore(t)
+	yesterday := time.Now().AddDate(0, 0, -1)
+	_ = os.WriteFile(store.DailyLogPath(yesterday), []byte("[ts] USER: long-yesterday-log\n"), 0644)
+	if h := store.ReadRecentHistory(16000); !strings.Contains(h, "long-yesterday-log") {
+		t.Fatalf("expected yesterday's log, got: %s", h)
+	}
+
+	if err := store.WriteSummary(yesterday.Format("2006-01-02"), "yesterday-summary"); err != nil {
+		t.Fatal(err)
+	}
+	if h := store.ReadRecentHistory(16000); !strings.Contains(h, "yesterday-summary") || strings.Contains(h, "long-yesterday-log") {
+		t.Errorf("expected the new summary in place of the log, got: %s", h)
+	}
+
+	_ = store.AppendHistory("user", "before-reset")
+	_ = store.ReadRecentHistory(16000)
+	if err := store.ResetRecentHistory(); err != nil {
+		t.Fatal(err)
+	}
+	_ = store.AppendHistory("user", "after-reset")
+	h := store.ReadRecentHistory(16000)
+	if strings.Contains(h, "before-reset") || strings.Contains(h, "yesterday-summary") || !strings.Contains(h, "after-reset") {
+		t.Errorf("expected only entries after the reset, got: %s", h)
+	}
+}
+
+func TestReadRecentHistory_CacheKeepsWholeEntries(t *testing.T) {
+	store := newTestStore(t)
+	_ = store.ReadRecentHistory(16000)
+	for i := 0; i < 100; i++ {
+		_ = store.AppendHistory("user", strings.Repeat("x", 1000))
+	}
+
+	h := store.ReadRecentHistory(1 << 20)
+	if len(h) > 70*1024 {
+		t.Errorf("the cache should cap today's history, got %d bytes", len(h))
+	}
+	today := h[strings.Index(h, "(today) ---\n")+len("(today) ---\n"):]
+	if !strings.HasPrefix(today, "[") {
+		t.Errorf("the cache should drop whole entries, got %q", today[:40])
+	}
+}
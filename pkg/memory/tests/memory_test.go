@@ -761,3 +761,22 @@ func TestSplitHistoryEntries_EmptyInput(t *testing.T) {
 		}
 	}
 }
+
+func TestResetRecentHistory_StartsFreshWindow(t *testing.T) {
+	store := newTestStore(t)
+	_ = os.WriteFile(store.DailyLogPath(time.Now().AddDate(0, 0, -1)), []byte("[ts] USER: yesterday-marker\n"), 0644)
+	_ = store.AppendHistory("user", "before-reset")
+
+	if err := store.ResetRecentHistory(); err != nil {
+		t.Fatalf("ResetRecentHistory() error = %v", err)
+	}
+	_ = store.AppendHistory("user", "after-reset")
+
+	history := store.ReadRecentHistory(16000)
+	if strings.Contains(history, "before-reset") || strings.Contains(history, "yesterday-marker") || !strings.Contains(history, "after-reset") {
+		t.Errorf("expected only entries after the reset, got: %s", history)
+	}
+	if results := store.SearchHistory("before-reset", "", ""); len(results) == 0 {
+		t.Error("the daily log should still be searchable after a reset")
+	}
+}
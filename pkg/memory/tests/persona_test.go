@@ -0,0 +1,40 @@
+package memory_test
+
+import (
+	"os"
+	"testing"
+
+	"littleclaw/pkg/memory"
+)
+
+func TestPersona_RoundTrip(t *testing.T) {
+	store := newTestStore(t)
+	if !store.ReadPersona().IsZero() {
+		t.Fatal("expected no persona before SYSTEM.md exists")
+	}
+
+	want := memory.Persona{Name: "Jarvis", Tone: "Dry wit.", Language: "German", Instructions: "Prices in EUR.\nNo emoji."}
+	if err := store.WritePersona(want); err != nil {
+		t.Fatalf("WritePersona() error = %v", err)
+	}
+	if got := store.ReadPersona(); got != want {
+		t.Errorf("ReadPersona() = %+v, want %+v", got, want)
+	}
+
+	if err := store.WritePersona(memory.Persona{}); err != nil {
+		t.Fatalf("WritePersona(zero) error = %v", err)
+	}
+	if _, err := os.Stat(store.PersonaFile()); !os.IsNotExist(err) {
+		t.Error("writing a zero persona should remove SYSTEM.md")
+	}
+}
+
+func TestParsePersona_FreeText(t *testing.T) {
+	p := memory.ParsePersona("Always answer like a pirate.\n- Name: Polly\n")
+	if p.Name != "Polly" || p.Instructions != "Always answer like a pirate." {
+		t.Errorf("ParsePersona() = %+v", p)
+	}
+	if d := p.WithDefaults(); d.Name != "Polly" || d.Tone != memory.DefaultPersonaTone {
+		t.Errorf("WithDefaults() = %+v", d)
+	}
+}
@@ -0,0 +1,182 @@
+package memory_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"littleclaw/pkg/memory"
+)
+
+func TestChunkText(t *testing.T) {
+	text := "first paragraph\n\nsecond paragraph\n\n" + strings.Repeat("x", 25) + "\nshort line"
+	chunks := memory.ChunkText(text, 20)
+	want := []string{"first paragraph", "second paragraph", strings.Repeat("x", 20), "xxxxx\nshort line"}
+	if !reflect.DeepEqual(chunks, want) {
+		t.Errorf("ChunkText = %q, want %q", chunks, want)
+	}
+	for _, c := range memory.ChunkText(strings.Repeat("héllo wörld\n", 50), 100) {
+		if n := utf8.RuneCountInString(c); n > 100 || !utf8.ValidString(c) {
+			t.Errorf("chunk of %d runes (valid UTF-8: %v)", n, utf8.ValidString(c))
+		}
+	}
+}
+
+func TestChunkText_AppendingKeepsEarlierChunks(t *testing.T) {
+	var log strings.Builder
+	for i := 0; i < 40; i++ {
+		log.WriteString("[10:00] USER: what's the weather like today?\n\n")
+	}
+	before := memory.ChunkText(log.String(), 300)
+	log.WriteString("[10:05] ASSISTANT: sunny\n\n")
+	after := memory.ChunkText(log.String(), 300)
+	if !reflect.DeepEqual(before[:len(before)-1], after[:len(before)-1]) {
+		t.Error("appending to a log changed its earlier chunks")
+	}
+}
+
+func TestNewPgIndex_Validation(t *testing.T) {
+	embed := func(ctx context.Context, texts []string) ([][]float32, error) { return nil, nil }
+	if _, err := memory.NewPgIndex("host=localhost dbname=x", "", embed); err == nil {
+		t.Error("expected an error for a non-URL dsn")
+	}
+	if _, err := memory.NewPgIndex("postgres://localhost/x", "memory; DROP TABLE x", embed); err == nil {
+		t.Error("expected an error for an unsafe table name")
+	}
+}
+
+// fakePsql puts a psql on PATH that logs each script it is given to
+// scripts.log, its arguments to args.log, and its PGPASSWORD to password,
+// and answers the table check from the file "exists" and the indexed
+// documents query from "indexed". It returns the directory.
+func fakePsql(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	fake := `#!/bin/sh
+dir="$(dirname "$0")"
+script="$(cat)"
+printf '%s\n---\n' "$script" >> "$dir/scripts.log"
+printf '%s\n' "$*" >> "$dir/args.log"
+printf '%s' "$PGPASSWORD" > "$dir/password"
+case "$script" in
+*to_regclass*) cat "$dir/exists" ;;
+*json_object_agg*) cat "$dir/indexed" ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(dir, "psql"), []byte(fake), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{"exists": "f", "indexed": "{}"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return dir
+}
+
+func TestPgIndex_SyncWritesChangedDocuments(t *testing.T) {
+	dir := fakePsql(t)
+	var embedded []string
+	embed := func(ctx context.Context, texts []string) ([][]float32, error) {
+		embedded = append(embedded, texts...)
+		vectors := make([][]float32, len(texts))
+		for i := range texts {
+			vectors[i] = []float32{0.5, -1, 2}
+		}
+		return vectors, nil
+	}
+	ix, err := memory.NewPgIndex("postgres://u:p@localhost/db", "", embed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := newTestStore(t)
+	if err := store.WriteEntity("bob", "Bob O'Brien prefers trains.\nPath: C:\\trains"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ix.Sync(context.Background(), store); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	log, _ := os.ReadFile(filepath.Join(dir, "scripts.log"))
+	for _, want := range []string{
+		"CREATE EXTENSION IF NOT EXISTS vector",
+		"embedding  vector(3) NOT NULL",
+		"DELETE FROM littleclaw_memory WHERE source = E'entities/bob'",
+		"E'Bob O''Brien prefers trains.\nPath: C:\\\\trains'",
+		"'[0.5,-1,2]'::vector",
+	} {
+		if !strings.Contains(string(log), want) {
+			t.Errorf("psql scripts missing %q:\n%s", want, log)
+		}
+	}
+	if len(embedded) == 0 {
+		t.Fatal("nothing was embedded")
+	}
+
+	// The password goes through the environment, not the process list
+	args, _ := os.ReadFile(filepath.Join(dir, "args.log"))
+	if strings.Contains(string(args), "u:p@") || !strings.Contains(string(args), "postgres://u@localhost/db") {
+		t.Errorf("psql arguments = %q, want the DSN without its password", args)
+	}
+	if pw, _ := os.ReadFile(filepath.Join(dir, "password")); string(pw) != "p" {
+		t.Errorf("PGPASSWORD = %q, want p", pw)
+	}
+}
+
+// insertedRow matches a row PgIndex inserts: source, chunk, content, and
+// chunk hash.
+var insertedRow = regexp.MustCompile(`\(E'([^']*)', (\d+), E'(?:[^']|'')*', E'([0-9a-f]{32})'`)
+
+func TestPgIndex_SyncSkipsUnchangedAndRemovesDeleted(t *testing.T) {
+	dir := fakePsql(t)
+	calls := 0
+	embed := func(ctx context.Context, texts []string) ([][]float32, error) {
+		calls++
+		return make([][]float32, len(texts)), nil
+	}
+	ix, err := memory.NewPgIndex("postgres://localhost/db", "", embed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := newTestStore(t)
+	if err := store.WriteEntity("alice", "Alice likes hiking."); err != nil {
+		t.Fatal(err)
+	}
+	if err := ix.Sync(context.Background(), store); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+
+	// Answer the next sync with what the first one wrote, plus a document
+	// that has since been deleted
+	log, _ := os.ReadFile(filepath.Join(dir, "scripts.log"))
+	indexed := map[string][]string{"entities/gone": {"0123456789abcdef0123456789abcdef"}}
+	for _, m := range insertedRow.FindAllStringSubmatch(string(log), -1) {
+		indexed[m[1]] = append(indexed[m[1]], m[3])
+	}
+	if len(indexed) < 2 {
+		t.Fatalf("no rows found in the psql scripts:\n%s", log)
+	}
+	data, _ := json.Marshal(indexed)
+	os.WriteFile(filepath.Join(dir, "indexed"), data, 0644)
+	os.WriteFile(filepath.Join(dir, "exists"), []byte("t"), 0644)
+	os.Remove(filepath.Join(dir, "scripts.log"))
+
+	calls = 0
+	if err := ix.Sync(context.Background(), store); err != nil {
+		t.Fatalf("second Sync: %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("unchanged documents were embedded again (%d calls)", calls)
+	}
+	log, _ = os.ReadFile(filepath.Join(dir, "scripts.log"))
+	if !strings.Contains(string(log), "DELETE FROM littleclaw_memory WHERE source IN (E'entities/gone')") || strings.Contains(string(log), "INSERT INTO") {
+		t.Errorf("expected only the deleted document to be removed:\n%s", log)
+	}
+}
@@ -0,0 +1,66 @@
+package memory_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMemoryVersions_ListAndRestore(t *testing.T) {
+	store := newTestStore(t)
+	for name, content := range map[string]string{
+		"MEMORY_20260101_090000.md": "- old fact\n",
+		"MEMORY_20260102_090000.md": "- newer fact\n",
+		"MEMORY_notes.md":           "not a version",
+	} {
+		if err := os.WriteFile(filepath.Join(store.MemoryDir(), name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(store.MemoryFile(), []byte("- current fact\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	versions := store.ListMemoryVersions()
+	if len(versions) != 2 || versions[0] != "20260102_090000" || versions[1] != "20260101_090000" {
+		t.Fatalf("ListMemoryVersions() = %v, want newest first", versions)
+	}
+
+	if err := store.RestoreMemoryVersion("20260101_090000"); err != nil {
+		t.Fatalf("RestoreMemoryVersion() error = %v", err)
+	}
+	if got := store.ReadLongTerm(); got != "- old fact\n" {
+		t.Errorf("MEMORY.md = %q after restore", got)
+	}
+	if got := store.ListMemoryVersions(); len(got) != 3 {
+		t.Errorf("expected the replaced content to be kept as a version, got %v", got)
+	}
+
+	for _, bad := range []string{"../../etc/passwd", "20990101_000000"} {
+		if err := store.RestoreMemoryVersion(bad); err == nil {
+			t.Errorf("RestoreMemoryVersion(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestSearchEntities(t *testing.T) {
+	store := newTestStore(t)
+	store.WriteEntity("Alice Smith", "# Alice\nWorks at Acme.\nLikes green tea.")
+	store.WriteEntity("Project Tea Party", "Planning notes.")
+	store.WriteEntity("Bob", "Coffee only.")
+
+	results := store.SearchEntities("TEA")
+	if len(results) != 2 {
+		t.Fatalf("SearchEntities() = %+v, want 2 matches", results)
+	}
+	lines := map[string]string{}
+	for _, r := range results {
+		lines[r.Name] = r.Line
+	}
+	if lines["alice_smith"] != "Likes green tea." {
+		t.Errorf("alice_smith line = %q", lines["alice_smith"])
+	}
+	if line, ok := lines["project_tea_party"]; !ok || line != "" {
+		t.Errorf("expected a name-only match for project_tea_party, got %q (found %v)", line, ok)
+	}
+}
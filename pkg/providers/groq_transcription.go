@@ -10,11 +10,15 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+
+	"littleclaw/pkg/httpclient"
 )
 
 // GroqTranscriptionProvider implements TranscriptionProvider for Groq's Whisper API.
 type GroqTranscriptionProvider struct {
 	APIKey     string
+	Model      string
+	Language   string // optional ISO-639-1 hint; empty auto-detects
 	HTTPClient *http.Client
 }
 
@@ -22,7 +26,8 @@ type GroqTranscriptionProvider struct {
 func NewGroqTranscriptionProvider(apiKey string) *GroqTranscriptionProvider {
 	return &GroqTranscriptionProvider{
 		APIKey:     apiKey,
-		HTTPClient: &http.Client{},
+		Model:      "whisper-large-v3",
+		HTTPClient: httpclient.New(transcriptionTimeout),
 	}
 }
 
@@ -48,8 +53,11 @@ func (p *GroqTranscriptionProvider) Transcribe(ctx context.Context, audioPath st
 		return "", fmt.Errorf("failed to copy file to form: %w", err)
 	}
 
-	_ = writer.WriteField("model", "whisper-large-v3")
+	_ = writer.WriteField("model", p.Model)
 	_ = writer.WriteField("response_format", "json")
+	if p.Language != "" {
+		_ = writer.WriteField("language", p.Language)
+	}
 	
 	if err := writer.Close(); err != nil {
 		return "", fmt.Errorf("failed to close multipart writer: %w", err)
@@ -0,0 +1,380 @@
+package providers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"littleclaw/pkg/httpclient"
+	"littleclaw/pkg/telemetry"
+)
+
+// DefaultOllamaURL is where a local Ollama server listens.
+const DefaultOllamaURL = "http://localhost:11434"
+
+// ollamaMaxImageBytes bounds an image fetched to send to the model.
+const ollamaMaxImageBytes = 10 << 20
+
+// OllamaProvider talks to Ollama's native API (/api/chat) rather than its
+// OpenAI-compatible shim, which ignores keep_alive and cannot list or pull
+// models.
+type OllamaProvider struct {
+	BaseURL string // e.g. "http://localhost:11434", without /api
+	// KeepAlive is how long Ollama keeps the model loaded after a request,
+	// as a duration ("10m") or "-1" for ever; empty uses the server default
+	// of five minutes.
+	KeepAlive  string
+	HTTPClient *http.Client
+}
+
+// NewOllamaProvider creates a provider for the Ollama server at baseURL, or
+// DefaultOllamaURL when empty. A trailing /v1 (the OpenAI shim) is dropped.
+func NewOllamaProvider(baseURL, keepAlive string) *OllamaProvider {
+	if baseURL == "" {
+		baseURL = DefaultOllamaURL
+	}
+	return &OllamaProvider{
+		BaseURL:    strings.TrimSuffix(strings.TrimRight(baseURL, "/"), "/v1"),
+		KeepAlive:  keepAlive,
+		HTTPClient: httpclient.New(3 * time.Minute),
+	}
+}
+
+func (p *OllamaProvider) Name() string {
+	return "ollama"
+}
+
+type ollamaRequest struct {
+	Model     string                 `json:"model"`
+	Messages  []ollamaMessage        `json:"messages"`
+	Tools     []ToolDefinition       `json:"tools,omitempty"`
+	Stream    bool                   `json:"stream"`
+	KeepAlive interface{}            `json:"keep_alive,omitempty"` // string duration or number of seconds
+	Options   map[string]interface{} `json:"options,omitempty"`
+}
+
+type ollamaMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	Images    []string         `json:"images,omitempty"` // base64, no data: prefix
+	ToolCalls []ollamaToolCall `json:"tool_calls,omitempty"`
+	ToolName  string           `json:"tool_name,omitempty"` // on tool results
+}
+
+type ollamaToolCall struct {
+	Function struct {
+		Name      string                 `json:"name"`
+		Arguments map[string]interface{} `json:"arguments"` // an object, not a JSON string as in OpenAI's API
+	} `json:"function"`
+}
+
+type ollamaResponse struct {
+	Message         ollamaMessage `json:"message"`
+	DoneReason      string        `json:"done_reason"`
+	PromptEvalCount int           `json:"prompt_eval_count"`
+	EvalCount       int           `json:"eval_count"`
+}
+
+func (p *OllamaProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	ctx, span := telemetry.Start(ctx, "chat "+req.Model, "gen_ai.system", "ollama", "gen_ai.request.model", req.Model,
+		"gen_ai.request.messages", len(req.Messages), "gen_ai.request.tools", len(req.Tools))
+	defer span.End()
+
+	resp, err := p.chat(ctx, req)
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+	span.SetAttributes("gen_ai.usage.input_tokens", resp.Usage.PromptTokens, "gen_ai.usage.output_tokens", resp.Usage.CompletionTokens,
+		"gen_ai.response.finish_reason", resp.FinishReason, "gen_ai.response.tool_calls", len(resp.ToolCalls))
+	return resp, nil
+}
+
+// chat sends one non-streaming /api/chat request.
+func (p *OllamaProvider) chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	messages, err := p.ollamaMessages(ctx, req.Messages)
+	if err != nil {
+		return nil, err
+	}
+	apiReq := ollamaRequest{Model: req.Model, Messages: messages, Tools: req.Tools, KeepAlive: keepAlive(p.KeepAlive)}
+	if req.Temperature != 0 || req.MaxTokens != 0 {
+		apiReq.Options = map[string]interface{}{}
+		if req.Temperature != 0 {
+			apiReq.Options["temperature"] = req.Temperature
+		}
+		if req.MaxTokens != 0 {
+			apiReq.Options["num_predict"] = req.MaxTokens
+		}
+	}
+
+	var apiResp ollamaResponse
+	if err := p.post(ctx, "/api/chat", apiReq, &apiResp); err != nil {
+		return nil, err
+	}
+
+	resp := &ChatResponse{
+		Content:      apiResp.Message.Content,
+		FinishReason: apiResp.DoneReason,
+		Usage: Usage{
+			PromptTokens:     apiResp.PromptEvalCount,
+			CompletionTokens: apiResp.EvalCount,
+			TotalTokens:      apiResp.PromptEvalCount + apiResp.EvalCount,
+		},
+	}
+	// Ollama gives tool calls no IDs; the agent needs unique ones to pair
+	// results with calls
+	stamp := time.Now().UnixNano()
+	for i, tc := range apiResp.Message.ToolCalls {
+		args, err := json.Marshal(tc.Function.Arguments)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode tool arguments: %w", err)
+		}
+		resp.ToolCalls = append(resp.ToolCalls, map[string]interface{}{
+			"id":   fmt.Sprintf("call_%x_%d", stamp, i),
+			"type": "function",
+			"function": map[string]interface{}{
+				"name":      tc.Function.Name,
+				"arguments": string(args),
+			},
+		})
+	}
+	if len(resp.ToolCalls) > 0 {
+		resp.FinishReason = "tool_calls"
+	}
+	return resp, nil
+}
+
+// ollamaMessages converts messages to Ollama's shape: tool call arguments as
+// objects, tool results named after their call, and images inlined as
+// base64.
+func (p *OllamaProvider) ollamaMessages(ctx context.Context, msgs []Message) ([]ollamaMessage, error) {
+	out := make([]ollamaMessage, len(msgs))
+	callNames := map[string]string{}
+	for i, msg := range msgs {
+		m := ollamaMessage{Role: msg.Role, Content: msg.Content}
+		for _, tc := range msg.ToolCalls {
+			fn, _ := tc["function"].(map[string]interface{})
+			name, _ := fn["name"].(string)
+			var call ollamaToolCall
+			call.Function.Name = name
+			if raw, _ := fn["arguments"].(string); raw != "" {
+				// chat encoded them from Ollama's own object, so they parse
+				_ = json.Unmarshal([]byte(raw), &call.Function.Arguments)
+			}
+			if id, _ := tc["id"].(string); id != "" {
+				callNames[id] = name
+			}
+			m.ToolCalls = append(m.ToolCalls, call)
+		}
+		if msg.ToolCallID != "" {
+			m.ToolName = callNames[msg.ToolCallID]
+		}
+		for n, url := range msg.Media {
+			img, err := p.image(ctx, url)
+			if err != nil {
+				return nil, fmt.Errorf("image %d: %w", n+1, err)
+			}
+			m.Images = append(m.Images, img)
+		}
+		out[i] = m
+	}
+	return out, nil
+}
+
+// image returns the image at url, a data: or http(s) URL, as base64.
+func (p *OllamaProvider) image(ctx context.Context, url string) (string, error) {
+	if strings.HasPrefix(url, "data:") {
+		_, data, ok := strings.Cut(url, ";base64,")
+		if !ok {
+			return "", fmt.Errorf("not a base64 data URL")
+		}
+		return data, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL")
+	}
+	resp, err := p.HTTPClient.Do(req)
+	if err != nil {
+		// err is a *url.Error quoting the URL, and Telegram file URLs carry
+		// the bot token in their path; this error ends up in the chat
+		return "", fmt.Errorf("download failed")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("download failed (status %d)", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, ollamaMaxImageBytes+1))
+	if err != nil {
+		return "", fmt.Errorf("download failed")
+	}
+	if len(data) > ollamaMaxImageBytes {
+		return "", fmt.Errorf("image exceeds %d MB", ollamaMaxImageBytes>>20)
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// keepAlive sends a bare number of seconds as a number, which is what
+// Ollama expects for "-1" and "0"; durations go as strings.
+func keepAlive(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		return n
+	}
+	return s
+}
+
+// ollamaModel is a model installed on an Ollama server.
+type ollamaModel struct {
+	Name    string `json:"name"` // e.g. "llama3.2:latest"
+	Size    int64  `json:"size"` // bytes on disk
+	Details struct {
+		ParameterSize     string `json:"parameter_size"`     // e.g. "3.2B"
+		QuantizationLevel string `json:"quantization_level"` // e.g. "Q4_K_M"
+	} `json:"details"`
+}
+
+// ListModels returns the models installed on the server, described by size
+// and quantization.
+func (p *OllamaProvider) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.BaseURL+"/api/tags", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := p.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("http request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+	}
+	var list struct {
+		Models []ollamaModel `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	models := make([]ModelInfo, len(list.Models))
+	for i, m := range list.Models {
+		details := []string{m.Details.ParameterSize, m.Details.QuantizationLevel, fmt.Sprintf("%.1f GB", float64(m.Size)/(1<<30))}
+		models[i] = ModelInfo{ID: m.Name, Description: strings.Join(strings.Fields(strings.Join(details, " ")), " ")}
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
+	return models, nil
+}
+
+// PullProgress is one status update while a model downloads.
+type PullProgress struct {
+	Status    string `json:"status"` // e.g. "pulling manifest", "downloading", "success"
+	Completed int64  `json:"completed"`
+	Total     int64  `json:"total"`
+}
+
+// Pull downloads model to the server, calling progress (if not nil) with
+// each status update. It returns once the model is ready.
+func (p *OllamaProvider) Pull(ctx context.Context, model string, progress func(PullProgress)) error {
+	body, err := json.Marshal(map[string]interface{}{"model": model, "stream": true})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.BaseURL+"/api/pull", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// A pull can take far longer than a chat; only ctx bounds it
+	client := *p.HTTPClient
+	client.Timeout = 0
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("http request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("API error %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var update struct {
+			PullProgress
+			Error string `json:"error"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &update); err != nil {
+			continue
+		}
+		if update.Error != "" {
+			return fmt.Errorf("pull failed: %s", update.Error)
+		}
+		if progress != nil {
+			progress(update.PullProgress)
+		}
+		if update.Status == "success" {
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("pull interrupted: %w", err)
+	}
+	return fmt.Errorf("pull ended before %s was ready", model)
+}
+
+// Embed returns embeddings of texts from /api/embed, e.g. with
+// nomic-embed-text.
+func (p *OllamaProvider) Embed(ctx context.Context, model string, texts []string) ([][]float32, error) {
+	var resp struct {
+		Embeddings [][]float32 `json:"embeddings"`
+	}
+	req := struct {
+		Model     string      `json:"model"`
+		Input     []string    `json:"input"`
+		KeepAlive interface{} `json:"keep_alive,omitempty"`
+	}{model, texts, keepAlive(p.KeepAlive)}
+	if err := p.post(ctx, "/api/embed", req, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("got %d embeddings for %d inputs", len(resp.Embeddings), len(texts))
+	}
+	return resp.Embeddings, nil
+}
+
+// post sends body as JSON to path and decodes the reply into out.
+func (p *OllamaProvider) post(ctx context.Context, path string, body, out interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.BaseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := p.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("http request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("API error %d: %s", resp.StatusCode, string(respBody))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
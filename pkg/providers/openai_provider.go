@@ -7,16 +7,28 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
+	"strings"
 	"time"
+
+	"littleclaw/pkg/httpclient"
+	"littleclaw/pkg/telemetry"
 )
 
 // OpenAIProvider is a generic provider for OpenAI-compatible APIs.
-// This supports OpenAI, OpenRouter, Ollama (with v1/chat/completions endpoint), and Codex.
+// This supports OpenAI, OpenRouter, xAI, Ollama (with v1/chat/completions endpoint), and Codex.
 type OpenAIProvider struct {
 	NameStr    string
 	BaseURL    string // e.g., "https://api.openai.com/v1" or "http://localhost:11434/v1"
 	APIKey     string
 	HTTPClient *http.Client
+
+	// ToolChoice, if set, is sent as tool_choice with requests that carry
+	// tools; APIs reject it on requests without them.
+	ToolChoice string
+	// Extra fields are added to every request body, for options only one
+	// API knows, like xAI's search_parameters.
+	Extra map[string]interface{}
 }
 
 // NewOpenAIProvider creates a new provider compatible with OpenAI's API format.
@@ -25,7 +37,7 @@ func NewOpenAIProvider(name, baseURL, apiKey string) *OpenAIProvider {
 		NameStr:    name,
 		BaseURL:    baseURL,
 		APIKey:     apiKey,
-		HTTPClient: &http.Client{Timeout: 3 * time.Minute},
+		HTTPClient: httpclient.New(3 * time.Minute),
 	}
 }
 
@@ -37,17 +49,41 @@ type openAIRequest struct {
 	Model       string           `json:"model"`
 	Messages    []openAIMessage  `json:"messages"`
 	Tools       []ToolDefinition `json:"tools,omitempty"`
+	ToolChoice  string           `json:"tool_choice,omitempty"`
 	Temperature float64          `json:"temperature,omitempty"`
 	MaxTokens   int              `json:"max_tokens,omitempty"`
 }
 
 type openAIMessage struct {
 	Role       string                   `json:"role"`
-	Content    string                   `json:"content"`
+	Content    interface{}              `json:"content"` // string, or []openAIContentPart when images are attached
 	ToolCalls  []map[string]interface{} `json:"tool_calls,omitempty"`
 	ToolCallID string                   `json:"tool_call_id,omitempty"`
 }
 
+type openAIContentPart struct {
+	Type     string          `json:"type"` // "text" or "image_url"
+	Text     string          `json:"text,omitempty"`
+	ImageURL *openAIImageURL `json:"image_url,omitempty"`
+}
+
+type openAIImageURL struct {
+	URL string `json:"url"` // http(s) URL or data: URL
+}
+
+// messageContent returns the plain text content, or multi-part content with
+// the message's Media attached as images for vision-capable models.
+func messageContent(msg Message) interface{} {
+	if len(msg.Media) == 0 {
+		return msg.Content
+	}
+	parts := []openAIContentPart{{Type: "text", Text: msg.Content}}
+	for _, url := range msg.Media {
+		parts = append(parts, openAIContentPart{Type: "image_url", ImageURL: &openAIImageURL{URL: url}})
+	}
+	return parts
+}
+
 type openAIResponse struct {
 	Choices []struct {
 		Message struct {
@@ -55,16 +91,34 @@ type openAIResponse struct {
 			Content   string                   `json:"content"`
 			ToolCalls []map[string]interface{} `json:"tool_calls,omitempty"`
 		} `json:"message"`
+		FinishReason string `json:"finish_reason"`
 	} `json:"choices"`
-	Usage Usage `json:"usage"`
+	Usage     Usage    `json:"usage"`
+	Citations []string `json:"citations,omitempty"` // sources of a search-backed answer (xAI, Perplexity)
 }
 
 func (p *OpenAIProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	ctx, span := telemetry.Start(ctx, "chat "+req.Model, "gen_ai.system", p.NameStr, "gen_ai.request.model", req.Model,
+		"gen_ai.request.messages", len(req.Messages), "gen_ai.request.tools", len(req.Tools))
+	defer span.End()
+
+	resp, err := p.chat(ctx, req)
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+	span.SetAttributes("gen_ai.usage.input_tokens", resp.Usage.PromptTokens, "gen_ai.usage.output_tokens", resp.Usage.CompletionTokens,
+		"gen_ai.response.finish_reason", resp.FinishReason, "gen_ai.response.tool_calls", len(resp.ToolCalls))
+	return resp, nil
+}
+
+// chat sends one chat completions request.
+func (p *OpenAIProvider) chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
 	apiMessages := make([]openAIMessage, len(req.Messages))
 	for i, msg := range req.Messages {
 		apiMessages[i] = openAIMessage{
 			Role:       msg.Role,
-			Content:    msg.Content,
+			Content:    messageContent(msg),
 			ToolCalls:  msg.ToolCalls,
 			ToolCallID: msg.ToolCallID,
 		}
@@ -77,8 +131,11 @@ func (p *OpenAIProvider) Chat(ctx context.Context, req ChatRequest) (*ChatRespon
 		Temperature: req.Temperature,
 		MaxTokens:   req.MaxTokens,
 	}
+	if len(req.Tools) > 0 {
+		apiReq.ToolChoice = p.ToolChoice
+	}
 
-	bodyBytes, err := json.Marshal(apiReq)
+	bodyBytes, err := p.requestBody(apiReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
@@ -121,8 +178,130 @@ func (p *OpenAIProvider) Chat(ctx context.Context, req ChatRequest) (*ChatRespon
 
 	msg := apiResp.Choices[0].Message
 	return &ChatResponse{
-		Content:   msg.Content,
-		ToolCalls: msg.ToolCalls,
-		Usage:     apiResp.Usage,
+		Content:      withCitations(msg.Content, apiResp.Citations),
+		ToolCalls:    msg.ToolCalls,
+		Usage:        apiResp.Usage,
+		FinishReason: apiResp.Choices[0].FinishReason,
 	}, nil
 }
+
+// requestBody marshals apiReq with the provider's Extra fields added.
+func (p *OpenAIProvider) requestBody(apiReq openAIRequest) ([]byte, error) {
+	body, err := json.Marshal(apiReq)
+	if err != nil || len(p.Extra) == 0 {
+		return body, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, err
+	}
+	for k, v := range p.Extra {
+		fields[k] = v
+	}
+	return json.Marshal(fields)
+}
+
+// withCitations appends the sources a search-backed answer cites, numbered,
+// so the user can check them.
+func withCitations(content string, citations []string) string {
+	if content == "" || len(citations) == 0 {
+		return content
+	}
+	var b strings.Builder
+	b.WriteString(content + "\n\nSources:")
+	for i, url := range citations {
+		fmt.Fprintf(&b, "\n%d. %s", i+1, url)
+	}
+	return b.String()
+}
+
+// ListModels lists the models at the API's /models endpoint. OpenRouter
+// adds display names and context lengths.
+func (p *OpenAIProvider) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, p.BaseURL+"/models", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if p.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.APIKey)
+	}
+	resp, err := p.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("http request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API error %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var list struct {
+		Data []struct {
+			ID            string `json:"id"`
+			Name          string `json:"name"`     // OpenRouter
+			OwnedBy       string `json:"owned_by"` // OpenAI, xAI
+			ContextLength int    `json:"context_length"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	models := make([]ModelInfo, 0, len(list.Data))
+	for _, m := range list.Data {
+		desc := m.Name
+		if desc == "" {
+			desc = m.OwnedBy
+		}
+		models = append(models, ModelInfo{ID: m.ID, Description: desc, ContextLength: m.ContextLength})
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
+	return models, nil
+}
+
+// Embed returns embeddings of texts from the API's /embeddings endpoint.
+func (p *OpenAIProvider) Embed(ctx context.Context, model string, texts []string) ([][]float32, error) {
+	bodyBytes, err := json.Marshal(map[string]interface{}{"model": model, "input": texts})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.BaseURL+"/embeddings", bytes.NewReader(bodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if p.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.APIKey)
+	}
+	resp, err := p.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("http request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API error %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var apiResp struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	vectors := make([][]float32, len(texts))
+	for _, d := range apiResp.Data {
+		if d.Index < 0 || d.Index >= len(vectors) {
+			return nil, fmt.Errorf("embedding index %d out of range", d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	for i, v := range vectors {
+		if len(v) == 0 {
+			return nil, fmt.Errorf("no embedding returned for input %d", i)
+		}
+	}
+	return vectors, nil
+}
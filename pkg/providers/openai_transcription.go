@@ -6,12 +6,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"mime/multipart"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
+
+	"littleclaw/pkg/httpclient"
 )
 
 // OpenAITranscriptionProvider implements TranscriptionProvider for OpenAI-compatible APIs (including local ones).
@@ -19,6 +21,7 @@ type OpenAITranscriptionProvider struct {
 	BaseURL    string
 	APIKey     string
 	Model      string
+	Language   string // optional ISO-639-1 hint; empty auto-detects
 	HTTPClient *http.Client
 }
 
@@ -34,7 +37,7 @@ func NewOpenAITranscriptionProvider(baseURL, apiKey, model string) *OpenAITransc
 		BaseURL:    baseURL,
 		APIKey:     apiKey,
 		Model:      model,
-		HTTPClient: &http.Client{},
+		HTTPClient: httpclient.New(transcriptionTimeout),
 	}
 }
 
@@ -62,6 +65,9 @@ func (p *OpenAITranscriptionProvider) Transcribe(ctx context.Context, audioPath
 
 	_ = writer.WriteField("model", p.Model)
 	_ = writer.WriteField("response_format", "json")
+	if p.Language != "" {
+		_ = writer.WriteField("language", p.Language)
+	}
 	
 	if err := writer.Close(); err != nil {
 		return "", fmt.Errorf("failed to close multipart writer: %w", err)
@@ -78,7 +84,7 @@ func (p *OpenAITranscriptionProvider) Transcribe(ctx context.Context, audioPath
 		endpoint = url + "/audio/transcriptions"
 	}
 
-	log.Printf("🎙️ Transcribing via: %s", endpoint)
+	slog.Debug("transcribing audio", "endpoint", endpoint)
 	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, body)
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
@@ -2,8 +2,13 @@ package providers
 
 import (
 	"context"
+	"time"
 )
 
+// transcriptionTimeout bounds one upload to a transcription API, long enough
+// for a few minutes of audio on a slow link.
+const transcriptionTimeout = 2 * time.Minute
+
 // TranscriptionProvider defines the interface for audio-to-text transcription.
 type TranscriptionProvider interface {
 	// Transcribe takes a local path to an audio file and returns its transcription.
@@ -39,15 +39,35 @@ type Usage struct {
 	TotalTokens      int `json:"total_tokens"`
 }
 
+// FinishReasonLength is the finish reason reported when a reply hit the
+// provider's max_tokens limit and was cut off.
+const FinishReasonLength = "length"
+
 // ChatResponse holds the parsed LLM response.
 type ChatResponse struct {
-	Content   string                   `json:"content"`
-	ToolCalls []map[string]interface{} `json:"tool_calls,omitempty"`
-	Usage     Usage                    `json:"usage"`
+	Content      string                   `json:"content"`
+	ToolCalls    []map[string]interface{} `json:"tool_calls,omitempty"`
+	Usage        Usage                    `json:"usage"`
+	FinishReason string                   `json:"finish_reason,omitempty"` // "stop", "length", "tool_calls", ...
+}
+
+// ModelInfo describes a model a provider serves.
+type ModelInfo struct {
+	ID            string `json:"id"`                       // what goes in ChatRequest.Model
+	Description   string `json:"description,omitempty"`    // e.g. owner, size, or display name
+	ContextLength int    `json:"context_length,omitempty"` // tokens, when the provider says
+}
+
+// Embedder is a provider that can turn texts into embedding vectors.
+type Embedder interface {
+	// Embed returns one vector per text, in order.
+	Embed(ctx context.Context, model string, texts []string) ([][]float32, error)
 }
 
 // Provider represents a generic LLM provider backend (OpenAI, Claude, OpenRouter, etc.)
 type Provider interface {
 	Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error)
 	Name() string
+	// ListModels returns the models the provider can serve, sorted by ID.
+	ListModels(ctx context.Context) ([]ModelInfo, error)
 }
@@ -3,7 +3,7 @@ package providers
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -12,7 +12,8 @@ import (
 
 // WhisperCLITranscriptionProvider implements TranscriptionProvider using the local whisper CLI.
 type WhisperCLITranscriptionProvider struct {
-	Model string
+	Model    string
+	Language string // optional language hint such as "en"; empty auto-detects
 }
 
 // NewWhisperCLITranscriptionProvider creates a new Whisper CLI transcription provider.
@@ -40,14 +41,17 @@ func (p *WhisperCLITranscriptionProvider) Transcribe(ctx context.Context, audioP
 		"--output_dir", tmpDir,
 		"--output_format", "txt",
 	}
+	if p.Language != "" {
+		args = append(args, "--language", p.Language)
+	}
 
-	log.Printf("🎙️ Running Whisper CLI: whisper %s", strings.Join(args, " "))
+	slog.Debug("running whisper CLI", "args", strings.Join(args, " "))
 	cmd := exec.CommandContext(ctx, "whisper", args...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return "", fmt.Errorf("whisper CLI failed: %w\nOutput: %s", err, string(output))
 	}
-	log.Printf("✅ Whisper CLI finished successfully")
+	slog.Debug("whisper CLI finished")
 
 	// Read the output text file
 	// Whisper creates <audio_filename>.txt
@@ -0,0 +1,52 @@
+package providers
+
+import "fmt"
+
+// XAIBaseURL is xAI's OpenAI-compatible API for the Grok models.
+const XAIBaseURL = "https://api.x.ai/v1"
+
+// XAIOptions are the request settings only xAI knows.
+type XAIOptions struct {
+	ToolChoice       string   // "auto" (the API's default), "required", or "none"
+	Search           string   // live search: "off" (default), "auto" (the model decides), or "on"
+	SearchSources    []string // "web", "x", or "news"; empty uses xAI's default sources
+	MaxSearchResults int      // sources to consider per answer; 0 uses xAI's default
+}
+
+// NewXAIProvider creates a provider for xAI's Grok models. With live search
+// on, answers come back with the URLs they cite listed under "Sources:".
+func NewXAIProvider(apiKey string, opts XAIOptions) (*OpenAIProvider, error) {
+	switch opts.ToolChoice {
+	case "", "auto", "required", "none":
+	default:
+		return nil, fmt.Errorf("unknown xai tool_choice %q (want auto, required, or none)", opts.ToolChoice)
+	}
+	p := NewOpenAIProvider("xai", XAIBaseURL, apiKey)
+	p.ToolChoice = opts.ToolChoice
+
+	switch opts.Search {
+	case "", "off":
+		return p, nil
+	case "auto", "on":
+	default:
+		return nil, fmt.Errorf("unknown xai search mode %q (want off, auto, or on)", opts.Search)
+	}
+	search := map[string]interface{}{"mode": opts.Search, "return_citations": true}
+	if len(opts.SearchSources) > 0 {
+		sources := make([]map[string]string, len(opts.SearchSources))
+		for i, s := range opts.SearchSources {
+			switch s {
+			case "web", "x", "news":
+			default:
+				return nil, fmt.Errorf("unknown xai search source %q (want web, x, or news)", s)
+			}
+			sources[i] = map[string]string{"type": s}
+		}
+		search["sources"] = sources
+	}
+	if opts.MaxSearchResults > 0 {
+		search["max_search_results"] = opts.MaxSearchResults
+	}
+	p.Extra = map[string]interface{}{"search_parameters": search}
+	return p, nil
+}
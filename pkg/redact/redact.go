@@ -0,0 +1,136 @@
+package redact
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// Placeholder replaces every redacted secret.
+const Placeholder = "[REDACTED]"
+
+// minSecretLen is the shortest known secret replaced verbatim; shorter values
+// would match ordinary words.
+const minSecretLen = 8
+
+// patterns match well-known token formats. A pattern with a capture group
+// keeps the group (the "key=" part) and replaces the rest of the match.
+var patterns = []*regexp.Regexp{
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`),
+	regexp.MustCompile(`\bsk-(?:or-v1-|proj-|ant-)?[A-Za-z0-9_\-]{20,}`),                     // OpenAI, OpenRouter, Anthropic
+	regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{30,}|github_pat_\w{30,})`),                // GitHub
+	regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`),                                    // Slack
+	regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`),                                               // AWS access key ID
+	regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`),                                         // Google API key
+	regexp.MustCompile(`\b\d{8,10}:AA[A-Za-z0-9_\-]{33}\b`),                                  // Telegram bot token
+	regexp.MustCompile(`\beyJ[A-Za-z0-9_\-]{10,}\.[A-Za-z0-9_\-]{10,}\.[A-Za-z0-9_\-]{10,}`), // JWT
+	regexp.MustCompile(`(?i)(authorization:\s*(?:bearer\s+|basic\s+|token\s+)?)[^\s"']+`),
+	regexp.MustCompile(`(?i)([\w-]*(?:api[_-]?key|secret|token|passw(?:or)?d)["']?\s*[:=]\s*["']?)[^\s"',;&]{6,}`),
+	regexp.MustCompile(`(://[^\s:/@]+:)[^\s/@]+(@)`), // password in a URL
+}
+
+// candidate matches long runs of token characters for the entropy check.
+var candidate = regexp.MustCompile(`[A-Za-z0-9_\-+=]{32,}`)
+
+// entropyThreshold is the Shannon entropy, in bits per character, above which
+// a long mixed-case alphanumeric run is taken for a key. English text and
+// hex digests stay below it.
+const entropyThreshold = 4.0
+
+// Redactor replaces secrets in text: the known values it was given, token
+// formats such as API keys and bearer headers, and long random-looking
+// strings.
+type Redactor struct {
+	secrets []string // longest first, so a secret containing another goes first
+}
+
+// New creates a Redactor for the given known secrets; values shorter than 8
+// characters are ignored.
+func New(secrets ...string) *Redactor {
+	r := &Redactor{}
+	seen := make(map[string]bool)
+	for _, s := range secrets {
+		s = strings.TrimSpace(s)
+		if len(s) >= minSecretLen && !seen[s] {
+			seen[s] = true
+			r.secrets = append(r.secrets, s)
+		}
+	}
+	sort.Slice(r.secrets, func(i, j int) bool { return len(r.secrets[i]) > len(r.secrets[j]) })
+	return r
+}
+
+// String returns s with every secret replaced by Placeholder.
+func (r *Redactor) String(s string) string {
+	if s == "" {
+		return s
+	}
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, Placeholder)
+	}
+	for _, re := range patterns {
+		if re.NumSubexp() == 0 {
+			s = re.ReplaceAllString(s, Placeholder)
+			continue
+		}
+		s = re.ReplaceAllStringFunc(s, func(m string) string {
+			sub := re.FindStringSubmatch(m)
+			out := sub[1] + Placeholder
+			if len(sub) > 2 {
+				out += sub[2]
+			}
+			return out
+		})
+	}
+	return candidate.ReplaceAllStringFunc(s, func(m string) string {
+		if looksRandom(m) {
+			return Placeholder
+		}
+		return m
+	})
+}
+
+// looksRandom reports whether s mixes upper case, lower case, and digits with
+// high entropy, as generated keys do.
+func looksRandom(s string) bool {
+	var upper, lower, digit bool
+	counts := make(map[rune]int)
+	for _, c := range s {
+		switch {
+		case c >= 'A' && c <= 'Z':
+			upper = true
+		case c >= 'a' && c <= 'z':
+			lower = true
+		case c >= '0' && c <= '9':
+			digit = true
+		}
+		counts[c]++
+	}
+	if !upper || !lower || !digit {
+		return false
+	}
+	entropy := 0.0
+	for _, n := range counts {
+		p := float64(n) / float64(len(s))
+		entropy -= p * math.Log2(p)
+	}
+	return entropy >= entropyThreshold
+}
+
+// global is the Redactor used by String; SetSecrets replaces it.
+var global atomic.Pointer[Redactor]
+
+func init() { global.Store(New()) }
+
+// SetSecrets makes String also replace the given known secrets, such as the
+// API keys in the config. It replaces the previous set.
+func SetSecrets(secrets ...string) {
+	global.Store(New(secrets...))
+}
+
+// String redacts s with the secrets from SetSecrets and the built-in patterns.
+func String(s string) string {
+	return global.Load().String(s)
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/template"
+)
+
+// Spec describes the service to install.
+type Spec struct {
+	Name    string   // unit name / launchd label suffix, e.g. "littleclaw" or "littleclaw-work"
+	Binary  string   // absolute path to the littleclaw binary
+	Args    []string // global flags to run it with, e.g. --profile work
+	WorkDir string   // working directory (the profile directory)
+	LogFile string   // launchd only: where stdout and stderr go (systemd uses the journal)
+}
+
+// Manager installs and controls a service with the platform's init system.
+type Manager struct {
+	Spec Spec
+
+	// Overridable for tests
+	GOOS   string
+	Home   string
+	Runner func(name string, args ...string) error
+}
+
+// New creates a manager for this platform and user.
+func New(spec Spec) (*Manager, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("could not find home directory: %w", err)
+	}
+	return &Manager{Spec: spec, GOOS: runtime.GOOS, Home: home, Runner: run}, nil
+}
+
+func run(name string, args ...string) error {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s %s: %v: %s", name, strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// label is the launchd job label.
+func (m *Manager) label() string {
+	return "com." + m.Spec.Name
+}
+
+// Path returns where the unit or plist is installed.
+func (m *Manager) Path() (string, error) {
+	switch m.GOOS {
+	case "linux":
+		return filepath.Join(m.Home, ".config", "systemd", "user", m.Spec.Name+".service"), nil
+	case "darwin":
+		return filepath.Join(m.Home, "Library", "LaunchAgents", m.label()+".plist"), nil
+	}
+	return "", fmt.Errorf("services are not supported on %s; use systemd (Linux) or launchd (macOS)", m.GOOS)
+}
+
+// Render returns the unit or plist for this platform.
+func (m *Manager) Render() (string, error) {
+	var tmpl *template.Template
+	switch m.GOOS {
+	case "linux":
+		tmpl = systemdUnit
+	case "darwin":
+		tmpl = launchdPlist
+	default:
+		_, err := m.Path()
+		return "", err
+	}
+	var buf bytes.Buffer
+	err := tmpl.Execute(&buf, struct {
+		Spec
+		Label string
+	}{m.Spec, m.label()})
+	return buf.String(), err
+}
+
+// Install writes the unit or plist and enables it to start at login. It does
+// not start the service.
+func (m *Manager) Install() (string, error) {
+	path, err := m.Path()
+	if err != nil {
+		return "", err
+	}
+	content, err := m.Render()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return "", err
+	}
+	if m.GOOS == "linux" {
+		if err := m.Runner("systemctl", "--user", "daemon-reload"); err != nil {
+			return path, err
+		}
+		return path, m.Runner("systemctl", "--user", "enable", m.Spec.Name+".service")
+	}
+	return path, nil
+}
+
+// Uninstall stops the service and removes the unit or plist.
+func (m *Manager) Uninstall() error {
+	path, err := m.Path()
+	if err != nil {
+		return err
+	}
+	m.Stop() // ignore the error: it may not be running
+	if m.GOOS == "linux" {
+		m.Runner("systemctl", "--user", "disable", m.Spec.Name+".service")
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if m.GOOS == "linux" {
+		return m.Runner("systemctl", "--user", "daemon-reload")
+	}
+	return nil
+}
+
+// Start starts the installed service.
+func (m *Manager) Start() error {
+	path, err := m.Path()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("the service is not installed; run 'littleclaw service install' first")
+	}
+	if m.GOOS == "linux" {
+		return m.Runner("systemctl", "--user", "start", m.Spec.Name+".service")
+	}
+	return m.Runner("launchctl", "load", "-w", path)
+}
+
+// Stop stops the service; on macOS it also stays stopped until the next Start.
+func (m *Manager) Stop() error {
+	path, err := m.Path()
+	if err != nil {
+		return err
+	}
+	if m.GOOS == "linux" {
+		return m.Runner("systemctl", "--user", "stop", m.Spec.Name+".service")
+	}
+	return m.Runner("launchctl", "unload", "-w", path)
+}
+
+// unitFuncs escape values for the unit and plist formats.
+var unitFuncs = template.FuncMap{
+	// systemd splits ExecStart on spaces unless the word is quoted
+	"quote": func(s string) string {
+		if !strings.ContainsAny(s, " \t\"'\\") {
+			return s
+		}
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+	},
+	"xml": func(s string) string {
+		var buf bytes.Buffer
+		xml.EscapeText(&buf, []byte(s))
+		return buf.String()
+	},
+}
+
+var systemdUnit = template.Must(template.New("systemd").Funcs(unitFuncs).Parse(`[Unit]
+Description=Littleclaw AI agent ({{.Name}})
+After=network-online.target
+Wants=network-online.target
+
+[Service]
+ExecStart={{quote .Binary}}{{range .Args}} {{quote .}}{{end}}
+WorkingDirectory={{.WorkDir}}
+Restart=on-failure
+RestartSec=10
+
+[Install]
+WantedBy=default.target
+`))
+
+var launchdPlist = template.Must(template.New("launchd").Funcs(unitFuncs).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>{{xml .Label}}</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>{{xml .Binary}}</string>{{range .Args}}
+		<string>{{xml .}}</string>{{end}}
+	</array>
+	<key>WorkingDirectory</key>
+	<string>{{xml .WorkDir}}</string>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<dict>
+		<key>SuccessfulExit</key>
+		<false/>
+	</dict>
+	<key>StandardOutPath</key>
+	<string>{{xml .LogFile}}</string>
+	<key>StandardErrorPath</key>
+	<string>{{xml .LogFile}}</string>
+</dict>
+</plist>
+`))
@@ -0,0 +1,132 @@
+package service_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"littleclaw/pkg/service"
+)
+
+func newManager(t *testing.T, goos string) (*service.Manager, *[]string) {
+	var ran []string
+	m := &service.Manager{
+		Spec: service.Spec{
+			Name:    "littleclaw-work",
+			Binary:  "/opt/my apps/littleclaw",
+			Args:    []string{"--profile", "work"},
+			WorkDir: "/home/ada/.littleclaw/profiles/work",
+			LogFile: "/home/ada/.littleclaw/profiles/work/littleclaw.log",
+		},
+		GOOS: goos,
+		Home: t.TempDir(),
+		Runner: func(name string, args ...string) error {
+			ran = append(ran, name+" "+strings.Join(args, " "))
+			return nil
+		},
+	}
+	return m, &ran
+}
+
+func TestRenderSystemd(t *testing.T) {
+	m, _ := newManager(t, "linux")
+	unit, err := m.Render()
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	for _, want := range []string{
+		`ExecStart="/opt/my apps/littleclaw" --profile work`,
+		"WorkingDirectory=/home/ada/.littleclaw/profiles/work",
+		"Restart=on-failure",
+		"WantedBy=default.target",
+	} {
+		if !strings.Contains(unit, want) {
+			t.Errorf("unit missing %q:\n%s", want, unit)
+		}
+	}
+}
+
+func TestRenderLaunchd(t *testing.T) {
+	m, _ := newManager(t, "darwin")
+	m.Spec.Args = []string{"--workspace", "/tmp/a&b"}
+	plist, err := m.Render()
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	for _, want := range []string{
+		"<string>com.littleclaw-work</string>",
+		"<string>/opt/my apps/littleclaw</string>",
+		"<string>/tmp/a&amp;b</string>",
+		"<key>RunAtLoad</key>",
+	} {
+		if !strings.Contains(plist, want) {
+			t.Errorf("plist missing %q:\n%s", want, plist)
+		}
+	}
+}
+
+func TestInstallStartStopSystemd(t *testing.T) {
+	m, ran := newManager(t, "linux")
+	if err := m.Start(); err == nil {
+		t.Error("expected Start to fail before Install")
+	}
+
+	path, err := m.Install()
+	if err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	if want := filepath.Join(m.Home, ".config", "systemd", "user", "littleclaw-work.service"); path != want {
+		t.Errorf("Install() path = %q, want %q", path, want)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("unit not written: %v", err)
+	}
+	if err := m.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if err := m.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	want := []string{
+		"systemctl --user daemon-reload",
+		"systemctl --user enable littleclaw-work.service",
+		"systemctl --user start littleclaw-work.service",
+		"systemctl --user stop littleclaw-work.service",
+	}
+	if strings.Join(*ran, "\n") != strings.Join(want, "\n") {
+		t.Errorf("ran:\n%s\nwant:\n%s", strings.Join(*ran, "\n"), strings.Join(want, "\n"))
+	}
+
+	if err := m.Uninstall(); err != nil {
+		t.Fatalf("Uninstall() error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("expected the unit to be removed")
+	}
+}
+
+func TestStartLaunchd(t *testing.T) {
+	m, ran := newManager(t, "darwin")
+	path, err := m.Install()
+	if err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	if len(*ran) != 0 {
+		t.Errorf("Install ran %v, want nothing on macOS", *ran)
+	}
+	if err := m.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if got, want := (*ran)[0], "launchctl load -w "+path; got != want {
+		t.Errorf("Start ran %q, want %q", got, want)
+	}
+}
+
+func TestUnsupportedOS(t *testing.T) {
+	m, _ := newManager(t, "windows")
+	if _, err := m.Install(); err == nil || !strings.Contains(err.Error(), "not supported") {
+		t.Errorf("Install() error = %v, want unsupported", err)
+	}
+}
@@ -0,0 +1,396 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"littleclaw/pkg/httpclient"
+)
+
+const (
+	// DefaultServiceName is the service.name reported when none is configured.
+	DefaultServiceName = "littleclaw"
+
+	// tracesPath is the OTLP/HTTP traces endpoint path.
+	tracesPath = "/v1/traces"
+
+	// exportInterval is how often queued spans are sent.
+	exportInterval = 5 * time.Second
+	// exportTimeout bounds one export request.
+	exportTimeout = 10 * time.Second
+	// maxBatch caps the spans sent in one request.
+	maxBatch = 256
+	// maxQueued caps the spans waiting for export; more are dropped.
+	maxQueued = 2048
+)
+
+// statusError is the OTLP status code of a failed span.
+const statusError = 2
+
+// Options configures the OTLP exporter.
+type Options struct {
+	Endpoint    string            // OTLP/HTTP base URL such as http://localhost:4318, or the full .../v1/traces URL
+	Headers     map[string]string // sent with every export, e.g. an API key
+	ServiceName string            // default "littleclaw"
+}
+
+// active is the exporter spans are sent to; nil while tracing is off.
+var active atomic.Pointer[exporter]
+
+// Setup starts exporting spans to opts.Endpoint. An empty endpoint turns
+// tracing off. The returned function flushes queued spans and stops the
+// exporter.
+func Setup(opts Options) (shutdown func(context.Context) error, err error) {
+	if opts.Endpoint == "" {
+		if old := active.Swap(nil); old != nil {
+			old.stop(context.Background())
+		}
+		return func(context.Context) error { return nil }, nil
+	}
+	target, err := tracesURL(opts.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	service := opts.ServiceName
+	if service == "" {
+		service = DefaultServiceName
+	}
+
+	e := &exporter{
+		url:      target,
+		headers:  opts.Headers,
+		resource: otlpResource{Attributes: attributes([]any{"service.name", service})},
+		client:   httpclient.New(exportTimeout),
+		queue:    make(chan otlpSpan, maxQueued),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	go e.run()
+	if old := active.Swap(e); old != nil {
+		old.stop(context.Background())
+	}
+	return func(ctx context.Context) error {
+		active.CompareAndSwap(e, nil)
+		return e.stop(ctx)
+	}, nil
+}
+
+// Enabled reports whether spans are being exported.
+func Enabled() bool { return active.Load() != nil }
+
+// tracesURL returns the traces endpoint for an OTLP/HTTP base URL.
+func tracesURL(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("invalid OTLP endpoint %q: want an http(s) URL such as http://localhost:4318", endpoint)
+	}
+	if !strings.HasSuffix(u.Path, tracesPath) {
+		u.Path = strings.TrimSuffix(u.Path, "/") + tracesPath
+	}
+	return u.String(), nil
+}
+
+// spanKey is the context key of the current span.
+type spanKey struct{}
+
+// Span is one timed operation. A nil *Span, returned while tracing is off,
+// ignores every call.
+type Span struct {
+	exp      *exporter
+	name     string
+	traceID  [16]byte
+	spanID   [8]byte
+	parentID [8]byte
+	start    time.Time
+
+	mu        sync.Mutex
+	attrs     []any
+	status    int
+	statusMsg string
+	ended     bool
+}
+
+// Start begins a span named name as a child of the span in ctx, if any.
+// attrs are alternating keys and values, as for slog. The returned context
+// carries the new span.
+func Start(ctx context.Context, name string, attrs ...any) (context.Context, *Span) {
+	e := active.Load()
+	if e == nil {
+		return ctx, nil
+	}
+	s := &Span{exp: e, name: name, start: time.Now(), attrs: attrs}
+	if parent, ok := ctx.Value(spanKey{}).(*Span); ok && parent != nil {
+		s.traceID, s.parentID = parent.traceID, parent.spanID
+	} else {
+		rand.Read(s.traceID[:])
+	}
+	rand.Read(s.spanID[:])
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// SetAttributes adds alternating keys and values to the span.
+func (s *Span) SetAttributes(attrs ...any) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.attrs = append(s.attrs, attrs...)
+	s.mu.Unlock()
+}
+
+// SetError marks the span as failed with err; a nil err is ignored.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	s.status, s.statusMsg = statusError, err.Error()
+	s.mu.Unlock()
+}
+
+// End finishes the span and queues it for export. Later calls do nothing.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	span := otlpSpan{
+		TraceID:           hex.EncodeToString(s.traceID[:]),
+		SpanID:            hex.EncodeToString(s.spanID[:]),
+		Name:              s.name,
+		Kind:              1, // SPAN_KIND_INTERNAL
+		StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(time.Now().UnixNano(), 10),
+		Attributes:        attributes(s.attrs),
+		Status:            otlpStatus{Code: s.status, Message: s.statusMsg},
+	}
+	s.mu.Unlock()
+	if s.parentID != [8]byte{} {
+		span.ParentSpanID = hex.EncodeToString(s.parentID[:])
+	}
+	s.exp.enqueue(span)
+}
+
+// exporter batches ended spans and posts them as OTLP/HTTP JSON.
+type exporter struct {
+	url      string
+	headers  map[string]string
+	resource otlpResource
+	client   *http.Client
+	queue    chan otlpSpan
+
+	stopOnce sync.Once
+	done     chan struct{} // closed to stop run
+	stopped  chan struct{} // closed when run has flushed and returned
+
+	dropped atomic.Int64
+	failing bool // the last export failed; only touched by run
+}
+
+func (e *exporter) enqueue(s otlpSpan) {
+	select {
+	case e.queue <- s:
+	default:
+		if e.dropped.Add(1) == 1 {
+			slog.Warn("telemetry queue full, dropping spans", "max", maxQueued)
+		}
+	}
+}
+
+// run sends queued spans every exportInterval, or sooner once maxBatch are
+// waiting, until stopped.
+func (e *exporter) run() {
+	defer close(e.stopped)
+	ticker := time.NewTicker(exportInterval)
+	defer ticker.Stop()
+
+	var batch []otlpSpan
+	flush := func() {
+		if len(batch) > 0 {
+			e.send(batch)
+			batch = nil
+		}
+	}
+	for {
+		select {
+		case s := <-e.queue:
+			batch = append(batch, s)
+			if len(batch) >= maxBatch {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-e.done:
+			for {
+				select {
+				case s := <-e.queue:
+					batch = append(batch, s)
+					if len(batch) >= maxBatch {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// stop flushes queued spans and waits for run to return, or for ctx.
+func (e *exporter) stop(ctx context.Context) error {
+	e.stopOnce.Do(func() { close(e.done) })
+	select {
+	case <-e.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// send posts one batch, logging only when exports start or stop failing.
+func (e *exporter) send(batch []otlpSpan) {
+	err := e.post(batch)
+	switch {
+	case err != nil && !e.failing:
+		slog.Warn("telemetry export failed", "url", e.url, "spans", len(batch), "err", err)
+	case err == nil && e.failing:
+		slog.Info("telemetry export working again", "url", e.url)
+	}
+	e.failing = err != nil
+}
+
+func (e *exporter) post(batch []otlpSpan) error {
+	body, err := json.Marshal(otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource:   e.resource,
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: DefaultServiceName}, Spans: batch}},
+	}}})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return errors.New(resp.Status + ": " + strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// OTLP/HTTP JSON encoding of an ExportTraceServiceRequest.
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    string   `json:"intValue,omitempty"` // int64 as a decimal string
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+// attributes converts alternating keys and values; a pair without a string
+// key is skipped.
+func attributes(kv []any) []otlpKeyValue {
+	var out []otlpKeyValue
+	for i := 0; i+1 < len(kv); i += 2 {
+		key, ok := kv[i].(string)
+		if !ok || key == "" {
+			continue
+		}
+		out = append(out, otlpKeyValue{Key: key, Value: anyValue(kv[i+1])})
+	}
+	return out
+}
+
+func anyValue(v any) otlpAnyValue {
+	switch v := v.(type) {
+	case string:
+		return otlpAnyValue{StringValue: &v}
+	case bool:
+		return otlpAnyValue{BoolValue: &v}
+	case int:
+		return otlpAnyValue{IntValue: strconv.Itoa(v)}
+	case int64:
+		return otlpAnyValue{IntValue: strconv.FormatInt(v, 10)}
+	case float64:
+		return otlpAnyValue{DoubleValue: &v}
+	case time.Duration:
+		return otlpAnyValue{IntValue: strconv.FormatInt(v.Milliseconds(), 10)}
+	case error:
+		s := v.Error()
+		return otlpAnyValue{StringValue: &s}
+	}
+	s := fmt.Sprint(v)
+	return otlpAnyValue{StringValue: &s}
+}
@@ -0,0 +1,132 @@
+package telemetry_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"littleclaw/pkg/telemetry"
+)
+
+// exportedSpan is the part of an OTLP span the tests look at.
+type exportedSpan struct {
+	TraceID      string `json:"traceId"`
+	SpanID       string `json:"spanId"`
+	ParentSpanID string `json:"parentSpanId"`
+	Name         string `json:"name"`
+	Attributes   []struct {
+		Key   string `json:"key"`
+		Value struct {
+			StringValue string `json:"stringValue"`
+			IntValue    string `json:"intValue"`
+		} `json:"value"`
+	} `json:"attributes"`
+	Status struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"status"`
+}
+
+func (s exportedSpan) attr(key string) string {
+	for _, a := range s.Attributes {
+		if a.Key == key {
+			return a.Value.StringValue + a.Value.IntValue
+		}
+	}
+	return ""
+}
+
+// collector is an OTLP/HTTP endpoint recording the spans it receives.
+func collector(t *testing.T) (*httptest.Server, func() []exportedSpan) {
+	t.Helper()
+	var mu sync.Mutex
+	var spans []exportedSpan
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/json" || r.Header.Get("X-Api-Key") != "secret" {
+			t.Errorf("unexpected export: %s %s %v", r.Method, r.URL.Path, r.Header)
+		}
+		var req struct {
+			ResourceSpans []struct {
+				ScopeSpans []struct {
+					Spans []exportedSpan `json:"spans"`
+				} `json:"scopeSpans"`
+			} `json:"resourceSpans"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("bad export body: %v", err)
+		}
+		mu.Lock()
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				spans = append(spans, ss.Spans...)
+			}
+		}
+		mu.Unlock()
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []exportedSpan {
+		mu.Lock()
+		defer mu.Unlock()
+		return spans
+	}
+}
+
+func TestSpans_ExportedWithParents(t *testing.T) {
+	srv, received := collector(t)
+	shutdown, err := telemetry.Setup(telemetry.Options{Endpoint: srv.URL, Headers: map[string]string{"X-Api-Key": "secret"}})
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+
+	ctx, run := telemetry.Start(context.Background(), "agent.run", "chat_id", "user123")
+	_, tool := telemetry.Start(ctx, "execute_tool exec", "gen_ai.tool.name", "exec")
+	tool.SetError(errors.New("exit status 1"))
+	tool.End()
+	run.SetAttributes("llm_calls", 2)
+	run.End()
+	run.End() // a second End is ignored
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown() error = %v", err)
+	}
+	if telemetry.Enabled() {
+		t.Error("expected tracing to be off after shutdown")
+	}
+
+	spans := received()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d: %+v", len(spans), spans)
+	}
+	child, parent := spans[0], spans[1]
+	if parent.Name != "agent.run" || parent.ParentSpanID != "" || parent.attr("chat_id") != "user123" || parent.attr("llm_calls") != "2" {
+		t.Errorf("unexpected run span: %+v", parent)
+	}
+	if child.TraceID != parent.TraceID || child.ParentSpanID != parent.SpanID || len(child.TraceID) != 32 || len(child.SpanID) != 16 {
+		t.Errorf("tool span not a child of the run: %+v", child)
+	}
+	if child.Status.Code != 2 || child.Status.Message != "exit status 1" {
+		t.Errorf("expected an error status, got %+v", child.Status)
+	}
+}
+
+func TestSpans_OffWithoutEndpoint(t *testing.T) {
+	if _, err := telemetry.Setup(telemetry.Options{}); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	ctx, span := telemetry.Start(context.Background(), "agent.run")
+	if span != nil || ctx != context.Background() {
+		t.Error("expected no span while tracing is off")
+	}
+	// A nil span ignores every call
+	span.SetAttributes("k", "v")
+	span.SetError(errors.New("boom"))
+	span.End()
+
+	if _, err := telemetry.Setup(telemetry.Options{Endpoint: "localhost:4318"}); err == nil {
+		t.Error("expected an error for an endpoint without a scheme")
+	}
+}
@@ -0,0 +1,91 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+)
+
+// Approver asks a human to confirm a risky action before it is executed.
+type Approver interface {
+	// RequestApproval blocks until the user approves or denies the action,
+	// the request times out, or ctx is cancelled.
+	RequestApproval(ctx context.Context, tool, action string) (bool, error)
+}
+
+// DefaultRiskPatterns are used when approval mode is enabled without custom patterns.
+// They cover package installs, network writes, and deletions.
+var DefaultRiskPatterns = []string{
+	`\b(apt|apt-get|yum|dnf|pacman|apk|brew)\s+(install|remove|upgrade|purge)\b`,
+	`\b(pip3?|npm|pnpm|yarn|gem|cargo)\s+(install|add|uninstall|remove)\b`,
+	`\bgo\s+install\b`,
+	`\bcurl\b.*(-X\s*(POST|PUT|PATCH|DELETE)|\s(-d|--data|-F|--form|-T|--upload-file)\b)`,
+	`\bwget\b.*--post-(data|file)`,
+	`\b(scp|rsync|sftp)\b`,
+	`\bgit\s+push\b`,
+	`\brm\s`,
+	`\brmdir\b`,
+	`\bshred\b`,
+	`\btruncate\b`,
+}
+
+// SetApprover enables the human-in-the-loop gate. Commands matching any of the
+// given regex patterns are held until the approver returns a decision.
+// An empty pattern list falls back to DefaultRiskPatterns.
+func (r *Registry) SetApprover(a Approver, patterns []string) error {
+	if len(patterns) == 0 {
+		patterns = DefaultRiskPatterns
+	}
+
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return fmt.Errorf("invalid risk pattern %q: %w", p, err)
+		}
+		compiled = append(compiled, re)
+	}
+
+	r.approver = a
+	r.riskPatterns = compiled
+	return nil
+}
+
+// RequiresApproval reports whether the action matches a configured risk pattern.
+func (r *Registry) RequiresApproval(action string) bool {
+	if r.approver == nil {
+		return false
+	}
+	for _, re := range r.riskPatterns {
+		if re.MatchString(action) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckApproval returns nil when the action may proceed, or a ToolResult
+// describing why it was held back.
+func (r *Registry) CheckApproval(ctx context.Context, tool, action string) *ToolResult {
+	if !r.RequiresApproval(action) {
+		return nil
+	}
+	return r.askApproval(ctx, tool, action)
+}
+
+// askApproval holds action for the approver whether or not it matches a risk
+// pattern, for actions that are risky by nature. It returns nil when approval
+// mode is off or the user approves.
+func (r *Registry) askApproval(ctx context.Context, tool, action string) *ToolResult {
+	if r.approver == nil {
+		return nil
+	}
+	approved, err := r.approver.RequestApproval(ctx, tool, action)
+	if err != nil {
+		return &ToolResult{ForLLM: fmt.Sprintf("Action requires user approval and was not run: %v", err)}
+	}
+	if !approved {
+		return &ToolResult{ForLLM: "The user DENIED this action. It was not run. Do not retry it without asking the user first."}
+	}
+	return nil
+}
@@ -0,0 +1,537 @@
+package tools
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"littleclaw/pkg/providers"
+)
+
+const (
+	maxArchiveInputBytes = 200 << 20 // compress refuses larger inputs
+	maxExtractBytes      = 500 << 20 // guards against zip bombs
+	maxExtractEntries    = 10000
+)
+
+// archiveFormat picks the archive format from a file name: "zip", "tar", or
+// "tar.gz". It returns "" for anything else.
+func archiveFormat(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return "zip"
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return "tar.gz"
+	case strings.HasSuffix(lower, ".tar"):
+		return "tar"
+	}
+	return ""
+}
+
+// trimArchiveExt strips the archive extension, for the default extract folder.
+func trimArchiveExt(name string) string {
+	lower := strings.ToLower(name)
+	for _, ext := range []string{".tar.gz", ".tgz", ".tar", ".zip"} {
+		if strings.HasSuffix(lower, ext) {
+			return name[:len(name)-len(ext)]
+		}
+	}
+	return name
+}
+
+// archiveSource is one file or directory to be written into an archive.
+type archiveSource struct {
+	abs  string
+	name string // slash-separated name inside the archive
+	info fs.FileInfo
+}
+
+// collectArchiveSources walks the requested paths. Each path is stored under
+// its base name, so "reports" becomes "reports/..." inside the archive.
+// Memory files, symlinks, and the output archive itself are skipped.
+func (r *Registry) collectArchiveSources(paths []string, outAbs string) ([]archiveSource, int, error) {
+	var sources []archiveSource
+	var total int64
+	skipped := 0
+	for _, p := range paths {
+		root, err := r.resolveWorkspacePath(p)
+		if err != nil {
+			return nil, 0, err
+		}
+		if filepath.Clean(root) == filepath.Clean(r.workspaceDir) {
+			return nil, 0, errors.New("Error: refusing to archive the whole workspace; list the folders to include")
+		}
+		if _, err := os.Lstat(root); err != nil {
+			return nil, 0, fmt.Errorf("Error: %v", err)
+		}
+		parent := filepath.Dir(root)
+		err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if p == outAbs || IsProtectedMemoryPath(d.Name(), filepath.Dir(p)) || d.Type()&fs.ModeSymlink != 0 {
+				skipped++
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() && !info.Mode().IsRegular() {
+				skipped++
+				return nil
+			}
+			total += info.Size()
+			if total > maxArchiveInputBytes {
+				return fmt.Errorf("inputs exceed %s", formatSize(maxArchiveInputBytes))
+			}
+			rel, _ := filepath.Rel(parent, p)
+			sources = append(sources, archiveSource{abs: p, name: filepath.ToSlash(rel), info: info})
+			return nil
+		})
+		if err != nil {
+			return nil, 0, fmt.Errorf("Error reading %s: %v", p, err)
+		}
+	}
+	return sources, skipped, nil
+}
+
+// writeArchive writes sources to w in the given format.
+func writeArchive(w io.Writer, format string, sources []archiveSource) error {
+	copyFile := func(dst io.Writer, src archiveSource) error {
+		f, err := os.Open(src.abs)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(dst, f)
+		return err
+	}
+
+	if format == "zip" {
+		zw := zip.NewWriter(w)
+		for _, src := range sources {
+			hdr, err := zip.FileInfoHeader(src.info)
+			if err != nil {
+				return err
+			}
+			hdr.Name = src.name
+			if src.info.IsDir() {
+				hdr.Name += "/"
+			} else {
+				hdr.Method = zip.Deflate
+			}
+			fw, err := zw.CreateHeader(hdr)
+			if err != nil {
+				return err
+			}
+			if !src.info.IsDir() {
+				if err := copyFile(fw, src); err != nil {
+					return err
+				}
+			}
+		}
+		return zw.Close()
+	}
+
+	var gz *gzip.Writer
+	if format == "tar.gz" {
+		gz = gzip.NewWriter(w)
+		w = gz
+	}
+	tw := tar.NewWriter(w)
+	for _, src := range sources {
+		hdr, err := tar.FileInfoHeader(src.info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = src.name
+		if src.info.IsDir() {
+			hdr.Name += "/"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !src.info.IsDir() {
+			if err := copyFile(tw, src); err != nil {
+				return err
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if gz != nil {
+		return gz.Close()
+	}
+	return nil
+}
+
+// archiveEntry is the format-independent view of one archive member.
+type archiveEntry struct {
+	name  string
+	mode  fs.FileMode
+	size  int64
+	isDir bool
+	open  func() (io.ReadCloser, error)
+}
+
+// walkArchive calls fn for every member of the archive at abs.
+func walkArchive(abs, format string, fn func(archiveEntry) error) error {
+	if format == "zip" {
+		zr, err := zip.OpenReader(abs)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		for _, f := range zr.File {
+			f := f
+			mode := f.Mode()
+			err := fn(archiveEntry{
+				name:  f.Name,
+				mode:  mode,
+				size:  int64(f.UncompressedSize64),
+				isDir: mode.IsDir() || strings.HasSuffix(f.Name, "/"),
+				open:  f.Open,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	file, err := os.Open(abs)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	var rd io.Reader = file
+	if format == "tar.gz" {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		rd = gz
+	}
+	tr := tar.NewReader(rd)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		entry := archiveEntry{
+			name:  hdr.Name,
+			mode:  hdr.FileInfo().Mode(),
+			size:  hdr.Size,
+			isDir: hdr.Typeflag == tar.TypeDir,
+			open:  func() (io.ReadCloser, error) { return io.NopCloser(tr), nil },
+		}
+		if hdr.Typeflag != tar.TypeDir && hdr.Typeflag != tar.TypeReg && hdr.Typeflag != '\x00' {
+			entry.mode |= fs.ModeIrregular
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+}
+
+// extractTarget maps an archive member name to a path under dest, rejecting
+// absolute names and anything that would escape dest (zip-slip).
+func extractTarget(dest, name string) (string, error) {
+	clean := path.Clean(strings.ReplaceAll(name, "\\", "/"))
+	if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") || filepath.VolumeName(clean) != "" {
+		return "", fmt.Errorf("unsafe path %q in archive", name)
+	}
+	target := filepath.Join(dest, filepath.FromSlash(clean))
+	if target != dest && !strings.HasPrefix(target, dest+string(filepath.Separator)) {
+		return "", fmt.Errorf("unsafe path %q in archive", name)
+	}
+	return target, nil
+}
+
+// symlinkedDir returns the first existing directory between root and
+// target's parent that is a symlink, or "" if there is none. Writing through
+// one would land wherever the link points.
+func symlinkedDir(root, target string) string {
+	rel, err := filepath.Rel(root, filepath.Dir(target))
+	if err != nil || rel == "." {
+		return ""
+	}
+	p := root
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		p = filepath.Join(p, part)
+		info, err := os.Lstat(p)
+		if err != nil {
+			return "" // missing directories are created as real ones
+		}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			return p
+		}
+	}
+	return ""
+}
+
+// registerArchiveTools adds compress and extract for zip, tar, and tar.gz.
+func (r *Registry) registerArchiveTools() {
+	// compress
+	r.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "compress",
+			Description: "Bundles workspace files and folders into a .zip, .tar, or .tar.gz archive (format from the output extension). Set send=true to attach the archive to the chat.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"paths": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Relative files or folders to include. Each is stored under its own name.",
+					},
+					"output": map[string]interface{}{
+						"type":        "string",
+						"description": "Relative path of the archive to create, e.g. 'exports/reports.zip'.",
+					},
+					"send": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Send the finished archive to the user.",
+					},
+				},
+				"required": []string{"paths", "output"},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *ToolResult {
+		var paths []string
+		if raw, ok := args["paths"].([]interface{}); ok {
+			for _, p := range raw {
+				if s, ok := p.(string); ok && s != "" {
+					paths = append(paths, s)
+				}
+			}
+		}
+		output, _ := args["output"].(string)
+		send, _ := args["send"].(bool)
+		if len(paths) == 0 || output == "" {
+			return &ToolResult{ForLLM: "Error: paths and output are required"}
+		}
+		format := archiveFormat(output)
+		if format == "" {
+			return &ToolResult{ForLLM: "Error: output must end in .zip, .tar, .tar.gz, or .tgz"}
+		}
+		outAbs, err := r.resolveWorkspacePath(output)
+		if err != nil {
+			return &ToolResult{ForLLM: err.Error()}
+		}
+		if _, err := os.Stat(outAbs); err == nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error: %s already exists", output)}
+		}
+
+		sources, skipped, err := r.collectArchiveSources(paths, outAbs)
+		if err != nil {
+			return &ToolResult{ForLLM: err.Error()}
+		}
+		if err := os.MkdirAll(filepath.Dir(outAbs), 0755); err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error creating parent directories: %v", err)}
+		}
+		tmp, err := os.CreateTemp(filepath.Dir(outAbs), "."+filepath.Base(outAbs)+".tmp-*")
+		if err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error creating archive: %v", err)}
+		}
+		defer os.Remove(tmp.Name())
+		if err := writeArchive(tmp, format, sources); err != nil {
+			tmp.Close()
+			return &ToolResult{ForLLM: fmt.Sprintf("Error creating archive: %v", err)}
+		}
+		if err := tmp.Close(); err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error creating archive: %v", err)}
+		}
+		if err := os.Rename(tmp.Name(), outAbs); err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error creating archive: %v", err)}
+		}
+
+		files := 0
+		for _, s := range sources {
+			if !s.info.IsDir() {
+				files++
+			}
+		}
+		var size int64
+		if info, err := os.Stat(outAbs); err == nil {
+			size = info.Size()
+		}
+		msg := fmt.Sprintf("Created %s (%d file(s), %s)", output, files, formatSize(size))
+		if skipped > 0 {
+			msg += fmt.Sprintf("; skipped %d memory file(s), symlink(s), or special file(s)", skipped)
+		}
+		result := &ToolResult{ForLLM: msg}
+		if send {
+			result.ForUser = fmt.Sprintf("📦 %s", filepath.Base(outAbs))
+			result.Files = []string{outAbs}
+		}
+		return result
+	})
+
+	// extract
+	r.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "extract",
+			Description: "Unpacks a .zip, .tar, or .tar.gz archive inside the workspace. Entries that would escape the destination (directly or through a symlinked folder), links, memory files, and audit logs are rejected before anything is written.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"archive": map[string]interface{}{
+						"type":        "string",
+						"description": "Relative path of the archive, e.g. 'media/photos.zip'.",
+					},
+					"dest": map[string]interface{}{
+						"type":        "string",
+						"description": "Relative folder to extract into (default: the archive name without its extension).",
+					},
+					"overwrite": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Replace files that already exist in the destination.",
+					},
+				},
+				"required": []string{"archive"},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *ToolResult {
+		archive, _ := args["archive"].(string)
+		dest, _ := args["dest"].(string)
+		overwrite, _ := args["overwrite"].(bool)
+		if archive == "" {
+			return &ToolResult{ForLLM: "Error: archive is required"}
+		}
+		format := archiveFormat(archive)
+		if format == "" {
+			return &ToolResult{ForLLM: "Error: unsupported archive type (expected .zip, .tar, .tar.gz, or .tgz)"}
+		}
+		if dest == "" {
+			dest = trimArchiveExt(archive)
+		}
+		archiveAbs, err := r.resolveWorkspacePath(archive)
+		if err != nil {
+			return &ToolResult{ForLLM: err.Error()}
+		}
+		destAbs, err := r.resolveWorkspacePath(dest)
+		if err != nil {
+			return &ToolResult{ForLLM: err.Error()}
+		}
+		destAbs = filepath.Clean(destAbs)
+
+		// First pass: validate every entry so a bad archive writes nothing
+		var count int
+		var total int64
+		err = walkArchive(archiveAbs, format, func(e archiveEntry) error {
+			count++
+			if count > maxExtractEntries {
+				return fmt.Errorf("archive has more than %d entries", maxExtractEntries)
+			}
+			target, err := extractTarget(destAbs, e.name)
+			if err != nil {
+				return err
+			}
+			if !e.isDir && !e.mode.IsRegular() {
+				return fmt.Errorf("%q is a link or special file", e.name)
+			}
+			if IsProtectedMemoryPath(filepath.Base(target), filepath.Dir(target)) {
+				return fmt.Errorf("%q would overwrite a memory file", e.name)
+			}
+			if IsAuditLogPath(r.workspaceDir, target) {
+				return fmt.Errorf("%q would overwrite an audit log", e.name)
+			}
+			if link := symlinkedDir(r.workspaceDir, target); link != "" {
+				rel, _ := filepath.Rel(r.workspaceDir, link)
+				return fmt.Errorf("%q would be written through the symlink %s", e.name, rel)
+			}
+			if total += e.size; total > maxExtractBytes {
+				return fmt.Errorf("archive expands to more than %s", formatSize(maxExtractBytes))
+			}
+			if info, err := os.Lstat(target); err == nil && !e.isDir {
+				rel, _ := filepath.Rel(r.workspaceDir, target)
+				if info.Mode()&fs.ModeSymlink != 0 || info.IsDir() {
+					return fmt.Errorf("%s already exists and is not a regular file", rel)
+				}
+				if !overwrite {
+					return fmt.Errorf("%s already exists; pass overwrite=true to replace it", rel)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error: refusing to extract %s: %v", archive, err)}
+		}
+
+		// Second pass: write files, still capping actual bytes in case sizes lie
+		var written int64
+		files := 0
+		err = walkArchive(archiveAbs, format, func(e archiveEntry) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			target, err := extractTarget(destAbs, e.name)
+			if err != nil {
+				return err
+			}
+			if e.isDir {
+				return os.MkdirAll(target, 0755)
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			src, err := e.open()
+			if err != nil {
+				return err
+			}
+			defer src.Close()
+			f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, e.mode.Perm()|0600)
+			if err != nil {
+				return err
+			}
+			n, err := io.Copy(f, io.LimitReader(src, maxExtractBytes-written+1))
+			written += n
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				return err
+			}
+			if written > maxExtractBytes {
+				return fmt.Errorf("archive expands to more than %s", formatSize(maxExtractBytes))
+			}
+			files++
+			return nil
+		})
+		if err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error extracting %s after %d file(s): %v", archive, files, err)}
+		}
+		rel, _ := filepath.Rel(r.workspaceDir, destAbs)
+		return &ToolResult{ForLLM: fmt.Sprintf("Extracted %d file(s) (%s) from %s into %s/", files, formatSize(written), archive, filepath.ToSlash(rel))}
+	})
+}
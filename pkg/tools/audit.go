@@ -0,0 +1,248 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"littleclaw/pkg/events"
+	"littleclaw/pkg/providers"
+)
+
+// ToolAuditFile is the append-only log of tool invocations in the workspace root.
+const ToolAuditFile = "TOOL_AUDIT.jsonl"
+
+// AuditRecord is one line of TOOL_AUDIT.jsonl.
+type AuditRecord struct {
+	Time       time.Time `json:"time"`
+	Tool       string    `json:"tool"`
+	ArgsHash   string    `json:"args_hash"` // identifies repeated calls without storing arguments
+	DurationMs int64     `json:"duration_ms"`
+	OK         bool      `json:"ok"`
+	Error      string    `json:"error,omitempty"` // first line of a failed result
+	ChatID     string    `json:"chat_id,omitempty"`
+	Channel    string    `json:"channel,omitempty"`
+}
+
+type callerKey struct{}
+
+type caller struct{ chatID, channel string }
+
+// WithCaller tags ctx with the chat that triggered the tool calls, for the audit log.
+func WithCaller(ctx context.Context, chatID, channel string) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller{chatID, channel})
+}
+
+// CallerChat returns the chat set by WithCaller, or "".
+func CallerChat(ctx context.Context) string {
+	c, _ := ctx.Value(callerKey{}).(caller)
+	return c.chatID
+}
+
+// toolAuditor appends AuditRecords to a JSONL file.
+type toolAuditor struct {
+	mu   sync.Mutex
+	path string
+}
+
+// onToolExecuted records a finished tool call.
+func (a *toolAuditor) onToolExecuted(e events.ToolExecuted) {
+	a.record(AuditRecord{
+		Time:       e.Time,
+		Tool:       e.Tool,
+		ArgsHash:   hashArgs(e.Args),
+		DurationMs: e.Duration.Milliseconds(),
+		OK:         !e.Failed,
+		Error:      e.Error,
+		ChatID:     e.ChatID,
+		Channel:    e.Channel,
+	})
+}
+
+func (a *toolAuditor) record(rec AuditRecord) {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	f, err := os.OpenFile(a.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		slog.Warn("tool audit: failed to open log", "tool", rec.Tool, "err", err)
+		return
+	}
+	defer f.Close()
+	_, _ = f.Write(append(data, '\n'))
+}
+
+// hashArgs returns a short stable hash of the tool arguments (map keys are
+// sorted by encoding/json).
+func hashArgs(args map[string]interface{}) string {
+	data, _ := json.Marshal(args)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:6])
+}
+
+// failedResult matches the first line of a ToolResult that reports a failure;
+// handlers surface errors in ForLLM ("Error: ...", "Skill failed: ...",
+// "Command blocked by exec policy: ...").
+var failedResult = regexp.MustCompile(`(?i)^(error\b|[\w ]{0,40}\b(failed|blocked)\b|the user denied|action requires user approval)`)
+
+// resultFailed reports whether a tool result describes a failure, and its first line.
+func resultFailed(res *ToolResult) (bool, string) {
+	if res == nil {
+		return true, "no result"
+	}
+	first, _, _ := strings.Cut(strings.TrimSpace(res.ForLLM), "\n")
+	if !failedResult.MatchString(first) {
+		return false, ""
+	}
+	if len(first) > 200 {
+		first = first[:200]
+	}
+	return true, first
+}
+
+// publishExecuted publishes the ToolExecuted event for a finished tool call.
+func (r *Registry) publishExecuted(ctx context.Context, name string, args map[string]interface{}, start time.Time, res *ToolResult) {
+	failed, errLine := resultFailed(res)
+	e := events.ToolExecuted{
+		Time:     start,
+		Tool:     name,
+		Args:     args,
+		Duration: time.Since(start),
+		Failed:   failed,
+		Error:    errLine,
+	}
+	if c, ok := ctx.Value(callerKey{}).(caller); ok {
+		e.ChatID, e.Channel = c.chatID, c.channel
+	}
+	r.events.Publish(e)
+}
+
+// ToolStat aggregates audit records for one tool.
+type ToolStat struct {
+	Tool     string
+	Calls    int
+	Failures int
+	AvgMs    int64
+	MaxMs    int64
+	LastUsed time.Time
+}
+
+// ReadToolStats aggregates TOOL_AUDIT.jsonl records newer than since,
+// sorted by call count.
+func ReadToolStats(workspaceDir string, since time.Time) ([]ToolStat, error) {
+	f, err := os.Open(filepath.Join(workspaceDir, ToolAuditFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	byTool := map[string]*ToolStat{}
+	totals := map[string]int64{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec AuditRecord
+		if json.Unmarshal(scanner.Bytes(), &rec) != nil || rec.Time.Before(since) {
+			continue
+		}
+		s, ok := byTool[rec.Tool]
+		if !ok {
+			s = &ToolStat{Tool: rec.Tool}
+			byTool[rec.Tool] = s
+		}
+		s.Calls++
+		if !rec.OK {
+			s.Failures++
+		}
+		totals[rec.Tool] += rec.DurationMs
+		if rec.DurationMs > s.MaxMs {
+			s.MaxMs = rec.DurationMs
+		}
+		if rec.Time.After(s.LastUsed) {
+			s.LastUsed = rec.Time
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	stats := make([]ToolStat, 0, len(byTool))
+	for name, s := range byTool {
+		s.AvgMs = totals[name] / int64(s.Calls)
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Calls != stats[j].Calls {
+			return stats[i].Calls > stats[j].Calls
+		}
+		return stats[i].Tool < stats[j].Tool
+	})
+	return stats, nil
+}
+
+// FormatToolStats renders stats as a compact table.
+func FormatToolStats(stats []ToolStat) string {
+	if len(stats) == 0 {
+		return "No tool calls recorded in this period."
+	}
+	var sb strings.Builder
+	calls, failures := 0, 0
+	sb.WriteString("tool | calls | failed | avg ms | max ms | last used\n")
+	for _, s := range stats {
+		calls += s.Calls
+		failures += s.Failures
+		sb.WriteString(fmt.Sprintf("%s | %d | %d | %d | %d | %s\n", s.Tool, s.Calls, s.Failures, s.AvgMs, s.MaxMs, s.LastUsed.Local().Format("Jan 2 15:04")))
+	}
+	sb.WriteString(fmt.Sprintf("Total: %d calls, %d failed", calls, failures))
+	return sb.String()
+}
+
+// registerToolStatsTool adds tool_stats.
+func (r *Registry) registerToolStatsTool() {
+	r.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "tool_stats",
+			Description: "Summarizes recent tool usage from the audit log: calls, failures, and durations per tool. Use when the user asks what you have been doing or which tools fail.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"description": "How many days back to include (default 7).",
+					},
+				},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *ToolResult {
+		days := 7
+		if d, ok := args["days"].(float64); ok && d > 0 {
+			days = int(d)
+		}
+		stats, err := ReadToolStats(r.workspaceDir, time.Now().AddDate(0, 0, -days))
+		if err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error reading audit log: %v", err)}
+		}
+		return &ToolResult{ForLLM: fmt.Sprintf("Tool usage, last %d day(s):\n%s", days, FormatToolStats(stats))}
+	})
+}
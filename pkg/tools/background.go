@@ -0,0 +1,40 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// BackgroundRunner runs work detached from the tool call that started it and
+// reports the outcome to the user when it finishes.
+type BackgroundRunner interface {
+	// StartBackground starts fn in the background and returns its run ID.
+	// description is shown when listing runs and in the completion notice.
+	StartBackground(ctx context.Context, kind, description string, fn func(ctx context.Context) (string, error)) (string, error)
+}
+
+// SetBackgroundRunner enables exec's background mode.
+func (r *Registry) SetBackgroundRunner(b BackgroundRunner) {
+	r.background = b
+}
+
+// startBackgroundExec runs an already-approved command through the background
+// runner instead of waiting for it.
+func (r *Registry) startBackgroundExec(ctx context.Context, cmdStr string) *ToolResult {
+	if r.background == nil {
+		return &ToolResult{ForLLM: "Error: background commands are not available; run it without background"}
+	}
+	id, err := r.background.StartBackground(ctx, "exec", cmdStr, func(ctx context.Context) (string, error) {
+		cmd := r.command(ctx, "sh", "-c", cmdStr)
+		GracefulCancel(cmd)
+		start := time.Now()
+		output, err := cmd.CombinedOutput()
+		r.publishCommand(ctx, "background", cmdStr, start, err)
+		return string(output), err
+	})
+	if err != nil {
+		return &ToolResult{ForLLM: fmt.Sprintf("Error: %v", err)}
+	}
+	return &ToolResult{ForLLM: fmt.Sprintf("Command started in the background as run %s. The user will be notified with its output when it finishes; check on it with list_background_runs or stop it with cancel_run. Tell the user it is running.", id)}
+}
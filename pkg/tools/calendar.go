@@ -0,0 +1,266 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"littleclaw/pkg/calendar"
+	"littleclaw/pkg/providers"
+)
+
+// calendarTimeLayouts are the formats accepted for start/end/from/to arguments.
+var calendarTimeLayouts = []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02"}
+
+// parseCalendarTime parses a tool argument in local time. The bool reports a
+// date-only value.
+func parseCalendarTime(s string) (time.Time, bool, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range calendarTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, layout == "2006-01-02", nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("cannot parse time %q (use YYYY-MM-DD or YYYY-MM-DD HH:MM)", s)
+}
+
+// calendarRange reads from/to arguments, defaulting to the next days days starting today.
+func calendarRange(args map[string]interface{}, days int) (time.Time, time.Time, error) {
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if s, _ := args["from"].(string); s != "" {
+		t, _, err := parseCalendarTime(s)
+		if err != nil {
+			return from, from, err
+		}
+		from = t
+	}
+	to := from.AddDate(0, 0, days)
+	if s, _ := args["to"].(string); s != "" {
+		t, dateOnly, err := parseCalendarTime(s)
+		if err != nil {
+			return from, to, err
+		}
+		// A date-only end includes that whole day
+		if dateOnly {
+			t = t.AddDate(0, 0, 1)
+		}
+		to = t
+	}
+	if !to.After(from) {
+		return from, to, fmt.Errorf("'to' must be after 'from'")
+	}
+	return from, to, nil
+}
+
+// SetCalendar connects a calendar backend and registers list_events,
+// create_event, and find_free_slot. Calling it again swaps the backend.
+func (r *Registry) SetCalendar(c calendar.Client) {
+	first := r.calendar == nil
+	r.calendar = c
+	if first && c != nil {
+		r.registerCalendarTools()
+	}
+}
+
+func (r *Registry) registerCalendarTools() {
+	// list_events
+	r.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "list_events",
+			Description: "Lists the user's calendar events in a time range (default: today). Use for agenda questions like 'what's on tomorrow?'. Times are local; pass YYYY-MM-DD or YYYY-MM-DD HH:MM.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"from": map[string]interface{}{
+						"type":        "string",
+						"description": "Start of the range (default: start of today).",
+					},
+					"to": map[string]interface{}{
+						"type":        "string",
+						"description": "End of the range; a bare date includes that whole day (default: one day after from).",
+					},
+				},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *ToolResult {
+		from, to, err := calendarRange(args, 1)
+		if err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error: %v", err)}
+		}
+		events, err := r.calendar.ListEvents(ctx, from, to)
+		if err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error listing events: %v", err)}
+		}
+		return &ToolResult{ForLLM: calendar.FormatEvents(events, time.Local)}
+	})
+
+	// create_event
+	r.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "create_event",
+			Description: "Creates an event in the user's calendar. Confirm the details with the user first if anything is ambiguous. A date-only start creates an all-day event.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"title": map[string]interface{}{
+						"type":        "string",
+						"description": "Event title.",
+					},
+					"start": map[string]interface{}{
+						"type":        "string",
+						"description": "Start time, YYYY-MM-DD HH:MM (local) or YYYY-MM-DD for all-day.",
+					},
+					"end": map[string]interface{}{
+						"type":        "string",
+						"description": "End time (default: start + duration_minutes).",
+					},
+					"duration_minutes": map[string]interface{}{
+						"type":        "integer",
+						"description": "Length of the event when end is omitted (default 60).",
+					},
+					"location": map[string]interface{}{
+						"type":        "string",
+						"description": "Optional location.",
+					},
+					"description": map[string]interface{}{
+						"type":        "string",
+						"description": "Optional notes.",
+					},
+				},
+				"required": []string{"title", "start"},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *ToolResult {
+		title, _ := args["title"].(string)
+		startStr, _ := args["start"].(string)
+		if title == "" || startStr == "" {
+			return &ToolResult{ForLLM: "Error: title and start are required"}
+		}
+		start, allDay, err := parseCalendarTime(startStr)
+		if err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error: %v", err)}
+		}
+
+		var end time.Time
+		if s, _ := args["end"].(string); s != "" {
+			if end, _, err = parseCalendarTime(s); err != nil {
+				return &ToolResult{ForLLM: fmt.Sprintf("Error: %v", err)}
+			}
+		} else if allDay {
+			end = start.AddDate(0, 0, 1)
+		} else {
+			minutes := 60
+			if m, ok := args["duration_minutes"].(float64); ok && m > 0 {
+				minutes = int(m)
+			}
+			end = start.Add(time.Duration(minutes) * time.Minute)
+		}
+		if !end.After(start) {
+			return &ToolResult{ForLLM: "Error: end must be after start"}
+		}
+
+		location, _ := args["location"].(string)
+		description, _ := args["description"].(string)
+		ev, err := r.calendar.CreateEvent(ctx, calendar.Event{
+			Title: title, Start: start, End: end, AllDay: allDay,
+			Location: location, Description: description,
+		})
+		if err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error creating event: %v", err)}
+		}
+		return &ToolResult{ForLLM: "Created: " + calendar.FormatEvents([]calendar.Event{ev}, time.Local)}
+	})
+
+	// find_free_slot
+	r.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "find_free_slot",
+			Description: "Finds free time in the user's calendar of at least the given length, within working hours. Defaults to the next 7 days, 09:00-18:00.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"duration_minutes": map[string]interface{}{
+						"type":        "integer",
+						"description": "Minimum length of the slot in minutes.",
+					},
+					"from": map[string]interface{}{
+						"type":        "string",
+						"description": "Start of the search range (default: now).",
+					},
+					"to": map[string]interface{}{
+						"type":        "string",
+						"description": "End of the search range (default: 7 days after from).",
+					},
+					"day_start_hour": map[string]interface{}{
+						"type":        "integer",
+						"description": "Earliest hour of the day to consider (default 9).",
+					},
+					"day_end_hour": map[string]interface{}{
+						"type":        "integer",
+						"description": "Latest hour of the day to consider (default 18).",
+					},
+				},
+				"required": []string{"duration_minutes"},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *ToolResult {
+		minutes, _ := args["duration_minutes"].(float64)
+		if minutes <= 0 {
+			return &ToolResult{ForLLM: "Error: duration_minutes must be positive"}
+		}
+		if _, ok := args["from"]; !ok {
+			args["from"] = time.Now().Format(time.RFC3339)
+		}
+		from, to, err := calendarRange(args, 7)
+		if err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error: %v", err)}
+		}
+		dayStart, dayEnd := 9, 18
+		if h, ok := args["day_start_hour"].(float64); ok {
+			dayStart = int(h)
+		}
+		if h, ok := args["day_end_hour"].(float64); ok {
+			dayEnd = int(h)
+		}
+		if dayStart < 0 || dayEnd > 24 || dayStart >= dayEnd {
+			return &ToolResult{ForLLM: "Error: working hours must satisfy 0 <= day_start_hour < day_end_hour <= 24"}
+		}
+
+		events, err := r.calendar.ListEvents(ctx, from, to)
+		if err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error listing events: %v", err)}
+		}
+		slots := calendar.FindFreeSlots(events, from, to, time.Duration(minutes)*time.Minute, dayStart, dayEnd)
+		if len(slots) == 0 {
+			return &ToolResult{ForLLM: "No free slot of that length in the range."}
+		}
+
+		const maxSlots = 10
+		var sb strings.Builder
+		for i, s := range slots {
+			if i == maxSlots {
+				sb.WriteString(fmt.Sprintf("...and %d more\n", len(slots)-maxSlots))
+				break
+			}
+			sb.WriteString(fmt.Sprintf("%s-%s\n", s.Start.Format("Mon Jan 2 15:04"), s.End.Format("15:04")))
+		}
+		return &ToolResult{ForLLM: strings.TrimSpace(sb.String())}
+	})
+}
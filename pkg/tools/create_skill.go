@@ -0,0 +1,191 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"littleclaw/pkg/providers"
+	"littleclaw/pkg/workspace"
+)
+
+const skillSyntaxCheckTimeout = 15 * time.Second
+
+var skillNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,47}$`)
+
+// skillLanguages maps create_skill languages to file extension and shebang.
+var skillLanguages = map[string]struct{ ext, shebang string }{
+	"sh":     {".sh", "#!/bin/sh"},
+	"python": {".py", "#!/usr/bin/env python3"},
+}
+
+// checkSkillSyntax parses the script without running it (sh -n / python compile).
+func checkSkillSyntax(ctx context.Context, language, path string) error {
+	ctx, cancel := context.WithTimeout(ctx, skillSyntaxCheckTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if language == "python" {
+		// Same check as py_compile, without leaving a __pycache__ behind
+		cmd = exec.CommandContext(ctx, "python3", "-c", "import sys; compile(open(sys.argv[1]).read(), sys.argv[1], 'exec')", path)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-n", path)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%v\n%s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// registerCreateSkillTool adds create_skill, which writes, validates, and
+// registers a skill in one step.
+func (r *Registry) registerCreateSkillTool() {
+	r.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "create_skill",
+			Description: "Creates a new skill (a script exposed as a tool), syntax-checks it, makes it executable, and registers it immediately. Prefer this over write_file + reload_skills. Declare typed arguments with a '# ---' frontmatter header right after the shebang. Use dry_run to validate without saving.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"name": map[string]interface{}{
+						"type":        "string",
+						"description": "Tool name: lowercase letters, digits, and underscores (e.g. 'check_disk').",
+					},
+					"language": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"sh", "python"},
+						"description": "Script language.",
+					},
+					"code": map[string]interface{}{
+						"type":        "string",
+						"description": "Full script source. A shebang is added if missing.",
+					},
+					"description": map[string]interface{}{
+						"type":        "string",
+						"description": "What the skill does; used as the tool description unless the frontmatter sets one.",
+					},
+					"overwrite": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Replace an existing skill with the same name.",
+					},
+					"dry_run": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Only validate; do not save or register.",
+					},
+				},
+				"required": []string{"name", "language", "code"},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *ToolResult {
+		name, _ := args["name"].(string)
+		language, _ := args["language"].(string)
+		code, _ := args["code"].(string)
+		description, _ := args["description"].(string)
+		overwrite, _ := args["overwrite"].(bool)
+		dryRun, _ := args["dry_run"].(bool)
+
+		if !skillNamePattern.MatchString(name) {
+			return &ToolResult{ForLLM: "Error: name must start with a lowercase letter and contain only lowercase letters, digits, and underscores"}
+		}
+		lang, ok := skillLanguages[language]
+		if !ok {
+			return &ToolResult{ForLLM: "Error: language must be 'sh' or 'python'"}
+		}
+		if strings.TrimSpace(code) == "" {
+			return &ToolResult{ForLLM: "Error: code is required"}
+		}
+
+		skillsDir := filepath.Join(r.workspaceDir, "skills")
+		existing := ""
+		for _, ext := range []string{".sh", ".py"} {
+			if _, err := os.Stat(filepath.Join(skillsDir, name+ext)); err == nil {
+				existing = name + ext
+			}
+		}
+		if _, taken := r.lookupTool(name); taken && existing == "" {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error: %q is already a built-in tool name", name)}
+		}
+		if existing != "" && !overwrite {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error: skill %q already exists (skills/%s); pass overwrite=true to replace it", name, existing)}
+		}
+
+		if !strings.HasPrefix(code, "#!") {
+			code = lang.shebang + "\n" + code
+		}
+		if !strings.HasSuffix(code, "\n") {
+			code += "\n"
+		}
+
+		meta, err := ParseSkillMeta(code)
+		if err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error: invalid frontmatter: %v", err)}
+		}
+		if err := r.policy().CheckScript(code); err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Skill blocked by exec policy: %v", err)}
+		}
+
+		// Validate from a temp file so a broken script never replaces a working one
+		if err := os.MkdirAll(skillsDir, 0755); err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error creating skills directory: %v", err)}
+		}
+		tmp, err := os.CreateTemp(skillsDir, "."+name+"-*"+lang.ext+".tmp")
+		if err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error writing skill: %v", err)}
+		}
+		tmpPath := tmp.Name()
+		defer os.Remove(tmpPath)
+		_, err = tmp.WriteString(code)
+		tmp.Close()
+		if err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error writing skill: %v", err)}
+		}
+		if err := checkSkillSyntax(ctx, language, tmpPath); err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Syntax check failed, skill not saved:\n%v", err)}
+		}
+
+		summary := "no declared params (takes a single 'args' string)"
+		if meta != nil && len(meta.Params) > 0 {
+			var names []string
+			for _, p := range meta.Params {
+				names = append(names, p.Name+":"+p.Type)
+			}
+			summary = "params: " + strings.Join(names, ", ")
+		}
+		if dryRun {
+			return &ToolResult{ForLLM: fmt.Sprintf("Dry run OK: %s%s passes syntax and policy checks (%s). Nothing was saved.", name, lang.ext, summary)}
+		}
+
+		finalPath := filepath.Join(skillsDir, name+lang.ext)
+		if err := os.Chmod(tmpPath, 0755); err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error making skill executable: %v", err)}
+		}
+		if err := os.Rename(tmpPath, finalPath); err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error saving skill: %v", err)}
+		}
+		// Switching language leaves the old script behind; drop it
+		if existing != "" && existing != name+lang.ext {
+			_ = os.Remove(filepath.Join(skillsDir, existing))
+		}
+
+		if r.wsMgr != nil && description != "" {
+			_ = r.wsMgr.TrackItem("skills", workspace.TrackedItem{
+				Name:        name,
+				File:        name + lang.ext,
+				Description: description,
+			})
+		}
+		r.registerSkill(skillsDir, name+lang.ext)
+
+		return &ToolResult{ForLLM: fmt.Sprintf("Skill '%s' saved to skills/%s%s and registered (%s). It is callable now.", name, name, lang.ext, summary)}
+	})
+}
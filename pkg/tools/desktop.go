@@ -0,0 +1,210 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"littleclaw/pkg/providers"
+)
+
+const (
+	desktopTimeout    = 15 * time.Second
+	clipboardMaxChars = 20000
+	desktopFileArg    = "{file}"
+)
+
+// desktopCommand is a CLI used for a desktop integration; args containing
+// desktopFileArg are replaced with the output path.
+type desktopCommand struct {
+	name string
+	args []string
+}
+
+// Candidate commands per platform, in order of preference. On Linux the
+// Wayland tools come first; they fail fast outside a Wayland session and the
+// next candidate is tried.
+var (
+	screenshotCommands = map[string][]desktopCommand{
+		"darwin": {{"screencapture", []string{"-x", desktopFileArg}}},
+		"linux": {
+			{"grim", []string{desktopFileArg}},
+			{"gnome-screenshot", []string{"-f", desktopFileArg}},
+			{"spectacle", []string{"-b", "-n", "-o", desktopFileArg}},
+			{"scrot", []string{desktopFileArg}},
+			{"import", []string{"-window", "root", desktopFileArg}},
+		},
+	}
+	clipboardReadCommands = map[string][]desktopCommand{
+		"darwin": {{"pbpaste", nil}},
+		"linux": {
+			{"wl-paste", []string{"--no-newline"}},
+			{"xclip", []string{"-selection", "clipboard", "-o"}},
+			{"xsel", []string{"--clipboard", "--output"}},
+		},
+	}
+	clipboardWriteCommands = map[string][]desktopCommand{
+		"darwin": {{"pbcopy", nil}},
+		"linux": {
+			{"wl-copy", nil},
+			{"xclip", []string{"-selection", "clipboard", "-i"}},
+			{"xsel", []string{"--clipboard", "--input"}},
+		},
+	}
+)
+
+// runDesktopCommand tries each installed candidate until one succeeds.
+func runDesktopCommand(ctx context.Context, candidates []desktopCommand, file string, stdin string) (string, error) {
+	if runtime.GOOS == "linux" && os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == "" {
+		return "", fmt.Errorf("no graphical session (DISPLAY and WAYLAND_DISPLAY are unset)")
+	}
+
+	var tried []string
+	var lastErr error
+	for _, c := range candidates {
+		if _, err := exec.LookPath(c.name); err != nil {
+			continue
+		}
+		args := make([]string, len(c.args))
+		for i, a := range c.args {
+			args[i] = strings.ReplaceAll(a, desktopFileArg, file)
+		}
+
+		cctx, cancel := context.WithTimeout(ctx, desktopTimeout)
+		cmd := exec.CommandContext(cctx, c.name, args...)
+		cmd.Stdin = strings.NewReader(stdin)
+		// xclip and wl-copy fork a child that keeps serving the selection
+		// and holds our output pipes open; don't wait for it
+		cmd.WaitDelay = time.Second
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout, cmd.Stderr = &stdout, &stderr
+		err := cmd.Run()
+		cancel()
+		if err == nil || errors.Is(err, exec.ErrWaitDelay) {
+			return stdout.String(), nil
+		}
+		tried = append(tried, c.name)
+		lastErr = fmt.Errorf("%s: %v %s", c.name, err, strings.TrimSpace(stderr.String()))
+	}
+
+	if lastErr != nil {
+		return "", fmt.Errorf("all available tools failed (%s); last error: %v", strings.Join(tried, ", "), lastErr)
+	}
+	var names []string
+	for _, c := range candidates {
+		names = append(names, c.name)
+	}
+	if len(names) == 0 {
+		return "", fmt.Errorf("not supported on %s", runtime.GOOS)
+	}
+	return "", fmt.Errorf("none of %s is installed", strings.Join(names, ", "))
+}
+
+// EnableDesktopTools registers take_screenshot, read_clipboard, and
+// write_clipboard. They are opt-in because they expose the host's screen and
+// clipboard, which only makes sense when Littleclaw runs on a workstation.
+func (r *Registry) EnableDesktopTools() {
+	if _, ok := r.lookupTool("take_screenshot"); ok {
+		return
+	}
+
+	// take_screenshot
+	r.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "take_screenshot",
+			Description: "Captures the host computer's screen to media/ and sends it to the user. Use analyze_image on the saved file to see what is on screen.",
+			Parameters: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *ToolResult {
+		dir := filepath.Join(r.workspaceDir, "media")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error creating media directory: %v", err)}
+		}
+		rel := filepath.Join("media", "screenshot-"+time.Now().Format("20060102-150405")+".png")
+		abs := filepath.Join(r.workspaceDir, rel)
+
+		if _, err := runDesktopCommand(ctx, screenshotCommands[runtime.GOOS], abs, ""); err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Screenshot failed: %v", err)}
+		}
+		if _, err := os.Stat(abs); err != nil {
+			return &ToolResult{ForLLM: "Screenshot failed: no image was written"}
+		}
+		return &ToolResult{ForLLM: fmt.Sprintf("Screenshot saved to %s and sent to the user.", rel), Files: []string{abs}}
+	})
+
+	// read_clipboard
+	r.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "read_clipboard",
+			Description: "Returns the text currently on the host computer's clipboard.",
+			Parameters: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *ToolResult {
+		out, err := runDesktopCommand(ctx, clipboardReadCommands[runtime.GOOS], "", "")
+		if err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Clipboard read failed: %v", err)}
+		}
+		if out == "" {
+			return &ToolResult{ForLLM: "The clipboard is empty."}
+		}
+		if len(out) > clipboardMaxChars {
+			out = out[:clipboardMaxChars] + fmt.Sprintf("\n... (truncated, %d chars total)", len(out))
+		}
+		return &ToolResult{ForLLM: out}
+	})
+
+	// write_clipboard
+	r.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "write_clipboard",
+			Description: "Replaces the host computer's clipboard with the given text, so the user can paste it.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"text": map[string]interface{}{
+						"type":        "string",
+						"description": "Text to put on the clipboard.",
+					},
+				},
+				"required": []string{"text"},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *ToolResult {
+		text, ok := args["text"].(string)
+		if !ok {
+			return &ToolResult{ForLLM: "Error: text must be a string"}
+		}
+		if _, err := runDesktopCommand(ctx, clipboardWriteCommands[runtime.GOOS], "", text); err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Clipboard write failed: %v", err)}
+		}
+		return &ToolResult{ForLLM: fmt.Sprintf("Copied %d characters to the clipboard.", len(text))}
+	})
+}
@@ -0,0 +1,297 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"littleclaw/pkg/providers"
+)
+
+// applySearchReplace applies search/replace blocks in order. Each search text
+// must occur exactly once unless replace_all is set, so an edit can never
+// land somewhere the model did not intend.
+func applySearchReplace(content string, edits []interface{}) (string, error) {
+	if len(edits) == 0 {
+		return "", fmt.Errorf("edits is empty")
+	}
+	for i, raw := range edits {
+		e, ok := raw.(map[string]interface{})
+		if !ok {
+			return "", fmt.Errorf("edit %d must be an object with search and replace", i+1)
+		}
+		search, okS := e["search"].(string)
+		replace, okR := e["replace"].(string)
+		if !okS || !okR || search == "" {
+			return "", fmt.Errorf("edit %d needs a non-empty search string and a replace string", i+1)
+		}
+		all, _ := e["replace_all"].(bool)
+
+		switch n := strings.Count(content, search); {
+		case n == 0:
+			return "", fmt.Errorf("edit %d: search text not found (it must match the file exactly, including whitespace)", i+1)
+		case n > 1 && !all:
+			return "", fmt.Errorf("edit %d: search text occurs %d times; add surrounding lines to make it unique or set replace_all", i+1, n)
+		}
+		if all {
+			content = strings.ReplaceAll(content, search, replace)
+		} else {
+			content = strings.Replace(content, search, replace, 1)
+		}
+	}
+	return content, nil
+}
+
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+type diffHunk struct {
+	oldStart int
+	old, new []string
+}
+
+// parseUnifiedDiff extracts the hunks of a single-file unified diff. File
+// headers (---/+++, diff --git, index) are ignored.
+func parseUnifiedDiff(diff string) ([]diffHunk, error) {
+	var hunks []diffHunk
+	var cur *diffHunk
+	for _, line := range strings.Split(strings.TrimRight(diff, "\n"), "\n") {
+		if m := hunkHeader.FindStringSubmatch(line); m != nil {
+			start, _ := strconv.Atoi(m[1])
+			hunks = append(hunks, diffHunk{oldStart: start})
+			cur = &hunks[len(hunks)-1]
+			continue
+		}
+		if cur == nil {
+			continue // headers before the first hunk
+		}
+		switch {
+		case strings.HasPrefix(line, "\\"):
+			// "\ No newline at end of file"
+		case strings.HasPrefix(line, "+"):
+			cur.new = append(cur.new, line[1:])
+		case strings.HasPrefix(line, "-"):
+			cur.old = append(cur.old, line[1:])
+		case strings.HasPrefix(line, " "):
+			cur.old = append(cur.old, line[1:])
+			cur.new = append(cur.new, line[1:])
+		case line == "":
+			// Blank context lines often lose their leading space in transit
+			cur.old = append(cur.old, "")
+			cur.new = append(cur.new, "")
+		default:
+			return nil, fmt.Errorf("unexpected line in hunk: %q", line)
+		}
+	}
+	if len(hunks) == 0 {
+		return nil, fmt.Errorf("no @@ hunks found")
+	}
+	return hunks, nil
+}
+
+// applyUnifiedDiff applies a unified diff to content. Hunks are located by
+// their context, starting at the stated line number and searching outward,
+// so diffs with stale line numbers still apply if the context matches.
+func applyUnifiedDiff(content, diff string) (string, error) {
+	hunks, err := parseUnifiedDiff(diff)
+	if err != nil {
+		return "", err
+	}
+
+	trailingNewline := strings.HasSuffix(content, "\n")
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if content == "" {
+		lines = nil
+	}
+
+	offset := 0
+	for i, h := range hunks {
+		want := h.oldStart - 1 + offset
+		if len(h.old) == 0 && want < 0 {
+			want = 0
+		}
+		at := findBlock(lines, h.old, want)
+		if at < 0 {
+			return "", fmt.Errorf("hunk %d (line %d) does not match the file", i+1, h.oldStart)
+		}
+		updated := make([]string, 0, len(lines)-len(h.old)+len(h.new))
+		updated = append(updated, lines[:at]...)
+		updated = append(updated, h.new...)
+		updated = append(updated, lines[at+len(h.old):]...)
+		lines = updated
+		offset += len(h.new) - len(h.old)
+	}
+
+	out := strings.Join(lines, "\n")
+	if trailingNewline || content == "" {
+		out += "\n"
+	}
+	return out, nil
+}
+
+// findBlock returns the index where block occurs in lines, preferring the
+// occurrence closest to near, or -1.
+func findBlock(lines, block []string, near int) int {
+	matches := func(at int) bool {
+		if at < 0 || at+len(block) > len(lines) {
+			return false
+		}
+		for j, l := range block {
+			if lines[at+j] != l {
+				return false
+			}
+		}
+		return true
+	}
+	for d := 0; d <= len(lines); d++ {
+		if matches(near - d) {
+			return near - d
+		}
+		if d > 0 && matches(near+d) {
+			return near + d
+		}
+	}
+	return -1
+}
+
+// writeFileAtomic replaces path with content via a temp file and rename,
+// keeping the original permissions, then reads it back to confirm.
+func writeFileAtomic(path, content string) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".edit-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	check, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("verification read failed: %w", err)
+	}
+	if string(check) != content {
+		return fmt.Errorf("verification failed: file content differs from the edit")
+	}
+	return nil
+}
+
+// lineDelta counts added and removed lines between two versions, for the summary.
+func lineDelta(before, after string) (added, removed int) {
+	count := map[string]int{}
+	for _, l := range strings.Split(before, "\n") {
+		count[l]++
+	}
+	for _, l := range strings.Split(after, "\n") {
+		if count[l] > 0 {
+			count[l]--
+		} else {
+			added++
+		}
+	}
+	for _, n := range count {
+		removed += n
+	}
+	return added, removed
+}
+
+// registerEditFileTool adds edit_file.
+func (r *Registry) registerEditFileTool() {
+	r.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name: "edit_file",
+			Description: "Edits part of an existing workspace file without rewriting it. Pass either `edits` (exact search/replace blocks) or `diff` (a unified diff). " +
+				"The change is applied atomically and verified; nothing is written if any edit fails to match. Prefer this over write_file for changes to existing files.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"path": map[string]interface{}{
+						"type":        "string",
+						"description": "Relative path to the file within the workspace.",
+					},
+					"edits": map[string]interface{}{
+						"type":        "array",
+						"description": "Search/replace blocks applied in order. Each search must match the file exactly once unless replace_all is true.",
+						"items": map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"search":      map[string]interface{}{"type": "string", "description": "Exact text to find, including whitespace."},
+								"replace":     map[string]interface{}{"type": "string", "description": "Replacement text."},
+								"replace_all": map[string]interface{}{"type": "boolean", "description": "Replace every occurrence."},
+							},
+							"required": []string{"search", "replace"},
+						},
+					},
+					"diff": map[string]interface{}{
+						"type":        "string",
+						"description": "Unified diff (with @@ hunk headers) for this one file.",
+					},
+				},
+				"required": []string{"path"},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *ToolResult {
+		p, ok := args["path"].(string)
+		if !ok {
+			return &ToolResult{ForLLM: "Error: path must be a string"}
+		}
+		edits, hasEdits := args["edits"].([]interface{})
+		diff, _ := args["diff"].(string)
+		hasDiff := strings.TrimSpace(diff) != ""
+		if hasEdits == hasDiff {
+			return &ToolResult{ForLLM: "Error: provide exactly one of edits or diff"}
+		}
+
+		safePath, err := r.resolveWorkspacePath(p)
+		if err != nil {
+			return &ToolResult{ForLLM: err.Error()}
+		}
+		data, err := os.ReadFile(safePath)
+		if err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error reading file: %v", err)}
+		}
+		before := string(data)
+
+		var after string
+		if hasDiff {
+			after, err = applyUnifiedDiff(before, diff)
+		} else {
+			after, err = applySearchReplace(before, edits)
+		}
+		if err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error: edit not applied, %v. Re-read the file and try again.", err)}
+		}
+		if after == before {
+			return &ToolResult{ForLLM: fmt.Sprintf("No changes: the edit leaves %s unchanged.", p)}
+		}
+
+		if err := writeFileAtomic(safePath, after); err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error writing file: %v", err)}
+		}
+		added, removed := lineDelta(before, after)
+		return &ToolResult{ForLLM: fmt.Sprintf("Edited %s (+%d -%d lines)", p, added, removed)}
+	})
+}
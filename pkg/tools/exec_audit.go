@@ -0,0 +1,221 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"littleclaw/pkg/events"
+	"littleclaw/pkg/redact"
+)
+
+// ExecAuditFile is the append-only log of shell commands. The daemon keeps it
+// in the profile directory, outside the workspace the file tools can reach.
+const ExecAuditFile = "EXEC_AUDIT.jsonl"
+
+// ExecRecord is one line of EXEC_AUDIT.jsonl. Unlike the tool audit log it
+// keeps the full command, so every command the bot ran can be traced back to
+// the message or job that asked for it.
+type ExecRecord struct {
+	Time        time.Time `json:"time"`
+	Source      string    `json:"source"` // exec, background, skill, git, or cron
+	Command     string    `json:"command"`
+	RequestedBy string    `json:"requested_by,omitempty"` // sender ID, or "cron:<job ID>"
+	ChatID      string    `json:"chat_id,omitempty"`
+	Channel     string    `json:"channel,omitempty"`
+	ExitCode    int       `json:"exit_code"`
+	DurationMs  int64     `json:"duration_ms"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// auditLogRe matches commands that mention an audit log. They are refused
+// whatever the exec policy says. This only stops the obvious cases (a glob
+// gets past it); the logs are protected for real by the sandbox, which
+// mounts them read-only (see AuditLogPaths).
+var auditLogRe = regexp.MustCompile(`(?i)\b(exec|tool)_audit\b`)
+
+// checkAuditLogs refuses commands that touch the audit logs.
+func checkAuditLogs(cmd string) error {
+	if auditLogRe.MatchString(cmd) || auditLogRe.MatchString(NormalizeCommand(cmd)) {
+		return errors.New("commands may not touch the audit logs")
+	}
+	return nil
+}
+
+// IsAuditLogPath reports whether abs is an audit log in the workspace root,
+// which file tools may not open.
+func IsAuditLogPath(workspaceDir, abs string) bool {
+	base := filepath.Base(abs)
+	return (base == ToolAuditFile || base == ExecAuditFile) && filepath.Clean(filepath.Dir(abs)) == filepath.Clean(workspaceDir)
+}
+
+type requesterKey struct{}
+
+// WithRequester tags ctx with the sender whose message led to the tool calls,
+// for the exec audit log.
+func WithRequester(ctx context.Context, senderID string) context.Context {
+	return context.WithValue(ctx, requesterKey{}, senderID)
+}
+
+// Requester returns the sender set by WithRequester, or "".
+func Requester(ctx context.Context) string {
+	s, _ := ctx.Value(requesterKey{}).(string)
+	return s
+}
+
+// SetExecAuditLog starts appending an ExecRecord to path for every shell
+// command run by exec, skills, and cron jobs.
+func (r *Registry) SetExecAuditLog(path string) {
+	r.execLog.mu.Lock()
+	defer r.execLog.mu.Unlock()
+	r.execLog.path = path
+}
+
+// AuditLogPaths returns the tool audit log and, once set, the exec audit
+// log, creating any that are missing so a sandbox can mount them read-only.
+// That is what keeps commands from rewriting the logs: checkAuditLogs only
+// catches commands that name them.
+func (r *Registry) AuditLogPaths() []string {
+	paths := []string{filepath.Join(r.workspaceDir, ToolAuditFile)}
+	r.execLog.mu.Lock()
+	if r.execLog.path != "" {
+		paths = append(paths, r.execLog.path)
+	}
+	r.execLog.mu.Unlock()
+	for _, p := range paths {
+		f, err := os.OpenFile(p, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			slog.Warn("audit log not protected in the sandbox", "path", p, "err", err)
+			continue
+		}
+		f.Close()
+	}
+	return paths
+}
+
+// execAuditor appends ExecRecords to a JSONL file opened in append mode. It
+// does nothing until a path is set.
+type execAuditor struct {
+	mu   sync.Mutex
+	path string
+}
+
+// onCommandExecuted records a finished shell command.
+func (a *execAuditor) onCommandExecuted(e events.CommandExecuted) {
+	data, err := json.Marshal(ExecRecord{
+		Time:        e.Time,
+		Source:      e.Source,
+		Command:     redact.String(e.Command),
+		RequestedBy: e.RequestedBy,
+		ChatID:      e.ChatID,
+		Channel:     e.Channel,
+		ExitCode:    e.ExitCode,
+		DurationMs:  e.Duration.Milliseconds(),
+		Error:       redact.String(e.Error),
+	})
+	if err != nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.path == "" {
+		return
+	}
+	f, err := os.OpenFile(a.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		slog.Warn("exec audit: failed to open log", "source", e.Source, "err", err)
+		return
+	}
+	defer f.Close()
+	_, _ = f.Write(append(data, '\n'))
+}
+
+// ExitCode returns the exit code of a command that finished with err: 0 for
+// nil, the process's code, or -1 when it did not exit on its own.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() >= 0 {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// publishCommand publishes the CommandExecuted event for a shell command
+// started at start that finished with err.
+func (r *Registry) publishCommand(ctx context.Context, source, command string, start time.Time, err error) {
+	e := events.CommandExecuted{
+		Time:        start,
+		Source:      source,
+		Command:     command,
+		RequestedBy: Requester(ctx),
+		ExitCode:    ExitCode(err),
+		Duration:    time.Since(start),
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	if c, ok := ctx.Value(callerKey{}).(caller); ok {
+		e.ChatID, e.Channel = c.chatID, c.channel
+	}
+	r.events.Publish(e)
+}
+
+// ReadExecAudit returns the last limit records of the exec audit log at path,
+// oldest first; limit 0 returns them all.
+func ReadExecAudit(path string, limit int) ([]ExecRecord, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var recs []ExecRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var rec ExecRecord
+		if json.Unmarshal(scanner.Bytes(), &rec) == nil {
+			recs = append(recs, rec)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(recs) > limit {
+		recs = recs[len(recs)-limit:]
+	}
+	return recs, nil
+}
+
+// FormatExecRecords renders exec audit records one per line.
+func FormatExecRecords(recs []ExecRecord) string {
+	if len(recs) == 0 {
+		return "No commands recorded."
+	}
+	var sb strings.Builder
+	for _, rec := range recs {
+		who := rec.RequestedBy
+		if who == "" {
+			who = "-"
+		}
+		sb.WriteString(fmt.Sprintf("%s  %-10s exit %-3d %6dms  by %s in %s:%s  %s\n",
+			rec.Time.Local().Format("Jan 2 15:04:05"), rec.Source, rec.ExitCode, rec.DurationMs, who, rec.Channel, rec.ChatID, rec.Command))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -0,0 +1,177 @@
+package tools
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultDenyPatterns are applied when no deny list is configured. They are
+// matched against each normalized command segment, so quoting or chaining
+// tricks like `r\m -rf` or `true && rm -r /` are still caught.
+var DefaultDenyPatterns = []string{
+	`\brm\s+(-\S+\s+)*(-[a-zA-Z]*[rR][a-zA-Z]*|--recursive)\b`,
+	`\bmkfs(\.\w+)?\b`,
+	`\bdd\s+.*\bif=`,
+	`:\(\)\s*\{.*\};\s*:`,
+	`>\s*/dev/(sd|hd|nvme|disk)`,
+	`^(sudo\s+)?(shutdown|reboot|halt|poweroff)\b`,
+	`\bchmod\s+(-\S+\s+)*[0-7]*777\s+/(\s|$)`,
+}
+
+// segmentSplitter splits a shell command line into the individual commands it runs.
+var segmentSplitter = regexp.MustCompile("&&|\\|\\||[;|&\n`]|\\$\\(|\\)")
+
+// ExecPolicy decides which shell commands may run. Deny patterns always win;
+// in allowlist-only mode every command segment must also match an allow pattern.
+type ExecPolicy struct {
+	allow         []*regexp.Regexp
+	deny          []*regexp.Regexp
+	allowlistOnly bool
+}
+
+// NewExecPolicy compiles an ExecPolicy. An empty deny list falls back to DefaultDenyPatterns.
+func NewExecPolicy(allow, deny []string, allowlistOnly bool) (*ExecPolicy, error) {
+	if len(deny) == 0 {
+		deny = DefaultDenyPatterns
+	}
+
+	p := &ExecPolicy{allowlistOnly: allowlistOnly}
+	var err error
+	if p.allow, err = compilePatterns(allow); err != nil {
+		return nil, fmt.Errorf("invalid allow pattern: %w", err)
+	}
+	if p.deny, err = compilePatterns(deny); err != nil {
+		return nil, fmt.Errorf("invalid deny pattern: %w", err)
+	}
+	if allowlistOnly && len(p.allow) == 0 {
+		return nil, fmt.Errorf("allowlist-only mode requires at least one allow pattern")
+	}
+	return p, nil
+}
+
+// DefaultExecPolicy returns the built-in deny-only policy.
+func DefaultExecPolicy() *ExecPolicy {
+	p, _ := NewExecPolicy(nil, nil, false)
+	return p
+}
+
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", p, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// Check returns an error describing why cmd is not allowed, or nil.
+func (p *ExecPolicy) Check(cmd string) error {
+	normalized := NormalizeCommand(cmd)
+	if normalized == "" {
+		return fmt.Errorf("empty command")
+	}
+	if err := checkAuditLogs(cmd); err != nil {
+		return err
+	}
+
+	for _, re := range p.deny {
+		if re.MatchString(cmd) || re.MatchString(normalized) {
+			return fmt.Errorf("matches deny pattern %q", re.String())
+		}
+	}
+
+	segments := CommandSegments(normalized)
+	for _, seg := range segments {
+		for _, re := range p.deny {
+			if re.MatchString(seg) {
+				return fmt.Errorf("%q matches deny pattern %q", seg, re.String())
+			}
+		}
+	}
+
+	if !p.allowlistOnly {
+		return nil
+	}
+	for _, seg := range segments {
+		if !matchesAny(p.allow, seg) {
+			return fmt.Errorf("%q is not in the allowlist", seg)
+		}
+	}
+	return nil
+}
+
+// CheckScript scans a script body line by line for denied commands. Allow
+// patterns are not applied here; in allowlist-only mode the invocation itself
+// is checked with Check.
+func (p *ExecPolicy) CheckScript(body string) error {
+	if err := checkAuditLogs(body); err != nil {
+		return fmt.Errorf("script %w", err)
+	}
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		normalized := NormalizeCommand(line)
+		for _, seg := range append([]string{normalized}, CommandSegments(normalized)...) {
+			for _, re := range p.deny {
+				if re.MatchString(seg) {
+					return fmt.Errorf("script line %q matches deny pattern %q", line, re.String())
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func matchesAny(patterns []*regexp.Regexp, s string) bool {
+	for _, re := range patterns {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeCommand strips quoting and escape characters and collapses whitespace
+// so that obfuscated commands compare equal to their plain form.
+func NormalizeCommand(cmd string) string {
+	cmd = strings.NewReplacer(`\`+"\n", " ", `\`, "", `'`, "", `"`, "").Replace(cmd)
+	return strings.Join(strings.Fields(cmd), " ")
+}
+
+// CommandSegments splits a normalized command into the individual commands
+// chained with ;, &&, ||, pipes, or substitutions.
+func CommandSegments(cmd string) []string {
+	var segments []string
+	for _, part := range segmentSplitter.Split(cmd, -1) {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			segments = append(segments, part)
+		}
+	}
+	return segments
+}
+
+// SetExecPolicy replaces the policy applied to exec, skills, and cron commands.
+// It is safe to call while tools are running.
+func (r *Registry) SetExecPolicy(p *ExecPolicy) {
+	if p == nil {
+		p = DefaultExecPolicy()
+	}
+	r.execPolicy.Store(p)
+}
+
+// policy returns the current exec policy.
+func (r *Registry) policy() *ExecPolicy {
+	return r.execPolicy.Load()
+}
+
+// CheckCommand evaluates cmd against the registry's exec policy.
+func (r *Registry) CheckCommand(cmd string) error {
+	return r.policy().Check(cmd)
+}
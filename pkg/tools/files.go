@@ -0,0 +1,350 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"littleclaw/pkg/providers"
+)
+
+const listFilesMaxEntries = 200
+
+// globToRegexp converts a workspace glob into an anchored regexp over
+// slash-separated relative paths. `*` and `?` stay within one path segment,
+// `**` spans directories ("**/*.md" matches Markdown files at any depth).
+func globToRegexp(glob string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			sb.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.Compile(sb.String())
+}
+
+// globBase returns the directory prefix of glob that contains no wildcards,
+// so listing only walks the part of the workspace that can match.
+func globBase(glob string) string {
+	dir := ""
+	for _, seg := range strings.Split(glob, "/") {
+		if strings.ContainsAny(seg, "*?") {
+			break
+		}
+		dir = filepath.Join(dir, seg)
+	}
+	if dir == glob || dir == filepath.Clean(glob) {
+		dir = filepath.Dir(dir)
+	}
+	return dir
+}
+
+// checkTreeUnprotected refuses to touch the workspace root or any tree
+// containing memory files, which only the memory tools may change.
+func (r *Registry) checkTreeUnprotected(abs string) error {
+	if filepath.Clean(abs) == filepath.Clean(r.workspaceDir) {
+		return errors.New("Error: refusing to modify the workspace root")
+	}
+	return filepath.WalkDir(abs, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if IsProtectedMemoryPath(filepath.Base(p), filepath.Dir(p)) {
+			rel, _ := filepath.Rel(r.workspaceDir, p)
+			return fmt.Errorf("Error: %s contains memory files (%s); use memory tools instead", filepath.Base(abs), rel)
+		}
+		return nil
+	})
+}
+
+// formatSize renders a byte count for listings.
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1fM", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1fK", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%dB", n)
+	}
+}
+
+// registerFileTools adds list_files, stat_file, delete_file, and move_file.
+// They resolve paths like read_file/write_file, so memory files stay off-limits.
+func (r *Registry) registerFileTools() {
+	// list_files
+	r.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "list_files",
+			Description: "Lists workspace files matching a glob, with sizes and modification times. Use instead of `exec ls`/`find`.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"glob": map[string]interface{}{
+						"type":        "string",
+						"description": "Glob relative to the workspace, e.g. 'scripts/*', '**/*.py'. `**` matches any depth. Default '*'.",
+					},
+				},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *ToolResult {
+		glob, _ := args["glob"].(string)
+		glob = strings.TrimPrefix(strings.TrimSpace(glob), "./")
+		if glob == "" {
+			glob = "*"
+		}
+		re, err := globToRegexp(glob)
+		if err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error: invalid glob: %v", err)}
+		}
+		root, err := r.resolveWorkspacePath(globBase(glob))
+		if err != nil {
+			return &ToolResult{ForLLM: err.Error()}
+		}
+
+		var lines []string
+		truncated := false
+		err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || p == root {
+				return nil
+			}
+			if d.IsDir() && d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			rel, _ := filepath.Rel(r.workspaceDir, p)
+			rel = filepath.ToSlash(rel)
+			if !re.MatchString(rel) || IsProtectedMemoryPath(d.Name(), filepath.Dir(p)) {
+				return nil
+			}
+			if len(lines) == listFilesMaxEntries {
+				truncated = true
+				return filepath.SkipAll
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			if d.IsDir() {
+				lines = append(lines, fmt.Sprintf("%s/  %s", rel, info.ModTime().Format("2006-01-02 15:04")))
+			} else {
+				lines = append(lines, fmt.Sprintf("%s  %s  %s", rel, formatSize(info.Size()), info.ModTime().Format("2006-01-02 15:04")))
+			}
+			return nil
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error listing files: %v", err)}
+		}
+		if len(lines) == 0 {
+			return &ToolResult{ForLLM: fmt.Sprintf("No files match %q.", glob)}
+		}
+		out := strings.Join(lines, "\n")
+		if truncated {
+			out += fmt.Sprintf("\n... (truncated at %d entries; use a narrower glob)", listFilesMaxEntries)
+		}
+		return &ToolResult{ForLLM: out}
+	})
+
+	// stat_file
+	r.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "stat_file",
+			Description: "Shows whether a workspace path exists, its type, size, permissions, and modification time.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"path": map[string]interface{}{
+						"type":        "string",
+						"description": "Relative path within the workspace.",
+					},
+				},
+				"required": []string{"path"},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *ToolResult {
+		p, ok := args["path"].(string)
+		if !ok {
+			return &ToolResult{ForLLM: "Error: path must be a string"}
+		}
+		safePath, err := r.resolveWorkspacePath(p)
+		if err != nil {
+			return &ToolResult{ForLLM: err.Error()}
+		}
+		info, err := os.Stat(safePath)
+		if os.IsNotExist(err) {
+			return &ToolResult{ForLLM: fmt.Sprintf("%s does not exist.", p)}
+		}
+		if err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error: %v", err)}
+		}
+
+		kind := "file"
+		size := formatSize(info.Size())
+		if info.IsDir() {
+			kind = "directory"
+			entries, _ := os.ReadDir(safePath)
+			size = fmt.Sprintf("%d entries", len(entries))
+		}
+		return &ToolResult{ForLLM: fmt.Sprintf("%s: %s, %s, mode %s, modified %s",
+			p, kind, size, info.Mode().Perm(), info.ModTime().Format("2006-01-02 15:04:05"))}
+	})
+
+	// delete_file
+	r.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "delete_file",
+			Description: "Deletes a file within the workspace. Directories require recursive=true. Memory files cannot be deleted.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"path": map[string]interface{}{
+						"type":        "string",
+						"description": "Relative path within the workspace.",
+					},
+					"recursive": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Delete a directory and everything in it.",
+					},
+				},
+				"required": []string{"path"},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *ToolResult {
+		p, ok := args["path"].(string)
+		if !ok {
+			return &ToolResult{ForLLM: "Error: path must be a string"}
+		}
+		recursive, _ := args["recursive"].(bool)
+
+		safePath, err := r.resolveWorkspacePath(p)
+		if err != nil {
+			return &ToolResult{ForLLM: err.Error()}
+		}
+		info, err := os.Lstat(safePath)
+		if err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error deleting file: %v", err)}
+		}
+		if err := r.checkTreeUnprotected(safePath); err != nil {
+			return &ToolResult{ForLLM: err.Error()}
+		}
+
+		if info.IsDir() {
+			if !recursive {
+				return &ToolResult{ForLLM: fmt.Sprintf("Error: %s is a directory; pass recursive=true to delete it", p)}
+			}
+			err = os.RemoveAll(safePath)
+		} else {
+			err = os.Remove(safePath)
+		}
+		if err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error deleting file: %v", err)}
+		}
+		return &ToolResult{ForLLM: fmt.Sprintf("Deleted %s", p)}
+	})
+
+	// move_file
+	r.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "move_file",
+			Description: "Moves or renames a file or directory within the workspace. Refuses to replace an existing destination unless overwrite=true.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"source": map[string]interface{}{
+						"type":        "string",
+						"description": "Relative path of the file or directory to move.",
+					},
+					"destination": map[string]interface{}{
+						"type":        "string",
+						"description": "Relative destination path.",
+					},
+					"overwrite": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Replace an existing destination file.",
+					},
+				},
+				"required": []string{"source", "destination"},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *ToolResult {
+		src, okSrc := args["source"].(string)
+		dst, okDst := args["destination"].(string)
+		if !okSrc || !okDst {
+			return &ToolResult{ForLLM: "Error: source and destination must be strings"}
+		}
+		overwrite, _ := args["overwrite"].(bool)
+
+		srcPath, err := r.resolveWorkspacePath(src)
+		if err != nil {
+			return &ToolResult{ForLLM: err.Error()}
+		}
+		dstPath, err := r.resolveWorkspacePath(dst)
+		if err != nil {
+			return &ToolResult{ForLLM: err.Error()}
+		}
+		if _, err := os.Lstat(srcPath); err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error moving file: %v", err)}
+		}
+		if err := r.checkTreeUnprotected(srcPath); err != nil {
+			return &ToolResult{ForLLM: err.Error()}
+		}
+		if strings.HasPrefix(dstPath+string(filepath.Separator), srcPath+string(filepath.Separator)) {
+			return &ToolResult{ForLLM: "Error: cannot move a directory into itself"}
+		}
+
+		if info, err := os.Lstat(dstPath); err == nil {
+			if info.IsDir() {
+				return &ToolResult{ForLLM: fmt.Sprintf("Error: destination %s is an existing directory; give the full target path", dst)}
+			}
+			if !overwrite {
+				return &ToolResult{ForLLM: fmt.Sprintf("Error: destination %s already exists; pass overwrite=true to replace it", dst)}
+			}
+		}
+
+		if err := os.MkdirAll(filepath.Dir(dstPath), 0755); err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error creating parent directories: %v", err)}
+		}
+		if err := os.Rename(srcPath, dstPath); err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error moving file: %v", err)}
+		}
+		return &ToolResult{ForLLM: fmt.Sprintf("Moved %s to %s", src, dst)}
+	})
+}
@@ -0,0 +1,318 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"littleclaw/pkg/providers"
+)
+
+const (
+	gitTimeout       = 2 * time.Minute
+	gitMaxDiffChars  = 6000 // patch text kept after the --stat summary
+	gitDefaultLogLen = 10
+	gitMaxLogLen     = 50
+)
+
+// gitConfigArgs go before every git command. Hooks and fsmonitor are off
+// because the agent can write .git/hooks and .git/config with the file tools,
+// and git would otherwise run whatever it put there.
+var gitConfigArgs = []string{
+	"-c", "core.pager=cat", "-c", "color.ui=never",
+	"-c", "core.hooksPath=/dev/null", "-c", "core.fsmonitor=false",
+}
+
+// runGit executes git with the given args inside dir and returns trimmed
+// combined output. It runs on the host; repositories in the workspace go
+// through Registry.runRepoGit instead.
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, gitTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", append(slices.Clone(gitConfigArgs), args...)...)
+	cmd.Dir = dir
+	// Never block waiting for credentials on a headless agent
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	out, err := cmd.CombinedOutput()
+	return strings.TrimSpace(string(out)), err
+}
+
+// runRepoGit runs git in dir, inside the workspace, the way exec runs
+// commands: under the exec policy, in the sandbox, and recorded in the exec
+// audit log.
+func (r *Registry) runRepoGit(ctx context.Context, dir string, args ...string) (string, error) {
+	line := "git " + strings.Join(args, " ")
+	// Only the subcommand: commit messages and paths are not commands
+	if err := r.CheckCommand("git " + args[0]); err != nil {
+		return "", fmt.Errorf("blocked by exec policy: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, gitTimeout)
+	defer cancel()
+
+	cmd := r.sandbox.Load().Command(ctx, r.workspaceDir, dir, "git", append(slices.Clone(gitConfigArgs), args...)...)
+	GracefulCancel(cmd)
+	// Never block waiting for credentials on a headless agent
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	start := time.Now()
+	out, err := cmd.CombinedOutput()
+	r.publishCommand(ctx, "git", line, start, err)
+	return strings.TrimSpace(string(out)), err
+}
+
+// repoNameFromURL derives a folder name from a clone URL ("https://x/y/repo.git" -> "repo").
+func repoNameFromURL(rawURL string) string {
+	rawURL = strings.TrimSuffix(strings.TrimSuffix(rawURL, "/"), ".git")
+	if i := strings.LastIndexAny(rawURL, "/:"); i >= 0 {
+		rawURL = rawURL[i+1:]
+	}
+	return rawURL
+}
+
+// isAllowedCloneURL accepts https and ssh remotes; local paths and file:// are rejected
+// so clone cannot be used to copy data from outside the workspace.
+func isAllowedCloneURL(u string) bool {
+	return strings.HasPrefix(u, "https://") ||
+		strings.HasPrefix(u, "ssh://") ||
+		(strings.HasPrefix(u, "git@") && strings.Contains(u, ":"))
+}
+
+// FormatGitStatus turns `git status --porcelain=v1 -b` output into a compact summary.
+func FormatGitStatus(porcelain string) string {
+	lines := strings.Split(porcelain, "\n")
+	if len(lines) == 0 || porcelain == "" {
+		return "Working tree clean."
+	}
+
+	var sb strings.Builder
+	if strings.HasPrefix(lines[0], "## ") {
+		sb.WriteString("Branch: " + strings.TrimPrefix(lines[0], "## ") + "\n")
+		lines = lines[1:]
+	}
+
+	var staged, modified, untracked []string
+	for _, l := range lines {
+		if len(l) < 4 {
+			continue
+		}
+		x, y, file := l[0], l[1], l[3:]
+		switch {
+		case x == '?' && y == '?':
+			untracked = append(untracked, file)
+		default:
+			if x != ' ' {
+				staged = append(staged, fmt.Sprintf("%c %s", x, file))
+			}
+			if y != ' ' {
+				modified = append(modified, fmt.Sprintf("%c %s", y, file))
+			}
+		}
+	}
+
+	if len(staged)+len(modified)+len(untracked) == 0 {
+		sb.WriteString("Working tree clean.")
+		return sb.String()
+	}
+	writeGroup := func(title string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		sb.WriteString(fmt.Sprintf("%s (%d):\n", title, len(items)))
+		for _, it := range items {
+			sb.WriteString("  " + it + "\n")
+		}
+	}
+	writeGroup("Staged", staged)
+	writeGroup("Modified", modified)
+	writeGroup("Untracked", untracked)
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// registerGitTools adds the structured git tool.
+func (r *Registry) registerGitTools() {
+	r.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "git",
+			Description: "Runs a git operation on a repository inside the workspace and returns compact output. Prefer this over exec for git. Actions: clone (needs url), status, diff, commit (needs message; stages all changes or the given paths), log.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"action": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"clone", "status", "diff", "commit", "log"},
+						"description": "The git operation to run.",
+					},
+					"repo": map[string]interface{}{
+						"type":        "string",
+						"description": "Relative path to the repository within the workspace. For clone this is the destination (default: repos/<name>).",
+					},
+					"url": map[string]interface{}{
+						"type":        "string",
+						"description": "Remote URL to clone (https://, ssh:// or git@host:path).",
+					},
+					"message": map[string]interface{}{
+						"type":        "string",
+						"description": "Commit message (commit only).",
+					},
+					"paths": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Optional paths to limit diff or to stage for commit.",
+					},
+					"staged": map[string]interface{}{
+						"type":        "boolean",
+						"description": "For diff: show staged changes instead of unstaged ones.",
+					},
+					"limit": map[string]interface{}{
+						"type":        "integer",
+						"description": "For log: number of commits to show (default 10, max 50).",
+					},
+				},
+				"required": []string{"action"},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *ToolResult {
+		action, _ := args["action"].(string)
+		repo, _ := args["repo"].(string)
+
+		var paths []string
+		if raw, ok := args["paths"].([]interface{}); ok {
+			for _, p := range raw {
+				if s, ok := p.(string); ok && s != "" {
+					paths = append(paths, s)
+				}
+			}
+		}
+
+		if action == "clone" {
+			return r.gitClone(ctx, args, repo)
+		}
+
+		if repo == "" {
+			return &ToolResult{ForLLM: "Error: repo is required for this action"}
+		}
+		dir, err := r.resolveWorkspacePath(repo)
+		if err != nil {
+			return &ToolResult{ForLLM: err.Error()}
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error: %s is not a git repository", repo)}
+		}
+
+		switch action {
+		case "status":
+			out, err := r.runRepoGit(ctx, dir, "status", "--porcelain=v1", "-b")
+			if err != nil {
+				return &ToolResult{ForLLM: fmt.Sprintf("git status failed: %v\n%s", err, out)}
+			}
+			return &ToolResult{ForLLM: FormatGitStatus(out)}
+
+		case "diff":
+			var scope []string
+			if staged, _ := args["staged"].(bool); staged {
+				scope = append(scope, "--cached")
+			}
+			if len(paths) > 0 {
+				scope = append(append(scope, "--"), paths...)
+			}
+			stat, err := r.runRepoGit(ctx, dir, append([]string{"diff", "--stat"}, scope...)...)
+			if err != nil {
+				return &ToolResult{ForLLM: fmt.Sprintf("git diff failed: %v\n%s", err, stat)}
+			}
+			if stat == "" {
+				return &ToolResult{ForLLM: "No changes."}
+			}
+			patch, _ := r.runRepoGit(ctx, dir, append([]string{"diff", "--unified=2"}, scope...)...)
+			if len(patch) > gitMaxDiffChars {
+				patch = patch[:gitMaxDiffChars] + "\n...(diff truncated; pass paths to narrow it down)"
+			}
+			return &ToolResult{ForLLM: stat + "\n\n" + patch}
+
+		case "commit":
+			message, _ := args["message"].(string)
+			if strings.TrimSpace(message) == "" {
+				return &ToolResult{ForLLM: "Error: message is required for commit"}
+			}
+			addArgs := []string{"add", "-A"}
+			if len(paths) > 0 {
+				addArgs = append([]string{"add", "--"}, paths...)
+			}
+			if out, err := r.runRepoGit(ctx, dir, addArgs...); err != nil {
+				return &ToolResult{ForLLM: fmt.Sprintf("git add failed: %v\n%s", err, out)}
+			}
+			out, err := r.runRepoGit(ctx, dir, "commit", "--no-verify", "-m", message)
+			if err != nil {
+				return &ToolResult{ForLLM: fmt.Sprintf("git commit failed: %v\n%s", err, out)}
+			}
+			// First line is "[branch hash] message", second is the change summary
+			lines := strings.SplitN(out, "\n", 3)
+			if len(lines) > 2 {
+				lines = lines[:2]
+			}
+			return &ToolResult{ForLLM: "Committed: " + strings.Join(lines, " | ")}
+
+		case "log":
+			limit := gitDefaultLogLen
+			if l, ok := args["limit"].(float64); ok && l > 0 {
+				limit = int(l)
+			}
+			if limit > gitMaxLogLen {
+				limit = gitMaxLogLen
+			}
+			out, err := r.runRepoGit(ctx, dir, "log", fmt.Sprintf("-n%d", limit), "--date=short", "--pretty=format:%h %ad %an: %s")
+			if err != nil {
+				return &ToolResult{ForLLM: fmt.Sprintf("git log failed: %v\n%s", err, out)}
+			}
+			if out == "" {
+				return &ToolResult{ForLLM: "No commits yet."}
+			}
+			return &ToolResult{ForLLM: out}
+		}
+
+		return &ToolResult{ForLLM: fmt.Sprintf("Error: unknown git action %q", action)}
+	})
+}
+
+// gitClone clones a remote into the workspace.
+func (r *Registry) gitClone(ctx context.Context, args map[string]interface{}, dest string) *ToolResult {
+	url, _ := args["url"].(string)
+	if !isAllowedCloneURL(url) {
+		return &ToolResult{ForLLM: "Error: url must be an https://, ssh:// or git@host:path remote"}
+	}
+	if dest == "" {
+		name := repoNameFromURL(url)
+		if name == "" {
+			return &ToolResult{ForLLM: "Error: could not derive a folder name from url; pass repo"}
+		}
+		dest = path.Join("repos", name)
+	}
+
+	dir, err := r.resolveWorkspacePath(dest)
+	if err != nil {
+		return &ToolResult{ForLLM: err.Error()}
+	}
+	if _, err := os.Stat(dir); err == nil {
+		return &ToolResult{ForLLM: fmt.Sprintf("Error: %s already exists", dest)}
+	}
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return &ToolResult{ForLLM: fmt.Sprintf("Error creating parent directories: %v", err)}
+	}
+
+	if out, err := r.runRepoGit(ctx, r.workspaceDir, "clone", "--depth", "50", url, dir); err != nil {
+		return &ToolResult{ForLLM: fmt.Sprintf("git clone failed: %v\n%s", err, out)}
+	}
+	return &ToolResult{ForLLM: fmt.Sprintf("Cloned %s into %s", url, dest)}
+}
@@ -0,0 +1,101 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"littleclaw/pkg/providers"
+)
+
+// UserRole is the permission tier of the user a run acts for.
+type UserRole string
+
+const (
+	// RoleAdmin may use every tool. Runs without a role (cron jobs, the
+	// heartbeat, the CLI) act as the admin.
+	RoleAdmin UserRole = "admin"
+	// RoleStandard may chat and use the safe tools: reading, searching, and
+	// their own tasks, feeds, and calendar events.
+	RoleStandard UserRole = "standard"
+	// RoleReadOnly may chat and use tools that change nothing.
+	RoleReadOnly UserRole = "read_only"
+)
+
+// ParseUserRole parses a role name from the config.
+func ParseUserRole(s string) (UserRole, error) {
+	switch r := UserRole(strings.ToLower(strings.TrimSpace(s))); r {
+	case RoleAdmin, RoleStandard, RoleReadOnly:
+		return r, nil
+	case "readonly", "read-only":
+		return RoleReadOnly, nil
+	}
+	return "", fmt.Errorf("unknown role %q (want admin, standard, or read_only)", s)
+}
+
+// readOnlyTools change nothing and are open to every role.
+var readOnlyTools = map[string]bool{
+	"read_file": true, "list_files": true, "stat_file": true,
+	"web_fetch": true, "web_search": true, "get_weather": true, "analyze_image": true,
+	"read_core_memory": true, "read_entity": true, "list_entities": true, "search_history": true, "semantic_search": true,
+	"list_events": true, "find_free_slot": true,
+	"list_cron": true, "cron_history": true, "list_feeds": true, "list_tasks": true, "list_watches": true,
+	"list_subagents": true, "list_background_runs": true,
+	"list_workspace": true, "list_tracked": true, "get_tracker_json": true,
+	"tool_stats": true, "usage_report": true, "list_models": true,
+}
+
+// standardTools are the tools standard users get on top of readOnlyTools.
+// Everything else (exec, skills, file writes, cron, memory, persona,
+// broadcast, sub-agents, plugins) is admin-only.
+var standardTools = map[string]bool{
+	"clear_session": true, "send_telegram_file": true,
+	"add_task": true, "complete_task": true,
+	"subscribe_feed": true, "unsubscribe_feed": true, "create_event": true,
+	"create_workspace_folder": true, "track_item": true,
+}
+
+// Allows reports whether role may use the named tool. Tools not listed as
+// safe, including skills and plugins, are admin-only.
+func (role UserRole) Allows(tool string) bool {
+	switch role {
+	case RoleAdmin, "":
+		return true
+	case RoleStandard:
+		return readOnlyTools[tool] || standardTools[tool]
+	case RoleReadOnly:
+		return readOnlyTools[tool]
+	}
+	return false
+}
+
+// AllowedDefinitions returns the definitions in defs role may use, so the
+// model is not offered tools it would be refused.
+func AllowedDefinitions(role UserRole, defs []providers.ToolDefinition) []providers.ToolDefinition {
+	if role == RoleAdmin || role == "" {
+		return defs
+	}
+	var out []providers.ToolDefinition
+	for _, def := range defs {
+		if role.Allows(def.Function.Name) {
+			out = append(out, def)
+		}
+	}
+	return out
+}
+
+type roleKey struct{}
+
+// WithUserRole tags ctx with the role of the user the run acts for;
+// Registry.Execute refuses tools the role does not allow.
+func WithUserRole(ctx context.Context, role UserRole) context.Context {
+	return context.WithValue(ctx, roleKey{}, role)
+}
+
+// UserRoleFrom returns the role set by WithUserRole, or RoleAdmin.
+func UserRoleFrom(ctx context.Context) UserRole {
+	if role, ok := ctx.Value(roleKey{}).(UserRole); ok && role != "" {
+		return role
+	}
+	return RoleAdmin
+}
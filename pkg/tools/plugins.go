@@ -0,0 +1,186 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"littleclaw/pkg/providers"
+)
+
+// PluginProtocolVersion is the skill plugin protocol spoken by this build.
+//
+// A plugin is any executable in skills/bin/. It is run once per operation:
+//
+//	<plugin> describe
+//	    stdout: {"protocol": 1, "tools": [{"name": "...", "description": "...", "parameters": {JSON schema}}]}
+//
+//	<plugin> invoke            (stdin: {"tool": "name", "args": {...}})
+//	    stdout: {"result": "text for the model", "error": "optional", "files": ["optional/workspace/paths"]}
+//
+// Plugins run with the workspace as working directory and
+// LITTLECLAW_WORKSPACE set. Anything on stderr is returned with failures.
+const PluginProtocolVersion = 1
+
+const (
+	pluginDescribeTimeout = 10 * time.Second
+	pluginMaxOutput       = 1 << 20
+)
+
+type pluginTool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Parameters  map[string]interface{} `json:"parameters"`
+}
+
+type pluginDescription struct {
+	Protocol int          `json:"protocol"`
+	Tools    []pluginTool `json:"tools"`
+}
+
+type pluginRequest struct {
+	Tool string                 `json:"tool"`
+	Args map[string]interface{} `json:"args"`
+}
+
+type pluginResponse struct {
+	Result string   `json:"result"`
+	Error  string   `json:"error,omitempty"`
+	Files  []string `json:"files,omitempty"`
+}
+
+// runPlugin executes a plugin subcommand and returns its stdout.
+func (r *Registry) runPlugin(ctx context.Context, path, subcommand string, stdin []byte) ([]byte, error) {
+	cmd := r.command(ctx, path, subcommand)
+	cmd.Env = append(os.Environ(), "LITTLECLAW_WORKSPACE="+r.workspaceDir)
+	cmd.Stdin = bytes.NewReader(stdin)
+	GracefulCancel(cmd)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if stdout.Len() > pluginMaxOutput {
+		return nil, fmt.Errorf("plugin output exceeds %d bytes", pluginMaxOutput)
+	}
+	return stdout.Bytes(), nil
+}
+
+// LoadPlugins registers the tools exposed by every executable in skills/bin/
+// and returns their names. Tools that would shadow an existing non-plugin
+// tool are skipped.
+func (r *Registry) LoadPlugins() []string {
+	binDir := filepath.Join(r.workspaceDir, "skills", "bin")
+	entries, err := os.ReadDir(binDir)
+	if err != nil {
+		return nil
+	}
+
+	var loaded []string
+
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || entry.IsDir() || info.Mode()&0111 == 0 {
+			continue
+		}
+		path := filepath.Join(binDir, entry.Name())
+		tools, err := r.loadPlugin(path)
+		if err != nil {
+			slog.Warn("plugin not loaded", "plugin", entry.Name(), "err", err)
+		}
+		loaded = append(loaded, tools...)
+	}
+	return loaded
+}
+
+func (r *Registry) loadPlugin(path string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), pluginDescribeTimeout)
+	defer cancel()
+
+	out, err := r.runPlugin(ctx, path, "describe", nil)
+	if err != nil {
+		return nil, fmt.Errorf("describe failed: %w", err)
+	}
+	var desc pluginDescription
+	if err := json.Unmarshal(out, &desc); err != nil {
+		return nil, fmt.Errorf("invalid describe output: %w", err)
+	}
+	if desc.Protocol != PluginProtocolVersion {
+		return nil, fmt.Errorf("plugin speaks protocol %d, expected %d", desc.Protocol, PluginProtocolVersion)
+	}
+
+	plugin := filepath.Base(path)
+	var loaded []string
+	for _, t := range desc.Tools {
+		if !skillNamePattern.MatchString(t.Name) {
+			slog.Warn("plugin: skipping tool with invalid name", "plugin", plugin, "tool", t.Name)
+			continue
+		}
+		params := t.Parameters
+		if params == nil {
+			params = map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+		}
+
+		def := providers.ToolDefinition{Type: "function"}
+		def.Function.Name = t.Name
+		def.Function.Description = t.Description
+		def.Function.Parameters = params
+
+		if !r.registerSkillTool(def, r.pluginHandler(path, t.Name), path) {
+			slog.Warn("plugin: tool already exists, skipping", "plugin", plugin, "tool", t.Name)
+			continue
+		}
+		loaded = append(loaded, t.Name)
+		slog.Debug("registered plugin tool", "plugin", plugin, "tool", t.Name)
+	}
+	return loaded, nil
+}
+
+func (r *Registry) pluginHandler(path, tool string) Handler {
+	plugin := filepath.Base(path)
+	return func(ctx context.Context, args map[string]interface{}) *ToolResult {
+		if args == nil {
+			args = map[string]interface{}{}
+		}
+		input, err := json.Marshal(pluginRequest{Tool: tool, Args: args})
+		if err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error encoding arguments: %v", err)}
+		}
+
+		if err := r.policy().Check(fmt.Sprintf("skills/bin/%s invoke %s", plugin, tool)); err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Plugin blocked by exec policy: %v", err)}
+		}
+		if held := r.CheckApproval(ctx, tool, fmt.Sprintf("%s %s %s", plugin, tool, input)); held != nil {
+			return held
+		}
+
+		out, err := r.runPlugin(ctx, path, "invoke", input)
+		if err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Plugin failed: %v", err)}
+		}
+		var resp pluginResponse
+		if err := json.Unmarshal(out, &resp); err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Plugin failed: invalid response: %v", err)}
+		}
+		if resp.Error != "" {
+			return &ToolResult{ForLLM: "Error: " + resp.Error}
+		}
+
+		result := &ToolResult{ForLLM: resp.Result}
+		for _, f := range resp.Files {
+			abs, err := r.resolveWorkspacePath(f)
+			if err != nil {
+				return &ToolResult{ForLLM: fmt.Sprintf("Plugin failed: returned file %q: %v", f, err)}
+			}
+			result.Files = append(result.Files, abs)
+		}
+		return result
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package tools
+
+import "os/exec"
+
+// startProcessGroup does nothing on this platform.
+func startProcessGroup(*exec.Cmd) {}
+
+// terminateProcessGroup kills cmd; its children are left running.
+func terminateProcessGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
@@ -0,0 +1,29 @@
+//go:build unix
+
+package tools
+
+import (
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// startProcessGroup runs cmd in a process group of its own, so its children
+// can be stopped with it.
+func startProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// terminateProcessGroup sends SIGTERM to cmd's process group and SIGKILL
+// after processGracePeriod. cmd.WaitDelay only kills cmd itself, so without
+// the second signal a child that ignores SIGTERM would outlive it.
+func terminateProcessGroup(cmd *exec.Cmd) error {
+	pgid := cmd.Process.Pid
+	if err := syscall.Kill(-pgid, syscall.SIGTERM); err != nil {
+		return err
+	}
+	time.AfterFunc(processGracePeriod, func() {
+		_ = syscall.Kill(-pgid, syscall.SIGKILL) // ESRCH once the group is gone
+	})
+	return nil
+}
@@ -2,15 +2,27 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"reflect"
 	"regexp"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"littleclaw/pkg/calendar"
+	"littleclaw/pkg/events"
 	"littleclaw/pkg/memory"
 	"littleclaw/pkg/providers"
+	"littleclaw/pkg/redact"
+	"littleclaw/pkg/telemetry"
+	"littleclaw/pkg/weather"
 	"littleclaw/pkg/workspace"
 )
 
@@ -19,6 +31,9 @@ type ToolResult struct {
 	ForLLM  string   // Sent back to the language model
 	ForUser string   // (Optional) Sent directly to the user
 	Files   []string // (Optional) Absolute paths of files to attach to the user response
+	// TempFiles (optional) are deleted once the response is delivered, e.g.
+	// copies of Files made only for sending (see bus.OutboundMessage.TempFiles)
+	TempFiles []string
 }
 
 // Handler handles the execution of a specific tool.
@@ -30,8 +45,40 @@ type Registry struct {
 	memoryStore  *memory.Store      // Optional reference to memory store
 	wsMgr        *workspace.Manager // Structured workspace manager
 	tavilyAPIKey string             // Optional Tavily API key for web_search
+	toolsMu      sync.RWMutex       // guards definitions, handlers, and skillTools; reload_skills changes them mid-run
 	definitions  []providers.ToolDefinition
 	handlers     map[string]Handler
+	execPolicy   atomic.Pointer[ExecPolicy] // allow/deny rules for exec, skills, and cron commands
+	sandbox      atomic.Pointer[Sandbox]    // OS sandbox for exec, skills, and plugins; nil runs them directly
+
+	// Optional human-in-the-loop gate for risky commands (see approval.go)
+	approver     Approver
+	riskPatterns []*regexp.Regexp
+
+	sqlConns map[string]SQLConnection // named databases for sql_query (see sql.go)
+	calendar calendar.Client          // optional backend for the calendar tools
+	weather  weather.Provider         // backend for get_weather (Open-Meteo by default)
+	auditor  *toolAuditor             // writes TOOL_AUDIT.jsonl (see audit.go)
+	execLog  *execAuditor             // writes EXEC_AUDIT.jsonl once SetExecAuditLog is called
+	events   *events.Bus              // gets a ToolExecuted for every call; the auditors subscribe
+
+	skillTools map[string]string // tool name -> skills/ script or skills/bin executable defining it
+	skillsMu   sync.Mutex        // serializes LoadSkills
+	background BackgroundRunner  // optional runner for exec's background mode (see background.go)
+	fileLinker FileLinker        // optional download links for send_telegram_file (see send_file.go)
+
+	// Optional vision model for analyze_image (see vision.go)
+	visionProvider providers.Provider
+	visionModel    string
+
+	// Per-call timeouts (see timeout.go)
+	defaultTimeout time.Duration
+	toolTimeouts   map[string]time.Duration
+
+	// Dynamic tool selection (see tool_select.go)
+	toolGroups    map[string]string
+	groupKeywords map[string][]string
+	maxTools      int
 }
 
 // NewRegistry initializes a tool registry configured for the given workspace.
@@ -43,33 +90,99 @@ func NewRegistry(workspaceDir string, mem *memory.Store, wsMgr *workspace.Manage
 		tavilyAPIKey: tavilyAPIKey,
 		definitions:  []providers.ToolDefinition{},
 		handlers:     make(map[string]Handler),
+		skillTools:   make(map[string]string),
+		auditor:      &toolAuditor{path: filepath.Join(workspaceDir, ToolAuditFile)},
+		execLog:      &execAuditor{},
+		events:       events.New(),
+		weather:      weather.NewOpenMeteo("metric"),
 	}
+	events.Subscribe(r.events, r.auditor.onToolExecuted)
+	events.Subscribe(r.events, r.execLog.onCommandExecuted)
+
+	r.execPolicy.Store(DefaultExecPolicy())
 
 	// Register default sandbox tools
 	r.registerCoreTools()
 
+	// Register list/stat/delete/move file tools
+	r.registerFileTools()
+
+	// Register edit_file (search/replace or unified diff)
+	r.registerEditFileTool()
+
+	// Register compress/extract (zip, tar, tar.gz)
+	r.registerArchiveTools()
+
 	// Register web tools (web_fetch always available; web_search needs Tavily key)
 	r.registerWebTools()
 
+	// Register get_weather (keyless by default)
+	r.registerWeatherTool()
+
+	// Register the structured git tool
+	r.registerGitTools()
+
+	// Register create_skill (write + validate + register in one step)
+	r.registerCreateSkillTool()
+
+	// Register install_skill_pack (shared skills from git)
+	r.registerSkillPackTool()
+
+	// Register tool_stats (reads the audit log)
+	r.registerToolStatsTool()
+
 	// Load dynamic skills
 	r.LoadSkills()
 
 	return r
 }
 
-func (r *Registry) LoadSkills() {
+// SkillReload lists what a LoadSkills call changed.
+type SkillReload struct {
+	Added   []string // tools registered for the first time
+	Updated []string // tools whose definition changed
+	Removed []string // tools whose script or plugin is gone
+	Total   int      // skill and plugin tools registered now
+}
+
+// Summary describes the reload for the model.
+func (s SkillReload) Summary() string {
+	var changes []string
+	for _, c := range []struct {
+		verb  string
+		names []string
+	}{{"added", s.Added}, {"updated", s.Updated}, {"removed", s.Removed}} {
+		if len(c.names) > 0 {
+			changes = append(changes, c.verb+" "+strings.Join(c.names, ", "))
+		}
+	}
+	if len(changes) == 0 {
+		return fmt.Sprintf("Dynamic skills reloaded: %d loaded, no changes.", s.Total)
+	}
+	return fmt.Sprintf("Dynamic skills reloaded: %d loaded; %s.", s.Total, strings.Join(changes, "; "))
+}
+
+// LoadSkills registers every script in skills/ and every plugin tool in
+// skills/bin/, and unregisters skill tools whose file is gone, so it can be
+// called any number of times.
+func (r *Registry) LoadSkills() SkillReload {
+	r.skillsMu.Lock()
+	defer r.skillsMu.Unlock()
+
 	skillsDir := filepath.Join(r.workspaceDir, "skills")
 	if err := os.MkdirAll(skillsDir, 0755); err != nil {
-		fmt.Printf("Error creating skills directory: %v\n", err)
-		return
+		slog.Error("failed to create skills directory", "err", err)
+		return SkillReload{}
 	}
 
 	entries, err := os.ReadDir(skillsDir)
 	if err != nil {
-		fmt.Printf("Error reading skills directory: %v\n", err)
-		return
+		slog.Error("failed to read skills directory", "err", err)
+		return SkillReload{}
 	}
 
+	before := r.skillDefinitions()
+	seen := make(map[string]bool)
 	for _, entry := range entries {
 		if entry.IsDir() {
 			continue
@@ -81,25 +194,98 @@ func (r *Registry) LoadSkills() {
 			continue
 		}
 
-		toolName := strings.TrimSuffix(name, filepath.Ext(name))
-		scriptPath := filepath.Join(skillsDir, name)
+		if tool := r.registerSkill(skillsDir, name); tool != "" {
+			seen[tool] = true
+		}
+	}
 
-		// Pull description from tracker if available
-		description := fmt.Sprintf("Dynamic skill: executes the %s script. Ensure to pass required arguments.", name)
-		if r.wsMgr != nil {
-			if t, err := r.wsMgr.ReadTracker("skills"); err == nil {
-				if item, ok := t.Items[toolName]; ok && item.Description != "" {
-					description = item.Description
-				}
-			}
+	// Compiled plugins in skills/bin/ can expose several typed tools each
+	for _, tool := range r.LoadPlugins() {
+		seen[tool] = true
+	}
+	return r.pruneSkills(before, seen)
+}
+
+// skillDefinitions returns the definitions of the registered skill tools.
+func (r *Registry) skillDefinitions() map[string]providers.ToolDefinition {
+	r.toolsMu.RLock()
+	defer r.toolsMu.RUnlock()
+	defs := make(map[string]providers.ToolDefinition, len(r.skillTools))
+	for _, def := range r.definitions {
+		if _, ok := r.skillTools[def.Function.Name]; ok {
+			defs[def.Function.Name] = def
+		}
+	}
+	return defs
+}
+
+// pruneSkills unregisters the skill tools not in seen and compares the rest
+// with their definitions before the reload.
+func (r *Registry) pruneSkills(before map[string]providers.ToolDefinition, seen map[string]bool) SkillReload {
+	var res SkillReload
+	for name := range r.skillDefinitions() {
+		if !seen[name] {
+			r.UnregisterTool(name)
+			res.Removed = append(res.Removed, name)
+		}
+	}
+	after := r.skillDefinitions()
+	for name, def := range after {
+		old, ok := before[name]
+		switch {
+		case !ok:
+			res.Added = append(res.Added, name)
+		case !reflect.DeepEqual(old, def):
+			res.Updated = append(res.Updated, name)
 		}
+	}
+	sort.Strings(res.Added)
+	sort.Strings(res.Updated)
+	sort.Strings(res.Removed)
+	res.Total = len(after)
+	if len(res.Added)+len(res.Updated)+len(res.Removed) > 0 {
+		slog.Info("skills reloaded", "added", res.Added, "updated", res.Updated, "removed", res.Removed)
+	}
+	return res
+}
 
-		// Define the tool
-		def := providers.ToolDefinition{
-			Type: "function",
+// registerSkill registers (or re-registers) skills/<name> as a tool and
+// returns the tool name, or "" when the name belongs to another tool.
+func (r *Registry) registerSkill(skillsDir, name string) string {
+	toolName := strings.TrimSuffix(name, filepath.Ext(name))
+	scriptPath := filepath.Join(skillsDir, name)
+
+	// Frontmatter in the script header declares a description and typed params
+	var meta *SkillMeta
+	if body, err := os.ReadFile(scriptPath); err == nil {
+		if meta, err = ParseSkillMeta(string(body)); err != nil {
+			slog.Warn("skill has invalid frontmatter, using plain args", "skill", name, "err", err)
+		}
+	}
+
+	// Pull description from tracker if available
+	description := fmt.Sprintf("Dynamic skill: executes the %s script. Ensure to pass required arguments.", name)
+	if r.wsMgr != nil {
+		if t, err := r.wsMgr.ReadTracker("skills"); err == nil {
+			if item, ok := t.Items[toolName]; ok && item.Description != "" {
+				description = item.Description
+			}
 		}
-		def.Function.Name = toolName
-		def.Function.Description = description
+	}
+	if meta != nil && meta.Description != "" {
+		description = meta.Description
+	}
+
+	// Define the tool
+	def := providers.ToolDefinition{
+		Type: "function",
+	}
+	def.Function.Name = toolName
+	def.Function.Description = description
+	if meta != nil && len(meta.Params) > 0 {
+		def.Function.Parameters = meta.Schema()
+	} else {
+		meta = nil
 		def.Function.Parameters = map[string]interface{}{
 			"type": "object",
 			"properties": map[string]interface{}{
@@ -109,67 +295,185 @@ func (r *Registry) LoadSkills() {
 				},
 			},
 		}
+	}
 
-		// Capture loop vars for closure
-		capturedName := name
-		capturedToolName := toolName
-		capturedPath := scriptPath
+	// Capture loop vars for closure
+	capturedName := name
+	capturedToolName := toolName
+	capturedPath := scriptPath
+	capturedMeta := meta
+
+	// Create handler
+	handler := func(ctx context.Context, args map[string]interface{}) *ToolResult {
+		cmdArgsStr, _ := args["args"].(string)
+
+		// Typed skills: validate against the declared params
+		var cmdArgs, argEnv []string
+		if capturedMeta != nil {
+			var err error
+			if cmdArgs, argEnv, err = capturedMeta.Bind(args); err != nil {
+				return &ToolResult{ForLLM: fmt.Sprintf("Error: %v", err)}
+			}
+			cmdArgsStr = strings.Join(cmdArgs, " ")
+		} else if cmdArgsStr != "" {
+			// Simple split by space for args (a more robust parser might handle quotes)
+			cmdArgs = strings.Fields(cmdArgsStr)
+		}
 
-		// Create handler
-		handler := func(ctx context.Context, args map[string]interface{}) *ToolResult {
-			cmdArgsStr, _ := args["args"].(string)
+		if held := r.CheckApproval(ctx, capturedToolName, strings.TrimSpace(capturedName+" "+cmdArgsStr)); held != nil {
+			return held
+		}
 
-			// Simple split by space for args (a more robust parser might handle quotes)
-			var cmdArgs []string
-			if cmdArgsStr != "" {
-				cmdArgs = strings.Fields(cmdArgsStr)
-			}
+		interpreter := "python3"
+		if strings.HasSuffix(capturedName, ".sh") {
+			interpreter = "sh"
+		}
 
-			var cmd *exec.Cmd
-			if strings.HasSuffix(capturedName, ".sh") {
-				execArgs := append([]string{capturedPath}, cmdArgs...)
-				cmd = exec.CommandContext(ctx, "sh", execArgs...)
-			} else {
-				execArgs := append([]string{capturedPath}, cmdArgs...)
-				cmd = exec.CommandContext(ctx, "python3", execArgs...)
+		// Evaluate the exec policy on the resolved invocation and the script body
+		resolved := strings.TrimSpace(fmt.Sprintf("%s skills/%s %s", interpreter, capturedName, cmdArgsStr))
+		if err := r.policy().Check(resolved); err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Skill blocked by exec policy: %v", err)}
+		}
+		if body, err := os.ReadFile(capturedPath); err == nil {
+			if err := r.policy().CheckScript(string(body)); err != nil {
+				return &ToolResult{ForLLM: fmt.Sprintf("Skill blocked by exec policy: %v", err)}
 			}
-			cmd.Dir = r.workspaceDir
+		}
 
-			output, err := cmd.CombinedOutput()
-			runOK := err == nil
-			outStr := string(output)
+		execArgs := append([]string{capturedPath}, cmdArgs...)
+		cmd := r.command(ctx, interpreter, execArgs...)
+		GracefulCancel(cmd)
+		if len(argEnv) > 0 {
+			cmd.Env = append(os.Environ(), argEnv...)
+		}
 
-			// Record run in tracker
-			if r.wsMgr != nil {
-				_ = r.wsMgr.RecordRun("skills", capturedToolName, cmdArgsStr, outStr, runOK)
-			}
+		start := time.Now()
+		output, err := cmd.CombinedOutput()
+		r.publishCommand(ctx, "skill", resolved, start, err)
+		runOK := err == nil
+		outStr := string(output)
 
-			if err != nil {
-				return &ToolResult{ForLLM: fmt.Sprintf("Skill failed: %s\nOutput: %s", err, output)}
-			}
-			return &ToolResult{ForLLM: outStr}
+		// Record run in tracker
+		if r.wsMgr != nil {
+			_ = r.wsMgr.RecordRun("skills", capturedToolName, cmdArgsStr, outStr, runOK)
 		}
 
-		r.RegisterTool(def, handler)
-		fmt.Printf("Registered dynamic skill: %s\n", toolName)
+		if err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Skill failed: %s\nOutput: %s", err, output)}
+		}
+		return &ToolResult{ForLLM: outStr}
+	}
+
+	if !r.registerSkillTool(def, handler, scriptPath) {
+		slog.Warn("skill not loaded, its name is taken by another tool", "skill", name, "tool", toolName)
+		return ""
+	}
+	slog.Debug("registered dynamic skill", "tool", toolName)
+	return toolName
+}
+
+// registerSkillTool registers a tool defined by source, a file under skills/.
+// It refuses to replace a built-in tool or one defined by another file that
+// still exists.
+func (r *Registry) registerSkillTool(def providers.ToolDefinition, handler Handler, source string) bool {
+	r.toolsMu.Lock()
+	defer r.toolsMu.Unlock()
+	name := def.Function.Name
+	if _, exists := r.handlers[name]; exists {
+		prev, isSkill := r.skillTools[name]
+		if !isSkill {
+			return false
+		}
+		if _, err := os.Stat(prev); prev != source && err == nil {
+			return false
+		}
 	}
+	r.skillTools[name] = source
+	r.register(def, handler)
+	return true
 }
 
+// RegisterTool adds a tool. A tool with the same name is replaced in place,
+// keeping its position in the tool list.
 func (r *Registry) RegisterTool(def providers.ToolDefinition, handler Handler) {
-	r.definitions = append(r.definitions, def)
+	r.toolsMu.Lock()
+	defer r.toolsMu.Unlock()
+	r.register(def, handler)
+}
+
+// register is RegisterTool; r.toolsMu must be held.
+func (r *Registry) register(def providers.ToolDefinition, handler Handler) {
 	r.handlers[def.Function.Name] = handler
+	for i, d := range r.definitions {
+		if d.Function.Name == def.Function.Name {
+			r.definitions[i] = def
+			return
+		}
+	}
+	r.definitions = append(r.definitions, def)
+}
+
+// UnregisterTool removes a tool and reports whether it was registered.
+func (r *Registry) UnregisterTool(name string) bool {
+	r.toolsMu.Lock()
+	defer r.toolsMu.Unlock()
+	if _, ok := r.handlers[name]; !ok {
+		return false
+	}
+	delete(r.handlers, name)
+	delete(r.skillTools, name)
+	r.definitions = slices.DeleteFunc(r.definitions, func(d providers.ToolDefinition) bool {
+		return d.Function.Name == name
+	})
+	return true
 }
 
+// lookupTool returns the handler of a registered tool.
+func (r *Registry) lookupTool(name string) (Handler, bool) {
+	r.toolsMu.RLock()
+	defer r.toolsMu.RUnlock()
+	h, ok := r.handlers[name]
+	return h, ok
+}
+
+// Events returns the bus tool calls are published on. The agent publishes its
+// own events there too.
+func (r *Registry) Events() *events.Bus { return r.events }
+
+// GetDefinitions returns a copy of the registered tool definitions, in
+// registration order.
 func (r *Registry) GetDefinitions() []providers.ToolDefinition {
-	return r.definitions
+	r.toolsMu.RLock()
+	defer r.toolsMu.RUnlock()
+	return slices.Clone(r.definitions)
 }
 
 func (r *Registry) Execute(ctx context.Context, name string, args map[string]interface{}) *ToolResult {
-	handler, exists := r.handlers[name]
+	handler, exists := r.lookupTool(name)
 	if !exists {
 		return &ToolResult{ForLLM: fmt.Sprintf("Error: Tool '%s' not found", name)}
 	}
-	return handler(ctx, args)
+
+	ctx, span := telemetry.Start(ctx, "execute_tool "+name, "gen_ai.tool.name", name)
+	defer span.End()
+
+	start := time.Now()
+	var result *ToolResult
+	if role := UserRoleFrom(ctx); !role.Allows(name) {
+		result = &ToolResult{ForLLM: fmt.Sprintf("Error: %s is not available to %s users. Only the admin can use it.", name, role)}
+	} else {
+		result = r.runWithTimeout(ctx, name, handler, args)
+	}
+	// Keys from env dumps or curl headers must not reach the provider or the logs
+	if result != nil {
+		result.ForLLM = redact.String(result.ForLLM)
+		result.ForUser = redact.String(result.ForUser)
+	}
+	r.publishExecuted(ctx, name, args, start, result)
+	if failed, errLine := resultFailed(result); failed {
+		span.SetError(errors.New(errLine))
+	}
+	return result
 }
 
 // Core execution sandbox tools
@@ -215,7 +519,7 @@ func (r *Registry) registerCoreTools() {
 			Parameters  map[string]interface{} `json:"parameters"`
 		}{
 			Name:        "read_file",
-			Description: "Reads the content of a file within the sandbox workspace.",
+			Description: "Reads the content of a file within the sandbox workspace. For large files, read a range of lines with start_line and max_lines.",
 			Parameters: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
@@ -223,6 +527,14 @@ func (r *Registry) registerCoreTools() {
 						"type":        "string",
 						"description": "Relative path to the file within the workspace.",
 					},
+					"start_line": map[string]interface{}{
+						"type":        "integer",
+						"description": "Optional first line to return (1-based).",
+					},
+					"max_lines": map[string]interface{}{
+						"type":        "integer",
+						"description": "Optional number of lines to return from start_line.",
+					},
 				},
 				"required": []string{"path"},
 			},
@@ -242,7 +554,21 @@ func (r *Registry) registerCoreTools() {
 		if err != nil {
 			return &ToolResult{ForLLM: fmt.Sprintf("Error reading file: %v", err)}
 		}
-		return &ToolResult{ForLLM: string(data)}
+		start, _ := args["start_line"].(float64)
+		count, _ := args["max_lines"].(float64)
+		if start <= 1 && count <= 0 {
+			return &ToolResult{ForLLM: string(data)}
+		}
+		lines := strings.SplitAfter(strings.TrimSuffix(string(data), "\n"), "\n")
+		from := max(int(start), 1) - 1
+		if from >= len(lines) {
+			return &ToolResult{ForLLM: fmt.Sprintf("%s has only %d lines.", p, len(lines))}
+		}
+		to := len(lines)
+		if count > 0 {
+			to = min(from+int(count), to)
+		}
+		return &ToolResult{ForLLM: fmt.Sprintf("[lines %d-%d of %d]\n%s", from+1, to, len(lines), strings.Join(lines[from:to], ""))}
 	})
 
 	// write_file
@@ -358,7 +684,7 @@ func (r *Registry) registerCoreTools() {
 			Parameters  map[string]interface{} `json:"parameters"`
 		}{
 			Name:        "send_telegram_file",
-			Description: "Attaches and sends a specific local file to the user over Telegram.",
+			Description: "Attaches and sends a specific local file to the user over Telegram. Telegram takes files up to 50MB; for larger ones, say how to deliver them with if_too_large.",
 			Parameters: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
@@ -370,6 +696,11 @@ func (r *Registry) registerCoreTools() {
 						"type":        "string",
 						"description": "Optional textual message to send alongside the file.",
 					},
+					"if_too_large": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"compress", "split", "link"},
+						"description": "What to do if the file is over 50MB: compress it with gzip, split it into parts, or send a download link (only when file links are configured). Leave unset to be told the file's size first.",
+					},
 				},
 				"required": []string{"path"},
 			},
@@ -395,6 +726,10 @@ func (r *Registry) registerCoreTools() {
 		}
 
 		caption, _ := args["caption"].(string)
+		if info.Size() > telegramUploadLimit {
+			mode, _ := args["if_too_large"].(string)
+			return r.sendOversizedFile(p, safePath, info.Size(), mode, caption)
+		}
 
 		return &ToolResult{
 			ForLLM:  fmt.Sprintf("Successfully queued %s for sending to Telegram.", p),
@@ -412,7 +747,7 @@ func (r *Registry) registerCoreTools() {
 			Parameters  map[string]interface{} `json:"parameters"`
 		}{
 			Name:        "exec",
-			Description: "Executes a shell command inside the workspace directory.",
+			Description: "Executes a shell command inside the workspace directory. Set background for long commands (builds, downloads, big scripts): it returns a run ID right away and the user is notified with the output when it finishes.",
 			Parameters: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
@@ -420,6 +755,10 @@ func (r *Registry) registerCoreTools() {
 						"type":        "string",
 						"description": "The shell command to run.",
 					},
+					"background": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Run the command in the background instead of waiting for it (default false).",
+					},
 				},
 				"required": []string{"command"},
 			},
@@ -430,15 +769,24 @@ func (r *Registry) registerCoreTools() {
 			return &ToolResult{ForLLM: "Error: command must be a string"}
 		}
 
-		// Very basic security boundary. In a real system, you'd want closer inspection.
-		if IsBannedCommand(cmdStr) {
-			return &ToolResult{ForLLM: "Command blocked by safety guard (dangerous pattern detected)"}
+		if err := r.policy().Check(cmdStr); err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Command blocked by exec policy: %v", err)}
+		}
+
+		if held := r.CheckApproval(ctx, "exec", cmdStr); held != nil {
+			return held
+		}
+
+		if bg, _ := args["background"].(bool); bg {
+			return r.startBackgroundExec(ctx, cmdStr)
 		}
 
-		cmd := exec.CommandContext(ctx, "sh", "-c", cmdStr)
-		cmd.Dir = r.workspaceDir
+		cmd := r.command(ctx, "sh", "-c", cmdStr)
+		GracefulCancel(cmd)
 
+		start := time.Now()
 		output, err := cmd.CombinedOutput()
+		r.publishCommand(ctx, "exec", cmdStr, start, err)
 		if err != nil {
 			return &ToolResult{ForLLM: fmt.Sprintf("Command failed: %s\nOutput: %s", err, output)}
 		}
@@ -464,9 +812,8 @@ func (r *Registry) registerCoreTools() {
 			},
 		},
 	}, func(ctx context.Context, args map[string]interface{}) *ToolResult {
-		r.LoadSkills()
 		return &ToolResult{
-			ForLLM: "Dynamic skills reloaded successfully.",
+			ForLLM: r.LoadSkills().Summary(),
 		}
 	})
 }
@@ -491,6 +838,9 @@ func (r *Registry) resolveWorkspacePath(p string) (string, error) {
 		if IsProtectedMemoryPath(base, dir) {
 			return "", fmt.Errorf("Error: Direct file access to memory files is prohibited. Use memory tools instead.")
 		}
+		if IsAuditLogPath(r.workspaceDir, safePath) {
+			return "", fmt.Errorf("Error: %s is an audit log and cannot be accessed with file tools.", base)
+		}
 		return safePath, nil
 	}
 
@@ -513,18 +863,16 @@ func (r *Registry) resolveWorkspacePath(p string) (string, error) {
 	if IsProtectedMemoryPath(base, dir) {
 		return "", fmt.Errorf("Error: Direct file access to memory files is prohibited. You MUST use memory tools (update_core_memory, append_core_memory, read_core_memory, write_entity, list_entities, read_entity, search_history) instead.")
 	}
+	if IsAuditLogPath(r.workspaceDir, cleanPath) {
+		return "", fmt.Errorf("Error: %s is an audit log and cannot be accessed with file tools.", base)
+	}
 
 	return cleanPath, nil
 }
 
+// IsBannedCommand reports whether cmd is rejected by the built-in deny patterns.
 func IsBannedCommand(cmd string) bool {
-	bans := []string{"rm -rf", "mkfs", "dd if="}
-	for _, b := range bans {
-		if strings.Contains(cmd, b) {
-			return true
-		}
-	}
-	return false
+	return DefaultExecPolicy().Check(cmd) != nil
 }
 
 // dailyLogPattern matches daily log files like "2026-03-11.md"
@@ -538,7 +886,7 @@ func IsProtectedMemoryPath(base, dir string) bool {
 		return true
 	}
 	// Identity files
-	if base == "SOUL.md" || base == "IDENTITY.md" || base == "USER.md" || base == "HEARTBEAT.md" {
+	if base == "SOUL.md" || base == "IDENTITY.md" || base == "USER.md" || base == "SYSTEM.md" || base == "HEARTBEAT.md" {
 		return true
 	}
 	// Entity directory
@@ -0,0 +1,123 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Sandbox runs shell commands under bubblewrap or firejail, for hosts
+// without Docker: the host filesystem is read-only, only the workspace and
+// Writable paths can be written, and NoNetwork cuts the network off.
+type Sandbox struct {
+	Mode      string   // "bwrap" or "firejail"
+	NoNetwork bool     // run without network access
+	Writable  []string // absolute paths writable besides the workspace
+	ReadOnly  []string // files kept read-only even inside a writable path, such as the audit logs
+
+	bin string // resolved path of the Mode binary
+}
+
+// NewSandbox checks a sandbox configuration and finds its binary. Mode ""
+// or "none" returns a nil Sandbox, which runs commands directly.
+func NewSandbox(mode string, noNetwork bool, writable []string) (*Sandbox, error) {
+	switch mode = strings.ToLower(strings.TrimSpace(mode)); mode {
+	case "", "none":
+		return nil, nil
+	case "bubblewrap":
+		mode = "bwrap"
+	case "bwrap", "firejail":
+	case "landlock":
+		return nil, fmt.Errorf("sandbox mode landlock is not supported; use bwrap or firejail")
+	default:
+		return nil, fmt.Errorf("unknown sandbox mode %q (want bwrap, firejail, or none)", mode)
+	}
+	bin, err := exec.LookPath(mode)
+	if err != nil {
+		return nil, fmt.Errorf("sandbox mode %s: %w", mode, err)
+	}
+	for _, p := range writable {
+		if !filepath.IsAbs(p) {
+			return nil, fmt.Errorf("sandbox writable path %q must be absolute", p)
+		}
+	}
+	return &Sandbox{Mode: mode, NoNetwork: noNetwork, Writable: writable, bin: bin}, nil
+}
+
+// Wrap returns the command line that runs name with args inside the sandbox,
+// with workspace writable and dir as the working directory. A nil Sandbox
+// returns name and args unchanged.
+func (s *Sandbox) Wrap(workspace, dir, name string, args ...string) (string, []string) {
+	if s == nil {
+		return name, args
+	}
+	bin := s.bin
+	if bin == "" {
+		bin = s.Mode
+	}
+	writable := append([]string{workspace}, s.Writable...)
+
+	var wrapped []string
+	switch s.Mode {
+	case "firejail":
+		wrapped = []string{"--quiet", "--noprofile", "--read-only=/", "--private-tmp"}
+		for _, p := range writable {
+			wrapped = append(wrapped, "--read-write="+p)
+		}
+		for _, p := range s.ReadOnly {
+			wrapped = append(wrapped, "--read-only="+p)
+		}
+		if s.NoNetwork {
+			wrapped = append(wrapped, "--net=none")
+		}
+	default: // bwrap
+		wrapped = []string{"--ro-bind", "/", "/", "--dev", "/dev", "--proc", "/proc", "--tmpfs", "/tmp"}
+		for _, p := range writable {
+			wrapped = append(wrapped, "--bind", p, p)
+		}
+		// Later mounts win, so these cover the writable binds
+		for _, p := range s.ReadOnly {
+			wrapped = append(wrapped, "--ro-bind-try", p, p)
+		}
+		if s.NoNetwork {
+			wrapped = append(wrapped, "--unshare-net")
+		}
+		wrapped = append(wrapped, "--die-with-parent", "--chdir", dir)
+	}
+	wrapped = append(wrapped, "--", name)
+	return bin, append(wrapped, args...)
+}
+
+// WithReadOnly returns a copy of s that also keeps paths read-only. A nil
+// Sandbox stays nil.
+func (s *Sandbox) WithReadOnly(paths ...string) *Sandbox {
+	if s == nil {
+		return nil
+	}
+	c := *s
+	c.ReadOnly = append(slices.Clip(s.ReadOnly), paths...)
+	return &c
+}
+
+// Command is exec.CommandContext for a command run in the sandbox from dir.
+func (s *Sandbox) Command(ctx context.Context, workspace, dir, name string, args ...string) *exec.Cmd {
+	name, args = s.Wrap(workspace, dir, name, args...)
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	return cmd
+}
+
+// SetSandbox runs exec, background commands, skills, and plugins inside s;
+// nil runs them directly.
+func (r *Registry) SetSandbox(s *Sandbox) {
+	r.sandbox.Store(s)
+}
+
+// command builds a shell command run from the workspace, inside the sandbox
+// if one is set.
+func (r *Registry) command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	return r.sandbox.Load().Command(ctx, r.workspaceDir, r.workspaceDir, name, args...)
+}
@@ -0,0 +1,194 @@
+package tools
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	// telegramUploadLimit is the largest file a bot may upload to Telegram.
+	telegramUploadLimit = 50 << 20
+	// sendPartBytes is the size of each part when an oversized file is split,
+	// with headroom under telegramUploadLimit for the multipart encoding.
+	sendPartBytes = 45 << 20
+)
+
+// FileLinker shares a local file over HTTP, for files too large to send
+// through Telegram.
+type FileLinker interface {
+	// Link returns a download URL for the file at path.
+	Link(path string) (string, error)
+}
+
+// SetFileLinker enables the "link" option of send_telegram_file.
+func (r *Registry) SetFileLinker(l FileLinker) {
+	r.fileLinker = l
+}
+
+// sendOversizedFile handles a send_telegram_file call for a file over the
+// upload limit: without a mode it explains the options, otherwise it
+// compresses, splits, or links the file.
+func (r *Registry) sendOversizedFile(p, safePath string, size int64, mode, caption string) *ToolResult {
+	switch mode {
+	case "":
+		options := []string{
+			`"compress" (gzip it; helps for text, logs, and databases, not for media or archives)`,
+			`"split" (send numbered parts the user joins back together)`,
+		}
+		if r.fileLinker != nil {
+			options = append(options, `"link" (send a download link instead)`)
+		}
+		return &ToolResult{ForLLM: fmt.Sprintf("Error: %s is %s, over Telegram's %s limit for bot uploads, so it was not sent. Call send_telegram_file again with if_too_large set to %s, or ask the user which they prefer.",
+			p, formatSize(size), formatSize(telegramUploadLimit), strings.Join(options, ", or "))}
+
+	case "compress":
+		dir, err := r.outgoingDir()
+		if err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error: %v", err)}
+		}
+		out := filepath.Join(dir, filepath.Base(safePath)+".gz")
+		n, err := gzipFile(safePath, out)
+		if err != nil {
+			os.RemoveAll(dir)
+			return &ToolResult{ForLLM: fmt.Sprintf("Error compressing %s: %v", p, err)}
+		}
+		if n > telegramUploadLimit {
+			os.RemoveAll(dir)
+			return &ToolResult{ForLLM: fmt.Sprintf("Error: %s only compresses to %s, still over Telegram's %s limit. Try if_too_large \"split\" instead.",
+				p, formatSize(n), formatSize(telegramUploadLimit))}
+		}
+		return &ToolResult{
+			ForLLM:    fmt.Sprintf("Compressed %s from %s to %s and queued it for sending to Telegram; the copy is deleted once sent.", p, formatSize(size), formatSize(n)),
+			ForUser:   caption,
+			Files:     []string{out},
+			TempFiles: []string{out, dir},
+		}
+
+	case "split":
+		dir, err := r.outgoingDir()
+		if err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error: %v", err)}
+		}
+		parts, err := splitFile(safePath, dir)
+		if err != nil {
+			os.RemoveAll(dir)
+			return &ToolResult{ForLLM: fmt.Sprintf("Error splitting %s: %v", p, err)}
+		}
+		name := filepath.Base(safePath)
+		note := fmt.Sprintf("%s was too large for one message, so it comes in %d parts. Join them with: cat %s.part* > %s", name, len(parts), name, name)
+		if caption != "" {
+			note = caption + "\n\n" + note
+		}
+		return &ToolResult{
+			ForLLM:    fmt.Sprintf("Split %s (%s) into %d parts and queued them for sending to Telegram; the parts are deleted once sent.", p, formatSize(size), len(parts)),
+			ForUser:   note,
+			Files:     parts,
+			TempFiles: append(slices.Clone(parts), dir),
+		}
+
+	case "link":
+		if r.fileLinker == nil {
+			return &ToolResult{ForLLM: "Error: download links are not configured (file_links in config.json). Use \"compress\" or \"split\" instead."}
+		}
+		url, err := r.fileLinker.Link(safePath)
+		if err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error creating a download link for %s: %v", p, err)}
+		}
+		msg := fmt.Sprintf("📎 %s (%s): %s", filepath.Base(safePath), formatSize(size), url)
+		if caption != "" {
+			msg = caption + "\n\n" + msg
+		}
+		return &ToolResult{
+			ForLLM:  fmt.Sprintf("%s is too large for Telegram; sent the user a download link instead.", p),
+			ForUser: msg,
+		}
+	}
+	return &ToolResult{ForLLM: fmt.Sprintf("Error: unknown if_too_large %q (use compress, split, or link)", mode)}
+}
+
+// outgoingDir creates a new directory under the workspace media/ for copies
+// made only for sending, so they keep the original file name without
+// replacing anything already in media/.
+func (r *Registry) outgoingDir() (string, error) {
+	media := filepath.Join(r.workspaceDir, "media")
+	if err := os.MkdirAll(media, 0755); err != nil {
+		return "", fmt.Errorf("creating media directory: %w", err)
+	}
+	dir, err := os.MkdirTemp(media, "outgoing-")
+	if err != nil {
+		return "", fmt.Errorf("creating a directory for the upload: %w", err)
+	}
+	return dir, nil
+}
+
+// gzipFile compresses src into dst and returns the compressed size.
+func gzipFile(src, dst string) (int64, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return 0, err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer out.Close()
+
+	zw, err := gzip.NewWriterLevel(out, gzip.BestCompression)
+	if err != nil {
+		return 0, err
+	}
+	zw.Name = filepath.Base(src)
+	if _, err := io.Copy(zw, in); err != nil {
+		return 0, err
+	}
+	if err := zw.Close(); err != nil {
+		return 0, err
+	}
+	info, err := out.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// splitFile copies src into <dir>/<name>.part01, .part02, ... of at most
+// sendPartBytes each and returns their paths. The numbering keeps the parts
+// in order for a shell glob.
+func splitFile(src, dir string) ([]string, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+
+	var parts []string
+	for i := 1; ; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("%s.part%02d", filepath.Base(src), i))
+		out, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil {
+			return parts, err
+		}
+		parts = append(parts, path)
+		n, copyErr := io.CopyN(out, in, sendPartBytes)
+		if err := out.Close(); err != nil {
+			return parts, err
+		}
+		if copyErr == io.EOF {
+			if n == 0 {
+				// The previous part ended exactly at the end of the file
+				os.Remove(path)
+				parts = parts[:len(parts)-1]
+			}
+			return parts, nil
+		}
+		if copyErr != nil {
+			return parts, copyErr
+		}
+	}
+}
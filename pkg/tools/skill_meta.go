@@ -0,0 +1,185 @@
+package tools
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SkillParam is one typed argument declared in a skill's frontmatter.
+type SkillParam struct {
+	Name        string
+	Type        string // string, integer, number, or boolean
+	Required    bool
+	Description string
+}
+
+// SkillMeta is the metadata declared in a skill script's comment header:
+//
+//	# ---
+//	# description: Get the forecast for a city
+//	# params:
+//	#   city: string, required, City name
+//	#   days: integer, Number of days (default 1)
+//	# ---
+//
+// Declared params are passed to the script as ARG_<NAME> environment
+// variables and as positional arguments in declaration order.
+type SkillMeta struct {
+	Description string
+	Params      []SkillParam
+}
+
+var (
+	skillParamName  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	skillParamTypes = map[string]bool{"string": true, "integer": true, "number": true, "boolean": true}
+)
+
+// ParseSkillMeta reads the frontmatter header from a script body. It returns
+// nil, nil when the script has no header.
+func ParseSkillMeta(body string) (*SkillMeta, error) {
+	lines := strings.Split(body, "\n")
+	i := 0
+	if i < len(lines) && strings.HasPrefix(lines[i], "#!") {
+		i++
+	}
+	// The header must open on the first line after the shebang
+	if i >= len(lines) || strings.TrimSpace(lines[i]) != "# ---" {
+		return nil, nil
+	}
+
+	meta := &SkillMeta{}
+	inParams := false
+	for i++; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " \t\r")
+		if line == "# ---" {
+			return meta, nil
+		}
+		if !strings.HasPrefix(line, "#") {
+			return nil, fmt.Errorf("unterminated frontmatter (missing closing '# ---')")
+		}
+		content := strings.TrimPrefix(line, "#")
+		if strings.TrimSpace(content) == "" {
+			continue
+		}
+		indented := strings.HasPrefix(content, "  ") || strings.HasPrefix(content, "\t")
+		key, value, ok := strings.Cut(strings.TrimSpace(content), ":")
+		if !ok {
+			return nil, fmt.Errorf("frontmatter line %q is not key: value", strings.TrimSpace(content))
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+		if inParams && indented {
+			p, err := parseSkillParam(key, value)
+			if err != nil {
+				return nil, err
+			}
+			for _, existing := range meta.Params {
+				if existing.Name == p.Name {
+					return nil, fmt.Errorf("param %q declared twice", p.Name)
+				}
+			}
+			meta.Params = append(meta.Params, p)
+			continue
+		}
+
+		inParams = false
+		switch key {
+		case "description":
+			meta.Description = value
+		case "params":
+			inParams = true
+		default:
+			return nil, fmt.Errorf("unknown frontmatter key %q", key)
+		}
+	}
+	return nil, fmt.Errorf("unterminated frontmatter (missing closing '# ---')")
+}
+
+// parseSkillParam parses "name: type[, required][, description]".
+func parseSkillParam(name, spec string) (SkillParam, error) {
+	if !skillParamName.MatchString(name) {
+		return SkillParam{}, fmt.Errorf("invalid param name %q", name)
+	}
+	parts := strings.SplitN(spec, ",", 3)
+	p := SkillParam{Name: name, Type: strings.TrimSpace(parts[0])}
+	if !skillParamTypes[p.Type] {
+		return SkillParam{}, fmt.Errorf("param %q: unsupported type %q (want string, integer, number, or boolean)", name, p.Type)
+	}
+	rest := parts[1:]
+	if len(rest) > 0 && strings.TrimSpace(rest[0]) == "required" {
+		p.Required = true
+		rest = rest[1:]
+	}
+	p.Description = strings.TrimSpace(strings.Join(rest, ","))
+	return p, nil
+}
+
+// Schema returns the JSON-schema parameters object for the skill's tool definition.
+func (m *SkillMeta) Schema() map[string]interface{} {
+	props := map[string]interface{}{}
+	required := []string{}
+	for _, p := range m.Params {
+		prop := map[string]interface{}{"type": p.Type}
+		if p.Description != "" {
+			prop["description"] = p.Description
+		}
+		props[p.Name] = prop
+		if p.Required {
+			required = append(required, p.Name)
+		}
+	}
+	schema := map[string]interface{}{"type": "object", "properties": props}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// Bind validates tool-call arguments against the declared params and returns
+// the positional arguments and ARG_<NAME> environment entries for the script.
+func (m *SkillMeta) Bind(args map[string]interface{}) (positional []string, env []string, err error) {
+	for _, p := range m.Params {
+		raw, ok := args[p.Name]
+		if !ok || raw == nil {
+			if p.Required {
+				return nil, nil, fmt.Errorf("missing required argument %q", p.Name)
+			}
+			positional = append(positional, "")
+			continue
+		}
+
+		var s string
+		switch p.Type {
+		case "string":
+			s = fmt.Sprint(raw)
+		case "integer", "number":
+			f, ok := raw.(float64)
+			if !ok {
+				if f, err = strconv.ParseFloat(fmt.Sprint(raw), 64); err != nil {
+					return nil, nil, fmt.Errorf("argument %q must be a %s", p.Name, p.Type)
+				}
+			}
+			if p.Type == "integer" && f != float64(int64(f)) {
+				return nil, nil, fmt.Errorf("argument %q must be an integer", p.Name)
+			}
+			s = strconv.FormatFloat(f, 'f', -1, 64)
+		case "boolean":
+			b, ok := raw.(bool)
+			if !ok {
+				if b, err = strconv.ParseBool(fmt.Sprint(raw)); err != nil {
+					return nil, nil, fmt.Errorf("argument %q must be a boolean", p.Name)
+				}
+			}
+			s = strconv.FormatBool(b)
+		}
+		positional = append(positional, s)
+		env = append(env, "ARG_"+strings.ToUpper(p.Name)+"="+s)
+	}
+	// Omitted trailing optionals should not show up as empty positional args
+	for len(positional) > 0 && positional[len(positional)-1] == "" {
+		positional = positional[:len(positional)-1]
+	}
+	return positional, env, nil
+}
@@ -0,0 +1,295 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"littleclaw/pkg/providers"
+)
+
+// SkillPackManifestFile is the manifest at the root of a shareable skill repository.
+const SkillPackManifestFile = "littleclaw-skills.json"
+
+// SkillPackManifest describes a skill pack:
+//
+//	{
+//	  "name": "weather-pack",
+//	  "description": "Forecasts and alerts",
+//	  "skills": ["weather.sh", "alerts/storm_watch.py"],
+//	  "dependencies": {"commands": ["curl", "jq"], "python": ["requests"]}
+//	}
+//
+// When skills is empty, every .sh and .py file at the repo root is installed.
+type SkillPackManifest struct {
+	Name         string   `json:"name"`
+	Description  string   `json:"description,omitempty"`
+	Skills       []string `json:"skills,omitempty"`
+	Dependencies struct {
+		Commands []string `json:"commands,omitempty"` // executables that must be on PATH
+		Python   []string `json:"python,omitempty"`   // importable python modules
+	} `json:"dependencies"`
+}
+
+// SkillPackRecord is kept in skills/packs.json for every installed pack.
+type SkillPackRecord struct {
+	Name        string    `json:"name"`
+	URL         string    `json:"url"`
+	Commit      string    `json:"commit"`
+	Skills      []string  `json:"skills"`
+	InstalledAt time.Time `json:"installed_at"`
+}
+
+// SkillPackInstall is the outcome of InstallSkillPack.
+type SkillPackInstall struct {
+	Record  SkillPackRecord
+	Missing []string // unmet dependencies; the skills are installed but may fail until these exist
+}
+
+func skillPacksPath(workspaceDir string) string {
+	return filepath.Join(workspaceDir, "skills", "packs.json")
+}
+
+// LoadSkillPacks reads the installed pack records, keyed by pack name.
+func LoadSkillPacks(workspaceDir string) (map[string]SkillPackRecord, error) {
+	packs := map[string]SkillPackRecord{}
+	data, err := os.ReadFile(skillPacksPath(workspaceDir))
+	if os.IsNotExist(err) {
+		return packs, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &packs); err != nil {
+		return nil, fmt.Errorf("invalid packs.json: %w", err)
+	}
+	return packs, nil
+}
+
+func saveSkillPacks(workspaceDir string, packs map[string]SkillPackRecord) error {
+	data, err := json.MarshalIndent(packs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(skillPacksPath(workspaceDir), data, 0644)
+}
+
+// InstallSkillPack clones a skill repository, vets every script (frontmatter,
+// syntax, exec policy), and copies the scripts into skills/. Nothing is
+// installed if any script fails vetting. Existing skills are only replaced when
+// force is set or they belong to an earlier install of the same pack.
+func InstallSkillPack(ctx context.Context, workspaceDir, url string, policy *ExecPolicy, force bool) (*SkillPackInstall, error) {
+	if !isAllowedCloneURL(url) {
+		return nil, fmt.Errorf("url must be an https://, ssh:// or git@host:path remote")
+	}
+	if policy == nil {
+		policy = DefaultExecPolicy()
+	}
+	skillsDir := filepath.Join(workspaceDir, "skills")
+	if err := os.MkdirAll(skillsDir, 0755); err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.MkdirTemp("", "littleclaw-pack-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	repo := filepath.Join(tmp, "repo")
+	if out, err := runGit(ctx, tmp, "clone", "--depth", "1", url, repo); err != nil {
+		return nil, fmt.Errorf("git clone failed: %v\n%s", err, out)
+	}
+	commit, _ := runGit(ctx, repo, "rev-parse", "--short", "HEAD")
+
+	data, err := os.ReadFile(filepath.Join(repo, SkillPackManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("repository has no %s manifest", SkillPackManifestFile)
+	}
+	var manifest SkillPackManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", SkillPackManifestFile, err)
+	}
+	if manifest.Name == "" {
+		manifest.Name = repoNameFromURL(url)
+	}
+
+	files := manifest.Skills
+	if len(files) == 0 {
+		entries, _ := os.ReadDir(repo)
+		for _, e := range entries {
+			if !e.IsDir() && (strings.HasSuffix(e.Name(), ".sh") || strings.HasSuffix(e.Name(), ".py")) {
+				files = append(files, e.Name())
+			}
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("pack %q contains no skills", manifest.Name)
+	}
+
+	packs, err := LoadSkillPacks(workspaceDir)
+	if err != nil {
+		return nil, err
+	}
+	owned := map[string]bool{}
+	for _, s := range packs[manifest.Name].Skills {
+		owned[s] = true
+	}
+
+	// Vet everything before touching skills/
+	type staged struct{ dest, body string }
+	var toInstall []staged
+	seen := map[string]bool{}
+	for _, rel := range files {
+		src := filepath.Join(repo, filepath.Clean(rel))
+		if !strings.HasPrefix(src, repo+string(filepath.Separator)) {
+			return nil, fmt.Errorf("skill path %q escapes the repository", rel)
+		}
+		base := filepath.Base(src)
+		ext := filepath.Ext(base)
+		if ext != ".sh" && ext != ".py" {
+			return nil, fmt.Errorf("%s: only .sh and .py skills are supported", rel)
+		}
+		if !skillNamePattern.MatchString(strings.TrimSuffix(base, ext)) {
+			return nil, fmt.Errorf("%s: skill names must be lowercase letters, digits, and underscores", rel)
+		}
+		if seen[base] {
+			return nil, fmt.Errorf("%s: duplicate skill name %q in pack", rel, base)
+		}
+		seen[base] = true
+
+		// A symlink in the pack could copy any file on the host into skills/
+		if link := symlinkedDir(repo, src); link != "" {
+			return nil, fmt.Errorf("%s: skill path goes through a symlink", rel)
+		}
+		if info, err := os.Lstat(src); err != nil {
+			return nil, fmt.Errorf("%s: %w", rel, err)
+		} else if !info.Mode().IsRegular() {
+			return nil, fmt.Errorf("%s: not a regular file", rel)
+		}
+		body, err := os.ReadFile(src)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", rel, err)
+		}
+		if _, err := ParseSkillMeta(string(body)); err != nil {
+			return nil, fmt.Errorf("%s: invalid frontmatter: %w", rel, err)
+		}
+		if err := policy.CheckScript(string(body)); err != nil {
+			return nil, fmt.Errorf("%s: blocked by exec policy: %w", rel, err)
+		}
+		language := "sh"
+		if ext == ".py" {
+			language = "python"
+		}
+		if err := checkSkillSyntax(ctx, language, src); err != nil {
+			return nil, fmt.Errorf("%s: syntax check failed: %w", rel, err)
+		}
+
+		dest := filepath.Join(skillsDir, base)
+		if _, err := os.Stat(dest); err == nil && !force && !owned[base] {
+			return nil, fmt.Errorf("skill %s already exists; use force to replace it", base)
+		}
+		toInstall = append(toInstall, staged{dest: dest, body: string(body)})
+	}
+
+	record := SkillPackRecord{Name: manifest.Name, URL: url, Commit: commit, InstalledAt: time.Now()}
+	for _, s := range toInstall {
+		if err := os.WriteFile(s.dest, []byte(s.body), 0755); err != nil {
+			return nil, err
+		}
+		record.Skills = append(record.Skills, filepath.Base(s.dest))
+	}
+	sort.Strings(record.Skills)
+
+	// Skills dropped by an update of the same pack are removed
+	for old := range owned {
+		if !seen[old] {
+			_ = os.Remove(filepath.Join(skillsDir, old))
+		}
+	}
+
+	packs[manifest.Name] = record
+	if err := saveSkillPacks(workspaceDir, packs); err != nil {
+		return nil, err
+	}
+
+	return &SkillPackInstall{Record: record, Missing: missingSkillDeps(ctx, manifest)}, nil
+}
+
+// missingSkillDeps reports dependencies from the manifest that are not available.
+func missingSkillDeps(ctx context.Context, m SkillPackManifest) []string {
+	var missing []string
+	for _, c := range m.Dependencies.Commands {
+		if _, err := exec.LookPath(c); err != nil {
+			missing = append(missing, "command "+c)
+		}
+	}
+	for _, mod := range m.Dependencies.Python {
+		if !skillParamName.MatchString(strings.ReplaceAll(mod, ".", "_")) {
+			missing = append(missing, "python module "+mod+" (invalid name)")
+			continue
+		}
+		if err := exec.CommandContext(ctx, "python3", "-c", "import "+mod).Run(); err != nil {
+			missing = append(missing, "python module "+mod)
+		}
+	}
+	return missing
+}
+
+// Summary describes an install for the user or the LLM.
+func (i *SkillPackInstall) Summary() string {
+	s := fmt.Sprintf("Installed pack %q (%s) with %d skill(s): %s", i.Record.Name, i.Record.Commit, len(i.Record.Skills), strings.Join(i.Record.Skills, ", "))
+	if len(i.Missing) > 0 {
+		s += "\nMissing dependencies: " + strings.Join(i.Missing, ", ")
+	}
+	return s
+}
+
+// registerSkillPackTool adds install_skill_pack.
+func (r *Registry) registerSkillPackTool() {
+	r.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "install_skill_pack",
+			Description: "Installs a shared skill pack from a git repository that has a littleclaw-skills.json manifest. Every script is syntax-checked and policy-checked before anything is installed; the skills are registered immediately. Reinstalling the same URL updates the pack.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"url": map[string]interface{}{
+						"type":        "string",
+						"description": "Git URL of the skill pack (https://, ssh:// or git@host:path).",
+					},
+					"force": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Replace existing skills with the same file names.",
+					},
+				},
+				"required": []string{"url"},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *ToolResult {
+		url, _ := args["url"].(string)
+		force, _ := args["force"].(bool)
+
+		// A pack is remote code, so it is always held in approval mode
+		if held := r.askApproval(ctx, "install_skill_pack", "install skill pack "+url); held != nil {
+			return held
+		}
+		res, err := InstallSkillPack(ctx, r.workspaceDir, url, r.policy(), force)
+		if err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error installing skill pack: %v", err)}
+		}
+		r.LoadSkills()
+		return &ToolResult{ForLLM: res.Summary()}
+	})
+}
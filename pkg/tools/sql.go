@@ -0,0 +1,374 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"littleclaw/pkg/providers"
+)
+
+const (
+	sqlTimeout        = 60 * time.Second
+	sqlDefaultMaxRows = 100
+)
+
+// SQLConnection describes a named database the sql_query tool may talk to.
+// Queries are run through the database's own CLI (sqlite3, psql, mysql), so
+// no drivers are linked into the binary.
+type SQLConnection struct {
+	Driver      string // "sqlite", "postgres", or "mysql"
+	DSN         string // sqlite: file path (relative to workspace); postgres: URL; mysql: user:pass@tcp(host:port)/db
+	AllowWrites bool   // permit readonly=false queries on this connection
+	MaxRows     int    // rows returned before truncation (default 100)
+}
+
+// readOnlyStatement matches statements that cannot modify data.
+var readOnlyStatement = regexp.MustCompile(`(?i)^\s*(select|with|explain|pragma|show|describe|desc|values)\b`)
+
+// mysqlDelimiter matches the mysql client's delimiter command.
+var mysqlDelimiter = regexp.MustCompile(`(?im)^\s*delimiter\b`)
+
+// mysqlDSN parses the go-sql-driver style DSN: user[:pass]@tcp(host[:port])/db
+var mysqlDSN = regexp.MustCompile(`^([^:@]*)(?::([^@]*))?@(?:tcp\(([^)]*)\))?/(\w*)`)
+
+// SetSQLConnections configures the databases available to sql_query and
+// registers the tool on first use. Connections with an unknown driver are rejected.
+func (r *Registry) SetSQLConnections(conns map[string]SQLConnection) error {
+	for name, c := range conns {
+		switch c.Driver {
+		case "sqlite", "postgres", "mysql":
+		default:
+			return fmt.Errorf("connection %q: unsupported driver %q (want sqlite, postgres, or mysql)", name, c.Driver)
+		}
+		if c.DSN == "" {
+			return fmt.Errorf("connection %q: dsn is required", name)
+		}
+		if c.Driver == "mysql" && !mysqlDSN.MatchString(c.DSN) {
+			return fmt.Errorf("connection %q: mysql dsn must look like user:pass@tcp(host:port)/db", name)
+		}
+	}
+
+	if r.sqlConns == nil && len(conns) > 0 {
+		r.registerSQLTool()
+	}
+	r.sqlConns = conns
+	return nil
+}
+
+func (r *Registry) registerSQLTool() {
+	r.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "sql_query",
+			Description: "Runs a SQL query against one of the user's configured database connections and returns tab-separated rows with a header line. Results are capped at the connection's row limit, so aggregate or add LIMIT for large tables. Queries are read-only unless readonly=false and the connection allows writes. Call with an unknown connection to list the configured ones.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"connection": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the configured database connection.",
+					},
+					"query": map[string]interface{}{
+						"type":        "string",
+						"description": "The SQL statement to run.",
+					},
+					"readonly": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Run in a read-only session (default true).",
+					},
+				},
+				"required": []string{"connection", "query"},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *ToolResult {
+		name, _ := args["connection"].(string)
+		query, _ := args["query"].(string)
+		readonly := true
+		if ro, ok := args["readonly"].(bool); ok {
+			readonly = ro
+		}
+
+		conn, ok := r.sqlConns[name]
+		if !ok {
+			names := make([]string, 0, len(r.sqlConns))
+			for n := range r.sqlConns {
+				names = append(names, n)
+			}
+			sort.Strings(names)
+			return &ToolResult{ForLLM: fmt.Sprintf("Error: unknown connection %q. Configured connections: %s", name, strings.Join(names, ", "))}
+		}
+		if strings.TrimSpace(query) == "" {
+			return &ToolResult{ForLLM: "Error: query is required"}
+		}
+		if !readonly && !conn.AllowWrites {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error: connection %q is read-only; writes are not allowed", name)}
+		}
+		if readonly && !readOnlyStatement.MatchString(query) {
+			return &ToolResult{ForLLM: "Error: only SELECT/WITH/EXPLAIN/SHOW/DESCRIBE statements are allowed in read-only mode"}
+		}
+		// A later statement could end the read-only session and write
+		if readonly && multipleStatements(query, conn.Driver) {
+			return &ToolResult{ForLLM: "Error: only one statement per query is allowed in read-only mode"}
+		}
+
+		out, err := r.runSQL(ctx, conn, query, readonly)
+		if err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Query failed: %v", err)}
+		}
+		return &ToolResult{ForLLM: out}
+	})
+}
+
+// sqlCommand builds the CLI invocation for a connection.
+func (r *Registry) sqlCommand(ctx context.Context, conn SQLConnection, query string, readonly bool) (*exec.Cmd, error) {
+	switch conn.Driver {
+	case "sqlite":
+		for _, line := range strings.Split(query, "\n") {
+			// Dot-commands are sqlite3 shell directives, not SQL
+			if strings.HasPrefix(strings.TrimSpace(line), ".") {
+				return nil, fmt.Errorf("sqlite dot-commands are not allowed")
+			}
+		}
+		path := conn.DSN
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(r.workspaceDir, path)
+		}
+		args := []string{"-safe", "-bail", "-header", "-separator", "\t"}
+		if readonly {
+			args = append(args, "-readonly")
+		}
+		return exec.CommandContext(ctx, "sqlite3", append(args, path, query)...), nil
+
+	case "postgres":
+		dsn, env := postgresConn(conn.DSN)
+		cmd := exec.CommandContext(ctx, "psql", dsn, "-X", "-q", "-A", "-F", "\t", "-P", "footer=off", "-v", "ON_ERROR_STOP=1", "-c", query)
+		if readonly {
+			env = append(env, "PGOPTIONS=-c default_transaction_read_only=on")
+		}
+		// Password via env so it never shows up in the process list
+		cmd.Env = append(os.Environ(), env...)
+		return cmd, nil
+
+	case "mysql":
+		m := mysqlDSN.FindStringSubmatch(conn.DSN)
+		args := []string{"--batch", "--user=" + m[1]}
+		if host, port, found := strings.Cut(m[3], ":"); host != "" {
+			args = append(args, "--host="+host)
+			if found {
+				args = append(args, "--port="+port)
+			}
+		}
+		if readonly {
+			args = append(args, "--init-command=SET SESSION TRANSACTION READ ONLY")
+		}
+		args = append(args, "-e", query)
+		if m[4] != "" {
+			args = append(args, m[4])
+		}
+		cmd := exec.CommandContext(ctx, "mysql", args...)
+		// Password via env so it never shows up in the process list
+		cmd.Env = append(os.Environ(), "MYSQL_PWD="+m[2])
+		return cmd, nil
+	}
+	return nil, fmt.Errorf("unsupported driver %q", conn.Driver)
+}
+
+// postgresConn moves the password out of a postgres:// URL and into
+// PGPASSWORD. DSNs in another form are returned as they are.
+func postgresConn(dsn string) (string, []string) {
+	u, err := url.Parse(dsn)
+	if err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") {
+		return dsn, nil
+	}
+	var env []string
+	if u.User != nil {
+		if pass, ok := u.User.Password(); ok {
+			env = append(env, "PGPASSWORD="+pass)
+			u.User = url.User(u.User.Username())
+		}
+	}
+	if q := u.Query(); q.Has("password") {
+		env = append(env, "PGPASSWORD="+q.Get("password"))
+		q.Del("password")
+		u.RawQuery = q.Encode()
+	}
+	return u.String(), env
+}
+
+// multipleStatements reports whether query holds more than one statement:
+// SQL after a ';' that is outside string literals, quoted identifiers, and
+// comments, as driver's database reads them. An unterminated literal or
+// comment counts too, since the database may read it differently.
+func multipleStatements(query, driver string) bool {
+	mysql := driver == "mysql"
+	// The mysql client changes what ends a statement on "delimiter" lines
+	if mysql && mysqlDelimiter.MatchString(query) {
+		return true
+	}
+	ended := false
+	for i := 0; i < len(query); i++ {
+		c, rest := query[i], query[i:]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			continue
+		// MySQL needs whitespace after "--"; "1--1" is arithmetic there
+		case strings.HasPrefix(rest, "--") && (!mysql || len(rest) == 2 || strings.ContainsRune(" \t\n\r\f", rune(rest[2]))),
+			mysql && c == '#':
+			n := strings.IndexByte(rest, '\n')
+			if n < 0 {
+				return false
+			}
+			i += n
+			continue
+		// MySQL runs the body of a /*! ... */ comment
+		case strings.HasPrefix(rest, "/*") && !(mysql && strings.HasPrefix(rest, "/*!")):
+			n := strings.Index(rest[2:], "*/")
+			if n < 0 {
+				return true
+			}
+			i += n + 3
+			continue
+		}
+		if ended {
+			return true
+		}
+
+		var end int // length of the literal at i, or -1 if unterminated
+		switch {
+		case c == ';':
+			ended = true
+			continue
+		case c == '\\' && mysql:
+			// mysql client commands such as \g also end a statement
+			return true
+		case c == '\'':
+			// E'...' is Postgres' escape string syntax
+			escape := mysql || (driver == "postgres" && i > 0 && (query[i-1] == 'E' || query[i-1] == 'e') && (i < 2 || !isIdentByte(query[i-2])))
+			end = quotedLength(rest, '\'', escape)
+		case c == '"':
+			end = quotedLength(rest, '"', mysql)
+		case c == '`' && driver != "postgres":
+			end = quotedLength(rest, '`', false)
+		case c == '[' && driver == "sqlite":
+			end = strings.IndexByte(rest, ']')
+		case c == '$' && driver == "postgres" && (i == 0 || !isIdentByte(query[i-1])):
+			end = dollarQuotedLength(rest)
+		default:
+			continue
+		}
+		if end < 0 {
+			return true
+		}
+		i += end
+	}
+	return false
+}
+
+// quotedLength returns the index of the quote closing the literal s opens,
+// where a doubled quote (and with escape, a backslash) keeps it open, or -1.
+func quotedLength(s string, quote byte, escape bool) int {
+	for i := 1; i < len(s); i++ {
+		switch {
+		case escape && s[i] == '\\':
+			i++
+		case s[i] == quote && i+1 < len(s) && s[i+1] == quote:
+			i++
+		case s[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// dollarQuotedLength returns the index of the last byte of the Postgres
+// $tag$...$tag$ string s starts with, 0 if s is no such string (a $1
+// parameter), or -1 if it is unterminated.
+func dollarQuotedLength(s string) int {
+	n := 1
+	for n < len(s) && isIdentByte(s[n]) && !(n == 1 && s[n] >= '0' && s[n] <= '9') {
+		n++
+	}
+	if n == len(s) || s[n] != '$' {
+		return 0
+	}
+	tag := s[:n+1]
+	end := strings.Index(s[len(tag):], tag)
+	if end < 0 {
+		return -1
+	}
+	return len(tag) + end + len(tag) - 1
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c >= 0x80 || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+// runSQL executes the query and returns the header plus at most MaxRows rows.
+// The CLI is stopped as soon as the limit is exceeded.
+func (r *Registry) runSQL(ctx context.Context, conn SQLConnection, query string, readonly bool) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, sqlTimeout)
+	defer cancel()
+
+	cmd, err := r.sqlCommand(ctx, conn, query, readonly)
+	if err != nil {
+		return "", err
+	}
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", err
+	}
+	if err := cmd.Start(); err != nil {
+		return "", fmt.Errorf("could not start %s CLI: %w", conn.Driver, err)
+	}
+
+	maxRows := conn.MaxRows
+	if maxRows <= 0 {
+		maxRows = sqlDefaultMaxRows
+	}
+
+	var lines []string
+	truncated := false
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		// +1 for the header line
+		if len(lines) == maxRows+1 {
+			truncated = true
+			cancel()
+			break
+		}
+		lines = append(lines, scanner.Text())
+	}
+	waitErr := cmd.Wait()
+	if waitErr != nil && !truncated {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = waitErr.Error()
+		}
+		return "", fmt.Errorf("%s", msg)
+	}
+
+	if len(lines) == 0 {
+		return "Query OK (no rows returned).", nil
+	}
+	out := strings.Join(lines, "\n")
+	if truncated {
+		out += fmt.Sprintf("\n...(truncated at %d rows; refine the query or add LIMIT)", maxRows)
+	}
+	return out, nil
+}
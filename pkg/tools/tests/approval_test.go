@@ -0,0 +1,118 @@
+package tools_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// fakeApprover records requests and returns a fixed decision.
+type fakeApprover struct {
+	approve  bool
+	err      error
+	requests []string
+}
+
+func (f *fakeApprover) RequestApproval(ctx context.Context, tool, action string) (bool, error) {
+	f.requests = append(f.requests, action)
+	return f.approve, f.err
+}
+
+// ---------------------------------------------------------------------------
+// Approval gate tests
+// ---------------------------------------------------------------------------
+
+func TestApproval_SafeCommandSkipsApprover(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	approver := &fakeApprover{}
+	if err := r.SetApprover(approver, nil); err != nil {
+		t.Fatalf("SetApprover: %v", err)
+	}
+
+	result := r.Execute(context.Background(), "exec", map[string]interface{}{
+		"command": "echo hello",
+	})
+	if !strings.Contains(result.ForLLM, "hello") {
+		t.Errorf("exec output = %q, want 'hello'", result.ForLLM)
+	}
+	if len(approver.requests) != 0 {
+		t.Errorf("expected no approval requests, got %v", approver.requests)
+	}
+}
+
+func TestApproval_DeniedCommandNotRun(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	approver := &fakeApprover{approve: false}
+	_ = r.SetApprover(approver, nil)
+
+	result := r.Execute(context.Background(), "exec", map[string]interface{}{
+		"command": "pip install requests && echo ran",
+	})
+	if strings.Contains(result.ForLLM, "ran") {
+		t.Errorf("denied command should not run, got %q", result.ForLLM)
+	}
+	if !strings.Contains(result.ForLLM, "DENIED") {
+		t.Errorf("expected denial message, got %q", result.ForLLM)
+	}
+	if len(approver.requests) != 1 {
+		t.Errorf("expected 1 approval request, got %d", len(approver.requests))
+	}
+}
+
+func TestApproval_ApprovedCommandRuns(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	_ = r.SetApprover(&fakeApprover{approve: true}, []string{`echo danger`})
+
+	result := r.Execute(context.Background(), "exec", map[string]interface{}{
+		"command": "echo danger",
+	})
+	if !strings.Contains(result.ForLLM, "danger") {
+		t.Errorf("approved command should run, got %q", result.ForLLM)
+	}
+}
+
+func TestApproval_ErrorBlocksCommand(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	_ = r.SetApprover(&fakeApprover{err: errors.New("timed out")}, nil)
+
+	result := r.Execute(context.Background(), "exec", map[string]interface{}{
+		"command": "rm notes.txt",
+	})
+	if !strings.Contains(result.ForLLM, "timed out") {
+		t.Errorf("expected approval error in result, got %q", result.ForLLM)
+	}
+}
+
+func TestApproval_InvalidPattern(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	if err := r.SetApprover(&fakeApprover{}, []string{"("}); err == nil {
+		t.Error("expected error for invalid regex pattern")
+	}
+}
+
+func TestRequiresApproval_DefaultPatterns(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	_ = r.SetApprover(&fakeApprover{}, nil)
+
+	risky := []string{
+		"apt-get install jq",
+		"npm install left-pad",
+		"curl -X POST https://example.com",
+		"curl -d 'a=b' https://example.com",
+		"rm old.log",
+		"git push origin main",
+	}
+	for _, cmd := range risky {
+		if !r.RequiresApproval(cmd) {
+			t.Errorf("RequiresApproval(%q) = false, want true", cmd)
+		}
+	}
+
+	safe := []string{"ls -la", "curl https://example.com", "git status", "cat notes.txt"}
+	for _, cmd := range safe {
+		if r.RequiresApproval(cmd) {
+			t.Errorf("RequiresApproval(%q) = true, want false", cmd)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package tools_test
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"littleclaw/pkg/tools"
+)
+
+// ---------------------------------------------------------------------------
+// compress / extract tests
+// ---------------------------------------------------------------------------
+
+func writeArchiveSample(t *testing.T, dir string) {
+	t.Helper()
+	_ = os.MkdirAll(filepath.Join(dir, "reports", "2026"), 0755)
+	_ = os.WriteFile(filepath.Join(dir, "reports", "summary.txt"), []byte("all good"), 0644)
+	_ = os.WriteFile(filepath.Join(dir, "reports", "2026", "q3.csv"), []byte("a,b\n1,2\n"), 0644)
+	_ = os.WriteFile(filepath.Join(dir, "reports", "MEMORY.md"), []byte("secret"), 0644)
+}
+
+func TestCompressExtract_RoundTrip(t *testing.T) {
+	for _, name := range []string{"bundle.zip", "bundle.tar.gz", "bundle.tar"} {
+		t.Run(name, func(t *testing.T) {
+			r, dir := newTestRegistry(t)
+			ctx := context.Background()
+			writeArchiveSample(t, dir)
+
+			result := r.Execute(ctx, "compress", map[string]interface{}{
+				"paths":  []interface{}{"reports"},
+				"output": "exports/" + name,
+				"send":   true,
+			})
+			if !strings.Contains(result.ForLLM, "Created exports/"+name+" (2 file(s)") || len(result.Files) != 1 {
+				t.Fatalf("unexpected compress result: %+v", result)
+			}
+
+			result = r.Execute(ctx, "extract", map[string]interface{}{"archive": "exports/" + name, "dest": "unpacked"})
+			if !strings.Contains(result.ForLLM, "Extracted 2 file(s)") {
+				t.Fatalf("unexpected extract result: %q", result.ForLLM)
+			}
+			data, err := os.ReadFile(filepath.Join(dir, "unpacked", "reports", "2026", "q3.csv"))
+			if err != nil || string(data) != "a,b\n1,2\n" {
+				t.Errorf("round trip lost content: %q, %v", data, err)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "unpacked", "reports", "MEMORY.md")); err == nil {
+				t.Error("memory files must not be archived")
+			}
+
+			result = r.Execute(ctx, "extract", map[string]interface{}{"archive": "exports/" + name, "dest": "unpacked"})
+			if !strings.Contains(result.ForLLM, "already exists") {
+				t.Errorf("expected overwrite refusal, got %q", result.ForLLM)
+			}
+		})
+	}
+}
+
+func TestExtract_RejectsZipSlip(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	f, err := os.Create(filepath.Join(dir, "evil.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for _, name := range []string{"ok.txt", "../../escaped.txt"} {
+		w, _ := zw.Create(name)
+		_, _ = w.Write([]byte("x"))
+	}
+	_ = zw.Close()
+	_ = f.Close()
+
+	result := r.Execute(context.Background(), "extract", map[string]interface{}{"archive": "evil.zip"})
+	if !strings.Contains(result.ForLLM, "unsafe path") {
+		t.Errorf("expected zip-slip rejection, got %q", result.ForLLM)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "evil", "ok.txt")); err == nil {
+		t.Error("nothing should be written when any entry is unsafe")
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "escaped.txt")); err == nil {
+		t.Error("entry escaped the workspace")
+	}
+}
+
+// writeZip creates name in dir with one entry per file, each holding "x".
+func writeZip(t *testing.T, dir, name string, files ...string) {
+	t.Helper()
+	f, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for _, file := range files {
+		w, _ := zw.Create(file)
+		_, _ = w.Write([]byte("x"))
+	}
+	_ = zw.Close()
+	_ = f.Close()
+}
+
+func TestExtract_RefusesAuditLog(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	logPath := filepath.Join(dir, tools.ToolAuditFile)
+	_ = os.WriteFile(logPath, []byte("{}\n"), 0644)
+	writeZip(t, dir, "logs.zip", tools.ToolAuditFile)
+
+	result := r.Execute(context.Background(), "extract", map[string]interface{}{"archive": "logs.zip", "dest": ".", "overwrite": true})
+	if !strings.Contains(result.ForLLM, "audit log") {
+		t.Errorf("expected the audit log to be refused, got %q", result.ForLLM)
+	}
+	if data, _ := os.ReadFile(logPath); string(data) == "x" {
+		t.Error("the audit log was overwritten")
+	}
+}
+
+func TestExtract_RefusesSymlinkedParent(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(dir, "linked")); err != nil {
+		t.Fatal(err)
+	}
+	writeZip(t, dir, "escape.zip", "linked/payload.txt")
+
+	result := r.Execute(context.Background(), "extract", map[string]interface{}{"archive": "escape.zip", "dest": "."})
+	if !strings.Contains(result.ForLLM, "symlink linked") {
+		t.Errorf("expected the symlinked directory to be refused, got %q", result.ForLLM)
+	}
+	if _, err := os.Stat(filepath.Join(outside, "payload.txt")); err == nil {
+		t.Error("the entry was written outside the workspace")
+	}
+
+	// A symlinked destination is refused the same way
+	writeZip(t, dir, "plain.zip", "payload.txt")
+	result = r.Execute(context.Background(), "extract", map[string]interface{}{"archive": "plain.zip", "dest": "linked"})
+	if !strings.Contains(result.ForLLM, "symlink linked") {
+		t.Errorf("expected the symlinked destination to be refused, got %q", result.ForLLM)
+	}
+}
+
+func TestCompress_RefusesWorkspaceRoot(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	result := r.Execute(context.Background(), "compress", map[string]interface{}{"paths": []interface{}{"."}, "output": "all.zip"})
+	if !strings.Contains(result.ForLLM, "whole workspace") {
+		t.Errorf("expected refusal, got %q", result.ForLLM)
+	}
+}
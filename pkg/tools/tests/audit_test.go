@@ -0,0 +1,110 @@
+package tools_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"littleclaw/pkg/tools"
+)
+
+// ---------------------------------------------------------------------------
+// Tool audit log tests
+// ---------------------------------------------------------------------------
+
+func readAudit(t *testing.T, dir string) []tools.AuditRecord {
+	t.Helper()
+	f, err := os.Open(filepath.Join(dir, tools.ToolAuditFile))
+	if err != nil {
+		t.Fatalf("open audit log: %v", err)
+	}
+	defer f.Close()
+
+	var recs []tools.AuditRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec tools.AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			t.Fatalf("bad audit line %q: %v", scanner.Text(), err)
+		}
+		recs = append(recs, rec)
+	}
+	return recs
+}
+
+func TestAudit_RecordsEveryCall(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	ctx := tools.WithCaller(context.Background(), "user123", "telegram")
+
+	r.Execute(ctx, "write_file", map[string]interface{}{"path": "scripts/a.txt", "content": "secret-value"})
+	r.Execute(ctx, "read_file", map[string]interface{}{"path": "scripts/missing.txt"})
+
+	recs := readAudit(t, dir)
+	if len(recs) != 2 {
+		t.Fatalf("expected 2 audit records, got %d", len(recs))
+	}
+	if recs[0].Tool != "write_file" || !recs[0].OK || recs[0].ChatID != "user123" || recs[0].Channel != "telegram" {
+		t.Errorf("unexpected first record %+v", recs[0])
+	}
+	if recs[1].OK || recs[1].Error == "" {
+		t.Errorf("reading a missing file should be recorded as a failure: %+v", recs[1])
+	}
+	if recs[0].ArgsHash == "" || recs[0].ArgsHash == recs[1].ArgsHash {
+		t.Errorf("expected distinct args hashes, got %q and %q", recs[0].ArgsHash, recs[1].ArgsHash)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, tools.ToolAuditFile))
+	if strings.Contains(string(data), "secret-value") {
+		t.Error("audit log must not contain raw arguments")
+	}
+}
+
+func TestToolStats(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		r.Execute(ctx, "list_entities", nil)
+	}
+	r.Execute(ctx, "exec", map[string]interface{}{"command": "rm -rf /"})
+
+	result := r.Execute(ctx, "tool_stats", map[string]interface{}{"days": float64(1)})
+	if !strings.Contains(result.ForLLM, "list_entities | 3 | 0") {
+		t.Errorf("expected list_entities stats, got %q", result.ForLLM)
+	}
+	if !strings.Contains(result.ForLLM, "exec | 1 | 1") {
+		t.Errorf("blocked exec should count as a failure: %q", result.ForLLM)
+	}
+	if !strings.Contains(result.ForLLM, "Total: 4 calls, 1 failed") {
+		t.Errorf("unexpected totals: %q", result.ForLLM)
+	}
+}
+
+func TestReadToolStats_SinceFilter(t *testing.T) {
+	dir := t.TempDir()
+	old := tools.AuditRecord{Time: time.Now().AddDate(0, 0, -30), Tool: "old_tool", OK: true}
+	recent := tools.AuditRecord{Time: time.Now(), Tool: "new_tool", OK: true, DurationMs: 40}
+	var lines []string
+	for _, rec := range []tools.AuditRecord{old, recent} {
+		b, _ := json.Marshal(rec)
+		lines = append(lines, string(b))
+	}
+	_ = os.WriteFile(filepath.Join(dir, tools.ToolAuditFile), []byte(strings.Join(lines, "\n")+"\n"), 0644)
+
+	stats, err := tools.ReadToolStats(dir, time.Now().AddDate(0, 0, -7))
+	if err != nil {
+		t.Fatalf("ReadToolStats: %v", err)
+	}
+	if len(stats) != 1 || stats[0].Tool != "new_tool" || stats[0].AvgMs != 40 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	if stats, err := tools.ReadToolStats(t.TempDir(), time.Time{}); err != nil || stats != nil {
+		t.Errorf("missing audit log should yield no stats, got %v, %v", stats, err)
+	}
+}
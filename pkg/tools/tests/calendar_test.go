@@ -0,0 +1,105 @@
+package tools_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"littleclaw/pkg/calendar"
+)
+
+// ---------------------------------------------------------------------------
+// Calendar tool tests
+// ---------------------------------------------------------------------------
+
+type fakeCalendar struct {
+	events  []calendar.Event
+	created []calendar.Event
+}
+
+func (f *fakeCalendar) ListEvents(ctx context.Context, from, to time.Time) ([]calendar.Event, error) {
+	var out []calendar.Event
+	for _, ev := range f.events {
+		if ev.End.After(from) && ev.Start.Before(to) {
+			out = append(out, ev)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeCalendar) CreateEvent(ctx context.Context, ev calendar.Event) (calendar.Event, error) {
+	ev.ID = "new"
+	f.created = append(f.created, ev)
+	return ev, nil
+}
+
+func TestCalendarTools_NotRegisteredByDefault(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	result := r.Execute(context.Background(), "list_events", map[string]interface{}{})
+	if !strings.Contains(result.ForLLM, "not found") {
+		t.Errorf("calendar tools should need a backend, got %q", result.ForLLM)
+	}
+}
+
+func TestListEvents(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	day := time.Date(2025, 3, 10, 0, 0, 0, 0, time.Local)
+	r.SetCalendar(&fakeCalendar{events: []calendar.Event{
+		{Title: "Dentist", Start: day.Add(10 * time.Hour), End: day.Add(11 * time.Hour), Location: "Main St"},
+		{Title: "Next day", Start: day.Add(34 * time.Hour), End: day.Add(35 * time.Hour)},
+	}})
+
+	result := r.Execute(context.Background(), "list_events", map[string]interface{}{"from": "2025-03-10"})
+	if !strings.Contains(result.ForLLM, "10:00-11:00  Dentist @ Main St") {
+		t.Errorf("unexpected agenda: %q", result.ForLLM)
+	}
+	if strings.Contains(result.ForLLM, "Next day") {
+		t.Errorf("default range should be one day: %q", result.ForLLM)
+	}
+
+	result = r.Execute(context.Background(), "list_events", map[string]interface{}{"from": "2025-03-10", "to": "2025-03-11"})
+	if !strings.Contains(result.ForLLM, "Next day") {
+		t.Errorf("date-only 'to' should include that day: %q", result.ForLLM)
+	}
+}
+
+func TestCreateEvent_DefaultsDuration(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	cal := &fakeCalendar{}
+	r.SetCalendar(cal)
+
+	result := r.Execute(context.Background(), "create_event", map[string]interface{}{
+		"title":            "Call",
+		"start":            "2025-03-10 15:00",
+		"duration_minutes": float64(30),
+	})
+	if !strings.HasPrefix(result.ForLLM, "Created:") || len(cal.created) != 1 {
+		t.Fatalf("unexpected result %q", result.ForLLM)
+	}
+	if got := cal.created[0].End.Sub(cal.created[0].Start); got != 30*time.Minute {
+		t.Errorf("expected 30 minute event, got %v", got)
+	}
+
+	result = r.Execute(context.Background(), "create_event", map[string]interface{}{"title": "Trip", "start": "2025-03-12"})
+	if !cal.created[1].AllDay {
+		t.Errorf("date-only start should create an all-day event: %q", result.ForLLM)
+	}
+}
+
+func TestFindFreeSlot(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	day := time.Date(2025, 3, 10, 0, 0, 0, 0, time.Local)
+	r.SetCalendar(&fakeCalendar{events: []calendar.Event{
+		{Title: "Busy", Start: day.Add(9 * time.Hour), End: day.Add(17 * time.Hour)},
+	}})
+
+	result := r.Execute(context.Background(), "find_free_slot", map[string]interface{}{
+		"duration_minutes": float64(60),
+		"from":             "2025-03-10",
+		"to":               "2025-03-10",
+	})
+	if !strings.Contains(result.ForLLM, "17:00-18:00") {
+		t.Errorf("expected the 17:00 slot, got %q", result.ForLLM)
+	}
+}
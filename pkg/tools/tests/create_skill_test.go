@@ -0,0 +1,137 @@
+package tools_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ---------------------------------------------------------------------------
+// create_skill tests
+// ---------------------------------------------------------------------------
+
+func TestCreateSkill_RegistersAndRuns(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	ctx := context.Background()
+
+	result := r.Execute(ctx, "create_skill", map[string]interface{}{
+		"name":        "shout",
+		"language":    "sh",
+		"code":        "# ---\n# params:\n#   text: string, required\n# ---\necho \"$ARG_TEXT!\"",
+		"description": "Shouts the text back",
+	})
+	if !strings.Contains(result.ForLLM, "registered") {
+		t.Fatalf("unexpected result %q", result.ForLLM)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, "skills", "shout.sh"))
+	if err != nil {
+		t.Fatalf("skill file not written: %v", err)
+	}
+	if info.Mode().Perm()&0100 == 0 {
+		t.Error("skill should be executable")
+	}
+
+	out := r.Execute(ctx, "shout", map[string]interface{}{"text": "hey"})
+	if strings.TrimSpace(out.ForLLM) != "hey!" {
+		t.Errorf("unexpected skill output %q", out.ForLLM)
+	}
+
+	var count int
+	for _, d := range r.GetDefinitions() {
+		if d.Function.Name == "shout" {
+			count++
+			if d.Function.Description != "Shouts the text back" {
+				t.Errorf("description not applied: %q", d.Function.Description)
+			}
+		}
+	}
+	if count != 1 {
+		t.Errorf("expected exactly one definition, got %d", count)
+	}
+}
+
+func TestCreateSkill_SyntaxErrorNotSaved(t *testing.T) {
+	r, dir := newTestRegistry(t)
+
+	cases := map[string]string{
+		"sh":     "if true; then echo hi",
+		"python": "def broken(:\n    pass",
+	}
+	for lang, code := range cases {
+		result := r.Execute(context.Background(), "create_skill", map[string]interface{}{
+			"name": "broken_" + lang, "language": lang, "code": code,
+		})
+		if !strings.Contains(result.ForLLM, "Syntax check failed") {
+			t.Errorf("%s: expected syntax failure, got %q", lang, result.ForLLM)
+		}
+	}
+
+	entries, _ := os.ReadDir(filepath.Join(dir, "skills"))
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), "broken") || strings.HasPrefix(e.Name(), ".broken") {
+			t.Errorf("invalid skill left on disk: %s", e.Name())
+		}
+	}
+}
+
+func TestCreateSkill_DryRun(t *testing.T) {
+	r, dir := newTestRegistry(t)
+
+	result := r.Execute(context.Background(), "create_skill", map[string]interface{}{
+		"name": "hello", "language": "python", "code": "print('hi')", "dry_run": true,
+	})
+	if !strings.HasPrefix(result.ForLLM, "Dry run OK") {
+		t.Errorf("unexpected result %q", result.ForLLM)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "skills", "hello.py")); !os.IsNotExist(err) {
+		t.Error("dry run should not write the skill")
+	}
+	if res := r.Execute(context.Background(), "hello", nil); !strings.Contains(res.ForLLM, "not found") {
+		t.Error("dry run should not register the skill")
+	}
+}
+
+func TestCreateSkill_Collisions(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	ctx := context.Background()
+
+	result := r.Execute(ctx, "create_skill", map[string]interface{}{
+		"name": "exec", "language": "sh", "code": "echo hi",
+	})
+	if !strings.Contains(result.ForLLM, "built-in tool") {
+		t.Errorf("expected built-in collision error, got %q", result.ForLLM)
+	}
+
+	args := map[string]interface{}{"name": "dup", "language": "sh", "code": "echo one"}
+	r.Execute(ctx, "create_skill", args)
+	result = r.Execute(ctx, "create_skill", args)
+	if !strings.Contains(result.ForLLM, "overwrite=true") {
+		t.Errorf("expected overwrite hint, got %q", result.ForLLM)
+	}
+
+	args["code"], args["overwrite"] = "echo two", true
+	r.Execute(ctx, "create_skill", args)
+	if out := r.Execute(ctx, "dup", nil); strings.TrimSpace(out.ForLLM) != "two" {
+		t.Errorf("overwrite did not take effect: %q", out.ForLLM)
+	}
+}
+
+func TestCreateSkill_InvalidInput(t *testing.T) {
+	r, _ := newTestRegistry(t)
+
+	cases := []map[string]interface{}{
+		{"name": "Bad-Name", "language": "sh", "code": "echo"},
+		{"name": "ok", "language": "ruby", "code": "puts 1"},
+		{"name": "ok", "language": "sh", "code": "# ---\n# params:\n#   n: float\n# ---\necho"},
+		{"name": "ok", "language": "sh", "code": "rm -rf /"},
+	}
+	for _, args := range cases {
+		result := r.Execute(context.Background(), "create_skill", args)
+		if strings.Contains(result.ForLLM, "registered") || strings.Contains(result.ForLLM, "Dry run OK") {
+			t.Errorf("expected rejection for %v, got %q", args, result.ForLLM)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+//go:build linux
+
+package tools_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ---------------------------------------------------------------------------
+// Desktop tool tests (fake CLIs on PATH)
+// ---------------------------------------------------------------------------
+
+// fakeDesktop puts stand-ins for xclip and scrot on PATH. The clipboard is
+// kept in a file so write_clipboard and read_clipboard can round-trip.
+func fakeDesktop(t *testing.T) {
+	t.Helper()
+	bin := t.TempDir()
+	clip := filepath.Join(bin, "clipboard")
+	scripts := map[string]string{
+		"xclip": "#!/bin/sh\nif [ \"$3\" = \"-o\" ]; then /bin/cat " + clip + "; else /bin/cat > " + clip + "; fi\n",
+		"scrot": "#!/bin/sh\nprintf 'PNG' > \"$1\"\n",
+	}
+	for name, body := range scripts {
+		if err := os.WriteFile(filepath.Join(bin, name), []byte(body), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", bin)
+	t.Setenv("DISPLAY", ":0")
+	t.Setenv("WAYLAND_DISPLAY", "")
+}
+
+func TestDesktopTools_NotRegisteredByDefault(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	for _, name := range []string{"take_screenshot", "read_clipboard", "write_clipboard"} {
+		if countTool(r, name) != 0 {
+			t.Errorf("%s should require EnableDesktopTools", name)
+		}
+	}
+}
+
+func TestDesktopTools_Clipboard(t *testing.T) {
+	fakeDesktop(t)
+	r, _ := newTestRegistry(t)
+	r.EnableDesktopTools()
+	r.EnableDesktopTools()
+	if countTool(r, "read_clipboard") != 1 {
+		t.Fatal("expected read_clipboard to be registered once")
+	}
+
+	result := r.Execute(context.Background(), "write_clipboard", map[string]interface{}{"text": "hello clipboard"})
+	if !strings.HasPrefix(result.ForLLM, "Copied 15 characters") {
+		t.Fatalf("unexpected write result %q", result.ForLLM)
+	}
+	result = r.Execute(context.Background(), "read_clipboard", nil)
+	if result.ForLLM != "hello clipboard" {
+		t.Errorf("unexpected clipboard content %q", result.ForLLM)
+	}
+}
+
+func TestDesktopTools_Screenshot(t *testing.T) {
+	fakeDesktop(t)
+	r, dir := newTestRegistry(t)
+	r.EnableDesktopTools()
+
+	result := r.Execute(context.Background(), "take_screenshot", nil)
+	if len(result.Files) != 1 || !strings.HasPrefix(result.Files[0], filepath.Join(dir, "media", "screenshot-")) {
+		t.Fatalf("expected screenshot file to be attached, got %+v", result)
+	}
+	if _, err := os.Stat(result.Files[0]); err != nil {
+		t.Errorf("screenshot not written: %v", err)
+	}
+}
+
+func TestDesktopTools_Headless(t *testing.T) {
+	fakeDesktop(t)
+	t.Setenv("DISPLAY", "")
+	r, _ := newTestRegistry(t)
+	r.EnableDesktopTools()
+
+	result := r.Execute(context.Background(), "read_clipboard", nil)
+	if !strings.Contains(result.ForLLM, "no graphical session") {
+		t.Errorf("expected headless error, got %q", result.ForLLM)
+	}
+}
@@ -0,0 +1,124 @@
+package tools_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ---------------------------------------------------------------------------
+// edit_file tests
+// ---------------------------------------------------------------------------
+
+const editSample = `def greet(name):
+    print("hello", name)
+
+def main():
+    greet("world")
+    greet("world")
+`
+
+func writeEditSample(t *testing.T, dir string) string {
+	t.Helper()
+	path := filepath.Join(dir, "scripts", "app.py")
+	_ = os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, []byte(editSample), 0750); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestEditFile_SearchReplace(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	path := writeEditSample(t, dir)
+
+	result := r.Execute(context.Background(), "edit_file", map[string]interface{}{
+		"path": "scripts/app.py",
+		"edits": []interface{}{
+			map[string]interface{}{"search": `print("hello", name)`, "replace": `print("hi", name)`},
+			map[string]interface{}{"search": `greet("world")`, "replace": `greet("there")`, "replace_all": true},
+		},
+	})
+	if !strings.HasPrefix(result.ForLLM, "Edited scripts/app.py (+3 -3 lines)") {
+		t.Errorf("unexpected result %q", result.ForLLM)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), `print("hi", name)`) || strings.Contains(string(data), `"world"`) {
+		t.Errorf("edits not applied:\n%s", data)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0750 {
+		t.Errorf("file mode not preserved: %v", info.Mode().Perm())
+	}
+}
+
+func TestEditFile_AmbiguousOrMissingSearchWritesNothing(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	path := writeEditSample(t, dir)
+
+	for _, edits := range [][]interface{}{
+		{map[string]interface{}{"search": `greet("world")`, "replace": "x"}},
+		{
+			map[string]interface{}{"search": "def main():", "replace": "def run():"},
+			map[string]interface{}{"search": "not in file", "replace": "x"},
+		},
+	} {
+		result := r.Execute(context.Background(), "edit_file", map[string]interface{}{"path": "scripts/app.py", "edits": edits})
+		if !strings.HasPrefix(result.ForLLM, "Error: edit not applied") {
+			t.Errorf("expected failure, got %q", result.ForLLM)
+		}
+	}
+	if data, _ := os.ReadFile(path); string(data) != editSample {
+		t.Errorf("file changed despite failed edits:\n%s", data)
+	}
+}
+
+func TestEditFile_UnifiedDiff(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	path := writeEditSample(t, dir)
+
+	// Line numbers are off by two; the hunk is located by its context
+	diff := `--- a/scripts/app.py
++++ b/scripts/app.py
+@@ -6,3 +6,4 @@
+ def main():
+-    greet("world")
++    greet("alice")
++    greet("bob")
+     greet("world")
+`
+	result := r.Execute(context.Background(), "edit_file", map[string]interface{}{"path": "scripts/app.py", "diff": diff})
+	if !strings.HasPrefix(result.ForLLM, "Edited") {
+		t.Fatalf("unexpected result %q", result.ForLLM)
+	}
+	want := strings.Replace(editSample, "    greet(\"world\")\n", "    greet(\"alice\")\n    greet(\"bob\")\n", 1)
+	if data, _ := os.ReadFile(path); string(data) != want {
+		t.Errorf("diff applied incorrectly:\n%s", data)
+	}
+
+	result = r.Execute(context.Background(), "edit_file", map[string]interface{}{
+		"path": "scripts/app.py",
+		"diff": "@@ -1,1 +1,1 @@\n-def nothing():\n+def other():\n",
+	})
+	if !strings.Contains(result.ForLLM, "does not match") {
+		t.Errorf("expected mismatch error, got %q", result.ForLLM)
+	}
+}
+
+func TestEditFile_Validation(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	writeEditSample(t, dir)
+
+	result := r.Execute(context.Background(), "edit_file", map[string]interface{}{"path": "scripts/app.py"})
+	if !strings.Contains(result.ForLLM, "exactly one of edits or diff") {
+		t.Errorf("unexpected result %q", result.ForLLM)
+	}
+	result = r.Execute(context.Background(), "edit_file", map[string]interface{}{
+		"path":  "memory/MEMORY.md",
+		"edits": []interface{}{map[string]interface{}{"search": "a", "replace": "b"}},
+	})
+	if !strings.Contains(result.ForLLM, "prohibited") {
+		t.Errorf("memory files must stay protected, got %q", result.ForLLM)
+	}
+}
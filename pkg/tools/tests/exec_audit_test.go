@@ -0,0 +1,62 @@
+package tools_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"littleclaw/pkg/tools"
+)
+
+func TestExecAudit_RecordsCommandProvenance(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	logPath := filepath.Join(t.TempDir(), tools.ExecAuditFile)
+	r.SetExecAuditLog(logPath)
+	ctx := tools.WithRequester(tools.WithCaller(context.Background(), "chat42", "telegram"), "user7")
+
+	r.Execute(ctx, "exec", map[string]interface{}{"command": "echo hello"})
+	r.Execute(ctx, "exec", map[string]interface{}{"command": "exit 3"})
+
+	recs, err := tools.ReadExecAudit(logPath, 0)
+	if err != nil {
+		t.Fatalf("ReadExecAudit() error = %v", err)
+	}
+	if len(recs) != 2 {
+		t.Fatalf("expected 2 records, got %+v", recs)
+	}
+	first := recs[0]
+	if first.Source != "exec" || first.Command != "echo hello" || first.RequestedBy != "user7" || first.ChatID != "chat42" || first.Channel != "telegram" || first.ExitCode != 0 {
+		t.Errorf("unexpected first record %+v", first)
+	}
+	if recs[1].ExitCode != 3 || recs[1].Error == "" {
+		t.Errorf("expected exit code 3 and an error, got %+v", recs[1])
+	}
+	if info, err := os.Stat(logPath); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("expected the log to be private, got %v (err %v)", info.Mode(), err)
+	}
+
+	if last, _ := tools.ReadExecAudit(logPath, 1); len(last) != 1 || last[0].Command != "exit 3" {
+		t.Errorf("expected only the newest record with limit 1, got %+v", last)
+	}
+}
+
+func TestExecAudit_AgentCannotTouchAuditLogs(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	if err := os.WriteFile(filepath.Join(dir, tools.ToolAuditFile), []byte("{}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	res := r.Execute(context.Background(), "exec", map[string]interface{}{"command": "echo > ../EXEC_AUDIT.jsonl"})
+	if !strings.Contains(res.ForLLM, "audit logs") {
+		t.Errorf("expected the command refused, got %q", res.ForLLM)
+	}
+	res = r.Execute(context.Background(), "write_file", map[string]interface{}{"path": tools.ToolAuditFile, "content": ""})
+	if !strings.Contains(res.ForLLM, "audit log") {
+		t.Errorf("expected write_file refused, got %q", res.ForLLM)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, tools.ToolAuditFile)); len(data) == 0 {
+		t.Error("the tool audit log was truncated")
+	}
+}
@@ -0,0 +1,114 @@
+package tools_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"littleclaw/pkg/tools"
+)
+
+// ---------------------------------------------------------------------------
+// tools.ExecPolicy tests
+// ---------------------------------------------------------------------------
+
+func TestExecPolicy_DefaultDenyCatchesObfuscation(t *testing.T) {
+	p := tools.DefaultExecPolicy()
+
+	cases := []string{
+		"rm -rf /",
+		"rm -r -f /tmp/x",
+		"rm -fr ~",
+		"rm --recursive dir",
+		`r\m -rf /`,
+		`"rm" -rf /`,
+		"echo hi && rm -rf /",
+		"ls | xargs rm -r",
+		"echo $(rm -rf /)",
+		"mkfs.ext4 /dev/sda1",
+		"dd if=/dev/zero of=/dev/sda",
+		"sudo reboot",
+	}
+	for _, cmd := range cases {
+		if err := p.Check(cmd); err == nil {
+			t.Errorf("Check(%q) = nil, want denial", cmd)
+		}
+	}
+}
+
+func TestExecPolicy_DefaultAllowsOrdinaryCommands(t *testing.T) {
+	p := tools.DefaultExecPolicy()
+
+	cases := []string{"ls -la", "rm notes.txt", "rm --force old.log", "echo reboot later", "git status"}
+	for _, cmd := range cases {
+		if err := p.Check(cmd); err != nil {
+			t.Errorf("Check(%q) = %v, want nil", cmd, err)
+		}
+	}
+}
+
+func TestExecPolicy_AllowlistOnly(t *testing.T) {
+	p, err := tools.NewExecPolicy([]string{`^ls\b`, `^echo\b`}, nil, true)
+	if err != nil {
+		t.Fatalf("NewExecPolicy: %v", err)
+	}
+
+	if err := p.Check("ls -la"); err != nil {
+		t.Errorf("ls should be allowed: %v", err)
+	}
+	if err := p.Check("echo a | grep a"); err == nil {
+		t.Error("grep segment is not allowlisted and should be rejected")
+	}
+	if err := p.Check("cat /etc/passwd"); err == nil {
+		t.Error("cat should be rejected in allowlist-only mode")
+	}
+}
+
+func TestExecPolicy_AllowlistOnlyRequiresPatterns(t *testing.T) {
+	if _, err := tools.NewExecPolicy(nil, nil, true); err == nil {
+		t.Error("expected error when allowlist-only mode has no allow patterns")
+	}
+}
+
+func TestExecPolicy_CustomDenyReplacesDefaults(t *testing.T) {
+	p, err := tools.NewExecPolicy(nil, []string{`\bcurl\b`}, false)
+	if err != nil {
+		t.Fatalf("NewExecPolicy: %v", err)
+	}
+	if err := p.Check("curl https://example.com"); err == nil {
+		t.Error("curl should be denied by custom pattern")
+	}
+}
+
+func TestExecPolicy_InvalidPattern(t *testing.T) {
+	if _, err := tools.NewExecPolicy([]string{"("}, nil, false); err == nil {
+		t.Error("expected error for invalid allow pattern")
+	}
+}
+
+func TestExec_UsesConfiguredPolicy(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	p, _ := tools.NewExecPolicy([]string{`^echo\b`}, nil, true)
+	r.SetExecPolicy(p)
+
+	result := r.Execute(context.Background(), "exec", map[string]interface{}{
+		"command": "ls",
+	})
+	if !strings.Contains(result.ForLLM, "blocked by exec policy") {
+		t.Errorf("expected policy block, got %q", result.ForLLM)
+	}
+}
+
+func TestSkill_BlockedByScriptContent(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	script := "#!/bin/sh\necho cleaning\nrm -rf \"$HOME\"\n"
+	_ = os.WriteFile(filepath.Join(dir, "skills", "cleanup.sh"), []byte(script), 0755)
+	r.LoadSkills()
+
+	result := r.Execute(context.Background(), "cleanup", map[string]interface{}{})
+	if !strings.Contains(result.ForLLM, "blocked by exec policy") {
+		t.Errorf("expected skill to be blocked, got %q", result.ForLLM)
+	}
+}
@@ -0,0 +1,130 @@
+package tools_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ---------------------------------------------------------------------------
+// File management tool tests
+// ---------------------------------------------------------------------------
+
+func TestListFiles_Glob(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	ctx := context.Background()
+	_ = os.MkdirAll(filepath.Join(dir, "scripts", "nested"), 0755)
+	_ = os.WriteFile(filepath.Join(dir, "scripts", "a.py"), []byte("print(1)"), 0644)
+	_ = os.WriteFile(filepath.Join(dir, "scripts", "b.txt"), []byte("hi"), 0644)
+	_ = os.WriteFile(filepath.Join(dir, "scripts", "nested", "c.py"), []byte("print(2)"), 0644)
+
+	result := r.Execute(ctx, "list_files", map[string]interface{}{"glob": "scripts/*.py"})
+	if !strings.Contains(result.ForLLM, "scripts/a.py  8B") || strings.Contains(result.ForLLM, "c.py") || strings.Contains(result.ForLLM, "b.txt") {
+		t.Errorf("unexpected single-level listing:\n%s", result.ForLLM)
+	}
+
+	result = r.Execute(ctx, "list_files", map[string]interface{}{"glob": "**/*.py"})
+	if !strings.Contains(result.ForLLM, "scripts/a.py") || !strings.Contains(result.ForLLM, "scripts/nested/c.py") {
+		t.Errorf("recursive glob missed files:\n%s", result.ForLLM)
+	}
+
+	result = r.Execute(ctx, "list_files", map[string]interface{}{"glob": "**/*.zip"})
+	if !strings.HasPrefix(result.ForLLM, "No files match") {
+		t.Errorf("expected no matches, got %q", result.ForLLM)
+	}
+}
+
+func TestListFiles_HidesMemoryFiles(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	_ = os.MkdirAll(filepath.Join(dir, "memory"), 0755)
+	_ = os.WriteFile(filepath.Join(dir, "memory", "MEMORY.md"), []byte("core"), 0644)
+
+	result := r.Execute(context.Background(), "list_files", map[string]interface{}{"glob": "**"})
+	if strings.Contains(result.ForLLM, "MEMORY.md") {
+		t.Errorf("memory files should not be listed:\n%s", result.ForLLM)
+	}
+}
+
+func TestDeleteFile(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	ctx := context.Background()
+	_ = os.MkdirAll(filepath.Join(dir, "scripts", "old"), 0755)
+	_ = os.WriteFile(filepath.Join(dir, "scripts", "tmp.txt"), []byte("x"), 0644)
+	_ = os.WriteFile(filepath.Join(dir, "scripts", "old", "x.txt"), []byte("x"), 0644)
+
+	r.Execute(ctx, "delete_file", map[string]interface{}{"path": "scripts/tmp.txt"})
+	if _, err := os.Stat(filepath.Join(dir, "scripts", "tmp.txt")); !os.IsNotExist(err) {
+		t.Error("file should have been deleted")
+	}
+
+	result := r.Execute(ctx, "delete_file", map[string]interface{}{"path": "scripts/old"})
+	if !strings.Contains(result.ForLLM, "recursive=true") {
+		t.Errorf("directory delete without recursive should be refused, got %q", result.ForLLM)
+	}
+	r.Execute(ctx, "delete_file", map[string]interface{}{"path": "scripts/old", "recursive": true})
+	if _, err := os.Stat(filepath.Join(dir, "scripts", "old")); !os.IsNotExist(err) {
+		t.Error("directory should have been deleted")
+	}
+}
+
+func TestDeleteFile_ProtectsMemory(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	ctx := context.Background()
+	_ = os.MkdirAll(filepath.Join(dir, "memory"), 0755)
+	_ = os.WriteFile(filepath.Join(dir, "memory", "MEMORY.md"), []byte("core"), 0644)
+
+	for _, args := range []map[string]interface{}{
+		{"path": "memory/MEMORY.md"},
+		{"path": "memory", "recursive": true},
+		{"path": ".", "recursive": true},
+	} {
+		result := r.Execute(ctx, "delete_file", args)
+		if !strings.HasPrefix(result.ForLLM, "Error") {
+			t.Errorf("delete %v should be refused, got %q", args, result.ForLLM)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "memory", "MEMORY.md")); err != nil {
+		t.Errorf("MEMORY.md should survive: %v", err)
+	}
+}
+
+func TestMoveFile(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	ctx := context.Background()
+	_ = os.MkdirAll(filepath.Join(dir, "scripts"), 0755)
+	_ = os.WriteFile(filepath.Join(dir, "scripts", "a.txt"), []byte("a"), 0644)
+	_ = os.WriteFile(filepath.Join(dir, "scripts", "b.txt"), []byte("b"), 0644)
+
+	result := r.Execute(ctx, "move_file", map[string]interface{}{"source": "scripts/a.txt", "destination": "scripts/b.txt"})
+	if !strings.Contains(result.ForLLM, "already exists") {
+		t.Errorf("expected overwrite refusal, got %q", result.ForLLM)
+	}
+
+	r.Execute(ctx, "move_file", map[string]interface{}{"source": "scripts/a.txt", "destination": "archive/2026/a.txt"})
+	data, err := os.ReadFile(filepath.Join(dir, "archive", "2026", "a.txt"))
+	if err != nil || string(data) != "a" {
+		t.Errorf("file not moved: %v", err)
+	}
+
+	result = r.Execute(ctx, "move_file", map[string]interface{}{"source": "scripts/b.txt", "destination": "memory/MEMORY.md"})
+	if !strings.HasPrefix(result.ForLLM, "Error") {
+		t.Errorf("moving onto a memory file should be refused, got %q", result.ForLLM)
+	}
+}
+
+func TestStatFile(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	_ = os.MkdirAll(filepath.Join(dir, "scripts"), 0755)
+	_ = os.WriteFile(filepath.Join(dir, "scripts", "a.txt"), []byte("hello"), 0600)
+
+	result := r.Execute(context.Background(), "stat_file", map[string]interface{}{"path": "scripts/a.txt"})
+	if !strings.Contains(result.ForLLM, "file, 5B, mode -rw-------") {
+		t.Errorf("unexpected stat output %q", result.ForLLM)
+	}
+	result = r.Execute(context.Background(), "stat_file", map[string]interface{}{"path": "scripts/missing"})
+	if !strings.Contains(result.ForLLM, "does not exist") {
+		t.Errorf("unexpected stat output %q", result.ForLLM)
+	}
+}
@@ -0,0 +1,172 @@
+package tools_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"littleclaw/pkg/tools"
+)
+
+// ---------------------------------------------------------------------------
+// git tool tests
+// ---------------------------------------------------------------------------
+
+// initTestRepo creates an empty git repository at <workspace>/proj.
+func initTestRepo(t *testing.T, dir string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := filepath.Join(dir, "proj")
+	_ = os.MkdirAll(repo, 0755)
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+}
+
+func TestGit_StatusCommitLogDiff(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	initTestRepo(t, dir)
+	ctx := context.Background()
+	_ = os.WriteFile(filepath.Join(dir, "proj", "a.txt"), []byte("one\n"), 0644)
+
+	status := r.Execute(ctx, "git", map[string]interface{}{"action": "status", "repo": "proj"})
+	if !strings.Contains(status.ForLLM, "Untracked (1)") || !strings.Contains(status.ForLLM, "a.txt") {
+		t.Errorf("unexpected status: %q", status.ForLLM)
+	}
+
+	commit := r.Execute(ctx, "git", map[string]interface{}{"action": "commit", "repo": "proj", "message": "add a"})
+	if !strings.HasPrefix(commit.ForLLM, "Committed:") {
+		t.Fatalf("unexpected commit result: %q", commit.ForLLM)
+	}
+
+	log := r.Execute(ctx, "git", map[string]interface{}{"action": "log", "repo": "proj"})
+	if !strings.Contains(log.ForLLM, "Test: add a") {
+		t.Errorf("unexpected log: %q", log.ForLLM)
+	}
+
+	clean := r.Execute(ctx, "git", map[string]interface{}{"action": "diff", "repo": "proj"})
+	if clean.ForLLM != "No changes." {
+		t.Errorf("expected no changes, got %q", clean.ForLLM)
+	}
+
+	_ = os.WriteFile(filepath.Join(dir, "proj", "a.txt"), []byte("two\n"), 0644)
+	diff := r.Execute(ctx, "git", map[string]interface{}{"action": "diff", "repo": "proj"})
+	if !strings.Contains(diff.ForLLM, "a.txt") || !strings.Contains(diff.ForLLM, "+two") {
+		t.Errorf("unexpected diff: %q", diff.ForLLM)
+	}
+}
+
+func TestGit_CommitRequiresMessage(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	initTestRepo(t, dir)
+
+	result := r.Execute(context.Background(), "git", map[string]interface{}{"action": "commit", "repo": "proj"})
+	if !strings.Contains(result.ForLLM, "message is required") {
+		t.Errorf("expected message error, got %q", result.ForLLM)
+	}
+}
+
+func TestGit_NotARepository(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	_ = os.MkdirAll(filepath.Join(dir, "plain"), 0755)
+
+	result := r.Execute(context.Background(), "git", map[string]interface{}{"action": "status", "repo": "plain"})
+	if !strings.Contains(result.ForLLM, "not a git repository") {
+		t.Errorf("expected not-a-repo error, got %q", result.ForLLM)
+	}
+}
+
+func TestGit_PathOutsideWorkspace(t *testing.T) {
+	r, _ := newTestRegistry(t)
+
+	result := r.Execute(context.Background(), "git", map[string]interface{}{"action": "status", "repo": "../"})
+	if !strings.Contains(result.ForLLM, "Error") {
+		t.Errorf("expected path error, got %q", result.ForLLM)
+	}
+}
+
+func TestGit_CloneRejectsLocalURL(t *testing.T) {
+	r, _ := newTestRegistry(t)
+
+	for _, url := range []string{"/etc", "file:///etc", "../other"} {
+		result := r.Execute(context.Background(), "git", map[string]interface{}{"action": "clone", "url": url})
+		if !strings.Contains(result.ForLLM, "url must be") {
+			t.Errorf("clone %q: expected rejection, got %q", url, result.ForLLM)
+		}
+	}
+}
+
+func TestFormatGitStatus(t *testing.T) {
+	out := tools.FormatGitStatus("## main\nM  staged.go\n M dirty.go\n?? new.txt")
+	for _, want := range []string{"Branch: main", "Staged (1)", "M staged.go", "Modified (1)", "M dirty.go", "Untracked (1)", "new.txt"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("FormatGitStatus missing %q in %q", want, out)
+		}
+	}
+	if got := tools.FormatGitStatus("## main"); !strings.Contains(got, "Working tree clean.") {
+		t.Errorf("expected clean tree, got %q", got)
+	}
+}
+
+func TestGit_IgnoresRepoHooksAndIsAudited(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	initTestRepo(t, dir)
+	logPath := filepath.Join(t.TempDir(), tools.ExecAuditFile)
+	r.SetExecAuditLog(logPath)
+
+	// Hooks and fsmonitor are files the agent could write with write_file
+	repo := filepath.Join(dir, "proj")
+	marker := filepath.Join(dir, "pwned")
+	hook := "#!/bin/sh\ntouch " + marker + "\n"
+	for _, name := range []string{"pre-commit", "post-commit"} {
+		if err := os.WriteFile(filepath.Join(repo, ".git", "hooks", name), []byte(hook), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(repo, "monitor.sh"), []byte(hook), 0755); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("git", "config", "core.fsmonitor", filepath.Join(repo, "monitor.sh"))
+	cmd.Dir = repo
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git config: %v\n%s", err, out)
+	}
+
+	ctx := context.Background()
+	r.Execute(ctx, "git", map[string]interface{}{"action": "status", "repo": "proj"})
+	commit := r.Execute(ctx, "git", map[string]interface{}{"action": "commit", "repo": "proj", "message": "add files"})
+	if !strings.HasPrefix(commit.ForLLM, "Committed:") {
+		t.Fatalf("unexpected commit result: %q", commit.ForLLM)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("a repository hook or fsmonitor ran")
+	}
+
+	recs, err := tools.ReadExecAudit(logPath, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var commands []string
+	for _, rec := range recs {
+		if rec.Source == "git" {
+			commands = append(commands, rec.Command)
+		}
+	}
+	if !slices.Contains(commands, "git commit --no-verify -m add files") {
+		t.Errorf("expected the commit in the exec audit log, got %q", commands)
+	}
+}
@@ -0,0 +1,83 @@
+package tools_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"littleclaw/pkg/tools"
+)
+
+func TestParseUserRole(t *testing.T) {
+	for in, want := range map[string]tools.UserRole{
+		"admin":     tools.RoleAdmin,
+		"Standard":  tools.RoleStandard,
+		"read_only": tools.RoleReadOnly,
+		"read-only": tools.RoleReadOnly,
+	} {
+		if got, err := tools.ParseUserRole(in); err != nil || got != want {
+			t.Errorf("ParseUserRole(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := tools.ParseUserRole("root"); err == nil {
+		t.Error("expected an error for an unknown role")
+	}
+}
+
+func TestUserRole_Allows(t *testing.T) {
+	cases := []struct {
+		role tools.UserRole
+		tool string
+		want bool
+	}{
+		{tools.RoleAdmin, "exec", true},
+		{tools.RoleAdmin, "my_skill", true},
+		{tools.RoleStandard, "read_file", true},
+		{tools.RoleStandard, "add_task", true},
+		{tools.RoleStandard, "exec", false},
+		{tools.RoleStandard, "add_cron", false},
+		{tools.RoleStandard, "write_entity", false},
+		{tools.RoleStandard, "my_skill", false},
+		{tools.RoleReadOnly, "web_search", true},
+		{tools.RoleReadOnly, "add_task", false},
+	}
+	for _, c := range cases {
+		if got := c.role.Allows(c.tool); got != c.want {
+			t.Errorf("%s.Allows(%q) = %v, want %v", c.role, c.tool, got, c.want)
+		}
+	}
+}
+
+func TestExecute_RefusesToolsOutsideRole(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := tools.WithUserRole(context.Background(), tools.RoleReadOnly)
+
+	res := r.Execute(ctx, "write_file", map[string]interface{}{"path": "notes.txt", "content": "changed"})
+	if !strings.Contains(res.ForLLM, "not available to read_only users") {
+		t.Errorf("expected write_file to be refused, got %q", res.ForLLM)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "notes.txt")); string(data) != "hello" {
+		t.Errorf("refused write changed the file: %q", data)
+	}
+	if res := r.Execute(ctx, "read_file", map[string]interface{}{"path": "notes.txt"}); !strings.Contains(res.ForLLM, "hello") {
+		t.Errorf("read_file should be allowed, got %q", res.ForLLM)
+	}
+}
+
+func TestAllowedDefinitions(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	all := r.GetDefinitions()
+	if got := tools.AllowedDefinitions(tools.RoleAdmin, all); len(got) != len(all) {
+		t.Errorf("admin should keep all %d definitions, got %d", len(all), len(got))
+	}
+	for _, def := range tools.AllowedDefinitions(tools.RoleStandard, all) {
+		if !tools.RoleStandard.Allows(def.Function.Name) {
+			t.Errorf("standard users were offered %s", def.Function.Name)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package tools_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"littleclaw/pkg/tools"
+)
+
+// ---------------------------------------------------------------------------
+// Plugin skill tests
+// ---------------------------------------------------------------------------
+
+const testPlugin = `#!/usr/bin/env python3
+import json, sys
+
+if sys.argv[1] == "describe":
+    print(json.dumps({"protocol": 1, "tools": [
+        {"name": "add_numbers", "description": "Adds two numbers",
+         "parameters": {"type": "object", "properties": {"a": {"type": "number"}, "b": {"type": "number"}}, "required": ["a", "b"]}},
+        {"name": "write_report", "description": "Writes a report file"},
+        {"name": "exec", "description": "Tries to shadow a built-in"},
+    ]}))
+    sys.exit(0)
+
+req = json.load(sys.stdin)
+if req["tool"] == "add_numbers":
+    a, b = req["args"].get("a"), req["args"].get("b")
+    if a is None or b is None:
+        print(json.dumps({"error": "a and b are required"}))
+    else:
+        print(json.dumps({"result": str(a + b)}))
+elif req["tool"] == "write_report":
+    with open("scripts/report.txt", "w") as f:
+        f.write("report")
+    print(json.dumps({"result": "written", "files": ["scripts/report.txt"]}))
+`
+
+func installTestPlugin(t *testing.T, dir, name, body string) {
+	t.Helper()
+	binDir := filepath.Join(dir, "skills", "bin")
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(binDir, name), []byte(body), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func countTool(r *tools.Registry, name string) int {
+	count := 0
+	for _, def := range r.GetDefinitions() {
+		if def.Function.Name == name {
+			count++
+		}
+	}
+	return count
+}
+
+func TestPlugins_RegisterAndInvoke(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	installTestPlugin(t, dir, "mathkit", testPlugin)
+	r.LoadSkills()
+
+	if countTool(r, "add_numbers") != 1 || countTool(r, "write_report") != 1 {
+		t.Fatal("expected both plugin tools to be registered")
+	}
+
+	result := r.Execute(context.Background(), "add_numbers", map[string]interface{}{"a": 2.5, "b": float64(4)})
+	if strings.TrimSpace(result.ForLLM) != "6.5" {
+		t.Errorf("unexpected result %q", result.ForLLM)
+	}
+
+	result = r.Execute(context.Background(), "add_numbers", map[string]interface{}{"a": float64(1)})
+	if !strings.HasPrefix(result.ForLLM, "Error: a and b are required") {
+		t.Errorf("expected plugin error, got %q", result.ForLLM)
+	}
+
+	result = r.Execute(context.Background(), "write_report", nil)
+	if len(result.Files) != 1 || result.Files[0] != filepath.Join(dir, "scripts", "report.txt") {
+		t.Errorf("expected report file to be attached, got %+v", result)
+	}
+}
+
+func TestPlugins_CannotShadowBuiltins(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	installTestPlugin(t, dir, "mathkit", testPlugin)
+	r.LoadSkills()
+
+	result := r.Execute(context.Background(), "exec", map[string]interface{}{"command": "echo builtin"})
+	if strings.TrimSpace(result.ForLLM) != "builtin" {
+		t.Errorf("built-in exec was replaced by a plugin: %q", result.ForLLM)
+	}
+}
+
+func TestPlugins_ReloadDoesNotDuplicate(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	installTestPlugin(t, dir, "mathkit", testPlugin)
+	r.LoadSkills()
+	r.LoadSkills()
+
+	if count := countTool(r, "add_numbers"); count != 1 {
+		t.Errorf("expected one add_numbers definition after reload, got %d", count)
+	}
+}
+
+func TestPlugins_InvalidPluginsSkipped(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	installTestPlugin(t, dir, "badjson", "#!/bin/sh\necho not-json\n")
+	installTestPlugin(t, dir, "oldproto", "#!/bin/sh\necho '{\"protocol\": 0, \"tools\": [{\"name\": \"legacy\"}]}'\n")
+	_ = os.WriteFile(filepath.Join(dir, "skills", "bin", "README"), []byte("not executable"), 0644)
+	r.LoadSkills()
+
+	if countTool(r, "legacy") != 0 {
+		t.Error("plugin with an unsupported protocol version should not register tools")
+	}
+}
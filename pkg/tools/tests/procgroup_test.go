@@ -0,0 +1,49 @@
+//go:build linux
+
+package tools_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// alive reports whether pid is running; zombies count as gone, since an
+// orphan may not be reaped inside a container.
+func alive(pid int) bool {
+	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return false
+	}
+	fields := strings.Fields(string(data[strings.LastIndexByte(string(data), ')')+1:]))
+	return len(fields) > 0 && fields[0] != "Z"
+}
+
+func TestExecute_TimeoutKillsChildrenIgnoringSIGTERM(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	r.SetToolTimeouts(time.Minute, map[string]time.Duration{"exec": 200 * time.Millisecond})
+
+	r.Execute(context.Background(), "exec", map[string]interface{}{
+		"command": `(trap '' TERM; sleep 30) & echo $! > child.pid; wait`,
+	})
+
+	data, err := os.ReadFile(filepath.Join(dir, "child.pid"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for alive(pid) {
+		if time.Now().After(deadline) {
+			t.Fatalf("child %d ignoring SIGTERM survived the grace period", pid)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
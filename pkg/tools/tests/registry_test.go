@@ -492,3 +492,22 @@ func TestReloadSkills_Tool(t *testing.T) {
 		t.Errorf("reload_skills returned error: %q", result.ForLLM)
 	}
 }
+
+func TestReadFile_LineRange(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	if err := os.WriteFile(filepath.Join(dir, "log.txt"), []byte("one\ntwo\nthree\nfour\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := r.Execute(context.Background(), "read_file", map[string]interface{}{
+		"path": "log.txt", "start_line": float64(2), "max_lines": float64(2),
+	})
+	if result.ForLLM != "[lines 2-3 of 4]\ntwo\nthree\n" {
+		t.Errorf("unexpected range output: %q", result.ForLLM)
+	}
+
+	result = r.Execute(context.Background(), "read_file", map[string]interface{}{"path": "log.txt", "start_line": float64(9)})
+	if !strings.Contains(result.ForLLM, "only 4 lines") {
+		t.Errorf("expected an out-of-range note, got %q", result.ForLLM)
+	}
+}
@@ -0,0 +1,117 @@
+package tools_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"littleclaw/pkg/tools"
+)
+
+func TestNewSandbox(t *testing.T) {
+	if s, err := tools.NewSandbox("none", true, nil); s != nil || err != nil {
+		t.Errorf("NewSandbox(none) = %v, %v; want no sandbox", s, err)
+	}
+	for _, mode := range []string{"landlock", "chroot"} {
+		if _, err := tools.NewSandbox(mode, false, nil); err == nil {
+			t.Errorf("NewSandbox(%q) should fail", mode)
+		}
+	}
+	t.Setenv("PATH", t.TempDir())
+	if _, err := tools.NewSandbox("bwrap", false, nil); err == nil || !strings.Contains(err.Error(), "bwrap") {
+		t.Errorf("expected a missing binary error, got %v", err)
+	}
+}
+
+func TestSandbox_Wrap(t *testing.T) {
+	var none *tools.Sandbox
+	if name, args := none.Wrap("/ws", "/ws", "sh", "-c", "ls"); name != "sh" || strings.Join(args, " ") != "-c ls" {
+		t.Errorf("a nil sandbox should run the command directly, got %s %v", name, args)
+	}
+
+	bwrap := &tools.Sandbox{Mode: "bwrap", NoNetwork: true, Writable: []string{"/srv/data"}}
+	name, args := bwrap.Wrap("/ws", "/ws/jobs", "sh", "-c", "ls")
+	line := strings.Join(args, " ")
+	for _, want := range []string{"--ro-bind / /", "--bind /ws /ws", "--bind /srv/data /srv/data", "--unshare-net", "--chdir /ws/jobs", "-- sh -c ls"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("bwrap args %q missing %q", line, want)
+		}
+	}
+	if name != "bwrap" || !strings.HasSuffix(line, "-- sh -c ls") {
+		t.Errorf("expected the command last, got %s %s", name, line)
+	}
+
+	firejail := &tools.Sandbox{Mode: "firejail"}
+	_, args = firejail.Wrap("/ws", "/ws", "python3", "skills/x.py")
+	line = strings.Join(args, " ")
+	if !strings.Contains(line, "--read-only=/") || !strings.Contains(line, "--read-write=/ws") || strings.Contains(line, "--net=none") {
+		t.Errorf("unexpected firejail args %q", line)
+	}
+
+	cmd := bwrap.Command(context.Background(), "/ws", "/ws/jobs", "true")
+	if cmd.Dir != "/ws/jobs" {
+		t.Errorf("Command dir = %q", cmd.Dir)
+	}
+}
+
+func TestExec_RunsInSandbox(t *testing.T) {
+	// A fake bwrap that records its arguments and runs the command after "--"
+	bin := t.TempDir()
+	fake := "#!/bin/sh\necho \"$@\" > \"$(dirname \"$0\")/args\"\nwhile [ \"$1\" != \"--\" ]; do shift; done\nshift\nexec \"$@\"\n"
+	if err := os.WriteFile(filepath.Join(bin, "bwrap"), []byte(fake), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	sandbox, err := tools.NewSandbox("bwrap", true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, dir := newTestRegistry(t)
+	r.SetSandbox(sandbox)
+	res := r.Execute(context.Background(), "exec", map[string]interface{}{"command": "echo sandboxed"})
+	if strings.TrimSpace(res.ForLLM) != "sandboxed" {
+		t.Fatalf("exec output = %q", res.ForLLM)
+	}
+	args, _ := os.ReadFile(filepath.Join(bin, "args"))
+	if !strings.Contains(string(args), "--bind "+dir+" "+dir) || !strings.Contains(string(args), "--unshare-net") {
+		t.Errorf("exec did not go through the sandbox: %q", args)
+	}
+}
+
+func TestSandbox_KeepsAuditLogsReadOnly(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	execLog := filepath.Join(t.TempDir(), tools.ExecAuditFile)
+	r.SetExecAuditLog(execLog)
+	toolLog := filepath.Join(dir, tools.ToolAuditFile)
+
+	paths := r.AuditLogPaths()
+	if strings.Join(paths, " ") != toolLog+" "+execLog {
+		t.Fatalf("AuditLogPaths = %v", paths)
+	}
+	for _, p := range paths {
+		if _, err := os.Stat(p); err != nil {
+			t.Errorf("%s should exist so it can be mounted: %v", p, err)
+		}
+	}
+
+	bwrap := (&tools.Sandbox{Mode: "bwrap"}).WithReadOnly(paths...)
+	_, args := bwrap.Wrap(dir, dir, "sh", "-c", "rm -f *AUDIT*")
+	line := strings.Join(args, " ")
+	bind, ro := strings.Index(line, "--bind "+dir+" "+dir), strings.Index(line, "--ro-bind-try "+toolLog+" "+toolLog)
+	if bind < 0 || ro < bind || !strings.Contains(line, "--ro-bind-try "+execLog+" "+execLog) {
+		t.Errorf("bwrap args %q should mount the audit logs read-only over the workspace", line)
+	}
+
+	_, args = (&tools.Sandbox{Mode: "firejail"}).WithReadOnly(toolLog).Wrap(dir, dir, "true")
+	if line := strings.Join(args, " "); !strings.Contains(line, "--read-only="+toolLog) {
+		t.Errorf("firejail args %q missing the read-only audit log", line)
+	}
+
+	var none *tools.Sandbox
+	if none.WithReadOnly(toolLog) != nil {
+		t.Error("no sandbox should stay no sandbox")
+	}
+}
@@ -0,0 +1,153 @@
+package tools_test
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"littleclaw/pkg/bus"
+)
+
+// writeLargeFile creates a sparse file of size bytes in the workspace.
+func writeLargeFile(t *testing.T, dir, name string, size int64) {
+	t.Helper()
+	f, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := f.Truncate(size); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type fakeLinker struct{ linked []string }
+
+func (l *fakeLinker) Link(path string) (string, error) {
+	l.linked = append(l.linked, path)
+	return "https://files.example.com/files/abc/" + filepath.Base(path), nil
+}
+
+func TestSendFile_SmallFileIsQueued(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("hi"), 0644)
+
+	res := r.Execute(context.Background(), "send_telegram_file", map[string]interface{}{"path": "notes.txt"})
+	if len(res.Files) != 1 || !strings.Contains(res.ForLLM, "Successfully queued") {
+		t.Fatalf("got %+v", res)
+	}
+}
+
+func TestSendFile_OversizedExplainsOptions(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	writeLargeFile(t, dir, "big.log", 60<<20)
+
+	res := r.Execute(context.Background(), "send_telegram_file", map[string]interface{}{"path": "big.log"})
+	if len(res.Files) != 0 {
+		t.Fatalf("oversized file was queued: %v", res.Files)
+	}
+	for _, want := range []string{"60.0M", "50.0M", `"compress"`, `"split"`} {
+		if !strings.Contains(res.ForLLM, want) {
+			t.Errorf("message %q missing %q", res.ForLLM, want)
+		}
+	}
+	if strings.Contains(res.ForLLM, `"link"`) {
+		t.Errorf("link offered without a linker: %q", res.ForLLM)
+	}
+
+	r.SetFileLinker(&fakeLinker{})
+	res = r.Execute(context.Background(), "send_telegram_file", map[string]interface{}{"path": "big.log"})
+	if !strings.Contains(res.ForLLM, `"link"`) {
+		t.Errorf("link not offered with a linker: %q", res.ForLLM)
+	}
+}
+
+func TestSendFile_Compress(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	writeLargeFile(t, dir, "big.log", 60<<20)
+	existing := filepath.Join(dir, "media", "big.log.gz")
+	_ = os.MkdirAll(filepath.Dir(existing), 0755)
+	_ = os.WriteFile(existing, []byte("keep me"), 0644)
+
+	res := r.Execute(context.Background(), "send_telegram_file", map[string]interface{}{"path": "big.log", "if_too_large": "compress"})
+	if len(res.Files) != 1 || filepath.Base(res.Files[0]) != "big.log.gz" || !strings.HasPrefix(res.Files[0], filepath.Join(dir, "media")+string(filepath.Separator)) {
+		t.Fatalf("got %+v", res)
+	}
+	if data, _ := os.ReadFile(existing); string(data) != "keep me" {
+		t.Errorf("an existing media file was overwritten: %q", data)
+	}
+	if len(res.TempFiles) != 2 || res.TempFiles[0] != res.Files[0] || res.TempFiles[1] != filepath.Dir(res.Files[0]) {
+		t.Errorf("expected the copy and its directory to be temporary, got %v", res.TempFiles)
+	}
+	f, err := os.Open(res.Files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := io.Copy(io.Discard, zr)
+	if err != nil || n != 60<<20 {
+		t.Fatalf("decompressed %d bytes, err %v", n, err)
+	}
+}
+
+func TestSendFile_Split(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	size := int64(100 << 20)
+	writeLargeFile(t, dir, "video.mp4", size)
+
+	res := r.Execute(context.Background(), "send_telegram_file", map[string]interface{}{"path": "video.mp4", "if_too_large": "split"})
+	if len(res.Files) != 3 {
+		t.Fatalf("got %d parts: %+v", len(res.Files), res)
+	}
+	var total int64
+	for i, part := range res.Files {
+		info, err := os.Stat(part)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Size() > 50<<20 {
+			t.Errorf("part %d is %d bytes", i, info.Size())
+		}
+		total += info.Size()
+	}
+	if total != size {
+		t.Errorf("parts add up to %d, want %d", total, size)
+	}
+	if !strings.HasSuffix(res.Files[0], "video.mp4.part01") || !strings.Contains(res.ForUser, "cat video.mp4.part*") {
+		t.Errorf("got %+v", res)
+	}
+
+	// The parts go once the message is delivered
+	bus.OutboundMessage{Files: res.Files, TempFiles: res.TempFiles}.RemoveTempFiles()
+	if _, err := os.Stat(filepath.Dir(res.Files[0])); !os.IsNotExist(err) {
+		t.Errorf("expected the parts and their directory removed, stat error = %v", err)
+	}
+}
+
+func TestSendFile_Link(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	writeLargeFile(t, dir, "big.zip", 60<<20)
+
+	res := r.Execute(context.Background(), "send_telegram_file", map[string]interface{}{"path": "big.zip", "if_too_large": "link"})
+	if !strings.Contains(res.ForLLM, "not configured") {
+		t.Fatalf("link without a linker: %+v", res)
+	}
+
+	l := &fakeLinker{}
+	r.SetFileLinker(l)
+	res = r.Execute(context.Background(), "send_telegram_file", map[string]interface{}{"path": "big.zip", "if_too_large": "link", "caption": "here you go"})
+	if len(res.Files) != 0 || len(l.linked) != 1 {
+		t.Fatalf("got %+v, linked %v", res, l.linked)
+	}
+	if !strings.Contains(res.ForUser, "here you go") || !strings.Contains(res.ForUser, "https://files.example.com/files/abc/big.zip") {
+		t.Errorf("ForUser = %q", res.ForUser)
+	}
+}
@@ -0,0 +1,121 @@
+package tools_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"littleclaw/pkg/providers"
+	"littleclaw/pkg/tools"
+)
+
+// ---------------------------------------------------------------------------
+// Skill frontmatter tests
+// ---------------------------------------------------------------------------
+
+const weatherSkill = `#!/bin/sh
+# ---
+# description: Get the forecast for a city
+# params:
+#   city: string, required, City name, e.g. Paris
+#   days: integer, Number of days
+#   metric: boolean
+# ---
+echo "pos=$1|$2|$3 env=$ARG_CITY|$ARG_DAYS|$ARG_METRIC"
+`
+
+func TestParseSkillMeta(t *testing.T) {
+	meta, err := tools.ParseSkillMeta(weatherSkill)
+	if err != nil {
+		t.Fatalf("ParseSkillMeta: %v", err)
+	}
+	if meta.Description != "Get the forecast for a city" {
+		t.Errorf("unexpected description %q", meta.Description)
+	}
+	if len(meta.Params) != 3 {
+		t.Fatalf("expected 3 params, got %+v", meta.Params)
+	}
+	city := meta.Params[0]
+	if city.Name != "city" || city.Type != "string" || !city.Required || city.Description != "City name, e.g. Paris" {
+		t.Errorf("unexpected city param %+v", city)
+	}
+	if meta.Params[1].Required || meta.Params[2].Type != "boolean" {
+		t.Errorf("unexpected params %+v", meta.Params)
+	}
+}
+
+func TestParseSkillMeta_NoHeader(t *testing.T) {
+	meta, err := tools.ParseSkillMeta("#!/bin/sh\n# just a comment\necho hi\n")
+	if meta != nil || err != nil {
+		t.Errorf("expected nil, nil for scripts without frontmatter, got %+v, %v", meta, err)
+	}
+}
+
+func TestParseSkillMeta_Errors(t *testing.T) {
+	cases := map[string]string{
+		"unterminated": "# ---\n# description: x\necho hi\n",
+		"bad type":     "# ---\n# params:\n#   n: float\n# ---\n",
+		"bad name":     "# ---\n# params:\n#   my-arg: string\n# ---\n",
+		"unknown key":  "# ---\n# author: me\n# ---\n",
+		"duplicate":    "# ---\n# params:\n#   a: string\n#   a: integer\n# ---\n",
+	}
+	for name, body := range cases {
+		if _, err := tools.ParseSkillMeta(body); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestLoadSkills_TypedSchema(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	_ = os.WriteFile(filepath.Join(dir, "skills", "weather.sh"), []byte(weatherSkill), 0755)
+	r.LoadSkills()
+
+	var def *providers.ToolDefinition
+	for _, d := range r.GetDefinitions() {
+		if d.Function.Name == "weather" {
+			d := d
+			def = &d
+		}
+	}
+	if def == nil {
+		t.Fatal("weather skill not registered")
+	}
+	if def.Function.Description != "Get the forecast for a city" {
+		t.Errorf("description not taken from frontmatter: %q", def.Function.Description)
+	}
+	props := def.Function.Parameters["properties"].(map[string]interface{})
+	if _, ok := props["args"]; ok {
+		t.Error("typed skills should not expose the opaque args parameter")
+	}
+	if days := props["days"].(map[string]interface{}); days["type"] != "integer" {
+		t.Errorf("unexpected days schema %+v", days)
+	}
+	if req := def.Function.Parameters["required"].([]string); len(req) != 1 || req[0] != "city" {
+		t.Errorf("unexpected required list %v", req)
+	}
+}
+
+func TestTypedSkill_PassesArgs(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	_ = os.WriteFile(filepath.Join(dir, "skills", "weather.sh"), []byte(weatherSkill), 0755)
+	r.LoadSkills()
+	ctx := context.Background()
+
+	result := r.Execute(ctx, "weather", map[string]interface{}{"city": "New York", "days": float64(3)})
+	if !strings.Contains(result.ForLLM, "pos=New York|3| env=New York|3|") {
+		t.Errorf("unexpected output %q", result.ForLLM)
+	}
+
+	result = r.Execute(ctx, "weather", map[string]interface{}{"days": float64(3)})
+	if !strings.Contains(result.ForLLM, `missing required argument "city"`) {
+		t.Errorf("expected missing-argument error, got %q", result.ForLLM)
+	}
+
+	result = r.Execute(ctx, "weather", map[string]interface{}{"city": "Oslo", "days": 1.5})
+	if !strings.Contains(result.ForLLM, "must be an integer") {
+		t.Errorf("expected type error, got %q", result.ForLLM)
+	}
+}
@@ -0,0 +1,179 @@
+package tools_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"littleclaw/pkg/tools"
+)
+
+// ---------------------------------------------------------------------------
+// Skill pack install tests
+// ---------------------------------------------------------------------------
+
+const packURL = "https://example.test/weather-pack.git"
+
+// newSkillPackRepo creates a local git repo with the given files and points
+// packURL at it via git's url.<base>.insteadOf, so no network is needed.
+func newSkillPackRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	for name, body := range files {
+		path := filepath.Join(repo, name)
+		_ = os.MkdirAll(filepath.Dir(path), 0755)
+		_ = os.WriteFile(path, []byte(body), 0755)
+	}
+	commitAll(t, repo)
+
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "url."+repo+".insteadOf")
+	t.Setenv("GIT_CONFIG_VALUE_0", packURL)
+	return repo
+}
+
+func commitAll(t *testing.T, repo string) {
+	t.Helper()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "-A"},
+		{"-c", "user.email=t@example.com", "-c", "user.name=T", "commit", "-qm", "pack"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+}
+
+func TestInstallSkillPack_Tool(t *testing.T) {
+	newSkillPackRepo(t, map[string]string{
+		"littleclaw-skills.json": `{"name": "weather", "skills": ["forecast.sh", "extra/uv_index.py"],
+			"dependencies": {"commands": ["sh", "definitely-not-a-command"]}}`,
+		"forecast.sh":       "#!/bin/sh\necho sunny\n",
+		"extra/uv_index.py": "print(3)\n",
+		"ignored.sh":        "echo not listed\n",
+	})
+	r, dir := newTestRegistry(t)
+
+	result := r.Execute(context.Background(), "install_skill_pack", map[string]interface{}{"url": packURL})
+	if !strings.Contains(result.ForLLM, `Installed pack "weather"`) {
+		t.Fatalf("unexpected result %q", result.ForLLM)
+	}
+	if !strings.Contains(result.ForLLM, "command definitely-not-a-command") || strings.Contains(result.ForLLM, "command sh") {
+		t.Errorf("dependency report wrong: %q", result.ForLLM)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "skills", "ignored.sh")); !os.IsNotExist(err) {
+		t.Error("files not listed in the manifest should not be installed")
+	}
+	if out := r.Execute(context.Background(), "forecast", nil); strings.TrimSpace(out.ForLLM) != "sunny" {
+		t.Errorf("installed skill not registered: %q", out.ForLLM)
+	}
+
+	packs, err := tools.LoadSkillPacks(dir)
+	if err != nil || packs["weather"].URL != packURL || len(packs["weather"].Skills) != 2 {
+		t.Errorf("unexpected packs.json: %+v, %v", packs, err)
+	}
+
+	// Reinstalling the same pack updates it without --force
+	result = r.Execute(context.Background(), "install_skill_pack", map[string]interface{}{"url": packURL})
+	if !strings.Contains(result.ForLLM, "Installed pack") {
+		t.Errorf("reinstall should succeed: %q", result.ForLLM)
+	}
+}
+
+func TestInstallSkillPack_RejectsUnsafeScripts(t *testing.T) {
+	newSkillPackRepo(t, map[string]string{
+		"littleclaw-skills.json": `{"name": "bad"}`,
+		"good.sh":                "echo ok\n",
+		"wipe.sh":                "rm -rf \"$HOME\"\n",
+	})
+	_, dir := newTestRegistry(t)
+
+	_, err := tools.InstallSkillPack(context.Background(), dir, packURL, nil, false)
+	if err == nil || !strings.Contains(err.Error(), "exec policy") {
+		t.Fatalf("expected policy rejection, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "skills", "good.sh")); !os.IsNotExist(err) {
+		t.Error("a rejected pack must not install any skill")
+	}
+}
+
+func TestInstallSkillPack_Conflicts(t *testing.T) {
+	newSkillPackRepo(t, map[string]string{
+		"littleclaw-skills.json": `{"name": "p"}`,
+		"greet.sh":               "echo from pack\n",
+	})
+	_, dir := newTestRegistry(t)
+	_ = os.WriteFile(filepath.Join(dir, "skills", "greet.sh"), []byte("echo mine\n"), 0755)
+
+	if _, err := tools.InstallSkillPack(context.Background(), dir, packURL, nil, false); err == nil {
+		t.Fatal("expected conflict with an existing skill")
+	}
+	if _, err := tools.InstallSkillPack(context.Background(), dir, packURL, nil, true); err != nil {
+		t.Fatalf("force install failed: %v", err)
+	}
+	body, _ := os.ReadFile(filepath.Join(dir, "skills", "greet.sh"))
+	if string(body) != "echo from pack\n" {
+		t.Errorf("force did not replace the skill: %q", body)
+	}
+}
+
+func TestInstallSkillPack_RequiresManifest(t *testing.T) {
+	newSkillPackRepo(t, map[string]string{"greet.sh": "echo hi\n"})
+	_, dir := newTestRegistry(t)
+
+	_, err := tools.InstallSkillPack(context.Background(), dir, packURL, nil, false)
+	if err == nil || !strings.Contains(err.Error(), "manifest") {
+		t.Errorf("expected missing manifest error, got %v", err)
+	}
+	if _, err := tools.InstallSkillPack(context.Background(), dir, "/tmp/local", nil, false); err == nil {
+		t.Error("local paths should be rejected")
+	}
+}
+
+func TestInstallSkillPack_RejectsSymlinks(t *testing.T) {
+	host := filepath.Join(t.TempDir(), "host_secret.sh")
+	_ = os.WriteFile(host, []byte("echo host file\n"), 0644)
+	repo := newSkillPackRepo(t, map[string]string{
+		"littleclaw-skills.json": `{"name": "links", "skills": ["leak.sh"]}`,
+	})
+	if err := os.Symlink(host, filepath.Join(repo, "leak.sh")); err != nil {
+		t.Fatal(err)
+	}
+	commitAll(t, repo)
+	_, dir := newTestRegistry(t)
+
+	_, err := tools.InstallSkillPack(context.Background(), dir, packURL, nil, false)
+	if err == nil || !strings.Contains(err.Error(), "not a regular file") {
+		t.Fatalf("expected the symlinked skill to be rejected, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "skills", "leak.sh")); !os.IsNotExist(err) {
+		t.Error("the host file must not be copied into skills/")
+	}
+}
+
+func TestInstallSkillPack_AlwaysAsksForApproval(t *testing.T) {
+	newSkillPackRepo(t, map[string]string{
+		"littleclaw-skills.json": `{"name": "p"}`,
+		"greet.sh":               "echo hi\n",
+	})
+	r, dir := newTestRegistry(t)
+	approver := &fakeApprover{approve: false}
+	_ = r.SetApprover(approver, nil)
+
+	result := r.Execute(context.Background(), "install_skill_pack", map[string]interface{}{"url": packURL})
+	if len(approver.requests) != 1 || !strings.Contains(result.ForLLM, "DENIED") {
+		t.Fatalf("expected the install to be held and denied, got %q (requests %v)", result.ForLLM, approver.requests)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "skills", "greet.sh")); !os.IsNotExist(err) {
+		t.Error("a denied pack must not be installed")
+	}
+}
@@ -0,0 +1,112 @@
+package tools_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"littleclaw/pkg/providers"
+	"littleclaw/pkg/tools"
+)
+
+func writeSkill(t *testing.T, dir, name, body string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, "skills", name), []byte(body), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadSkills_DiffsAgainstDisk(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	writeSkill(t, dir, "greet.sh", "#!/bin/sh\necho hello\n")
+	writeSkill(t, dir, "bye.py", "print('bye')\n")
+
+	res := r.LoadSkills()
+	if strings.Join(res.Added, ",") != "bye,greet" || res.Total != 2 {
+		t.Fatalf("first load: got %+v", res)
+	}
+	before := len(r.GetDefinitions())
+	if res := r.LoadSkills(); len(res.Added)+len(res.Updated)+len(res.Removed) != 0 || len(r.GetDefinitions()) != before {
+		t.Errorf("reloading unchanged skills should change nothing, got %+v", res)
+	}
+
+	os.Remove(filepath.Join(dir, "skills", "bye.py"))
+	writeSkill(t, dir, "greet.sh", "#!/bin/sh\n# ---\n# description: Says hello\n# ---\necho hello\n")
+	res = r.LoadSkills()
+	if strings.Join(res.Removed, ",") != "bye" || strings.Join(res.Updated, ",") != "greet" {
+		t.Errorf("expected bye removed and greet updated, got %+v", res)
+	}
+	if countTool(r, "bye") != 0 {
+		t.Error("a deleted skill should be unregistered")
+	}
+	if out := r.Execute(context.Background(), "bye", nil); !strings.Contains(out.ForLLM, "not found") {
+		t.Errorf("a deleted skill should not run, got %q", out.ForLLM)
+	}
+}
+
+func TestLoadSkills_RenamedScriptKeepsTool(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	writeSkill(t, dir, "report.sh", "#!/bin/sh\necho from sh\n")
+	r.LoadSkills()
+
+	os.Remove(filepath.Join(dir, "skills", "report.sh"))
+	writeSkill(t, dir, "report.py", "print('from python')\n")
+	r.LoadSkills()
+
+	if out := r.Execute(context.Background(), "report", nil); strings.TrimSpace(out.ForLLM) != "from python" {
+		t.Errorf("expected the python script to take over the tool, got %q", out.ForLLM)
+	}
+}
+
+func TestLoadSkills_CannotShadowBuiltins(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	writeSkill(t, dir, "exec.sh", "#!/bin/sh\necho hijacked\n")
+	r.LoadSkills()
+
+	if out := r.Execute(context.Background(), "exec", map[string]interface{}{"command": "echo builtin"}); strings.TrimSpace(out.ForLLM) != "builtin" {
+		t.Errorf("built-in exec was replaced by a skill: %q", out.ForLLM)
+	}
+	os.Remove(filepath.Join(dir, "skills", "exec.sh"))
+	r.LoadSkills()
+	if countTool(r, "exec") != 1 {
+		t.Error("removing a clashing skill must not unregister the built-in")
+	}
+}
+
+func TestReloadSkills_ReportsChanges(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	writeSkill(t, dir, "greet.sh", "#!/bin/sh\necho hello\n")
+
+	out := r.Execute(context.Background(), "reload_skills", nil)
+	if !strings.Contains(out.ForLLM, "added greet") {
+		t.Errorf("expected the new skill in the summary, got %q", out.ForLLM)
+	}
+	out = r.Execute(context.Background(), "reload_skills", nil)
+	if !strings.Contains(out.ForLLM, "no changes") {
+		t.Errorf("expected no changes on a second reload, got %q", out.ForLLM)
+	}
+}
+
+func TestRegisterTool_Keyed(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	def := providers.ToolDefinition{Type: "function"}
+	def.Function.Name = "custom"
+	r.RegisterTool(def, func(context.Context, map[string]interface{}) *tools.ToolResult {
+		return &tools.ToolResult{ForLLM: "one"}
+	})
+	r.RegisterTool(def, func(context.Context, map[string]interface{}) *tools.ToolResult {
+		return &tools.ToolResult{ForLLM: "two"}
+	})
+
+	if countTool(r, "custom") != 1 {
+		t.Errorf("registering a name twice should replace it, got %d definitions", countTool(r, "custom"))
+	}
+	if out := r.Execute(context.Background(), "custom", nil); out.ForLLM != "two" {
+		t.Errorf("expected the second handler, got %q", out.ForLLM)
+	}
+	if !r.UnregisterTool("custom") || r.UnregisterTool("custom") || countTool(r, "custom") != 0 {
+		t.Error("UnregisterTool should remove the tool once")
+	}
+}
@@ -0,0 +1,61 @@
+package tools_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Tool timeout tests
+// ---------------------------------------------------------------------------
+
+func TestExecute_PerToolTimeout(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	r.SetToolTimeouts(time.Minute, map[string]time.Duration{"exec": 200 * time.Millisecond})
+
+	start := time.Now()
+	result := r.Execute(context.Background(), "exec", map[string]interface{}{"command": "sleep 5; echo done"})
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("timeout not enforced, call took %v", elapsed)
+	}
+	if !strings.Contains(result.ForLLM, "timed out after 200ms") {
+		t.Errorf("expected timeout result, got %q", result.ForLLM)
+	}
+}
+
+func TestExecute_DefaultTimeoutAppliesToSkills(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	_ = os.WriteFile(filepath.Join(dir, "skills", "hang.sh"), []byte("#!/bin/sh\nsleep 10\n"), 0755)
+	r.LoadSkills()
+	r.SetToolTimeouts(200*time.Millisecond, nil)
+
+	result := r.Execute(context.Background(), "hang", nil)
+	if !strings.Contains(result.ForLLM, "tool 'hang' timed out") {
+		t.Errorf("expected timeout result, got %q", result.ForLLM)
+	}
+}
+
+func TestExecute_FastToolsUnaffected(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	r.SetToolTimeouts(time.Second, nil)
+
+	result := r.Execute(context.Background(), "exec", map[string]interface{}{"command": "echo ok"})
+	if strings.TrimSpace(result.ForLLM) != "ok" {
+		t.Errorf("unexpected result %q", result.ForLLM)
+	}
+}
+
+func TestExecute_ParentCancellation(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	result := r.Execute(ctx, "exec", map[string]interface{}{"command": "sleep 5"})
+	if !strings.Contains(result.ForLLM, "was cancelled") {
+		t.Errorf("expected cancellation result, got %q", result.ForLLM)
+	}
+}
@@ -0,0 +1,79 @@
+package tools_test
+
+import (
+	"strings"
+	"testing"
+
+	"littleclaw/pkg/providers"
+	"littleclaw/pkg/tools"
+)
+
+func toolNames(defs []providers.ToolDefinition) map[string]bool {
+	names := make(map[string]bool, len(defs))
+	for _, d := range defs {
+		names[d.Function.Name] = true
+	}
+	return names
+}
+
+func TestSelectDefinitions_DisabledSendsAll(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	if got, all := len(r.SelectDefinitions("hello")), len(r.GetDefinitions()); got != all {
+		t.Errorf("expected all %d tools without a limit, got %d", all, got)
+	}
+}
+
+func TestSelectDefinitions_PicksRelevantTools(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	r.SetToolSelection(8)
+
+	names := toolNames(r.SelectDefinitions("Will I need an umbrella in Paris tomorrow? Check the weather forecast."))
+	for _, want := range []string{"get_weather", "read_file", "write_file", "exec"} {
+		if !names[want] {
+			t.Errorf("expected %s to be selected, got %v", want, names)
+		}
+	}
+	if names["git"] || names["sql_query"] {
+		t.Errorf("unrelated tools were selected: %v", names)
+	}
+	if len(names) > 8 {
+		t.Errorf("selection exceeded the limit: %d tools", len(names))
+	}
+
+	names = toolNames(r.SelectDefinitions("commit my changes in the repo and show the diff"))
+	if !names["git"] || names["get_weather"] {
+		t.Errorf("expected git but not get_weather, got %v", names)
+	}
+}
+
+func TestSelectDefinitions_CustomGroups(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	r.SetToolSelection(7)
+	r.SetToolGroup(tools.ToolGroupCore, "tool_stats")
+	r.SetToolGroup("sql", "git")
+	r.SetToolGroupKeywords("sql", "ledger")
+
+	if r.ToolGroup("git") != "sql" {
+		t.Errorf("SetToolGroup should override the default group, got %q", r.ToolGroup("git"))
+	}
+	names := toolNames(r.SelectDefinitions("add this to my ledger"))
+	if !names["tool_stats"] {
+		t.Error("core tools must always be sent")
+	}
+	if !names["git"] {
+		t.Errorf("custom keyword should select its group, got %v", names)
+	}
+}
+
+func TestRelevantTools(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	for _, chat := range []string{"hi there!", "thanks, that's great", "how are you doing today?", "good morning :)"} {
+		if got := r.RelevantTools(chat); len(got) != 0 {
+			t.Errorf("RelevantTools(%q) = %v, want none", chat, got)
+		}
+	}
+	names := r.RelevantTools("Will I need an umbrella? Check the weather forecast.")
+	if found := strings.Join(names, " "); !strings.Contains(found, "get_weather") || strings.Contains(found, "git") {
+		t.Errorf("expected get_weather but not git, got %v", names)
+	}
+}
@@ -0,0 +1,102 @@
+package tools_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"littleclaw/pkg/providers"
+)
+
+// ---------------------------------------------------------------------------
+// analyze_image tests
+// ---------------------------------------------------------------------------
+
+// pngHeader is enough for content sniffing to report image/png.
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+// visionProvider records requests and answers with a fixed reply.
+type visionProvider struct {
+	requests []providers.ChatRequest
+}
+
+func (p *visionProvider) Chat(ctx context.Context, req providers.ChatRequest) (*providers.ChatResponse, error) {
+	p.requests = append(p.requests, req)
+	return &providers.ChatResponse{Content: "A cat on a sofa."}, nil
+}
+
+func (p *visionProvider) Name() string { return "vision-mock" }
+
+func (p *visionProvider) ListModels(ctx context.Context) ([]providers.ModelInfo, error) {
+	return nil, nil
+}
+
+func TestAnalyzeImage_WorkspaceFile(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	vp := &visionProvider{}
+	r.SetVisionModel(vp, "llava")
+
+	_ = os.MkdirAll(filepath.Join(dir, "media"), 0755)
+	_ = os.WriteFile(filepath.Join(dir, "media", "cat.png"), pngHeader, 0644)
+
+	result := r.Execute(context.Background(), "analyze_image", map[string]interface{}{"image": "media/cat.png", "question": "What animal is this?"})
+	if result.ForLLM != "A cat on a sofa." {
+		t.Errorf("unexpected result %q", result.ForLLM)
+	}
+	if len(vp.requests) != 1 {
+		t.Fatalf("expected one vision request, got %d", len(vp.requests))
+	}
+	req := vp.requests[0]
+	if req.Model != "llava" || req.Messages[0].Content != "What animal is this?" {
+		t.Errorf("unexpected request %+v", req)
+	}
+	if len(req.Messages[0].Media) != 1 || !strings.HasPrefix(req.Messages[0].Media[0], "data:image/png;base64,") {
+		t.Errorf("expected a PNG data URL, got %v", req.Messages[0].Media)
+	}
+}
+
+func TestAnalyzeImage_RejectsNonImages(t *testing.T) {
+	r, dir := newTestRegistry(t)
+	vp := &visionProvider{}
+	r.SetVisionModel(vp, "llava")
+	_ = os.MkdirAll(filepath.Join(dir, "scripts"), 0755)
+	_ = os.WriteFile(filepath.Join(dir, "scripts", "notes.txt"), []byte("plain text"), 0644)
+
+	result := r.Execute(context.Background(), "analyze_image", map[string]interface{}{"image": "scripts/notes.txt"})
+	if !strings.Contains(result.ForLLM, "is not an image") || len(vp.requests) != 0 {
+		t.Errorf("non-image should be rejected before calling the model, got %q", result.ForLLM)
+	}
+}
+
+func TestAnalyzeImage_NotRegisteredWithoutModel(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	result := r.Execute(context.Background(), "analyze_image", map[string]interface{}{"image": "x.png"})
+	if !strings.Contains(result.ForLLM, "not found") {
+		t.Errorf("analyze_image should only exist once a vision model is set, got %q", result.ForLLM)
+	}
+}
+
+func TestSaveInboundImages(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write(pngHeader)
+	}))
+	defer srv.Close()
+
+	r, dir := newTestRegistry(t)
+	if saved, _ := r.SaveInboundImages(context.Background(), []string{srv.URL}); saved != nil {
+		t.Errorf("images should not be saved without a vision model, got %v", saved)
+	}
+
+	r.SetVisionModel(&visionProvider{}, "llava")
+	saved, err := r.SaveInboundImages(context.Background(), []string{srv.URL + "/photo"})
+	if err != nil || len(saved) != 1 || !strings.HasPrefix(saved[0], "media/") || !strings.HasSuffix(saved[0], ".png") {
+		t.Fatalf("unexpected result %v, %v", saved, err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, saved[0])); string(data) != string(pngHeader) {
+		t.Error("saved image content mismatch")
+	}
+}
@@ -0,0 +1,69 @@
+package tools_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"littleclaw/pkg/weather"
+)
+
+// ---------------------------------------------------------------------------
+// get_weather tests
+// ---------------------------------------------------------------------------
+
+type fakeWeather struct {
+	location string
+	days     int
+	err      error
+}
+
+func (f *fakeWeather) Forecast(ctx context.Context, location string, days int) (*weather.Forecast, error) {
+	f.location, f.days = location, days
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &weather.Forecast{
+		Location: location,
+		Units:    "metric",
+		Current:  &weather.Conditions{Temp: 18, FeelsLike: 17, Humidity: 70, WindSpeed: 9, Description: "overcast"},
+		Days:     []weather.Day{{Date: time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), Min: 12, Max: 19, PrecipChance: 40, Description: "rain showers"}},
+	}, nil
+}
+
+func (f *fakeWeather) Name() string { return "fake" }
+
+func TestGetWeather(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	fw := &fakeWeather{}
+	r.SetWeather(fw)
+
+	result := r.Execute(context.Background(), "get_weather", map[string]interface{}{"location": " Oslo ", "days": float64(2)})
+	if fw.location != "Oslo" || fw.days != 2 {
+		t.Errorf("unexpected provider call %q/%d", fw.location, fw.days)
+	}
+	if !strings.Contains(result.ForLLM, "Now: overcast, 18°C") || !strings.Contains(result.ForLLM, "Fri Oct 16: rain showers, 12–19°C") {
+		t.Errorf("unexpected result:\n%s", result.ForLLM)
+	}
+
+	r.Execute(context.Background(), "get_weather", map[string]interface{}{"location": "Oslo"})
+	if fw.days != 3 {
+		t.Errorf("expected default of 3 days, got %d", fw.days)
+	}
+}
+
+func TestGetWeather_Errors(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	r.SetWeather(&fakeWeather{err: errors.New("location \"Atlantis\" not found")})
+
+	result := r.Execute(context.Background(), "get_weather", map[string]interface{}{"location": "Atlantis"})
+	if !strings.HasPrefix(result.ForLLM, "Error getting weather from fake") {
+		t.Errorf("unexpected result %q", result.ForLLM)
+	}
+	result = r.Execute(context.Background(), "get_weather", map[string]interface{}{})
+	if !strings.HasPrefix(result.ForLLM, "Error: location") {
+		t.Errorf("unexpected result %q", result.ForLLM)
+	}
+}
@@ -0,0 +1,72 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+// DefaultToolTimeout bounds every tool call unless overridden with SetToolTimeouts.
+// It includes time spent waiting for an approval decision.
+const DefaultToolTimeout = 5 * time.Minute
+
+// processGracePeriod is how long a cancelled command gets after SIGTERM
+// before it is killed.
+const processGracePeriod = 5 * time.Second
+
+// SetToolTimeouts sets the default per-call timeout and optional per-tool
+// overrides. A zero default keeps DefaultToolTimeout.
+func (r *Registry) SetToolTimeouts(def time.Duration, perTool map[string]time.Duration) {
+	if def <= 0 {
+		def = DefaultToolTimeout
+	}
+	r.defaultTimeout = def
+	r.toolTimeouts = perTool
+}
+
+// timeoutFor returns the timeout applied to the named tool.
+func (r *Registry) timeoutFor(name string) time.Duration {
+	if d, ok := r.toolTimeouts[name]; ok && d > 0 {
+		return d
+	}
+	if r.defaultTimeout > 0 {
+		return r.defaultTimeout
+	}
+	return DefaultToolTimeout
+}
+
+// runWithTimeout runs handler under the tool's timeout. If the deadline passes
+// first, the handler's context is cancelled and a timeout result is returned
+// straight away; the handler finishes in the background.
+func (r *Registry) runWithTimeout(ctx context.Context, name string, handler Handler, args map[string]interface{}) *ToolResult {
+	timeout := r.timeoutFor(name)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan *ToolResult, 1)
+	go func() { done <- handler(ctx, args) }()
+
+	select {
+	case res := <-done:
+		return res
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error: tool '%s' timed out after %s and was cancelled. Try a smaller task or a different approach.", name, timeout)}
+		}
+		return &ToolResult{ForLLM: fmt.Sprintf("Error: tool '%s' was cancelled", name)}
+	}
+}
+
+// GracefulCancel makes a context-bound command stop its whole process group
+// with SIGTERM on cancellation, then SIGKILL after processGracePeriod. Without
+// this, children of `sh -c` keep running and hold the output pipes open.
+// Where there are no process groups the command is simply killed.
+func GracefulCancel(cmd *exec.Cmd) {
+	startProcessGroup(cmd)
+	cmd.Cancel = func() error {
+		return terminateProcessGroup(cmd)
+	}
+	cmd.WaitDelay = processGracePeriod
+}
@@ -0,0 +1,228 @@
+package tools
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"littleclaw/pkg/providers"
+)
+
+// minToolScore is the lowest relevance score that gets a tool sent; a single
+// incidental description word is not enough.
+const minToolScore = 2
+
+// ToolGroupCore is the group of tools that are sent with every request when
+// dynamic tool selection is enabled.
+const ToolGroupCore = "core"
+
+// defaultToolGroups assigns the registry's built-in tools to groups. Tools
+// registered elsewhere are grouped with SetToolGroup; tools without a group
+// (e.g. skills) are ranked on their name and description alone.
+var defaultToolGroups = map[string]string{
+	"read_file":          ToolGroupCore,
+	"write_file":         ToolGroupCore,
+	"append_file":        ToolGroupCore,
+	"edit_file":          ToolGroupCore,
+	"exec":               ToolGroupCore,
+	"list_files":         "files",
+	"stat_file":          "files",
+	"delete_file":        "files",
+	"move_file":          "files",
+	"compress":           "files",
+	"extract":            "files",
+	"send_telegram_file": "files",
+	"web_fetch":          "web",
+	"web_search":         "web",
+	"get_weather":        "weather",
+	"git":                "git",
+	"reload_skills":      "skills",
+	"create_skill":       "skills",
+	"install_skill_pack": "skills",
+	"tool_stats":         "diagnostics",
+	"sql_query":          "sql",
+	"list_events":        "calendar",
+	"create_event":       "calendar",
+	"find_free_slot":     "calendar",
+	"analyze_image":      "vision",
+	"take_screenshot":    "desktop",
+	"read_clipboard":     "desktop",
+	"write_clipboard":    "desktop",
+}
+
+// toolGroupKeywords are the words in a user message that make a group relevant.
+var toolGroupKeywords = map[string][]string{
+	"files":       {"file", "folder", "directory", "delete", "remove", "rename", "move", "copy", "list", "zip", "unzip", "tar", "archive", "compress", "extract", "bundle", "document", "pdf", "csv", "send", "attach"},
+	"web":         {"web", "search", "google", "internet", "online", "url", "link", "http", "website", "site", "page", "fetch", "news", "lookup", "latest", "price"},
+	"weather":     {"weather", "forecast", "rain", "temperature", "sunny", "snow", "wind", "umbrella", "cold", "hot", "storm"},
+	"git":         {"git", "repo", "repository", "commit", "branch", "diff", "clone", "github", "push", "pull"},
+	"skills":      {"skill", "script", "plugin", "tool", "automate", "install", "reload"},
+	"diagnostics": {"stats", "audit", "failing", "failure", "usage", "slow", "timing"},
+	"sql":         {"sql", "database", "query", "table", "rows", "postgres", "mysql", "sqlite"},
+	"calendar":    {"calendar", "event", "meeting", "schedule", "appointment", "agenda", "free", "busy", "slot", "book"},
+	"vision":      {"image", "photo", "picture", "screenshot", "look", "analyze", "see"},
+	"desktop":     {"screen", "screenshot", "clipboard", "copy", "paste", "desktop"},
+}
+
+// stopWords are ignored when matching a message against tool descriptions.
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "that": true, "this": true, "with": true,
+	"you": true, "your": true, "from": true, "what": true, "can": true, "please": true,
+	"are": true, "was": true, "have": true, "has": true, "not": true, "but": true,
+	"all": true, "any": true, "into": true, "about": true, "just": true, "like": true,
+	"will": true, "would": true, "could": true, "should": true, "there": true, "them": true,
+}
+
+// SetToolGroup assigns tools to a group (ToolGroupCore to always send them).
+func (r *Registry) SetToolGroup(group string, names ...string) {
+	if r.toolGroups == nil {
+		r.toolGroups = make(map[string]string)
+	}
+	for _, n := range names {
+		r.toolGroups[n] = group
+	}
+}
+
+// SetToolGroupKeywords adds keywords that make a group relevant to a message.
+func (r *Registry) SetToolGroupKeywords(group string, keywords ...string) {
+	if r.groupKeywords == nil {
+		r.groupKeywords = make(map[string][]string)
+	}
+	r.groupKeywords[group] = append(r.groupKeywords[group], keywords...)
+}
+
+// ToolGroup returns the group a tool belongs to, or "" if it has none.
+func (r *Registry) ToolGroup(name string) string {
+	if g, ok := r.toolGroups[name]; ok {
+		return g
+	}
+	return defaultToolGroups[name]
+}
+
+// SetToolSelection limits how many tool definitions SelectDefinitions
+// returns. Core tools are always included; 0 disables selection.
+func (r *Registry) SetToolSelection(limit int) {
+	r.maxTools = limit
+}
+
+// SelectDefinitions returns the tool definitions to send for a message. With
+// selection disabled, or when every tool fits, it returns all definitions.
+// Otherwise it keeps core tools plus the highest-scoring others, in
+// registration order so the tool list stays stable across similar requests.
+func (r *Registry) SelectDefinitions(query string) []providers.ToolDefinition {
+	defs := r.GetDefinitions()
+	if r.maxTools <= 0 || len(defs) <= r.maxTools {
+		return defs
+	}
+
+	words := queryWords(query)
+	type scored struct {
+		idx   int
+		score int
+	}
+	var candidates []scored
+	keep := make(map[int]bool)
+	for i, def := range defs {
+		if r.ToolGroup(def.Function.Name) == ToolGroupCore {
+			keep[i] = true
+			continue
+		}
+		if s := r.scoreTool(def, query, words); s >= minToolScore {
+			candidates = append(candidates, scored{i, s})
+		}
+	}
+	sort.SliceStable(candidates, func(a, b int) bool { return candidates[a].score > candidates[b].score })
+	for _, c := range candidates {
+		if len(keep) >= r.maxTools {
+			break
+		}
+		keep[c.idx] = true
+	}
+
+	out := make([]providers.ToolDefinition, 0, len(keep))
+	for i, def := range defs {
+		if keep[i] {
+			out = append(out, def)
+		}
+	}
+	return out
+}
+
+// RelevantTools returns the names of the tools a message looks like it needs,
+// core tools included, scored as in SelectDefinitions. A message that needs
+// none is likely chit-chat.
+func (r *Registry) RelevantTools(query string) []string {
+	words := queryWords(query)
+	var names []string
+	for _, def := range r.GetDefinitions() {
+		if r.scoreTool(def, query, words) >= minToolScore {
+			names = append(names, def.Function.Name)
+		}
+	}
+	return names
+}
+
+// scoreTool rates how relevant a tool is to a message: an explicit mention of
+// the tool name wins, then matches on name parts, group keywords, and
+// description words.
+func (r *Registry) scoreTool(def providers.ToolDefinition, query string, words []string) int {
+	name := def.Function.Name
+	if strings.Contains(strings.ToLower(query), name) {
+		return 100
+	}
+
+	group := r.ToolGroup(name)
+	keywords := append(append([]string{}, toolGroupKeywords[group]...), r.groupKeywords[group]...)
+	descWords := queryWords(def.Function.Description)
+
+	score := 0
+	for _, w := range words {
+		for _, part := range strings.Split(name, "_") {
+			if wordMatches(w, part) {
+				score += 3
+			}
+		}
+		for _, kw := range keywords {
+			if wordMatches(w, kw) {
+				score += 2
+				break
+			}
+		}
+		for _, dw := range descWords {
+			if wordMatches(w, dw) {
+				score++
+				break
+			}
+		}
+	}
+	return score
+}
+
+// queryWords lowercases s and splits it into words, dropping short and stop words.
+func queryWords(s string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(s), func(c rune) bool {
+		return !unicode.IsLetter(c) && !unicode.IsDigit(c)
+	})
+	seen := make(map[string]bool, len(fields))
+	var out []string
+	for _, f := range fields {
+		if len(f) < 3 || stopWords[f] || seen[f] {
+			continue
+		}
+		seen[f] = true
+		out = append(out, f)
+	}
+	return out
+}
+
+// wordMatches compares a message word with a keyword, allowing simple
+// inflections ("reminders" matches "reminder", "searching" matches "search").
+func wordMatches(word, keyword string) bool {
+	if word == keyword {
+		return true
+	}
+	if len(keyword) >= 4 && strings.HasPrefix(word, keyword) {
+		return true
+	}
+	return len(word) >= 4 && strings.HasPrefix(keyword, word)
+}
@@ -0,0 +1,169 @@
+package tools
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"littleclaw/pkg/httpclient"
+	"littleclaw/pkg/providers"
+)
+
+const (
+	visionMaxImageBytes = 10 << 20
+	visionMaxTokens     = 1000
+)
+
+// downloadClient fetches inbound images on the shared transport.
+var downloadClient = httpclient.New(time.Minute)
+
+// imageDataURL reads a workspace image and encodes it as a data: URL.
+func (r *Registry) imageDataURL(p string) (string, error) {
+	safePath, err := r.resolveWorkspacePath(p)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(safePath)
+	if err != nil {
+		return "", fmt.Errorf("Error reading image: %w", err)
+	}
+	if info.Size() > visionMaxImageBytes {
+		return "", fmt.Errorf("Error: image is %s, the limit is %s", formatSize(info.Size()), formatSize(visionMaxImageBytes))
+	}
+	data, err := os.ReadFile(safePath)
+	if err != nil {
+		return "", fmt.Errorf("Error reading image: %w", err)
+	}
+	mime := http.DetectContentType(data)
+	if !strings.HasPrefix(mime, "image/") {
+		return "", fmt.Errorf("Error: %s is not an image (detected %s)", p, mime)
+	}
+	return "data:" + mime + ";base64," + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// SaveInboundImages downloads images attached to an incoming message into
+// media/ so analyze_image can look at them, returning workspace-relative
+// paths. It does nothing unless a vision model is configured.
+func (r *Registry) SaveInboundImages(ctx context.Context, urls []string) ([]string, error) {
+	if r.visionProvider == nil || len(urls) == 0 {
+		return nil, nil
+	}
+	dir := filepath.Join(r.workspaceDir, "media")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	var saved []string
+	stamp := time.Now().Format("20060102-150405")
+	for i, url := range urls {
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return saved, fmt.Errorf("invalid URL for image %d", i+1)
+		}
+		resp, err := downloadClient.Do(req)
+		if err != nil {
+			// The URL may embed a bot token, so don't echo it back
+			return saved, fmt.Errorf("downloading image %d failed", i+1)
+		}
+		data, err := io.ReadAll(io.LimitReader(resp.Body, visionMaxImageBytes+1))
+		resp.Body.Close()
+		if err != nil || resp.StatusCode != http.StatusOK {
+			return saved, fmt.Errorf("downloading image %d failed (status %d)", i+1, resp.StatusCode)
+		}
+		if len(data) > visionMaxImageBytes {
+			return saved, fmt.Errorf("image %d exceeds %s", i+1, formatSize(visionMaxImageBytes))
+		}
+
+		ext := ".jpg"
+		if mime := http.DetectContentType(data); mime == "image/png" {
+			ext = ".png"
+		} else if mime == "image/webp" {
+			ext = ".webp"
+		}
+		rel := filepath.Join("media", fmt.Sprintf("%s-%d%s", stamp, i+1, ext))
+		if err := os.WriteFile(filepath.Join(r.workspaceDir, rel), data, 0644); err != nil {
+			return saved, err
+		}
+		saved = append(saved, rel)
+	}
+	return saved, nil
+}
+
+// SetVisionModel enables analyze_image, which sends images to the given
+// provider and model. This lets a text-only chat model (e.g. a local llama)
+// hand image questions to a vision-capable one.
+func (r *Registry) SetVisionModel(p providers.Provider, model string) {
+	first := r.visionProvider == nil
+	r.visionProvider = p
+	r.visionModel = model
+	if first {
+		r.registerVisionTool()
+	}
+}
+
+// registerVisionTool adds analyze_image.
+func (r *Registry) registerVisionTool() {
+	r.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "analyze_image",
+			Description: "Looks at an image and answers a question about it using a vision model. Accepts a workspace path (e.g. a photo the user sent) or an http(s) URL.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"image": map[string]interface{}{
+						"type":        "string",
+						"description": "Workspace-relative image path or http(s) URL.",
+					},
+					"question": map[string]interface{}{
+						"type":        "string",
+						"description": "What to find out, e.g. 'Transcribe the receipt total' or 'Describe this photo'.",
+					},
+				},
+				"required": []string{"image"},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *ToolResult {
+		image, ok := args["image"].(string)
+		if !ok || strings.TrimSpace(image) == "" {
+			return &ToolResult{ForLLM: "Error: image must be a non-empty string"}
+		}
+		question, _ := args["question"].(string)
+		if strings.TrimSpace(question) == "" {
+			question = "Describe this image in detail."
+		}
+
+		url := image
+		if !strings.HasPrefix(image, "http://") && !strings.HasPrefix(image, "https://") {
+			var err error
+			if url, err = r.imageDataURL(image); err != nil {
+				return &ToolResult{ForLLM: err.Error()}
+			}
+		}
+
+		resp, err := r.visionProvider.Chat(ctx, providers.ChatRequest{
+			Model: r.visionModel,
+			Messages: []providers.Message{
+				{Role: "user", Content: question, Media: []string{url}},
+			},
+			MaxTokens: visionMaxTokens,
+		})
+		if err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error: vision model request failed: %v", err)}
+		}
+		if strings.TrimSpace(resp.Content) == "" {
+			return &ToolResult{ForLLM: "Error: vision model returned an empty answer"}
+		}
+		return &ToolResult{ForLLM: resp.Content}
+	})
+}
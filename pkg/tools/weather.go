@@ -0,0 +1,61 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"littleclaw/pkg/providers"
+	"littleclaw/pkg/weather"
+)
+
+// SetWeather replaces the get_weather backend (Open-Meteo by default).
+func (r *Registry) SetWeather(p weather.Provider) {
+	if p != nil {
+		r.weather = p
+	}
+}
+
+// registerWeatherTool adds get_weather, backed by r.weather.
+func (r *Registry) registerWeatherTool() {
+	r.RegisterTool(providers.ToolDefinition{
+		Type: "function",
+		Function: struct {
+			Name        string                 `json:"name"`
+			Description string                 `json:"description"`
+			Parameters  map[string]interface{} `json:"parameters"`
+		}{
+			Name:        "get_weather",
+			Description: "Gets current conditions and a daily forecast for a place. Use this instead of scraping weather websites.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"location": map[string]interface{}{
+						"type":        "string",
+						"description": "Place name (e.g. 'Pune' or 'Berlin, Germany') or 'lat,lon' coordinates.",
+					},
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"description": fmt.Sprintf("Days of forecast including today, 1-%d (default 3).", weather.MaxDays),
+					},
+				},
+				"required": []string{"location"},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) *ToolResult {
+		location, _ := args["location"].(string)
+		if strings.TrimSpace(location) == "" {
+			return &ToolResult{ForLLM: "Error: location must be a non-empty string"}
+		}
+		days := 3
+		if d, ok := args["days"].(float64); ok {
+			days = int(d)
+		}
+
+		f, err := r.weather.Forecast(ctx, strings.TrimSpace(location), days)
+		if err != nil {
+			return &ToolResult{ForLLM: fmt.Sprintf("Error getting weather from %s: %v", r.weather.Name(), err)}
+		}
+		return &ToolResult{ForLLM: weather.Format(f)}
+	})
+}
@@ -6,12 +6,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"regexp"
 	"strings"
 	"time"
 
+	"littleclaw/pkg/httpclient"
 	"littleclaw/pkg/providers"
 )
 
@@ -65,7 +67,7 @@ func StripHTML(raw string) string {
 // --- web_fetch implementation ---
 
 func DoWebFetch(rawURL string) (string, error) {
-	client := &http.Client{Timeout: httpTimeout}
+	client := httpclient.New(httpTimeout)
 
 	req, err := http.NewRequest("GET", rawURL, nil)
 	if err != nil {
@@ -148,7 +150,7 @@ func tavilySearch(ctx context.Context, apiKey, query string) (string, bool, erro
 		return "", false, fmt.Errorf("failed to encode request: %w", err)
 	}
 
-	client := &http.Client{Timeout: httpTimeout}
+	client := httpclient.New(httpTimeout)
 	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.tavily.com/search", bytes.NewReader(body))
 	if err != nil {
 		return "", false, fmt.Errorf("failed to build Tavily request: %w", err)
@@ -224,7 +226,7 @@ func FormatTavilyResults(query string, r TavilyResponse) string {
 func duckDuckGoSearch(ctx context.Context, query string) (string, error) {
 	searchURL := "https://html.duckduckgo.com/html/?q=" + url.QueryEscape(query)
 
-	client := &http.Client{Timeout: httpTimeout}
+	client := httpclient.New(httpTimeout)
 	req, err := http.NewRequestWithContext(ctx, "GET", searchURL, nil)
 	if err != nil {
 		return "", fmt.Errorf("failed to build DDG request: %w", err)
@@ -402,7 +404,7 @@ func (r *Registry) registerWebTools() {
 			if rateLimited {
 				fallbackReason = "rate limit reached"
 			}
-			fmt.Printf("⚠️  Tavily search failed (%s: %v), falling back to DuckDuckGo\n", fallbackReason, err)
+			slog.Warn("tavily search failed, falling back to DuckDuckGo", "reason", fallbackReason, "err", err)
 		}
 
 		// DuckDuckGo fallback
@@ -0,0 +1,186 @@
+package weather
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"littleclaw/pkg/httpclient"
+)
+
+// OpenMeteo uses the free Open-Meteo forecast and geocoding APIs (no key).
+type OpenMeteo struct {
+	units string
+	http  *http.Client
+
+	// Overridable for tests
+	GeocodeURL  string
+	ForecastURL string
+}
+
+// NewOpenMeteo creates an Open-Meteo provider; units is "metric" (default) or "imperial".
+func NewOpenMeteo(units string) *OpenMeteo {
+	if units != "imperial" {
+		units = "metric"
+	}
+	return &OpenMeteo{
+		units:       units,
+		http:        httpclient.New(httpTimeout),
+		GeocodeURL:  "https://geocoding-api.open-meteo.com/v1/search",
+		ForecastURL: "https://api.open-meteo.com/v1/forecast",
+	}
+}
+
+func (o *OpenMeteo) Name() string { return "open-meteo" }
+
+// geocode resolves a place name to coordinates and a display name.
+func (o *OpenMeteo) geocode(ctx context.Context, place string) (lat, lon float64, name string, err error) {
+	var res struct {
+		Results []struct {
+			Name      string  `json:"name"`
+			Latitude  float64 `json:"latitude"`
+			Longitude float64 `json:"longitude"`
+			Admin1    string  `json:"admin1"`
+			Country   string  `json:"country"`
+		} `json:"results"`
+	}
+	q := url.Values{"name": {place}, "count": {"1"}, "format": {"json"}}
+	if err := getJSON(ctx, o.http, o.GeocodeURL+"?"+q.Encode(), &res); err != nil {
+		return 0, 0, "", fmt.Errorf("geocoding failed: %w", err)
+	}
+	if len(res.Results) == 0 {
+		return 0, 0, "", fmt.Errorf("location %q not found", place)
+	}
+	r := res.Results[0]
+	parts := []string{r.Name}
+	for _, p := range []string{r.Admin1, r.Country} {
+		if p != "" && p != r.Name {
+			parts = append(parts, p)
+		}
+	}
+	return r.Latitude, r.Longitude, strings.Join(parts, ", "), nil
+}
+
+func (o *OpenMeteo) Forecast(ctx context.Context, location string, days int) (*Forecast, error) {
+	lat, lon, ok := parseLatLon(location)
+	name := location
+	if !ok {
+		var err error
+		if lat, lon, name, err = o.geocode(ctx, location); err != nil {
+			return nil, err
+		}
+	}
+
+	q := url.Values{
+		"latitude":      {fmt.Sprintf("%.4f", lat)},
+		"longitude":     {fmt.Sprintf("%.4f", lon)},
+		"current":       {"temperature_2m,apparent_temperature,relative_humidity_2m,weather_code,wind_speed_10m"},
+		"daily":         {"weather_code,temperature_2m_max,temperature_2m_min,precipitation_probability_max,precipitation_sum"},
+		"timezone":      {"auto"},
+		"forecast_days": {fmt.Sprint(clampDays(days))},
+	}
+	if o.units == "imperial" {
+		q.Set("temperature_unit", "fahrenheit")
+		q.Set("wind_speed_unit", "mph")
+		q.Set("precipitation_unit", "inch")
+	}
+
+	var res struct {
+		Current struct {
+			Temp      float64 `json:"temperature_2m"`
+			FeelsLike float64 `json:"apparent_temperature"`
+			Humidity  int     `json:"relative_humidity_2m"`
+			Code      int     `json:"weather_code"`
+			Wind      float64 `json:"wind_speed_10m"`
+		} `json:"current"`
+		Daily struct {
+			Time         []string  `json:"time"`
+			Code         []int     `json:"weather_code"`
+			Max          []float64 `json:"temperature_2m_max"`
+			Min          []float64 `json:"temperature_2m_min"`
+			PrecipChance []*int    `json:"precipitation_probability_max"`
+			Precip       []float64 `json:"precipitation_sum"`
+		} `json:"daily"`
+	}
+	if err := getJSON(ctx, o.http, o.ForecastURL+"?"+q.Encode(), &res); err != nil {
+		return nil, fmt.Errorf("forecast request failed: %w", err)
+	}
+
+	f := &Forecast{
+		Location: name,
+		Units:    o.units,
+		Current: &Conditions{
+			Temp:        res.Current.Temp,
+			FeelsLike:   res.Current.FeelsLike,
+			Humidity:    res.Current.Humidity,
+			WindSpeed:   res.Current.Wind,
+			Description: wmoDescription(res.Current.Code),
+		},
+	}
+	d := res.Daily
+	for i, day := range d.Time {
+		date, err := time.Parse("2006-01-02", day)
+		if err != nil || i >= len(d.Code) || i >= len(d.Max) || i >= len(d.Min) {
+			continue
+		}
+		fd := Day{Date: date, Min: d.Min[i], Max: d.Max[i], Description: wmoDescription(d.Code[i])}
+		if i < len(d.PrecipChance) && d.PrecipChance[i] != nil {
+			fd.PrecipChance = *d.PrecipChance[i]
+		}
+		if i < len(d.Precip) {
+			fd.Precip = d.Precip[i]
+		}
+		f.Days = append(f.Days, fd)
+	}
+	return f, nil
+}
+
+// wmoDescription maps WMO weather interpretation codes used by Open-Meteo.
+func wmoDescription(code int) string {
+	switch code {
+	case 0:
+		return "clear sky"
+	case 1:
+		return "mainly clear"
+	case 2:
+		return "partly cloudy"
+	case 3:
+		return "overcast"
+	case 45, 48:
+		return "fog"
+	case 51, 53, 55:
+		return "drizzle"
+	case 56, 57:
+		return "freezing drizzle"
+	case 61:
+		return "light rain"
+	case 63:
+		return "rain"
+	case 65:
+		return "heavy rain"
+	case 66, 67:
+		return "freezing rain"
+	case 71:
+		return "light snow"
+	case 73:
+		return "snow"
+	case 75:
+		return "heavy snow"
+	case 77:
+		return "snow grains"
+	case 80, 81:
+		return "rain showers"
+	case 82:
+		return "violent rain showers"
+	case 85, 86:
+		return "snow showers"
+	case 95:
+		return "thunderstorm"
+	case 96, 99:
+		return "thunderstorm with hail"
+	}
+	return fmt.Sprintf("weather code %d", code)
+}
@@ -0,0 +1,175 @@
+package weather
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"littleclaw/pkg/httpclient"
+)
+
+// OpenWeatherMap uses the OpenWeatherMap current weather and 5 day / 3 hour
+// forecast APIs (free tier key required). Daily values are aggregated from
+// the 3-hourly entries, so at most 5 days are available.
+type OpenWeatherMap struct {
+	apiKey string
+	units  string
+	http   *http.Client
+
+	// Overridable for tests
+	BaseURL string
+}
+
+// NewOpenWeatherMap creates an OpenWeatherMap provider; units is "metric" (default) or "imperial".
+func NewOpenWeatherMap(apiKey, units string) *OpenWeatherMap {
+	if units != "imperial" {
+		units = "metric"
+	}
+	return &OpenWeatherMap{
+		apiKey:  apiKey,
+		units:   units,
+		http:    httpclient.New(httpTimeout),
+		BaseURL: "https://api.openweathermap.org",
+	}
+}
+
+func (o *OpenWeatherMap) Name() string { return "openweathermap" }
+
+type owmEntry struct {
+	Dt   int64 `json:"dt"`
+	Main struct {
+		Temp      float64 `json:"temp"`
+		FeelsLike float64 `json:"feels_like"`
+		TempMin   float64 `json:"temp_min"`
+		TempMax   float64 `json:"temp_max"`
+		Humidity  int     `json:"humidity"`
+	} `json:"main"`
+	Weather []struct {
+		Description string `json:"description"`
+	} `json:"weather"`
+	Wind struct {
+		Speed float64 `json:"speed"`
+	} `json:"wind"`
+	Pop  float64            `json:"pop"`
+	Rain map[string]float64 `json:"rain"`
+	Snow map[string]float64 `json:"snow"`
+}
+
+func (e owmEntry) description() string {
+	if len(e.Weather) == 0 {
+		return "unknown"
+	}
+	return e.Weather[0].Description
+}
+
+func (o *OpenWeatherMap) Forecast(ctx context.Context, location string, days int) (*Forecast, error) {
+	if o.apiKey == "" {
+		return nil, fmt.Errorf("OpenWeatherMap API key is not configured")
+	}
+
+	q := url.Values{"appid": {o.apiKey}, "units": {o.units}}
+	name := location
+	if lat, lon, ok := parseLatLon(location); ok {
+		q.Set("lat", fmt.Sprintf("%.4f", lat))
+		q.Set("lon", fmt.Sprintf("%.4f", lon))
+	} else {
+		var geo []struct {
+			Name    string  `json:"name"`
+			Lat     float64 `json:"lat"`
+			Lon     float64 `json:"lon"`
+			State   string  `json:"state"`
+			Country string  `json:"country"`
+		}
+		gq := url.Values{"q": {location}, "limit": {"1"}, "appid": {o.apiKey}}
+		if err := getJSON(ctx, o.http, o.BaseURL+"/geo/1.0/direct?"+gq.Encode(), &geo); err != nil {
+			return nil, fmt.Errorf("geocoding failed: %w", err)
+		}
+		if len(geo) == 0 {
+			return nil, fmt.Errorf("location %q not found", location)
+		}
+		g := geo[0]
+		q.Set("lat", fmt.Sprintf("%.4f", g.Lat))
+		q.Set("lon", fmt.Sprintf("%.4f", g.Lon))
+		parts := []string{g.Name}
+		for _, p := range []string{g.State, g.Country} {
+			if p != "" {
+				parts = append(parts, p)
+			}
+		}
+		name = strings.Join(parts, ", ")
+	}
+
+	var current owmEntry
+	if err := getJSON(ctx, o.http, o.BaseURL+"/data/2.5/weather?"+q.Encode(), &current); err != nil {
+		return nil, fmt.Errorf("current weather request failed: %w", err)
+	}
+	var fc struct {
+		List []owmEntry `json:"list"`
+		City struct {
+			Timezone int `json:"timezone"` // UTC offset in seconds
+		} `json:"city"`
+	}
+	if err := getJSON(ctx, o.http, o.BaseURL+"/data/2.5/forecast?"+q.Encode(), &fc); err != nil {
+		return nil, fmt.Errorf("forecast request failed: %w", err)
+	}
+
+	f := &Forecast{
+		Location: name,
+		Units:    o.units,
+		Current: &Conditions{
+			Temp:        current.Main.Temp,
+			FeelsLike:   current.Main.FeelsLike,
+			Humidity:    current.Main.Humidity,
+			WindSpeed:   o.windSpeed(current.Wind.Speed),
+			Description: current.description(),
+		},
+		Days: o.aggregateDays(fc.List, time.FixedZone("local", fc.City.Timezone)),
+	}
+	if n := clampDays(days); len(f.Days) > n {
+		f.Days = f.Days[:n]
+	}
+	return f, nil
+}
+
+// windSpeed converts the API's m/s (metric) to km/h; imperial is already mph.
+func (o *OpenWeatherMap) windSpeed(v float64) float64 {
+	if o.units == "metric" {
+		return v * 3.6
+	}
+	return v
+}
+
+// aggregateDays folds 3-hourly entries into per-day min/max, the highest
+// precipitation chance, total precipitation, and the midday description.
+func (o *OpenWeatherMap) aggregateDays(entries []owmEntry, loc *time.Location) []Day {
+	var out []Day
+	var middayDist []float64
+	for _, e := range entries {
+		t := time.Unix(e.Dt, 0).In(loc)
+		date := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		if len(out) == 0 || !out[len(out)-1].Date.Equal(date) {
+			out = append(out, Day{Date: date, Min: e.Main.TempMin, Max: e.Main.TempMax})
+			middayDist = append(middayDist, math.Inf(1))
+		}
+		d := &out[len(out)-1]
+		d.Min = math.Min(d.Min, e.Main.TempMin)
+		d.Max = math.Max(d.Max, e.Main.TempMax)
+		if pop := int(math.Round(e.Pop * 100)); pop > d.PrecipChance {
+			d.PrecipChance = pop
+		}
+		mm := e.Rain["3h"] + e.Snow["3h"]
+		if o.units == "imperial" {
+			mm /= 25.4
+		}
+		d.Precip += mm
+		if dist := math.Abs(float64(t.Hour()) - 12); dist < middayDist[len(out)-1] {
+			middayDist[len(out)-1] = dist
+			d.Description = e.description()
+		}
+	}
+	return out
+}
@@ -0,0 +1,166 @@
+package weather_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"littleclaw/pkg/weather"
+)
+
+// ---------------------------------------------------------------------------
+// Open-Meteo
+// ---------------------------------------------------------------------------
+
+func newOpenMeteoServer(t *testing.T, queries *[]string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*queries = append(*queries, r.URL.Path+"?"+r.URL.RawQuery)
+		switch r.URL.Path {
+		case "/search":
+			if r.URL.Query().Get("name") == "Nowhere" {
+				_, _ = w.Write([]byte(`{}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"results":[{"name":"Pune","latitude":18.52,"longitude":73.86,"admin1":"Maharashtra","country":"India"}]}`))
+		case "/forecast":
+			_, _ = w.Write([]byte(`{
+				"current": {"temperature_2m": 27.4, "apparent_temperature": 29.1, "relative_humidity_2m": 62, "weather_code": 2, "wind_speed_10m": 11.2},
+				"daily": {
+					"time": ["2026-10-16", "2026-10-17"],
+					"weather_code": [61, 0],
+					"temperature_2m_max": [30.2, 31.0],
+					"temperature_2m_min": [21.0, 20.4],
+					"precipitation_probability_max": [80, null],
+					"precipitation_sum": [4.2, 0]
+				}
+			}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestOpenMeteo_Forecast(t *testing.T) {
+	var queries []string
+	srv := newOpenMeteoServer(t, &queries)
+	defer srv.Close()
+
+	om := weather.NewOpenMeteo("")
+	om.GeocodeURL, om.ForecastURL = srv.URL+"/search", srv.URL+"/forecast"
+
+	f, err := om.Forecast(context.Background(), "Pune", 2)
+	if err != nil {
+		t.Fatalf("Forecast: %v", err)
+	}
+	if f.Location != "Pune, Maharashtra, India" || f.Current.Description != "partly cloudy" || f.Current.Humidity != 62 {
+		t.Errorf("unexpected forecast %+v / %+v", f, f.Current)
+	}
+	if len(f.Days) != 2 || f.Days[0].Description != "light rain" || f.Days[0].PrecipChance != 80 || f.Days[1].PrecipChance != 0 {
+		t.Errorf("unexpected days %+v", f.Days)
+	}
+	if !strings.Contains(queries[1], "forecast_days=2") || !strings.Contains(queries[1], "latitude=18.5200") {
+		t.Errorf("unexpected forecast query %q", queries[1])
+	}
+
+	text := weather.Format(f)
+	for _, want := range []string{"Weather for Pune", "Now: partly cloudy, 27°C", "Fri Oct 16: light rain, 21–30°C, 80% chance of precipitation (4.2 mm)"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("formatted forecast missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestOpenMeteo_CoordinatesAndUnits(t *testing.T) {
+	var queries []string
+	srv := newOpenMeteoServer(t, &queries)
+	defer srv.Close()
+
+	om := weather.NewOpenMeteo("imperial")
+	om.GeocodeURL, om.ForecastURL = srv.URL+"/search", srv.URL+"/forecast"
+
+	f, err := om.Forecast(context.Background(), "40.71,-74.01", 30)
+	if err != nil {
+		t.Fatalf("Forecast: %v", err)
+	}
+	if len(queries) != 1 {
+		t.Errorf("coordinates should skip geocoding, got %v", queries)
+	}
+	for _, want := range []string{"temperature_unit=fahrenheit", "forecast_days=7"} {
+		if !strings.Contains(queries[0], want) {
+			t.Errorf("query %q missing %q", queries[0], want)
+		}
+	}
+	if !strings.Contains(weather.Format(f), "°F") {
+		t.Error("imperial forecast should be formatted in °F")
+	}
+}
+
+func TestOpenMeteo_UnknownLocation(t *testing.T) {
+	var queries []string
+	srv := newOpenMeteoServer(t, &queries)
+	defer srv.Close()
+
+	om := weather.NewOpenMeteo("metric")
+	om.GeocodeURL, om.ForecastURL = srv.URL+"/search", srv.URL+"/forecast"
+	if _, err := om.Forecast(context.Background(), "Nowhere", 1); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// OpenWeatherMap
+// ---------------------------------------------------------------------------
+
+func TestOpenWeatherMap_AggregatesDays(t *testing.T) {
+	day1 := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("appid") != "key" {
+			http.Error(w, `{"message":"Invalid API key"}`, http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/geo/1.0/direct":
+			_, _ = w.Write([]byte(`[{"name":"London","lat":51.5,"lon":-0.12,"country":"GB"}]`))
+		case "/data/2.5/weather":
+			_, _ = w.Write([]byte(`{"main":{"temp":12,"feels_like":10,"humidity":80},"weather":[{"description":"light rain"}],"wind":{"speed":5}}`))
+		case "/data/2.5/forecast":
+			entry := func(h int, min, max, pop float64, desc string) string {
+				return `{"dt":` + fmt.Sprint(day1.Add(time.Duration(h)*time.Hour).Unix()) + `,"main":{"temp_min":` + fmt.Sprint(min) + `,"temp_max":` + fmt.Sprint(max) + `},"weather":[{"description":"` + desc + `"}],"pop":` + fmt.Sprint(pop) + `,"rain":{"3h":1.5}}`
+			}
+			_, _ = w.Write([]byte(`{"city":{"timezone":0},"list":[` +
+				entry(6, 9, 11, 0.2, "mist") + "," +
+				entry(12, 11, 15, 0.6, "moderate rain") + "," +
+				entry(18, 10, 13, 0.1, "clouds") + "," +
+				entry(30, 8, 12, 0, "clear sky") + `]}`))
+		}
+	}))
+	defer srv.Close()
+
+	owm := weather.NewOpenWeatherMap("key", "metric")
+	owm.BaseURL = srv.URL
+	f, err := owm.Forecast(context.Background(), "London", 5)
+	if err != nil {
+		t.Fatalf("Forecast: %v", err)
+	}
+	if f.Location != "London, GB" || f.Current.WindSpeed != 18 {
+		t.Errorf("unexpected location/current %q %+v", f.Location, f.Current)
+	}
+	if len(f.Days) != 2 {
+		t.Fatalf("expected 2 days, got %+v", f.Days)
+	}
+	d := f.Days[0]
+	if d.Min != 9 || d.Max != 15 || d.PrecipChance != 60 || d.Precip != 4.5 || d.Description != "moderate rain" {
+		t.Errorf("unexpected first day %+v", d)
+	}
+
+	bad := weather.NewOpenWeatherMap("wrong", "metric")
+	bad.BaseURL = srv.URL
+	if _, err := bad.Forecast(context.Background(), "London", 1); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("expected auth error, got %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const httpTimeout = 20 * time.Second
+
+// MaxDays is the longest forecast any provider is asked for.
+const MaxDays = 7
+
+// Conditions describes the weather right now.
+type Conditions struct {
+	Temp        float64
+	FeelsLike   float64
+	Humidity    int     // percent
+	WindSpeed   float64 // km/h (metric) or mph (imperial)
+	Description string
+}
+
+// Day is the forecast for one calendar day at the location.
+type Day struct {
+	Date         time.Time
+	Min, Max     float64
+	PrecipChance int     // percent
+	Precip       float64 // mm (metric) or inches (imperial)
+	Description  string
+}
+
+// Forecast is the normalized result from every provider.
+type Forecast struct {
+	Location string
+	Units    string // "metric" or "imperial"
+	Current  *Conditions
+	Days     []Day
+}
+
+// Provider is implemented by each weather backend (Open-Meteo, OpenWeatherMap).
+type Provider interface {
+	// Forecast returns current conditions and days (1..MaxDays) of daily
+	// forecast for a place name or "lat,lon".
+	Forecast(ctx context.Context, location string, days int) (*Forecast, error)
+	Name() string
+}
+
+// parseLatLon recognizes "lat,lon" coordinates.
+func parseLatLon(s string) (lat, lon float64, ok bool) {
+	a, b, found := strings.Cut(s, ",")
+	if !found {
+		return 0, 0, false
+	}
+	lat, err1 := strconv.ParseFloat(strings.TrimSpace(a), 64)
+	lon, err2 := strconv.ParseFloat(strings.TrimSpace(b), 64)
+	if err1 != nil || err2 != nil || lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		return 0, 0, false
+	}
+	return lat, lon, true
+}
+
+// clampDays keeps a requested day count within 1..MaxDays.
+func clampDays(days int) int {
+	if days < 1 {
+		return 1
+	}
+	if days > MaxDays {
+		return MaxDays
+	}
+	return days
+}
+
+// getJSON fetches url and decodes the JSON body into out.
+func getJSON(ctx context.Context, client *http.Client, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 500))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// Format renders a forecast as compact text for the model or a cron message.
+func Format(f *Forecast) string {
+	temp, speed, precip := "°C", "km/h", "mm"
+	if f.Units == "imperial" {
+		temp, speed, precip = "°F", "mph", "in"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Weather for %s\n", f.Location))
+	if c := f.Current; c != nil {
+		sb.WriteString(fmt.Sprintf("Now: %s, %.0f%s (feels like %.0f%s), humidity %d%%, wind %.0f %s\n",
+			c.Description, c.Temp, temp, c.FeelsLike, temp, c.Humidity, c.WindSpeed, speed))
+	}
+	for _, d := range f.Days {
+		sb.WriteString(fmt.Sprintf("%s: %s, %.0f–%.0f%s, %d%% chance of precipitation",
+			d.Date.Format("Mon Jan 2"), d.Description, d.Min, d.Max, temp, d.PrecipChance))
+		if d.Precip > 0 {
+			sb.WriteString(fmt.Sprintf(" (%.1f %s)", d.Precip, precip))
+		}
+		sb.WriteString("\n")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}